@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -11,11 +12,12 @@ import (
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/database"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
 )
 
 var (
 	username   = flag.String("username", "", "用户名 (3-50个字符，仅字母和数字)")
-	password   = flag.String("password", "", "密码 (至少8个字符)")
+	password   = flag.String("password", "", "密码 (需符合配置文件中的密码策略)")
 	email      = flag.String("email", "", "电子邮件 (可选)")
 	role       = flag.String("role", "", "用户角色: admin 或 user (可选，默认根据是否为第一个用户自动判断)")
 	configPath = flag.String("config", "", "配置文件路径 (默认: ./configs/config.yaml)")
@@ -33,20 +35,20 @@ func main() {
 		fmt.Println()
 		fmt.Println("参数:")
 		fmt.Println("  -username  用户名 (3-50个字符，仅字母和数字)")
-		fmt.Println("  -password  密码 (至少8个字符)")
+		fmt.Println("  -password  密码 (需符合配置文件中的密码策略)")
 		fmt.Println("  -email     电子邮件 (可选)")
 		fmt.Println("  -role      用户角色: admin 或 user (可选，默认自动判断)")
 		fmt.Println("  -config    配置文件路径 (可选)")
 		fmt.Println()
 		fmt.Println("示例:")
 		fmt.Println("  # 创建第一个用户（自动成为管理员）")
-		fmt.Println("  create-user -username admin -password adminpass123 -email admin@example.com")
+		fmt.Println("  create-user -username admin -password 'Str0ng-Passw0rd!' -email admin@example.com")
 		fmt.Println()
 		fmt.Println("  # 创建普通用户")
-		fmt.Println("  create-user -username testuser -password userpass123")
+		fmt.Println("  create-user -username testuser -password 'Gr33n-Apple-Tea'")
 		fmt.Println()
 		fmt.Println("  # 显式指定角色创建管理员")
-		fmt.Println("  create-user -username admin2 -password admin2pass -role admin")
+		fmt.Println("  create-user -username admin2 -password 'Blue-Sky-2024!' -role admin")
 		os.Exit(1)
 	}
 
@@ -56,12 +58,6 @@ func main() {
 		os.Exit(1)
 	}
 
-	// 验证密码格式
-	if err := validatePassword(*password); err != nil {
-		fmt.Printf("错误: %v\n", err)
-		os.Exit(1)
-	}
-
 	// 验证邮箱格式（如果提供）
 	if *email != "" {
 		if err := validateEmail(*email); err != nil {
@@ -84,6 +80,25 @@ func main() {
 		os.Exit(1)
 	}
 
+	// 按配置的密码策略验证密码
+	passwordPolicy, err := utils.NewPasswordPolicy(&cfg.Security)
+	if err != nil {
+		fmt.Printf("加载密码策略失败: %v\n", err)
+		os.Exit(1)
+	}
+	if err := passwordPolicy.Validate(*password, *username); err != nil {
+		var policyErr *utils.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			fmt.Println("错误: 密码不符合密码策略:")
+			for _, v := range policyErr.Violations {
+				fmt.Printf("  - [%s] %s\n", v.Rule, v.Message)
+			}
+		} else {
+			fmt.Printf("错误: %v\n", err)
+		}
+		os.Exit(1)
+	}
+
 	// 连接数据库
 	fmt.Println("正在连接数据库...")
 	err = database.Init(&cfg.Database)
@@ -177,17 +192,6 @@ func validateUsername(username string) error {
 	return nil
 }
 
-// validatePassword 验证密码格式
-func validatePassword(password string) error {
-	if len(password) < 8 {
-		return fmt.Errorf("密码长度不能少于8个字符")
-	}
-	if len(password) > 128 {
-		return fmt.Errorf("密码长度不能超过128个字符")
-	}
-	return nil
-}
-
 // validateEmail 验证邮箱格式
 func validateEmail(email string) error {
 	if len(email) > 100 {
@@ -666,6 +666,7 @@ security:
   require_special_char: true
   require_number: true
   require_uppercase: true
+  password_denylist_file: configs/common-passwords.txt
 
 upload:
   max_file_size: 10485760
@@ -1153,7 +1154,7 @@ create_initial_user() {
     # 获取密码 / Get password
     echo ""
     log "等待用户输入: 管理员密码"
-    INIT_PASSWORD=$(read_password "管理员密码 (至少8个字符)")
+    INIT_PASSWORD=$(read_password "管理员密码 (至少8个字符，需包含大写字母、数字和特殊字符)")
     log "管理员密码已设置 (长度: ${#INIT_PASSWORD})"
     
     # 获取邮箱 / Get email
@@ -13,9 +13,12 @@ import (
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/config"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/database"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/handler"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/mailer"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/oidc"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/router"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/scheduler"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/service"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
 	"github.com/gin-gonic/gin"
@@ -24,11 +27,13 @@ import (
 
 // App 应用程序结构
 type App struct {
-	config     *config.Config
-	logger     *zap.Logger
-	db         *sql.DB
-	httpServer *http.Server
-	router     *gin.Engine
+	config         *config.Config
+	logger         *zap.Logger
+	db             *sql.DB
+	passwordPolicy *utils.PasswordPolicy
+	httpServer     *http.Server
+	router         *gin.Engine
+	scheduler      *scheduler.Scheduler
 }
 
 // New 创建应用程序实例
@@ -78,10 +83,26 @@ func (a *App) initDependencies() error {
 		return fmt.Errorf("failed to create crypto service: %w", err)
 	}
 
+	// 创建邮件发送器
+	mailSender, err := mailer.New(&a.config.Mail)
+	if err != nil {
+		return fmt.Errorf("failed to create mailer: %w", err)
+	}
+	a.logger.Info("Mailer initialized", zap.String("driver", a.config.Mail.Driver))
+
+	// 创建密码策略
+	passwordPolicy, err := utils.NewPasswordPolicy(&a.config.Security)
+	if err != nil {
+		return fmt.Errorf("failed to create password policy: %w", err)
+	}
+	a.passwordPolicy = passwordPolicy
+	a.logger.Info("Password policy initialized", zap.Int("denylist_size", passwordPolicy.DenylistSize()))
+
 	// ========== 创建所有 Repository 实例 ==========
 	userRepo := repository.NewUserRepository(a.db)
 	userPrefsRepo := repository.NewUserPreferencesRepository(a.db)
 	foodRepo := repository.NewFoodRepository(a.db)
+	foodCategoryRepo := repository.NewFoodCategoryRepository(a.db)
 	mealRepo := repository.NewMealRepository(a.db)
 	planRepo := repository.NewPlanRepository(a.db)
 	aiSettingsRepo := repository.NewAISettingsRepository(a.db, cryptoService)
@@ -90,25 +111,64 @@ func (a *App) initDependencies() error {
 	systemSettingsRepo := repository.NewSystemSettingsRepository(a.db)
 	conversationRepo := repository.NewConversationRepository(a.db)
 	messageRepo := repository.NewMessageRepository(a.db)
+	fastingRepo := repository.NewFastingRepository(a.db)
+	bodyMetricRepo := repository.NewBodyMetricRepository(a.db)
+	nutritionGoalRepo := repository.NewNutritionGoalRepository(a.db)
+	activityRepo := repository.NewActivityRepository(a.db)
+	waterLogRepo := repository.NewWaterLogRepository(a.db)
+	twoFactorRepo := repository.NewTwoFactorRepository(a.db, cryptoService)
+	sessionRepo := repository.NewSessionRepository(a.db)
+	apiTokenRepo := repository.NewAPITokenRepository(a.db)
+	emailTokenRepo := repository.NewEmailTokenRepository(a.db)
+	userIdentityRepo := repository.NewUserIdentityRepository(a.db)
+	oidcStateRepo := repository.NewOIDCStateRepository(a.db)
+	accountDataRepo := repository.NewAccountDataRepository(a.db)
+	accountExportRepo := repository.NewAccountExportRepository(a.db)
+	apiLogRepo := repository.NewAPILogRepository(a.db)
+	auditLogRepo := repository.NewAuditLogRepository(a.db)
 
 	// 创建令牌黑名单仓库（根据 Redis 是否可用选择实现）
 	var tokenBlacklistRepo repository.TokenBlacklistRepository
+	var userCacheRepo repository.UserCacheRepository
 	if database.IsRedisEnabled() {
 		tokenBlacklistRepo = repository.NewRedisTokenBlacklistRepository(database.GetRedisClient())
+		userCacheRepo = repository.NewRedisUserCacheRepository(database.GetRedisClient())
 		a.logger.Info("Using Redis for token blacklist")
 	} else {
 		tokenBlacklistRepo = repository.NewMemoryTokenBlacklistRepository()
+		userCacheRepo = repository.NewNoopUserCacheRepository()
 		a.logger.Warn("Using in-memory storage for token blacklist (not recommended for production)")
 	}
 
 	a.logger.Info("All repositories initialized")
 
 	// ========== 创建所有 Service 实例 ==========
+	// 审计日志服务最先创建，账户、安全和设置相关的服务都依赖它
+	auditService := service.NewAuditService(auditLogRepo, a.config.Jobs.LogCleanup.AuditLogRetentionDays, a.logger)
+
 	// 先创建 SettingsService，因为 AuthService 依赖它
 	settingsService := service.NewSettingsService(
 		aiSettingsRepo,
 		userPrefsRepo,
 		systemSettingsRepo,
+		nutritionGoalRepo,
+		auditService,
+	)
+
+	twoFactorService := service.NewTwoFactorService(twoFactorRepo, userRepo, auditService)
+	sessionService := service.NewSessionService(sessionRepo, tokenBlacklistRepo, auditService, jwtService)
+	apiTokenService := service.NewAPITokenService(apiTokenRepo, userRepo, auditService)
+	emailService := service.NewEmailService(
+		userRepo,
+		emailTokenRepo,
+		sessionService,
+		auditService,
+		mailSender,
+		passwordPolicy,
+		a.config.Mail.AppURL,
+		a.config.Mail.VerificationTTL,
+		a.config.Mail.PasswordResetTTL,
+		a.logger,
 	)
 
 	authService := service.NewAuthService(
@@ -116,16 +176,68 @@ func (a *App) initDependencies() error {
 		loginAttemptRepo,
 		tokenBlacklistRepo,
 		settingsService,
+		twoFactorService,
+		sessionService,
+		emailService,
+		auditService,
 		jwtService,
+		passwordPolicy,
 		a.config.Security.MaxLoginAttempts,
 		a.config.Security.LockoutDuration,
 	)
 
-	foodService := service.NewFoodService(foodRepo)
+	// 未启用单点登录时不创建身份提供方客户端
+	var oidcProvider *oidc.Provider
+	if a.config.OIDC.Enabled {
+		oidcProvider = oidc.NewProvider(&a.config.OIDC)
+		a.logger.Info("OIDC login enabled", zap.String("issuer", a.config.OIDC.Issuer))
+	}
+	oidcService := service.NewOIDCService(
+		oidcProvider,
+		a.config.OIDC.ProviderName,
+		a.config.OIDC.StateTTL,
+		userRepo,
+		userIdentityRepo,
+		oidcStateRepo,
+		settingsService,
+		authService,
+		auditService,
+		a.logger,
+	)
+
+	userAdminService := service.NewUserAdminService(
+		userRepo,
+		userCacheRepo,
+		twoFactorService,
+		sessionService,
+		auditService,
+		passwordPolicy,
+		a.logger,
+	)
+
+	dietaryService := service.NewDietaryService(foodRepo, userPrefsRepo)
+
+	foodCategoryService := service.NewFoodCategoryService(foodCategoryRepo)
+
+	foodService := service.NewFoodService(foodRepo, foodCategoryService, dietaryService)
+	foodSearchService := service.NewFoodSearchService(foodRepo, mealRepo, foodCategoryService)
+	foodSuggestionService := service.NewFoodSuggestionService(foodRepo, mealRepo)
+
+	hydrationService := service.NewHydrationService(waterLogRepo, mealRepo, foodRepo, userPrefsRepo)
+
+	nutritionService := service.NewNutritionService(foodRepo, mealRepo, activityRepo, hydrationService, foodCategoryService)
+
+	goalService := service.NewGoalService(nutritionGoalRepo, userPrefsRepo)
+
+	fastingService := service.NewFastingService(fastingRepo, mealRepo)
 
-	nutritionService := service.NewNutritionService(foodRepo, mealRepo)
+	bodyMetricService := service.NewBodyMetricService(bodyMetricRepo, userPrefsRepo, settingsService)
 
-	mealService := service.NewMealService(mealRepo, nutritionService)
+	energyService := service.NewEnergyService(userPrefsRepo, bodyMetricRepo, mealRepo, settingsService)
+
+	activityService := service.NewActivityService(activityRepo, bodyMetricRepo)
+
+	mealService := service.NewMealService(mealRepo, userPrefsRepo, nutritionService, fastingService, dietaryService)
 
 	aiService := service.NewAIService(
 		aiSettingsRepo,
@@ -135,14 +247,20 @@ func (a *App) initDependencies() error {
 	planService := service.NewPlanService(
 		planRepo,
 		mealRepo,
+		userPrefsRepo,
 		aiService,
 		nutritionService,
+		dietaryService,
+		a.config.Jobs.PlanReconcile.DefaultTime,
 	)
 
 	dashboardService := service.NewDashboardService(
 		mealService,
 		planService,
 		nutritionService,
+		fastingService,
+		bodyMetricService,
+		hydrationService,
 	)
 
 	// 创建对话流服务
@@ -156,21 +274,96 @@ func (a *App) initDependencies() error {
 		conversationRepo,
 		messageRepo,
 		aiSettingsRepo,
+		userPrefsRepo,
+		goalService,
+	)
+
+	// 创建账户数据导出和导入服务
+	accountDataService := service.NewAccountDataService(
+		accountDataRepo,
+		accountExportRepo,
+		userRepo,
+		settingsService,
+		auditService,
+		a.config.Export.Path,
+		a.config.Export.AsyncThreshold,
+		a.config.Export.TTL,
+		a.logger,
+	)
+
+	// 创建账户删除服务
+	accountDeletionService := service.NewAccountDeletionService(
+		userRepo,
+		userCacheRepo,
+		sessionService,
+		auditService,
+		a.config.Security.AccountDeletionGrace,
+		a.logger,
 	)
 
 	a.logger.Info("All services initialized")
 
+	// ========== 注册后台任务 ==========
+	a.scheduler = scheduler.New(a.logger)
+	if a.config.Jobs.PlanReconcile.Enabled {
+		a.scheduler.Register("plan_reconcile", a.config.Jobs.PlanReconcile.Interval, func(ctx context.Context) error {
+			result, err := planService.ReconcileAllPlans(ctx, time.Now())
+			if result != nil && result.Checked > 0 {
+				a.logger.Info("Plans reconciled",
+					zap.Int("checked", result.Checked),
+					zap.Int("completed", result.Completed),
+					zap.Int("skipped", result.Skipped),
+				)
+			}
+			return err
+		})
+	}
+	a.scheduler.Register("account_export_cleanup", a.config.Export.CleanupInterval, accountDataService.CleanupExports)
+	if a.config.Jobs.AccountPurge.Enabled {
+		a.scheduler.Register("account_purge", a.config.Jobs.AccountPurge.Interval, accountDeletionService.PurgeDueAccounts)
+	}
+	if a.config.Jobs.LogCleanup.Enabled {
+		a.scheduler.Register("log_cleanup", a.config.Jobs.LogCleanup.Interval, func(ctx context.Context) error {
+			if days := a.config.Jobs.LogCleanup.APILogRetentionDays; days > 0 {
+				deleted, err := apiLogRepo.CleanupOldLogs(ctx, days)
+				if err != nil {
+					return err
+				}
+				if deleted > 0 {
+					a.logger.Info("Old API logs deleted", zap.Int64("deleted", deleted))
+				}
+			}
+			return auditService.CleanupLogs(ctx)
+		})
+	}
+
 	// ========== 创建所有 Handler 实例 ==========
 	authHandler := handler.NewAuthHandler(authService)
-	foodHandler := handler.NewFoodHandler(foodService)
+	foodHandler := handler.NewFoodHandler(foodService, foodSearchService, foodSuggestionService)
 	mealHandler := handler.NewMealHandler(mealService)
 	planHandler := handler.NewPlanHandler(planService)
 	aiHandler := handler.NewAIHandler(aiService)
-	nutritionHandler := handler.NewNutritionHandler(nutritionService, userPrefsRepo)
-	dashboardHandler := handler.NewDashboardHandler(dashboardService, userPrefsRepo)
+	nutritionHandler := handler.NewNutritionHandler(nutritionService, goalService)
+	dashboardHandler := handler.NewDashboardHandler(dashboardService, goalService)
 	settingsHandler := handler.NewSettingsHandler(settingsService)
 	conversationHandler := handler.NewConversationHandler(conversationService)
 	messageHandler := handler.NewMessageHandler(messageProxyService)
+	fastingHandler := handler.NewFastingHandler(fastingService)
+	bodyMetricHandler := handler.NewBodyMetricHandler(bodyMetricService)
+	energyHandler := handler.NewEnergyHandler(energyService)
+	goalHandler := handler.NewGoalHandler(goalService)
+	activityHandler := handler.NewActivityHandler(activityService)
+	hydrationHandler := handler.NewHydrationHandler(hydrationService)
+	foodCategoryHandler := handler.NewFoodCategoryHandler(foodCategoryService)
+	adminHandler := handler.NewAdminHandler(userAdminService)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorService)
+	sessionHandler := handler.NewSessionHandler(sessionService)
+	apiTokenHandler := handler.NewAPITokenHandler(apiTokenService)
+	emailHandler := handler.NewEmailHandler(emailService)
+	oidcHandler := handler.NewOIDCHandler(oidcService)
+	accountDataHandler := handler.NewAccountDataHandler(accountDataService)
+	accountHandler := handler.NewAccountHandler(accountDeletionService)
+	auditHandler := handler.NewAuditHandler(auditService)
 
 	a.logger.Info("All handlers initialized")
 
@@ -186,10 +379,26 @@ func (a *App) initDependencies() error {
 		Settings:     settingsHandler,
 		Conversation: conversationHandler,
 		Message:      messageHandler,
+		Fasting:      fastingHandler,
+		BodyMetric:   bodyMetricHandler,
+		Energy:       energyHandler,
+		Goal:         goalHandler,
+		Activity:     activityHandler,
+		Hydration:    hydrationHandler,
+		FoodCategory: foodCategoryHandler,
+		Admin:        adminHandler,
+		TwoFactor:    twoFactorHandler,
+		Session:      sessionHandler,
+		APIToken:     apiTokenHandler,
+		Email:        emailHandler,
+		OIDC:         oidcHandler,
+		AccountData:  accountDataHandler,
+		Account:      accountHandler,
+		Audit:        auditHandler,
 	}
 
 	// ========== 设置路由 ==========
-	a.router = router.SetupRouter(a.config, a.logger, jwtService, authService, handlers, userRepo)
+	a.router = router.SetupRouter(a.config, a.logger, jwtService, authService, apiTokenService, handlers, userRepo, userPrefsRepo, apiLogRepo)
 	a.logger.Info("Router initialized successfully")
 
 	return nil
@@ -205,6 +414,9 @@ func (a *App) Run() error {
 		WriteTimeout: a.config.Server.WriteTimeout,
 	}
 
+	// 启动后台任务
+	a.scheduler.Start()
+
 	// 启动服务器（在 goroutine 中）
 	go func() {
 		a.logger.Info("Starting HTTP server",
@@ -244,6 +456,14 @@ func (a *App) Shutdown() error {
 		a.logger.Info("HTTP server stopped")
 	}
 
+	// 停止后台任务（需在关闭数据库之前）
+	if a.scheduler != nil {
+		a.logger.Info("Stopping background jobs...")
+		if err := a.scheduler.Stop(ctx); err != nil {
+			a.logger.Error("Background jobs stop error", zap.Error(err))
+		}
+	}
+
 	// 关闭数据库连接
 	if a.db != nil {
 		a.logger.Info("Closing database connection...")
@@ -289,6 +509,14 @@ func (a *App) ensureDefaultUser() error {
 		return fmt.Errorf("default user username or password is empty")
 	}
 
+	// 默认用户的密码来自配置文件，不符合密码策略时只提示，避免升级后无法启动
+	if err := a.passwordPolicy.Validate(password, username); err != nil {
+		a.logger.Warn("Default user password does not meet the password policy, please change it",
+			zap.String("username", username),
+			zap.Error(err),
+		)
+	}
+
 	ctx := context.Background()
 	userRepo := repository.NewUserRepository(a.db)
 
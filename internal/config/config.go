@@ -16,6 +16,10 @@ type Config struct {
 	AI         AIConfig         `mapstructure:"ai"`
 	Security   SecurityConfig   `mapstructure:"security"`
 	Upload     UploadConfig     `mapstructure:"upload"`
+	Jobs       JobsConfig       `mapstructure:"jobs"`
+	Mail       MailConfig       `mapstructure:"mail"`
+	Export     ExportConfig     `mapstructure:"export"`
+	OIDC       OIDCConfig       `mapstructure:"oidc"`
 }
 
 // ServerConfig 服务器配置
@@ -95,13 +99,15 @@ type AIConfig struct {
 
 // SecurityConfig 安全配置
 type SecurityConfig struct {
-	MaxLoginAttempts   int               `mapstructure:"max_login_attempts"`
-	LockoutDuration    time.Duration     `mapstructure:"lockout_duration"`
-	PasswordMinLength  int               `mapstructure:"password_min_length"`
-	RequireSpecialChar bool              `mapstructure:"require_special_char"`
-	RequireNumber      bool              `mapstructure:"require_number"`
-	RequireUppercase   bool              `mapstructure:"require_uppercase"`
-	DefaultUser        DefaultUserConfig `mapstructure:"default_user"`
+	MaxLoginAttempts     int               `mapstructure:"max_login_attempts"`
+	LockoutDuration      time.Duration     `mapstructure:"lockout_duration"`
+	PasswordMinLength    int               `mapstructure:"password_min_length"`
+	RequireSpecialChar   bool              `mapstructure:"require_special_char"`
+	RequireNumber        bool              `mapstructure:"require_number"`
+	RequireUppercase     bool              `mapstructure:"require_uppercase"`
+	PasswordDenylistFile string            `mapstructure:"password_denylist_file"` // 常见密码列表文件，每行一个，为空表示不检查
+	AccountDeletionGrace time.Duration     `mapstructure:"account_deletion_grace"` // 用户申请删除账户后到永久删除的宽限期，期间登录即取消删除
+	DefaultUser          DefaultUserConfig `mapstructure:"default_user"`
 }
 
 // DefaultUserConfig 默认用户配置
@@ -117,3 +123,73 @@ type UploadConfig struct {
 	AllowedTypes []string `mapstructure:"allowed_types"`
 	UploadPath   string   `mapstructure:"upload_path"`
 }
+
+// JobsConfig 后台任务配置
+type JobsConfig struct {
+	PlanReconcile PlanReconcileJobConfig `mapstructure:"plan_reconcile"`
+	AccountPurge  AccountPurgeJobConfig  `mapstructure:"account_purge"`
+	LogCleanup    LogCleanupJobConfig    `mapstructure:"log_cleanup"`
+}
+
+// PlanReconcileJobConfig 计划状态对账任务配置
+type PlanReconcileJobConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	Interval    time.Duration `mapstructure:"interval"`     // 任务检查间隔
+	DefaultTime string        `mapstructure:"default_time"` // 用户未设置时的每日对账时间（HH:MM）
+}
+
+// AccountPurgeJobConfig 永久删除宽限期已过的账户的任务配置
+type AccountPurgeJobConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Interval time.Duration `mapstructure:"interval"` // 任务检查间隔
+}
+
+// LogCleanupJobConfig 删除过期的 API 日志和审计日志的任务配置
+type LogCleanupJobConfig struct {
+	Enabled               bool          `mapstructure:"enabled"`
+	Interval              time.Duration `mapstructure:"interval"`                 // 任务检查间隔
+	APILogRetentionDays   int           `mapstructure:"api_log_retention_days"`   // API 日志保留天数，0 表示永久保留
+	AuditLogRetentionDays int           `mapstructure:"audit_log_retention_days"` // 审计日志保留天数，0 表示永久保留
+}
+
+// MailConfig 邮件配置
+type MailConfig struct {
+	Driver           string        `mapstructure:"driver"`             // "file"（写入本地文件，用于开发测试）或 "smtp"
+	From             string        `mapstructure:"from"`               // 发件人，如 "AI Diet Assistant <no-reply@example.com>"
+	AppURL           string        `mapstructure:"app_url"`            // 前端地址，用于生成邮件中的链接
+	FilePath         string        `mapstructure:"file_path"`          // file 驱动写入的文件
+	VerificationTTL  time.Duration `mapstructure:"verification_ttl"`   // 邮箱验证链接有效期
+	PasswordResetTTL time.Duration `mapstructure:"password_reset_ttl"` // 密码重置链接有效期
+	SMTP             SMTPConfig    `mapstructure:"smtp"`
+}
+
+// SMTPConfig SMTP 配置
+type SMTPConfig struct {
+	Host       string        `mapstructure:"host"`
+	Port       int           `mapstructure:"port"`
+	Username   string        `mapstructure:"username"`
+	Password   string        `mapstructure:"password"`
+	Encryption string        `mapstructure:"encryption"` // "starttls"、"tls" 或 "none"
+	Timeout    time.Duration `mapstructure:"timeout"`
+}
+
+// ExportConfig 账户数据导出配置
+type ExportConfig struct {
+	Path            string        `mapstructure:"path"`             // 导出文件保存目录
+	AsyncThreshold  int           `mapstructure:"async_threshold"`  // 记录数超过该值时在后台生成，否则在请求中直接生成
+	TTL             time.Duration `mapstructure:"ttl"`              // 导出文件保留时间
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"` // 清理过期导出文件的间隔
+}
+
+// OIDCConfig OpenID Connect 单点登录配置
+type OIDCConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	ProviderName string        `mapstructure:"provider_name"` // 登录页按钮上显示的身份提供方名称
+	Issuer       string        `mapstructure:"issuer"`        // 签发方地址，用于获取 /.well-known/openid-configuration
+	ClientID     string        `mapstructure:"client_id"`
+	ClientSecret string        `mapstructure:"client_secret"` // 公共客户端留空，仅使用 PKCE
+	RedirectURL  string        `mapstructure:"redirect_url"`  // 前端回调页面地址，需在身份提供方登记
+	Scopes       []string      `mapstructure:"scopes"`
+	StateTTL     time.Duration `mapstructure:"state_ttl"`    // 发起登录到回调完成的最长时间
+	HTTPTimeout  time.Duration `mapstructure:"http_timeout"` // 请求身份提供方的超时时间
+}
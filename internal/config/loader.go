@@ -2,8 +2,12 @@ package config
 
 import (
 	"fmt"
+	"net/mail"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -29,6 +33,9 @@ func Load(configPath string) (*Config, error) {
 	v.SetEnvPrefix("APP")
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
+	// 设置默认值
+	setDefaults(v)
+
 	// 读取配置文件
 	if err := v.ReadInConfig(); err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
@@ -57,6 +64,50 @@ func Get() *Config {
 	return globalConfig
 }
 
+// setDefaults 设置可选配置项的默认值
+func setDefaults(v *viper.Viper) {
+	// 密码策略
+	v.SetDefault("security.password_min_length", 8)
+
+	// 账户删除宽限期
+	v.SetDefault("security.account_deletion_grace", "720h")
+
+	// 后台任务
+	v.SetDefault("jobs.plan_reconcile.enabled", true)
+	v.SetDefault("jobs.plan_reconcile.interval", "15m")
+	v.SetDefault("jobs.plan_reconcile.default_time", "23:30")
+	v.SetDefault("jobs.account_purge.enabled", true)
+	v.SetDefault("jobs.account_purge.interval", "1h")
+	v.SetDefault("jobs.log_cleanup.enabled", true)
+	v.SetDefault("jobs.log_cleanup.interval", "24h")
+	v.SetDefault("jobs.log_cleanup.api_log_retention_days", 90)
+	v.SetDefault("jobs.log_cleanup.audit_log_retention_days", 365)
+
+	// 邮件
+	v.SetDefault("mail.driver", "file")
+	v.SetDefault("mail.from", "AI Diet Assistant <no-reply@localhost>")
+	v.SetDefault("mail.app_url", "http://localhost:3000")
+	v.SetDefault("mail.file_path", "logs/mail.log")
+	v.SetDefault("mail.verification_ttl", "24h")
+	v.SetDefault("mail.password_reset_ttl", "1h")
+	v.SetDefault("mail.smtp.port", 587)
+	v.SetDefault("mail.smtp.encryption", "starttls")
+	v.SetDefault("mail.smtp.timeout", "30s")
+
+	// 账户数据导出
+	v.SetDefault("export.path", "exports/")
+	v.SetDefault("export.async_threshold", 5000)
+	v.SetDefault("export.ttl", "24h")
+	v.SetDefault("export.cleanup_interval", "1h")
+
+	// OpenID Connect 单点登录
+	v.SetDefault("oidc.enabled", false)
+	v.SetDefault("oidc.provider_name", "SSO")
+	v.SetDefault("oidc.scopes", []string{"openid", "profile", "email"})
+	v.SetDefault("oidc.state_ttl", "10m")
+	v.SetDefault("oidc.http_timeout", "10s")
+}
+
 // validateConfig 验证配置
 func validateConfig(cfg *Config) error {
 	// 验证服务器配置
@@ -91,6 +142,52 @@ func validateConfig(cfg *Config) error {
 		return fmt.Errorf("aes key must be exactly 32 bytes")
 	}
 
+	// 验证密码策略配置（bcrypt 只使用前 72 字节）
+	if cfg.Security.PasswordMinLength < 1 || cfg.Security.PasswordMinLength > 72 {
+		return fmt.Errorf("password min length must be between 1 and 72, got %d", cfg.Security.PasswordMinLength)
+	}
+	if cfg.Security.AccountDeletionGrace < 0 {
+		return fmt.Errorf("account deletion grace period must not be negative")
+	}
+
+	// 验证后台任务配置
+	if cfg.Jobs.PlanReconcile.Enabled && cfg.Jobs.PlanReconcile.Interval <= 0 {
+		return fmt.Errorf("plan reconcile job interval must be positive")
+	}
+	if _, err := time.Parse("15:04", cfg.Jobs.PlanReconcile.DefaultTime); err != nil {
+		return fmt.Errorf("invalid plan reconcile default time %q, expected HH:MM", cfg.Jobs.PlanReconcile.DefaultTime)
+	}
+	if cfg.Jobs.AccountPurge.Enabled && cfg.Jobs.AccountPurge.Interval <= 0 {
+		return fmt.Errorf("account purge job interval must be positive")
+	}
+	if cfg.Jobs.LogCleanup.Enabled && cfg.Jobs.LogCleanup.Interval <= 0 {
+		return fmt.Errorf("log cleanup job interval must be positive")
+	}
+	if cfg.Jobs.LogCleanup.APILogRetentionDays < 0 || cfg.Jobs.LogCleanup.AuditLogRetentionDays < 0 {
+		return fmt.Errorf("log retention days must not be negative")
+	}
+
+	// 验证邮件配置
+	if err := validateMailConfig(&cfg.Mail); err != nil {
+		return err
+	}
+
+	// 验证账户数据导出配置
+	if cfg.Export.Path == "" {
+		return fmt.Errorf("export path is required")
+	}
+	if cfg.Export.AsyncThreshold < 0 {
+		return fmt.Errorf("export async threshold must not be negative")
+	}
+	if cfg.Export.TTL <= 0 || cfg.Export.CleanupInterval <= 0 {
+		return fmt.Errorf("export ttl and cleanup interval must be positive")
+	}
+
+	// 验证 OpenID Connect 配置
+	if err := validateOIDCConfig(&cfg.OIDC); err != nil {
+		return err
+	}
+
 	// 验证 AI 配置（在测试环境下可选）
 	// AI API key 可以稍后通过 Web UI 配置
 	// if cfg.AI.APIKey == "" {
@@ -105,10 +202,82 @@ func validateConfig(cfg *Config) error {
 	return nil
 }
 
+// validateMailConfig 验证邮件配置
+func validateMailConfig(cfg *MailConfig) error {
+	switch cfg.Driver {
+	case "file":
+		if cfg.FilePath == "" {
+			return fmt.Errorf("mail file path is required for the file driver")
+		}
+	case "smtp":
+		if cfg.SMTP.Host == "" {
+			return fmt.Errorf("smtp host is required for the smtp driver")
+		}
+		if cfg.SMTP.Port <= 0 || cfg.SMTP.Port > 65535 {
+			return fmt.Errorf("invalid smtp port: %d", cfg.SMTP.Port)
+		}
+		switch cfg.SMTP.Encryption {
+		case "starttls", "tls", "none":
+		default:
+			return fmt.Errorf("invalid smtp encryption %q, expected starttls, tls or none", cfg.SMTP.Encryption)
+		}
+	default:
+		return fmt.Errorf("invalid mail driver %q, expected file or smtp", cfg.Driver)
+	}
+
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return fmt.Errorf("invalid mail from address %q: %w", cfg.From, err)
+	}
+	if cfg.AppURL == "" {
+		return fmt.Errorf("mail app url is required")
+	}
+	if cfg.VerificationTTL <= 0 || cfg.PasswordResetTTL <= 0 {
+		return fmt.Errorf("mail token ttl must be positive")
+	}
+
+	return nil
+}
+
+// validateOIDCConfig 验证 OpenID Connect 配置，未启用时不检查
+func validateOIDCConfig(cfg *OIDCConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	issuer, err := url.Parse(cfg.Issuer)
+	if err != nil || issuer.Host == "" || (issuer.Scheme != "https" && issuer.Scheme != "http") {
+		return fmt.Errorf("invalid oidc issuer %q", cfg.Issuer)
+	}
+	if cfg.ClientID == "" {
+		return fmt.Errorf("oidc client id is required")
+	}
+	if cfg.RedirectURL == "" {
+		return fmt.Errorf("oidc redirect url is required")
+	}
+
+	hasOpenID := false
+	for _, scope := range cfg.Scopes {
+		if scope == "openid" {
+			hasOpenID = true
+			break
+		}
+	}
+	if !hasOpenID {
+		return fmt.Errorf("oidc scopes must include openid")
+	}
+
+	if cfg.StateTTL <= 0 || cfg.HTTPTimeout <= 0 {
+		return fmt.Errorf("oidc state ttl and http timeout must be positive")
+	}
+
+	return nil
+}
+
 // ensureDirectories 确保必要的目录存在
 func ensureDirectories(cfg *Config) error {
 	dirs := []string{
 		cfg.Upload.UploadPath,
+		cfg.Export.Path,
 	}
 
 	// 如果日志输出到文件，确保日志目录存在
@@ -121,6 +290,13 @@ func ensureDirectories(cfg *Config) error {
 		}
 	}
 
+	// 使用 file 邮件驱动时，确保邮件文件所在目录存在
+	if cfg.Mail.Driver == "file" {
+		if dir := filepath.Dir(cfg.Mail.FilePath); dir != "." {
+			dirs = append(dirs, dir)
+		}
+	}
+
 	for _, dir := range dirs {
 		if dir == "" {
 			continue
@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/middleware"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/service"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// accountImportMaxSize 导入文件的最大大小，与全局请求体大小限制一致
+const accountImportMaxSize = 10 << 20
+
+// AccountDataHandler 账户数据导出和导入处理器
+type AccountDataHandler struct {
+	accountDataService service.AccountDataService
+}
+
+// NewAccountDataHandler 创建账户数据导出和导入处理器实例
+func NewAccountDataHandler(accountDataService service.AccountDataService) *AccountDataHandler {
+	return &AccountDataHandler{
+		accountDataService: accountDataService,
+	}
+}
+
+// CreateExport 创建账户数据导出
+// @Summary 创建账户数据导出
+// @Description 导出用户资料、偏好、食材、餐饮记录、饮食计划、AI 设置（不含 API 密钥）、对话流和消息。数据量较小时直接生成（status 为 completed），否则在后台生成（status 为 pending），需要轮询导出状态；同时只能有一个正在生成的导出
+// @Tags 设置
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.CreateAccountExportRequest false "导出格式"
+// @Success 200 {object} utils.Response{data=model.AccountExport}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /api/v1/user/exports [post]
+func (h *AccountDataHandler) CreateExport(c *gin.Context) {
+	var req model.CreateAccountExportRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+			return
+		}
+	}
+
+	export, err := h.accountDataService.CreateExport(c.Request.Context(), middleware.MustGetUserID(c), req.Format)
+	if err != nil {
+		if errors.Is(err, service.ErrAccountExportInProgress) {
+			utils.Error(c, utils.NewAppError(utils.CodeConflict, "an account export is already in progress", err))
+			return
+		}
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to create account export", err))
+		return
+	}
+
+	if export.Status == model.AccountExportStatusCompleted {
+		utils.SuccessWithMessage(c, "account export is ready", export)
+		return
+	}
+	utils.SuccessWithMessage(c, "account export is being generated", export)
+}
+
+// ListExports 获取账户数据导出列表
+// @Summary 获取账户数据导出列表
+// @Description 获取当前用户未过期的账户数据导出，最新的在前
+// @Tags 设置
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]model.AccountExport}
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/user/exports [get]
+func (h *AccountDataHandler) ListExports(c *gin.Context) {
+	exports, err := h.accountDataService.ListExports(c.Request.Context(), middleware.MustGetUserID(c))
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to list account exports", err))
+		return
+	}
+
+	utils.Success(c, exports)
+}
+
+// GetExport 获取账户数据导出状态
+// @Summary 获取账户数据导出状态
+// @Description 获取账户数据导出的生成状态，status 为 completed 时可以下载
+// @Tags 设置
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "导出 ID"
+// @Success 200 {object} utils.Response{data=model.AccountExport}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/user/exports/{id} [get]
+func (h *AccountDataHandler) GetExport(c *gin.Context) {
+	exportID, ok := parseExportID(c)
+	if !ok {
+		return
+	}
+
+	export, err := h.accountDataService.GetExport(c.Request.Context(), middleware.MustGetUserID(c), exportID)
+	if err != nil {
+		if errors.Is(err, service.ErrAccountExportNotFound) {
+			utils.Error(c, utils.NewAppError(utils.CodeNotFound, "account export not found", err))
+			return
+		}
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to get account export", err))
+		return
+	}
+
+	utils.Success(c, export)
+}
+
+// DownloadExport 下载账户数据导出文件
+// @Summary 下载账户数据导出文件
+// @Description 下载已生成的账户数据归档（JSON 或包含 account.json 的 ZIP）
+// @Tags 设置
+// @Produce json
+// @Produce application/zip
+// @Security BearerAuth
+// @Param id path int true "导出 ID"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /api/v1/user/exports/{id}/download [get]
+func (h *AccountDataHandler) DownloadExport(c *gin.Context) {
+	exportID, ok := parseExportID(c)
+	if !ok {
+		return
+	}
+
+	export, path, err := h.accountDataService.GetExportFile(c.Request.Context(), middleware.MustGetUserID(c), exportID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAccountExportNotFound):
+			utils.Error(c, utils.NewAppError(utils.CodeNotFound, "account export not found", err))
+		case errors.Is(err, service.ErrAccountExportNotReady):
+			utils.Error(c, utils.NewAppError(utils.CodeConflict, "account export is not ready", err))
+		default:
+			utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to download account export", err))
+		}
+		return
+	}
+
+	fileName := fmt.Sprintf("account_export_%s.%s", export.CreatedAt.Format("20060102_150405"), export.Format)
+	c.FileAttachment(path, fileName)
+}
+
+// ImportAccount 导入账户数据
+// @Summary 导入账户数据
+// @Description 上传账户数据导出的 JSON 或 ZIP 归档，将数据恢复到当前账户。当前账户不能已有食材、餐饮记录、计划、AI 设置或对话流；记录重新分配 ID，餐饮记录和计划中的食材引用按新 ID 更新。用户名和邮箱不会被修改，AI 设置导入后需要重新填写 API 密钥
+// @Tags 设置
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "账户数据归档"
+// @Success 200 {object} utils.Response{data=model.AccountImportResult}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /api/v1/user/import [post]
+func (h *AccountDataHandler) ImportAccount(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "archive file is required", err))
+		return
+	}
+	if fileHeader.Size > accountImportMaxSize {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "archive file is too large", nil))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "failed to read archive file", err))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, accountImportMaxSize))
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "failed to read archive file", err))
+		return
+	}
+
+	result, err := h.accountDataService.ImportAccount(c.Request.Context(), middleware.MustGetUserID(c), data)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidAccountArchive):
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, err.Error(), err))
+		case errors.Is(err, service.ErrUnsupportedArchiveVersion):
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "unsupported account archive version", err))
+		case errors.Is(err, service.ErrAccountNotEmpty):
+			utils.Error(c, utils.NewAppError(utils.CodeConflict, "account already has data, import into a new or empty account", err))
+		default:
+			utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to import account data", err))
+		}
+		return
+	}
+
+	utils.SuccessWithMessage(c, "account data imported successfully", result)
+}
+
+// parseExportID 解析路径中的导出 ID，无效时写入错误响应
+func parseExportID(c *gin.Context) (int64, bool) {
+	exportID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || exportID <= 0 {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid export id", err))
+		return 0, false
+	}
+	return exportID, true
+}
+
+// RegisterRoutes 注册账户数据导出和导入路由（需要认证）
+func (h *AccountDataHandler) RegisterRoutes(router *gin.RouterGroup) {
+	user := router.Group("/user")
+	{
+		user.POST("/exports", h.CreateExport)
+		user.GET("/exports", h.ListExports)
+		user.GET("/exports/:id", h.GetExport)
+		user.GET("/exports/:id/download", h.DownloadExport)
+		user.POST("/import", h.ImportAccount)
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/middleware"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/service"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// AccountHandler 账户处理器
+type AccountHandler struct {
+	accountDeletionService service.AccountDeletionService
+}
+
+// NewAccountHandler 创建账户处理器实例
+func NewAccountHandler(accountDeletionService service.AccountDeletionService) *AccountHandler {
+	return &AccountHandler{
+		accountDeletionService: accountDeletionService,
+	}
+}
+
+// DeleteAccount 申请删除账户
+// @Summary 申请删除账户
+// @Description 验证密码后申请删除当前账户并撤销所有登录会话。账户在宽限期内保留，期间重新登录即取消删除；宽限期过后账户及其所有数据被永久删除
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.DeleteAccountRequest true "申请删除账户请求"
+// @Success 200 {object} utils.Response{data=model.AccountDeletion}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /api/v1/user/account [delete]
+func (h *AccountHandler) DeleteAccount(c *gin.Context) {
+	var req model.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	deletion, err := h.accountDeletionService.RequestDeletion(c.Request.Context(), middleware.MustGetUserID(c), req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrInvalidPassword):
+			utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "password is incorrect", err))
+		case errors.Is(err, service.ErrLastAdmin):
+			utils.Error(c, utils.NewAppError(utils.CodeForbidden, err.Error(), err))
+		case errors.Is(err, service.ErrUserNotFound):
+			utils.Error(c, utils.NewAppError(utils.CodeNotFound, "user not found", err))
+		default:
+			utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to delete account", err))
+		}
+		return
+	}
+
+	utils.SuccessWithMessage(c, "account scheduled for deletion, login again before the scheduled time to cancel", deletion)
+}
+
+// RegisterRoutes 注册账户删除路由（需要认证）
+func (h *AccountHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.DELETE("/user/account", h.DeleteAccount)
+}
@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/middleware"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/service"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ActivityHandler handles activity logging HTTP requests
+type ActivityHandler struct {
+	activityService *service.ActivityService
+}
+
+// NewActivityHandler creates a new ActivityHandler instance
+func NewActivityHandler(activityService *service.ActivityService) *ActivityHandler {
+	return &ActivityHandler{
+		activityService: activityService,
+	}
+}
+
+// CreateActivity handles POST /api/v1/activities
+// @Summary Log an activity
+// @Description Log a workout or activity. Calories burned are estimated from the MET value and body weight when omitted.
+// @Tags activities
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.ActivityRequest true "Activity"
+// @Success 200 {object} utils.Response{data=model.Activity}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/activities [post]
+func (h *ActivityHandler) CreateActivity(c *gin.Context) {
+	var req model.ActivityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	// performed_on is a calendar date in the user's timezone
+	req.PerformedOn = utils.DateInLocation(req.PerformedOn, middleware.GetUserLocation(c))
+
+	activity, err := h.activityService.CreateActivity(userID.(int64), &req)
+	if err != nil {
+		h.handleWriteError(c, err, "failed to create activity")
+		return
+	}
+
+	utils.Success(c, activity)
+}
+
+// UpdateActivity handles PUT /api/v1/activities/:id
+// @Summary Update an activity
+// @Description Replace an activity. Calories burned are re-estimated when omitted.
+// @Tags activities
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Activity ID"
+// @Param request body model.ActivityRequest true "Activity"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/activities/{id} [put]
+func (h *ActivityHandler) UpdateActivity(c *gin.Context) {
+	activityID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid activity id", err))
+		return
+	}
+
+	var req model.ActivityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	req.PerformedOn = utils.DateInLocation(req.PerformedOn, middleware.GetUserLocation(c))
+
+	if err := h.activityService.UpdateActivity(userID.(int64), activityID, &req); err != nil {
+		h.handleWriteError(c, err, "failed to update activity")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "activity updated successfully", nil)
+}
+
+// DeleteActivity handles DELETE /api/v1/activities/:id
+// @Summary Delete an activity
+// @Description Delete an activity
+// @Tags activities
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Activity ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/activities/{id} [delete]
+func (h *ActivityHandler) DeleteActivity(c *gin.Context) {
+	activityID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid activity id", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	if err := h.activityService.DeleteActivity(userID.(int64), activityID); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeNotFound, "activity not found", err))
+		return
+	}
+
+	utils.SuccessWithMessage(c, "activity deleted successfully", nil)
+}
+
+// GetActivity handles GET /api/v1/activities/:id
+// @Summary Get an activity
+// @Description Get an activity
+// @Tags activities
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Activity ID"
+// @Success 200 {object} utils.Response{data=model.Activity}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/activities/{id} [get]
+func (h *ActivityHandler) GetActivity(c *gin.Context) {
+	activityID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid activity id", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	activity, err := h.activityService.GetActivity(userID.(int64), activityID)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeNotFound, "activity not found", err))
+		return
+	}
+
+	utils.Success(c, activity)
+}
+
+// ListActivities handles GET /api/v1/activities
+// @Summary List activities
+// @Description List activities in a date range, oldest first
+// @Tags activities
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string false "Start date (YYYY-MM-DD, default: 27 days before end_date)"
+// @Param end_date query string false "End date (YYYY-MM-DD, default: today)"
+// @Success 200 {object} utils.Response{data=[]model.Activity}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/activities [get]
+func (h *ActivityHandler) ListActivities(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	startDate, endDate, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+
+	activities, err := h.activityService.ListActivities(userID.(int64), startDate, endDate)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to list activities", err))
+		return
+	}
+
+	utils.Success(c, activities)
+}
+
+// GetDailySummaries handles GET /api/v1/activities/daily
+// @Summary Get daily activity totals
+// @Description Get activity count, duration and calories burned for each day in a date range
+// @Tags activities
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string false "Start date (YYYY-MM-DD, default: 27 days before end_date)"
+// @Param end_date query string false "End date (YYYY-MM-DD, default: today)"
+// @Success 200 {object} utils.Response{data=[]model.DailyActivitySummary}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/activities/daily [get]
+func (h *ActivityHandler) GetDailySummaries(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	startDate, endDate, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+
+	summaries, err := h.activityService.GetDailySummaries(userID.(int64), startDate, endDate)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to get daily activity totals", err))
+		return
+	}
+
+	utils.Success(c, summaries)
+}
+
+// ListActivityTypes handles GET /api/v1/activities/types
+// @Summary List activity types
+// @Description List the built-in activity types and their MET values per intensity
+// @Tags activities
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]model.ActivityType}
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/activities/types [get]
+func (h *ActivityHandler) ListActivityTypes(c *gin.Context) {
+	utils.Success(c, model.ActivityTypes)
+}
+
+// handleWriteError maps create/update errors to responses
+func (h *ActivityHandler) handleWriteError(c *gin.Context, err error, message string) {
+	if errors.Is(err, service.ErrActivityWeightUnknown) {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, err.Error(), err))
+		return
+	}
+	utils.Error(c, utils.NewAppError(utils.CodeInternalError, message, err))
+}
+
+// RegisterRoutes registers activity routes
+func (h *ActivityHandler) RegisterRoutes(router *gin.RouterGroup) {
+	activities := router.Group("/activities")
+	{
+		activities.POST("", h.CreateActivity)
+		activities.GET("", h.ListActivities)
+		activities.GET("/types", h.ListActivityTypes)
+		activities.GET("/daily", h.GetDailySummaries)
+		activities.GET("/:id", h.GetActivity)
+		activities.PUT("/:id", h.UpdateActivity)
+		activities.DELETE("/:id", h.DeleteActivity)
+	}
+}
@@ -0,0 +1,380 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/middleware"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/service"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler 用户管理处理器（需要管理员权限）
+type AdminHandler struct {
+	userAdminService service.UserAdminService
+}
+
+// NewAdminHandler 创建用户管理处理器实例
+func NewAdminHandler(userAdminService service.UserAdminService) *AdminHandler {
+	return &AdminHandler{
+		userAdminService: userAdminService,
+	}
+}
+
+// AdminCreateUserRequest 管理员创建用户请求
+type AdminCreateUserRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=50,alphanum"`
+	Password string `json:"password" binding:"required,max=128"`
+	Email    string `json:"email,omitempty" binding:"omitempty,email,max=100"`
+	Role     string `json:"role,omitempty" binding:"omitempty,oneof=admin user"`
+}
+
+// ChangeRoleRequest 修改角色请求
+type ChangeRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=admin user"`
+}
+
+// ResetPasswordRequest 重置密码请求
+type ResetPasswordRequest struct {
+	NewPassword string `json:"new_password" binding:"required,max=128"`
+}
+
+// ChangeStatusRequest 修改用户状态请求
+type ChangeStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=active disabled"`
+}
+
+// ListUsers 获取用户列表
+// @Summary 获取用户列表
+// @Description 搜索和筛选用户（需要管理员权限）
+// @Tags 管理
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "按用户名或邮箱模糊搜索"
+// @Param role query string false "按角色筛选：admin 或 user"
+// @Param status query string false "按状态筛选：active 或 disabled"
+// @Param page query int false "页码（默认 1）"
+// @Param page_size query int false "每页数量（默认 20，最大 100）"
+// @Success 200 {object} utils.PaginatedResponse{data=[]model.UserSummary}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /api/v1/admin/users [get]
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	filter := &model.UserFilter{
+		Query:  strings.TrimSpace(c.Query("q")),
+		Role:   c.Query("role"),
+		Status: c.Query("status"),
+	}
+
+	if filter.Role != "" && filter.Role != model.RoleAdmin && filter.Role != model.RoleUser {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid role, must be admin or user", nil))
+		return
+	}
+
+	if filter.Status != "" && filter.Status != model.UserStatusActive && filter.Status != model.UserStatusDisabled {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid status, must be active or disabled", nil))
+		return
+	}
+
+	// 解析分页参数
+	filter.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	filter.PageSize, _ = strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	users, total, err := h.userAdminService.ListUsers(c.Request.Context(), filter)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to list users", err))
+		return
+	}
+
+	utils.SuccessWithPagination(c, users, utils.CalculatePagination(filter.Page, filter.PageSize, total))
+}
+
+// GetUser 获取用户详情
+// @Summary 获取用户详情
+// @Description 获取用户信息及数据使用概况（需要管理员权限）
+// @Tags 管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户 ID"
+// @Success 200 {object} utils.Response{data=model.UserSummary}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/admin/users/{id} [get]
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	summary, err := h.userAdminService.GetUser(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err, "failed to get user")
+		return
+	}
+
+	utils.Success(c, summary)
+}
+
+// CreateUser 创建用户
+// @Summary 创建用户
+// @Description 管理员创建用户，不受注册开关限制，密码需符合密码策略（需要管理员权限）
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body AdminCreateUserRequest true "创建用户请求"
+// @Success 200 {object} utils.Response{data=model.User}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /api/v1/admin/users [post]
+func (h *AdminHandler) CreateUser(c *gin.Context) {
+	var req AdminCreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	user, err := h.userAdminService.CreateUser(c.Request.Context(), req.Username, req.Password, req.Email, req.Role)
+	if err != nil {
+		h.handleError(c, err, "failed to create user")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "user created successfully", user)
+}
+
+// ChangeRole 修改用户角色
+// @Summary 修改用户角色
+// @Description 设置用户为管理员或普通用户，不能修改自己的角色（需要管理员权限）
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户 ID"
+// @Param request body ChangeRoleRequest true "修改角色请求"
+// @Success 200 {object} utils.Response{data=model.UserSummary}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/admin/users/{id}/role [put]
+func (h *AdminHandler) ChangeRole(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req ChangeRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	summary, err := h.userAdminService.ChangeRole(c.Request.Context(), middleware.MustGetUserID(c), userID, req.Role)
+	if err != nil {
+		h.handleError(c, err, "failed to change role")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "role changed successfully", summary)
+}
+
+// ResetPassword 重置用户密码
+// @Summary 重置用户密码
+// @Description 为用户设置新密码，新密码需符合密码策略；该用户已登录的会话全部失效（需要管理员权限）
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户 ID"
+// @Param request body ResetPasswordRequest true "重置密码请求"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/admin/users/{id}/password [put]
+func (h *AdminHandler) ResetPassword(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	if err := h.userAdminService.ResetPassword(c.Request.Context(), userID, req.NewPassword); err != nil {
+		h.handleError(c, err, "failed to reset password")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "password reset successfully", nil)
+}
+
+// ChangeStatus 停用或启用用户
+// @Summary 停用或启用用户
+// @Description 停用的用户无法登录，已签发的令牌立即失效；不能停用自己（需要管理员权限）
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户 ID"
+// @Param request body ChangeStatusRequest true "修改状态请求"
+// @Success 200 {object} utils.Response{data=model.UserSummary}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/admin/users/{id}/status [put]
+func (h *AdminHandler) ChangeStatus(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req ChangeStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	disabled := req.Status == model.UserStatusDisabled
+	summary, err := h.userAdminService.SetDisabled(c.Request.Context(), middleware.MustGetUserID(c), userID, disabled)
+	if err != nil {
+		h.handleError(c, err, "failed to change user status")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "user status changed successfully", summary)
+}
+
+// DeleteUser 删除用户
+// @Summary 删除用户
+// @Description 永久删除用户及其所有数据（食材、餐饮记录、计划、对话等），不能删除自己（需要管理员权限）
+// @Tags 管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户 ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/admin/users/{id} [delete]
+func (h *AdminHandler) DeleteUser(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.userAdminService.DeleteUser(c.Request.Context(), middleware.MustGetUserID(c), userID); err != nil {
+		h.handleError(c, err, "failed to delete user")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "user deleted successfully", nil)
+}
+
+// ListPendingDeletions 获取待删除的账户
+// @Summary 获取待删除的账户
+// @Description 获取已申请删除账户、仍在宽限期内的用户，按计划删除时间排序，最先到期的在前（需要管理员权限）
+// @Tags 管理
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]model.UserSummary}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /api/v1/admin/users/pending-deletions [get]
+func (h *AdminHandler) ListPendingDeletions(c *gin.Context) {
+	users, err := h.userAdminService.ListPendingDeletions(c.Request.Context())
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to list pending deletions", err))
+		return
+	}
+
+	utils.Success(c, users)
+}
+
+// ResetTwoFactor 重置用户的两步验证
+// @Summary 重置用户的两步验证
+// @Description 删除用户的 TOTP 密钥和恢复码，用于用户丢失身份验证器且没有恢复码的情况（需要管理员权限）
+// @Tags 管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户 ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/admin/users/{id}/2fa [delete]
+func (h *AdminHandler) ResetTwoFactor(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.userAdminService.ResetTwoFactor(c.Request.Context(), userID); err != nil {
+		h.handleError(c, err, "failed to reset two-factor authentication")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "two-factor authentication reset successfully", nil)
+}
+
+// handleError 将用户管理服务的错误映射为响应
+func (h *AdminHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		utils.Error(c, utils.NewAppError(utils.CodeNotFound, "user not found", err))
+	case errors.Is(err, service.ErrPasswordPolicy):
+		utils.Error(c, passwordPolicyAppError(err))
+	case errors.Is(err, service.ErrUsernameExists):
+		utils.Error(c, utils.NewAppError(utils.CodeConflict, "username already exists", err))
+	case errors.Is(err, service.ErrCannotModifySelf):
+		utils.Error(c, utils.NewAppError(utils.CodeForbidden, err.Error(), err))
+	case errors.Is(err, service.ErrInvalidRole):
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, err.Error(), err))
+	default:
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, message, err))
+	}
+}
+
+// parseUserID 解析路径中的用户 ID，无效时返回错误响应
+func parseUserID(c *gin.Context) (int64, bool) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || userID <= 0 {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid user id", err))
+		return 0, false
+	}
+	return userID, true
+}
+
+// RegisterRoutes 注册用户管理路由，所有路由都需要管理员权限
+func (h *AdminHandler) RegisterRoutes(router *gin.RouterGroup, userRepo repository.UserRepository) {
+	users := router.Group("/admin/users")
+	users.Use(middleware.AdminMiddleware(userRepo))
+	{
+		users.GET("", h.ListUsers)
+		users.POST("", h.CreateUser)
+		users.GET("/pending-deletions", h.ListPendingDeletions)
+		users.GET("/:id", h.GetUser)
+		users.PUT("/:id/role", h.ChangeRole)
+		users.PUT("/:id/password", h.ResetPassword)
+		users.PUT("/:id/status", h.ChangeStatus)
+		users.DELETE("/:id", h.DeleteUser)
+		users.DELETE("/:id/2fa", h.ResetTwoFactor)
+	}
+}
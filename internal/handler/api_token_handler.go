@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/middleware"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/service"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// APITokenHandler 个人访问令牌处理器
+type APITokenHandler struct {
+	apiTokenService service.APITokenService
+}
+
+// NewAPITokenHandler 创建个人访问令牌处理器实例
+func NewAPITokenHandler(apiTokenService service.APITokenService) *APITokenHandler {
+	return &APITokenHandler{
+		apiTokenService: apiTokenService,
+	}
+}
+
+// CreateAPITokenRequest 创建个人访问令牌请求
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays *int     `json:"expires_in_days" binding:"omitempty,min=1,max=365"` // 为空表示永不过期
+}
+
+// ListTokens 获取个人访问令牌列表
+// @Summary 获取个人访问令牌列表
+// @Description 获取当前用户创建的所有个人访问令牌，不包含令牌明文
+// @Tags 设置
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]model.APIToken}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /api/v1/settings/api-tokens [get]
+func (h *APITokenHandler) ListTokens(c *gin.Context) {
+	tokens, err := h.apiTokenService.ListTokens(c.Request.Context(), middleware.MustGetUserID(c))
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to list api tokens", err))
+		return
+	}
+
+	utils.Success(c, tokens)
+}
+
+// CreateToken 创建个人访问令牌
+// @Summary 创建个人访问令牌
+// @Description 创建带名称、权限范围和可选有效期的个人访问令牌，令牌明文只在本次响应中返回
+// @Tags 设置
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateAPITokenRequest true "创建令牌请求"
+// @Success 200 {object} utils.Response{data=model.APITokenCreated}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /api/v1/settings/api-tokens [post]
+func (h *APITokenHandler) CreateToken(c *gin.Context) {
+	var req CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	expiresInDays := 0
+	if req.ExpiresInDays != nil {
+		expiresInDays = *req.ExpiresInDays
+	}
+
+	token, err := h.apiTokenService.CreateToken(c.Request.Context(), middleware.MustGetUserID(c), req.Name, req.Scopes, expiresInDays)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidAPITokenScope), errors.Is(err, service.ErrInvalidAPITokenExpiry):
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, err.Error(), err))
+		case errors.Is(err, service.ErrAPITokenLimitReached):
+			utils.Error(c, utils.NewAppError(utils.CodeConflict, "api token limit reached", err))
+		default:
+			utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to create api token", err))
+		}
+		return
+	}
+
+	utils.SuccessWithMessage(c, "api token created, copy it now as it will not be shown again", token)
+}
+
+// RevokeToken 撤销个人访问令牌
+// @Summary 撤销个人访问令牌
+// @Description 撤销指定的个人访问令牌，使用该令牌的请求立即失效
+// @Tags 设置
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "令牌 ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/settings/api-tokens/{id} [delete]
+func (h *APITokenHandler) RevokeToken(c *gin.Context) {
+	tokenID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || tokenID <= 0 {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid api token id", err))
+		return
+	}
+
+	err = h.apiTokenService.RevokeToken(c.Request.Context(), middleware.MustGetUserID(c), tokenID)
+	if err != nil {
+		if errors.Is(err, service.ErrAPITokenNotFound) {
+			utils.Error(c, utils.NewAppError(utils.CodeNotFound, "api token not found", err))
+			return
+		}
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to revoke api token", err))
+		return
+	}
+
+	utils.SuccessWithMessage(c, "api token revoked successfully", nil)
+}
+
+// RegisterRoutes 注册个人访问令牌路由（需要认证，且只允许 JWT 认证）
+func (h *APITokenHandler) RegisterRoutes(router *gin.RouterGroup) {
+	tokens := router.Group("/settings/api-tokens")
+	{
+		tokens.GET("", h.ListTokens)
+		tokens.POST("", h.CreateToken)
+		tokens.DELETE("/:id", h.RevokeToken)
+	}
+}
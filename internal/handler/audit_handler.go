@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/middleware"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/service"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditHandler 审计日志处理器（需要管理员权限）
+type AuditHandler struct {
+	auditService service.AuditService
+}
+
+// NewAuditHandler 创建审计日志处理器实例
+func NewAuditHandler(auditService service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// ListLogs 获取审计日志列表
+// @Summary 获取审计日志列表
+// @Description 按操作者、操作、资源、结果和日期筛选审计日志，最新的在前（需要管理员权限）
+// @Tags 管理
+// @Produce json
+// @Security BearerAuth
+// @Param actor_user_id query int false "按操作者用户 ID 筛选"
+// @Param action query string false "按操作筛选，如 auth.login；以 .* 结尾时按前缀筛选，如 admin.*"
+// @Param resource_type query string false "按资源类型筛选"
+// @Param resource_id query string false "按资源 ID 筛选"
+// @Param outcome query string false "按结果筛选：success 或 failure"
+// @Param start_date query string false "开始日期（YYYY-MM-DD，包含）"
+// @Param end_date query string false "结束日期（YYYY-MM-DD，包含）"
+// @Param page query int false "页码（默认 1）"
+// @Param page_size query int false "每页数量（默认 20，最大 100）"
+// @Success 200 {object} utils.PaginatedResponse{data=[]model.AuditLog}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /api/v1/admin/audit-logs [get]
+func (h *AuditHandler) ListLogs(c *gin.Context) {
+	filter, ok := parseAuditLogFilter(c)
+	if !ok {
+		return
+	}
+
+	// 解析分页参数
+	filter.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	filter.PageSize, _ = strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	logs, total, err := h.auditService.ListLogs(c.Request.Context(), filter)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to list audit logs", err))
+		return
+	}
+
+	utils.SuccessWithPagination(c, logs, utils.CalculatePagination(filter.Page, filter.PageSize, total))
+}
+
+// ExportLogs 导出审计日志
+// @Summary 导出审计日志
+// @Description 按与列表相同的筛选条件导出审计日志（CSV 或 JSON），最多导出最新的 10000 条；导出操作本身也会记录审计日志（需要管理员权限）
+// @Tags 管理
+// @Produce text/csv
+// @Produce json
+// @Security BearerAuth
+// @Param format query string false "导出格式：csv 或 json（默认 csv）"
+// @Param actor_user_id query int false "按操作者用户 ID 筛选"
+// @Param action query string false "按操作筛选，以 .* 结尾时按前缀筛选"
+// @Param resource_type query string false "按资源类型筛选"
+// @Param resource_id query string false "按资源 ID 筛选"
+// @Param outcome query string false "按结果筛选：success 或 failure"
+// @Param start_date query string false "开始日期（YYYY-MM-DD，包含）"
+// @Param end_date query string false "结束日期（YYYY-MM-DD，包含）"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /api/v1/admin/audit-logs/export [get]
+func (h *AuditHandler) ExportLogs(c *gin.Context) {
+	filter, ok := parseAuditLogFilter(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", model.AuditExportFormatCSV)
+	data, _, err := h.auditService.ExportLogs(c.Request.Context(), filter, format)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAuditExportFormat) {
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, err.Error(), err))
+			return
+		}
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to export audit logs", err))
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == model.AuditExportFormatJSON {
+		contentType = "application/json"
+	}
+
+	fileName := fmt.Sprintf("audit_logs_%s.%s", time.Now().Format("20060102_150405"), format)
+	c.Header("Content-Disposition", "attachment; filename="+fileName)
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// parseAuditLogFilter 解析审计日志筛选条件，无效时写入错误响应
+// 日期按管理员的时区解释，结束日期包含当天
+func parseAuditLogFilter(c *gin.Context) (*model.AuditLogFilter, bool) {
+	filter := &model.AuditLogFilter{
+		Action:       c.Query("action"),
+		ResourceType: c.Query("resource_type"),
+		ResourceID:   c.Query("resource_id"),
+		Outcome:      c.Query("outcome"),
+	}
+
+	if value := c.Query("actor_user_id"); value != "" {
+		actorID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || actorID <= 0 {
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid actor_user_id", err))
+			return nil, false
+		}
+		filter.ActorUserID = &actorID
+	}
+
+	if filter.Outcome != "" && filter.Outcome != model.AuditOutcomeSuccess && filter.Outcome != model.AuditOutcomeFailure {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid outcome, must be success or failure", nil))
+		return nil, false
+	}
+
+	loc := middleware.GetUserLocation(c)
+	if value := c.Query("start_date"); value != "" {
+		start, err := utils.ParseDateToStartOfDayInLocation(value, loc)
+		if err != nil {
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid start_date, expected YYYY-MM-DD", err))
+			return nil, false
+		}
+		filter.StartDate = &start
+	}
+	if value := c.Query("end_date"); value != "" {
+		end, err := utils.ParseDateToStartOfDayInLocation(value, loc)
+		if err != nil {
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid end_date, expected YYYY-MM-DD", err))
+			return nil, false
+		}
+		end = end.AddDate(0, 0, 1)
+		filter.EndDate = &end
+	}
+
+	if filter.StartDate != nil && filter.EndDate != nil && !filter.StartDate.Before(*filter.EndDate) {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "start_date must not be after end_date", nil))
+		return nil, false
+	}
+
+	return filter, true
+}
+
+// RegisterRoutes 注册审计日志路由，所有路由都需要管理员权限
+func (h *AuditHandler) RegisterRoutes(router *gin.RouterGroup, userRepo repository.UserRepository) {
+	logs := router.Group("/admin/audit-logs")
+	logs.Use(middleware.AdminMiddleware(userRepo))
+	{
+		logs.GET("", h.ListLogs)
+		logs.GET("/export", h.ExportLogs)
+	}
+}
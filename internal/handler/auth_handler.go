@@ -25,13 +25,26 @@ func NewAuthHandler(authService service.AuthService) *AuthHandler {
 // LoginRequest 登录请求
 type LoginRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=50,alphanum"`
-	Password string `json:"password" binding:"required,min=8,max=128"`
+	Password string `json:"password" binding:"required,max=128"`
+}
+
+// LoginMFARequest 两步登录验证请求
+type LoginMFARequest struct {
+	MFAToken string `json:"mfa_token" binding:"required,min=20"`
+	Code     string `json:"code" binding:"required,min=6,max=20"` // 6 位验证码或恢复码
+}
+
+// MFAChallengeResponse 需要两步验证时的登录响应
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+	ExpiresIn   int64  `json:"expires_in"`
 }
 
 // RegisterRequest 注册请求
 type RegisterRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=50,alphanum"`
-	Password string `json:"password" binding:"required,min=8,max=128"`
+	Password string `json:"password" binding:"required,max=128"`
 	Email    string `json:"email,omitempty" binding:"omitempty,email,max=100"`
 }
 
@@ -51,13 +64,13 @@ type RefreshTokenRequest struct {
 
 // ChangePasswordRequest 修改密码请求
 type ChangePasswordRequest struct {
-	OldPassword string `json:"old_password" binding:"required,min=8,max=128"`
-	NewPassword string `json:"new_password" binding:"required,min=8,max=128"`
+	OldPassword string `json:"old_password" binding:"required,max=128"`
+	NewPassword string `json:"new_password" binding:"required,max=128"`
 }
 
 // Login 用户登录
 // @Summary 用户登录
-// @Description 用户使用用户名和密码登录系统
+// @Description 用户使用用户名和密码登录系统；启用了两步验证的用户会得到 MFA 挑战令牌，需要调用 /auth/login/2fa 完成登录
 // @Tags 认证
 // @Accept json
 // @Produce json
@@ -65,6 +78,7 @@ type ChangePasswordRequest struct {
 // @Success 200 {object} utils.Response{data=utils.TokenPair}
 // @Failure 400 {object} utils.Response
 // @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
 // @Router /api/v1/auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
@@ -77,7 +91,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	ipAddress := c.ClientIP()
 
 	// 调用服务层登录
-	tokenPair, err := h.authService.Login(c.Request.Context(), req.Username, req.Password, ipAddress)
+	result, err := h.authService.Login(c.Request.Context(), req.Username, req.Password, ipAddress, c.Request.UserAgent())
 	if err != nil {
 		if errors.Is(err, service.ErrAccountLocked) {
 			utils.Error(c, utils.NewAppError(utils.CodeTooManyRequests, "account locked due to too many failed login attempts", err))
@@ -87,21 +101,74 @@ func (h *AuthHandler) Login(c *gin.Context) {
 			utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "invalid username or password", err))
 			return
 		}
+		if errors.Is(err, service.ErrAccountDisabled) {
+			utils.Error(c, utils.NewAppError(utils.CodeForbidden, "account has been disabled", err))
+			return
+		}
 		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "login failed", err))
 		return
 	}
 
+	if result.MFARequired {
+		utils.SuccessWithMessage(c, "two-factor authentication required", MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    result.MFAToken,
+			ExpiresIn:   result.ExpiresIn,
+		})
+		return
+	}
+
+	utils.Success(c, result.TokenPair)
+}
+
+// LoginMFA 两步登录验证
+// @Summary 两步登录验证
+// @Description 提交登录时返回的 MFA 挑战令牌和身份验证器中的 6 位验证码（或一个恢复码）完成登录
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body LoginMFARequest true "两步登录验证请求"
+// @Success 200 {object} utils.Response{data=utils.TokenPair}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 429 {object} utils.Response
+// @Router /api/v1/auth/login/2fa [post]
+func (h *AuthHandler) LoginMFA(c *gin.Context) {
+	var req LoginMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	tokenPair, err := h.authService.VerifyMFA(c.Request.Context(), req.MFAToken, req.Code, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAccountLocked):
+			utils.Error(c, utils.NewAppError(utils.CodeTooManyRequests, "account locked due to too many failed login attempts", err))
+		case errors.Is(err, service.ErrInvalidTwoFactorCode):
+			utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "invalid two-factor code", err))
+		case errors.Is(err, service.ErrAccountDisabled):
+			utils.Error(c, utils.NewAppError(utils.CodeForbidden, "account has been disabled", err))
+		case errors.Is(err, service.ErrInvalidMFAToken):
+			utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "invalid or expired mfa token, please login again", err))
+		default:
+			utils.Error(c, utils.NewAppError(utils.CodeInternalError, "two-factor verification failed", err))
+		}
+		return
+	}
+
 	utils.Success(c, tokenPair)
 }
 
 // RefreshToken 刷新访问令牌
 // @Summary 刷新访问令牌
-// @Description 使用 Refresh Token 获取新的 Access Token
+// @Description 使用 Refresh Token 换取新的 Access Token 和 Refresh Token，旧的 Refresh Token 随即失效；已失效的 Refresh Token 被再次使用时撤销整个会话
 // @Tags 认证
 // @Accept json
 // @Produce json
 // @Param request body RefreshTokenRequest true "刷新令牌请求"
-// @Success 200 {object} utils.Response{data=map[string]string}
+// @Success 200 {object} utils.Response{data=utils.TokenPair}
 // @Failure 400 {object} utils.Response
 // @Failure 401 {object} utils.Response
 // @Router /api/v1/auth/refresh [post]
@@ -112,21 +179,23 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	// 刷新 token
-	accessToken, err := h.authService.RefreshToken(c.Request.Context(), req.RefreshToken)
+	// 刷新 token（轮换 Refresh Token）
+	tokenPair, err := h.authService.RefreshToken(c.Request.Context(), req.RefreshToken, c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
+		if errors.Is(err, service.ErrRefreshTokenReused) {
+			utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "refresh token has already been used, session revoked, please login again", err))
+			return
+		}
 		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "invalid or expired refresh token", err))
 		return
 	}
 
-	utils.Success(c, gin.H{
-		"access_token": accessToken,
-	})
+	utils.Success(c, tokenPair)
 }
 
 // Logout 用户登出
 // @Summary 用户登出
-// @Description 用户登出系统，将当前令牌加入黑名单
+// @Description 用户登出系统，将当前令牌加入黑名单并撤销当前会话
 // @Tags 认证
 // @Accept json
 // @Produce json
@@ -161,7 +230,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 
 // Register 用户注册
 // @Summary 用户注册
-// @Description 新用户注册账户
+// @Description 新用户注册账户，密码需符合密码策略，不符合时 data.violations 返回所有未通过的规则
 // @Tags 认证
 // @Accept json
 // @Produce json
@@ -182,6 +251,10 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	// 调用服务层注册
 	user, err := h.authService.Register(c.Request.Context(), req.Username, req.Password, req.Email)
 	if err != nil {
+		if errors.Is(err, service.ErrPasswordPolicy) {
+			utils.Error(c, passwordPolicyAppError(err))
+			return
+		}
 		if errors.Is(err, service.ErrUsernameExists) {
 			utils.Error(c, utils.NewAppError(utils.CodeConflict, "username already exists", err))
 			return
@@ -208,7 +281,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 // ChangePassword 修改密码
 // @Summary 修改密码
-// @Description 用户修改登录密码
+// @Description 用户修改登录密码，新密码需符合密码策略
 // @Tags 认证
 // @Accept json
 // @Produce json
@@ -239,6 +312,10 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 			utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "old password is incorrect", err))
 			return
 		}
+		if errors.Is(err, service.ErrPasswordPolicy) {
+			utils.Error(c, passwordPolicyAppError(err))
+			return
+		}
 		if errors.Is(err, service.ErrInvalidCredentials) {
 			utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not found", err))
 			return
@@ -250,12 +327,24 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	utils.SuccessWithMessage(c, "password changed successfully, please login again", nil)
 }
 
+// passwordPolicyAppError 将密码策略错误转换为参数错误，data 中返回所有未通过的规则
+func passwordPolicyAppError(err error) *utils.AppError {
+	appErr := utils.NewAppError(utils.CodeInvalidParams, "password does not meet the password policy", err)
+
+	var policyErr *utils.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		appErr.WithData(policyErr)
+	}
+	return appErr
+}
+
 // RegisterRoutes 注册认证相关路由
 func (h *AuthHandler) RegisterRoutes(router *gin.RouterGroup) {
 	auth := router.Group("/auth")
 	{
 		auth.POST("/register", h.Register) // 注册路由，不需要认证
 		auth.POST("/login", h.Login)
+		auth.POST("/login/2fa", h.LoginMFA)
 		auth.POST("/refresh", h.RefreshToken)
 		auth.POST("/logout", h.Logout)
 		auth.PUT("/password", h.ChangePassword) // 需要认证中间件
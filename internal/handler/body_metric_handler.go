@@ -0,0 +1,316 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/middleware"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/service"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// BodyMetricHandler handles body weight and measurement HTTP requests
+type BodyMetricHandler struct {
+	bodyMetricService *service.BodyMetricService
+}
+
+// NewBodyMetricHandler creates a new BodyMetricHandler instance
+func NewBodyMetricHandler(bodyMetricService *service.BodyMetricService) *BodyMetricHandler {
+	return &BodyMetricHandler{
+		bodyMetricService: bodyMetricService,
+	}
+}
+
+// CreateBodyMetric handles POST /api/v1/body-metrics
+// @Summary Create a body metric entry
+// @Description Record weight, body fat and measurements for a date (one entry per date)
+// @Tags body-metrics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.BodyMetricRequest true "Body metric entry"
+// @Success 200 {object} utils.Response{data=model.BodyMetricEntry}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /api/v1/body-metrics [post]
+func (h *BodyMetricHandler) CreateBodyMetric(c *gin.Context) {
+	var req model.BodyMetricRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	// measured_on is a calendar date in the user's timezone
+	req.MeasuredOn = utils.DateInLocation(req.MeasuredOn, middleware.GetUserLocation(c))
+
+	entry, err := h.bodyMetricService.CreateBodyMetric(userID.(int64), &req)
+	if err != nil {
+		h.handleWriteError(c, err, "failed to create body metric")
+		return
+	}
+
+	utils.Success(c, entry)
+}
+
+// UpdateBodyMetric handles PUT /api/v1/body-metrics/:id
+// @Summary Update a body metric entry
+// @Description Replace a body metric entry
+// @Tags body-metrics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Body metric ID"
+// @Param request body model.BodyMetricRequest true "Body metric entry"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /api/v1/body-metrics/{id} [put]
+func (h *BodyMetricHandler) UpdateBodyMetric(c *gin.Context) {
+	metricID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid body metric id", err))
+		return
+	}
+
+	var req model.BodyMetricRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	req.MeasuredOn = utils.DateInLocation(req.MeasuredOn, middleware.GetUserLocation(c))
+
+	if err := h.bodyMetricService.UpdateBodyMetric(userID.(int64), metricID, &req); err != nil {
+		h.handleWriteError(c, err, "failed to update body metric")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "body metric updated successfully", nil)
+}
+
+// DeleteBodyMetric handles DELETE /api/v1/body-metrics/:id
+// @Summary Delete a body metric entry
+// @Description Delete a body metric entry
+// @Tags body-metrics
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Body metric ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/body-metrics/{id} [delete]
+func (h *BodyMetricHandler) DeleteBodyMetric(c *gin.Context) {
+	metricID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid body metric id", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	if err := h.bodyMetricService.DeleteBodyMetric(userID.(int64), metricID); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeNotFound, "body metric not found", err))
+		return
+	}
+
+	utils.SuccessWithMessage(c, "body metric deleted successfully", nil)
+}
+
+// GetBodyMetric handles GET /api/v1/body-metrics/:id
+// @Summary Get a body metric entry
+// @Description Get a body metric entry in the user's units
+// @Tags body-metrics
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Body metric ID"
+// @Success 200 {object} utils.Response{data=model.BodyMetricEntry}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/body-metrics/{id} [get]
+func (h *BodyMetricHandler) GetBodyMetric(c *gin.Context) {
+	metricID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid body metric id", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	entry, err := h.bodyMetricService.GetBodyMetric(userID.(int64), metricID)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeNotFound, "body metric not found", err))
+		return
+	}
+
+	utils.Success(c, entry)
+}
+
+// ListBodyMetrics handles GET /api/v1/body-metrics
+// @Summary List body metric entries
+// @Description List body metric entries in a date range, oldest first
+// @Tags body-metrics
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string false "Start date (YYYY-MM-DD, default: 27 days before end_date)"
+// @Param end_date query string false "End date (YYYY-MM-DD, default: today)"
+// @Success 200 {object} utils.Response{data=[]model.BodyMetricEntry}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/body-metrics [get]
+func (h *BodyMetricHandler) ListBodyMetrics(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	startDate, endDate, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+
+	entries, err := h.bodyMetricService.ListBodyMetrics(userID.(int64), startDate, endDate)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to list body metrics", err))
+		return
+	}
+
+	utils.Success(c, entries)
+}
+
+// GetWeightTrend handles GET /api/v1/body-metrics/trend
+// @Summary Get weight trend
+// @Description Get the moving average weight trend, weekly rate of change and projected goal date
+// @Tags body-metrics
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string false "Start date (YYYY-MM-DD, default: 27 days before end_date)"
+// @Param end_date query string false "End date (YYYY-MM-DD, default: today)"
+// @Param window query int false "Moving average window in days (default: 7, max: 30)"
+// @Success 200 {object} utils.Response{data=model.WeightTrend}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/body-metrics/trend [get]
+func (h *BodyMetricHandler) GetWeightTrend(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	startDate, endDate, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+
+	window := service.DefaultWeightTrendWindow
+	if windowStr := c.Query("window"); windowStr != "" {
+		parsed, err := strconv.Atoi(windowStr)
+		if err != nil || parsed < 1 || parsed > 30 {
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "window must be between 1 and 30", err))
+			return
+		}
+		window = parsed
+	}
+
+	trend, err := h.bodyMetricService.GetWeightTrend(userID.(int64), startDate, endDate, window)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to get weight trend", err))
+		return
+	}
+
+	utils.Success(c, trend)
+}
+
+// SetGoalWeight handles PUT /api/v1/body-metrics/goal
+// @Summary Set goal weight
+// @Description Set or clear (goal_weight: null) the goal weight used for projections
+// @Tags body-metrics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.SetGoalWeightRequest true "Goal weight"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/body-metrics/goal [put]
+func (h *BodyMetricHandler) SetGoalWeight(c *gin.Context) {
+	var req model.SetGoalWeightRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	if err := h.bodyMetricService.SetGoalWeight(c.Request.Context(), userID.(int64), &req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "failed to set goal weight", err))
+		return
+	}
+
+	utils.SuccessWithMessage(c, "goal weight updated successfully", nil)
+}
+
+// handleWriteError maps create/update errors to responses
+func (h *BodyMetricHandler) handleWriteError(c *gin.Context, err error, message string) {
+	if errors.Is(err, service.ErrBodyMetricExists) {
+		utils.Error(c, utils.NewAppError(utils.CodeConflict, "a body metric entry already exists for this date", err))
+		return
+	}
+	if errors.Is(err, service.ErrInvalidBodyMetric) {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid body metric", err))
+		return
+	}
+	utils.Error(c, utils.NewAppError(utils.CodeInternalError, message, err))
+}
+
+// RegisterRoutes registers body metric routes
+func (h *BodyMetricHandler) RegisterRoutes(router *gin.RouterGroup) {
+	metrics := router.Group("/body-metrics")
+	{
+		metrics.POST("", h.CreateBodyMetric)
+		metrics.GET("", h.ListBodyMetrics)
+		metrics.GET("/trend", h.GetWeightTrend)
+		metrics.PUT("/goal", h.SetGoalWeight)
+		metrics.GET("/:id", h.GetBodyMetric)
+		metrics.PUT("/:id", h.UpdateBodyMetric)
+		metrics.DELETE("/:id", h.DeleteBodyMetric)
+	}
+}
@@ -1,7 +1,8 @@
 package handler
 
 import (
-	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/middleware"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/service"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
 	"github.com/gin-gonic/gin"
@@ -10,14 +11,14 @@ import (
 // DashboardHandler handles dashboard-related HTTP requests
 type DashboardHandler struct {
 	dashboardService *service.DashboardService
-	prefsRepo        repository.UserPreferencesRepository
+	goalService      *service.GoalService
 }
 
 // NewDashboardHandler creates a new DashboardHandler instance
-func NewDashboardHandler(dashboardService *service.DashboardService, prefsRepo repository.UserPreferencesRepository) *DashboardHandler {
+func NewDashboardHandler(dashboardService *service.DashboardService, goalService *service.GoalService) *DashboardHandler {
 	return &DashboardHandler{
 		dashboardService: dashboardService,
-		prefsRepo:        prefsRepo,
+		goalService:      goalService,
 	}
 }
 
@@ -41,38 +42,18 @@ func (h *DashboardHandler) GetDashboard(c *gin.Context) {
 	}
 
 	// Get dashboard data
-	dashboardData, err := h.dashboardService.GetDashboardData(userID.(int64))
+	dashboardData, err := h.dashboardService.GetDashboardData(userID.(int64), middleware.GetUserLocation(c))
 	if err != nil {
 		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to get dashboard data", err))
 		return
 	}
 
-	// Get user nutrition goals from preferences
-	userPrefs, err := h.prefsRepo.GetPreferences(userID.(int64))
-	if err != nil {
-		// If preferences not found, use default values
-		userPrefs = nil
-	}
-
-	// Set default nutrition goals if preferences not found
-	caloriesGoal := 2000
-	proteinGoal := 150
-	carbsGoal := 250
-	fatGoal := 70
-
-	if userPrefs != nil {
-		if userPrefs.DailyCaloriesGoal > 0 {
-			caloriesGoal = userPrefs.DailyCaloriesGoal
-		}
-		if userPrefs.DailyProteinGoal > 0 {
-			proteinGoal = userPrefs.DailyProteinGoal
-		}
-		if userPrefs.DailyCarbsGoal > 0 {
-			carbsGoal = userPrefs.DailyCarbsGoal
-		}
-		if userPrefs.DailyFatGoal > 0 {
-			fatGoal = userPrefs.DailyFatGoal
-		}
+	// Get the nutrition goals in effect today, resolving today's goal profile and
+	// flexing the calorie goal by today's exercise
+	today := dashboardData.TodayStats.Date
+	goals := model.ResolvedGoals{NutritionGoals: model.DefaultNutritionGoals()} // Fallback
+	if calendar, err := h.goalService.GetCalendar(userID.(int64), today, today); err == nil {
+		goals = calendar.OnWithActivity(today, dashboardData.TodayStats.CaloriesBurned)
 	}
 
 	// Transform plans to match frontend expectations
@@ -89,18 +70,25 @@ func (h *DashboardHandler) GetDashboard(c *gin.Context) {
 	// Build frontend-expected response structure
 	response := gin.H{
 		"today_nutrition": gin.H{
-			"calories": dashboardData.TodayStats.Nutrition.Calories,
-			"protein":  dashboardData.TodayStats.Nutrition.Protein,
-			"carbs":    dashboardData.TodayStats.Nutrition.Carbs,
-			"fat":      dashboardData.TodayStats.Nutrition.Fat,
+			"calories":        dashboardData.TodayStats.Nutrition.Calories,
+			"protein":         dashboardData.TodayStats.Nutrition.Protein,
+			"carbs":           dashboardData.TodayStats.Nutrition.Carbs,
+			"fat":             dashboardData.TodayStats.Nutrition.Fat,
+			"calories_burned": dashboardData.TodayStats.CaloriesBurned,
+			"net_calories":    dashboardData.TodayStats.NetCalories,
 		},
 		"nutrition_goal": gin.H{
-			"calories": caloriesGoal,
-			"protein":  proteinGoal,
-			"carbs":    carbsGoal,
-			"fat":      fatGoal,
+			"calories":       goals.Calories,
+			"protein":        goals.Protein,
+			"carbs":          goals.Carbs,
+			"fat":            goals.Fat,
+			"profile":        goals.Profile,
+			"exercise_bonus": goals.ExerciseBonus,
 		},
 		"upcoming_plans": transformedPlans,
+		"fasting":        dashboardData.Fasting,
+		"body_metrics":   dashboardData.BodyMetrics,
+		"hydration":      dashboardData.Hydration,
 	}
 
 	utils.Success(c, response)
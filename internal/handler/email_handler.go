@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/middleware"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/service"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// EmailHandler 邮箱验证和找回密码处理器
+type EmailHandler struct {
+	emailService service.EmailService
+}
+
+// NewEmailHandler 创建邮箱验证和找回密码处理器实例
+func NewEmailHandler(emailService service.EmailService) *EmailHandler {
+	return &EmailHandler{
+		emailService: emailService,
+	}
+}
+
+// EmailTokenRequest 邮件令牌请求
+type EmailTokenRequest struct {
+	Token string `json:"token" binding:"required,max=128"`
+}
+
+// ForgotPasswordRequest 找回密码请求
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email,max=100"`
+}
+
+// ResetPasswordWithTokenRequest 通过邮件令牌重置密码请求
+type ResetPasswordWithTokenRequest struct {
+	Token       string `json:"token" binding:"required,max=128"`
+	NewPassword string `json:"new_password" binding:"required,max=128"`
+}
+
+// SendVerificationEmail 发送邮箱验证邮件
+// @Summary 发送邮箱验证邮件
+// @Description 向当前用户的邮箱重新发送验证邮件，之前发出的验证链接随即失效；每分钟最多发送一次
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Failure 429 {object} utils.Response
+// @Router /api/v1/auth/email/verification [post]
+func (h *EmailHandler) SendVerificationEmail(c *gin.Context) {
+	err := h.emailService.SendVerificationEmail(c.Request.Context(), middleware.MustGetUserID(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrEmailNotSet):
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "no email address is set for this account", err))
+		case errors.Is(err, service.ErrEmailAlreadyVerified):
+			utils.Error(c, utils.NewAppError(utils.CodeConflict, "email is already verified", err))
+		case errors.Is(err, service.ErrEmailRateLimited):
+			utils.Error(c, utils.NewAppError(utils.CodeTooManyRequests, "please wait a minute before requesting another email", err))
+		default:
+			utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to send verification email", err))
+		}
+		return
+	}
+
+	utils.SuccessWithMessage(c, "verification email sent", nil)
+}
+
+// VerifyEmail 验证邮箱
+// @Summary 验证邮箱
+// @Description 提交验证邮件链接中的令牌完成邮箱验证，令牌只能使用一次
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body EmailTokenRequest true "验证令牌"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /api/v1/auth/email/verify [post]
+func (h *EmailHandler) VerifyEmail(c *gin.Context) {
+	var req EmailTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	if err := h.emailService.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		if errors.Is(err, service.ErrInvalidEmailToken) {
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid or expired token", err))
+			return
+		}
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to verify email", err))
+		return
+	}
+
+	utils.SuccessWithMessage(c, "email verified successfully", nil)
+}
+
+// ForgotPassword 找回密码
+// @Summary 找回密码
+// @Description 向已验证该邮箱的账户发送密码重置邮件。无论邮箱是否存在都返回相同的响应
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body ForgotPasswordRequest true "找回密码请求"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /api/v1/auth/password/forgot [post]
+func (h *EmailHandler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	if err := h.emailService.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to process password reset request", err))
+		return
+	}
+
+	utils.SuccessWithMessage(c, "if the email belongs to an account with a verified email, a password reset link has been sent", nil)
+}
+
+// ResetPassword 重置密码
+// @Summary 重置密码
+// @Description 提交密码重置邮件链接中的令牌和新密码，新密码需符合密码策略（不符合时令牌仍可使用）；令牌只能使用一次，重置后所有会话失效，需要重新登录
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body ResetPasswordWithTokenRequest true "重置密码请求"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/auth/password/reset [post]
+func (h *EmailHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordWithTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	if err := h.emailService.ResetPassword(c.Request.Context(), req.Token, req.NewPassword); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidEmailToken):
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid or expired token", err))
+		case errors.Is(err, service.ErrPasswordPolicy):
+			utils.Error(c, passwordPolicyAppError(err))
+		case errors.Is(err, service.ErrAccountDisabled):
+			utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "account has been disabled", err))
+		default:
+			utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to reset password", err))
+		}
+		return
+	}
+
+	utils.SuccessWithMessage(c, "password reset successfully, please login again", nil)
+}
+
+// RegisterPublicRoutes 注册不需要认证的邮箱验证和找回密码路由
+func (h *EmailHandler) RegisterPublicRoutes(router *gin.RouterGroup) {
+	auth := router.Group("/auth")
+	{
+		auth.POST("/email/verify", h.VerifyEmail)
+		auth.POST("/password/forgot", h.ForgotPassword)
+		auth.POST("/password/reset", h.ResetPassword)
+	}
+}
+
+// RegisterRoutes 注册需要认证的邮箱验证路由
+func (h *EmailHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.POST("/auth/email/verification", h.SendVerificationEmail)
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/middleware"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/service"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// EnergyHandler handles BMR/TDEE estimation HTTP requests
+type EnergyHandler struct {
+	energyService *service.EnergyService
+}
+
+// NewEnergyHandler creates a new EnergyHandler instance
+func NewEnergyHandler(energyService *service.EnergyService) *EnergyHandler {
+	return &EnergyHandler{
+		energyService: energyService,
+	}
+}
+
+// GetEnergyEstimate handles GET /api/v1/energy/estimate
+// @Summary Estimate BMR/TDEE
+// @Description Estimate BMR with every applicable formula, TDEE and suggested calorie and macro goals
+// @Tags energy
+// @Produce json
+// @Security BearerAuth
+// @Param formula query string false "Formula used for TDEE (mifflin_st_jeor, harris_benedict, katch_mcardle)"
+// @Success 200 {object} utils.Response{data=model.EnergyEstimate}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/energy/estimate [get]
+func (h *EnergyHandler) GetEnergyEstimate(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	estimate, err := h.energyService.Estimate(userID.(int64), c.Query("formula"), time.Now(), middleware.GetUserLocation(c))
+	if err != nil {
+		h.handleEstimateError(c, err, "failed to estimate energy expenditure")
+		return
+	}
+
+	utils.Success(c, estimate)
+}
+
+// ApplySuggestedGoals handles POST /api/v1/energy/apply
+// @Summary Apply suggested goals
+// @Description Write the suggested calorie and macro goals into the user's preferences
+// @Tags energy
+// @Produce json
+// @Security BearerAuth
+// @Param formula query string false "Formula used for TDEE"
+// @Success 200 {object} utils.Response{data=model.EnergyEstimate}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/energy/apply [post]
+func (h *EnergyHandler) ApplySuggestedGoals(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	estimate, err := h.energyService.ApplySuggestedGoals(c.Request.Context(), userID.(int64), c.Query("formula"), time.Now(), middleware.GetUserLocation(c))
+	if err != nil {
+		h.handleEstimateError(c, err, "failed to apply suggested goals")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "suggested goals applied successfully", estimate)
+}
+
+// GetAdaptiveTDEE handles GET /api/v1/energy/adaptive
+// @Summary Adaptive TDEE
+// @Description Estimate TDEE from logged intake and weight trend over the past weeks
+// @Tags energy
+// @Produce json
+// @Security BearerAuth
+// @Param weeks query int false "Lookback in weeks (2-12, default 4)"
+// @Success 200 {object} utils.Response{data=model.AdaptiveTDEE}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/energy/adaptive [get]
+func (h *EnergyHandler) GetAdaptiveTDEE(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	weeks := service.DefaultAdaptiveWeeks
+	if weeksStr := c.Query("weeks"); weeksStr != "" {
+		parsed, err := strconv.Atoi(weeksStr)
+		if err != nil || parsed < 2 || parsed > 12 {
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "weeks must be between 2 and 12", err))
+			return
+		}
+		weeks = parsed
+	}
+
+	adaptive, err := h.energyService.AdaptiveTDEE(userID.(int64), weeks, time.Now(), middleware.GetUserLocation(c))
+	if err != nil {
+		h.handleEstimateError(c, err, "failed to estimate adaptive TDEE")
+		return
+	}
+
+	utils.Success(c, adaptive)
+}
+
+// handleEstimateError maps energy service errors to response codes
+func (h *EnergyHandler) handleEstimateError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrIncompleteEnergyProfile),
+		errors.Is(err, service.ErrInvalidFormula),
+		errors.Is(err, service.ErrInsufficientAdaptiveData):
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, err.Error(), err))
+	default:
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, message, err))
+	}
+}
+
+// RegisterRoutes registers energy routes
+func (h *EnergyHandler) RegisterRoutes(router *gin.RouterGroup) {
+	energy := router.Group("/energy")
+	{
+		energy.GET("/estimate", h.GetEnergyEstimate)
+		energy.POST("/apply", h.ApplySuggestedGoals)
+		energy.GET("/adaptive", h.GetAdaptiveTDEE)
+	}
+}
@@ -0,0 +1,299 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/middleware"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/service"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// FastingHandler handles intermittent fasting HTTP requests
+type FastingHandler struct {
+	fastingService *service.FastingService
+}
+
+// NewFastingHandler creates a new FastingHandler instance
+func NewFastingHandler(fastingService *service.FastingService) *FastingHandler {
+	return &FastingHandler{
+		fastingService: fastingService,
+	}
+}
+
+// ListProtocols handles GET /api/v1/fasting/protocols
+// @Summary List fasting protocols
+// @Description List the built-in fasting protocols and their target durations
+// @Tags fasting
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]model.FastingProtocol}
+// @Router /api/v1/fasting/protocols [get]
+func (h *FastingHandler) ListProtocols(c *gin.Context) {
+	utils.Success(c, model.FastingProtocols)
+}
+
+// StartFast handles POST /api/v1/fasting/start
+// @Summary Start a fast
+// @Description Start a fast with a built-in protocol or a custom target duration
+// @Tags fasting
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.StartFastRequest true "Start fast request"
+// @Success 200 {object} utils.Response{data=model.Fast}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /api/v1/fasting/start [post]
+func (h *FastingHandler) StartFast(c *gin.Context) {
+	var req model.StartFastRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	fast, err := h.fastingService.StartFast(userID.(int64), &req, time.Now())
+	if err != nil {
+		if errors.Is(err, service.ErrFastAlreadyActive) {
+			utils.Error(c, utils.NewAppError(utils.CodeConflict, "a fast is already active", err))
+			return
+		}
+		if errors.Is(err, service.ErrInvalidFastingProtocol) {
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid fasting protocol", err))
+			return
+		}
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to start fast", err))
+		return
+	}
+
+	utils.Success(c, fast)
+}
+
+// StopFast handles POST /api/v1/fasting/stop
+// @Summary Stop the active fast
+// @Description End the active fast now
+// @Tags fasting
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=model.Fast}
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/fasting/stop [post]
+func (h *FastingHandler) StopFast(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	fast, err := h.fastingService.StopFast(userID.(int64), time.Now())
+	if err != nil {
+		if errors.Is(err, service.ErrNoActiveFast) {
+			utils.Error(c, utils.NewAppError(utils.CodeNotFound, "no active fast", err))
+			return
+		}
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to stop fast", err))
+		return
+	}
+
+	utils.Success(c, fast)
+}
+
+// GetCurrentFast handles GET /api/v1/fasting/current
+// @Summary Get the active fast
+// @Description Get the progress of the active fast; data is null when no fast is active
+// @Tags fasting
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=model.FastStatus}
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/fasting/current [get]
+func (h *FastingHandler) GetCurrentFast(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	status, err := h.fastingService.GetCurrentFast(userID.(int64), time.Now())
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to get current fast", err))
+		return
+	}
+
+	utils.Success(c, status)
+}
+
+// ListFasts handles GET /api/v1/fasting/history
+// @Summary List fasting history
+// @Description List past and active fasts, newest first
+// @Tags fasting
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 20, max: 100)"
+// @Success 200 {object} utils.PaginatedResponse{data=[]model.Fast}
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/fasting/history [get]
+func (h *FastingHandler) ListFasts(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if err != nil || pageSize < 1 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	fasts, total, err := h.fastingService.ListFasts(userID.(int64), page, pageSize)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to list fasts", err))
+		return
+	}
+
+	utils.SuccessWithPagination(c, fasts, utils.CalculatePagination(page, pageSize, total))
+}
+
+// GetFastingStats handles GET /api/v1/fasting/stats
+// @Summary Get fasting stats
+// @Description Get fasting totals, completion rate and streaks
+// @Tags fasting
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=model.FastingStats}
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/fasting/stats [get]
+func (h *FastingHandler) GetFastingStats(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	stats, err := h.fastingService.GetFastingStats(userID.(int64), time.Now(), middleware.GetUserLocation(c))
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to get fasting stats", err))
+		return
+	}
+
+	utils.Success(c, stats)
+}
+
+// GetInferredWindows handles GET /api/v1/fasting/inferred
+// @Summary Infer fasting windows from meals
+// @Description Infer fasting windows from gaps between logged meal times
+// @Tags fasting
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string false "Start date (YYYY-MM-DD, default: 27 days before end_date)"
+// @Param end_date query string false "End date (YYYY-MM-DD, default: today)"
+// @Param min_hours query number false "Minimum gap between meals in hours (default: 12)"
+// @Success 200 {object} utils.Response{data=[]model.FastingWindow}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/fasting/inferred [get]
+func (h *FastingHandler) GetInferredWindows(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	startDate, endDate, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+
+	minHours := float64(service.DefaultMinInferredFastHours)
+	if minHoursStr := c.Query("min_hours"); minHoursStr != "" {
+		parsed, err := strconv.ParseFloat(minHoursStr, 64)
+		if err != nil || parsed <= 0 || parsed > 168 {
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "min_hours must be between 0 and 168", err))
+			return
+		}
+		minHours = parsed
+	}
+
+	windows, err := h.fastingService.InferFastingWindows(userID.(int64), startDate, endDate, minHours)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to infer fasting windows", err))
+		return
+	}
+
+	utils.Success(c, windows)
+}
+
+// DeleteFast handles DELETE /api/v1/fasting/:id
+// @Summary Delete a fast
+// @Description Delete a fast record
+// @Tags fasting
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Fast ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/fasting/{id} [delete]
+func (h *FastingHandler) DeleteFast(c *gin.Context) {
+	fastID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid fast id", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	if err := h.fastingService.DeleteFast(userID.(int64), fastID); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeNotFound, "fast not found", err))
+		return
+	}
+
+	utils.SuccessWithMessage(c, "fast deleted successfully", nil)
+}
+
+// RegisterRoutes registers fasting-related routes
+func (h *FastingHandler) RegisterRoutes(router *gin.RouterGroup) {
+	fasting := router.Group("/fasting")
+	{
+		fasting.GET("/protocols", h.ListProtocols)
+		fasting.POST("/start", h.StartFast)
+		fasting.POST("/stop", h.StopFast)
+		fasting.GET("/current", h.GetCurrentFast)
+		fasting.GET("/history", h.ListFasts)
+		fasting.GET("/stats", h.GetFastingStats)
+		fasting.GET("/inferred", h.GetInferredWindows)
+		fasting.DELETE("/:id", h.DeleteFast)
+	}
+}
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/service"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// FoodCategoryHandler handles food category HTTP requests
+type FoodCategoryHandler struct {
+	categoryService *service.FoodCategoryService
+}
+
+// NewFoodCategoryHandler creates a new FoodCategoryHandler instance
+func NewFoodCategoryHandler(categoryService *service.FoodCategoryService) *FoodCategoryHandler {
+	return &FoodCategoryHandler{
+		categoryService: categoryService,
+	}
+}
+
+// ListCategories handles GET /api/v1/food-categories
+// @Summary List food categories
+// @Description List the built-in and the user's own food categories as a tree, or as a flat list with flat=true
+// @Tags food-categories
+// @Produce json
+// @Security BearerAuth
+// @Param flat query bool false "Return a flat list instead of a tree"
+// @Success 200 {object} utils.Response{data=[]model.FoodCategory}
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/food-categories [get]
+func (h *FoodCategoryHandler) ListCategories(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	if c.Query("flat") == "true" {
+		categories, err := h.categoryService.ListCategories(userID.(int64))
+		if err != nil {
+			utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to list food categories", err))
+			return
+		}
+		utils.Success(c, categories)
+		return
+	}
+
+	tree, err := h.categoryService.GetCategoryTree(userID.(int64))
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to list food categories", err))
+		return
+	}
+
+	utils.Success(c, tree)
+}
+
+// CreateCategory handles POST /api/v1/food-categories
+// @Summary Create a food category
+// @Description Create a custom food category, optionally under a built-in or custom parent
+// @Tags food-categories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.CreateFoodCategoryRequest true "Food category"
+// @Success 200 {object} utils.Response{data=model.FoodCategory}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /api/v1/food-categories [post]
+func (h *FoodCategoryHandler) CreateCategory(c *gin.Context) {
+	var req model.CreateFoodCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	category := &model.FoodCategory{
+		ParentID:  req.ParentID,
+		Slug:      req.Slug,
+		Name:      req.Name,
+		SortOrder: req.SortOrder,
+	}
+
+	if err := h.categoryService.CreateCategory(userID.(int64), category); err != nil {
+		h.handleWriteError(c, err, "failed to create food category")
+		return
+	}
+
+	utils.Success(c, category)
+}
+
+// UpdateCategory handles PUT /api/v1/food-categories/:id
+// @Summary Update a food category
+// @Description Rename or move a custom food category. Built-in categories and slugs cannot be changed.
+// @Tags food-categories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Category ID"
+// @Param request body model.UpdateFoodCategoryRequest true "Food category"
+// @Success 200 {object} utils.Response{data=model.FoodCategory}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/food-categories/{id} [put]
+func (h *FoodCategoryHandler) UpdateCategory(c *gin.Context) {
+	categoryID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid food category id", err))
+		return
+	}
+
+	var req model.UpdateFoodCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	category := &model.FoodCategory{
+		ParentID:  req.ParentID,
+		Name:      req.Name,
+		SortOrder: req.SortOrder,
+	}
+
+	if err := h.categoryService.UpdateCategory(userID.(int64), categoryID, category); err != nil {
+		h.handleWriteError(c, err, "failed to update food category")
+		return
+	}
+
+	utils.Success(c, category)
+}
+
+// DeleteCategory handles DELETE /api/v1/food-categories/:id
+// @Summary Delete a food category
+// @Description Delete a custom food category without subcategories. Its foods move to the parent category, or to "other".
+// @Tags food-categories
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Category ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /api/v1/food-categories/{id} [delete]
+func (h *FoodCategoryHandler) DeleteCategory(c *gin.Context) {
+	categoryID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid food category id", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	if err := h.categoryService.DeleteCategory(userID.(int64), categoryID); err != nil {
+		h.handleWriteError(c, err, "failed to delete food category")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "food category deleted successfully", nil)
+}
+
+// handleWriteError maps food category service errors to responses
+func (h *FoodCategoryHandler) handleWriteError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidFoodCategory):
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, err.Error(), err))
+	case errors.Is(err, service.ErrFoodCategoryNotFound):
+		utils.Error(c, utils.NewAppError(utils.CodeNotFound, err.Error(), err))
+	case errors.Is(err, service.ErrFoodCategoryReadOnly):
+		utils.Error(c, utils.NewAppError(utils.CodeForbidden, err.Error(), err))
+	case errors.Is(err, service.ErrFoodCategoryExists), errors.Is(err, service.ErrFoodCategoryHasChildren):
+		utils.Error(c, utils.NewAppError(utils.CodeConflict, err.Error(), err))
+	default:
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, message, err))
+	}
+}
+
+// RegisterRoutes registers food category routes
+func (h *FoodCategoryHandler) RegisterRoutes(router *gin.RouterGroup) {
+	categories := router.Group("/food-categories")
+	{
+		categories.GET("", h.ListCategories)
+		categories.POST("", h.CreateCategory)
+		categories.PUT("/:id", h.UpdateCategory)
+		categories.DELETE("/:id", h.DeleteCategory)
+	}
+}
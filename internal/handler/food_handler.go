@@ -1,8 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/middleware"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/service"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
@@ -11,42 +15,56 @@ import (
 
 // FoodHandler handles food-related HTTP requests
 type FoodHandler struct {
-	foodService *service.FoodService
+	foodService       *service.FoodService
+	searchService     *service.FoodSearchService
+	suggestionService *service.FoodSuggestionService
 }
 
 // NewFoodHandler creates a new FoodHandler instance
-func NewFoodHandler(foodService *service.FoodService) *FoodHandler {
+func NewFoodHandler(foodService *service.FoodService, searchService *service.FoodSearchService, suggestionService *service.FoodSuggestionService) *FoodHandler {
 	return &FoodHandler{
-		foodService: foodService,
+		foodService:       foodService,
+		searchService:     searchService,
+		suggestionService: suggestionService,
 	}
 }
 
 // CreateFoodRequest represents the request body for creating a food item
 type CreateFoodRequest struct {
-	Name      string  `json:"name" binding:"required,min=1,max=100"`
-	Category  string  `json:"category" binding:"required,oneof=meat vegetable fruit grain other"`
-	Price     float64 `json:"price" binding:"required,gte=0,lte=100000"`
-	Unit      string  `json:"unit" binding:"required,min=1,max=20"`
-	Protein   float64 `json:"protein" binding:"required,gte=0,lte=1000"`
-	Carbs     float64 `json:"carbs" binding:"required,gte=0,lte=1000"`
-	Fat       float64 `json:"fat" binding:"required,gte=0,lte=1000"`
-	Fiber     float64 `json:"fiber" binding:"required,gte=0,lte=1000"`
-	Calories  float64 `json:"calories" binding:"required,gte=0,lte=10000"`
-	Available bool    `json:"available"`
+	Name      string   `json:"name" binding:"required,min=1,max=100"`
+	Category  string   `json:"category" binding:"omitempty,max=50"`
+	Aliases   []string `json:"aliases" binding:"omitempty,max=10,dive,min=1,max=100"`
+	Tags      []string `json:"tags" binding:"omitempty,max=20,dive,min=1,max=30"`
+	Price     float64  `json:"price" binding:"required,gte=0,lte=100000"`
+	Unit      string   `json:"unit" binding:"required,min=1,max=20"`
+	Protein   float64  `json:"protein" binding:"required,gte=0,lte=1000"`
+	Carbs     float64  `json:"carbs" binding:"required,gte=0,lte=1000"`
+	Fat       float64  `json:"fat" binding:"required,gte=0,lte=1000"`
+	Fiber     float64  `json:"fiber" binding:"required,gte=0,lte=1000"`
+	Calories  float64  `json:"calories" binding:"required,gte=0,lte=10000"`
+	WaterPct  float64  `json:"water_pct" binding:"gte=0,lte=100"`
+	Allergens []string `json:"allergens" binding:"omitempty,max=9,dive,oneof=peanuts tree_nuts gluten dairy eggs soy fish shellfish sesame"`
+	DietTags  []string `json:"diet_tags" binding:"omitempty,max=6,dive,oneof=vegan vegetarian pescatarian halal kosher low_fodmap"`
+	Available bool     `json:"available"`
 }
 
 // UpdateFoodRequest represents the request body for updating a food item
 type UpdateFoodRequest struct {
-	Name      string  `json:"name" binding:"required,min=1,max=100"`
-	Category  string  `json:"category" binding:"required,oneof=meat vegetable fruit grain other"`
-	Price     float64 `json:"price" binding:"required,gte=0,lte=100000"`
-	Unit      string  `json:"unit" binding:"required,min=1,max=20"`
-	Protein   float64 `json:"protein" binding:"required,gte=0,lte=1000"`
-	Carbs     float64 `json:"carbs" binding:"required,gte=0,lte=1000"`
-	Fat       float64 `json:"fat" binding:"required,gte=0,lte=1000"`
-	Fiber     float64 `json:"fiber" binding:"required,gte=0,lte=1000"`
-	Calories  float64 `json:"calories" binding:"required,gte=0,lte=10000"`
-	Available bool    `json:"available"`
+	Name      string   `json:"name" binding:"required,min=1,max=100"`
+	Category  string   `json:"category" binding:"omitempty,max=50"`
+	Aliases   []string `json:"aliases" binding:"omitempty,max=10,dive,min=1,max=100"`
+	Tags      []string `json:"tags" binding:"omitempty,max=20,dive,min=1,max=30"`
+	Price     float64  `json:"price" binding:"required,gte=0,lte=100000"`
+	Unit      string   `json:"unit" binding:"required,min=1,max=20"`
+	Protein   float64  `json:"protein" binding:"required,gte=0,lte=1000"`
+	Carbs     float64  `json:"carbs" binding:"required,gte=0,lte=1000"`
+	Fat       float64  `json:"fat" binding:"required,gte=0,lte=1000"`
+	Fiber     float64  `json:"fiber" binding:"required,gte=0,lte=1000"`
+	Calories  float64  `json:"calories" binding:"required,gte=0,lte=10000"`
+	WaterPct  float64  `json:"water_pct" binding:"gte=0,lte=100"`
+	Allergens []string `json:"allergens" binding:"omitempty,max=9,dive,oneof=peanuts tree_nuts gluten dairy eggs soy fish shellfish sesame"`
+	DietTags  []string `json:"diet_tags" binding:"omitempty,max=6,dive,oneof=vegan vegetarian pescatarian halal kosher low_fodmap"`
+	Available bool     `json:"available"`
 }
 
 // BatchImportRequest represents the request body for batch importing foods
@@ -84,6 +102,8 @@ func (h *FoodHandler) CreateFood(c *gin.Context) {
 	food := &model.Food{
 		Name:      req.Name,
 		Category:  req.Category,
+		Aliases:   req.Aliases,
+		Tags:      req.Tags,
 		Price:     req.Price,
 		Unit:      req.Unit,
 		Protein:   req.Protein,
@@ -91,12 +111,15 @@ func (h *FoodHandler) CreateFood(c *gin.Context) {
 		Fat:       req.Fat,
 		Fiber:     req.Fiber,
 		Calories:  req.Calories,
+		WaterPct:  req.WaterPct,
+		Allergens: req.Allergens,
+		DietTags:  req.DietTags,
 		Available: req.Available,
 	}
 
 	// Create food
 	if err := h.foodService.CreateFood(userID.(int64), food); err != nil {
-		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to create food", err))
+		h.handleWriteError(c, err, "failed to create food")
 		return
 	}
 
@@ -142,6 +165,8 @@ func (h *FoodHandler) UpdateFood(c *gin.Context) {
 	food := &model.Food{
 		Name:      req.Name,
 		Category:  req.Category,
+		Aliases:   req.Aliases,
+		Tags:      req.Tags,
 		Price:     req.Price,
 		Unit:      req.Unit,
 		Protein:   req.Protein,
@@ -149,12 +174,15 @@ func (h *FoodHandler) UpdateFood(c *gin.Context) {
 		Fat:       req.Fat,
 		Fiber:     req.Fiber,
 		Calories:  req.Calories,
+		WaterPct:  req.WaterPct,
+		Allergens: req.Allergens,
+		DietTags:  req.DietTags,
 		Available: req.Available,
 	}
 
 	// Update food
 	if err := h.foodService.UpdateFood(userID.(int64), foodID, food); err != nil {
-		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to update food", err))
+		h.handleWriteError(c, err, "failed to update food")
 		return
 	}
 
@@ -243,8 +271,15 @@ func (h *FoodHandler) GetFood(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param category query string false "Filter by category (meat, vegetable, fruit, grain, other)"
+// @Param category query string false "Filter by category slug, including its subcategories"
+// @Param include_subcategories query bool false "Whether to include subcategories of category (default: true)"
+// @Param tags query string false "Comma-separated tags the foods must have"
+// @Param tag_match query string false "all (default) or any"
 // @Param available query bool false "Filter by availability"
+// @Param favorite query bool false "Filter by favorite"
+// @Param compatible query bool false "Filter by compatibility with the user's dietary restrictions"
+// @Param allergen_free query string false "Comma-separated allergens the foods must not contain (instead of the user's restrictions)"
+// @Param diet query string false "Comma-separated diets the foods must suit (instead of the user's restrictions)"
 // @Param page query int false "Page number (default: 1)"
 // @Param page_size query int false "Page size (default: 20, max: 100)"
 // @Success 200 {object} utils.PaginatedResponse{data=[]model.Food}
@@ -259,58 +294,260 @@ func (h *FoodHandler) ListFoods(c *gin.Context) {
 		return
 	}
 
-	// Parse query parameters
-	filter := &model.FoodFilter{
-		Category: c.Query("category"),
+	filter, ok := h.parseFoodFilter(c, userID.(int64))
+	if !ok {
+		return
 	}
 
-	// Validate category if provided
-	if filter.Category != "" {
-		validCategories := map[string]bool{"meat": true, "vegetable": true, "fruit": true, "grain": true, "other": true}
-		if !validCategories[filter.Category] {
-			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid category, must be one of: meat, vegetable, fruit, grain, other", nil))
+	// List foods
+	foods, total, err := h.foodService.ListFoods(userID.(int64), filter)
+	if err != nil {
+		if errors.Is(err, service.ErrFoodCategoryNotFound) {
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, err.Error(), err))
 			return
 		}
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to list foods", err))
+		return
 	}
 
-	// Parse available filter
-	if availableStr := c.Query("available"); availableStr != "" {
-		if availableStr != "true" && availableStr != "false" {
-			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid available parameter, must be true or false", nil))
+	// Calculate pagination
+	pagination := utils.CalculatePagination(filter.Page, filter.PageSize, total)
+
+	utils.SuccessWithPagination(c, foods, pagination)
+}
+
+// SearchFoods handles GET /api/v1/foods/search
+// @Summary Search food items
+// @Description Search food names and aliases by prefix, substring, pinyin (for Chinese names) and with typo tolerance. Results are ranked by match quality, boosted by how often and how recently each food was eaten. Accepts the same filters as the food list.
+// @Tags foods
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search query"
+// @Param category query string false "Filter by category slug, including its subcategories"
+// @Param tags query string false "Comma-separated tags the foods must have"
+// @Param available query bool false "Filter by availability"
+// @Param compatible query bool false "Filter by compatibility with the user's dietary restrictions"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 20, max: 100)"
+// @Success 200 {object} utils.PaginatedResponse{data=[]model.FoodSearchResult}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/foods/search [get]
+func (h *FoodHandler) SearchFoods(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	query, ok := parseSearchQuery(c)
+	if !ok {
+		return
+	}
+
+	filter, ok := h.parseFoodFilter(c, userID.(int64))
+	if !ok {
+		return
+	}
+
+	results, total, err := h.searchService.Search(userID.(int64), query, filter)
+	if err != nil {
+		if errors.Is(err, service.ErrFoodCategoryNotFound) {
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, err.Error(), err))
 			return
 		}
-		available := availableStr == "true"
-		filter.Available = &available
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to search foods", err))
+		return
 	}
 
-	// Parse pagination with validation
-	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
-	if err != nil || page < 1 {
-		page = 1
+	// Calculate pagination
+	pagination := utils.CalculatePagination(filter.Page, filter.PageSize, total)
+
+	utils.SuccessWithPagination(c, results, pagination)
+}
+
+// AutocompleteFoods handles GET /api/v1/foods/autocomplete
+// @Summary Autocomplete food names for meal entry
+// @Description Suggest available foods for a partially typed name with the amount and unit last eaten, foods usually eaten at the given meal first
+// @Tags foods
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Partially typed food name"
+// @Param meal_type query string false "Meal being entered: breakfast, lunch, dinner or snack"
+// @Param limit query int false "Maximum number of suggestions (default: 10, max: 20)"
+// @Success 200 {object} utils.Response{data=[]model.FoodCompletion}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/foods/autocomplete [get]
+func (h *FoodHandler) AutocompleteFoods(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
 	}
 
-	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "20"))
-	if err != nil || pageSize < 1 {
-		pageSize = 20
+	query, ok := parseSearchQuery(c)
+	if !ok {
+		return
 	}
-	if pageSize > 100 {
-		pageSize = 100
+
+	mealType := c.Query("meal_type")
+	if mealType != "" && mealType != "breakfast" && mealType != "lunch" && mealType != "dinner" && mealType != "snack" {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid meal_type, must be breakfast, lunch, dinner or snack", nil))
+		return
 	}
 
-	filter.Page = page
-	filter.PageSize = pageSize
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid limit parameter", err))
+		return
+	}
 
-	// List foods
-	foods, total, err := h.foodService.ListFoods(userID.(int64), filter)
+	completions, err := h.searchService.Autocomplete(userID.(int64), query, mealType, limit)
 	if err != nil {
-		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to list foods", err))
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to autocomplete foods", err))
 		return
 	}
 
-	// Calculate pagination
-	pagination := utils.CalculatePagination(filter.Page, filter.PageSize, total)
+	utils.Success(c, completions)
+}
 
-	utils.SuccessWithPagination(c, foods, pagination)
+// GetSuggestions handles GET /api/v1/foods/suggestions
+// @Summary Suggest foods for a meal
+// @Description Suggest foods and whole meals for a meal type from the user's favorites and the meals logged at that meal type in the last 90 days, weighting the same weekday. Favorites come first.
+// @Tags foods
+// @Produce json
+// @Security BearerAuth
+// @Param meal_type query string true "Meal being logged: breakfast, lunch, dinner or snack"
+// @Param date query string false "Date of the meal (YYYY-MM-DD or ISO 8601, default: today)"
+// @Param limit query int false "Maximum number of suggested foods (default: 20, max: 50)"
+// @Success 200 {object} utils.Response{data=model.MealSuggestions}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/foods/suggestions [get]
+func (h *FoodHandler) GetSuggestions(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	mealType := c.Query("meal_type")
+	if mealType != "breakfast" && mealType != "lunch" && mealType != "dinner" && mealType != "snack" {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid meal_type, must be breakfast, lunch, dinner or snack", nil))
+		return
+	}
+
+	loc := middleware.GetUserLocation(c)
+	date := utils.StartOfDay(time.Now(), loc)
+	if dateStr := c.Query("date"); dateStr != "" {
+		parsed, err := utils.ParseDateToStartOfDayInLocation(dateStr, loc)
+		if err != nil {
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid date format, expected YYYY-MM-DD or ISO 8601", err))
+			return
+		}
+		date = parsed
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid limit parameter", err))
+		return
+	}
+
+	suggestions, err := h.suggestionService.GetMealSuggestions(userID.(int64), date, mealType, limit)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to get food suggestions", err))
+		return
+	}
+
+	utils.Success(c, suggestions)
+}
+
+// FavoriteFood handles PUT /api/v1/foods/:id/favorite
+// @Summary Favorite a food item
+// @Description Pin a food item so it is suggested first when logging meals
+// @Tags foods
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Food ID"
+// @Success 200 {object} utils.Response{data=model.Food}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/foods/{id}/favorite [put]
+func (h *FoodHandler) FavoriteFood(c *gin.Context) {
+	h.setFavorite(c, true)
+}
+
+// UnfavoriteFood handles DELETE /api/v1/foods/:id/favorite
+// @Summary Unfavorite a food item
+// @Description Unpin a favorite food item
+// @Tags foods
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Food ID"
+// @Success 200 {object} utils.Response{data=model.Food}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/foods/{id}/favorite [delete]
+func (h *FoodHandler) UnfavoriteFood(c *gin.Context) {
+	h.setFavorite(c, false)
+}
+
+// setFavorite sets or clears the favorite flag of the food in the path
+func (h *FoodHandler) setFavorite(c *gin.Context, favorite bool) {
+	// Parse food ID
+	foodID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid food id", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	food, err := h.foodService.SetFavorite(userID.(int64), foodID, favorite)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeNotFound, "food not found", err))
+		return
+	}
+
+	utils.Success(c, food)
+}
+
+// ListTags handles GET /api/v1/foods/tags
+// @Summary List food tags
+// @Description List the tags used on the user's foods with the number of foods carrying each
+// @Tags foods
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]model.FoodTag}
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/foods/tags [get]
+func (h *FoodHandler) ListTags(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	tags, err := h.foodService.ListTags(userID.(int64))
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to list food tags", err))
+		return
+	}
+
+	utils.Success(c, tags)
 }
 
 // BatchImport handles POST /api/v1/foods/batch
@@ -345,6 +582,8 @@ func (h *FoodHandler) BatchImport(c *gin.Context) {
 		foods[i] = &model.Food{
 			Name:      foodReq.Name,
 			Category:  foodReq.Category,
+			Aliases:   foodReq.Aliases,
+			Tags:      foodReq.Tags,
 			Price:     foodReq.Price,
 			Unit:      foodReq.Unit,
 			Protein:   foodReq.Protein,
@@ -352,6 +591,9 @@ func (h *FoodHandler) BatchImport(c *gin.Context) {
 			Fat:       foodReq.Fat,
 			Fiber:     foodReq.Fiber,
 			Calories:  foodReq.Calories,
+			WaterPct:  foodReq.WaterPct,
+			Allergens: foodReq.Allergens,
+			DietTags:  foodReq.DietTags,
 			Available: foodReq.Available,
 		}
 	}
@@ -366,6 +608,146 @@ func (h *FoodHandler) BatchImport(c *gin.Context) {
 	utils.Success(c, result)
 }
 
+// handleWriteError maps food category errors to invalid parameters
+func (h *FoodHandler) handleWriteError(c *gin.Context, err error, message string) {
+	if errors.Is(err, service.ErrFoodCategoryNotFound) {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, err.Error(), err))
+		return
+	}
+	utils.Error(c, utils.NewAppError(utils.CodeInternalError, message, err))
+}
+
+// parseFoodFilter parses the food list filters and pagination shared by ListFoods
+// and SearchFoods, responding with an error if they are invalid
+func (h *FoodHandler) parseFoodFilter(c *gin.Context, userID int64) (*model.FoodFilter, bool) {
+	// Parse query parameters
+	filter := &model.FoodFilter{
+		Category: c.Query("category"),
+		Tags:     splitQueryList(c.Query("tags")),
+	}
+
+	// Parse category subtree filter (a category matches its subcategories by default)
+	switch c.DefaultQuery("include_subcategories", "true") {
+	case "true":
+	case "false":
+		if filter.Category != "" {
+			filter.Categories = []string{filter.Category}
+		}
+	default:
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid include_subcategories parameter, must be true or false", nil))
+		return nil, false
+	}
+
+	// Parse tag match mode
+	switch c.DefaultQuery("tag_match", "all") {
+	case "all":
+	case "any":
+		filter.AnyTag = true
+	default:
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid tag_match parameter, must be all or any", nil))
+		return nil, false
+	}
+
+	// Parse available filter
+	if availableStr := c.Query("available"); availableStr != "" {
+		if availableStr != "true" && availableStr != "false" {
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid available parameter, must be true or false", nil))
+			return nil, false
+		}
+		available := availableStr == "true"
+		filter.Available = &available
+	}
+
+	// Parse favorite filter
+	if favoriteStr := c.Query("favorite"); favoriteStr != "" {
+		if favoriteStr != "true" && favoriteStr != "false" {
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid favorite parameter, must be true or false", nil))
+			return nil, false
+		}
+		favorite := favoriteStr == "true"
+		filter.Favorite = &favorite
+	}
+
+	// Parse compatibility filter: explicit allergens/diets, or the user's restrictions
+	allergenFree := splitQueryList(c.Query("allergen_free"))
+	diets := splitQueryList(c.Query("diet"))
+	compatibleStr := c.Query("compatible")
+	if compatibleStr != "" && compatibleStr != "true" && compatibleStr != "false" {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid compatible parameter, must be true or false", nil))
+		return nil, false
+	}
+
+	if len(allergenFree) > 0 || len(diets) > 0 {
+		for _, allergen := range allergenFree {
+			if !model.IsValidAllergen(allergen) {
+				utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid allergen: "+allergen, nil))
+				return nil, false
+			}
+		}
+		for _, diet := range diets {
+			if !model.IsValidDiet(diet) {
+				utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid diet: "+diet, nil))
+				return nil, false
+			}
+		}
+		filter.Restrictions = &model.DietaryRestrictions{Allergens: allergenFree, Diets: diets}
+		filter.Compatible = compatibleStr != "false"
+	} else if compatibleStr != "" {
+		restrictions, err := h.foodService.GetDietaryRestrictions(userID)
+		if err != nil {
+			utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to get dietary restrictions", err))
+			return nil, false
+		}
+		filter.Restrictions = &restrictions
+		filter.Compatible = compatibleStr == "true"
+	}
+
+	// Parse pagination with validation
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if err != nil || pageSize < 1 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	filter.Page = page
+	filter.PageSize = pageSize
+
+	return filter, true
+}
+
+// parseSearchQuery reads the required q parameter, responding with an error if it
+// is missing or too long
+func parseSearchQuery(c *gin.Context) (string, bool) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "q parameter is required", nil))
+		return "", false
+	}
+	if len([]rune(query)) > 100 {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "q parameter must be at most 100 characters", nil))
+		return "", false
+	}
+	return query, true
+}
+
+// splitQueryList splits a comma-separated query parameter, dropping empty items
+func splitQueryList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // RegisterRoutes registers food-related routes
 func (h *FoodHandler) RegisterRoutes(router *gin.RouterGroup) {
 	foods := router.Group("/foods")
@@ -376,5 +758,11 @@ func (h *FoodHandler) RegisterRoutes(router *gin.RouterGroup) {
 		foods.GET("/:id", h.GetFood)
 		foods.GET("", h.ListFoods)
 		foods.POST("/batch", h.BatchImport)
+		foods.GET("/tags", h.ListTags)
+		foods.GET("/search", h.SearchFoods)
+		foods.GET("/autocomplete", h.AutocompleteFoods)
+		foods.GET("/suggestions", h.GetSuggestions)
+		foods.PUT("/:id/favorite", h.FavoriteFood)
+		foods.DELETE("/:id/favorite", h.UnfavoriteFood)
 	}
 }
@@ -0,0 +1,398 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/middleware"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/service"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// GoalHandler handles nutrition goal history HTTP requests
+type GoalHandler struct {
+	goalService *service.GoalService
+}
+
+// NewGoalHandler creates a new GoalHandler instance
+func NewGoalHandler(goalService *service.GoalService) *GoalHandler {
+	return &GoalHandler{
+		goalService: goalService,
+	}
+}
+
+// ListGoals handles GET /api/v1/goals
+// @Summary List goal history
+// @Description List past, current and scheduled nutrition goal periods
+// @Tags goals
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]model.GoalPeriod}
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/goals [get]
+func (h *GoalHandler) ListGoals(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	today := utils.StartOfDay(time.Now(), middleware.GetUserLocation(c))
+	goals, err := h.goalService.ListGoals(userID.(int64), today)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to list nutrition goals", err))
+		return
+	}
+
+	utils.Success(c, goals)
+}
+
+// GetGoalsOn handles GET /api/v1/goals/effective
+// @Summary Get goals in effect on a date
+// @Description Get the nutrition goals and goal profile in effect on a date (default today)
+// @Tags goals
+// @Produce json
+// @Security BearerAuth
+// @Param date query string false "Date (YYYY-MM-DD or ISO 8601)"
+// @Success 200 {object} utils.Response{data=model.ResolvedGoals}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/goals/effective [get]
+func (h *GoalHandler) GetGoalsOn(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	loc := middleware.GetUserLocation(c)
+	date := utils.StartOfDay(time.Now(), loc)
+	if dateStr := c.Query("date"); dateStr != "" {
+		parsed, err := utils.ParseDateToStartOfDayInLocation(dateStr, loc)
+		if err != nil {
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid date format, expected YYYY-MM-DD or ISO 8601", err))
+			return
+		}
+		date = parsed
+	}
+
+	goals, err := h.goalService.GetGoalsOn(userID.(int64), date)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to get nutrition goals", err))
+		return
+	}
+
+	utils.Success(c, goals)
+}
+
+// ScheduleGoal handles POST /api/v1/goals
+// @Summary Schedule a goal change
+// @Description Add everyday or goal profile nutrition goals taking effect today or on a later date
+// @Tags goals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.ScheduleGoalRequest true "Goal period"
+// @Success 200 {object} utils.Response{data=model.GoalPeriod}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/goals [post]
+func (h *GoalHandler) ScheduleGoal(c *gin.Context) {
+	var req model.ScheduleGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	// effective_from is a calendar date in the user's timezone
+	loc := middleware.GetUserLocation(c)
+	req.EffectiveFrom = utils.DateInLocation(req.EffectiveFrom, loc)
+
+	goal, err := h.goalService.ScheduleGoal(userID.(int64), &req, utils.StartOfDay(time.Now(), loc))
+	if err != nil {
+		h.handleWriteError(c, err, "failed to schedule nutrition goal")
+		return
+	}
+
+	utils.Success(c, goal)
+}
+
+// DeleteGoal handles DELETE /api/v1/goals/:id
+// @Summary Delete a scheduled goal change
+// @Description Delete a goal period that has not taken effect yet
+// @Tags goals
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Goal period ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/goals/{id} [delete]
+func (h *GoalHandler) DeleteGoal(c *gin.Context) {
+	goalID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid goal id", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	today := utils.StartOfDay(time.Now(), middleware.GetUserLocation(c))
+	if err := h.goalService.DeleteGoal(userID.(int64), goalID, today); err != nil {
+		if errors.Is(err, service.ErrGoalNotScheduled) {
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, err.Error(), err))
+			return
+		}
+		utils.Error(c, utils.NewAppError(utils.CodeNotFound, "nutrition goal not found", err))
+		return
+	}
+
+	utils.SuccessWithMessage(c, "scheduled goal deleted successfully", nil)
+}
+
+// ListProfiles handles GET /api/v1/goals/profiles
+// @Summary List goal profiles
+// @Description List goal profiles with their goals in effect today and the weekdays assigned to them
+// @Tags goals
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]model.GoalProfile}
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/goals/profiles [get]
+func (h *GoalHandler) ListProfiles(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	today := utils.StartOfDay(time.Now(), middleware.GetUserLocation(c))
+	profiles, err := h.goalService.ListProfiles(userID.(int64), today)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to list goal profiles", err))
+		return
+	}
+
+	utils.Success(c, profiles)
+}
+
+// ListSchedules handles GET /api/v1/goals/schedule
+// @Summary List weekday schedules
+// @Description List past, current and scheduled weekday goal profile schedules
+// @Tags goals
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]model.GoalProfileSchedule}
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/goals/schedule [get]
+func (h *GoalHandler) ListSchedules(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	today := utils.StartOfDay(time.Now(), middleware.GetUserLocation(c))
+	schedules, err := h.goalService.ListSchedules(userID.(int64), today)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to list goal profile schedules", err))
+		return
+	}
+
+	utils.Success(c, schedules)
+}
+
+// SetSchedule handles PUT /api/v1/goals/schedule
+// @Summary Set weekday schedule
+// @Description Assign goal profiles to weekdays from today or a later date on
+// @Tags goals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.SetGoalProfileScheduleRequest true "Weekday schedule"
+// @Success 200 {object} utils.Response{data=model.GoalProfileSchedule}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/goals/schedule [put]
+func (h *GoalHandler) SetSchedule(c *gin.Context) {
+	var req model.SetGoalProfileScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	loc := middleware.GetUserLocation(c)
+	if req.EffectiveFrom != nil {
+		effectiveFrom := utils.DateInLocation(*req.EffectiveFrom, loc)
+		req.EffectiveFrom = &effectiveFrom
+	}
+
+	schedule, err := h.goalService.SetSchedule(userID.(int64), &req, utils.StartOfDay(time.Now(), loc))
+	if err != nil {
+		h.handleWriteError(c, err, "failed to set goal profile schedule")
+		return
+	}
+
+	utils.Success(c, schedule)
+}
+
+// ListOverrides handles GET /api/v1/goals/overrides
+// @Summary List per-date goal profile overrides
+// @Description List goal profiles assigned to single dates within a date range
+// @Tags goals
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Success 200 {object} utils.Response{data=[]model.GoalProfileOverride}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/goals/overrides [get]
+func (h *GoalHandler) ListOverrides(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	startDate, endDate, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+
+	overrides, err := h.goalService.ListOverrides(userID.(int64), startDate, endDate)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to list goal profile overrides", err))
+		return
+	}
+
+	utils.Success(c, overrides)
+}
+
+// SetOverride handles PUT /api/v1/goals/overrides
+// @Summary Assign a goal profile to a date
+// @Description Assign a goal profile to a single date, taking precedence over the weekday schedule
+// @Tags goals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.SetGoalProfileOverrideRequest true "Override"
+// @Success 200 {object} utils.Response{data=model.GoalProfileOverride}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/goals/overrides [put]
+func (h *GoalHandler) SetOverride(c *gin.Context) {
+	var req model.SetGoalProfileOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	// date is a calendar date in the user's timezone
+	req.Date = utils.DateInLocation(req.Date, middleware.GetUserLocation(c))
+
+	override, err := h.goalService.SetOverride(userID.(int64), &req)
+	if err != nil {
+		h.handleWriteError(c, err, "failed to set goal profile override")
+		return
+	}
+
+	utils.Success(c, override)
+}
+
+// DeleteOverride handles DELETE /api/v1/goals/overrides/:id
+// @Summary Delete a per-date goal profile override
+// @Description Delete an override so the date follows the weekday schedule again
+// @Tags goals
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Override ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/goals/overrides/{id} [delete]
+func (h *GoalHandler) DeleteOverride(c *gin.Context) {
+	overrideID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid override id", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	if err := h.goalService.DeleteOverride(userID.(int64), overrideID); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeNotFound, "goal profile override not found", err))
+		return
+	}
+
+	utils.SuccessWithMessage(c, "goal profile override deleted successfully", nil)
+}
+
+// handleWriteError maps goal service validation errors to invalid parameters
+func (h *GoalHandler) handleWriteError(c *gin.Context, err error, message string) {
+	if errors.Is(err, service.ErrGoalInPast) ||
+		errors.Is(err, service.ErrInvalidGoalProfile) ||
+		errors.Is(err, service.ErrUnknownGoalProfile) {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, err.Error(), err))
+		return
+	}
+	utils.Error(c, utils.NewAppError(utils.CodeInternalError, message, err))
+}
+
+// RegisterRoutes registers goal history and goal profile routes
+func (h *GoalHandler) RegisterRoutes(router *gin.RouterGroup) {
+	goals := router.Group("/goals")
+	{
+		goals.GET("", h.ListGoals)
+		goals.POST("", h.ScheduleGoal)
+		goals.GET("/effective", h.GetGoalsOn)
+		goals.GET("/profiles", h.ListProfiles)
+		goals.GET("/schedule", h.ListSchedules)
+		goals.PUT("/schedule", h.SetSchedule)
+		goals.GET("/overrides", h.ListOverrides)
+		goals.PUT("/overrides", h.SetOverride)
+		goals.DELETE("/overrides/:id", h.DeleteOverride)
+		goals.DELETE("/:id", h.DeleteGoal)
+	}
+}
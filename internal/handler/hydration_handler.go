@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/middleware"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/service"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// HydrationHandler handles water intake HTTP requests
+type HydrationHandler struct {
+	hydrationService *service.HydrationService
+}
+
+// NewHydrationHandler creates a new HydrationHandler instance
+func NewHydrationHandler(hydrationService *service.HydrationService) *HydrationHandler {
+	return &HydrationHandler{
+		hydrationService: hydrationService,
+	}
+}
+
+// CreateWaterLog handles POST /api/v1/hydration
+// @Summary Log a drink
+// @Description Log water or another drink. Beverages that are also foods (milk, juice) should be logged in meals, where they count toward hydration through the food's water_pct.
+// @Tags hydration
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.WaterLogRequest true "Drink"
+// @Success 200 {object} utils.Response{data=model.WaterLog}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/hydration [post]
+func (h *HydrationHandler) CreateWaterLog(c *gin.Context) {
+	var req model.WaterLogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	// log_date is a calendar date in the user's timezone
+	req.LogDate = utils.DateInLocation(req.LogDate, middleware.GetUserLocation(c))
+
+	log, err := h.hydrationService.CreateWaterLog(userID.(int64), &req)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to create water log", err))
+		return
+	}
+
+	utils.Success(c, log)
+}
+
+// UpdateWaterLog handles PUT /api/v1/hydration/:id
+// @Summary Update a water log entry
+// @Description Replace a water log entry
+// @Tags hydration
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Water log ID"
+// @Param request body model.WaterLogRequest true "Drink"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/hydration/{id} [put]
+func (h *HydrationHandler) UpdateWaterLog(c *gin.Context) {
+	logID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid water log id", err))
+		return
+	}
+
+	var req model.WaterLogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	req.LogDate = utils.DateInLocation(req.LogDate, middleware.GetUserLocation(c))
+
+	if err := h.hydrationService.UpdateWaterLog(userID.(int64), logID, &req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeNotFound, "water log not found", err))
+		return
+	}
+
+	utils.SuccessWithMessage(c, "water log updated successfully", nil)
+}
+
+// DeleteWaterLog handles DELETE /api/v1/hydration/:id
+// @Summary Delete a water log entry
+// @Description Delete a water log entry
+// @Tags hydration
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Water log ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/hydration/{id} [delete]
+func (h *HydrationHandler) DeleteWaterLog(c *gin.Context) {
+	logID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid water log id", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	if err := h.hydrationService.DeleteWaterLog(userID.(int64), logID); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeNotFound, "water log not found", err))
+		return
+	}
+
+	utils.SuccessWithMessage(c, "water log deleted successfully", nil)
+}
+
+// GetWaterLog handles GET /api/v1/hydration/:id
+// @Summary Get a water log entry
+// @Description Get a water log entry
+// @Tags hydration
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Water log ID"
+// @Success 200 {object} utils.Response{data=model.WaterLog}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/hydration/{id} [get]
+func (h *HydrationHandler) GetWaterLog(c *gin.Context) {
+	logID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid water log id", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	log, err := h.hydrationService.GetWaterLog(userID.(int64), logID)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeNotFound, "water log not found", err))
+		return
+	}
+
+	utils.Success(c, log)
+}
+
+// ListWaterLogs handles GET /api/v1/hydration
+// @Summary List water log entries
+// @Description List water log entries in a date range, oldest first
+// @Tags hydration
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string false "Start date (YYYY-MM-DD, default: 27 days before end_date)"
+// @Param end_date query string false "End date (YYYY-MM-DD, default: today)"
+// @Success 200 {object} utils.Response{data=[]model.WaterLog}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/hydration [get]
+func (h *HydrationHandler) ListWaterLogs(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	startDate, endDate, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+
+	logs, err := h.hydrationService.ListWaterLogs(userID.(int64), startDate, endDate)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to list water logs", err))
+		return
+	}
+
+	utils.Success(c, logs)
+}
+
+// GetDailyHydration handles GET /api/v1/hydration/daily
+// @Summary Get daily water intake
+// @Description Get water intake for each day in a date range against the daily water goal, including water from beverage foods in meals
+// @Tags hydration
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string false "Start date (YYYY-MM-DD, default: 27 days before end_date)"
+// @Param end_date query string false "End date (YYYY-MM-DD, default: today)"
+// @Success 200 {object} utils.Response{data=[]model.DailyHydration}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/hydration/daily [get]
+func (h *HydrationHandler) GetDailyHydration(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	startDate, endDate, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+
+	days, err := h.hydrationService.GetDailyHydration(userID.(int64), startDate, endDate)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to get daily water intake", err))
+		return
+	}
+
+	utils.Success(c, days)
+}
+
+// RegisterRoutes registers hydration routes
+func (h *HydrationHandler) RegisterRoutes(router *gin.RouterGroup) {
+	hydration := router.Group("/hydration")
+	{
+		hydration.POST("", h.CreateWaterLog)
+		hydration.GET("", h.ListWaterLogs)
+		hydration.GET("/daily", h.GetDailyHydration)
+		hydration.GET("/:id", h.GetWaterLog)
+		hydration.PUT("/:id", h.UpdateWaterLog)
+		hydration.DELETE("/:id", h.DeleteWaterLog)
+	}
+}
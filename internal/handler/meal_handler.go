@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 	"time"
 
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/middleware"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/service"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
@@ -28,6 +30,7 @@ type CreateMealRequest struct {
 	MealType string           `json:"meal_type" binding:"required,oneof=breakfast lunch dinner snack"`
 	Foods    []model.MealFood `json:"foods" binding:"required,gte=1,lte=50,dive"`
 	Notes    string           `json:"notes" binding:"omitempty,max=500"`
+	EatenAt  *time.Time       `json:"eaten_at"` // Optional; defaults to the preferred time for meal_type
 }
 
 // UpdateMealRequest represents the request body for updating a meal
@@ -36,6 +39,7 @@ type UpdateMealRequest struct {
 	MealType string           `json:"meal_type" binding:"required,oneof=breakfast lunch dinner snack"`
 	Foods    []model.MealFood `json:"foods" binding:"required,gte=1,lte=50,dive"`
 	Notes    string           `json:"notes" binding:"omitempty,max=500"`
+	EatenAt  *time.Time       `json:"eaten_at"` // Optional; defaults to the preferred time for meal_type
 }
 
 // CreateMeal handles POST /api/v1/meals
@@ -64,16 +68,21 @@ func (h *MealHandler) CreateMeal(c *gin.Context) {
 		return
 	}
 
-	// Convert request to model
+	// Convert request to model (meal_date is a calendar date in the user's timezone)
 	meal := &model.Meal{
-		MealDate: req.MealDate,
+		MealDate: utils.DateInLocation(req.MealDate, middleware.GetUserLocation(c)),
 		MealType: req.MealType,
 		Foods:    req.Foods,
 		Notes:    req.Notes,
+		EatenAt:  req.EatenAt,
 	}
 
 	// Create meal (nutrition will be calculated automatically)
 	if err := h.mealService.CreateMeal(userID.(int64), meal); err != nil {
+		if errors.Is(err, service.ErrDietaryRestrictionViolated) {
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, err.Error(), nil))
+			return
+		}
 		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to create meal", err))
 		return
 	}
@@ -116,20 +125,30 @@ func (h *MealHandler) UpdateMeal(c *gin.Context) {
 		return
 	}
 
-	// Convert request to model
+	// Convert request to model (meal_date is a calendar date in the user's timezone)
 	meal := &model.Meal{
-		MealDate: req.MealDate,
+		MealDate: utils.DateInLocation(req.MealDate, middleware.GetUserLocation(c)),
 		MealType: req.MealType,
 		Foods:    req.Foods,
 		Notes:    req.Notes,
+		EatenAt:  req.EatenAt,
 	}
 
 	// Update meal (nutrition will be recalculated automatically)
 	if err := h.mealService.UpdateMeal(userID.(int64), mealID, meal); err != nil {
+		if errors.Is(err, service.ErrDietaryRestrictionViolated) {
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, err.Error(), nil))
+			return
+		}
 		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to update meal", err))
 		return
 	}
 
+	if len(meal.Warnings) > 0 {
+		utils.SuccessWithMessage(c, "meal updated successfully", gin.H{"warnings": meal.Warnings})
+		return
+	}
+
 	utils.SuccessWithMessage(c, "meal updated successfully", nil)
 }
 
@@ -246,9 +265,10 @@ func (h *MealHandler) ListMeals(c *gin.Context) {
 		}
 	}
 
-	// Parse date filters
+	// Parse date filters in the user's timezone
+	loc := middleware.GetUserLocation(c)
 	if startDateStr := c.Query("start_date"); startDateStr != "" {
-		startDate, err := utils.ParseDateToStartOfDay(startDateStr)
+		startDate, err := utils.ParseDateToStartOfDayInLocation(startDateStr, loc)
 		if err != nil {
 			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid start_date format, expected YYYY-MM-DD or ISO 8601", err))
 			return
@@ -257,7 +277,7 @@ func (h *MealHandler) ListMeals(c *gin.Context) {
 	}
 
 	if endDateStr := c.Query("end_date"); endDateStr != "" {
-		endDate, err := utils.ParseDateToEndOfDay(endDateStr)
+		endDate, err := utils.ParseDateToEndOfDayInLocation(endDateStr, loc)
 		if err != nil {
 			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid end_date format, expected YYYY-MM-DD or ISO 8601", err))
 			return
@@ -301,11 +321,75 @@ func (h *MealHandler) ListMeals(c *gin.Context) {
 	utils.SuccessWithPagination(c, meals, pagination)
 }
 
+// GetMealTiming handles GET /api/v1/meals/timing
+// @Summary Get meal timing analytics
+// @Description Get eating window, first/last meal times and late-night eating for a date range
+// @Tags meals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string false "Start date (YYYY-MM-DD, default: 27 days before end_date)"
+// @Param end_date query string false "End date (YYYY-MM-DD, default: today)"
+// @Success 200 {object} utils.Response{data=model.MealTimingStats}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/meals/timing [get]
+func (h *MealHandler) GetMealTiming(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	startDate, endDate, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.mealService.GetMealTimingStats(userID.(int64), startDate, endDate, middleware.GetUserLocation(c))
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to get meal timing stats", err))
+		return
+	}
+
+	utils.Success(c, stats)
+}
+
+// GetMealReminders handles GET /api/v1/meals/reminders
+// @Summary Get today's meal reminders
+// @Description Get today's preferred meal times and whether each meal is logged or due
+// @Tags meals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]model.MealReminder}
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/meals/reminders [get]
+func (h *MealHandler) GetMealReminders(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	reminders, err := h.mealService.GetMealReminders(userID.(int64), time.Now(), middleware.GetUserLocation(c))
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to get meal reminders", err))
+		return
+	}
+
+	utils.Success(c, reminders)
+}
+
 // RegisterRoutes registers meal-related routes
 func (h *MealHandler) RegisterRoutes(router *gin.RouterGroup) {
 	meals := router.Group("/meals")
 	{
 		meals.POST("", h.CreateMeal)
+		meals.GET("/timing", h.GetMealTiming)
+		meals.GET("/reminders", h.GetMealReminders)
 		meals.PUT("/:id", h.UpdateMeal)
 		meals.DELETE("/:id", h.DeleteMeal)
 		meals.GET("/:id", h.GetMeal)
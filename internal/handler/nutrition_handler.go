@@ -4,8 +4,8 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/middleware"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
-	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/service"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
 	"github.com/gin-gonic/gin"
@@ -14,14 +14,14 @@ import (
 // NutritionHandler handles nutrition analysis and statistics HTTP requests
 type NutritionHandler struct {
 	nutritionService *service.NutritionService
-	prefsRepo        repository.UserPreferencesRepository
+	goalService      *service.GoalService
 }
 
 // NewNutritionHandler creates a new NutritionHandler instance
-func NewNutritionHandler(nutritionService *service.NutritionService, prefsRepo repository.UserPreferencesRepository) *NutritionHandler {
+func NewNutritionHandler(nutritionService *service.NutritionService, goalService *service.GoalService) *NutritionHandler {
 	return &NutritionHandler{
 		nutritionService: nutritionService,
-		prefsRepo:        prefsRepo,
+		goalService:      goalService,
 	}
 }
 
@@ -45,9 +45,9 @@ func (h *NutritionHandler) GetDailyNutrition(c *gin.Context) {
 		return
 	}
 
-	// Parse date parameter
+	// Parse date parameter in the user's timezone
 	dateStr := c.Param("date")
-	date, err := utils.ParseDateToStartOfDay(dateStr)
+	date, err := utils.ParseDateToStartOfDayInLocation(dateStr, middleware.GetUserLocation(c))
 	if err != nil {
 		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid date format, expected YYYY-MM-DD or ISO 8601", err))
 		return
@@ -60,6 +60,15 @@ func (h *NutritionHandler) GetDailyNutrition(c *gin.Context) {
 		return
 	}
 
+	// Attach the goals in effect on that day, flexed by the day's exercise
+	calendar, err := h.goalService.GetCalendar(userID.(int64), date, date)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to get nutrition goals", err))
+		return
+	}
+	goals := calendar.OnWithActivity(date, stats.CaloriesBurned)
+	stats.Goal = &goals
+
 	utils.Success(c, stats)
 }
 
@@ -106,18 +115,31 @@ func (h *NutritionHandler) GetMonthlyNutrition(c *gin.Context) {
 	}
 
 	// Get monthly nutrition trend
-	dailyStats, err := h.nutritionService.GetMonthlyTrend(userID.(int64), year, month)
+	dailyStats, err := h.nutritionService.GetMonthlyTrend(userID.(int64), year, month, middleware.GetUserLocation(c))
 	if err != nil {
 		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to get monthly nutrition trend", err))
 		return
 	}
 
+	// Attach the goals in effect on each day, flexed by the day's exercise
+	if len(dailyStats) > 0 {
+		calendar, err := h.goalService.GetCalendar(userID.(int64), dailyStats[0].Date, dailyStats[len(dailyStats)-1].Date)
+		if err != nil {
+			utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to get nutrition goals", err))
+			return
+		}
+		for _, stats := range dailyStats {
+			goals := calendar.OnWithActivity(stats.Date, stats.CaloriesBurned)
+			stats.Goal = &goals
+		}
+	}
+
 	utils.Success(c, dailyStats)
 }
 
 // CompareNutrition handles GET /api/v1/nutrition/compare
 // @Summary Compare actual nutrition with target
-// @Description Compare actual nutrition intake with user's target values for a date range
+// @Description Compare actual nutrition intake with user's target values for a date range. Each day uses the goals in effect on that day.
 // @Tags nutrition
 // @Accept json
 // @Produce json
@@ -143,16 +165,17 @@ func (h *NutritionHandler) CompareNutrition(c *gin.Context) {
 
 	var startDate, endDate time.Time
 	var err error
+	loc := middleware.GetUserLocation(c)
 
 	// If start_date and end_date are provided, use date range
 	if startDateStr != "" && endDateStr != "" {
-		startDate, err = utils.ParseDateToStartOfDay(startDateStr)
+		startDate, err = utils.ParseDateToStartOfDayInLocation(startDateStr, loc)
 		if err != nil {
 			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid start_date format, expected YYYY-MM-DD or ISO 8601", err))
 			return
 		}
 
-		endDate, err = utils.ParseDateToEndOfDay(endDateStr)
+		endDate, err = utils.ParseDateToEndOfDayInLocation(endDateStr, loc)
 		if err != nil {
 			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid end_date format, expected YYYY-MM-DD or ISO 8601", err))
 			return
@@ -165,7 +188,7 @@ func (h *NutritionHandler) CompareNutrition(c *gin.Context) {
 		}
 	} else if singleDateStr != "" {
 		// Backward compatibility: single date parameter
-		startDate, err = utils.ParseDateToStartOfDay(singleDateStr)
+		startDate, err = utils.ParseDateToStartOfDayInLocation(singleDateStr, loc)
 		if err != nil {
 			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid date format, expected YYYY-MM-DD or ISO 8601", err))
 			return
@@ -176,9 +199,17 @@ func (h *NutritionHandler) CompareNutrition(c *gin.Context) {
 		return
 	}
 
-	// Get actual nutrition for the date range
+	// Each day is judged against the goals (and goal profile) in effect on that day
+	calendar, err := h.goalService.GetCalendar(userID.(int64), startDate, endDate)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to get nutrition goals", err))
+		return
+	}
+
+	// Get actual nutrition and goals for the date range
 	// For now, we'll aggregate the stats across the date range
-	var totalNutrition model.NutritionData
+	var totalNutrition, totalTarget model.NutritionData
+	profileDays := make(map[string]int)
 	dayCount := 0
 
 	for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
@@ -193,11 +224,23 @@ func (h *NutritionHandler) CompareNutrition(c *gin.Context) {
 		totalNutrition.Carbs += stats.Nutrition.Carbs
 		totalNutrition.Fat += stats.Nutrition.Fat
 		totalNutrition.Fiber += stats.Nutrition.Fiber
+
+		resolved := calendar.OnWithActivity(d, stats.CaloriesBurned)
+		if resolved.Profile != model.BaseGoalProfile {
+			profileDays[resolved.Profile]++
+		}
+		goals := resolved.ToNutritionData()
+		totalTarget.Calories += goals.Calories
+		totalTarget.Protein += goals.Protein
+		totalTarget.Carbs += goals.Carbs
+		totalTarget.Fat += goals.Fat
+		totalTarget.Fiber += goals.Fiber
 		dayCount++
 	}
 
 	// Calculate average if multiple days
 	var avgNutrition model.NutritionData
+	target := calendar.On(startDate).ToNutritionData()
 	if dayCount > 0 {
 		avgNutrition = model.NutritionData{
 			Calories: totalNutrition.Calories / float64(dayCount),
@@ -206,56 +249,67 @@ func (h *NutritionHandler) CompareNutrition(c *gin.Context) {
 			Fat:      totalNutrition.Fat / float64(dayCount),
 			Fiber:    totalNutrition.Fiber / float64(dayCount),
 		}
+		target = model.NutritionData{
+			Calories: totalTarget.Calories / float64(dayCount),
+			Protein:  totalTarget.Protein / float64(dayCount),
+			Carbs:    totalTarget.Carbs / float64(dayCount),
+			Fat:      totalTarget.Fat / float64(dayCount),
+			Fiber:    totalTarget.Fiber / float64(dayCount),
+		}
 	}
 
-	// Get user preferences to get target nutrition values
-	prefs, err := h.prefsRepo.GetPreferences(userID.(int64))
+	// Compare actual with target
+	comparison, err := h.nutritionService.CompareWithTarget(&avgNutrition, &target)
 	if err != nil {
-		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to get user preferences", err))
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to compare nutrition", err))
 		return
 	}
+	if len(profileDays) > 0 {
+		comparison.ProfileDays = profileDays
+	}
+
+	utils.Success(c, comparison)
+}
 
-	// If no preferences set, use default values
-	targetCalories := 2000.0
-	targetProtein := 150.0
-	targetCarbs := 250.0
-	targetFat := 70.0
-	targetFiber := 25.0
+// GetCategoryIntake handles GET /api/v1/nutrition/categories
+// @Summary Get intake by food category
+// @Description Break down the nutrition eaten over a date range by food category. level=top rolls subcategories up into their top-level category; level=leaf reports each category separately.
+// @Tags nutrition
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string false "Start date (YYYY-MM-DD or ISO 8601, default: 27 days before end_date)"
+// @Param end_date query string false "End date (YYYY-MM-DD or ISO 8601, default: today)"
+// @Param level query string false "Breakdown level: top or leaf (default: top)"
+// @Success 200 {object} utils.Response{data=model.CategoryIntakeReport}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/nutrition/categories [get]
+func (h *NutritionHandler) GetCategoryIntake(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
 
-	if prefs != nil {
-		if prefs.DailyCaloriesGoal > 0 {
-			targetCalories = float64(prefs.DailyCaloriesGoal)
-		}
-		if prefs.DailyProteinGoal > 0 {
-			targetProtein = float64(prefs.DailyProteinGoal)
-		}
-		if prefs.DailyCarbsGoal > 0 {
-			targetCarbs = float64(prefs.DailyCarbsGoal)
-		}
-		if prefs.DailyFatGoal > 0 {
-			targetFat = float64(prefs.DailyFatGoal)
-		}
-		if prefs.DailyFiberGoal > 0 {
-			targetFiber = float64(prefs.DailyFiberGoal)
-		}
+	startDate, endDate, ok := parseReportRange(c)
+	if !ok {
+		return
 	}
 
-	target := &model.NutritionData{
-		Calories: targetCalories,
-		Protein:  targetProtein,
-		Carbs:    targetCarbs,
-		Fat:      targetFat,
-		Fiber:    targetFiber,
+	level := c.DefaultQuery("level", model.CategoryLevelTop)
+	if level != model.CategoryLevelTop && level != model.CategoryLevelLeaf {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid level, expected top or leaf", nil))
+		return
 	}
 
-	// Compare actual with target
-	comparison, err := h.nutritionService.CompareWithTarget(&avgNutrition, target)
+	report, err := h.nutritionService.GetCategoryIntake(userID.(int64), startDate, endDate, level)
 	if err != nil {
-		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to compare nutrition", err))
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to get category intake", err))
 		return
 	}
 
-	utils.Success(c, comparison)
+	utils.Success(c, report)
 }
 
 // RegisterRoutes registers nutrition-related routes
@@ -265,5 +319,6 @@ func (h *NutritionHandler) RegisterRoutes(router *gin.RouterGroup) {
 		nutrition.GET("/daily/:date", h.GetDailyNutrition)
 		nutrition.GET("/monthly", h.GetMonthlyNutrition)
 		nutrition.GET("/compare", h.CompareNutrition)
+		nutrition.GET("/categories", h.GetCategoryIntake)
 	}
 }
@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/middleware"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/service"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// OIDCHandler OpenID Connect 单点登录处理器
+type OIDCHandler struct {
+	oidcService service.OIDCService
+}
+
+// NewOIDCHandler 创建 OpenID Connect 单点登录处理器实例
+func NewOIDCHandler(oidcService service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+	}
+}
+
+// OIDCCallbackRequest 身份提供方回调请求，前端将回调地址中的 code 和 state 原样提交
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" binding:"required,max=2048"`
+	State string `json:"state" binding:"required,max=128"`
+}
+
+// GetProvider 获取单点登录信息
+// @Summary 获取单点登录信息
+// @Description 获取是否启用了 OpenID Connect 单点登录以及登录按钮上显示的身份提供方名称
+// @Tags 认证
+// @Produce json
+// @Success 200 {object} utils.Response{data=model.OIDCProviderInfo}
+// @Router /api/v1/auth/oidc/provider [get]
+func (h *OIDCHandler) GetProvider(c *gin.Context) {
+	utils.Success(c, h.oidcService.GetProviderInfo())
+}
+
+// StartLogin 发起单点登录
+// @Summary 发起单点登录
+// @Description 生成身份提供方的授权地址（授权码流程，使用 PKCE），前端跳转到该地址；授权流程在有效期内只能完成一次
+// @Tags 认证
+// @Produce json
+// @Success 200 {object} utils.Response{data=model.OIDCAuthorization}
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /api/v1/auth/oidc/authorize [post]
+func (h *OIDCHandler) StartLogin(c *gin.Context) {
+	authorization, err := h.oidcService.StartLogin(c.Request.Context())
+	if err != nil {
+		utils.Error(c, oidcAppError(err, "failed to start oidc login"))
+		return
+	}
+
+	utils.Success(c, authorization)
+}
+
+// CompleteLogin 完成单点登录
+// @Summary 完成单点登录
+// @Description 提交身份提供方回调的 code 和 state 完成登录。外部身份未关联账户时，开放注册则自动创建账户，否则拒绝登录；启用了两步验证的用户会得到 MFA 挑战令牌，需要调用 /auth/login/2fa 完成登录
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body OIDCCallbackRequest true "回调请求"
+// @Success 200 {object} utils.Response{data=utils.TokenPair}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /api/v1/auth/oidc/callback [post]
+func (h *OIDCHandler) CompleteLogin(c *gin.Context) {
+	var req OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	result, err := h.oidcService.CompleteLogin(c.Request.Context(), req.Code, req.State, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		utils.Error(c, oidcAppError(err, "oidc login failed"))
+		return
+	}
+
+	if result.MFARequired {
+		utils.SuccessWithMessage(c, "two-factor authentication required", MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    result.MFAToken,
+			ExpiresIn:   result.ExpiresIn,
+		})
+		return
+	}
+
+	utils.Success(c, result.TokenPair)
+}
+
+// ListIdentities 获取关联的外部身份
+// @Summary 获取关联的外部身份
+// @Description 获取当前用户关联的单点登录身份
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]model.UserIdentity}
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/auth/identities [get]
+func (h *OIDCHandler) ListIdentities(c *gin.Context) {
+	identities, err := h.oidcService.ListIdentities(c.Request.Context(), middleware.MustGetUserID(c))
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to list identities", err))
+		return
+	}
+
+	utils.Success(c, identities)
+}
+
+// StartLink 发起外部身份关联
+// @Summary 发起外部身份关联
+// @Description 为当前用户生成身份提供方的授权地址，回调后调用 /auth/identities/oidc/callback 完成关联
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=model.OIDCAuthorization}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /api/v1/auth/identities/oidc/authorize [post]
+func (h *OIDCHandler) StartLink(c *gin.Context) {
+	authorization, err := h.oidcService.StartLink(c.Request.Context(), middleware.MustGetUserID(c))
+	if err != nil {
+		utils.Error(c, oidcAppError(err, "failed to start identity linking"))
+		return
+	}
+
+	utils.Success(c, authorization)
+}
+
+// CompleteLink 完成外部身份关联
+// @Summary 完成外部身份关联
+// @Description 提交身份提供方回调的 code 和 state，将外部身份关联到当前用户；授权流程必须由当前用户发起
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body OIDCCallbackRequest true "回调请求"
+// @Success 200 {object} utils.Response{data=model.UserIdentity}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /api/v1/auth/identities/oidc/callback [post]
+func (h *OIDCHandler) CompleteLink(c *gin.Context) {
+	var req OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	identity, err := h.oidcService.CompleteLink(c.Request.Context(), middleware.MustGetUserID(c), req.Code, req.State)
+	if err != nil {
+		utils.Error(c, oidcAppError(err, "failed to link identity"))
+		return
+	}
+
+	utils.SuccessWithMessage(c, "identity linked successfully", identity)
+}
+
+// UnlinkIdentity 取消关联外部身份
+// @Summary 取消关联外部身份
+// @Description 取消关联指定的外部身份，之后无法再通过该身份登录
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "外部身份 ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/auth/identities/{id} [delete]
+func (h *OIDCHandler) UnlinkIdentity(c *gin.Context) {
+	identityID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || identityID <= 0 {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid identity id", err))
+		return
+	}
+
+	if err := h.oidcService.UnlinkIdentity(c.Request.Context(), middleware.MustGetUserID(c), identityID); err != nil {
+		if errors.Is(err, service.ErrIdentityNotFound) {
+			utils.Error(c, utils.NewAppError(utils.CodeNotFound, "identity not found", err))
+			return
+		}
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to unlink identity", err))
+		return
+	}
+
+	utils.SuccessWithMessage(c, "identity unlinked successfully", nil)
+}
+
+// oidcAppError 将单点登录相关的服务层错误转换为应用错误
+func oidcAppError(err error, fallback string) *utils.AppError {
+	switch {
+	case errors.Is(err, service.ErrOIDCDisabled):
+		return utils.NewAppError(utils.CodeForbidden, "oidc login is not enabled", err)
+	case errors.Is(err, service.ErrInvalidOIDCState):
+		return utils.NewAppError(utils.CodeInvalidParams, "invalid or expired login state", err)
+	case errors.Is(err, service.ErrOIDCAuthenticationFailed):
+		return utils.NewAppError(utils.CodeUnauthorized, "oidc authentication failed", err)
+	case errors.Is(err, service.ErrOIDCAccountNotLinked):
+		return utils.NewAppError(utils.CodeForbidden, "no account is linked to this identity", err)
+	case errors.Is(err, service.ErrAccountDisabled):
+		return utils.NewAppError(utils.CodeForbidden, "account has been disabled", err)
+	case errors.Is(err, service.ErrIdentityAlreadyLinked):
+		return utils.NewAppError(utils.CodeConflict, "identity is already linked to an account", err)
+	case errors.Is(err, service.ErrOIDCProviderUnavailable):
+		return utils.NewAppError(utils.CodeAIServiceError, "identity provider is unavailable", err)
+	default:
+		return utils.NewAppError(utils.CodeInternalError, fallback, err)
+	}
+}
+
+// RegisterPublicRoutes 注册不需要认证的单点登录路由
+func (h *OIDCHandler) RegisterPublicRoutes(router *gin.RouterGroup) {
+	oidc := router.Group("/auth/oidc")
+	{
+		oidc.GET("/provider", h.GetProvider)
+		oidc.POST("/authorize", h.StartLogin)
+		oidc.POST("/callback", h.CompleteLogin)
+	}
+}
+
+// RegisterRoutes 注册外部身份关联路由（需要认证）
+func (h *OIDCHandler) RegisterRoutes(router *gin.RouterGroup) {
+	identities := router.Group("/auth/identities")
+	{
+		identities.GET("", h.ListIdentities)
+		identities.POST("/oidc/authorize", h.StartLink)
+		identities.POST("/oidc/callback", h.CompleteLink)
+		identities.DELETE("/:id", h.UnlinkIdentity)
+	}
+}
@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 	"time"
 
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/middleware"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/service"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
@@ -28,6 +30,14 @@ type GeneratePlanRequest struct {
 	Preferences string `json:"preferences" binding:"omitempty,max=500"`
 }
 
+// CreatePlanRequest represents the request body for creating a plan
+type CreatePlanRequest struct {
+	PlanDate    time.Time        `json:"plan_date" binding:"required"`
+	MealType    string           `json:"meal_type" binding:"required,oneof=breakfast lunch dinner snack"`
+	Foods       []model.MealFood `json:"foods" binding:"required,gte=1,lte=50,dive"`
+	AIReasoning string           `json:"ai_reasoning" binding:"omitempty,max=1000"`
+}
+
 // UpdatePlanRequest represents the request body for updating a plan
 type UpdatePlanRequest struct {
 	PlanDate    time.Time        `json:"plan_date" binding:"required"`
@@ -37,6 +47,53 @@ type UpdatePlanRequest struct {
 	AIReasoning string           `json:"ai_reasoning" binding:"omitempty,max=1000"`
 }
 
+// CreatePlan handles POST /api/v1/plans
+// @Summary Create a plan record
+// @Description Create a pending meal plan; foods are checked against the user's dietary restrictions
+// @Tags plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreatePlanRequest true "Plan creation request"
+// @Success 200 {object} utils.Response{data=model.Plan}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/plans [post]
+func (h *PlanHandler) CreatePlan(c *gin.Context) {
+	var req CreatePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	// Convert request to model (plan_date is a calendar date in the user's timezone)
+	plan := &model.Plan{
+		PlanDate:    utils.DateInLocation(req.PlanDate, middleware.GetUserLocation(c)),
+		MealType:    req.MealType,
+		Foods:       req.Foods,
+		AIReasoning: req.AIReasoning,
+	}
+
+	// Create plan (nutrition will be calculated automatically)
+	if err := h.planService.CreatePlan(userID.(int64), plan); err != nil {
+		if errors.Is(err, service.ErrDietaryRestrictionViolated) {
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, err.Error(), nil))
+			return
+		}
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to create plan", err))
+		return
+	}
+
+	utils.Success(c, plan)
+}
+
 // GeneratePlan handles POST /api/v1/plans/generate
 // @Summary Generate meal plans using AI
 // @Description Generate meal plans for future days based on available foods and preferences
@@ -155,9 +212,10 @@ func (h *PlanHandler) ListPlans(c *gin.Context) {
 		}
 	}
 
-	// Parse date filters
+	// Parse date filters in the user's timezone
+	loc := middleware.GetUserLocation(c)
 	if startDateStr := c.Query("start_date"); startDateStr != "" {
-		startDate, err := utils.ParseDateToStartOfDay(startDateStr)
+		startDate, err := utils.ParseDateToStartOfDayInLocation(startDateStr, loc)
 		if err != nil {
 			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid start_date format, expected YYYY-MM-DD or ISO 8601", err))
 			return
@@ -166,7 +224,7 @@ func (h *PlanHandler) ListPlans(c *gin.Context) {
 	}
 
 	if endDateStr := c.Query("end_date"); endDateStr != "" {
-		endDate, err := utils.ParseDateToEndOfDay(endDateStr)
+		endDate, err := utils.ParseDateToEndOfDayInLocation(endDateStr, loc)
 		if err != nil {
 			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid end_date format, expected YYYY-MM-DD or ISO 8601", err))
 			return
@@ -245,9 +303,9 @@ func (h *PlanHandler) UpdatePlan(c *gin.Context) {
 		return
 	}
 
-	// Convert request to model
+	// Convert request to model (plan_date is a calendar date in the user's timezone)
 	plan := &model.Plan{
-		PlanDate:    req.PlanDate,
+		PlanDate:    utils.DateInLocation(req.PlanDate, middleware.GetUserLocation(c)),
 		MealType:    req.MealType,
 		Foods:       req.Foods,
 		Status:      req.Status,
@@ -256,10 +314,19 @@ func (h *PlanHandler) UpdatePlan(c *gin.Context) {
 
 	// Update plan (nutrition will be recalculated automatically)
 	if err := h.planService.UpdatePlan(userID.(int64), planID, plan); err != nil {
+		if errors.Is(err, service.ErrDietaryRestrictionViolated) {
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, err.Error(), nil))
+			return
+		}
 		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to update plan", err))
 		return
 	}
 
+	if len(plan.Warnings) > 0 {
+		utils.SuccessWithMessage(c, "plan updated successfully", gin.H{"warnings": plan.Warnings})
+		return
+	}
+
 	utils.SuccessWithMessage(c, "plan updated successfully", nil)
 }
 
@@ -338,11 +405,153 @@ func (h *PlanHandler) CompletePlan(c *gin.Context) {
 	utils.SuccessWithMessage(c, "plan completed and meal record created", meal)
 }
 
+// GetAdherenceStats handles GET /api/v1/plans/adherence
+// @Summary Get plan adherence statistics
+// @Description Get planned vs. actually eaten meals per week for a date range (defaults to the last 4 weeks)
+// @Tags plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string false "Start date (YYYY-MM-DD or ISO 8601)"
+// @Param end_date query string false "End date (YYYY-MM-DD or ISO 8601)"
+// @Success 200 {object} utils.Response{data=model.PlanAdherenceStats}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/plans/adherence [get]
+func (h *PlanHandler) GetAdherenceStats(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	startDate, endDate, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.planService.GetAdherenceStats(userID.(int64), startDate, endDate)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to get adherence stats", err))
+		return
+	}
+
+	utils.Success(c, stats)
+}
+
+// GetPlanReport handles GET /api/v1/plans/report
+// @Summary Get plan-vs-actual report
+// @Description Compare plans with the meals actually eaten: per-meal and per-day nutrition deltas, adherence, most skipped meal types and substituted foods (defaults to the last 4 weeks)
+// @Tags plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string false "Start date (YYYY-MM-DD or ISO 8601)"
+// @Param end_date query string false "End date (YYYY-MM-DD or ISO 8601)"
+// @Success 200 {object} utils.Response{data=model.PlanReport}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/plans/report [get]
+func (h *PlanHandler) GetPlanReport(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	startDate, endDate, ok := parseReportRange(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.planService.GetPlanReport(userID.(int64), startDate, endDate)
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to get plan report", err))
+		return
+	}
+
+	utils.Success(c, report)
+}
+
+// ReconcilePlans handles POST /api/v1/plans/reconcile
+// @Summary Reconcile past pending plans
+// @Description Complete past pending plans that have a matching meal record (linking the two) and skip the rest
+// @Tags plans
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=model.PlanReconcileResult}
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/plans/reconcile [post]
+func (h *PlanHandler) ReconcilePlans(c *gin.Context) {
+	// Get user ID from context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "user not authenticated", nil))
+		return
+	}
+
+	result, err := h.planService.ReconcilePlans(userID.(int64), time.Now())
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to reconcile plans", err))
+		return
+	}
+
+	utils.SuccessWithMessage(c, "plans reconciled successfully", result)
+}
+
+// parseReportRange parses the start_date/end_date query of report endpoints,
+// defaulting to the last 4 weeks including today. It writes the error response
+// and returns false if the range is invalid.
+func parseReportRange(c *gin.Context) (time.Time, time.Time, bool) {
+	// Default to the last 4 weeks including today in the user's timezone
+	loc := middleware.GetUserLocation(c)
+	endDate := utils.StartOfDay(time.Now(), loc)
+	startDate := endDate.AddDate(0, 0, -27)
+
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		parsed, err := utils.ParseDateToStartOfDayInLocation(startDateStr, loc)
+		if err != nil {
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid start_date format, expected YYYY-MM-DD or ISO 8601", err))
+			return time.Time{}, time.Time{}, false
+		}
+		startDate = parsed
+	}
+
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		parsed, err := utils.ParseDateToStartOfDayInLocation(endDateStr, loc)
+		if err != nil {
+			utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid end_date format, expected YYYY-MM-DD or ISO 8601", err))
+			return time.Time{}, time.Time{}, false
+		}
+		endDate = parsed
+	}
+
+	// Validate date range
+	if endDate.Before(startDate) {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "end_date must be after or equal to start_date", nil))
+		return time.Time{}, time.Time{}, false
+	}
+
+	if endDate.Sub(startDate) > 366*24*time.Hour {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "date range cannot exceed 366 days", nil))
+		return time.Time{}, time.Time{}, false
+	}
+
+	return startDate, endDate, true
+}
+
 // RegisterRoutes registers plan-related routes
 func (h *PlanHandler) RegisterRoutes(router *gin.RouterGroup) {
 	plans := router.Group("/plans")
 	{
+		plans.POST("", h.CreatePlan)
 		plans.POST("/generate", h.GeneratePlan)
+		plans.GET("/adherence", h.GetAdherenceStats)
+		plans.GET("/report", h.GetPlanReport)
+		plans.POST("/reconcile", h.ReconcilePlans)
 		plans.GET("/:id", h.GetPlan)
 		plans.GET("", h.ListPlans)
 		plans.PUT("/:id", h.UpdatePlan)
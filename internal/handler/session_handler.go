@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/middleware"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/service"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// SessionHandler 登录会话处理器
+type SessionHandler struct {
+	sessionService service.SessionService
+}
+
+// NewSessionHandler 创建登录会话处理器实例
+func NewSessionHandler(sessionService service.SessionService) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+// ListSessions 获取登录会话列表
+// @Summary 获取登录会话列表
+// @Description 获取当前用户所有有效的登录会话（设备），current 为 true 的是发起请求的会话
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]model.Session}
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/auth/sessions [get]
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	sessions, err := h.sessionService.ListSessions(c.Request.Context(), middleware.MustGetUserID(c), middleware.GetSessionID(c))
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to list sessions", err))
+		return
+	}
+
+	utils.Success(c, sessions)
+}
+
+// RevokeSession 撤销登录会话
+// @Summary 撤销登录会话
+// @Description 撤销指定的登录会话，该会话的 Access Token 和 Refresh Token 立即失效；撤销当前会话等同于登出
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "会话 ID"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/auth/sessions/{id} [delete]
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	sessionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || sessionID <= 0 {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid session id", err))
+		return
+	}
+
+	err = h.sessionService.RevokeSession(c.Request.Context(), middleware.MustGetUserID(c), sessionID, model.SessionRevokedByUser)
+	if err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			utils.Error(c, utils.NewAppError(utils.CodeNotFound, "session not found", err))
+			return
+		}
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to revoke session", err))
+		return
+	}
+
+	utils.SuccessWithMessage(c, "session revoked successfully", nil)
+}
+
+// RevokeOtherSessions 撤销其他所有登录会话
+// @Summary 撤销其他所有登录会话
+// @Description 撤销除当前会话之外的所有登录会话，用于在其他设备上退出登录
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=map[string]int}
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/auth/sessions/others [delete]
+func (h *SessionHandler) RevokeOtherSessions(c *gin.Context) {
+	revoked, err := h.sessionService.RevokeOtherSessions(c.Request.Context(), middleware.MustGetUserID(c), middleware.GetSessionID(c))
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to revoke sessions", err))
+		return
+	}
+
+	utils.SuccessWithMessage(c, "other sessions revoked successfully", gin.H{
+		"revoked": revoked,
+	})
+}
+
+// RegisterRoutes 注册登录会话路由（需要认证）
+func (h *SessionHandler) RegisterRoutes(router *gin.RouterGroup) {
+	sessions := router.Group("/auth/sessions")
+	{
+		sessions.GET("", h.ListSessions)
+		sessions.DELETE("/others", h.RevokeOtherSessions)
+		sessions.DELETE("/:id", h.RevokeSession)
+	}
+}
@@ -324,6 +324,104 @@ func (h *SettingsHandler) UpdateUserPreferences(c *gin.Context) {
 		prefs.DailyFiberGoal = 30
 	}
 
+	if req.DailyWaterGoalML > 0 {
+		prefs.DailyWaterGoalML = req.DailyWaterGoalML
+	} else if existing != nil && existing.DailyWaterGoalML > 0 {
+		prefs.DailyWaterGoalML = existing.DailyWaterGoalML
+	} else {
+		prefs.DailyWaterGoalML = model.DefaultDailyWaterGoalML
+	}
+
+	prefs.PlanReconcileTime = req.PlanReconcileTime
+	if prefs.PlanReconcileTime == "" && existing != nil {
+		prefs.PlanReconcileTime = existing.PlanReconcileTime
+	}
+
+	prefs.Timezone = req.Timezone
+	if prefs.Timezone == "" && existing != nil {
+		prefs.Timezone = existing.Timezone
+	}
+
+	prefs.WeightUnit = req.WeightUnit
+	if prefs.WeightUnit == "" && existing != nil {
+		prefs.WeightUnit = existing.WeightUnit
+	}
+
+	prefs.LengthUnit = req.LengthUnit
+	if prefs.LengthUnit == "" && existing != nil {
+		prefs.LengthUnit = existing.LengthUnit
+	}
+
+	// 能量计算个人资料：未提供的字段保留原值
+	prefs.Sex = req.Sex
+	if prefs.Sex == "" && existing != nil {
+		prefs.Sex = existing.Sex
+	}
+
+	prefs.BirthDate = req.BirthDate
+	if prefs.BirthDate == "" && existing != nil {
+		prefs.BirthDate = existing.BirthDate
+	}
+
+	prefs.HeightCm = req.HeightCm
+	if prefs.HeightCm == nil && existing != nil {
+		prefs.HeightCm = existing.HeightCm
+	}
+
+	prefs.ActivityLevel = req.ActivityLevel
+	if prefs.ActivityLevel == "" && existing != nil {
+		prefs.ActivityLevel = existing.ActivityLevel
+	}
+
+	prefs.WeightGoal = req.WeightGoal
+	if prefs.WeightGoal == "" && existing != nil {
+		prefs.WeightGoal = existing.WeightGoal
+	}
+
+	if req.AdaptiveTDEE != nil {
+		prefs.AdaptiveTDEE = *req.AdaptiveTDEE
+	} else if existing != nil {
+		prefs.AdaptiveTDEE = existing.AdaptiveTDEE
+	}
+
+	if req.ExerciseFlexPct != nil {
+		prefs.ExerciseFlexPct = *req.ExerciseFlexPct
+	} else if existing != nil {
+		prefs.ExerciseFlexPct = existing.ExerciseFlexPct
+	}
+
+	// 饮食限制：未提供时保留原值，提供空数组时清空
+	prefs.Allergens = req.Allergens
+	if prefs.Allergens == nil && existing != nil {
+		prefs.Allergens = existing.Allergens
+	}
+
+	prefs.Diets = req.Diets
+	if prefs.Diets == nil && existing != nil {
+		prefs.Diets = existing.Diets
+	}
+
+	prefs.RestrictionMode = req.RestrictionMode
+	if prefs.RestrictionMode == "" && existing != nil {
+		prefs.RestrictionMode = existing.RestrictionMode
+	}
+
+	// 目标体重通过 /body-metrics/goal 设置，这里保留原值
+	if existing != nil {
+		prefs.GoalWeightKg = existing.GoalWeightKg
+	}
+
+	// 偏好用餐时间按餐次合并，未提供的餐次保留原值
+	prefs.PreferredMealTimes = make(map[string]string)
+	if existing != nil {
+		for mealType, mealTime := range existing.PreferredMealTimes {
+			prefs.PreferredMealTimes[mealType] = mealTime
+		}
+	}
+	for mealType, mealTime := range req.PreferredMealTimes {
+		prefs.PreferredMealTimes[mealType] = mealTime
+	}
+
 	// 更新偏好
 	err := h.settingsService.UpdateUserPreferences(c.Request.Context(), userID.(int64), prefs)
 	if err != nil {
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/middleware"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/service"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// TwoFactorHandler 两步验证处理器
+type TwoFactorHandler struct {
+	twoFactorService service.TwoFactorService
+}
+
+// NewTwoFactorHandler 创建两步验证处理器实例
+func NewTwoFactorHandler(twoFactorService service.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: twoFactorService,
+	}
+}
+
+// TwoFactorCodeRequest 两步验证码请求
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required,min=6,max=20"` // 6 位验证码或恢复码
+}
+
+// DisableTwoFactorRequest 关闭两步验证请求
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required,max=128"`
+	Code     string `json:"code" binding:"required,min=6,max=20"` // 6 位验证码或恢复码
+}
+
+// GetStatus 获取两步验证状态
+// @Summary 获取两步验证状态
+// @Description 获取当前用户是否启用了两步验证以及剩余的恢复码数量
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=model.TwoFactorStatus}
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/auth/2fa [get]
+func (h *TwoFactorHandler) GetStatus(c *gin.Context) {
+	status, err := h.twoFactorService.GetStatus(c.Request.Context(), middleware.MustGetUserID(c))
+	if err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, "failed to get two-factor status", err))
+		return
+	}
+
+	utils.Success(c, status)
+}
+
+// Setup 开始设置两步验证
+// @Summary 开始设置两步验证
+// @Description 生成新的 TOTP 密钥和 otpauth URI，用身份验证器应用扫描后调用 /auth/2fa/enable 提交验证码完成启用
+// @Tags 认证
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=model.TwoFactorSetup}
+// @Failure 401 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /api/v1/auth/2fa/setup [post]
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	username, _ := middleware.GetUsername(c)
+
+	setup, err := h.twoFactorService.Setup(c.Request.Context(), middleware.MustGetUserID(c), username)
+	if err != nil {
+		h.handleError(c, err, "failed to set up two-factor authentication")
+		return
+	}
+
+	utils.Success(c, setup)
+}
+
+// Enable 启用两步验证
+// @Summary 启用两步验证
+// @Description 提交身份验证器中的验证码启用两步验证，返回 10 个恢复码（只显示这一次）
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body TwoFactorCodeRequest true "验证码"
+// @Success 200 {object} utils.Response{data=model.RecoveryCodes}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /api/v1/auth/2fa/enable [post]
+func (h *TwoFactorHandler) Enable(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	codes, err := h.twoFactorService.Enable(c.Request.Context(), middleware.MustGetUserID(c), req.Code)
+	if err != nil {
+		h.handleError(c, err, "failed to enable two-factor authentication")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "two-factor authentication enabled", codes)
+}
+
+// Disable 关闭两步验证
+// @Summary 关闭两步验证
+// @Description 提交密码和验证码（或恢复码）关闭两步验证，密钥和恢复码会被删除
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body DisableTwoFactorRequest true "关闭两步验证请求"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/auth/2fa/disable [post]
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var req DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	if err := h.twoFactorService.Disable(c.Request.Context(), middleware.MustGetUserID(c), req.Password, req.Code); err != nil {
+		h.handleError(c, err, "failed to disable two-factor authentication")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "two-factor authentication disabled", nil)
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+// @Summary 重新生成恢复码
+// @Description 提交验证码后生成 10 个新的恢复码，旧的恢复码全部作废
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body TwoFactorCodeRequest true "验证码"
+// @Success 200 {object} utils.Response{data=model.RecoveryCodes}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /api/v1/auth/2fa/recovery-codes [post]
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, "invalid request parameters", err))
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(c.Request.Context(), middleware.MustGetUserID(c), req.Code)
+	if err != nil {
+		h.handleError(c, err, "failed to regenerate recovery codes")
+		return
+	}
+
+	utils.SuccessWithMessage(c, "recovery codes regenerated", codes)
+}
+
+// handleError 将两步验证服务的错误映射为响应
+func (h *TwoFactorHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidTwoFactorCode):
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "invalid two-factor code", err))
+	case errors.Is(err, repository.ErrInvalidPassword):
+		utils.Error(c, utils.NewAppError(utils.CodeUnauthorized, "password is incorrect", err))
+	case errors.Is(err, service.ErrTwoFactorAlreadyEnabled):
+		utils.Error(c, utils.NewAppError(utils.CodeConflict, err.Error(), err))
+	case errors.Is(err, service.ErrTwoFactorNotEnabled), errors.Is(err, service.ErrTwoFactorNotSetUp):
+		utils.Error(c, utils.NewAppError(utils.CodeInvalidParams, err.Error(), err))
+	default:
+		utils.Error(c, utils.NewAppError(utils.CodeInternalError, message, err))
+	}
+}
+
+// RegisterRoutes 注册两步验证路由（需要认证）
+func (h *TwoFactorHandler) RegisterRoutes(router *gin.RouterGroup) {
+	twoFactor := router.Group("/auth/2fa")
+	{
+		twoFactor.GET("", h.GetStatus)
+		twoFactor.POST("/setup", h.Setup)
+		twoFactor.POST("/enable", h.Enable)
+		twoFactor.POST("/disable", h.Disable)
+		twoFactor.POST("/recovery-codes", h.RegenerateRecoveryCodes)
+	}
+}
@@ -0,0 +1,51 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileMailer 将邮件追加写入本地文件，用于本地开发和测试，不会真正发送邮件
+type FileMailer struct {
+	from string
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileMailer 创建写入文件的邮件发送器
+func NewFileMailer(from, path string) *FileMailer {
+	return &FileMailer{
+		from: from,
+		path: path,
+	}
+}
+
+// Send 将邮件以可读的纯文本形式追加到文件
+func (m *FileMailer) Send(ctx context.Context, msg *Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open mail file: %w", err)
+	}
+	defer f.Close()
+
+	var b strings.Builder
+	b.WriteString("==================== " + time.Now().Format(time.RFC3339) + " ====================\n")
+	b.WriteString("From: " + sanitizeHeader(m.from) + "\n")
+	b.WriteString("To: " + sanitizeHeader(msg.To) + "\n")
+	b.WriteString("Subject: " + sanitizeHeader(msg.Subject) + "\n\n")
+	b.WriteString(msg.Body)
+	b.WriteString("\n\n")
+
+	if _, err := f.WriteString(b.String()); err != nil {
+		return fmt.Errorf("failed to write mail file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+// Package mailer 提供发送邮件的抽象，支持 SMTP 和写入本地文件两种实现
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/config"
+)
+
+// Message 邮件内容（纯文本）
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer 邮件发送接口
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// New 根据配置创建邮件发送器
+func New(cfg *config.MailConfig) (Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPMailer(cfg.From, &cfg.SMTP), nil
+	case "file":
+		return NewFileMailer(cfg.From, cfg.FilePath), nil
+	default:
+		return nil, fmt.Errorf("unsupported mail driver: %s", cfg.Driver)
+	}
+}
+
+// buildMessage 生成 RFC 5322 格式的邮件，正文使用 base64 编码以支持中文
+func buildMessage(from string, msg *Message, now time.Time) []byte {
+	var buf bytes.Buffer
+
+	writeHeader(&buf, "From", from)
+	writeHeader(&buf, "To", msg.To)
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", sanitizeHeader(msg.Subject)))
+	writeHeader(&buf, "Date", now.Format(time.RFC1123Z))
+	writeHeader(&buf, "MIME-Version", "1.0")
+	writeHeader(&buf, "Content-Type", `text/plain; charset="utf-8"`)
+	writeHeader(&buf, "Content-Transfer-Encoding", "base64")
+	buf.WriteString("\r\n")
+
+	// base64 正文按 76 字符换行
+	encoded := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76])
+		buf.WriteString("\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded)
+	buf.WriteString("\r\n")
+
+	return buf.Bytes()
+}
+
+// writeHeader 写入一个邮件头
+func writeHeader(buf *bytes.Buffer, key, value string) {
+	buf.WriteString(key)
+	buf.WriteString(": ")
+	buf.WriteString(sanitizeHeader(value))
+	buf.WriteString("\r\n")
+}
+
+// sanitizeHeader 移除邮件头中的换行符，防止邮件头注入
+func sanitizeHeader(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"strings"
 
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
 	"github.com/gin-gonic/gin"
 )
@@ -13,11 +14,19 @@ const (
 	ContextKeyUserID = "user_id"
 	// ContextKeyUsername 用户名的 context key
 	ContextKeyUsername = "username"
+	// ContextKeySessionID 登录会话 ID 的 context key
+	ContextKeySessionID = "session_id"
+	// ContextKeyAPITokenID 个人访问令牌 ID 的 context key（仅令牌认证的请求）
+	ContextKeyAPITokenID = "api_token_id"
+	// ContextKeyAPITokenScopes 个人访问令牌权限范围的 context key（仅令牌认证的请求）
+	ContextKeyAPITokenScopes = "api_token_scopes"
 )
 
-// AuthMiddleware JWT 认证中间件
+// AuthMiddleware 认证中间件，同时接受 JWT 访问令牌和个人访问令牌（pat_ 前缀）
 func AuthMiddleware(jwtService *utils.JWTService, authService interface {
 	ValidateToken(ctx context.Context, token string) (*utils.Claims, error)
+}, apiTokenService interface {
+	Authenticate(ctx context.Context, token, ipAddress string) (*model.APIToken, error)
 }) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 从 Authorization 头获取 token
@@ -46,6 +55,39 @@ func AuthMiddleware(jwtService *utils.JWTService, authService interface {
 
 		token := parts[1]
 
+		// 个人访问令牌认证，权限范围由 RequireScope 在各路由组检查
+		if utils.IsAPIToken(token) {
+			apiToken, err := apiTokenService.Authenticate(c.Request.Context(), token, c.ClientIP())
+			if err != nil {
+				var message string
+				if err == utils.ErrAPITokenExpired {
+					message = "api token has expired"
+				} else if err == utils.ErrAccountDisabled {
+					message = "account has been disabled"
+				} else if err == utils.ErrAccountPendingDeletion {
+					message = "account is scheduled for deletion, login again to cancel"
+				} else {
+					message = "invalid api token"
+				}
+				utils.Error(c, utils.NewAppError(
+					utils.CodeUnauthorized,
+					message,
+					err,
+				))
+				c.Abort()
+				return
+			}
+
+			c.Set(ContextKeyUserID, apiToken.UserID)
+			c.Set(ContextKeyUsername, apiToken.Username)
+			c.Set(ContextKeyAPITokenID, apiToken.ID)
+			c.Set(ContextKeyAPITokenScopes, apiToken.Scopes)
+			setRequestUser(c, apiToken.UserID, apiToken.Username, apiToken.ID)
+
+			c.Next()
+			return
+		}
+
 		// 使用 authService 验证 token（包括黑名单和密码版本检查）
 		claims, err := authService.ValidateToken(c.Request.Context(), token)
 		if err != nil {
@@ -54,6 +96,12 @@ func AuthMiddleware(jwtService *utils.JWTService, authService interface {
 				message = "token has expired"
 			} else if err == utils.ErrPasswordChanged {
 				message = "password has been changed, please login again"
+			} else if err == utils.ErrAccountDisabled {
+				message = "account has been disabled"
+			} else if err == utils.ErrAccountPendingDeletion {
+				message = "account is scheduled for deletion, login again to cancel"
+			} else if err == utils.ErrSessionRevoked {
+				message = "session has been revoked, please login again"
 			} else {
 				message = "invalid token"
 			}
@@ -69,6 +117,8 @@ func AuthMiddleware(jwtService *utils.JWTService, authService interface {
 		// 将用户信息注入到 context
 		c.Set(ContextKeyUserID, claims.UserID)
 		c.Set(ContextKeyUsername, claims.Username)
+		c.Set(ContextKeySessionID, claims.SessionID)
+		setRequestUser(c, claims.UserID, claims.Username, 0)
 
 		c.Next()
 	}
@@ -94,6 +144,16 @@ func GetUsername(c *gin.Context) (string, bool) {
 	return name, ok
 }
 
+// GetSessionID 从 context 获取登录会话 ID，升级前签发的令牌没有会话 ID，返回 0
+func GetSessionID(c *gin.Context) int64 {
+	sessionID, exists := c.Get(ContextKeySessionID)
+	if !exists {
+		return 0
+	}
+	id, _ := sessionID.(int64)
+	return id
+}
+
 // MustGetUserID 从 context 获取用户 ID，如果不存在则 panic
 func MustGetUserID(c *gin.Context) int64 {
 	userID, ok := GetUserID(c)
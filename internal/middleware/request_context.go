@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestContextMiddleware 请求信息中间件
+// 将客户端 IP 和用户代理写入请求的 context，AuthMiddleware 认证成功后补充用户信息，
+// 服务层通过 utils.RequestInfoFromContext 读取（用于审计日志）
+func RequestContextMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		info := &utils.RequestInfo{
+			IPAddress: c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}
+		c.Request = c.Request.WithContext(utils.WithRequestInfo(c.Request.Context(), info))
+		c.Next()
+	}
+}
+
+// setRequestUser 在请求 context 的请求信息中记录认证用户
+func setRequestUser(c *gin.Context, userID int64, username string, apiTokenID int64) {
+	if info := utils.RequestInfoFromContext(c.Request.Context()); info != nil {
+		info.UserID = userID
+		info.Username = username
+		info.APITokenID = apiTokenID
+	}
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireScope 个人访问令牌权限范围检查中间件
+// 此中间件必须在 AuthMiddleware 之后使用；JWT 认证的请求不受限制。
+// 令牌认证的请求：GET/HEAD 需要 read 或 writeScope，其他方法需要 writeScope；
+// writeScope 为空表示该路由组对令牌只读
+func RequireScope(writeScope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scopes, isAPIToken := GetAPITokenScopes(c)
+		if !isAPIToken {
+			c.Next()
+			return
+		}
+
+		allowed := false
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			allowed = hasScope(scopes, model.APITokenScopeRead) || (writeScope != "" && hasScope(scopes, writeScope))
+		} else {
+			allowed = writeScope != "" && hasScope(scopes, writeScope)
+		}
+
+		if !allowed {
+			message := "api token does not have the required scope"
+			if writeScope != "" {
+				message += ": " + writeScope
+			}
+			utils.Error(c, utils.NewAppError(
+				utils.CodeForbidden,
+				message,
+				nil,
+			))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RejectAPIToken 拒绝个人访问令牌的中间件
+// 用于设置、管理、两步验证、会话和令牌管理等只允许登录用户本人操作的路由组
+func RejectAPIToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, isAPIToken := GetAPITokenScopes(c); isAPIToken {
+			utils.Error(c, utils.NewAppError(
+				utils.CodeForbidden,
+				"api tokens are not allowed for this endpoint",
+				nil,
+			))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GetAPITokenScopes 从 context 获取个人访问令牌的权限范围，第二个返回值表示请求是否使用令牌认证
+func GetAPITokenScopes(c *gin.Context) ([]string, bool) {
+	scopes, exists := c.Get(ContextKeyAPITokenScopes)
+	if !exists {
+		return nil, false
+	}
+	s, ok := scopes.([]string)
+	return s, ok
+}
+
+// hasScope 检查权限范围列表是否包含指定的权限范围
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
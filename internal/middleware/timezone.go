@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// ContextKeyUserLocation 用户时区的 context key
+	ContextKeyUserLocation = "user_location"
+)
+
+// UserLocationMiddleware 用户时区中间件
+// 根据用户偏好中的时区设置加载 *time.Location 并存入上下文
+// 此中间件必须在 AuthMiddleware 之后使用；未设置或无效的时区回退为服务器时区
+func UserLocationMiddleware(prefsRepo repository.UserPreferencesRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		loc := time.Local
+
+		if userID, ok := GetUserID(c); ok {
+			prefs, err := prefsRepo.GetPreferences(userID)
+			if err == nil && prefs != nil {
+				if userLoc, err := utils.LoadLocation(prefs.Timezone); err == nil {
+					loc = userLoc
+				}
+			}
+		}
+
+		c.Set(ContextKeyUserLocation, loc)
+		c.Next()
+	}
+}
+
+// GetUserLocation 从 context 获取用户时区，未设置时返回服务器时区
+func GetUserLocation(c *gin.Context) *time.Location {
+	if value, exists := c.Get(ContextKeyUserLocation); exists {
+		if loc, ok := value.(*time.Location); ok {
+			return loc
+		}
+	}
+	return time.Local
+}
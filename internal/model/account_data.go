@@ -0,0 +1,145 @@
+package model
+
+import "time"
+
+// AccountArchiveVersion 账户数据归档格式版本，格式不兼容的变化时递增
+const AccountArchiveVersion = 1
+
+// AccountArchiveFileName ZIP 归档中账户数据文件的名称
+const AccountArchiveFileName = "account.json"
+
+// 账户数据导出格式
+const (
+	AccountExportFormatJSON = "json"
+	AccountExportFormatZIP  = "zip"
+)
+
+// 账户数据导出状态
+const (
+	AccountExportStatusPending    = "pending"    // 等待生成
+	AccountExportStatusProcessing = "processing" // 正在生成
+	AccountExportStatusCompleted  = "completed"  // 可以下载
+	AccountExportStatusFailed     = "failed"     // 生成失败
+)
+
+// AccountExport 账户数据导出任务
+type AccountExport struct {
+	ID          int64      `json:"id" db:"id"`
+	UserID      int64      `json:"user_id" db:"user_id"`
+	Format      string     `json:"format" db:"format"`
+	Status      string     `json:"status" db:"status"`
+	FileName    string     `json:"-" db:"file_name"` // 导出目录中的文件名
+	FileSize    int64      `json:"file_size" db:"file_size"`
+	RecordCount int        `json:"record_count" db:"record_count"`
+	Error       string     `json:"error,omitempty" db:"error_message"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	ExpiresAt   time.Time  `json:"expires_at" db:"expires_at"`
+}
+
+// CreateAccountExportRequest 创建账户数据导出请求
+type CreateAccountExportRequest struct {
+	Format string `json:"format" binding:"omitempty,oneof=json zip"`
+}
+
+// AccountArchive 账户数据归档
+// 包含用户资料、偏好、自定义食材分类、食材、餐饮记录、饮食计划、断食、身体指标、运动、饮水记录、
+// 营养目标历史和目标配置安排、AI 设置（不含 API 密钥）、对话流和消息；
+// 记录保留原始 ID，导入时重新分配 ID，并按原始 ID 更新餐饮记录、计划和断食中的引用
+type AccountArchive struct {
+	Version        int                    `json:"version"`
+	ExportedAt     time.Time              `json:"exported_at"`
+	Profile        *ArchiveProfile        `json:"profile"`
+	Preferences    *UserPreferences       `json:"preferences,omitempty"`
+	FoodCategories []*ArchiveFoodCategory `json:"food_categories"`
+	Foods          []*Food                `json:"foods"`
+	Meals          []*Meal                `json:"meals"`
+	Plans          []*Plan                `json:"plans"`
+	Fasts          []*Fast                `json:"fasts"`
+	BodyMetrics    []*BodyMetric          `json:"body_metrics"`
+	Activities     []*Activity            `json:"activities"`
+	WaterLogs      []*WaterLog            `json:"water_logs"`
+	Goals          []*GoalPeriod          `json:"goals"`          // 所有目标配置的目标历史
+	GoalSchedules  []*GoalProfileSchedule `json:"goal_schedules"` // 按星期安排目标配置的历史
+	GoalOverrides  []*GoalProfileOverride `json:"goal_overrides"` // 按日期指定的目标配置
+	AISettings     []*ArchiveAISettings   `json:"ai_settings"`
+	Conversations  []*ArchiveConversation `json:"conversations"`
+}
+
+// RecordCount 返回归档中的记录数（不含资料和偏好）
+func (a *AccountArchive) RecordCount() int {
+	count := len(a.FoodCategories) + len(a.Foods) + len(a.Meals) + len(a.Plans) +
+		len(a.Fasts) + len(a.BodyMetrics) + len(a.Activities) + len(a.WaterLogs) +
+		len(a.Goals) + len(a.GoalSchedules) + len(a.GoalOverrides) +
+		len(a.AISettings) + len(a.Conversations)
+	for _, conv := range a.Conversations {
+		count += len(conv.Messages)
+	}
+	return count
+}
+
+// ArchiveProfile 归档中的用户资料，仅供参考，导入时不会修改目标账户的用户名和邮箱
+type ArchiveProfile struct {
+	Username  string    `json:"username"`
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ArchiveFoodCategory 归档中的自定义食材分类
+// 上级分类按标识引用（可以是内置分类），因为内置分类的 ID 在不同的系统中可能不同
+type ArchiveFoodCategory struct {
+	Slug      string `json:"slug"`
+	Name      string `json:"name"`
+	Parent    string `json:"parent,omitempty"` // 上级分类的标识，为空表示顶级分类
+	SortOrder int    `json:"sort_order"`
+}
+
+// ArchiveAISettings 归档中的 AI 设置，不包含 API 密钥
+type ArchiveAISettings struct {
+	Provider    string    `json:"provider"`
+	APIEndpoint string    `json:"api_endpoint,omitempty"`
+	Model       string    `json:"model"`
+	Temperature float64   `json:"temperature"`
+	MaxTokens   int       `json:"max_tokens"`
+	IsActive    bool      `json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ArchiveConversation 归档中的对话流
+type ArchiveConversation struct {
+	ID          int64             `json:"id"`
+	Title       string            `json:"title"`
+	IsFavorited bool              `json:"is_favorited"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	Messages    []*ArchiveMessage `json:"messages"`
+}
+
+// ArchiveMessage 归档中的消息，不包含原始请求和响应
+type ArchiveMessage struct {
+	Role      string    `json:"role"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AccountImportResult 账户数据导入结果
+type AccountImportResult struct {
+	FoodCategories      int      `json:"food_categories"`
+	Foods               int      `json:"foods"`
+	Meals               int      `json:"meals"`
+	Plans               int      `json:"plans"`
+	Fasts               int      `json:"fasts"`
+	BodyMetrics         int      `json:"body_metrics"`
+	Activities          int      `json:"activities"`
+	WaterLogs           int      `json:"water_logs"`
+	Goals               int      `json:"goals"`
+	GoalSchedules       int      `json:"goal_schedules"`
+	GoalOverrides       int      `json:"goal_overrides"`
+	AISettings          int      `json:"ai_settings"`
+	Conversations       int      `json:"conversations"`
+	Messages            int      `json:"messages"`
+	UnmappedFoodRefs    int      `json:"unmapped_food_refs"`  // 引用了归档中不存在的食材的条目数，这些条目的 food_id 置为 0
+	UnmappedCategories  int      `json:"unmapped_categories"` // 分类不存在的食材数，这些食材的分类改为 other
+	PreferencesRestored bool     `json:"preferences_restored"`
+	Warnings            []string `json:"warnings,omitempty"`
+}
@@ -0,0 +1,83 @@
+package model
+
+import "time"
+
+// Activity intensity levels
+const (
+	IntensityLow      = "low"
+	IntensityModerate = "moderate"
+	IntensityHigh     = "high"
+)
+
+// ActivityType describes a built-in activity type and its MET values per intensity
+type ActivityType struct {
+	Name string             `json:"name"`
+	METs map[string]float64 `json:"mets"` // Intensity => MET
+}
+
+// ActivityTypes lists the built-in activity types with MET values from the
+// Compendium of Physical Activities. "other" is used for unlisted activities.
+var ActivityTypes = []ActivityType{
+	{Name: "walking", METs: map[string]float64{IntensityLow: 2.8, IntensityModerate: 3.5, IntensityHigh: 5.0}},
+	{Name: "running", METs: map[string]float64{IntensityLow: 6.0, IntensityModerate: 9.8, IntensityHigh: 11.5}},
+	{Name: "cycling", METs: map[string]float64{IntensityLow: 4.0, IntensityModerate: 6.8, IntensityHigh: 10.0}},
+	{Name: "swimming", METs: map[string]float64{IntensityLow: 5.8, IntensityModerate: 7.0, IntensityHigh: 9.8}},
+	{Name: "strength_training", METs: map[string]float64{IntensityLow: 3.5, IntensityModerate: 5.0, IntensityHigh: 6.0}},
+	{Name: "hiit", METs: map[string]float64{IntensityLow: 6.0, IntensityModerate: 8.0, IntensityHigh: 10.0}},
+	{Name: "yoga", METs: map[string]float64{IntensityLow: 2.5, IntensityModerate: 3.0, IntensityHigh: 4.0}},
+	{Name: "hiking", METs: map[string]float64{IntensityLow: 5.3, IntensityModerate: 6.0, IntensityHigh: 7.8}},
+	{Name: "rowing", METs: map[string]float64{IntensityLow: 4.8, IntensityModerate: 7.0, IntensityHigh: 8.5}},
+	{Name: "elliptical", METs: map[string]float64{IntensityLow: 4.6, IntensityModerate: 5.0, IntensityHigh: 7.0}},
+	{Name: "dancing", METs: map[string]float64{IntensityLow: 3.5, IntensityModerate: 5.0, IntensityHigh: 7.3}},
+	{Name: "team_sports", METs: map[string]float64{IntensityLow: 4.0, IntensityModerate: 7.0, IntensityHigh: 8.0}},
+	{Name: "other", METs: map[string]float64{IntensityLow: 3.0, IntensityModerate: 4.5, IntensityHigh: 6.5}},
+}
+
+// FindActivityMET returns the MET value for an activity type and intensity.
+// Unlisted activity types use the "other" values.
+func FindActivityMET(activityType, intensity string) float64 {
+	other := ActivityTypes[len(ActivityTypes)-1]
+	for _, t := range ActivityTypes {
+		if t.Name == activityType {
+			return t.METs[intensity]
+		}
+	}
+	return other.METs[intensity]
+}
+
+// Activity represents a logged workout or activity
+type Activity struct {
+	ID                int64      `json:"id" db:"id"`
+	UserID            int64      `json:"user_id" db:"user_id"`
+	ActivityType      string     `json:"activity_type" db:"activity_type"`
+	PerformedOn       time.Time  `json:"performed_on" db:"performed_on"`
+	StartedAt         *time.Time `json:"started_at,omitempty" db:"started_at"`
+	DurationMinutes   int        `json:"duration_minutes" db:"duration_minutes"`
+	Intensity         string     `json:"intensity" db:"intensity"`
+	MET               *float64   `json:"met,omitempty" db:"met"` // Set when calories were estimated
+	CaloriesBurned    float64    `json:"calories_burned" db:"calories_burned"`
+	CaloriesEstimated bool       `json:"calories_estimated" db:"calories_estimated"`
+	Notes             string     `json:"notes,omitempty" db:"notes"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// ActivityRequest represents the request to log or update an activity. Calories
+// burned are estimated from the MET value and body weight when omitted.
+type ActivityRequest struct {
+	ActivityType    string     `json:"activity_type" binding:"required,max=50"`
+	PerformedOn     time.Time  `json:"performed_on" binding:"required"`
+	StartedAt       *time.Time `json:"started_at"`
+	DurationMinutes int        `json:"duration_minutes" binding:"required,gte=1,lte=1440"`
+	Intensity       string     `json:"intensity" binding:"omitempty,oneof=low moderate high"`
+	CaloriesBurned  *float64   `json:"calories_burned" binding:"omitempty,gte=0,lte=10000"`
+	Notes           string     `json:"notes" binding:"max=500"`
+}
+
+// DailyActivitySummary represents activity totals for a single day
+type DailyActivitySummary struct {
+	Date            string  `json:"date"`
+	ActivityCount   int     `json:"activity_count"`
+	DurationMinutes int     `json:"duration_minutes"`
+	CaloriesBurned  float64 `json:"calories_burned"`
+}
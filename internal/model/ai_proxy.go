@@ -19,7 +19,7 @@ type AIProxyRequest struct {
 
 // AIProxyMessage represents a single message in the conversation
 type AIProxyMessage struct {
-	Role    string `json:"role"` // "user" or "assistant"
+	Role    string `json:"role"` // "system", "user" or "assistant"
 	Content string `json:"content"`
 }
 
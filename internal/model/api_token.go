@@ -0,0 +1,78 @@
+package model
+
+import "time"
+
+// 个人访问令牌权限范围
+// read 允许读取所有开放给令牌的数据；<资源>:write 允许修改对应资源，同时包含该资源的读取权限
+const (
+	APITokenScopeRead             = "read"
+	APITokenScopeFoodsWrite       = "foods:write"        // 食材和食材分类
+	APITokenScopeMealsWrite       = "meals:write"        // 餐饮记录
+	APITokenScopePlansWrite       = "plans:write"        // 饮食计划
+	APITokenScopeAIWrite          = "ai:write"           // AI 对话和对话流
+	APITokenScopeFastingWrite     = "fasting:write"      // 间歇性断食
+	APITokenScopeBodyMetricsWrite = "body-metrics:write" // 身体指标
+	APITokenScopeActivitiesWrite  = "activities:write"   // 运动记录
+	APITokenScopeHydrationWrite   = "hydration:write"    // 饮水记录
+	APITokenScopeGoalsWrite       = "goals:write"        // 营养目标和能量估算
+)
+
+// APITokenScopes 所有可用的权限范围
+var APITokenScopes = []string{
+	APITokenScopeRead,
+	APITokenScopeFoodsWrite,
+	APITokenScopeMealsWrite,
+	APITokenScopePlansWrite,
+	APITokenScopeAIWrite,
+	APITokenScopeFastingWrite,
+	APITokenScopeBodyMetricsWrite,
+	APITokenScopeActivitiesWrite,
+	APITokenScopeHydrationWrite,
+	APITokenScopeGoalsWrite,
+}
+
+// IsValidAPITokenScope 检查权限范围是否有效
+func IsValidAPITokenScope(scope string) bool {
+	for _, s := range APITokenScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIToken 个人访问令牌
+type APIToken struct {
+	ID          int64      `json:"id" db:"id"`
+	UserID      int64      `json:"-" db:"user_id"`
+	Username    string     `json:"-" db:"-"` // 令牌所属用户的用户名，认证时填充
+	Name        string     `json:"name" db:"name"`
+	TokenHash   string     `json:"-" db:"token_hash"`
+	TokenPrefix string     `json:"token_prefix" db:"token_prefix"` // 令牌开头几位，用于识别
+	Scopes      []string   `json:"scopes" db:"scopes"`
+	ExpiresAt   *time.Time `json:"expires_at" db:"expires_at"` // 为空表示永不过期
+	LastUsedAt  *time.Time `json:"last_used_at" db:"last_used_at"`
+	LastUsedIP  string     `json:"last_used_ip,omitempty" db:"last_used_ip"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// IsExpired 检查令牌是否已过期
+func (t *APIToken) IsExpired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// HasScope 检查令牌是否拥有指定的权限范围
+func (t *APIToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APITokenCreated 新创建的个人访问令牌，明文令牌只在创建时返回一次
+type APITokenCreated struct {
+	*APIToken
+	Token string `json:"token"`
+}
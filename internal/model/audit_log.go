@@ -0,0 +1,120 @@
+package model
+
+import (
+	"reflect"
+	"time"
+)
+
+// 审计操作
+// 格式为 <模块>.<操作>，按前缀筛选时使用 <模块>.*
+const (
+	AuditActionLogin          = "auth.login"           // 登录（包括两步验证和单点登录）
+	AuditActionLogout         = "auth.logout"          // 登出
+	AuditActionRegister       = "auth.register"        // 注册
+	AuditActionPasswordChange = "auth.password_change" // 修改密码
+	AuditActionPasswordReset  = "auth.password_reset"  // 通过邮件重置密码
+
+	AuditActionTwoFactorEnable         = "two_factor.enable"                    // 启用两步验证
+	AuditActionTwoFactorDisable        = "two_factor.disable"                   // 停用两步验证
+	AuditActionRecoveryCodesRegenerate = "two_factor.recovery_codes_regenerate" // 重新生成恢复码
+
+	AuditActionSessionRevoke       = "session.revoke"        // 撤销一个会话
+	AuditActionSessionRevokeOthers = "session.revoke_others" // 撤销其他所有会话
+
+	AuditActionAPITokenCreate = "api_token.create" // 创建个人访问令牌
+	AuditActionAPITokenRevoke = "api_token.revoke" // 撤销个人访问令牌
+
+	AuditActionIdentityLink   = "identity.link"   // 关联外部身份
+	AuditActionIdentityUnlink = "identity.unlink" // 解除外部身份关联
+
+	AuditActionAISettingsUpdate     = "settings.ai_update"     // 修改 AI 设置
+	AuditActionSystemSettingsUpdate = "settings.system_update" // 修改系统设置
+
+	AuditActionAccountExport          = "account.export"           // 创建账户数据导出
+	AuditActionAccountExportDownload  = "account.export_download"  // 下载账户数据导出
+	AuditActionAccountImport          = "account.import"           // 导入账户数据
+	AuditActionAccountDeletionRequest = "account.deletion_request" // 申请删除账户
+	AuditActionAccountDeletionCancel  = "account.deletion_cancel"  // 重新登录取消删除
+	AuditActionAccountPurge           = "account.purge"            // 宽限期过后永久删除账户
+
+	AuditActionAdminUserCreate         = "admin.user.create"           // 管理员创建用户
+	AuditActionAdminUserRoleChange     = "admin.user.role_change"      // 管理员修改角色
+	AuditActionAdminUserPasswordReset  = "admin.user.password_reset"   // 管理员重置密码
+	AuditActionAdminUserStatusChange   = "admin.user.status_change"    // 管理员禁用或启用用户
+	AuditActionAdminUserDelete         = "admin.user.delete"           // 管理员删除用户
+	AuditActionAdminUserTwoFactorReset = "admin.user.two_factor_reset" // 管理员重置两步验证
+
+	AuditActionAuditLogExport = "audit.export" // 导出审计日志
+)
+
+// 审计资源类型
+const (
+	AuditResourceUser           = "user"
+	AuditResourceSession        = "session"
+	AuditResourceAPIToken       = "api_token"
+	AuditResourceIdentity       = "identity"
+	AuditResourceAISettings     = "ai_settings"
+	AuditResourceSystemSettings = "system_settings"
+	AuditResourceAccountExport  = "account_export"
+	AuditResourceAuditLog       = "audit_log"
+)
+
+// 审计结果
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// 审计日志导出格式
+const (
+	AuditExportFormatCSV  = "csv"
+	AuditExportFormatJSON = "json"
+)
+
+// AuditLog 审计日志，记录谁对哪个资源做了什么
+// 敏感字段（密码、密钥、令牌等）在写入前脱敏，不会出现在 changes 和 details 中
+type AuditLog struct {
+	ID            int64          `json:"id" db:"id"`
+	ActorUserID   *int64         `json:"actor_user_id" db:"actor_user_id"` // 系统任务或未认证时为 null
+	ActorUsername string         `json:"actor_username" db:"actor_username"`
+	APITokenID    *int64         `json:"api_token_id,omitempty" db:"api_token_id"`
+	Action        string         `json:"action" db:"action"`
+	ResourceType  string         `json:"resource_type" db:"resource_type"`
+	ResourceID    string         `json:"resource_id" db:"resource_id"`
+	Outcome       string         `json:"outcome" db:"outcome"`
+	IPAddress     string         `json:"ip_address" db:"ip_address"`
+	UserAgent     string         `json:"user_agent" db:"user_agent"`
+	Changes       AuditChanges   `json:"changes,omitempty" db:"changes"`
+	Details       map[string]any `json:"details,omitempty" db:"details"`
+	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
+}
+
+// AuditChange 单个字段修改前后的值
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AuditChanges 按字段名记录的修改
+type AuditChanges map[string]AuditChange
+
+// Add 记录字段的修改，前后值相同时忽略
+func (c AuditChanges) Add(field string, before, after any) {
+	if reflect.DeepEqual(before, after) {
+		return
+	}
+	c[field] = AuditChange{Before: before, After: after}
+}
+
+// AuditLogFilter 审计日志筛选条件
+type AuditLogFilter struct {
+	ActorUserID  *int64
+	Action       string // 精确匹配，以 .* 结尾时按前缀匹配
+	ResourceType string
+	ResourceID   string
+	Outcome      string
+	StartDate    *time.Time
+	EndDate      *time.Time
+	Page         int
+	PageSize     int
+}
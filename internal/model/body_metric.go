@@ -0,0 +1,142 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+// Measurement units
+const (
+	WeightUnitKg = "kg"
+	WeightUnitLb = "lb"
+	LengthUnitCm = "cm"
+	LengthUnitIn = "in"
+
+	kgPerLb = 0.45359237
+	cmPerIn = 2.54
+)
+
+// Valid weight range in kilograms, for entries and goals
+const (
+	MinWeightKg = 20
+	MaxWeightKg = 500
+)
+
+// BodyMetric represents a dated body measurement entry. Values are stored in
+// metric units and converted to the user's preferred units at the API boundary.
+type BodyMetric struct {
+	ID         int64     `json:"id" db:"id"`
+	UserID     int64     `json:"user_id" db:"user_id"`
+	MeasuredOn time.Time `json:"measured_on" db:"measured_on"`
+	WeightKg   *float64  `json:"weight_kg,omitempty" db:"weight_kg"`
+	BodyFatPct *float64  `json:"body_fat_pct,omitempty" db:"body_fat_pct"`
+	WaistCm    *float64  `json:"waist_cm,omitempty" db:"waist_cm"`
+	HipCm      *float64  `json:"hip_cm,omitempty" db:"hip_cm"`
+	ChestCm    *float64  `json:"chest_cm,omitempty" db:"chest_cm"`
+	NeckCm     *float64  `json:"neck_cm,omitempty" db:"neck_cm"`
+	Notes      string    `json:"notes,omitempty" db:"notes"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// BodyMetricRequest represents the request body for creating or updating a body
+// metric entry. Values are in the user's preferred units unless overridden by
+// weight_unit/length_unit. At least one measurement is required.
+type BodyMetricRequest struct {
+	MeasuredOn time.Time `json:"measured_on" binding:"required"`
+	Weight     *float64  `json:"weight" binding:"omitempty,gt=0"`
+	BodyFatPct *float64  `json:"body_fat_pct" binding:"omitempty,gt=0,lt=100"`
+	Waist      *float64  `json:"waist" binding:"omitempty,gt=0"`
+	Hip        *float64  `json:"hip" binding:"omitempty,gt=0"`
+	Chest      *float64  `json:"chest" binding:"omitempty,gt=0"`
+	Neck       *float64  `json:"neck" binding:"omitempty,gt=0"`
+	WeightUnit string    `json:"weight_unit" binding:"omitempty,oneof=kg lb"`
+	LengthUnit string    `json:"length_unit" binding:"omitempty,oneof=cm in"`
+	Notes      string    `json:"notes" binding:"omitempty,max=500"`
+}
+
+// BodyMetricEntry represents a body metric entry in the user's preferred units
+type BodyMetricEntry struct {
+	ID         int64     `json:"id"`
+	Date       string    `json:"date"`
+	Weight     *float64  `json:"weight,omitempty"`
+	BodyFatPct *float64  `json:"body_fat_pct,omitempty"`
+	Waist      *float64  `json:"waist,omitempty"`
+	Hip        *float64  `json:"hip,omitempty"`
+	Chest      *float64  `json:"chest,omitempty"`
+	Neck       *float64  `json:"neck,omitempty"`
+	WeightUnit string    `json:"weight_unit"`
+	LengthUnit string    `json:"length_unit"`
+	Notes      string    `json:"notes,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// WeightTrendPoint represents a weigh-in with its moving average
+type WeightTrendPoint struct {
+	Date          string  `json:"date"`
+	Weight        float64 `json:"weight"`
+	MovingAverage float64 `json:"moving_average"`
+}
+
+// WeightTrend represents the smoothed weight trend over a date range, in the
+// user's weight unit
+type WeightTrend struct {
+	StartDate         string              `json:"start_date"`
+	EndDate           string              `json:"end_date"`
+	Unit              string              `json:"unit"`
+	WindowDays        int                 `json:"window_days"`
+	Points            []*WeightTrendPoint `json:"points"`
+	WeeklyRate        *float64            `json:"weekly_rate,omitempty"` // Change per week of the moving average; negative when losing
+	GoalWeight        *float64            `json:"goal_weight,omitempty"`
+	ProjectedGoalDate *string             `json:"projected_goal_date,omitempty"` // Set only when the trend is heading towards the goal
+}
+
+// BodyMetricsSummary represents the latest body metrics for the dashboard
+type BodyMetricsSummary struct {
+	Latest            *BodyMetricEntry `json:"latest,omitempty"`
+	TrendWeight       *float64         `json:"trend_weight,omitempty"`
+	WeeklyRate        *float64         `json:"weekly_rate,omitempty"`
+	GoalWeight        *float64         `json:"goal_weight,omitempty"`
+	ProjectedGoalDate *string          `json:"projected_goal_date,omitempty"`
+	WeightUnit        string           `json:"weight_unit"`
+}
+
+// SetGoalWeightRequest represents the request body for setting the goal weight.
+// A null goal_weight clears the goal.
+type SetGoalWeightRequest struct {
+	GoalWeight *float64 `json:"goal_weight" binding:"omitempty,gt=0"`
+	Unit       string   `json:"unit" binding:"omitempty,oneof=kg lb"`
+}
+
+// WeightToKg converts a weight in unit to kilograms
+func WeightToKg(value float64, unit string) float64 {
+	if unit == WeightUnitLb {
+		return value * kgPerLb
+	}
+	return value
+}
+
+// WeightFromKg converts kilograms to unit, rounded to 0.1
+func WeightFromKg(kg float64, unit string) float64 {
+	if unit == WeightUnitLb {
+		kg = kg / kgPerLb
+	}
+	return math.Round(kg*10) / 10
+}
+
+// LengthToCm converts a length in unit to centimeters
+func LengthToCm(value float64, unit string) float64 {
+	if unit == LengthUnitIn {
+		return value * cmPerIn
+	}
+	return value
+}
+
+// LengthFromCm converts centimeters to unit, rounded to 0.1
+func LengthFromCm(cm float64, unit string) float64 {
+	if unit == LengthUnitIn {
+		cm = cm / cmPerIn
+	}
+	return math.Round(cm*10) / 10
+}
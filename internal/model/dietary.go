@@ -0,0 +1,145 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Allergens that can be excluded in user preferences and tagged on foods
+const (
+	AllergenPeanuts   = "peanuts"
+	AllergenTreeNuts  = "tree_nuts"
+	AllergenGluten    = "gluten"
+	AllergenDairy     = "dairy"
+	AllergenEggs      = "eggs"
+	AllergenSoy       = "soy"
+	AllergenFish      = "fish"
+	AllergenShellfish = "shellfish"
+	AllergenSesame    = "sesame"
+)
+
+// Diets that can be followed in user preferences and tagged on foods
+const (
+	DietVegan       = "vegan"
+	DietVegetarian  = "vegetarian"
+	DietPescatarian = "pescatarian"
+	DietHalal       = "halal"
+	DietKosher      = "kosher"
+	DietLowFODMAP   = "low_fodmap"
+)
+
+// Restriction modes decide what happens when a meal or plan contains a food that
+// violates the user's restrictions
+const (
+	RestrictionModeWarn   = "warn"   // Save and return a warning (default)
+	RestrictionModeReject = "reject" // Refuse to save
+)
+
+// Allergens lists the supported allergens
+var Allergens = []string{
+	AllergenPeanuts, AllergenTreeNuts, AllergenGluten, AllergenDairy, AllergenEggs,
+	AllergenSoy, AllergenFish, AllergenShellfish, AllergenSesame,
+}
+
+// Diets lists the supported diets
+var Diets = []string{DietVegan, DietVegetarian, DietPescatarian, DietHalal, DietKosher, DietLowFODMAP}
+
+// dietSatisfiedBy lists the food diet tags that satisfy each diet. A vegan food is
+// also vegetarian and pescatarian; a vegetarian food is also pescatarian.
+var dietSatisfiedBy = map[string][]string{
+	DietVegan:       {DietVegan},
+	DietVegetarian:  {DietVegetarian, DietVegan},
+	DietPescatarian: {DietPescatarian, DietVegetarian, DietVegan},
+	DietHalal:       {DietHalal},
+	DietKosher:      {DietKosher},
+	DietLowFODMAP:   {DietLowFODMAP},
+}
+
+// DietSatisfiedBy returns the food diet tags that satisfy a diet
+func DietSatisfiedBy(diet string) []string {
+	return dietSatisfiedBy[diet]
+}
+
+// IsValidAllergen reports whether allergen is supported
+func IsValidAllergen(allergen string) bool {
+	return containsString(Allergens, allergen)
+}
+
+// IsValidDiet reports whether diet is supported
+func IsValidDiet(diet string) bool {
+	_, ok := dietSatisfiedBy[diet]
+	return ok
+}
+
+// DietaryRestrictions represents the allergens a user avoids and the diets they follow
+type DietaryRestrictions struct {
+	Allergens []string `json:"allergens"`
+	Diets     []string `json:"diets"`
+	Mode      string   `json:"mode"`
+}
+
+// IsEmpty reports whether there are no restrictions to check
+func (r DietaryRestrictions) IsEmpty() bool {
+	return len(r.Allergens) == 0 && len(r.Diets) == 0
+}
+
+// Check returns the restrictions a food violates, or nil if it is compatible. A food
+// violates a diet unless it is tagged with the diet (or a stricter one), so untagged
+// foods are not assumed to be compatible.
+func (r DietaryRestrictions) Check(food *Food) *RestrictionViolation {
+	violation := &RestrictionViolation{FoodID: food.ID, FoodName: food.Name}
+
+	for _, allergen := range r.Allergens {
+		if containsString(food.Allergens, allergen) {
+			violation.Allergens = append(violation.Allergens, allergen)
+		}
+	}
+
+	for _, diet := range r.Diets {
+		satisfied := false
+		for _, tag := range DietSatisfiedBy(diet) {
+			if containsString(food.DietTags, tag) {
+				satisfied = true
+				break
+			}
+		}
+		if !satisfied {
+			violation.Diets = append(violation.Diets, diet)
+		}
+	}
+
+	if len(violation.Allergens) == 0 && len(violation.Diets) == 0 {
+		return nil
+	}
+	return violation
+}
+
+// RestrictionViolation describes how a food violates the user's restrictions
+type RestrictionViolation struct {
+	FoodID    int64    `json:"food_id"`
+	FoodName  string   `json:"food_name"`
+	Allergens []string `json:"allergens,omitempty"` // Avoided allergens the food contains
+	Diets     []string `json:"diets,omitempty"`     // Followed diets the food is not tagged for
+}
+
+// Message returns a human-readable description of the violation
+func (v *RestrictionViolation) Message() string {
+	parts := make([]string, 0, 2)
+	if len(v.Allergens) > 0 {
+		parts = append(parts, "contains "+strings.Join(v.Allergens, ", "))
+	}
+	if len(v.Diets) > 0 {
+		parts = append(parts, "is not marked "+strings.Join(v.Diets, ", "))
+	}
+	return fmt.Sprintf("%s %s", v.FoodName, strings.Join(parts, " and "))
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
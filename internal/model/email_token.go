@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// 邮件令牌用途
+const (
+	EmailTokenPurposeVerifyEmail   = "verify_email"   // 邮箱验证
+	EmailTokenPurposePasswordReset = "password_reset" // 密码重置
+)
+
+// EmailToken 通过邮件发送的一次性令牌
+type EmailToken struct {
+	ID        int64      `json:"id" db:"id"`
+	UserID    int64      `json:"user_id" db:"user_id"`
+	Purpose   string     `json:"purpose" db:"purpose"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	Email     string     `json:"email" db:"email"` // 令牌发送到的邮箱
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty" db:"used_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// IsUsable 检查令牌是否未使用且未过期
+func (t *EmailToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEmailTokenIsUsable(t *testing.T) {
+	now := time.Date(2024, 11, 15, 12, 0, 0, 0, time.UTC)
+	used := now.Add(-time.Minute)
+
+	tests := []struct {
+		name  string
+		token EmailToken
+		want  bool
+	}{
+		{"unused and not expired", EmailToken{ExpiresAt: now.Add(time.Hour)}, true},
+		{"already used", EmailToken{ExpiresAt: now.Add(time.Hour), UsedAt: &used}, false},
+		{"expired", EmailToken{ExpiresAt: now.Add(-time.Second)}, false},
+		{"expires exactly now", EmailToken{ExpiresAt: now}, false},
+		{"used and expired", EmailToken{ExpiresAt: now.Add(-time.Hour), UsedAt: &used}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.token.IsUsable(now); got != tt.want {
+				t.Errorf("IsUsable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package model
+
+// Sex values used by BMR formulas
+const (
+	SexMale   = "male"
+	SexFemale = "female"
+)
+
+// Weight goals
+const (
+	WeightGoalLose     = "lose"
+	WeightGoalMaintain = "maintain"
+	WeightGoalGain     = "gain"
+)
+
+// BMR formulas
+const (
+	FormulaMifflinStJeor  = "mifflin_st_jeor"
+	FormulaHarrisBenedict = "harris_benedict"
+	FormulaKatchMcArdle   = "katch_mcardle"
+)
+
+// ActivityFactors maps activity levels to TDEE multipliers
+var ActivityFactors = map[string]float64{
+	"sedentary":   1.2,
+	"light":       1.375,
+	"moderate":    1.55,
+	"active":      1.725,
+	"very_active": 1.9,
+}
+
+// EnergyInputs represents the profile values used for an energy estimate
+type EnergyInputs struct {
+	Sex            string   `json:"sex"`
+	Age            int      `json:"age"`
+	HeightCm       float64  `json:"height_cm"`
+	WeightKg       float64  `json:"weight_kg"`
+	BodyFatPct     *float64 `json:"body_fat_pct,omitempty"`
+	ActivityLevel  string   `json:"activity_level"`
+	ActivityFactor float64  `json:"activity_factor"`
+	WeightGoal     string   `json:"weight_goal"`
+}
+
+// AdaptiveTDEE represents a TDEE estimated from logged intake and weight change
+type AdaptiveTDEE struct {
+	StartDate      string  `json:"start_date"`
+	EndDate        string  `json:"end_date"`
+	LoggedDays     int     `json:"logged_days"`
+	AvgIntake      float64 `json:"avg_intake"`
+	WeightChangeKg float64 `json:"weight_change_kg"`
+	TDEE           float64 `json:"tdee"`
+}
+
+// EnergyEstimate represents BMR/TDEE estimates and suggested goals
+type EnergyEstimate struct {
+	Inputs      EnergyInputs       `json:"inputs"`
+	BMR         map[string]float64 `json:"bmr"`     // BMR per applicable formula
+	Formula     string             `json:"formula"` // Formula used for TDEE
+	FormulaTDEE float64            `json:"formula_tdee"`
+	Adaptive    *AdaptiveTDEE      `json:"adaptive,omitempty"`
+	TDEE        float64            `json:"tdee"`
+	TDEESource  string             `json:"tdee_source"` // "formula" or "adaptive"
+	Suggested   NutritionGoals     `json:"suggested"`
+}
@@ -0,0 +1,114 @@
+package model
+
+import "time"
+
+// Fast status values
+const (
+	FastStatusActive    = "active"
+	FastStatusCompleted = "completed"
+	FastStatusBroken    = "broken"
+)
+
+// FastingProtocol represents a fasting protocol with its target duration
+type FastingProtocol struct {
+	Name        string  `json:"name"`
+	TargetHours float64 `json:"target_hours"`
+	Description string  `json:"description"`
+}
+
+// FastingProtocols lists the built-in fasting protocols. "custom" fasts carry
+// their own target duration.
+var FastingProtocols = []FastingProtocol{
+	{Name: "12:12", TargetHours: 12, Description: "12 hours fasting, 12 hours eating"},
+	{Name: "14:10", TargetHours: 14, Description: "14 hours fasting, 10 hours eating"},
+	{Name: "16:8", TargetHours: 16, Description: "16 hours fasting, 8 hours eating"},
+	{Name: "18:6", TargetHours: 18, Description: "18 hours fasting, 6 hours eating"},
+	{Name: "20:4", TargetHours: 20, Description: "20 hours fasting, 4 hours eating"},
+	{Name: "omad", TargetHours: 23, Description: "One meal a day"},
+	{Name: "36h", TargetHours: 36, Description: "36 hour extended fast"},
+}
+
+// FindFastingProtocol returns the built-in protocol with the given name
+func FindFastingProtocol(name string) (FastingProtocol, bool) {
+	for _, p := range FastingProtocols {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return FastingProtocol{}, false
+}
+
+// Fast represents a fasting period
+type Fast struct {
+	ID             int64      `json:"id" db:"id"`
+	UserID         int64      `json:"user_id" db:"user_id"`
+	Protocol       string     `json:"protocol" db:"protocol"`
+	TargetHours    float64    `json:"target_hours" db:"target_hours"`
+	StartedAt      time.Time  `json:"started_at" db:"started_at"`
+	EndedAt        *time.Time `json:"ended_at,omitempty" db:"ended_at"`
+	Status         string     `json:"status" db:"status"`
+	BrokenByMealID *int64     `json:"broken_by_meal_id,omitempty" db:"broken_by_meal_id"`
+	Notes          string     `json:"notes,omitempty" db:"notes"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Duration returns how long the fast lasted, or has lasted so far at now
+func (f *Fast) Duration(now time.Time) time.Duration {
+	end := now
+	if f.EndedAt != nil {
+		end = *f.EndedAt
+	}
+	if end.Before(f.StartedAt) {
+		return 0
+	}
+	return end.Sub(f.StartedAt)
+}
+
+// GoalReached reports whether the fast reached its target duration by now
+func (f *Fast) GoalReached(now time.Time) bool {
+	return f.Duration(now).Hours() >= f.TargetHours
+}
+
+// StartFastRequest represents the request body for starting a fast
+type StartFastRequest struct {
+	Protocol    string     `json:"protocol" binding:"required,max=20"`
+	TargetHours float64    `json:"target_hours" binding:"omitempty,gt=0,lte=168"`
+	StartedAt   *time.Time `json:"started_at"`
+	Notes       string     `json:"notes" binding:"omitempty,max=500"`
+}
+
+// FastStatus represents the progress of the active fast
+type FastStatus struct {
+	Fast             *Fast     `json:"fast"`
+	ElapsedMinutes   int       `json:"elapsed_minutes"`
+	RemainingMinutes int       `json:"remaining_minutes"`
+	Progress         float64   `json:"progress"` // Percentage of the target duration, may exceed 100
+	TargetEndAt      time.Time `json:"target_end_at"`
+	GoalReached      bool      `json:"goal_reached"`
+}
+
+// FastingWindow represents a fasting period inferred from logged meal times
+type FastingWindow struct {
+	StartedAt     time.Time `json:"started_at"` // eaten_at of the last meal before the fast
+	EndedAt       time.Time `json:"ended_at"`   // eaten_at of the meal that ended the fast
+	DurationHours float64   `json:"duration_hours"`
+}
+
+// FastingStats represents fasting streaks and totals
+type FastingStats struct {
+	TotalFasts          int        `json:"total_fasts"`
+	CompletedFasts      int        `json:"completed_fasts"` // Fasts that reached their target
+	CompletionRate      float64    `json:"completion_rate"`
+	AvgDurationHours    float64    `json:"avg_duration_hours"`
+	LongestFastHours    float64    `json:"longest_fast_hours"`
+	CurrentStreak       int        `json:"current_streak"` // Consecutive days ending today or yesterday with a completed fast
+	LongestStreak       int        `json:"longest_streak"`
+	LastCompletedFastAt *time.Time `json:"last_completed_fast_at,omitempty"`
+}
+
+// FastingSummary represents the fasting section of the dashboard
+type FastingSummary struct {
+	Current *FastStatus   `json:"current,omitempty"`
+	Stats   *FastingStats `json:"stats"`
+}
@@ -7,7 +7,9 @@ type Food struct {
 	ID        int64     `json:"id" db:"id"`
 	UserID    int64     `json:"user_id" db:"user_id"`
 	Name      string    `json:"name" db:"name" binding:"required,max=100"`
-	Category  string    `json:"category" db:"category" binding:"required,oneof=meat vegetable fruit grain other"`
+	Aliases   []string  `json:"aliases" db:"aliases"`                              // Alternative names matched by search
+	Category  string    `json:"category" db:"category" binding:"omitempty,max=50"` // Category slug, see FoodCategory
+	Tags      []string  `json:"tags" db:"tags"`                                    // Free-form tags
 	Price     float64   `json:"price" db:"price" binding:"gte=0"`
 	Unit      string    `json:"unit" db:"unit" binding:"required,max=20"`
 	Protein   float64   `json:"protein" db:"protein" binding:"gte=0"`
@@ -15,17 +17,33 @@ type Food struct {
 	Fat       float64   `json:"fat" db:"fat" binding:"gte=0"`
 	Fiber     float64   `json:"fiber" db:"fiber" binding:"gte=0"`
 	Calories  float64   `json:"calories" db:"calories" binding:"gte=0"`
+	WaterPct  float64   `json:"water_pct" db:"water_pct" binding:"gte=0,lte=100"` // Share of the amount counted as water intake; 0 if not a beverage
+	Allergens []string  `json:"allergens" db:"allergens"`                         // Allergens the food contains, see Allergens
+	DietTags  []string  `json:"diet_tags" db:"diet_tags"`                         // Diets the food is suitable for, see Diets
 	Available bool      `json:"available" db:"available"`
+	Favorite  bool      `json:"favorite" db:"favorite"` // Pinned by the user; set through the favorite endpoints only
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
 // FoodFilter represents filter criteria for listing foods
 type FoodFilter struct {
-	Category  string
-	Available *bool
-	Page      int
-	PageSize  int
+	Category     string
+	Categories   []string // Category slugs to match instead of Category (the category's subtree), set by the service
+	Tags         []string // Tags the foods must have
+	AnyTag       bool     // Match foods with any of Tags instead of all of them
+	Available    *bool
+	Favorite     *bool
+	Restrictions *DietaryRestrictions // Filter by compatibility with these restrictions when set
+	Compatible   bool                 // Whether to return compatible (true) or incompatible (false) foods
+	Page         int
+	PageSize     int
+}
+
+// FoodTag represents a tag used on the user's foods
+type FoodTag struct {
+	Tag       string `json:"tag"`
+	FoodCount int    `json:"food_count"`
 }
 
 // BatchResult represents the result of a batch import operation
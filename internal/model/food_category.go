@@ -0,0 +1,197 @@
+package model
+
+import (
+	"regexp"
+	"sort"
+	"time"
+)
+
+// DefaultFoodCategory is the category used for foods without a category and for
+// foods whose category has been deleted without a parent to move to
+const DefaultFoodCategory = "other"
+
+// Category breakdown levels
+const (
+	CategoryLevelTop  = "top"  // Roll subcategories up into their top-level category
+	CategoryLevelLeaf = "leaf" // Report each category separately
+)
+
+// foodCategorySlugPattern matches category slugs: lowercase letters, digits and
+// underscores, starting with a letter
+var foodCategorySlugPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// IsValidFoodCategorySlug reports whether slug is a well-formed category slug
+func IsValidFoodCategorySlug(slug string) bool {
+	return foodCategorySlugPattern.MatchString(slug)
+}
+
+// FoodCategory represents a node in the food category tree. Built-in categories have
+// no owner and are shared by all users; users can add their own categories anywhere
+// in the tree. Foods refer to categories by slug, which is unique per user.
+type FoodCategory struct {
+	ID        int64           `json:"id" db:"id"`
+	UserID    *int64          `json:"user_id,omitempty" db:"user_id"`
+	ParentID  *int64          `json:"parent_id,omitempty" db:"parent_id"`
+	Slug      string          `json:"slug" db:"slug"`
+	Name      string          `json:"name" db:"name"`
+	SortOrder int             `json:"sort_order" db:"sort_order"`
+	BuiltIn   bool            `json:"built_in"`
+	Children  []*FoodCategory `json:"children,omitempty"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// CreateFoodCategoryRequest represents a request to create a custom food category
+type CreateFoodCategoryRequest struct {
+	ParentID  *int64 `json:"parent_id" binding:"omitempty,gt=0"`
+	Slug      string `json:"slug" binding:"required,min=1,max=50"`
+	Name      string `json:"name" binding:"required,min=1,max=50"`
+	SortOrder int    `json:"sort_order" binding:"gte=0,lte=10000"`
+}
+
+// UpdateFoodCategoryRequest represents a request to rename or move a custom food
+// category. The slug cannot be changed because foods refer to it.
+type UpdateFoodCategoryRequest struct {
+	ParentID  *int64 `json:"parent_id" binding:"omitempty,gt=0"`
+	Name      string `json:"name" binding:"required,min=1,max=50"`
+	SortOrder int    `json:"sort_order" binding:"gte=0,lte=10000"`
+}
+
+// FoodCategories is the set of categories visible to a user: the built-in
+// categories plus the user's own
+type FoodCategories []*FoodCategory
+
+// ByID returns the categories keyed by ID
+func (cs FoodCategories) ByID() map[int64]*FoodCategory {
+	byID := make(map[int64]*FoodCategory, len(cs))
+	for _, c := range cs {
+		byID[c.ID] = c
+	}
+	return byID
+}
+
+// FindBySlug returns the category with the given slug, or nil if there is none
+func (cs FoodCategories) FindBySlug(slug string) *FoodCategory {
+	for _, c := range cs {
+		if c.Slug == slug {
+			return c
+		}
+	}
+	return nil
+}
+
+// Subtree returns the slugs of the category with the given ID and all of its
+// descendants
+func (cs FoodCategories) Subtree(id int64) []string {
+	children := make(map[int64][]*FoodCategory)
+	var root *FoodCategory
+	for _, c := range cs {
+		if c.ParentID != nil {
+			children[*c.ParentID] = append(children[*c.ParentID], c)
+		}
+		if c.ID == id {
+			root = c
+		}
+	}
+	if root == nil {
+		return nil
+	}
+
+	slugs := []string{}
+	queue := []*FoodCategory{root}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		slugs = append(slugs, c.Slug)
+		queue = append(queue, children[c.ID]...)
+	}
+	return slugs
+}
+
+// IsDescendant reports whether the category with ID id is ancestorID itself or one
+// of its descendants
+func (cs FoodCategories) IsDescendant(id, ancestorID int64) bool {
+	byID := cs.ByID()
+	for c := byID[id]; c != nil; {
+		if c.ID == ancestorID {
+			return true
+		}
+		if c.ParentID == nil {
+			return false
+		}
+		c = byID[*c.ParentID]
+	}
+	return false
+}
+
+// Root returns the top-level ancestor of a category (the category itself if it is
+// top-level)
+func (cs FoodCategories) Root(c *FoodCategory) *FoodCategory {
+	byID := cs.ByID()
+	for c.ParentID != nil {
+		parent, ok := byID[*c.ParentID]
+		if !ok {
+			break
+		}
+		c = parent
+	}
+	return c
+}
+
+// Tree returns copies of the categories arranged as a tree of top-level categories,
+// with siblings ordered by sort order and then ID
+func (cs FoodCategories) Tree() []*FoodCategory {
+	nodes := make(map[int64]*FoodCategory, len(cs))
+	for _, c := range cs {
+		node := *c
+		node.Children = nil
+		nodes[c.ID] = &node
+	}
+
+	roots := []*FoodCategory{}
+	for _, c := range cs {
+		node := nodes[c.ID]
+		if c.ParentID != nil {
+			if parent, ok := nodes[*c.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	sortFoodCategories(roots)
+	return roots
+}
+
+// sortFoodCategories sorts siblings by sort order and ID, recursively
+func sortFoodCategories(categories []*FoodCategory) {
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].SortOrder != categories[j].SortOrder {
+			return categories[i].SortOrder < categories[j].SortOrder
+		}
+		return categories[i].ID < categories[j].ID
+	})
+	for _, c := range categories {
+		sortFoodCategories(c.Children)
+	}
+}
+
+// CategoryIntake represents the intake from foods of one category over a date range
+type CategoryIntake struct {
+	Category    string        `json:"category"`
+	Name        string        `json:"name"`
+	Amount      float64       `json:"amount"`       // Total amount eaten (grams)
+	Nutrition   NutritionData `json:"nutrition"`    // Nutrition from the category's foods
+	CaloriesPct float64       `json:"calories_pct"` // Share of total calories (%)
+	FoodCount   int           `json:"food_count"`   // Number of distinct foods eaten
+}
+
+// CategoryIntakeReport breaks down intake over a date range by food category
+type CategoryIntakeReport struct {
+	StartDate  string            `json:"start_date"`
+	EndDate    string            `json:"end_date"`
+	Level      string            `json:"level"`
+	Total      NutritionData     `json:"total"`
+	Categories []*CategoryIntake `json:"categories"` // Ordered by calories, highest first
+}
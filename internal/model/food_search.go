@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// Food search match types, from strongest to weakest
+const (
+	FoodMatchExact     = "exact"     // The whole name or alias equals the query
+	FoodMatchPrefix    = "prefix"    // The name, or each of its words, starts with the query
+	FoodMatchSubstring = "substring" // The name contains the query
+	FoodMatchPinyin    = "pinyin"    // The pinyin of a Chinese name matches the query
+	FoodMatchFuzzy     = "fuzzy"     // The name matches the query with typos
+)
+
+// FoodUsage summarizes how a user has used a food in meals
+type FoodUsage struct {
+	FoodID     int64          `json:"food_id"`
+	UseCount   int            `json:"use_count"`   // Number of meal entries with the food
+	LastUsed   time.Time      `json:"last_used"`   // Date of the most recent meal with the food
+	LastAmount float64        `json:"last_amount"` // Amount eaten in the most recent meal
+	LastUnit   string         `json:"last_unit"`
+	MealTypes  map[string]int `json:"meal_types"` // Number of meal entries by meal type
+}
+
+// FoodSearchResult represents a food matching a search query
+type FoodSearchResult struct {
+	Food      *Food      `json:"food"`
+	Score     float64    `json:"score"`               // Match strength boosted by usage, higher first
+	MatchType string     `json:"match_type"`          // See FoodMatchExact and friends
+	MatchedOn string     `json:"matched_on"`          // The name or alias that matched
+	UseCount  int        `json:"use_count"`           // Meal entries with the food in the usage window
+	LastUsed  *time.Time `json:"last_used,omitempty"` // Date the food was last eaten
+}
+
+// FoodCompletion represents an autocomplete suggestion for meal entry, carrying
+// what is needed to add the food to a meal
+type FoodCompletion struct {
+	ID        int64   `json:"id"`
+	Name      string  `json:"name"`
+	MatchedOn string  `json:"matched_on"` // The name or alias that matched
+	MatchType string  `json:"match_type"`
+	Category  string  `json:"category"`
+	Calories  float64 `json:"calories"`
+	Amount    float64 `json:"amount"` // Suggested amount: the amount last eaten, or 100
+	Unit      string  `json:"unit"`   // Unit of Amount: the unit last used, or the food's unit
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// FoodSuggestion is a food suggested for a meal: a favorite, or a food the user
+// commonly eats at that meal
+type FoodSuggestion struct {
+	Food         *Food      `json:"food"`
+	UseCount     int        `json:"use_count"`     // Times eaten at this meal type in the history window
+	WeekdayCount int        `json:"weekday_count"` // Times eaten at this meal type on the same weekday
+	LastEaten    *time.Time `json:"last_eaten,omitempty"`
+	Amount       float64    `json:"amount"` // Amount last eaten at this meal type, or 100
+	Unit         string     `json:"unit"`   // Unit of Amount
+}
+
+// MealCombination is a set of foods the user has eaten together at a meal type
+// more than once
+type MealCombination struct {
+	Foods        []MealFood    `json:"foods"`     // Foods with the amounts eaten the last time
+	Nutrition    NutritionData `json:"nutrition"` // Nutrition of the last time
+	Count        int           `json:"count"`     // Times eaten in the history window
+	WeekdayCount int           `json:"weekday_count"`
+	LastEaten    time.Time     `json:"last_eaten"`
+}
+
+// MealSuggestions are the foods and whole meals suggested when logging a meal
+type MealSuggestions struct {
+	Date     string             `json:"date"`
+	Weekday  string             `json:"weekday"`
+	MealType string             `json:"meal_type"`
+	Foods    []*FoodSuggestion  `json:"foods"` // Favorites first, then by how commonly eaten
+	Meals    []*MealCombination `json:"meals"` // Most common combinations first
+}
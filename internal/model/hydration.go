@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// DefaultDailyWaterGoalML is the daily water goal used when the user has not set one
+const DefaultDailyWaterGoalML = 2000
+
+// Beverage types for water log entries. Beverages that are also foods (milk, juice)
+// are logged as meal foods instead and count toward hydration through the food's
+// water percentage.
+const (
+	BeverageWater          = "water"
+	BeverageSparklingWater = "sparkling_water"
+	BeverageTea            = "tea"
+	BeverageCoffee         = "coffee"
+	BeverageSportsDrink    = "sports_drink"
+	BeverageOther          = "other"
+)
+
+// WaterLog represents a logged drink
+type WaterLog struct {
+	ID           int64      `json:"id" db:"id"`
+	UserID       int64      `json:"user_id" db:"user_id"`
+	LogDate      time.Time  `json:"log_date" db:"log_date"`
+	LoggedAt     *time.Time `json:"logged_at,omitempty" db:"logged_at"`
+	AmountML     int        `json:"amount_ml" db:"amount_ml"`
+	BeverageType string     `json:"beverage_type" db:"beverage_type"`
+	Notes        string     `json:"notes,omitempty" db:"notes"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// WaterLogRequest represents the request to log or update a drink
+type WaterLogRequest struct {
+	LogDate      time.Time  `json:"log_date" binding:"required"`
+	LoggedAt     *time.Time `json:"logged_at"`
+	AmountML     int        `json:"amount_ml" binding:"required,gte=1,lte=5000"`
+	BeverageType string     `json:"beverage_type" binding:"omitempty,oneof=water sparkling_water tea coffee sports_drink other"`
+	Notes        string     `json:"notes" binding:"max=500"`
+}
+
+// DailyHydration represents water intake for a single day
+type DailyHydration struct {
+	Date        string  `json:"date"`
+	LoggedML    float64 `json:"logged_ml"`     // From water log entries
+	FromFoodsML float64 `json:"from_foods_ml"` // From beverage foods in meals
+	TotalML     float64 `json:"total_ml"`
+	GoalML      int     `json:"goal_ml"`
+	GoalPct     float64 `json:"goal_pct"`
+	LogCount    int     `json:"log_count"`
+}
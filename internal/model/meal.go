@@ -11,8 +11,32 @@ type Meal struct {
 	Foods     []MealFood    `json:"foods" binding:"required,gte=1,dive"`
 	Nutrition NutritionData `json:"nutrition"`
 	Notes     string        `json:"notes,omitempty" db:"notes" binding:"omitempty,max=500"`
-	CreatedAt time.Time     `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time     `json:"updated_at" db:"updated_at"`
+	EatenAt   *time.Time    `json:"eaten_at,omitempty" db:"eaten_at"`
+	// EatenAtEstimated is set when eaten_at was not given and defaults to the preferred meal time
+	EatenAtEstimated bool      `json:"eaten_at_estimated" db:"eaten_at_estimated"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+
+	// Warnings are produced when the meal is saved and are not stored
+	Warnings []*MealWarning `json:"warnings,omitempty"`
+}
+
+// HasRecordedTime reports whether the meal has an eaten_at given by the user rather than
+// an estimate, so it can be used for timing analytics
+func (m *Meal) HasRecordedTime() bool {
+	return m.EatenAt != nil && !m.EatenAtEstimated
+}
+
+// Meal warning types
+const (
+	MealWarningFastBroken          = "fast_broken"
+	MealWarningRestrictionViolated = "restriction_violated"
+)
+
+// MealWarning represents a non-fatal warning raised when a meal is logged
+type MealWarning struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
 }
 
 // MealFood represents a food item in a meal
@@ -43,9 +67,13 @@ type MealFilter struct {
 
 // DailyNutritionStats represents daily nutrition statistics
 type DailyNutritionStats struct {
-	Date      time.Time     `json:"date"`
-	Nutrition NutritionData `json:"nutrition"`
-	MealCount int           `json:"meal_count"`
+	Date           time.Time      `json:"date"`
+	Nutrition      NutritionData  `json:"nutrition"`
+	MealCount      int            `json:"meal_count"`
+	CaloriesBurned float64        `json:"calories_burned"` // Calories burned by logged activities
+	NetCalories    float64        `json:"net_calories"`    // Calories eaten minus calories burned
+	WaterML        float64        `json:"water_ml"`        // Water intake, including beverage foods
+	Goal           *ResolvedGoals `json:"goal,omitempty"`  // Goals in effect on the day, when requested
 }
 
 // MonthlyStats represents monthly meal statistics
@@ -56,14 +84,65 @@ type MonthlyStats struct {
 	DailyStats []*DailyNutritionStats `json:"daily_stats"`
 	AvgDaily   NutritionData          `json:"avg_daily"`
 	Total      NutritionData          `json:"total"`
+	// TotalCaloriesBurned and AvgDailyNetCalories account for logged activities
+	TotalCaloriesBurned float64 `json:"total_calories_burned"`
+	TotalNetCalories    float64 `json:"total_net_calories"`
+	AvgDailyNetCalories float64 `json:"avg_daily_net_calories"`
+	// TotalWaterML and AvgDailyWaterML include water log entries and beverage foods
+	TotalWaterML    float64 `json:"total_water_ml"`
+	AvgDailyWaterML float64 `json:"avg_daily_water_ml"`
 }
 
 // NutritionComparison represents comparison between actual and target nutrition
 type NutritionComparison struct {
-	Target     NutritionData      `json:"target"`
-	Actual     NutritionData      `json:"actual"`
-	Difference NutritionData      `json:"difference"`
-	Percentage map[string]float64 `json:"percentage"`
+	Target      NutritionData      `json:"target"`
+	Actual      NutritionData      `json:"actual"`
+	Difference  NutritionData      `json:"difference"`
+	Percentage  map[string]float64 `json:"percentage"`
+	ProfileDays map[string]int     `json:"profile_days,omitempty"` // Days per goal profile in a date range comparison
+}
+
+// LateNightStartHour and LateNightEndHour bound the late-night eating window
+// (local time, [21:00, 04:00)).
+const (
+	LateNightStartHour = 21
+	LateNightEndHour   = 4
+)
+
+// DailyMealTiming represents meal timing for a single day
+type DailyMealTiming struct {
+	Date                string    `json:"date"`
+	MealCount           int       `json:"meal_count"`
+	FirstMealAt         time.Time `json:"first_meal_at"`
+	LastMealAt          time.Time `json:"last_meal_at"`
+	EatingWindowMinutes int       `json:"eating_window_minutes"`
+	LateNightMeals      int       `json:"late_night_meals"`
+}
+
+// MealTimingStats represents meal timing analytics over a date range.
+// Clock times are "HH:MM" in the user's timezone.
+type MealTimingStats struct {
+	StartDate              string             `json:"start_date"`
+	EndDate                string             `json:"end_date"`
+	TrackedMeals           int                `json:"tracked_meals"`
+	UntrackedMeals         int                `json:"untracked_meals"`
+	DaysTracked            int                `json:"days_tracked"`
+	AvgFirstMealTime       string             `json:"avg_first_meal_time,omitempty"`
+	AvgLastMealTime        string             `json:"avg_last_meal_time,omitempty"`
+	AvgEatingWindowMinutes float64            `json:"avg_eating_window_minutes"`
+	LateNightMeals         int                `json:"late_night_meals"`
+	LateNightFrequency     float64            `json:"late_night_frequency"`
+	MealTypeAvgTimes       map[string]string  `json:"meal_type_avg_times"`
+	Days                   []*DailyMealTiming `json:"days"`
+}
+
+// MealReminder represents a meal reminder for a single meal type on a given day
+type MealReminder struct {
+	MealType      string    `json:"meal_type"`
+	PreferredTime string    `json:"preferred_time"`
+	RemindAt      time.Time `json:"remind_at"`
+	Logged        bool      `json:"logged"`
+	Due           bool      `json:"due"`
 }
 
 // DashboardData represents aggregated data for the dashboard view
@@ -73,5 +152,8 @@ type DashboardData struct {
 	TodayStats   *DailyNutritionStats `json:"today_stats"`
 	CurrentMonth int                  `json:"current_month"`
 	CurrentYear  int                  `json:"current_year"`
+	Fasting      *FastingSummary      `json:"fasting"`
+	BodyMetrics  *BodyMetricsSummary  `json:"body_metrics"`
+	Hydration    *DailyHydration      `json:"hydration"`
 	GeneratedAt  time.Time            `json:"generated_at"`
 }
@@ -6,6 +6,7 @@ import "time"
 const (
 	MessageRoleUser      = "user"
 	MessageRoleAssistant = "assistant"
+	MessageRoleSystem    = "system" // 仅用于发送给 AI 的上下文，不保存
 )
 
 // Message 消息模型
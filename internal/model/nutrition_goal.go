@@ -0,0 +1,235 @@
+package model
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Goal period statuses, relative to the user's today
+const (
+	GoalStatusPast      = "past"
+	GoalStatusCurrent   = "current"
+	GoalStatusScheduled = "scheduled"
+)
+
+// BaseGoalProfile is the profile name of the everyday goals, used on days without
+// a scheduled or overridden profile
+const BaseGoalProfile = ""
+
+// goalProfileNamePattern restricts goal profile names to short lowercase slugs
+var goalProfileNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,29}$`)
+
+// IsValidGoalProfileName reports whether name can be used as a goal profile name
+func IsValidGoalProfileName(name string) bool {
+	return goalProfileNamePattern.MatchString(name)
+}
+
+// Weekdays lists the weekday keys used by goal profile schedules, Monday first
+var Weekdays = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+// WeekdayKey returns the goal profile schedule key of the day's weekday
+func WeekdayKey(day time.Time) string {
+	return strings.ToLower(day.Weekday().String())
+}
+
+// NutritionGoals represents a set of daily nutrition goals
+type NutritionGoals struct {
+	Calories int `json:"calories"`
+	Protein  int `json:"protein"`
+	Carbs    int `json:"carbs"`
+	Fat      int `json:"fat"`
+	Fiber    int `json:"fiber"`
+}
+
+// DefaultNutritionGoals returns the goals used when a user has never set any
+func DefaultNutritionGoals() NutritionGoals {
+	return NutritionGoals{
+		Calories: 2000,
+		Protein:  150,
+		Carbs:    250,
+		Fat:      70,
+		Fiber:    30,
+	}
+}
+
+// ToNutritionData converts goals to nutrition data for comparisons
+func (g NutritionGoals) ToNutritionData() NutritionData {
+	return NutritionData{
+		Calories: float64(g.Calories),
+		Protein:  float64(g.Protein),
+		Carbs:    float64(g.Carbs),
+		Fat:      float64(g.Fat),
+		Fiber:    float64(g.Fiber),
+	}
+}
+
+// ResolvedGoals represents the goals that apply to a specific date and the profile they came from
+type ResolvedGoals struct {
+	Profile string `json:"profile,omitempty"` // Empty for the everyday goals
+	NutritionGoals
+	ExerciseBonus int `json:"exercise_bonus,omitempty"` // Calories added for exercise, already included in Calories
+}
+
+// WithExerciseFlex returns the goals with the calorie goal raised by pct percent of
+// the calories burned by exercise
+func (g ResolvedGoals) WithExerciseFlex(caloriesBurned float64, pct int) ResolvedGoals {
+	if pct <= 0 || caloriesBurned <= 0 {
+		return g
+	}
+
+	bonus := int(math.Round(caloriesBurned * float64(pct) / 100))
+	g.Calories += bonus
+	g.ExerciseBonus += bonus
+	return g
+}
+
+// GoalPeriod represents a set of goals for a profile in effect from a date until the
+// next period of the same profile starts
+type GoalPeriod struct {
+	ID            int64     `json:"id" db:"id"`
+	UserID        int64     `json:"user_id" db:"user_id"`
+	Profile       string    `json:"profile,omitempty" db:"profile"` // Empty for the everyday goals
+	EffectiveFrom time.Time `json:"effective_from" db:"effective_from"`
+	NutritionGoals
+	Note      string    `json:"note,omitempty" db:"note"`
+	Status    string    `json:"status,omitempty" db:"-"` // past, current or scheduled
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ScheduleGoalRequest represents the request to add a goal period starting today or later
+type ScheduleGoalRequest struct {
+	Profile       string    `json:"profile" binding:"omitempty,max=30"`
+	EffectiveFrom time.Time `json:"effective_from" binding:"required"`
+	Calories      int       `json:"calories" binding:"required,gte=800,lte=10000"`
+	Protein       int       `json:"protein" binding:"gte=0,lte=500"`
+	Carbs         int       `json:"carbs" binding:"gte=0,lte=1000"`
+	Fat           int       `json:"fat" binding:"gte=0,lte=500"`
+	Fiber         int       `json:"fiber" binding:"gte=0,lte=200"`
+	Note          string    `json:"note" binding:"max=200"`
+}
+
+// GoalProfile summarizes a goal profile and its goals in effect today
+type GoalProfile struct {
+	Name     string         `json:"name"`
+	Goals    NutritionGoals `json:"goals"`
+	Weekdays []string       `json:"weekdays"` // Weekdays assigned by the current schedule
+}
+
+// GoalProfileSchedule assigns goal profiles to weekdays from a date on. Weekdays
+// without a profile use the everyday goals.
+type GoalProfileSchedule struct {
+	ID              int64             `json:"id" db:"id"`
+	UserID          int64             `json:"user_id" db:"user_id"`
+	EffectiveFrom   time.Time         `json:"effective_from" db:"effective_from"`
+	WeekdayProfiles map[string]string `json:"weekday_profiles" db:"weekday_profiles"` // Weekday => profile name
+	Status          string            `json:"status,omitempty" db:"-"`                // past, current or scheduled
+	CreatedAt       time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// SetGoalProfileScheduleRequest represents the request to change the weekday schedule
+type SetGoalProfileScheduleRequest struct {
+	EffectiveFrom   *time.Time        `json:"effective_from"` // Defaults to today
+	WeekdayProfiles map[string]string `json:"weekday_profiles"`
+}
+
+// GoalProfileOverride assigns a goal profile to a single date, taking precedence over the schedule
+type GoalProfileOverride struct {
+	ID        int64     `json:"id" db:"id"`
+	UserID    int64     `json:"user_id" db:"user_id"`
+	Date      time.Time `json:"date" db:"override_date"`
+	Profile   string    `json:"profile" db:"profile"` // Empty forces the everyday goals
+	Note      string    `json:"note,omitempty" db:"note"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SetGoalProfileOverrideRequest represents the request to assign a profile to a date
+type SetGoalProfileOverrideRequest struct {
+	Date    time.Time `json:"date" binding:"required"`
+	Profile string    `json:"profile" binding:"omitempty,max=30"`
+	Note    string    `json:"note" binding:"max=200"`
+}
+
+// GoalTimeline is a user's goal periods of all profiles sorted by effective date
+type GoalTimeline []*GoalPeriod
+
+// On returns the everyday goals in effect on the calendar day of day
+func (t GoalTimeline) On(day time.Time) NutritionGoals {
+	goals, _ := t.ProfileOn(day, BaseGoalProfile)
+	return goals
+}
+
+// ProfileOn returns the goals of a profile in effect on the calendar day of day. Days
+// before the profile's first period use its first period's goals. ok is false when the
+// profile has no periods; the everyday goals fall back to the defaults in that case.
+func (t GoalTimeline) ProfileOn(day time.Time, profile string) (goals NutritionGoals, ok bool) {
+	date := day.Format("2006-01-02")
+	for _, period := range t {
+		if period.Profile != profile {
+			continue
+		}
+		if ok && period.EffectiveFrom.Format("2006-01-02") > date {
+			break
+		}
+		goals, ok = period.NutritionGoals, true
+	}
+
+	if !ok && profile == BaseGoalProfile {
+		return DefaultNutritionGoals(), false
+	}
+	return goals, ok
+}
+
+// GoalCalendar resolves the goals for each date from goal periods, the weekday
+// schedule and per-date overrides
+type GoalCalendar struct {
+	Timeline        GoalTimeline
+	Schedules       []*GoalProfileSchedule // Sorted by effective date
+	Overrides       map[string]string      // YYYY-MM-DD => profile
+	ExerciseFlexPct int                    // Percentage of exercise calories added to the calorie goal
+}
+
+// On returns the goals for the calendar day of day: a per-date override wins over
+// the weekday schedule in effect that day; days without a profile, or whose profile
+// has no goals, use the everyday goals.
+func (c *GoalCalendar) On(day time.Time) ResolvedGoals {
+	profile := c.ProfileFor(day)
+	if profile != BaseGoalProfile {
+		if goals, ok := c.Timeline.ProfileOn(day, profile); ok {
+			return ResolvedGoals{Profile: profile, NutritionGoals: goals}
+		}
+	}
+
+	return ResolvedGoals{NutritionGoals: c.Timeline.On(day)}
+}
+
+// OnWithActivity returns the goals for the calendar day of day with the calorie goal
+// flexed by the calories burned that day
+func (c *GoalCalendar) OnWithActivity(day time.Time, caloriesBurned float64) ResolvedGoals {
+	return c.On(day).WithExerciseFlex(caloriesBurned, c.ExerciseFlexPct)
+}
+
+// ProfileFor returns the profile assigned to the calendar day of day
+func (c *GoalCalendar) ProfileFor(day time.Time) string {
+	date := day.Format("2006-01-02")
+	if profile, ok := c.Overrides[date]; ok {
+		return profile
+	}
+
+	var schedule *GoalProfileSchedule
+	for _, s := range c.Schedules {
+		if s.EffectiveFrom.Format("2006-01-02") > date {
+			break
+		}
+		schedule = s
+	}
+	if schedule == nil {
+		return BaseGoalProfile
+	}
+
+	return schedule.WeekdayProfiles[WeekdayKey(day)]
+}
@@ -11,9 +11,13 @@ type Plan struct {
 	Foods       []MealFood    `json:"foods" binding:"required,gte=1,dive"`
 	Nutrition   NutritionData `json:"nutrition"`
 	Status      string        `json:"status" db:"status" binding:"omitempty,oneof=pending completed skipped"`
+	MealID      *int64        `json:"meal_id,omitempty" db:"meal_id"`
 	AIReasoning string        `json:"ai_reasoning,omitempty" db:"ai_reasoning" binding:"omitempty,max=1000"`
 	CreatedAt   time.Time     `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time     `json:"updated_at" db:"updated_at"`
+
+	// Warnings are produced when the plan is saved and are not stored
+	Warnings []*MealWarning `json:"warnings,omitempty"`
 }
 
 // PlanFilter represents filter criteria for listing plans
@@ -30,3 +34,78 @@ type GeneratePlanRequest struct {
 	Days        int    `json:"days" binding:"omitempty,gte=1,lte=7"`
 	Preferences string `json:"preferences,omitempty" binding:"omitempty,max=500"`
 }
+
+// PlanReconcileResult summarizes a reconciliation run over past pending plans
+type PlanReconcileResult struct {
+	Checked   int `json:"checked"`
+	Completed int `json:"completed"`
+	Skipped   int `json:"skipped"`
+}
+
+// AdherenceCounts holds plan counts by status and the resulting adherence rate
+type AdherenceCounts struct {
+	Planned       int     `json:"planned"`
+	Completed     int     `json:"completed"`
+	Skipped       int     `json:"skipped"`
+	Pending       int     `json:"pending"`
+	AdherenceRate float64 `json:"adherence_rate"`
+}
+
+// WeeklyAdherence represents planned vs. actually eaten meals for one week (Monday to Sunday)
+type WeeklyAdherence struct {
+	WeekStart string `json:"week_start"`
+	WeekEnd   string `json:"week_end"`
+	AdherenceCounts
+}
+
+// PlanAdherenceStats represents plan adherence statistics over a date range
+type PlanAdherenceStats struct {
+	StartDate string            `json:"start_date"`
+	EndDate   string            `json:"end_date"`
+	Weeks     []WeeklyAdherence `json:"weeks"`
+	Overall   AdherenceCounts   `json:"overall"`
+}
+
+// PlanMealComparison compares a single plan with the meal that fulfilled it
+type PlanMealComparison struct {
+	PlanID     int64                `json:"plan_id"`
+	MealID     *int64               `json:"meal_id,omitempty"`
+	Date       string               `json:"date"`
+	MealType   string               `json:"meal_type"`
+	Status     string               `json:"status"`
+	Comparison *NutritionComparison `json:"comparison,omitempty"` // nil when no meal matched the plan
+}
+
+// PlanDayComparison compares the planned nutrition of a day with everything eaten that day
+type PlanDayComparison struct {
+	Date         string               `json:"date"`
+	PlannedMeals int                  `json:"planned_meals"`
+	EatenMeals   int                  `json:"eaten_meals"`
+	Comparison   *NutritionComparison `json:"comparison"`
+}
+
+// MealTypeCount represents how often a meal type occurs
+type MealTypeCount struct {
+	MealType string `json:"meal_type"`
+	Count    int    `json:"count"`
+}
+
+// FoodSubstitution represents a planned food that was replaced by another food in the actual meal
+type FoodSubstitution struct {
+	PlannedFoodID int64  `json:"planned_food_id"`
+	PlannedFood   string `json:"planned_food"`
+	ActualFoodID  int64  `json:"actual_food_id"`
+	ActualFood    string `json:"actual_food"`
+	Count         int    `json:"count"`
+}
+
+// PlanReport represents a plan-vs-actual report over a date range
+type PlanReport struct {
+	StartDate        string               `json:"start_date"`
+	EndDate          string               `json:"end_date"`
+	Adherence        AdherenceCounts      `json:"adherence"`
+	Meals            []PlanMealComparison `json:"meals"`
+	Days             []PlanDayComparison  `json:"days"`
+	SkippedMealTypes []MealTypeCount      `json:"skipped_meal_types"`
+	SubstitutedFoods []FoodSubstitution   `json:"substituted_foods"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// 会话撤销原因
+const (
+	SessionRevokedLogout          = "logout"           // 用户登出
+	SessionRevokedByUser          = "revoked"          // 用户在会话列表中撤销
+	SessionRevokedReuseDetected   = "reuse_detected"   // 检测到已轮换的刷新令牌被重放
+	SessionRevokedPasswordChanged = "password_changed" // 密码被修改或重置
+	SessionRevokedAccountDeletion = "account_deletion" // 用户申请删除账户
+)
+
+// Session 登录会话（一个刷新令牌家族）
+type Session struct {
+	ID             int64      `json:"id" db:"id"`
+	UserID         int64      `json:"-" db:"user_id"`
+	RefreshTokenID string     `json:"-" db:"refresh_token_id"` // 当前有效的刷新令牌 ID
+	UserAgent      string     `json:"user_agent" db:"user_agent"`
+	IPAddress      string     `json:"ip_address" db:"ip_address"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt     time.Time  `json:"last_used_at" db:"last_used_at"`
+	ExpiresAt      time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt      *time.Time `json:"-" db:"revoked_at"`
+	RevokedReason  string     `json:"-" db:"revoked_reason"`
+	Current        bool       `json:"current" db:"-"` // 是否为发起请求的会话
+}
+
+// IsActive 检查会话是否仍然有效（未撤销且未过期）
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// UserTOTP 用户的 TOTP 两步验证密钥
+type UserTOTP struct {
+	UserID       int64      `json:"user_id" db:"user_id"`
+	Secret       string     `json:"-" db:"-"`                   // 解密后的密钥（base32）
+	EnabledAt    *time.Time `json:"enabled_at" db:"enabled_at"` // 为空表示已生成密钥但尚未验证启用
+	LastUsedStep int64      `json:"-" db:"last_used_step"`      // 最近一次使用的时间步，防止验证码重放
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// IsEnabled 检查两步验证是否已启用
+func (t *UserTOTP) IsEnabled() bool {
+	return t.EnabledAt != nil
+}
+
+// RecoveryCode 两步验证恢复码（哈希存储，每个只能使用一次）
+type RecoveryCode struct {
+	ID        int64      `json:"id" db:"id"`
+	UserID    int64      `json:"user_id" db:"user_id"`
+	CodeHash  string     `json:"-" db:"code_hash"`
+	UsedAt    *time.Time `json:"used_at,omitempty" db:"used_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// TwoFactorStatus 两步验证状态
+type TwoFactorStatus struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"` // 未使用的恢复码数量
+}
+
+// TwoFactorSetup 开始设置两步验证时返回的密钥
+type TwoFactorSetup struct {
+	Secret     string `json:"secret"`      // base32 密钥，供无法扫码时手动输入
+	OTPAuthURI string `json:"otpauth_uri"` // otpauth URI，可生成二维码供身份验证器应用扫描
+}
+
+// RecoveryCodes 新生成的恢复码，只在生成时返回一次
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
@@ -8,16 +8,26 @@ const (
 	RoleUser  = "user"
 )
 
+// 用户状态常量
+const (
+	UserStatusActive   = "active"   // 正常
+	UserStatusDisabled = "disabled" // 已停用
+)
+
 // User 用户模型
 type User struct {
-	ID              int64     `json:"id" db:"id"`
-	Username        string    `json:"username" db:"username" binding:"required,min=3,max=50"`
-	PasswordHash    string    `json:"-" db:"password_hash"`
-	PasswordVersion int64     `json:"-" db:"password_version"` // 密码版本（最后修改时间戳）
-	Email           string    `json:"email,omitempty" db:"email" binding:"omitempty,email,max=100"`
-	Role            string    `json:"role" db:"role"` // 用户角色
-	CreatedAt       time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+	ID                  int64      `json:"id" db:"id"`
+	Username            string     `json:"username" db:"username" binding:"required,min=3,max=50"`
+	PasswordHash        string     `json:"-" db:"password_hash"`
+	PasswordVersion     int64      `json:"-" db:"password_version"` // 密码版本（最后修改时间戳）
+	Email               string     `json:"email,omitempty" db:"email" binding:"omitempty,email,max=100"`
+	EmailVerifiedAt     *time.Time `json:"email_verified_at,omitempty" db:"email_verified_at"`         // 邮箱验证时间，为空表示未验证
+	Role                string     `json:"role" db:"role"`                                             // 用户角色
+	DisabledAt          *time.Time `json:"disabled_at,omitempty" db:"disabled_at"`                     // 停用时间，为空表示正常
+	DeletionRequestedAt *time.Time `json:"deletion_requested_at,omitempty" db:"deletion_requested_at"` // 申请删除账户的时间，为空表示未申请
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty" db:"deletion_scheduled_at"` // 计划永久删除的时间
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 // IsAdmin 检查用户是否为管理员
@@ -25,15 +35,79 @@ func (u *User) IsAdmin() bool {
 	return u.Role == RoleAdmin
 }
 
+// IsDisabled 检查用户是否已被停用
+func (u *User) IsDisabled() bool {
+	return u.DisabledAt != nil
+}
+
+// IsPendingDeletion 检查用户是否已申请删除账户
+func (u *User) IsPendingDeletion() bool {
+	return u.DeletionScheduledAt != nil
+}
+
+// IsEmailVerified 检查用户是否已验证邮箱
+func (u *User) IsEmailVerified() bool {
+	return u.Email != "" && u.EmailVerifiedAt != nil
+}
+
+// Status 返回用户状态
+func (u *User) Status() string {
+	if u.IsDisabled() {
+		return UserStatusDisabled
+	}
+	return UserStatusActive
+}
+
+// UserFilter 用户列表筛选条件
+type UserFilter struct {
+	Query    string // 按用户名或邮箱模糊搜索
+	Role     string
+	Status   string
+	Page     int
+	PageSize int
+}
+
+// UserUsage 用户的数据使用概况
+type UserUsage struct {
+	FoodCount         int        `json:"food_count"`
+	MealCount         int        `json:"meal_count"`
+	PlanCount         int        `json:"plan_count"`
+	ConversationCount int        `json:"conversation_count"`
+	MessageCount      int        `json:"message_count"`
+	ActivityCount     int        `json:"activity_count"`
+	WaterLogCount     int        `json:"water_log_count"`
+	BodyMetricCount   int        `json:"body_metric_count"`
+	LastMealDate      *time.Time `json:"last_meal_date,omitempty"` // 最近一条餐饮记录的日期
+	LastLoginAt       *time.Time `json:"last_login_at,omitempty"`  // 最近一次成功登录的时间
+}
+
+// UserSummary 管理员查看的用户详情
+type UserSummary struct {
+	*User
+	Status string     `json:"status"`
+	Usage  *UserUsage `json:"usage,omitempty"` // 仅在用户详情中返回
+}
+
 // CreateUserRequest 创建用户请求
 type CreateUserRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=50"`
-	Password string `json:"password" binding:"required,min=8"`
+	Password string `json:"password" binding:"required,max=128"`
 	Email    string `json:"email,omitempty" binding:"omitempty,email,max=100"`
 }
 
+// DeleteAccountRequest 申请删除账户请求
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required,max=128"`
+}
+
+// AccountDeletion 账户删除申请
+type AccountDeletion struct {
+	RequestedAt time.Time `json:"deletion_requested_at"`
+	ScheduledAt time.Time `json:"deletion_scheduled_at"` // 在此之前登录即取消删除
+}
+
 // UpdatePasswordRequest 更新密码请求
 type UpdatePasswordRequest struct {
 	OldPassword string `json:"old_password" binding:"required"`
-	NewPassword string `json:"new_password" binding:"required,min=8"`
+	NewPassword string `json:"new_password" binding:"required,max=128"`
 }
@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// OIDC 登录流程用途
+const (
+	OIDCStatePurposeLogin = "login" // 使用外部身份登录
+	OIDCStatePurposeLink  = "link"  // 为已登录用户关联外部身份
+)
+
+// UserIdentity 与本地用户关联的外部身份（OpenID Connect）
+type UserIdentity struct {
+	ID          int64      `json:"id" db:"id"`
+	UserID      int64      `json:"user_id" db:"user_id"`
+	Issuer      string     `json:"issuer" db:"issuer"`
+	Subject     string     `json:"subject" db:"subject"`
+	Email       string     `json:"email,omitempty" db:"email"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
+}
+
+// OIDCLoginState 进行中的 OIDC 授权流程
+// state 只保存哈希；nonce 和 code_verifier 在回调时使用，流程完成或过期后删除
+type OIDCLoginState struct {
+	ID           int64     `json:"id" db:"id"`
+	StateHash    string    `json:"-" db:"state_hash"`
+	Purpose      string    `json:"purpose" db:"purpose"`
+	UserID       *int64    `json:"user_id,omitempty" db:"user_id"`
+	Nonce        string    `json:"-" db:"nonce"`
+	CodeVerifier string    `json:"-" db:"code_verifier"`
+	ExpiresAt    time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// OIDCProviderInfo 登录页展示的单点登录信息
+type OIDCProviderInfo struct {
+	Enabled      bool   `json:"enabled"`
+	ProviderName string `json:"provider_name,omitempty"`
+}
+
+// OIDCAuthorization 发起 OIDC 授权流程的结果，前端需要跳转到 AuthorizationURL
+type OIDCAuthorization struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+	ExpiresIn        int64  `json:"expires_in"` // 授权流程有效期（秒）
+}
@@ -5,27 +5,117 @@ import "time"
 // UserPreferences 用户偏好设置
 // 采用扁平化结构以匹配前端期望
 type UserPreferences struct {
-	ID                  int64     `json:"id" db:"id"`
-	UserID              int64     `json:"user_id" db:"user_id"`
-	TastePreferences    string    `json:"taste_preferences" db:"taste_preferences"`
-	DietaryRestrictions string    `json:"dietary_restrictions" db:"dietary_restrictions"`
-	DailyCaloriesGoal   int       `json:"daily_calories_goal" db:"daily_calories_goal"`
-	DailyProteinGoal    int       `json:"daily_protein_goal" db:"daily_protein_goal"`
-	DailyCarbsGoal      int       `json:"daily_carbs_goal" db:"daily_carbs_goal"`
-	DailyFatGoal        int       `json:"daily_fat_goal" db:"daily_fat_goal"`
-	DailyFiberGoal      int       `json:"daily_fiber_goal" db:"daily_fiber_goal"`
-	CreatedAt           time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt           time.Time `json:"updated_at" db:"updated_at"`
+	ID                  int64             `json:"id" db:"id"`
+	UserID              int64             `json:"user_id" db:"user_id"`
+	TastePreferences    string            `json:"taste_preferences" db:"taste_preferences"`
+	DietaryRestrictions string            `json:"dietary_restrictions" db:"dietary_restrictions"`
+	DailyCaloriesGoal   int               `json:"daily_calories_goal" db:"daily_calories_goal"`
+	DailyProteinGoal    int               `json:"daily_protein_goal" db:"daily_protein_goal"`
+	DailyCarbsGoal      int               `json:"daily_carbs_goal" db:"daily_carbs_goal"`
+	DailyFatGoal        int               `json:"daily_fat_goal" db:"daily_fat_goal"`
+	DailyFiberGoal      int               `json:"daily_fiber_goal" db:"daily_fiber_goal"`
+	DailyWaterGoalML    int               `json:"daily_water_goal_ml" db:"daily_water_goal_ml"`   // 每日饮水目标（毫升）
+	PlanReconcileTime   string            `json:"plan_reconcile_time" db:"plan_reconcile_time"`   // 每日计划对账时间（HH:MM），为空时使用系统默认值
+	Timezone            string            `json:"timezone" db:"timezone"`                         // IANA 时区，为空时使用服务器时区
+	PreferredMealTimes  map[string]string `json:"preferred_meal_times" db:"preferred_meal_times"` // 各餐次偏好用餐时间（餐次 => HH:MM）
+	WeightUnit          string            `json:"weight_unit" db:"weight_unit"`                   // 体重单位（kg/lb），为空时使用 kg
+	LengthUnit          string            `json:"length_unit" db:"length_unit"`                   // 围度单位（cm/in），为空时使用 cm
+	GoalWeightKg        *float64          `json:"goal_weight_kg,omitempty" db:"goal_weight_kg"`   // 目标体重（千克）
+	Sex                 string            `json:"sex" db:"sex"`                                   // 性别（male/female）
+	BirthDate           string            `json:"birth_date" db:"birth_date"`                     // 出生日期（YYYY-MM-DD）
+	HeightCm            *float64          `json:"height_cm,omitempty" db:"height_cm"`             // 身高（厘米）
+	ActivityLevel       string            `json:"activity_level" db:"activity_level"`             // 活动水平
+	WeightGoal          string            `json:"weight_goal" db:"weight_goal"`                   // 体重目标（lose/maintain/gain）
+	AdaptiveTDEE        bool              `json:"adaptive_tdee" db:"adaptive_tdee"`               // 是否使用自适应 TDEE
+	ExerciseFlexPct     int               `json:"exercise_flex_pct" db:"exercise_flex_pct"`       // 按运动消耗上调热量目标的比例（0-100）
+	Allergens           []string          `json:"allergens" db:"allergens"`                       // 需要避免的过敏原
+	Diets               []string          `json:"diets" db:"diets"`                               // 遵循的饮食方式
+	RestrictionMode     string            `json:"restriction_mode" db:"restriction_mode"`         // 违反饮食限制时的处理方式（warn/reject），为空时使用 warn
+	CreatedAt           time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time         `json:"updated_at" db:"updated_at"`
 }
 
 // UpdateUserPreferencesRequest 更新用户偏好请求
 // 采用扁平化结构，字段类型统一为简单类型
 type UpdateUserPreferencesRequest struct {
-	TastePreferences    string `json:"taste_preferences" binding:"omitempty,max=500"`
-	DietaryRestrictions string `json:"dietary_restrictions" binding:"omitempty,max=500"`
-	DailyCaloriesGoal   int    `json:"daily_calories_goal" binding:"omitempty,gte=800,lte=10000"`
-	DailyProteinGoal    int    `json:"daily_protein_goal" binding:"omitempty,gte=0,lte=500"`
-	DailyCarbsGoal      int    `json:"daily_carbs_goal" binding:"omitempty,gte=0,lte=1000"`
-	DailyFatGoal        int    `json:"daily_fat_goal" binding:"omitempty,gte=0,lte=500"`
-	DailyFiberGoal      int    `json:"daily_fiber_goal" binding:"omitempty,gte=0,lte=200"`
+	TastePreferences    string            `json:"taste_preferences" binding:"omitempty,max=500"`
+	DietaryRestrictions string            `json:"dietary_restrictions" binding:"omitempty,max=500"`
+	DailyCaloriesGoal   int               `json:"daily_calories_goal" binding:"omitempty,gte=800,lte=10000"`
+	DailyProteinGoal    int               `json:"daily_protein_goal" binding:"omitempty,gte=0,lte=500"`
+	DailyCarbsGoal      int               `json:"daily_carbs_goal" binding:"omitempty,gte=0,lte=1000"`
+	DailyFatGoal        int               `json:"daily_fat_goal" binding:"omitempty,gte=0,lte=500"`
+	DailyFiberGoal      int               `json:"daily_fiber_goal" binding:"omitempty,gte=0,lte=200"`
+	DailyWaterGoalML    int               `json:"daily_water_goal_ml" binding:"omitempty,gte=500,lte=10000"`
+	PlanReconcileTime   string            `json:"plan_reconcile_time" binding:"omitempty,datetime=15:04"`
+	Timezone            string            `json:"timezone" binding:"omitempty,max=64"`
+	PreferredMealTimes  map[string]string `json:"preferred_meal_times" binding:"omitempty,max=4"`
+	WeightUnit          string            `json:"weight_unit" binding:"omitempty,oneof=kg lb"`
+	LengthUnit          string            `json:"length_unit" binding:"omitempty,oneof=cm in"`
+	Sex                 string            `json:"sex" binding:"omitempty,oneof=male female"`
+	BirthDate           string            `json:"birth_date" binding:"omitempty,datetime=2006-01-02"`
+	HeightCm            *float64          `json:"height_cm" binding:"omitempty,gte=50,lte=300"`
+	ActivityLevel       string            `json:"activity_level" binding:"omitempty,oneof=sedentary light moderate active very_active"`
+	WeightGoal          string            `json:"weight_goal" binding:"omitempty,oneof=lose maintain gain"`
+	AdaptiveTDEE        *bool             `json:"adaptive_tdee"`
+	ExerciseFlexPct     *int              `json:"exercise_flex_pct" binding:"omitempty,gte=0,lte=100"`
+	Allergens           []string          `json:"allergens" binding:"omitempty,max=9,dive,oneof=peanuts tree_nuts gluten dairy eggs soy fish shellfish sesame"`
+	Diets               []string          `json:"diets" binding:"omitempty,max=6,dive,oneof=vegan vegetarian pescatarian halal kosher low_fodmap"`
+	RestrictionMode     string            `json:"restriction_mode" binding:"omitempty,oneof=warn reject"`
+}
+
+// DefaultPreferredMealTimes 返回默认的各餐次用餐时间（HH:MM）
+func DefaultPreferredMealTimes() map[string]string {
+	return map[string]string{
+		"breakfast": "08:00",
+		"lunch":     "12:00",
+		"dinner":    "18:30",
+		"snack":     "15:00",
+	}
+}
+
+// MealTimeFor 返回指定餐次的偏好用餐时间，未设置时使用默认值
+func (p *UserPreferences) MealTimeFor(mealType string) string {
+	if p != nil {
+		if t, ok := p.PreferredMealTimes[mealType]; ok && t != "" {
+			return t
+		}
+	}
+	return DefaultPreferredMealTimes()[mealType]
+}
+
+// WaterGoalOrDefault 返回每日饮水目标（毫升），未设置时使用默认值
+func (p *UserPreferences) WaterGoalOrDefault() int {
+	if p != nil && p.DailyWaterGoalML > 0 {
+		return p.DailyWaterGoalML
+	}
+	return DefaultDailyWaterGoalML
+}
+
+// Restrictions 返回用户的饮食限制，未设置处理方式时使用 warn
+func (p *UserPreferences) Restrictions() DietaryRestrictions {
+	restrictions := DietaryRestrictions{Mode: RestrictionModeWarn}
+	if p != nil {
+		restrictions.Allergens = p.Allergens
+		restrictions.Diets = p.Diets
+		if p.RestrictionMode != "" {
+			restrictions.Mode = p.RestrictionMode
+		}
+	}
+	return restrictions
+}
+
+// WeightUnitOrDefault 返回体重单位，未设置时使用 kg
+func (p *UserPreferences) WeightUnitOrDefault() string {
+	if p != nil && p.WeightUnit != "" {
+		return p.WeightUnit
+	}
+	return WeightUnitKg
+}
+
+// LengthUnitOrDefault 返回围度单位，未设置时使用 cm
+func (p *UserPreferences) LengthUnitOrDefault() string {
+	if p != nil && p.LengthUnit != "" {
+		return p.LengthUnit
+	}
+	return LengthUnitCm
 }
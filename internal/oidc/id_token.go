@@ -0,0 +1,96 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// idTokenLeeway 校验 ID Token 时间声明时允许的时钟偏差
+const idTokenLeeway = time.Minute
+
+// idTokenSigningMethods 接受的 ID Token 签名算法，只接受非对称签名
+var idTokenSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// idTokenClaims ID Token 声明
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce             string       `json:"nonce"`
+	AuthorizedParty   string       `json:"azp"`
+	Email             string       `json:"email"`
+	EmailVerified     flexibleBool `json:"email_verified"`
+	PreferredUsername string       `json:"preferred_username"`
+	Name              string       `json:"name"`
+}
+
+// flexibleBool 兼容部分身份提供方以字符串 "true"/"false" 返回的布尔声明
+type flexibleBool bool
+
+// UnmarshalJSON 实现 json.Unmarshaler 接口
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return fmt.Errorf("invalid boolean value %s", data)
+	}
+	*b = flexibleBool(v)
+	return nil
+}
+
+// VerifyIDToken 校验 ID Token 的签名、签发方、受众、有效期和 nonce，返回其中的身份声明
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*IDToken, error) {
+	doc, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	keys := p.keys
+	p.mu.Unlock()
+
+	claims := &idTokenClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims,
+		func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			return keys.getKey(ctx, kid)
+		},
+		jwt.WithValidMethods(idTokenSigningMethods),
+		jwt.WithIssuer(doc.Issuer),
+		jwt.WithAudience(p.clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(idTokenLeeway),
+	)
+	if err != nil {
+		if errors.Is(err, ErrProviderUnavailable) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+	if claims.AuthorizedParty != "" && claims.AuthorizedParty != p.clientID {
+		return nil, fmt.Errorf("%w: token was issued to another client", ErrInvalidIDToken)
+	}
+	if nonce == "" || claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+
+	return &IDToken{
+		Issuer:            claims.Issuer,
+		Subject:           claims.Subject,
+		Email:             claims.Email,
+		EmailVerified:     bool(claims.EmailVerified),
+		PreferredUsername: claims.PreferredUsername,
+		Name:              claims.Name,
+	}, nil
+}
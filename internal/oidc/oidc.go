@@ -0,0 +1,278 @@
+// Package oidc 提供 OpenID Connect 授权码流程（PKCE）的客户端实现，
+// 包括发现文档、授权地址生成、授权码换取令牌和基于 JWKS 的 ID Token 校验
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/config"
+)
+
+const (
+	// discoveryTTL 发现文档缓存时间
+	discoveryTTL = 24 * time.Hour
+	// maxResponseSize 身份提供方响应的最大读取长度
+	maxResponseSize = 1 << 20
+	// randomTokenSize state、nonce 和 code_verifier 的随机字节数
+	randomTokenSize = 32
+)
+
+var (
+	// ErrProviderUnavailable 无法访问身份提供方（发现文档、JWKS 或令牌端点请求失败）
+	ErrProviderUnavailable = errors.New("oidc provider is unavailable")
+	// ErrTokenExchange 身份提供方拒绝了授权码（授权码无效、已使用或 code_verifier 不匹配）
+	ErrTokenExchange = errors.New("oidc token exchange failed")
+	// ErrInvalidIDToken ID Token 无效（签名、签发方、受众、有效期或 nonce 校验失败）
+	ErrInvalidIDToken = errors.New("invalid oidc id token")
+)
+
+// IDToken 校验通过的 ID Token 中与账户关联相关的声明
+type IDToken struct {
+	Issuer            string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+	Name              string
+}
+
+// discoveryDocument OpenID Provider 发现文档中用到的字段
+type discoveryDocument struct {
+	Issuer                        string   `json:"issuer"`
+	AuthorizationEndpoint         string   `json:"authorization_endpoint"`
+	TokenEndpoint                 string   `json:"token_endpoint"`
+	JWKSURI                       string   `json:"jwks_uri"`
+	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported"`
+}
+
+// tokenResponse 令牌端点响应
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Provider OpenID Connect 身份提供方客户端
+// 发现文档和 JWKS 在首次使用时获取并缓存，身份提供方暂时不可用不影响服务启动
+type Provider struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       []string
+	httpClient   *http.Client
+
+	mu          sync.Mutex
+	discovery   *discoveryDocument
+	discoveryAt time.Time
+	keys        *keySet
+}
+
+// NewProvider 根据配置创建身份提供方客户端
+func NewProvider(cfg *config.OIDCConfig) *Provider {
+	return &Provider{
+		issuer:       strings.TrimRight(cfg.Issuer, "/"),
+		clientID:     cfg.ClientID,
+		clientSecret: cfg.ClientSecret,
+		redirectURL:  cfg.RedirectURL,
+		scopes:       cfg.Scopes,
+		httpClient:   &http.Client{Timeout: cfg.HTTPTimeout},
+	}
+}
+
+// AuthCodeURL 生成授权地址，使用 S256 方式的 PKCE
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	doc, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(doc.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid authorization endpoint: %v", ErrProviderUnavailable, err)
+	}
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.clientID)
+	query.Set("redirect_uri", p.redirectURL)
+	query.Set("scope", strings.Join(p.scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+// Exchange 使用授权码和 code_verifier 换取令牌，返回 ID Token 原文
+// 配置了 client_secret 时使用 HTTP Basic 认证（client_secret_basic），否则作为公共客户端只提交 client_id
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (string, error) {
+	doc, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.redirectURL)
+	form.Set("code_verifier", codeVerifier)
+	if p.clientSecret == "" {
+		form.Set("client_id", p.clientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.clientID), url.QueryEscape(p.clientSecret))
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: token request failed: %v", ErrProviderUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to read token response: %v", ErrProviderUnavailable, err)
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return "", fmt.Errorf("%w: token endpoint returned status %d", ErrProviderUnavailable, resp.StatusCode)
+	}
+
+	var token tokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("%w: invalid token response: %v", ErrTokenExchange, err)
+	}
+	if resp.StatusCode != http.StatusOK || token.Error != "" {
+		return "", fmt.Errorf("%w: %s %s", ErrTokenExchange, token.Error, token.ErrorDescription)
+	}
+	if token.IDToken == "" {
+		return "", fmt.Errorf("%w: token response does not contain an id_token", ErrTokenExchange)
+	}
+
+	return token.IDToken, nil
+}
+
+// getDiscovery 获取发现文档，缓存过期后重新获取；重新获取失败时继续使用旧的文档
+func (p *Provider) getDiscovery(ctx context.Context) (*discoveryDocument, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil && time.Since(p.discoveryAt) < discoveryTTL {
+		return p.discovery, nil
+	}
+
+	doc, err := p.fetchDiscovery(ctx)
+	if err != nil {
+		if p.discovery != nil {
+			return p.discovery, nil
+		}
+		return nil, err
+	}
+
+	if p.discovery == nil || p.discovery.JWKSURI != doc.JWKSURI {
+		p.keys = newKeySet(doc.JWKSURI, p.httpClient)
+	}
+	p.discovery = doc
+	p.discoveryAt = time.Now()
+
+	return doc, nil
+}
+
+// fetchDiscovery 请求 {issuer}/.well-known/openid-configuration 并校验内容
+func (p *Provider) fetchDiscovery(ctx context.Context) (*discoveryDocument, error) {
+	var doc discoveryDocument
+	if err := getJSON(ctx, p.httpClient, p.issuer+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, err
+	}
+
+	// 发现文档中的 issuer 必须与配置一致，防止使用其他签发方的令牌
+	if strings.TrimRight(doc.Issuer, "/") != p.issuer {
+		return nil, fmt.Errorf("%w: discovery issuer %q does not match configured issuer %q", ErrProviderUnavailable, doc.Issuer, p.issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: discovery document is missing required endpoints", ErrProviderUnavailable)
+	}
+	if len(doc.CodeChallengeMethodsSupported) > 0 && !contains(doc.CodeChallengeMethodsSupported, "S256") {
+		return nil, fmt.Errorf("%w: provider does not support the S256 code challenge method", ErrProviderUnavailable)
+	}
+
+	return &doc, nil
+}
+
+// getJSON 发送 GET 请求并解析 JSON 响应
+func getJSON(ctx context.Context, client *http.Client, rawURL string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrProviderUnavailable, err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: request to %s failed: %v", ErrProviderUnavailable, rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s returned status %d", ErrProviderUnavailable, rawURL, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(v); err != nil {
+		return fmt.Errorf("%w: invalid response from %s: %v", ErrProviderUnavailable, rawURL, err)
+	}
+
+	return nil
+}
+
+// GenerateRandomToken 生成 URL 安全的随机字符串，用作 state、nonce 和 code_verifier
+func GenerateRandomToken() (string, error) {
+	b := make([]byte, randomTokenSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CodeChallengeS256 根据 code_verifier 计算 S256 方式的 code_challenge
+func CodeChallengeS256(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// HashState 计算 state 的 SHA-256 哈希（十六进制），数据库只保存哈希
+func HashState(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(sum[:])
+}
+
+// contains 检查字符串列表是否包含指定值
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,381 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID     = "diet-assistant"
+	testClientSecret = "client-secret"
+	testRedirectURL  = "http://localhost:3000/oidc/callback"
+)
+
+// mockIdP 本地模拟的身份提供方，提供发现文档、JWKS 和令牌端点
+type mockIdP struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu          sync.Mutex
+	signingKey  crypto.Signer
+	method      jwt.SigningMethod
+	kid         string
+	codes       map[string]authorization
+	jwksFetches int
+	claims      jwt.MapClaims // 覆盖签发的 ID Token 声明
+}
+
+// authorization 授权端点记录的一次授权
+type authorization struct {
+	nonce         string
+	codeChallenge string
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	t.Helper()
+
+	idp := &mockIdP{t: t, codes: make(map[string]authorization)}
+	idp.rotateRSAKey("key-1")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"issuer":                           idp.server.URL,
+			"authorization_endpoint":           idp.server.URL + "/authorize",
+			"token_endpoint":                   idp.server.URL + "/token",
+			"jwks_uri":                         idp.server.URL + "/jwks",
+			"code_challenge_methods_supported": []string{"plain", "S256"},
+		})
+	})
+	mux.HandleFunc("/jwks", idp.handleJWKS)
+	mux.HandleFunc("/token", idp.handleToken)
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+
+	return idp
+}
+
+func (m *mockIdP) rotateRSAKey(kid string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		m.t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	m.mu.Lock()
+	m.signingKey, m.method, m.kid = key, jwt.SigningMethodRS256, kid
+	m.mu.Unlock()
+}
+
+func (m *mockIdP) useECKey(kid string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		m.t.Fatalf("failed to generate ec key: %v", err)
+	}
+	m.mu.Lock()
+	m.signingKey, m.method, m.kid = key, jwt.SigningMethodES256, kid
+	m.mu.Unlock()
+}
+
+func (m *mockIdP) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jwksFetches++
+
+	jwk := map[string]string{"kid": m.kid, "use": "sig"}
+	switch pub := m.signingKey.Public().(type) {
+	case *rsa.PublicKey:
+		jwk["kty"] = "RSA"
+		jwk["n"] = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk["e"] = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		point, _ := pub.Bytes()
+		jwk["kty"] = "EC"
+		jwk["crv"] = "P-256"
+		jwk["x"] = base64.RawURLEncoding.EncodeToString(point[1:33])
+		jwk["y"] = base64.RawURLEncoding.EncodeToString(point[33:])
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []interface{}{
+			map[string]string{"kty": "oct", "kid": "ignored", "k": "c2VjcmV0"},
+			jwk,
+		},
+	})
+}
+
+func (m *mockIdP) handleToken(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok || clientID != testClientID || clientSecret != testClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("redirect_uri") != testRedirectURL {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	m.mu.Lock()
+	auth, exists := m.codes[r.PostForm.Get("code")]
+	delete(m.codes, r.PostForm.Get("code"))
+	m.mu.Unlock()
+
+	if !exists || CodeChallengeS256(r.PostForm.Get("code_verifier")) != auth.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "bad code or verifier"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"id_token":     m.signIDToken(auth.nonce),
+	})
+}
+
+func (m *mockIdP) signIDToken(nonce string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":                m.server.URL,
+		"sub":                "user-123",
+		"aud":                testClientID,
+		"exp":                now.Add(5 * time.Minute).Unix(),
+		"iat":                now.Unix(),
+		"nonce":              nonce,
+		"email":              "alice@example.com",
+		"email_verified":     "true",
+		"preferred_username": "alice",
+		"name":               "Alice",
+	}
+	for k, v := range m.claims {
+		claims[k] = v
+	}
+
+	token := jwt.NewWithClaims(m.method, claims)
+	token.Header["kid"] = m.kid
+	signed, err := token.SignedString(m.signingKey)
+	if err != nil {
+		m.t.Fatalf("failed to sign id token: %v", err)
+	}
+	return signed
+}
+
+// authorize 模拟用户在身份提供方完成登录，返回授权码
+func (m *mockIdP) authorize(t *testing.T, authURL string) (code, state string) {
+	t.Helper()
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("invalid authorization url: %v", err)
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" || q.Get("client_id") != testClientID || q.Get("redirect_uri") != testRedirectURL {
+		t.Fatalf("unexpected authorization request: %s", authURL)
+	}
+
+	code = "code-" + q.Get("state")
+	m.mu.Lock()
+	m.codes[code] = authorization{nonce: q.Get("nonce"), codeChallenge: q.Get("code_challenge")}
+	m.mu.Unlock()
+
+	return code, q.Get("state")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func newTestProvider(idp *mockIdP) *Provider {
+	return NewProvider(&config.OIDCConfig{
+		Issuer:       idp.server.URL + "/",
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+		Scopes:       []string{"openid", "profile", "email"},
+		HTTPTimeout:  5 * time.Second,
+	})
+}
+
+// login 走完一次授权码流程，返回 ID Token 校验结果
+func login(t *testing.T, idp *mockIdP, provider *Provider) (*IDToken, error) {
+	t.Helper()
+	ctx := context.Background()
+
+	state, _ := GenerateRandomToken()
+	nonce, _ := GenerateRandomToken()
+	verifier, _ := GenerateRandomToken()
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, CodeChallengeS256(verifier))
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error: %v", err)
+	}
+	code, returnedState := idp.authorize(t, authURL)
+	if returnedState != state {
+		t.Fatalf("state = %s, want %s", returnedState, state)
+	}
+
+	rawIDToken, err := provider.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange() error: %v", err)
+	}
+	return provider.VerifyIDToken(ctx, rawIDToken, nonce)
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := newTestProvider(idp)
+
+	idToken, err := login(t, idp, provider)
+	if err != nil {
+		t.Fatalf("VerifyIDToken() error: %v", err)
+	}
+
+	if idToken.Issuer != idp.server.URL || idToken.Subject != "user-123" {
+		t.Errorf("identity = %s/%s, want %s/user-123", idToken.Issuer, idToken.Subject, idp.server.URL)
+	}
+	if idToken.Email != "alice@example.com" || !idToken.EmailVerified {
+		t.Errorf("email = %s verified=%v, want alice@example.com verified", idToken.Email, idToken.EmailVerified)
+	}
+	if idToken.PreferredUsername != "alice" || idToken.Name != "Alice" {
+		t.Errorf("profile = %s/%s, want alice/Alice", idToken.PreferredUsername, idToken.Name)
+	}
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := newTestProvider(idp)
+	ctx := context.Background()
+
+	verifier, _ := GenerateRandomToken()
+	authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", CodeChallengeS256(verifier))
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error: %v", err)
+	}
+	code, _ := idp.authorize(t, authURL)
+
+	if _, err := provider.Exchange(ctx, code, "another-verifier"); !errors.Is(err, ErrTokenExchange) {
+		t.Errorf("Exchange() with wrong verifier error = %v, want ErrTokenExchange", err)
+	}
+}
+
+func TestVerifyIDTokenRejectsInvalidTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+	}{
+		{"wrong audience", jwt.MapClaims{"aud": "another-client"}},
+		{"wrong issuer", jwt.MapClaims{"iss": "https://evil.example.com"}},
+		{"expired", jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}},
+		{"wrong nonce", jwt.MapClaims{"nonce": "replayed"}},
+		{"missing subject", jwt.MapClaims{"sub": ""}},
+		{"other authorized party", jwt.MapClaims{"aud": []string{testClientID, "other"}, "azp": "other"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newMockIdP(t)
+			idp.claims = tt.claims
+			provider := newTestProvider(idp)
+
+			if _, err := login(t, idp, provider); !errors.Is(err, ErrInvalidIDToken) {
+				t.Errorf("VerifyIDToken() error = %v, want ErrInvalidIDToken", err)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenRejectsUnsignedToken(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := newTestProvider(idp)
+	ctx := context.Background()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
+		"iss": idp.server.URL, "sub": "user-123", "aud": testClientID,
+		"exp": time.Now().Add(time.Minute).Unix(), "nonce": "nonce",
+	})
+	raw, _ := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+
+	if _, err := provider.VerifyIDToken(ctx, raw, "nonce"); !errors.Is(err, ErrInvalidIDToken) {
+		t.Errorf("VerifyIDToken() with alg none error = %v, want ErrInvalidIDToken", err)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := newTestProvider(idp)
+
+	if _, err := login(t, idp, provider); err != nil {
+		t.Fatalf("login before rotation error: %v", err)
+	}
+
+	// 未知 kid 在最小刷新间隔内不会重新获取 JWKS
+	idp.useECKey("key-2")
+	if _, err := login(t, idp, provider); !errors.Is(err, ErrInvalidIDToken) {
+		t.Fatalf("login right after rotation error = %v, want ErrInvalidIDToken", err)
+	}
+
+	provider.keys.fetchedAt = time.Now().Add(-2 * minJWKSRefreshInterval)
+	if _, err := login(t, idp, provider); err != nil {
+		t.Fatalf("login after rotation error: %v", err)
+	}
+	if idp.jwksFetches != 2 {
+		t.Errorf("jwks fetches = %d, want 2", idp.jwksFetches)
+	}
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := NewProvider(&config.OIDCConfig{
+		Issuer:      idp.server.URL + "/tenant",
+		ClientID:    testClientID,
+		RedirectURL: testRedirectURL,
+		Scopes:      []string{"openid"},
+		HTTPTimeout: 5 * time.Second,
+	})
+
+	if _, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "challenge"); !errors.Is(err, ErrProviderUnavailable) {
+		t.Errorf("AuthCodeURL() error = %v, want ErrProviderUnavailable", err)
+	}
+}
+
+func TestCodeChallengeS256(t *testing.T) {
+	// RFC 7636 附录 B 的示例
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	if got := CodeChallengeS256(verifier); got != want {
+		t.Errorf("CodeChallengeS256() = %s, want %s", got, want)
+	}
+}
+
+func TestFlexibleBool(t *testing.T) {
+	tests := map[string]bool{`true`: true, `false`: false, `"true"`: true, `"false"`: false}
+	for input, want := range tests {
+		var b flexibleBool
+		if err := json.Unmarshal([]byte(input), &b); err != nil {
+			t.Errorf("Unmarshal(%s) error: %v", input, err)
+			continue
+		}
+		if bool(b) != want {
+			t.Errorf("Unmarshal(%s) = %v, want %v", input, b, want)
+		}
+	}
+}
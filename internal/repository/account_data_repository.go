@@ -0,0 +1,1167 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+)
+
+var (
+	// ErrAccountDataExists 目标账户已有数据，只能导入到新账户或空账户
+	ErrAccountDataExists = errors.New("account already has data")
+)
+
+// accountRecordCountQuery 统计用户在归档中包含的所有记录总数
+const accountRecordCountQuery = `
+	SELECT
+		(SELECT COUNT(*) FROM food_categories WHERE user_id = ?) +
+		(SELECT COUNT(*) FROM foods WHERE user_id = ?) +
+		(SELECT COUNT(*) FROM meals WHERE user_id = ?) +
+		(SELECT COUNT(*) FROM plans WHERE user_id = ?) +
+		(SELECT COUNT(*) FROM fasts WHERE user_id = ?) +
+		(SELECT COUNT(*) FROM body_metrics WHERE user_id = ?) +
+		(SELECT COUNT(*) FROM activities WHERE user_id = ?) +
+		(SELECT COUNT(*) FROM water_logs WHERE user_id = ?) +
+		(SELECT COUNT(*) FROM nutrition_goals WHERE user_id = ?) +
+		(SELECT COUNT(*) FROM goal_profile_schedules WHERE user_id = ?) +
+		(SELECT COUNT(*) FROM goal_profile_overrides WHERE user_id = ?) +
+		(SELECT COUNT(*) FROM ai_settings WHERE user_id = ?) +
+		(SELECT COUNT(*) FROM conversation_flows WHERE user_id = ?) +
+		(SELECT COUNT(*) FROM messages m INNER JOIN conversation_flows c ON c.id = m.conversation_id WHERE c.user_id = ?)
+`
+
+// AccountDataRepository 账户数据导出和导入仓储接口
+type AccountDataRepository interface {
+	CountRecords(ctx context.Context, userID int64) (int, error)
+	ExportData(ctx context.Context, userID int64) (*model.AccountArchive, error)
+	ImportData(ctx context.Context, userID int64, archive *model.AccountArchive) (*model.AccountImportResult, error)
+}
+
+// accountDataRepository 账户数据导出和导入仓储实现
+type accountDataRepository struct {
+	db *sql.DB
+}
+
+// NewAccountDataRepository 创建账户数据导出和导入仓储实例
+func NewAccountDataRepository(db *sql.DB) AccountDataRepository {
+	return &accountDataRepository{
+		db: db,
+	}
+}
+
+// CountRecords 统计用户的记录数
+func (r *accountDataRepository) CountRecords(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, accountRecordCountQuery, accountRecordCountArgs(userID)...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count account records: %w", err)
+	}
+	return count, nil
+}
+
+// accountRecordCountArgs 返回 accountRecordCountQuery 的参数，每个子查询一个用户 ID
+func accountRecordCountArgs(userID int64) []interface{} {
+	args := make([]interface{}, strings.Count(accountRecordCountQuery, "?"))
+	for i := range args {
+		args[i] = userID
+	}
+	return args
+}
+
+// ExportData 读取用户在归档中包含的所有记录
+// 返回的归档不包含版本、资料和偏好，由调用方填写
+func (r *accountDataRepository) ExportData(ctx context.Context, userID int64) (*model.AccountArchive, error) {
+	archive := &model.AccountArchive{}
+	var err error
+
+	if archive.FoodCategories, err = r.exportFoodCategories(ctx, userID); err != nil {
+		return nil, err
+	}
+	if archive.Foods, err = r.exportFoods(ctx, userID); err != nil {
+		return nil, err
+	}
+	if archive.Meals, err = r.exportMeals(ctx, userID); err != nil {
+		return nil, err
+	}
+	if archive.Plans, err = r.exportPlans(ctx, userID); err != nil {
+		return nil, err
+	}
+	if archive.Fasts, err = r.exportFasts(ctx, userID); err != nil {
+		return nil, err
+	}
+	if archive.BodyMetrics, err = r.exportBodyMetrics(ctx, userID); err != nil {
+		return nil, err
+	}
+	if archive.Activities, err = r.exportActivities(ctx, userID); err != nil {
+		return nil, err
+	}
+	if archive.WaterLogs, err = r.exportWaterLogs(ctx, userID); err != nil {
+		return nil, err
+	}
+	if archive.Goals, err = r.exportGoals(ctx, userID); err != nil {
+		return nil, err
+	}
+	if archive.GoalSchedules, err = r.exportGoalSchedules(ctx, userID); err != nil {
+		return nil, err
+	}
+	if archive.GoalOverrides, err = r.exportGoalOverrides(ctx, userID); err != nil {
+		return nil, err
+	}
+	if archive.AISettings, err = r.exportAISettings(ctx, userID); err != nil {
+		return nil, err
+	}
+	if archive.Conversations, err = r.exportConversations(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+// exportFoodCategories 读取用户的自定义食材分类，上级分类按标识引用
+// 按 ID 排序，通常上级分类在前；导入时不依赖这个顺序
+func (r *accountDataRepository) exportFoodCategories(ctx context.Context, userID int64) ([]*model.ArchiveFoodCategory, error) {
+	query := `
+		SELECT c.slug, c.name, p.slug, c.sort_order
+		FROM food_categories c
+		LEFT JOIN food_categories p ON p.id = c.parent_id
+		WHERE c.user_id = ?
+		ORDER BY c.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export food categories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := make([]*model.ArchiveFoodCategory, 0)
+	for rows.Next() {
+		category := &model.ArchiveFoodCategory{}
+		var parent sql.NullString
+		if err := rows.Scan(&category.Slug, &category.Name, &parent, &category.SortOrder); err != nil {
+			return nil, fmt.Errorf("failed to scan food category: %w", err)
+		}
+		category.Parent = parent.String
+		categories = append(categories, category)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating food categories: %w", err)
+	}
+
+	return categories, nil
+}
+
+// exportFoods 读取用户的所有食材
+func (r *accountDataRepository) exportFoods(ctx context.Context, userID int64) ([]*model.Food, error) {
+	query := `SELECT ` + foodColumns + ` FROM foods WHERE user_id = ? ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export foods: %w", err)
+	}
+	defer rows.Close()
+
+	foods := make([]*model.Food, 0)
+	for rows.Next() {
+		food, err := scanFood(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan food: %w", err)
+		}
+		foods = append(foods, food)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating foods: %w", err)
+	}
+
+	return foods, nil
+}
+
+// exportMeals 读取用户的所有餐饮记录
+func (r *accountDataRepository) exportMeals(ctx context.Context, userID int64) ([]*model.Meal, error) {
+	query := `
+		SELECT id, user_id, meal_date, meal_type, foods, nutrition, notes, eaten_at, eaten_at_estimated, created_at, updated_at
+		FROM meals
+		WHERE user_id = ?
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export meals: %w", err)
+	}
+	defer rows.Close()
+
+	meals := make([]*model.Meal, 0)
+	for rows.Next() {
+		meal := &model.Meal{}
+		var foodsJSON, nutritionJSON []byte
+		var notes sql.NullString
+		var eatenAt sql.NullTime
+
+		err := rows.Scan(
+			&meal.ID,
+			&meal.UserID,
+			&meal.MealDate,
+			&meal.MealType,
+			&foodsJSON,
+			&nutritionJSON,
+			&notes,
+			&eatenAt,
+			&meal.EatenAtEstimated,
+			&meal.CreatedAt,
+			&meal.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan meal: %w", err)
+		}
+
+		if err := json.Unmarshal(foodsJSON, &meal.Foods); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal foods: %w", err)
+		}
+		if err := json.Unmarshal(nutritionJSON, &meal.Nutrition); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal nutrition: %w", err)
+		}
+		meal.Notes = notes.String
+		if eatenAt.Valid {
+			meal.EatenAt = &eatenAt.Time
+		}
+
+		meals = append(meals, meal)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating meals: %w", err)
+	}
+
+	return meals, nil
+}
+
+// exportPlans 读取用户的所有饮食计划
+func (r *accountDataRepository) exportPlans(ctx context.Context, userID int64) ([]*model.Plan, error) {
+	query := `
+		SELECT id, user_id, plan_date, meal_type, foods, nutrition, status, meal_id, ai_reasoning, created_at, updated_at
+		FROM plans
+		WHERE user_id = ?
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export plans: %w", err)
+	}
+	defer rows.Close()
+
+	plans := make([]*model.Plan, 0)
+	for rows.Next() {
+		plan := &model.Plan{}
+		var foodsJSON, nutritionJSON []byte
+		var mealID sql.NullInt64
+		var aiReasoning sql.NullString
+
+		err := rows.Scan(
+			&plan.ID,
+			&plan.UserID,
+			&plan.PlanDate,
+			&plan.MealType,
+			&foodsJSON,
+			&nutritionJSON,
+			&plan.Status,
+			&mealID,
+			&aiReasoning,
+			&plan.CreatedAt,
+			&plan.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan plan: %w", err)
+		}
+
+		if err := json.Unmarshal(foodsJSON, &plan.Foods); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal foods: %w", err)
+		}
+		if err := json.Unmarshal(nutritionJSON, &plan.Nutrition); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal nutrition: %w", err)
+		}
+		if mealID.Valid {
+			plan.MealID = &mealID.Int64
+		}
+		plan.AIReasoning = aiReasoning.String
+
+		plans = append(plans, plan)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating plans: %w", err)
+	}
+
+	return plans, nil
+}
+
+// exportFasts 读取用户的所有断食记录
+func (r *accountDataRepository) exportFasts(ctx context.Context, userID int64) ([]*model.Fast, error) {
+	query := `SELECT ` + fastColumns + ` FROM fasts WHERE user_id = ? ORDER BY id`
+
+	fasts := make([]*model.Fast, 0)
+	err := r.exportRows(ctx, "fasts", query, userID, func(row rowScanner) error {
+		fast, err := scanFast(row)
+		if err != nil {
+			return err
+		}
+		fasts = append(fasts, fast)
+		return nil
+	})
+	return fasts, err
+}
+
+// exportBodyMetrics 读取用户的所有身体指标记录
+func (r *accountDataRepository) exportBodyMetrics(ctx context.Context, userID int64) ([]*model.BodyMetric, error) {
+	query := `SELECT ` + bodyMetricColumns + ` FROM body_metrics WHERE user_id = ? ORDER BY measured_on`
+
+	metrics := make([]*model.BodyMetric, 0)
+	err := r.exportRows(ctx, "body metrics", query, userID, func(row rowScanner) error {
+		metric, err := scanBodyMetric(row)
+		if err != nil {
+			return err
+		}
+		metrics = append(metrics, metric)
+		return nil
+	})
+	return metrics, err
+}
+
+// exportActivities 读取用户的所有运动记录
+func (r *accountDataRepository) exportActivities(ctx context.Context, userID int64) ([]*model.Activity, error) {
+	query := `SELECT ` + activityColumns + ` FROM activities WHERE user_id = ? ORDER BY id`
+
+	activities := make([]*model.Activity, 0)
+	err := r.exportRows(ctx, "activities", query, userID, func(row rowScanner) error {
+		activity, err := scanActivity(row)
+		if err != nil {
+			return err
+		}
+		activities = append(activities, activity)
+		return nil
+	})
+	return activities, err
+}
+
+// exportWaterLogs 读取用户的所有饮水记录
+func (r *accountDataRepository) exportWaterLogs(ctx context.Context, userID int64) ([]*model.WaterLog, error) {
+	query := `SELECT ` + waterLogColumns + ` FROM water_logs WHERE user_id = ? ORDER BY id`
+
+	logs := make([]*model.WaterLog, 0)
+	err := r.exportRows(ctx, "water logs", query, userID, func(row rowScanner) error {
+		log, err := scanWaterLog(row)
+		if err != nil {
+			return err
+		}
+		logs = append(logs, log)
+		return nil
+	})
+	return logs, err
+}
+
+// exportGoals 读取用户所有目标配置的营养目标历史
+func (r *accountDataRepository) exportGoals(ctx context.Context, userID int64) ([]*model.GoalPeriod, error) {
+	query := `SELECT ` + nutritionGoalColumns + ` FROM nutrition_goals WHERE user_id = ? ORDER BY effective_from, profile`
+
+	goals := make([]*model.GoalPeriod, 0)
+	err := r.exportRows(ctx, "nutrition goals", query, userID, func(row rowScanner) error {
+		goal, err := scanGoalPeriod(row)
+		if err != nil {
+			return err
+		}
+		goals = append(goals, goal)
+		return nil
+	})
+	return goals, err
+}
+
+// exportGoalSchedules 读取用户按星期安排目标配置的历史
+func (r *accountDataRepository) exportGoalSchedules(ctx context.Context, userID int64) ([]*model.GoalProfileSchedule, error) {
+	query := `SELECT ` + goalProfileScheduleColumns + ` FROM goal_profile_schedules WHERE user_id = ? ORDER BY effective_from`
+
+	schedules := make([]*model.GoalProfileSchedule, 0)
+	err := r.exportRows(ctx, "goal profile schedules", query, userID, func(row rowScanner) error {
+		schedule, err := scanGoalProfileSchedule(row)
+		if err != nil {
+			return err
+		}
+		schedules = append(schedules, schedule)
+		return nil
+	})
+	return schedules, err
+}
+
+// exportGoalOverrides 读取用户按日期指定的目标配置
+func (r *accountDataRepository) exportGoalOverrides(ctx context.Context, userID int64) ([]*model.GoalProfileOverride, error) {
+	query := `SELECT ` + goalProfileOverrideColumns + ` FROM goal_profile_overrides WHERE user_id = ? ORDER BY override_date`
+
+	overrides := make([]*model.GoalProfileOverride, 0)
+	err := r.exportRows(ctx, "goal profile overrides", query, userID, func(row rowScanner) error {
+		override, err := scanGoalProfileOverride(row)
+		if err != nil {
+			return err
+		}
+		overrides = append(overrides, override)
+		return nil
+	})
+	return overrides, err
+}
+
+// exportRows 执行只有用户 ID 一个参数的查询，逐行调用 scan；name 用于错误信息
+func (r *accountDataRepository) exportRows(ctx context.Context, name, query string, userID int64, scan func(rowScanner) error) error {
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to export %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return fmt.Errorf("failed to scan %s: %w", name, err)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// exportAISettings 读取用户的 AI 设置，不读取加密的 API 密钥
+func (r *accountDataRepository) exportAISettings(ctx context.Context, userID int64) ([]*model.ArchiveAISettings, error) {
+	query := `
+		SELECT provider, api_endpoint, model, temperature, max_tokens, is_active, created_at
+		FROM ai_settings
+		WHERE user_id = ?
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export ai settings: %w", err)
+	}
+	defer rows.Close()
+
+	settingsList := make([]*model.ArchiveAISettings, 0)
+	for rows.Next() {
+		settings := &model.ArchiveAISettings{}
+		var apiEndpoint, modelName sql.NullString
+
+		err := rows.Scan(
+			&settings.Provider,
+			&apiEndpoint,
+			&modelName,
+			&settings.Temperature,
+			&settings.MaxTokens,
+			&settings.IsActive,
+			&settings.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ai settings: %w", err)
+		}
+		settings.APIEndpoint = apiEndpoint.String
+		settings.Model = modelName.String
+
+		settingsList = append(settingsList, settings)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ai settings: %w", err)
+	}
+
+	return settingsList, nil
+}
+
+// exportConversations 读取用户的所有对话流及其消息，不包含原始请求和响应
+func (r *accountDataRepository) exportConversations(ctx context.Context, userID int64) ([]*model.ArchiveConversation, error) {
+	query := `
+		SELECT id, title, is_favorited, created_at, updated_at
+		FROM conversation_flows
+		WHERE user_id = ?
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export conversations: %w", err)
+	}
+	defer rows.Close()
+
+	conversations := make([]*model.ArchiveConversation, 0)
+	byID := make(map[int64]*model.ArchiveConversation)
+	for rows.Next() {
+		conv := &model.ArchiveConversation{Messages: []*model.ArchiveMessage{}}
+		if err := rows.Scan(&conv.ID, &conv.Title, &conv.IsFavorited, &conv.CreatedAt, &conv.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan conversation: %w", err)
+		}
+		conversations = append(conversations, conv)
+		byID[conv.ID] = conv
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating conversations: %w", err)
+	}
+
+	if len(conversations) == 0 {
+		return conversations, nil
+	}
+
+	msgQuery := `
+		SELECT m.conversation_id, m.role, m.content, m.created_at
+		FROM messages m
+		INNER JOIN conversation_flows c ON c.id = m.conversation_id
+		WHERE c.user_id = ?
+		ORDER BY m.conversation_id, m.created_at, m.id
+	`
+
+	msgRows, err := r.db.QueryContext(ctx, msgQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export messages: %w", err)
+	}
+	defer msgRows.Close()
+
+	for msgRows.Next() {
+		var convID int64
+		msg := &model.ArchiveMessage{}
+		if err := msgRows.Scan(&convID, &msg.Role, &msg.Content, &msg.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		if conv, ok := byID[convID]; ok {
+			conv.Messages = append(conv.Messages, msg)
+		}
+	}
+
+	if err := msgRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating messages: %w", err)
+	}
+
+	return conversations, nil
+}
+
+// ImportData 在一个事务中将归档数据导入到用户账户
+// 所有记录重新分配 ID：自定义食材分类重新创建，食材的分类按分类标识映射，找不到的分类改为 other；
+// 餐饮记录和计划中的食材按归档中的原始食材 ID 重新关联，找不到的食材 ID 置为 0；
+// 计划关联的餐饮记录和中断断食的餐饮记录同样重新关联，找不到时取消关联。AI 设置没有 API 密钥，导入后均为未激活状态
+func (r *accountDataRepository) ImportData(ctx context.Context, userID int64, archive *model.AccountArchive) (*model.AccountImportResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 锁定用户行，防止同一账户并发导入
+	var lockedID int64
+	if err := tx.QueryRowContext(ctx, `SELECT id FROM users WHERE id = ? FOR UPDATE`, userID).Scan(&lockedID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to lock user: %w", err)
+	}
+
+	var count int
+	if err := tx.QueryRowContext(ctx, accountRecordCountQuery, accountRecordCountArgs(userID)...).Scan(&count); err != nil {
+		return nil, fmt.Errorf("failed to count account records: %w", err)
+	}
+	if count > 0 {
+		return nil, ErrAccountDataExists
+	}
+
+	result := &model.AccountImportResult{}
+
+	categorySlugs, err := importFoodCategories(ctx, tx, userID, archive.FoodCategories)
+	if err != nil {
+		return nil, err
+	}
+	result.FoodCategories = len(archive.FoodCategories)
+
+	foodIDs, unmappedCategories, err := importFoods(ctx, tx, userID, archive.Foods, categorySlugs)
+	if err != nil {
+		return nil, err
+	}
+	result.Foods = len(archive.Foods)
+	result.UnmappedCategories = unmappedCategories
+
+	mealIDs, unmapped, err := importMeals(ctx, tx, userID, archive.Meals, foodIDs)
+	if err != nil {
+		return nil, err
+	}
+	result.Meals = len(archive.Meals)
+	result.UnmappedFoodRefs += unmapped
+
+	unmapped, err = importPlans(ctx, tx, userID, archive.Plans, foodIDs, mealIDs)
+	if err != nil {
+		return nil, err
+	}
+	result.Plans = len(archive.Plans)
+	result.UnmappedFoodRefs += unmapped
+
+	if err := importFasts(ctx, tx, userID, archive.Fasts, mealIDs); err != nil {
+		return nil, err
+	}
+	result.Fasts = len(archive.Fasts)
+
+	if err := importBodyMetrics(ctx, tx, userID, archive.BodyMetrics); err != nil {
+		return nil, err
+	}
+	result.BodyMetrics = len(archive.BodyMetrics)
+
+	if err := importActivities(ctx, tx, userID, archive.Activities); err != nil {
+		return nil, err
+	}
+	result.Activities = len(archive.Activities)
+
+	if err := importWaterLogs(ctx, tx, userID, archive.WaterLogs); err != nil {
+		return nil, err
+	}
+	result.WaterLogs = len(archive.WaterLogs)
+
+	if err := importGoals(ctx, tx, userID, archive); err != nil {
+		return nil, err
+	}
+	result.Goals = len(archive.Goals)
+	result.GoalSchedules = len(archive.GoalSchedules)
+	result.GoalOverrides = len(archive.GoalOverrides)
+
+	if err := importAISettings(ctx, tx, userID, archive.AISettings); err != nil {
+		return nil, err
+	}
+	result.AISettings = len(archive.AISettings)
+
+	messages, err := importConversations(ctx, tx, userID, archive.Conversations)
+	if err != nil {
+		return nil, err
+	}
+	result.Conversations = len(archive.Conversations)
+	result.Messages = messages
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+// importFoodCategories 重新创建自定义食材分类，返回归档中的分类标识到账户中分类标识的映射（包括内置分类）
+// 与内置分类标识相同的分类合并到内置分类；上级分类不存在或形成循环的分类作为顶级分类创建
+func importFoodCategories(ctx context.Context, tx *sql.Tx, userID int64, categories []*model.ArchiveFoodCategory) (map[string]string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id, slug FROM food_categories WHERE user_id IS NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list built-in food categories: %w", err)
+	}
+	defer rows.Close()
+
+	// 分类标识 => 账户中的分类 ID
+	categoryIDs := make(map[string]int64)
+	slugs := make(map[string]string)
+	for rows.Next() {
+		var id int64
+		var slug string
+		if err := rows.Scan(&id, &slug); err != nil {
+			return nil, fmt.Errorf("failed to scan food category: %w", err)
+		}
+		categoryIDs[slug] = id
+		slugs[slug] = slug
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating food categories: %w", err)
+	}
+	rows.Close()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO food_categories (user_id, parent_id, slug, name, sort_order)
+		VALUES (?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	create := func(category *model.ArchiveFoodCategory, parentID sql.NullInt64) error {
+		res, err := stmt.ExecContext(ctx, userID, parentID, category.Slug, category.Name, category.SortOrder)
+		if err != nil {
+			return fmt.Errorf("failed to import food category '%s': %w", category.Slug, err)
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to get food category id: %w", err)
+		}
+		categoryIDs[category.Slug] = id
+		slugs[category.Slug] = category.Slug
+		return nil
+	}
+
+	// 上级分类创建后才能创建下级分类，每一轮创建上级分类已存在的分类
+	pending := make([]*model.ArchiveFoodCategory, 0, len(categories))
+	for _, category := range categories {
+		if _, ok := categoryIDs[category.Slug]; !ok {
+			pending = append(pending, category)
+		}
+	}
+	for len(pending) > 0 {
+		var next []*model.ArchiveFoodCategory
+		for _, category := range pending {
+			var parentID sql.NullInt64
+			if category.Parent != "" {
+				id, ok := categoryIDs[category.Parent]
+				if !ok {
+					next = append(next, category)
+					continue
+				}
+				parentID = sql.NullInt64{Int64: id, Valid: true}
+			}
+			if err := create(category, parentID); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(next) == len(pending) {
+			// 剩余分类的上级分类不存在或形成循环，作为顶级分类创建
+			for _, category := range next {
+				if err := create(category, sql.NullInt64{}); err != nil {
+					return nil, err
+				}
+			}
+			break
+		}
+		pending = next
+	}
+
+	return slugs, nil
+}
+
+// importFoods 导入食材，返回原始 ID 到新 ID 的映射和分类不存在的食材数
+func importFoods(ctx context.Context, tx *sql.Tx, userID int64, foods []*model.Food, categorySlugs map[string]string) (map[int64]int64, int, error) {
+	foodIDs := make(map[int64]int64, len(foods))
+	if len(foods) == 0 {
+		return foodIDs, 0, nil
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO foods (user_id, name, aliases, category, tags, price, unit, protein, carbs, fat, fiber, calories,
+		                   water_pct, allergens, diet_tags, available, favorite)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	unmapped := 0
+	for _, food := range foods {
+		category, ok := categorySlugs[food.Category]
+		if !ok {
+			category = model.DefaultFoodCategory
+			unmapped++
+		}
+
+		res, err := stmt.ExecContext(ctx,
+			userID,
+			food.Name,
+			marshalStringList(food.Aliases),
+			category,
+			marshalStringList(food.Tags),
+			food.Price,
+			food.Unit,
+			food.Protein,
+			food.Carbs,
+			food.Fat,
+			food.Fiber,
+			food.Calories,
+			food.WaterPct,
+			marshalStringList(food.Allergens),
+			marshalStringList(food.DietTags),
+			food.Available,
+			food.Favorite,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to import food '%s': %w", food.Name, err)
+		}
+
+		id, err := res.LastInsertId()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get food id: %w", err)
+		}
+		if food.ID > 0 {
+			foodIDs[food.ID] = id
+		}
+	}
+
+	return foodIDs, unmapped, nil
+}
+
+// importMeals 导入餐饮记录，返回原始 ID 到新 ID 的映射和找不到食材的条目数
+func importMeals(ctx context.Context, tx *sql.Tx, userID int64, meals []*model.Meal, foodIDs map[int64]int64) (map[int64]int64, int, error) {
+	mealIDs := make(map[int64]int64, len(meals))
+	if len(meals) == 0 {
+		return mealIDs, 0, nil
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO meals (user_id, meal_date, meal_type, foods, nutrition, notes, eaten_at, eaten_at_estimated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	unmapped := 0
+	for _, meal := range meals {
+		foods, missing := remapMealFoods(meal.Foods, foodIDs)
+		unmapped += missing
+
+		foodsJSON, err := json.Marshal(foods)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to marshal foods: %w", err)
+		}
+		nutritionJSON, err := json.Marshal(meal.Nutrition)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to marshal nutrition: %w", err)
+		}
+
+		res, err := stmt.ExecContext(ctx,
+			userID,
+			dateArg(meal.MealDate),
+			meal.MealType,
+			foodsJSON,
+			nutritionJSON,
+			meal.Notes,
+			meal.EatenAt,
+			meal.EatenAtEstimated,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to import meal: %w", err)
+		}
+
+		id, err := res.LastInsertId()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get meal id: %w", err)
+		}
+		if meal.ID > 0 {
+			mealIDs[meal.ID] = id
+		}
+	}
+
+	return mealIDs, unmapped, nil
+}
+
+// importPlans 导入饮食计划，返回找不到食材的条目数
+func importPlans(ctx context.Context, tx *sql.Tx, userID int64, plans []*model.Plan, foodIDs, mealIDs map[int64]int64) (int, error) {
+	if len(plans) == 0 {
+		return 0, nil
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO plans (user_id, plan_date, meal_type, foods, nutrition, status, meal_id, ai_reasoning)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	unmapped := 0
+	for _, plan := range plans {
+		foods, missing := remapMealFoods(plan.Foods, foodIDs)
+		unmapped += missing
+
+		foodsJSON, err := json.Marshal(foods)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal foods: %w", err)
+		}
+		nutritionJSON, err := json.Marshal(plan.Nutrition)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal nutrition: %w", err)
+		}
+
+		var mealID sql.NullInt64
+		if plan.MealID != nil {
+			if id, ok := mealIDs[*plan.MealID]; ok {
+				mealID = sql.NullInt64{Int64: id, Valid: true}
+			}
+		}
+
+		_, err = stmt.ExecContext(ctx,
+			userID,
+			dateArg(plan.PlanDate),
+			plan.MealType,
+			foodsJSON,
+			nutritionJSON,
+			plan.Status,
+			mealID,
+			plan.AIReasoning,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to import plan: %w", err)
+		}
+	}
+
+	return unmapped, nil
+}
+
+// importFasts 导入断食记录，中断断食的餐饮记录按原始 ID 重新关联，找不到时取消关联
+func importFasts(ctx context.Context, tx *sql.Tx, userID int64, fasts []*model.Fast, mealIDs map[int64]int64) error {
+	query := `
+		INSERT INTO fasts (user_id, protocol, target_hours, started_at, ended_at, status, broken_by_meal_id, notes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	for _, fast := range fasts {
+		var mealID sql.NullInt64
+		if fast.BrokenByMealID != nil {
+			if id, ok := mealIDs[*fast.BrokenByMealID]; ok {
+				mealID = sql.NullInt64{Int64: id, Valid: true}
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, query,
+			userID,
+			fast.Protocol,
+			fast.TargetHours,
+			fast.StartedAt,
+			fast.EndedAt,
+			fast.Status,
+			mealID,
+			nullableString(fast.Notes),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to import fast: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// importBodyMetrics 导入身体指标记录
+func importBodyMetrics(ctx context.Context, tx *sql.Tx, userID int64, metrics []*model.BodyMetric) error {
+	query := `
+		INSERT INTO body_metrics (user_id, measured_on, weight_kg, body_fat_pct, waist_cm, hip_cm, chest_cm, neck_cm, notes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	for _, metric := range metrics {
+		_, err := tx.ExecContext(ctx, query,
+			userID,
+			dateArg(metric.MeasuredOn),
+			metric.WeightKg,
+			metric.BodyFatPct,
+			metric.WaistCm,
+			metric.HipCm,
+			metric.ChestCm,
+			metric.NeckCm,
+			nullableString(metric.Notes),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to import body metric: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// importActivities 导入运动记录
+func importActivities(ctx context.Context, tx *sql.Tx, userID int64, activities []*model.Activity) error {
+	query := `
+		INSERT INTO activities (user_id, activity_type, performed_on, started_at, duration_minutes, intensity,
+		                        met, calories_burned, calories_estimated, notes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	for _, activity := range activities {
+		_, err := tx.ExecContext(ctx, query,
+			userID,
+			activity.ActivityType,
+			dateArg(activity.PerformedOn),
+			activity.StartedAt,
+			activity.DurationMinutes,
+			activity.Intensity,
+			activity.MET,
+			activity.CaloriesBurned,
+			activity.CaloriesEstimated,
+			nullableString(activity.Notes),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to import activity: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// importWaterLogs 导入饮水记录
+func importWaterLogs(ctx context.Context, tx *sql.Tx, userID int64, logs []*model.WaterLog) error {
+	query := `
+		INSERT INTO water_logs (user_id, log_date, logged_at, amount_ml, beverage_type, notes)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	for _, log := range logs {
+		_, err := tx.ExecContext(ctx, query,
+			userID,
+			dateArg(log.LogDate),
+			log.LoggedAt,
+			log.AmountML,
+			log.BeverageType,
+			nullableString(log.Notes),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to import water log: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// importGoals 导入营养目标历史、星期安排和按日期指定的目标配置
+func importGoals(ctx context.Context, tx *sql.Tx, userID int64, archive *model.AccountArchive) error {
+	goalQuery := `
+		INSERT INTO nutrition_goals (user_id, profile, effective_from, calories, protein, carbs, fat, fiber, note)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	for _, goal := range archive.Goals {
+		_, err := tx.ExecContext(ctx, goalQuery,
+			userID,
+			goal.Profile,
+			dateArg(goal.EffectiveFrom),
+			goal.Calories,
+			goal.Protein,
+			goal.Carbs,
+			goal.Fat,
+			goal.Fiber,
+			nullableString(goal.Note),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to import nutrition goal: %w", err)
+		}
+	}
+
+	scheduleQuery := `
+		INSERT INTO goal_profile_schedules (user_id, effective_from, weekday_profiles)
+		VALUES (?, ?, ?)
+	`
+	for _, schedule := range archive.GoalSchedules {
+		weekdayProfiles, err := json.Marshal(schedule.WeekdayProfiles)
+		if err != nil {
+			return fmt.Errorf("failed to marshal weekday profiles: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, scheduleQuery, userID, dateArg(schedule.EffectiveFrom), string(weekdayProfiles)); err != nil {
+			return fmt.Errorf("failed to import goal profile schedule: %w", err)
+		}
+	}
+
+	overrideQuery := `
+		INSERT INTO goal_profile_overrides (user_id, override_date, profile, note)
+		VALUES (?, ?, ?, ?)
+	`
+	for _, override := range archive.GoalOverrides {
+		if _, err := tx.ExecContext(ctx, overrideQuery, userID, dateArg(override.Date), override.Profile, nullableString(override.Note)); err != nil {
+			return fmt.Errorf("failed to import goal profile override: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// importAISettings 导入 AI 设置，API 密钥为空且不激活
+func importAISettings(ctx context.Context, tx *sql.Tx, userID int64, settingsList []*model.ArchiveAISettings) error {
+	query := `
+		INSERT INTO ai_settings (user_id, provider, api_endpoint, api_key_encrypted, model, temperature, max_tokens, is_active, created_at)
+		VALUES (?, ?, ?, '', ?, ?, ?, FALSE, ?)
+	`
+
+	for _, settings := range settingsList {
+		_, err := tx.ExecContext(ctx, query,
+			userID,
+			settings.Provider,
+			nullableString(settings.APIEndpoint),
+			settings.Model,
+			settings.Temperature,
+			settings.MaxTokens,
+			settings.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to import ai settings: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// importConversations 导入对话流和消息，返回导入的消息数
+func importConversations(ctx context.Context, tx *sql.Tx, userID int64, conversations []*model.ArchiveConversation) (int, error) {
+	if len(conversations) == 0 {
+		return 0, nil
+	}
+
+	convStmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO conversation_flows (user_id, title, is_favorited, message_count, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer convStmt.Close()
+
+	msgStmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO messages (conversation_id, role, content, created_at)
+		VALUES (?, ?, ?, ?)
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer msgStmt.Close()
+
+	messages := 0
+	for _, conv := range conversations {
+		res, err := convStmt.ExecContext(ctx,
+			userID,
+			conv.Title,
+			conv.IsFavorited,
+			len(conv.Messages),
+			conv.CreatedAt,
+			conv.UpdatedAt,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to import conversation: %w", err)
+		}
+
+		convID, err := res.LastInsertId()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get conversation id: %w", err)
+		}
+
+		for _, msg := range conv.Messages {
+			if _, err := msgStmt.ExecContext(ctx, convID, msg.Role, msg.Content, msg.CreatedAt); err != nil {
+				return 0, fmt.Errorf("failed to import message: %w", err)
+			}
+			messages++
+		}
+	}
+
+	return messages, nil
+}
+
+// remapMealFoods 按食材 ID 映射替换餐饮记录或计划中的食材 ID，返回新的列表和找不到食材的条目数
+func remapMealFoods(foods []model.MealFood, foodIDs map[int64]int64) ([]model.MealFood, int) {
+	remapped := make([]model.MealFood, len(foods))
+	missing := 0
+	for i, food := range foods {
+		remapped[i] = food
+		if id, ok := foodIDs[food.FoodID]; ok {
+			remapped[i].FoodID = id
+		} else {
+			remapped[i].FoodID = 0
+			missing++
+		}
+	}
+	return remapped, missing
+}
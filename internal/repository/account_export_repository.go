@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+)
+
+var (
+	// ErrAccountExportNotFound 导出任务不存在
+	ErrAccountExportNotFound = errors.New("account export not found")
+)
+
+// accountExportColumns 导出任务查询的列，与 scanAccountExport 的扫描顺序一致
+const accountExportColumns = `id, user_id, format, status, file_name, file_size, record_count,
+	error_message, created_at, completed_at, expires_at`
+
+// AccountExportRepository 账户数据导出任务仓储接口
+type AccountExportRepository interface {
+	CreateExport(ctx context.Context, export *model.AccountExport) error
+	GetExport(ctx context.Context, userID, exportID int64) (*model.AccountExport, error)
+	ListExports(ctx context.Context, userID int64) ([]*model.AccountExport, error)
+	HasActiveExport(ctx context.Context, userID int64, since time.Time) (bool, error)
+	MarkProcessing(ctx context.Context, exportID int64) error
+	CompleteExport(ctx context.Context, exportID int64, fileName string, fileSize int64, recordCount int) error
+	FailExport(ctx context.Context, exportID int64, message string) error
+	FailStaleExports(ctx context.Context, before time.Time) (int64, error)
+	DeleteExpiredExports(ctx context.Context, now time.Time) (int64, error)
+}
+
+// accountExportRepository 账户数据导出任务仓储实现
+type accountExportRepository struct {
+	db *sql.DB
+}
+
+// NewAccountExportRepository 创建账户数据导出任务仓储实例
+func NewAccountExportRepository(db *sql.DB) AccountExportRepository {
+	return &accountExportRepository{
+		db: db,
+	}
+}
+
+// CreateExport 创建导出任务
+func (r *accountExportRepository) CreateExport(ctx context.Context, export *model.AccountExport) error {
+	query := `
+		INSERT INTO account_exports (user_id, format, status, record_count, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query,
+		export.UserID,
+		export.Format,
+		export.Status,
+		export.RecordCount,
+		now,
+		export.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create account export: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get account export id: %w", err)
+	}
+
+	export.ID = id
+	export.CreatedAt = now
+
+	return nil
+}
+
+// GetExport 获取用户的导出任务
+func (r *accountExportRepository) GetExport(ctx context.Context, userID, exportID int64) (*model.AccountExport, error) {
+	query := `SELECT ` + accountExportColumns + ` FROM account_exports WHERE id = ? AND user_id = ?`
+
+	export, err := scanAccountExport(r.db.QueryRowContext(ctx, query, exportID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAccountExportNotFound
+		}
+		return nil, fmt.Errorf("failed to get account export: %w", err)
+	}
+
+	return export, nil
+}
+
+// ListExports 获取用户未过期的导出任务，最新的在前
+func (r *accountExportRepository) ListExports(ctx context.Context, userID int64) ([]*model.AccountExport, error) {
+	query := `SELECT ` + accountExportColumns + ` FROM account_exports
+		WHERE user_id = ? AND expires_at > ?
+		ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list account exports: %w", err)
+	}
+	defer rows.Close()
+
+	exports := make([]*model.AccountExport, 0)
+	for rows.Next() {
+		export, err := scanAccountExport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan account export: %w", err)
+		}
+		exports = append(exports, export)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating account exports: %w", err)
+	}
+
+	return exports, nil
+}
+
+// HasActiveExport 检查用户在 since 之后是否创建了仍在生成的导出任务
+func (r *accountExportRepository) HasActiveExport(ctx context.Context, userID int64, since time.Time) (bool, error) {
+	query := `
+		SELECT COUNT(*) FROM account_exports
+		WHERE user_id = ? AND status IN (?, ?) AND created_at > ?
+	`
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query,
+		userID,
+		model.AccountExportStatusPending,
+		model.AccountExportStatusProcessing,
+		since,
+	).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check active account exports: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// MarkProcessing 将导出任务标记为正在生成
+func (r *accountExportRepository) MarkProcessing(ctx context.Context, exportID int64) error {
+	query := `UPDATE account_exports SET status = ? WHERE id = ?`
+
+	if _, err := r.db.ExecContext(ctx, query, model.AccountExportStatusProcessing, exportID); err != nil {
+		return fmt.Errorf("failed to update account export: %w", err)
+	}
+
+	return nil
+}
+
+// CompleteExport 将导出任务标记为已完成
+func (r *accountExportRepository) CompleteExport(ctx context.Context, exportID int64, fileName string, fileSize int64, recordCount int) error {
+	query := `
+		UPDATE account_exports
+		SET status = ?, file_name = ?, file_size = ?, record_count = ?, completed_at = ?
+		WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		model.AccountExportStatusCompleted,
+		fileName,
+		fileSize,
+		recordCount,
+		time.Now(),
+		exportID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete account export: %w", err)
+	}
+
+	return nil
+}
+
+// FailExport 将导出任务标记为失败
+func (r *accountExportRepository) FailExport(ctx context.Context, exportID int64, message string) error {
+	query := `UPDATE account_exports SET status = ?, error_message = ?, completed_at = ? WHERE id = ?`
+
+	if len(message) > 255 {
+		message = message[:255]
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, model.AccountExportStatusFailed, message, time.Now(), exportID); err != nil {
+		return fmt.Errorf("failed to update account export: %w", err)
+	}
+
+	return nil
+}
+
+// FailStaleExports 将 before 之前创建、仍未完成的导出任务标记为失败（如生成过程中服务重启）
+func (r *accountExportRepository) FailStaleExports(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		UPDATE account_exports
+		SET status = ?, error_message = ?, completed_at = ?
+		WHERE status IN (?, ?) AND created_at <= ?
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		model.AccountExportStatusFailed,
+		"export was interrupted",
+		time.Now(),
+		model.AccountExportStatusPending,
+		model.AccountExportStatusProcessing,
+		before,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail stale account exports: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// DeleteExpiredExports 删除已过期的导出任务
+func (r *accountExportRepository) DeleteExpiredExports(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM account_exports WHERE expires_at <= ?`, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired account exports: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// scanAccountExport 扫描一行导出任务记录
+func scanAccountExport(row rowScanner) (*model.AccountExport, error) {
+	export := &model.AccountExport{}
+	var fileName, errorMessage sql.NullString
+	var completedAt sql.NullTime
+
+	err := row.Scan(
+		&export.ID,
+		&export.UserID,
+		&export.Format,
+		&export.Status,
+		&fileName,
+		&export.FileSize,
+		&export.RecordCount,
+		&errorMessage,
+		&export.CreatedAt,
+		&completedAt,
+		&export.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	export.FileName = fileName.String
+	export.Error = errorMessage.String
+	if completedAt.Valid {
+		export.CompletedAt = &completedAt.Time
+	}
+
+	return export, nil
+}
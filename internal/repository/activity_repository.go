@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+)
+
+// ActivityRepository handles activity data access operations
+type ActivityRepository struct {
+	db *sql.DB
+}
+
+// NewActivityRepository creates a new ActivityRepository instance
+func NewActivityRepository(db *sql.DB) *ActivityRepository {
+	return &ActivityRepository{db: db}
+}
+
+const activityColumns = `id, user_id, activity_type, performed_on, started_at, duration_minutes, intensity,
+		       met, calories_burned, calories_estimated, notes, created_at, updated_at`
+
+// CreateActivity creates a new activity record
+func (r *ActivityRepository) CreateActivity(activity *model.Activity) error {
+	query := `
+		INSERT INTO activities (user_id, activity_type, performed_on, started_at, duration_minutes, intensity,
+		                        met, calories_burned, calories_estimated, notes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.Exec(
+		query,
+		activity.UserID,
+		activity.ActivityType,
+		dateArg(activity.PerformedOn),
+		activity.StartedAt,
+		activity.DurationMinutes,
+		activity.Intensity,
+		activity.MET,
+		activity.CaloriesBurned,
+		activity.CaloriesEstimated,
+		activity.Notes,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create activity: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	activity.ID = id
+	return nil
+}
+
+// UpdateActivity updates an existing activity record
+func (r *ActivityRepository) UpdateActivity(userID, activityID int64, activity *model.Activity) error {
+	query := `
+		UPDATE activities
+		SET activity_type = ?, performed_on = ?, started_at = ?, duration_minutes = ?, intensity = ?,
+		    met = ?, calories_burned = ?, calories_estimated = ?, notes = ?
+		WHERE id = ? AND user_id = ?
+	`
+
+	result, err := r.db.Exec(
+		query,
+		activity.ActivityType,
+		dateArg(activity.PerformedOn),
+		activity.StartedAt,
+		activity.DurationMinutes,
+		activity.Intensity,
+		activity.MET,
+		activity.CaloriesBurned,
+		activity.CaloriesEstimated,
+		activity.Notes,
+		activityID,
+		userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update activity: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("activity not found or access denied")
+	}
+
+	return nil
+}
+
+// DeleteActivity deletes an activity record
+func (r *ActivityRepository) DeleteActivity(userID, activityID int64) error {
+	query := `DELETE FROM activities WHERE id = ? AND user_id = ?`
+
+	result, err := r.db.Exec(query, activityID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete activity: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("activity not found or access denied")
+	}
+
+	return nil
+}
+
+// GetActivityByID retrieves an activity record by ID
+func (r *ActivityRepository) GetActivityByID(userID, activityID int64) (*model.Activity, error) {
+	query := `
+		SELECT ` + activityColumns + `
+		FROM activities
+		WHERE id = ? AND user_id = ?
+	`
+
+	activities, err := r.queryActivities(query, activityID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(activities) == 0 {
+		return nil, fmt.Errorf("activity not found or access denied")
+	}
+
+	return activities[0], nil
+}
+
+// GetActivitiesByDateRange retrieves activities within a date range (inclusive)
+func (r *ActivityRepository) GetActivitiesByDateRange(userID int64, startDate, endDate time.Time) ([]*model.Activity, error) {
+	query := `
+		SELECT ` + activityColumns + `
+		FROM activities
+		WHERE user_id = ? AND performed_on BETWEEN ? AND ?
+		ORDER BY performed_on ASC, started_at ASC, id ASC
+	`
+
+	return r.queryActivities(query, userID, dateArg(startDate), dateArg(endDate))
+}
+
+// GetDailyCaloriesBurned sums calories burned per day within a date range (inclusive),
+// keyed by YYYY-MM-DD
+func (r *ActivityRepository) GetDailyCaloriesBurned(userID int64, startDate, endDate time.Time) (map[string]float64, error) {
+	query := `
+		SELECT performed_on, SUM(calories_burned)
+		FROM activities
+		WHERE user_id = ? AND performed_on BETWEEN ? AND ?
+		GROUP BY performed_on
+	`
+
+	rows, err := r.db.Query(query, userID, dateArg(startDate), dateArg(endDate))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily calories burned: %w", err)
+	}
+	defer rows.Close()
+
+	burned := make(map[string]float64)
+	for rows.Next() {
+		var day time.Time
+		var calories float64
+		if err := rows.Scan(&day, &calories); err != nil {
+			return nil, fmt.Errorf("failed to scan daily calories burned: %w", err)
+		}
+		burned[day.Format("2006-01-02")] = calories
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating daily calories burned: %w", err)
+	}
+
+	return burned, nil
+}
+
+// queryActivities runs an activity query and scans the results
+func (r *ActivityRepository) queryActivities(query string, args ...interface{}) ([]*model.Activity, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query activities: %w", err)
+	}
+	defer rows.Close()
+
+	activities := make([]*model.Activity, 0)
+	for rows.Next() {
+		activity, err := scanActivity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan activity: %w", err)
+		}
+		activities = append(activities, activity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating activities: %w", err)
+	}
+
+	return activities, nil
+}
+
+// scanActivity scans a row selecting activityColumns
+func scanActivity(row rowScanner) (*model.Activity, error) {
+	activity := &model.Activity{}
+	var startedAt sql.NullTime
+	var met sql.NullFloat64
+	var notes sql.NullString
+
+	err := row.Scan(
+		&activity.ID,
+		&activity.UserID,
+		&activity.ActivityType,
+		&activity.PerformedOn,
+		&startedAt,
+		&activity.DurationMinutes,
+		&activity.Intensity,
+		&met,
+		&activity.CaloriesBurned,
+		&activity.CaloriesEstimated,
+		&notes,
+		&activity.CreatedAt,
+		&activity.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if startedAt.Valid {
+		activity.StartedAt = &startedAt.Time
+	}
+	activity.MET = nullFloatPtr(met)
+	activity.Notes = notes.String
+	return activity, nil
+}
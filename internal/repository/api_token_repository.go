@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+)
+
+var (
+	// ErrAPITokenNotFound 个人访问令牌不存在
+	ErrAPITokenNotFound = errors.New("api token not found")
+)
+
+// apiTokenColumns 个人访问令牌查询的列，与 scanAPIToken 的扫描顺序一致
+const apiTokenColumns = `id, user_id, name, token_hash, token_prefix, scopes,
+	expires_at, last_used_at, last_used_ip, created_at`
+
+// APITokenRepository 个人访问令牌仓储接口
+type APITokenRepository interface {
+	CreateToken(ctx context.Context, token *model.APIToken) error
+	GetTokenByHash(ctx context.Context, tokenHash string) (*model.APIToken, error)
+	ListTokens(ctx context.Context, userID int64) ([]*model.APIToken, error)
+	CountTokens(ctx context.Context, userID int64) (int, error)
+	DeleteToken(ctx context.Context, userID, tokenID int64) error
+	TouchToken(ctx context.Context, tokenID int64, ipAddress string, usedAt time.Time, minInterval time.Duration) error
+}
+
+// apiTokenRepository 个人访问令牌仓储实现
+type apiTokenRepository struct {
+	db *sql.DB
+}
+
+// NewAPITokenRepository 创建个人访问令牌仓储实例
+func NewAPITokenRepository(db *sql.DB) APITokenRepository {
+	return &apiTokenRepository{
+		db: db,
+	}
+}
+
+// CreateToken 创建个人访问令牌
+func (r *apiTokenRepository) CreateToken(ctx context.Context, token *model.APIToken) error {
+	query := `
+		INSERT INTO api_tokens (user_id, name, token_hash, token_prefix, scopes, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query,
+		token.UserID,
+		token.Name,
+		token.TokenHash,
+		token.TokenPrefix,
+		marshalStringList(token.Scopes),
+		token.ExpiresAt,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create api token: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get api token id: %w", err)
+	}
+
+	token.ID = id
+	token.CreatedAt = now
+
+	return nil
+}
+
+// GetTokenByHash 根据令牌哈希获取个人访问令牌
+func (r *apiTokenRepository) GetTokenByHash(ctx context.Context, tokenHash string) (*model.APIToken, error) {
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE token_hash = ?`
+
+	token, err := scanAPIToken(r.db.QueryRowContext(ctx, query, tokenHash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAPITokenNotFound
+		}
+		return nil, fmt.Errorf("failed to get api token: %w", err)
+	}
+
+	return token, nil
+}
+
+// ListTokens 获取用户的所有个人访问令牌，最新创建的在前
+func (r *apiTokenRepository) ListTokens(ctx context.Context, userID int64) ([]*model.APIToken, error) {
+	query := `
+		SELECT ` + apiTokenColumns + `
+		FROM api_tokens
+		WHERE user_id = ?
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := make([]*model.APIToken, 0)
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api token: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating api tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// CountTokens 统计用户的个人访问令牌数量
+func (r *apiTokenRepository) CountTokens(ctx context.Context, userID int64) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM api_tokens WHERE user_id = ?`
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count api tokens: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteToken 删除（撤销）用户的个人访问令牌
+func (r *apiTokenRepository) DeleteToken(ctx context.Context, userID, tokenID int64) error {
+	query := `DELETE FROM api_tokens WHERE id = ? AND user_id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, tokenID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete api token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrAPITokenNotFound
+	}
+
+	return nil
+}
+
+// TouchToken 记录令牌的最近使用时间和 IP
+// 距上次记录不足 minInterval 时不更新，避免每个请求都写数据库
+func (r *apiTokenRepository) TouchToken(ctx context.Context, tokenID int64, ipAddress string, usedAt time.Time, minInterval time.Duration) error {
+	query := `
+		UPDATE api_tokens
+		SET last_used_at = ?, last_used_ip = ?
+		WHERE id = ? AND (last_used_at IS NULL OR last_used_at < ?)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, usedAt, ipAddress, tokenID, usedAt.Add(-minInterval)); err != nil {
+		return fmt.Errorf("failed to update api token usage: %w", err)
+	}
+
+	return nil
+}
+
+// scanAPIToken 扫描一行个人访问令牌数据
+func scanAPIToken(row rowScanner) (*model.APIToken, error) {
+	token := &model.APIToken{}
+	var scopes []byte
+	var expiresAt, lastUsedAt sql.NullTime
+	var lastUsedIP sql.NullString
+
+	err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Name,
+		&token.TokenHash,
+		&token.TokenPrefix,
+		&scopes,
+		&expiresAt,
+		&lastUsedAt,
+		&lastUsedIP,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	token.Scopes = unmarshalStringList(scopes)
+	if expiresAt.Valid {
+		token.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		token.LastUsedAt = &lastUsedAt.Time
+	}
+	token.LastUsedIP = lastUsedIP.String
+
+	return token, nil
+}
@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+)
+
+// auditLogColumns 审计日志查询的列，与 scanAuditLog 的扫描顺序一致
+const auditLogColumns = `id, actor_user_id, actor_username, api_token_id, action, resource_type, resource_id,
+	outcome, ip_address, user_agent, changes, details, created_at`
+
+// AuditLogRepository 审计日志仓储接口
+type AuditLogRepository interface {
+	CreateAuditLog(ctx context.Context, log *model.AuditLog) error
+	ListAuditLogs(ctx context.Context, filter *model.AuditLogFilter) ([]*model.AuditLog, int, error)
+	IterateAuditLogs(ctx context.Context, filter *model.AuditLogFilter, limit int, fn func(*model.AuditLog) error) error
+	DeleteAuditLogsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// auditLogRepository 审计日志仓储实现
+type auditLogRepository struct {
+	db *sql.DB
+}
+
+// NewAuditLogRepository 创建审计日志仓储实例
+func NewAuditLogRepository(db *sql.DB) AuditLogRepository {
+	return &auditLogRepository{
+		db: db,
+	}
+}
+
+// CreateAuditLog 创建审计日志
+func (r *auditLogRepository) CreateAuditLog(ctx context.Context, log *model.AuditLog) error {
+	// 没有修改或上下文信息时写入 NULL
+	var changesJSON, detailsJSON sql.NullString
+	if len(log.Changes) > 0 {
+		data, err := json.Marshal(log.Changes)
+		if err != nil {
+			return fmt.Errorf("failed to marshal audit changes: %w", err)
+		}
+		changesJSON = sql.NullString{String: string(data), Valid: true}
+	}
+	if len(log.Details) > 0 {
+		data, err := json.Marshal(log.Details)
+		if err != nil {
+			return fmt.Errorf("failed to marshal audit details: %w", err)
+		}
+		detailsJSON = sql.NullString{String: string(data), Valid: true}
+	}
+
+	query := `
+		INSERT INTO audit_logs (actor_user_id, actor_username, api_token_id, action, resource_type, resource_id,
+			outcome, ip_address, user_agent, changes, details, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query,
+		log.ActorUserID,
+		log.ActorUsername,
+		log.APITokenID,
+		log.Action,
+		log.ResourceType,
+		log.ResourceID,
+		log.Outcome,
+		log.IPAddress,
+		log.UserAgent,
+		changesJSON,
+		detailsJSON,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get audit log id: %w", err)
+	}
+
+	log.ID = id
+	log.CreatedAt = now
+
+	return nil
+}
+
+// ListAuditLogs 获取审计日志列表（支持分页和筛选），最新的在前
+func (r *auditLogRepository) ListAuditLogs(ctx context.Context, filter *model.AuditLogFilter) ([]*model.AuditLog, int, error) {
+	whereClause, args := buildAuditLogWhere(filter)
+
+	// 查询总数
+	var total int
+	countQuery := "SELECT COUNT(*) FROM audit_logs " + whereClause
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit logs: %w", err)
+	}
+
+	logs := make([]*model.AuditLog, 0)
+	if total == 0 {
+		return logs, 0, nil
+	}
+
+	// 查询数据
+	query := `SELECT ` + auditLogColumns + ` FROM audit_logs ` + whereClause + ` ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`
+	args = append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		log, err := scanAuditLog(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan audit log: %w", err)
+		}
+		logs = append(logs, log)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating audit logs: %w", err)
+	}
+
+	return logs, total, nil
+}
+
+// IterateAuditLogs 按筛选条件逐条读取审计日志（最新的在前，最多 limit 条），用于导出
+// 逐条回调避免一次性加载所有记录，fn 返回错误时停止读取
+func (r *auditLogRepository) IterateAuditLogs(ctx context.Context, filter *model.AuditLogFilter, limit int, fn func(*model.AuditLog) error) error {
+	whereClause, args := buildAuditLogWhere(filter)
+
+	query := `SELECT ` + auditLogColumns + ` FROM audit_logs ` + whereClause + ` ORDER BY created_at DESC, id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		log, err := scanAuditLog(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan audit log: %w", err)
+		}
+		if err := fn(log); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating audit logs: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAuditLogsBefore 删除指定时间之前的审计日志
+func (r *auditLogRepository) DeleteAuditLogsBefore(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM audit_logs WHERE created_at < ?`
+
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old audit logs: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// buildAuditLogWhere 根据筛选条件构建 WHERE 子句和参数
+func buildAuditLogWhere(filter *model.AuditLogFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.ActorUserID != nil {
+		conditions = append(conditions, "actor_user_id = ?")
+		args = append(args, *filter.ActorUserID)
+	}
+
+	if filter.Action != "" {
+		if prefix, ok := strings.CutSuffix(filter.Action, ".*"); ok {
+			conditions = append(conditions, "action LIKE ?")
+			args = append(args, escapeLike(prefix)+".%")
+		} else {
+			conditions = append(conditions, "action = ?")
+			args = append(args, filter.Action)
+		}
+	}
+
+	if filter.ResourceType != "" {
+		conditions = append(conditions, "resource_type = ?")
+		args = append(args, filter.ResourceType)
+	}
+
+	if filter.ResourceID != "" {
+		conditions = append(conditions, "resource_id = ?")
+		args = append(args, filter.ResourceID)
+	}
+
+	if filter.Outcome != "" {
+		conditions = append(conditions, "outcome = ?")
+		args = append(args, filter.Outcome)
+	}
+
+	if filter.StartDate != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, *filter.StartDate)
+	}
+
+	if filter.EndDate != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, *filter.EndDate)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// scanAuditLog 扫描一行审计日志数据
+func scanAuditLog(row rowScanner) (*model.AuditLog, error) {
+	log := &model.AuditLog{}
+	var actorUserID, apiTokenID sql.NullInt64
+	var changesJSON, detailsJSON []byte
+	err := row.Scan(
+		&log.ID,
+		&actorUserID,
+		&log.ActorUsername,
+		&apiTokenID,
+		&log.Action,
+		&log.ResourceType,
+		&log.ResourceID,
+		&log.Outcome,
+		&log.IPAddress,
+		&log.UserAgent,
+		&changesJSON,
+		&detailsJSON,
+		&log.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if actorUserID.Valid {
+		log.ActorUserID = &actorUserID.Int64
+	}
+	if apiTokenID.Valid {
+		log.APITokenID = &apiTokenID.Int64
+	}
+	if len(changesJSON) > 0 {
+		if err := json.Unmarshal(changesJSON, &log.Changes); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal audit changes: %w", err)
+		}
+	}
+	if len(detailsJSON) > 0 {
+		if err := json.Unmarshal(detailsJSON, &log.Details); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal audit details: %w", err)
+		}
+	}
+
+	return log, nil
+}
@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+)
+
+// BodyMetricRepository handles body metric data access operations
+type BodyMetricRepository struct {
+	db *sql.DB
+}
+
+// NewBodyMetricRepository creates a new BodyMetricRepository instance
+func NewBodyMetricRepository(db *sql.DB) *BodyMetricRepository {
+	return &BodyMetricRepository{db: db}
+}
+
+const bodyMetricColumns = `id, user_id, measured_on, weight_kg, body_fat_pct, waist_cm, hip_cm,
+		       chest_cm, neck_cm, notes, created_at, updated_at`
+
+// CreateBodyMetric creates a new body metric entry
+func (r *BodyMetricRepository) CreateBodyMetric(metric *model.BodyMetric) error {
+	query := `
+		INSERT INTO body_metrics (user_id, measured_on, weight_kg, body_fat_pct, waist_cm, hip_cm, chest_cm, neck_cm, notes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.Exec(
+		query,
+		metric.UserID,
+		dateArg(metric.MeasuredOn),
+		metric.WeightKg,
+		metric.BodyFatPct,
+		metric.WaistCm,
+		metric.HipCm,
+		metric.ChestCm,
+		metric.NeckCm,
+		metric.Notes,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create body metric: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	metric.ID = id
+	return nil
+}
+
+// UpdateBodyMetric updates an existing body metric entry
+func (r *BodyMetricRepository) UpdateBodyMetric(userID, metricID int64, metric *model.BodyMetric) error {
+	query := `
+		UPDATE body_metrics
+		SET measured_on = ?, weight_kg = ?, body_fat_pct = ?, waist_cm = ?, hip_cm = ?,
+		    chest_cm = ?, neck_cm = ?, notes = ?
+		WHERE id = ? AND user_id = ?
+	`
+
+	result, err := r.db.Exec(
+		query,
+		dateArg(metric.MeasuredOn),
+		metric.WeightKg,
+		metric.BodyFatPct,
+		metric.WaistCm,
+		metric.HipCm,
+		metric.ChestCm,
+		metric.NeckCm,
+		metric.Notes,
+		metricID,
+		userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update body metric: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("body metric not found or access denied")
+	}
+
+	return nil
+}
+
+// DeleteBodyMetric deletes a body metric entry
+func (r *BodyMetricRepository) DeleteBodyMetric(userID, metricID int64) error {
+	query := `DELETE FROM body_metrics WHERE id = ? AND user_id = ?`
+
+	result, err := r.db.Exec(query, metricID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete body metric: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("body metric not found or access denied")
+	}
+
+	return nil
+}
+
+// GetBodyMetricByID retrieves a body metric entry by ID
+func (r *BodyMetricRepository) GetBodyMetricByID(userID, metricID int64) (*model.BodyMetric, error) {
+	query := `
+		SELECT ` + bodyMetricColumns + `
+		FROM body_metrics
+		WHERE id = ? AND user_id = ?
+	`
+
+	metrics, err := r.queryBodyMetrics(query, metricID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(metrics) == 0 {
+		return nil, fmt.Errorf("body metric not found")
+	}
+
+	return metrics[0], nil
+}
+
+// GetBodyMetricByDate retrieves the entry for a date. Returns nil, nil if there is none.
+func (r *BodyMetricRepository) GetBodyMetricByDate(userID int64, date time.Time) (*model.BodyMetric, error) {
+	query := `
+		SELECT ` + bodyMetricColumns + `
+		FROM body_metrics
+		WHERE user_id = ? AND measured_on = ?
+	`
+
+	metrics, err := r.queryBodyMetrics(query, userID, dateArg(date))
+	if err != nil {
+		return nil, err
+	}
+	if len(metrics) == 0 {
+		return nil, nil
+	}
+
+	return metrics[0], nil
+}
+
+// GetBodyMetricsByDateRange retrieves entries between startDate and endDate (inclusive), oldest first
+func (r *BodyMetricRepository) GetBodyMetricsByDateRange(userID int64, startDate, endDate time.Time) ([]*model.BodyMetric, error) {
+	query := `
+		SELECT ` + bodyMetricColumns + `
+		FROM body_metrics
+		WHERE user_id = ? AND measured_on >= ? AND measured_on <= ?
+		ORDER BY measured_on ASC
+	`
+
+	return r.queryBodyMetrics(query, userID, dateArg(startDate), dateArg(endDate))
+}
+
+// GetLatestBodyMetric retrieves the most recent entry. Returns nil, nil if there is none.
+func (r *BodyMetricRepository) GetLatestBodyMetric(userID int64) (*model.BodyMetric, error) {
+	query := `
+		SELECT ` + bodyMetricColumns + `
+		FROM body_metrics
+		WHERE user_id = ?
+		ORDER BY measured_on DESC
+		LIMIT 1
+	`
+
+	metrics, err := r.queryBodyMetrics(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(metrics) == 0 {
+		return nil, nil
+	}
+
+	return metrics[0], nil
+}
+
+// GetLatestWeight retrieves the most recent entry with a weight. Returns nil, nil if there is none.
+func (r *BodyMetricRepository) GetLatestWeight(userID int64) (*model.BodyMetric, error) {
+	query := `
+		SELECT ` + bodyMetricColumns + `
+		FROM body_metrics
+		WHERE user_id = ? AND weight_kg IS NOT NULL
+		ORDER BY measured_on DESC
+		LIMIT 1
+	`
+
+	metrics, err := r.queryBodyMetrics(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(metrics) == 0 {
+		return nil, nil
+	}
+
+	return metrics[0], nil
+}
+
+// GetWeightOnOrBefore retrieves the most recent entry with a weight measured on or
+// before the given date. Returns nil, nil if there is none.
+func (r *BodyMetricRepository) GetWeightOnOrBefore(userID int64, date time.Time) (*model.BodyMetric, error) {
+	query := `
+		SELECT ` + bodyMetricColumns + `
+		FROM body_metrics
+		WHERE user_id = ? AND weight_kg IS NOT NULL AND measured_on <= ?
+		ORDER BY measured_on DESC
+		LIMIT 1
+	`
+
+	metrics, err := r.queryBodyMetrics(query, userID, dateArg(date))
+	if err != nil {
+		return nil, err
+	}
+	if len(metrics) == 0 {
+		return nil, nil
+	}
+
+	return metrics[0], nil
+}
+
+// GetLatestBodyFat retrieves the most recent entry with a body fat percentage. Returns nil, nil if there is none.
+func (r *BodyMetricRepository) GetLatestBodyFat(userID int64) (*model.BodyMetric, error) {
+	query := `
+		SELECT ` + bodyMetricColumns + `
+		FROM body_metrics
+		WHERE user_id = ? AND body_fat_pct IS NOT NULL
+		ORDER BY measured_on DESC
+		LIMIT 1
+	`
+
+	metrics, err := r.queryBodyMetrics(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(metrics) == 0 {
+		return nil, nil
+	}
+
+	return metrics[0], nil
+}
+
+// queryBodyMetrics runs a body metric query and scans the resulting rows
+func (r *BodyMetricRepository) queryBodyMetrics(query string, args ...interface{}) ([]*model.BodyMetric, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query body metrics: %w", err)
+	}
+	defer rows.Close()
+
+	metrics := make([]*model.BodyMetric, 0)
+	for rows.Next() {
+		metric, err := scanBodyMetric(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan body metric: %w", err)
+		}
+		metrics = append(metrics, metric)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating body metrics: %w", err)
+	}
+
+	return metrics, nil
+}
+
+// scanBodyMetric scans a row selecting bodyMetricColumns
+func scanBodyMetric(row rowScanner) (*model.BodyMetric, error) {
+	metric := &model.BodyMetric{}
+	var weight, bodyFat, waist, hip, chest, neck sql.NullFloat64
+	var notes sql.NullString
+
+	err := row.Scan(
+		&metric.ID,
+		&metric.UserID,
+		&metric.MeasuredOn,
+		&weight,
+		&bodyFat,
+		&waist,
+		&hip,
+		&chest,
+		&neck,
+		&notes,
+		&metric.CreatedAt,
+		&metric.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	metric.WeightKg = nullFloatPtr(weight)
+	metric.BodyFatPct = nullFloatPtr(bodyFat)
+	metric.WaistCm = nullFloatPtr(waist)
+	metric.HipCm = nullFloatPtr(hip)
+	metric.ChestCm = nullFloatPtr(chest)
+	metric.NeckCm = nullFloatPtr(neck)
+	metric.Notes = notes.String
+	return metric, nil
+}
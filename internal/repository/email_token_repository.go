@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+)
+
+var (
+	// ErrEmailTokenNotFound 邮件令牌不存在
+	ErrEmailTokenNotFound = errors.New("email token not found")
+)
+
+// emailTokenColumns 邮件令牌查询的列，与 scanEmailToken 的扫描顺序一致
+const emailTokenColumns = `id, user_id, purpose, token_hash, email, expires_at, used_at, created_at`
+
+// EmailTokenRepository 邮件令牌仓储接口
+type EmailTokenRepository interface {
+	CreateToken(ctx context.Context, token *model.EmailToken) error
+	GetTokenByHash(ctx context.Context, tokenHash, purpose string) (*model.EmailToken, error)
+	GetLatestTokenTime(ctx context.Context, userID int64, purpose string) (*time.Time, error)
+	MarkTokenUsed(ctx context.Context, tokenID int64) (bool, error)
+}
+
+// emailTokenRepository 邮件令牌仓储实现
+type emailTokenRepository struct {
+	db *sql.DB
+}
+
+// NewEmailTokenRepository 创建邮件令牌仓储实例
+func NewEmailTokenRepository(db *sql.DB) EmailTokenRepository {
+	return &emailTokenRepository{
+		db: db,
+	}
+}
+
+// CreateToken 创建邮件令牌，同时删除该用户同一用途的旧令牌，保证只有最新发出的链接有效
+func (r *emailTokenRepository) CreateToken(ctx context.Context, token *model.EmailToken) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM email_tokens WHERE user_id = ? AND purpose = ?`,
+		token.UserID, token.Purpose,
+	); err != nil {
+		return fmt.Errorf("failed to delete old email tokens: %w", err)
+	}
+
+	query := `
+		INSERT INTO email_tokens (user_id, purpose, token_hash, email, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, query,
+		token.UserID,
+		token.Purpose,
+		token.TokenHash,
+		token.Email,
+		token.ExpiresAt,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create email token: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get email token id: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	token.ID = id
+	token.CreatedAt = now
+
+	return nil
+}
+
+// GetTokenByHash 根据令牌哈希和用途获取邮件令牌
+func (r *emailTokenRepository) GetTokenByHash(ctx context.Context, tokenHash, purpose string) (*model.EmailToken, error) {
+	query := `SELECT ` + emailTokenColumns + ` FROM email_tokens WHERE token_hash = ? AND purpose = ?`
+
+	token, err := scanEmailToken(r.db.QueryRowContext(ctx, query, tokenHash, purpose))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrEmailTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to get email token: %w", err)
+	}
+
+	return token, nil
+}
+
+// GetLatestTokenTime 获取用户最近一次发出指定用途令牌的时间，没有时返回 nil
+func (r *emailTokenRepository) GetLatestTokenTime(ctx context.Context, userID int64, purpose string) (*time.Time, error) {
+	query := `SELECT MAX(created_at) FROM email_tokens WHERE user_id = ? AND purpose = ?`
+
+	var latest sql.NullTime
+	if err := r.db.QueryRowContext(ctx, query, userID, purpose).Scan(&latest); err != nil {
+		return nil, fmt.Errorf("failed to get latest email token time: %w", err)
+	}
+
+	if !latest.Valid {
+		return nil, nil
+	}
+	return &latest.Time, nil
+}
+
+// MarkTokenUsed 标记令牌已使用
+// 只有令牌尚未使用时才会更新，返回 false 表示令牌已被使用（并发提交时只有一个请求成功）
+func (r *emailTokenRepository) MarkTokenUsed(ctx context.Context, tokenID int64) (bool, error) {
+	query := `UPDATE email_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), tokenID)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark email token used: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// scanEmailToken 扫描一行邮件令牌数据
+func scanEmailToken(row rowScanner) (*model.EmailToken, error) {
+	token := &model.EmailToken{}
+	var usedAt sql.NullTime
+
+	err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Purpose,
+		&token.TokenHash,
+		&token.Email,
+		&token.ExpiresAt,
+		&usedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+
+	return token, nil
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+)
+
+// FastingRepository handles fast data access operations
+type FastingRepository struct {
+	db *sql.DB
+}
+
+// NewFastingRepository creates a new FastingRepository instance
+func NewFastingRepository(db *sql.DB) *FastingRepository {
+	return &FastingRepository{db: db}
+}
+
+const fastColumns = `id, user_id, protocol, target_hours, started_at, ended_at, status,
+		       broken_by_meal_id, notes, created_at, updated_at`
+
+// CreateFast creates a new fast record
+func (r *FastingRepository) CreateFast(fast *model.Fast) error {
+	query := `
+		INSERT INTO fasts (user_id, protocol, target_hours, started_at, status, notes)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.Exec(
+		query,
+		fast.UserID,
+		fast.Protocol,
+		fast.TargetHours,
+		fast.StartedAt,
+		fast.Status,
+		fast.Notes,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create fast: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	fast.ID = id
+	return nil
+}
+
+// EndFast ends an active fast with the given status. mealID is the meal that
+// broke the fast, if any.
+func (r *FastingRepository) EndFast(userID, fastID int64, endedAt time.Time, status string, mealID *int64) error {
+	query := `
+		UPDATE fasts
+		SET ended_at = ?, status = ?, broken_by_meal_id = ?
+		WHERE id = ? AND user_id = ? AND status = 'active'
+	`
+
+	result, err := r.db.Exec(query, endedAt, status, mealID, fastID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to end fast: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("active fast not found or access denied")
+	}
+
+	return nil
+}
+
+// DeleteFast deletes a fast record
+func (r *FastingRepository) DeleteFast(userID, fastID int64) error {
+	query := `DELETE FROM fasts WHERE id = ? AND user_id = ?`
+
+	result, err := r.db.Exec(query, fastID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete fast: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("fast not found or access denied")
+	}
+
+	return nil
+}
+
+// GetActiveFast retrieves the user's active fast. Returns nil, nil if there is none.
+func (r *FastingRepository) GetActiveFast(userID int64) (*model.Fast, error) {
+	query := `
+		SELECT ` + fastColumns + `
+		FROM fasts
+		WHERE user_id = ? AND status = 'active'
+		ORDER BY started_at DESC
+		LIMIT 1
+	`
+
+	fasts, err := r.queryFasts(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(fasts) == 0 {
+		return nil, nil
+	}
+
+	return fasts[0], nil
+}
+
+// ListFasts retrieves a page of the user's fasts, newest first
+func (r *FastingRepository) ListFasts(userID int64, page, pageSize int) ([]*model.Fast, int, error) {
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM fasts WHERE user_id = ?`, userID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count fasts: %w", err)
+	}
+
+	query := `
+		SELECT ` + fastColumns + `
+		FROM fasts
+		WHERE user_id = ?
+		ORDER BY started_at DESC
+		LIMIT ? OFFSET ?
+	`
+
+	fasts, err := r.queryFasts(query, userID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return fasts, total, nil
+}
+
+// GetEndedFasts retrieves all of the user's ended fasts, oldest first
+func (r *FastingRepository) GetEndedFasts(userID int64) ([]*model.Fast, error) {
+	query := `
+		SELECT ` + fastColumns + `
+		FROM fasts
+		WHERE user_id = ? AND status <> 'active'
+		ORDER BY started_at ASC
+	`
+
+	return r.queryFasts(query, userID)
+}
+
+// queryFasts runs a fast query and scans the resulting rows
+func (r *FastingRepository) queryFasts(query string, args ...interface{}) ([]*model.Fast, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fasts: %w", err)
+	}
+	defer rows.Close()
+
+	fasts := make([]*model.Fast, 0)
+	for rows.Next() {
+		fast, err := scanFast(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan fast: %w", err)
+		}
+		fasts = append(fasts, fast)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating fasts: %w", err)
+	}
+
+	return fasts, nil
+}
+
+// scanFast scans a row selecting fastColumns
+func scanFast(row rowScanner) (*model.Fast, error) {
+	fast := &model.Fast{}
+	var endedAt sql.NullTime
+	var mealID sql.NullInt64
+	var notes sql.NullString
+
+	err := row.Scan(
+		&fast.ID,
+		&fast.UserID,
+		&fast.Protocol,
+		&fast.TargetHours,
+		&fast.StartedAt,
+		&endedAt,
+		&fast.Status,
+		&mealID,
+		&notes,
+		&fast.CreatedAt,
+		&fast.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if endedAt.Valid {
+		fast.EndedAt = &endedAt.Time
+	}
+	if mealID.Valid {
+		fast.BrokenByMealID = &mealID.Int64
+	}
+	fast.Notes = notes.String
+	return fast, nil
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+)
+
+// FoodCategoryRepository handles food category data access operations
+type FoodCategoryRepository struct {
+	db *sql.DB
+}
+
+// NewFoodCategoryRepository creates a new FoodCategoryRepository instance
+func NewFoodCategoryRepository(db *sql.DB) *FoodCategoryRepository {
+	return &FoodCategoryRepository{db: db}
+}
+
+const foodCategoryColumns = `id, user_id, parent_id, slug, name, sort_order, created_at, updated_at`
+
+// ListCategories retrieves the categories visible to a user: the built-in categories
+// and the user's own
+func (r *FoodCategoryRepository) ListCategories(userID int64) (model.FoodCategories, error) {
+	query := `SELECT ` + foodCategoryColumns + `
+		FROM food_categories
+		WHERE user_id IS NULL OR user_id = ?
+		ORDER BY sort_order ASC, id ASC`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list food categories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := make(model.FoodCategories, 0)
+	for rows.Next() {
+		category := &model.FoodCategory{}
+		var ownerID, parentID sql.NullInt64
+		err := rows.Scan(
+			&category.ID,
+			&ownerID,
+			&parentID,
+			&category.Slug,
+			&category.Name,
+			&category.SortOrder,
+			&category.CreatedAt,
+			&category.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan food category: %w", err)
+		}
+		if ownerID.Valid {
+			category.UserID = &ownerID.Int64
+		}
+		if parentID.Valid {
+			category.ParentID = &parentID.Int64
+		}
+		category.BuiltIn = category.UserID == nil
+		categories = append(categories, category)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating food categories: %w", err)
+	}
+
+	return categories, nil
+}
+
+// CreateCategory creates a custom category for a user
+func (r *FoodCategoryRepository) CreateCategory(category *model.FoodCategory) error {
+	query := `
+		INSERT INTO food_categories (user_id, parent_id, slug, name, sort_order)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.Exec(query, category.UserID, category.ParentID, category.Slug, category.Name, category.SortOrder)
+	if err != nil {
+		return fmt.Errorf("failed to create food category: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	category.ID = id
+	return nil
+}
+
+// UpdateCategory updates the name, parent and sort order of a user's own category
+func (r *FoodCategoryRepository) UpdateCategory(userID, categoryID int64, category *model.FoodCategory) error {
+	query := `
+		UPDATE food_categories
+		SET parent_id = ?, name = ?, sort_order = ?
+		WHERE id = ? AND user_id = ?
+	`
+
+	result, err := r.db.Exec(query, category.ParentID, category.Name, category.SortOrder, categoryID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update food category: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("food category not found or access denied")
+	}
+
+	return nil
+}
+
+// DeleteCategory deletes a user's own category and moves the user's foods in it to
+// the replacement category
+func (r *FoodCategoryRepository) DeleteCategory(userID, categoryID int64, slug, replacement string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE foods SET category = ? WHERE user_id = ? AND category = ?`, replacement, userID, slug); err != nil {
+		return fmt.Errorf("failed to move foods out of category: %w", err)
+	}
+
+	result, err := tx.Exec(`DELETE FROM food_categories WHERE id = ? AND user_id = ?`, categoryID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete food category: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("food category not found or access denied")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+)
+
+// openTestDB connects to the database in TEST_MYSQL_DSN, which must have all
+// migrations applied. Tests that need it are skipped when it is not set.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("TEST_MYSQL_DSN not set")
+	}
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func TestDeleteUserWithNestedCategories(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewFoodCategoryRepository(db)
+
+	username := fmt.Sprintf("category_test_%d", time.Now().UnixNano())
+	result, err := db.Exec(`INSERT INTO users (username, password_hash) VALUES (?, ?)`, username, "x")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	userID, err := result.LastInsertId()
+	if err != nil {
+		t.Fatalf("failed to get user id: %v", err)
+	}
+	t.Cleanup(func() { db.Exec(`DELETE FROM users WHERE id = ?`, userID) })
+
+	parent := &model.FoodCategory{UserID: &userID, Slug: "test_parent", Name: "Parent"}
+	if err := repo.CreateCategory(parent); err != nil {
+		t.Fatalf("failed to create parent category: %v", err)
+	}
+	child := &model.FoodCategory{UserID: &userID, ParentID: &parent.ID, Slug: "test_child", Name: "Child"}
+	if err := repo.CreateCategory(child); err != nil {
+		t.Fatalf("failed to create child category: %v", err)
+	}
+
+	if _, err := db.Exec(`DELETE FROM users WHERE id = ?`, userID); err != nil {
+		t.Fatalf("failed to delete user with nested categories: %v", err)
+	}
+
+	var remaining int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM food_categories WHERE id IN (?, ?)`, parent.ID, child.ID).Scan(&remaining); err != nil {
+		t.Fatalf("failed to count categories: %v", err)
+	}
+	if remaining != 0 {
+		t.Errorf("%d categories left after deleting the user, want 0", remaining)
+	}
+}
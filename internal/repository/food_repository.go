@@ -8,6 +8,10 @@ import (
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
 )
 
+// foodColumns lists the columns selected for a food, in the order scanFood expects
+const foodColumns = `id, user_id, name, aliases, category, tags, price, unit, protein, carbs, fat, fiber,
+	calories, water_pct, allergens, diet_tags, available, favorite, created_at, updated_at`
+
 // FoodRepository handles food data access operations
 type FoodRepository struct {
 	db *sql.DB
@@ -21,15 +25,18 @@ func NewFoodRepository(db *sql.DB) *FoodRepository {
 // CreateFood creates a new food item for a user
 func (r *FoodRepository) CreateFood(food *model.Food) error {
 	query := `
-		INSERT INTO foods (user_id, name, category, price, unit, protein, carbs, fat, fiber, calories, available)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO foods (user_id, name, aliases, category, tags, price, unit, protein, carbs, fat, fiber, calories,
+		                   water_pct, allergens, diet_tags, available)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := r.db.Exec(
 		query,
 		food.UserID,
 		food.Name,
+		marshalStringList(food.Aliases),
 		food.Category,
+		marshalStringList(food.Tags),
 		food.Price,
 		food.Unit,
 		food.Protein,
@@ -37,6 +44,9 @@ func (r *FoodRepository) CreateFood(food *model.Food) error {
 		food.Fat,
 		food.Fiber,
 		food.Calories,
+		food.WaterPct,
+		marshalStringList(food.Allergens),
+		marshalStringList(food.DietTags),
 		food.Available,
 	)
 	if err != nil {
@@ -56,15 +66,17 @@ func (r *FoodRepository) CreateFood(food *model.Food) error {
 func (r *FoodRepository) UpdateFood(userID, foodID int64, food *model.Food) error {
 	query := `
 		UPDATE foods 
-		SET name = ?, category = ?, price = ?, unit = ?, protein = ?, carbs = ?, 
-		    fat = ?, fiber = ?, calories = ?, available = ?
+		SET name = ?, aliases = ?, category = ?, tags = ?, price = ?, unit = ?, protein = ?, carbs = ?, 
+		    fat = ?, fiber = ?, calories = ?, water_pct = ?, allergens = ?, diet_tags = ?, available = ?
 		WHERE id = ? AND user_id = ?
 	`
 
 	result, err := r.db.Exec(
 		query,
 		food.Name,
+		marshalStringList(food.Aliases),
 		food.Category,
+		marshalStringList(food.Tags),
 		food.Price,
 		food.Unit,
 		food.Protein,
@@ -72,6 +84,9 @@ func (r *FoodRepository) UpdateFood(userID, foodID int64, food *model.Food) erro
 		food.Fat,
 		food.Fiber,
 		food.Calories,
+		food.WaterPct,
+		marshalStringList(food.Allergens),
+		marshalStringList(food.DietTags),
 		food.Available,
 		foodID,
 		userID,
@@ -115,30 +130,9 @@ func (r *FoodRepository) DeleteFood(userID, foodID int64) error {
 
 // GetFoodByID retrieves a food item by ID (with ownership verification)
 func (r *FoodRepository) GetFoodByID(userID, foodID int64) (*model.Food, error) {
-	query := `
-		SELECT id, user_id, name, category, price, unit, protein, carbs, fat, fiber, 
-		       calories, available, created_at, updated_at
-		FROM foods
-		WHERE id = ? AND user_id = ?
-	`
+	query := `SELECT ` + foodColumns + ` FROM foods WHERE id = ? AND user_id = ?`
 
-	food := &model.Food{}
-	err := r.db.QueryRow(query, foodID, userID).Scan(
-		&food.ID,
-		&food.UserID,
-		&food.Name,
-		&food.Category,
-		&food.Price,
-		&food.Unit,
-		&food.Protein,
-		&food.Carbs,
-		&food.Fat,
-		&food.Fiber,
-		&food.Calories,
-		&food.Available,
-		&food.CreatedAt,
-		&food.UpdatedAt,
-	)
+	food, err := scanFood(r.db.QueryRow(query, foodID, userID))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("food not found")
 	}
@@ -149,23 +143,40 @@ func (r *FoodRepository) GetFoodByID(userID, foodID int64) (*model.Food, error)
 	return food, nil
 }
 
-// ListFoods retrieves a list of foods with filtering and pagination
-func (r *FoodRepository) ListFoods(userID int64, filter *model.FoodFilter) ([]*model.Food, int, error) {
-	// Build the WHERE clause
-	whereClauses := []string{"user_id = ?"}
-	args := []interface{}{userID}
+// GetFoodsByIDs retrieves the user's foods with the given IDs, keyed by ID. IDs that
+// do not exist or belong to another user are left out.
+func (r *FoodRepository) GetFoodsByIDs(userID int64, foodIDs []int64) (map[int64]*model.Food, error) {
+	foods := make(map[int64]*model.Food, len(foodIDs))
+	if len(foodIDs) == 0 {
+		return foods, nil
+	}
 
-	if filter.Category != "" {
-		whereClauses = append(whereClauses, "category = ?")
-		args = append(args, filter.Category)
+	placeholders := make([]string, len(foodIDs))
+	args := make([]interface{}, 0, len(foodIDs)+1)
+	args = append(args, userID)
+	for i, id := range foodIDs {
+		placeholders[i] = "?"
+		args = append(args, id)
 	}
 
-	if filter.Available != nil {
-		whereClauses = append(whereClauses, "available = ?")
-		args = append(args, *filter.Available)
+	query := fmt.Sprintf(`SELECT %s FROM foods WHERE user_id = ? AND id IN (%s)`,
+		foodColumns, strings.Join(placeholders, ", "))
+
+	list, err := r.queryFoods(query, args...)
+	if err != nil {
+		return nil, err
 	}
 
-	whereClause := strings.Join(whereClauses, " AND ")
+	for _, food := range list {
+		foods[food.ID] = food
+	}
+
+	return foods, nil
+}
+
+// ListFoods retrieves a list of foods with filtering and pagination
+func (r *FoodRepository) ListFoods(userID int64, filter *model.FoodFilter) ([]*model.Food, int, error) {
+	whereClause, args := foodFilterClause(userID, filter)
 
 	// Get total count
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM foods WHERE %s", whereClause)
@@ -177,53 +188,136 @@ func (r *FoodRepository) ListFoods(userID int64, filter *model.FoodFilter) ([]*m
 
 	// Get paginated results
 	query := fmt.Sprintf(`
-		SELECT id, user_id, name, category, price, unit, protein, carbs, fat, fiber, 
-		       calories, available, created_at, updated_at
+		SELECT %s
 		FROM foods
 		WHERE %s
 		ORDER BY created_at DESC
 		LIMIT ? OFFSET ?
-	`, whereClause)
+	`, foodColumns, whereClause)
 
 	offset := (filter.Page - 1) * filter.PageSize
 	args = append(args, filter.PageSize, offset)
 
-	rows, err := r.db.Query(query, args...)
+	foods, err := r.queryFoods(query, args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list foods: %w", err)
+		return nil, 0, err
+	}
+
+	return foods, total, nil
+}
+
+// SetFavorite pins or unpins a food for the user
+func (r *FoodRepository) SetFavorite(userID, foodID int64, favorite bool) error {
+	query := `UPDATE foods SET favorite = ? WHERE id = ? AND user_id = ?`
+
+	if _, err := r.db.Exec(query, favorite, foodID, userID); err != nil {
+		return fmt.Errorf("failed to set favorite: %w", err)
+	}
+
+	return nil
+}
+
+// ListAllFoods retrieves all of the user's foods matching the filter, ignoring
+// pagination, for ranking in memory
+func (r *FoodRepository) ListAllFoods(userID int64, filter *model.FoodFilter) ([]*model.Food, error) {
+	whereClause, args := foodFilterClause(userID, filter)
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM foods
+		WHERE %s
+		ORDER BY id ASC
+	`, foodColumns, whereClause)
+
+	return r.queryFoods(query, args...)
+}
+
+// foodFilterClause builds the WHERE clause and arguments selecting the user's
+// foods matching the filter
+func foodFilterClause(userID int64, filter *model.FoodFilter) (string, []interface{}) {
+	// Build the WHERE clause
+	whereClauses := []string{"user_id = ?"}
+	args := []interface{}{userID}
+
+	if len(filter.Categories) > 0 {
+		placeholders := make([]string, len(filter.Categories))
+		for i, category := range filter.Categories {
+			placeholders[i] = "?"
+			args = append(args, category)
+		}
+		whereClauses = append(whereClauses, fmt.Sprintf("category IN (%s)", strings.Join(placeholders, ", ")))
+	} else if filter.Category != "" {
+		whereClauses = append(whereClauses, "category = ?")
+		args = append(args, filter.Category)
+	}
+
+	if len(filter.Tags) > 0 {
+		conditions := make([]string, len(filter.Tags))
+		for i, tag := range filter.Tags {
+			conditions[i] = "JSON_CONTAINS(COALESCE(tags, JSON_ARRAY()), JSON_QUOTE(?))"
+			args = append(args, tag)
+		}
+		operator := " AND "
+		if filter.AnyTag {
+			operator = " OR "
+		}
+		whereClauses = append(whereClauses, "("+strings.Join(conditions, operator)+")")
+	}
+
+	if filter.Available != nil {
+		whereClauses = append(whereClauses, "available = ?")
+		args = append(args, *filter.Available)
+	}
+
+	if filter.Favorite != nil {
+		whereClauses = append(whereClauses, "favorite = ?")
+		args = append(args, *filter.Favorite)
+	}
+
+	if filter.Restrictions != nil {
+		clause, clauseArgs := restrictionClause(*filter.Restrictions)
+		if !filter.Compatible {
+			clause = "NOT " + clause
+		}
+		whereClauses = append(whereClauses, clause)
+		args = append(args, clauseArgs...)
+	}
+
+	return strings.Join(whereClauses, " AND "), args
+}
+
+// ListTags retrieves the tags used on the user's foods with the number of foods
+// carrying each, most used first
+func (r *FoodRepository) ListTags(userID int64) ([]*model.FoodTag, error) {
+	query := `
+		SELECT t.tag, COUNT(*) AS food_count
+		FROM foods f,
+		     JSON_TABLE(COALESCE(f.tags, JSON_ARRAY()), '$[*]' COLUMNS (tag VARCHAR(30) PATH '$')) t
+		WHERE f.user_id = ?
+		GROUP BY t.tag
+		ORDER BY food_count DESC, t.tag ASC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list food tags: %w", err)
 	}
 	defer rows.Close()
 
-	foods := make([]*model.Food, 0)
+	tags := make([]*model.FoodTag, 0)
 	for rows.Next() {
-		food := &model.Food{}
-		err := rows.Scan(
-			&food.ID,
-			&food.UserID,
-			&food.Name,
-			&food.Category,
-			&food.Price,
-			&food.Unit,
-			&food.Protein,
-			&food.Carbs,
-			&food.Fat,
-			&food.Fiber,
-			&food.Calories,
-			&food.Available,
-			&food.CreatedAt,
-			&food.UpdatedAt,
-		)
-		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan food: %w", err)
+		tag := &model.FoodTag{}
+		if err := rows.Scan(&tag.Tag, &tag.FoodCount); err != nil {
+			return nil, fmt.Errorf("failed to scan food tag: %w", err)
 		}
-		foods = append(foods, food)
+		tags = append(tags, tag)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("error iterating foods: %w", err)
+		return nil, fmt.Errorf("error iterating food tags: %w", err)
 	}
 
-	return foods, total, nil
+	return tags, nil
 }
 
 // BatchInsertFoods inserts multiple food items in a batch
@@ -240,8 +334,9 @@ func (r *FoodRepository) BatchInsertFoods(userID int64, foods []*model.Food) err
 	defer tx.Rollback()
 
 	query := `
-		INSERT INTO foods (user_id, name, category, price, unit, protein, carbs, fat, fiber, calories, available)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO foods (user_id, name, aliases, category, tags, price, unit, protein, carbs, fat, fiber, calories,
+		                   water_pct, allergens, diet_tags, available)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	stmt, err := tx.Prepare(query)
@@ -257,7 +352,9 @@ func (r *FoodRepository) BatchInsertFoods(userID int64, foods []*model.Food) err
 		_, err := stmt.Exec(
 			food.UserID,
 			food.Name,
+			marshalStringList(food.Aliases),
 			food.Category,
+			marshalStringList(food.Tags),
 			food.Price,
 			food.Unit,
 			food.Protein,
@@ -265,6 +362,9 @@ func (r *FoodRepository) BatchInsertFoods(userID int64, foods []*model.Food) err
 			food.Fat,
 			food.Fiber,
 			food.Calories,
+			food.WaterPct,
+			marshalStringList(food.Allergens),
+			marshalStringList(food.DietTags),
 			food.Available,
 		)
 		if err != nil {
@@ -278,3 +378,92 @@ func (r *FoodRepository) BatchInsertFoods(userID int64, foods []*model.Food) err
 
 	return nil
 }
+
+// queryFoods runs a query selecting foodColumns and scans the result rows
+func (r *FoodRepository) queryFoods(query string, args ...interface{}) ([]*model.Food, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list foods: %w", err)
+	}
+	defer rows.Close()
+
+	foods := make([]*model.Food, 0)
+	for rows.Next() {
+		food, err := scanFood(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan food: %w", err)
+		}
+		foods = append(foods, food)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating foods: %w", err)
+	}
+
+	return foods, nil
+}
+
+// scanFood scans a row selecting foodColumns
+func scanFood(row rowScanner) (*model.Food, error) {
+	food := &model.Food{}
+	var aliases, tags, allergens, dietTags []byte
+	err := row.Scan(
+		&food.ID,
+		&food.UserID,
+		&food.Name,
+		&aliases,
+		&food.Category,
+		&tags,
+		&food.Price,
+		&food.Unit,
+		&food.Protein,
+		&food.Carbs,
+		&food.Fat,
+		&food.Fiber,
+		&food.Calories,
+		&food.WaterPct,
+		&allergens,
+		&dietTags,
+		&food.Available,
+		&food.Favorite,
+		&food.CreatedAt,
+		&food.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	food.Aliases = unmarshalStringList(aliases)
+	food.Tags = unmarshalStringList(tags)
+	food.Allergens = unmarshalStringList(allergens)
+	food.DietTags = unmarshalStringList(dietTags)
+	return food, nil
+}
+
+// restrictionClause builds a condition matching foods compatible with the
+// restrictions: free of every avoided allergen and tagged for every followed diet
+// (or a stricter one, see model.DietSatisfiedBy)
+func restrictionClause(restrictions model.DietaryRestrictions) (string, []interface{}) {
+	conditions := []string{"1 = 1"}
+	args := []interface{}{}
+
+	for _, allergen := range restrictions.Allergens {
+		conditions = append(conditions, "NOT JSON_CONTAINS(COALESCE(allergens, JSON_ARRAY()), JSON_QUOTE(?))")
+		args = append(args, allergen)
+	}
+
+	for _, diet := range restrictions.Diets {
+		tags := model.DietSatisfiedBy(diet)
+		if len(tags) == 0 {
+			tags = []string{diet}
+		}
+		alternatives := make([]string, len(tags))
+		for i, tag := range tags {
+			alternatives[i] = "JSON_CONTAINS(COALESCE(diet_tags, JSON_ARRAY()), JSON_QUOTE(?))"
+			args = append(args, tag)
+		}
+		conditions = append(conditions, "("+strings.Join(alternatives, " OR ")+")")
+	}
+
+	return "(" + strings.Join(conditions, " AND ") + ")", args
+}
@@ -34,18 +34,20 @@ func (r *MealRepository) CreateMeal(meal *model.Meal) error {
 	}
 
 	query := `
-		INSERT INTO meals (user_id, meal_date, meal_type, foods, nutrition, notes)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO meals (user_id, meal_date, meal_type, foods, nutrition, notes, eaten_at, eaten_at_estimated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := r.db.Exec(
 		query,
 		meal.UserID,
-		meal.MealDate,
+		dateArg(meal.MealDate),
 		meal.MealType,
 		foodsJSON,
 		nutritionJSON,
 		meal.Notes,
+		meal.EatenAt,
+		meal.EatenAtEstimated,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create meal: %w", err)
@@ -75,17 +77,19 @@ func (r *MealRepository) UpdateMeal(userID, mealID int64, meal *model.Meal) erro
 
 	query := `
 		UPDATE meals 
-		SET meal_date = ?, meal_type = ?, foods = ?, nutrition = ?, notes = ?
+		SET meal_date = ?, meal_type = ?, foods = ?, nutrition = ?, notes = ?, eaten_at = ?, eaten_at_estimated = ?
 		WHERE id = ? AND user_id = ?
 	`
 
 	result, err := r.db.Exec(
 		query,
-		meal.MealDate,
+		dateArg(meal.MealDate),
 		meal.MealType,
 		foodsJSON,
 		nutritionJSON,
 		meal.Notes,
+		meal.EatenAt,
+		meal.EatenAtEstimated,
 		mealID,
 		userID,
 	)
@@ -129,13 +133,14 @@ func (r *MealRepository) DeleteMeal(userID, mealID int64) error {
 // GetMealByID retrieves a meal record by ID (with ownership verification)
 func (r *MealRepository) GetMealByID(userID, mealID int64) (*model.Meal, error) {
 	query := `
-		SELECT id, user_id, meal_date, meal_type, foods, nutrition, notes, created_at, updated_at
+		SELECT id, user_id, meal_date, meal_type, foods, nutrition, notes, eaten_at, eaten_at_estimated, created_at, updated_at
 		FROM meals
 		WHERE id = ? AND user_id = ?
 	`
 
 	meal := &model.Meal{}
 	var foodsJSON, nutritionJSON []byte
+	var eatenAt sql.NullTime
 
 	err := r.db.QueryRow(query, mealID, userID).Scan(
 		&meal.ID,
@@ -145,6 +150,8 @@ func (r *MealRepository) GetMealByID(userID, mealID int64) (*model.Meal, error)
 		&foodsJSON,
 		&nutritionJSON,
 		&meal.Notes,
+		&eatenAt,
+		&meal.EatenAtEstimated,
 		&meal.CreatedAt,
 		&meal.UpdatedAt,
 	)
@@ -164,6 +171,10 @@ func (r *MealRepository) GetMealByID(userID, mealID int64) (*model.Meal, error)
 		return nil, fmt.Errorf("failed to unmarshal nutrition: %w", err)
 	}
 
+	if eatenAt.Valid {
+		meal.EatenAt = &eatenAt.Time
+	}
+
 	return meal, nil
 }
 
@@ -175,12 +186,12 @@ func (r *MealRepository) ListMeals(userID int64, filter *model.MealFilter) ([]*m
 
 	if filter.StartDate != nil {
 		whereClauses = append(whereClauses, "meal_date >= ?")
-		args = append(args, *filter.StartDate)
+		args = append(args, dateArg(*filter.StartDate))
 	}
 
 	if filter.EndDate != nil {
 		whereClauses = append(whereClauses, "meal_date <= ?")
-		args = append(args, *filter.EndDate)
+		args = append(args, dateArg(*filter.EndDate))
 	}
 
 	if filter.MealType != "" {
@@ -200,7 +211,7 @@ func (r *MealRepository) ListMeals(userID int64, filter *model.MealFilter) ([]*m
 
 	// Get paginated results
 	query := fmt.Sprintf(`
-		SELECT id, user_id, meal_date, meal_type, foods, nutrition, notes, created_at, updated_at
+		SELECT id, user_id, meal_date, meal_type, foods, nutrition, notes, eaten_at, eaten_at_estimated, created_at, updated_at
 		FROM meals
 		WHERE %s
 		ORDER BY meal_date DESC, created_at DESC
@@ -220,6 +231,7 @@ func (r *MealRepository) ListMeals(userID int64, filter *model.MealFilter) ([]*m
 	for rows.Next() {
 		meal := &model.Meal{}
 		var foodsJSON, nutritionJSON []byte
+		var eatenAt sql.NullTime
 
 		err := rows.Scan(
 			&meal.ID,
@@ -229,6 +241,8 @@ func (r *MealRepository) ListMeals(userID int64, filter *model.MealFilter) ([]*m
 			&foodsJSON,
 			&nutritionJSON,
 			&meal.Notes,
+			&eatenAt,
+			&meal.EatenAtEstimated,
 			&meal.CreatedAt,
 			&meal.UpdatedAt,
 		)
@@ -245,6 +259,10 @@ func (r *MealRepository) ListMeals(userID int64, filter *model.MealFilter) ([]*m
 			return nil, 0, fmt.Errorf("failed to unmarshal nutrition: %w", err)
 		}
 
+		if eatenAt.Valid {
+			meal.EatenAt = &eatenAt.Time
+		}
+
 		meals = append(meals, meal)
 	}
 
@@ -257,18 +275,18 @@ func (r *MealRepository) ListMeals(userID int64, filter *model.MealFilter) ([]*m
 
 // GetMonthlyMeals retrieves all meals for a specific month
 func (r *MealRepository) GetMonthlyMeals(userID int64, year, month int) ([]*model.Meal, error) {
-	// Calculate start and end dates for the month
+	// Calculate the first and last calendar day of the month
 	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
-	endDate := startDate.AddDate(0, 1, 0).Add(-time.Second)
+	endDate := startDate.AddDate(0, 1, -1)
 
 	query := `
-		SELECT id, user_id, meal_date, meal_type, foods, nutrition, notes, created_at, updated_at
+		SELECT id, user_id, meal_date, meal_type, foods, nutrition, notes, eaten_at, eaten_at_estimated, created_at, updated_at
 		FROM meals
 		WHERE user_id = ? AND meal_date >= ? AND meal_date <= ?
 		ORDER BY meal_date ASC, created_at ASC
 	`
 
-	rows, err := r.db.Query(query, userID, startDate, endDate)
+	rows, err := r.db.Query(query, userID, dateArg(startDate), dateArg(endDate))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get monthly meals: %w", err)
 	}
@@ -278,6 +296,7 @@ func (r *MealRepository) GetMonthlyMeals(userID int64, year, month int) ([]*mode
 	for rows.Next() {
 		meal := &model.Meal{}
 		var foodsJSON, nutritionJSON []byte
+		var eatenAt sql.NullTime
 
 		err := rows.Scan(
 			&meal.ID,
@@ -287,6 +306,8 @@ func (r *MealRepository) GetMonthlyMeals(userID int64, year, month int) ([]*mode
 			&foodsJSON,
 			&nutritionJSON,
 			&meal.Notes,
+			&eatenAt,
+			&meal.EatenAtEstimated,
 			&meal.CreatedAt,
 			&meal.UpdatedAt,
 		)
@@ -303,6 +324,10 @@ func (r *MealRepository) GetMonthlyMeals(userID int64, year, month int) ([]*mode
 			return nil, fmt.Errorf("failed to unmarshal nutrition: %w", err)
 		}
 
+		if eatenAt.Valid {
+			meal.EatenAt = &eatenAt.Time
+		}
+
 		meals = append(meals, meal)
 	}
 
@@ -312,3 +337,170 @@ func (r *MealRepository) GetMonthlyMeals(userID int64, year, month int) ([]*mode
 
 	return meals, nil
 }
+
+// FindUnlinkedMeal finds the earliest meal logged for the given date and meal type
+// that is not yet linked to any plan. Returns nil if no such meal exists.
+func (r *MealRepository) FindUnlinkedMeal(userID int64, mealDate time.Time, mealType string) (*model.Meal, error) {
+	query := `
+		SELECT m.id, m.user_id, m.meal_date, m.meal_type, m.foods, m.nutrition, m.notes, m.eaten_at, m.eaten_at_estimated, m.created_at, m.updated_at
+		FROM meals m
+		WHERE m.user_id = ? AND m.meal_date = ? AND m.meal_type = ?
+		  AND NOT EXISTS (SELECT 1 FROM plans p WHERE p.meal_id = m.id)
+		ORDER BY m.created_at ASC
+		LIMIT 1
+	`
+
+	meal := &model.Meal{}
+	var foodsJSON, nutritionJSON []byte
+	var eatenAt sql.NullTime
+
+	err := r.db.QueryRow(query, userID, dateArg(mealDate), mealType).Scan(
+		&meal.ID,
+		&meal.UserID,
+		&meal.MealDate,
+		&meal.MealType,
+		&foodsJSON,
+		&nutritionJSON,
+		&meal.Notes,
+		&eatenAt,
+		&meal.EatenAtEstimated,
+		&meal.CreatedAt,
+		&meal.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find unlinked meal: %w", err)
+	}
+
+	// Unmarshal JSON fields
+	if err := json.Unmarshal(foodsJSON, &meal.Foods); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal foods: %w", err)
+	}
+
+	if err := json.Unmarshal(nutritionJSON, &meal.Nutrition); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal nutrition: %w", err)
+	}
+
+	if eatenAt.Valid {
+		meal.EatenAt = &eatenAt.Time
+	}
+
+	return meal, nil
+}
+
+// GetMealsByDateRange retrieves all meals of a user within a date range (inclusive)
+func (r *MealRepository) GetMealsByDateRange(userID int64, startDate, endDate time.Time) ([]*model.Meal, error) {
+	query := `
+		SELECT id, user_id, meal_date, meal_type, foods, nutrition, notes, eaten_at, eaten_at_estimated, created_at, updated_at
+		FROM meals
+		WHERE user_id = ? AND meal_date >= ? AND meal_date <= ?
+		ORDER BY meal_date ASC, created_at ASC
+	`
+
+	rows, err := r.db.Query(query, userID, dateArg(startDate), dateArg(endDate))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meals by date range: %w", err)
+	}
+	defer rows.Close()
+
+	meals := make([]*model.Meal, 0)
+	for rows.Next() {
+		meal := &model.Meal{}
+		var foodsJSON, nutritionJSON []byte
+		var eatenAt sql.NullTime
+
+		err := rows.Scan(
+			&meal.ID,
+			&meal.UserID,
+			&meal.MealDate,
+			&meal.MealType,
+			&foodsJSON,
+			&nutritionJSON,
+			&meal.Notes,
+			&eatenAt,
+			&meal.EatenAtEstimated,
+			&meal.CreatedAt,
+			&meal.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan meal: %w", err)
+		}
+
+		// Unmarshal JSON fields
+		if err := json.Unmarshal(foodsJSON, &meal.Foods); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal foods: %w", err)
+		}
+
+		if err := json.Unmarshal(nutritionJSON, &meal.Nutrition); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal nutrition: %w", err)
+		}
+
+		if eatenAt.Valid {
+			meal.EatenAt = &eatenAt.Time
+		}
+
+		meals = append(meals, meal)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating meals: %w", err)
+	}
+
+	return meals, nil
+}
+
+// GetFoodUsage summarizes how often and how recently each food appears in the
+// user's meals since the given date (inclusive), keyed by food ID
+func (r *MealRepository) GetFoodUsage(userID int64, since time.Time) (map[int64]*model.FoodUsage, error) {
+	query := `
+		SELECT jt.food_id, m.meal_type, m.meal_date, jt.amount, jt.unit
+		FROM meals m,
+		     JSON_TABLE(m.foods, '$[*]' COLUMNS (
+		         food_id BIGINT PATH '$.food_id',
+		         amount DOUBLE PATH '$.amount',
+		         unit VARCHAR(20) PATH '$.unit'
+		     )) jt
+		WHERE m.user_id = ? AND m.meal_date >= ?
+		ORDER BY m.meal_date ASC, m.created_at ASC
+	`
+
+	rows, err := r.db.Query(query, userID, dateArg(since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get food usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := make(map[int64]*model.FoodUsage)
+	for rows.Next() {
+		var foodID int64
+		var mealType string
+		var mealDate time.Time
+		var amount sql.NullFloat64
+		var unit sql.NullString
+
+		if err := rows.Scan(&foodID, &mealType, &mealDate, &amount, &unit); err != nil {
+			return nil, fmt.Errorf("failed to scan food usage: %w", err)
+		}
+
+		u, ok := usage[foodID]
+		if !ok {
+			u = &model.FoodUsage{FoodID: foodID, MealTypes: make(map[string]int)}
+			usage[foodID] = u
+		}
+
+		// Rows are in meal order, so the last row seen is the most recent use
+		u.UseCount++
+		u.MealTypes[mealType]++
+		u.LastUsed = mealDate
+		u.LastAmount = amount.Float64
+		u.LastUnit = unit.String
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating food usage: %w", err)
+	}
+
+	return usage, nil
+}
@@ -0,0 +1,381 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+)
+
+// NutritionGoalRepository handles nutrition goal history data access operations
+type NutritionGoalRepository struct {
+	db *sql.DB
+}
+
+// NewNutritionGoalRepository creates a new NutritionGoalRepository instance
+func NewNutritionGoalRepository(db *sql.DB) *NutritionGoalRepository {
+	return &NutritionGoalRepository{db: db}
+}
+
+const nutritionGoalColumns = `id, user_id, profile, effective_from, calories, protein, carbs, fat, fiber, note, created_at, updated_at`
+
+const goalProfileScheduleColumns = `id, user_id, effective_from, weekday_profiles, created_at, updated_at`
+
+const goalProfileOverrideColumns = `id, user_id, override_date, profile, note, created_at, updated_at`
+
+// UpsertGoal creates a goal period, replacing the goals of an existing period of the
+// same profile with the same effective date
+func (r *NutritionGoalRepository) UpsertGoal(goal *model.GoalPeriod) error {
+	query := `
+		INSERT INTO nutrition_goals (user_id, profile, effective_from, calories, protein, carbs, fat, fiber, note)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			id = LAST_INSERT_ID(id),
+			calories = VALUES(calories),
+			protein = VALUES(protein),
+			carbs = VALUES(carbs),
+			fat = VALUES(fat),
+			fiber = VALUES(fiber),
+			note = VALUES(note)
+	`
+
+	result, err := r.db.Exec(
+		query,
+		goal.UserID,
+		goal.Profile,
+		dateArg(goal.EffectiveFrom),
+		goal.Calories,
+		goal.Protein,
+		goal.Carbs,
+		goal.Fat,
+		goal.Fiber,
+		nullableString(goal.Note),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save nutrition goal: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	goal.ID = id
+	return nil
+}
+
+// CreateBaseGoal records the everyday goals as a period effective from the date the
+// account was created, or latest if that is earlier. It does nothing if a period
+// already starts on that date.
+func (r *NutritionGoalRepository) CreateBaseGoal(userID int64, goals model.NutritionGoals, latest time.Time) error {
+	query := `
+		INSERT IGNORE INTO nutrition_goals (user_id, profile, effective_from, calories, protein, carbs, fat, fiber)
+		SELECT id, ?, LEAST(DATE(created_at), ?), ?, ?, ?, ?, ?
+		FROM users
+		WHERE id = ?
+	`
+
+	_, err := r.db.Exec(
+		query,
+		model.BaseGoalProfile,
+		dateArg(latest),
+		goals.Calories,
+		goals.Protein,
+		goals.Carbs,
+		goals.Fat,
+		goals.Fiber,
+		userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create base nutrition goal: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteGoal deletes a goal period
+func (r *NutritionGoalRepository) DeleteGoal(userID, goalID int64) error {
+	query := `DELETE FROM nutrition_goals WHERE id = ? AND user_id = ?`
+
+	result, err := r.db.Exec(query, goalID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete nutrition goal: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("nutrition goal not found or access denied")
+	}
+
+	return nil
+}
+
+// GetGoalByID retrieves a goal period by ID
+func (r *NutritionGoalRepository) GetGoalByID(userID, goalID int64) (*model.GoalPeriod, error) {
+	query := `
+		SELECT ` + nutritionGoalColumns + `
+		FROM nutrition_goals
+		WHERE id = ? AND user_id = ?
+	`
+
+	goals, err := r.queryGoals(query, goalID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(goals) == 0 {
+		return nil, fmt.Errorf("nutrition goal not found or access denied")
+	}
+
+	return goals[0], nil
+}
+
+// ListGoals retrieves the goal periods of all profiles of a user ordered by effective date
+func (r *NutritionGoalRepository) ListGoals(userID int64) ([]*model.GoalPeriod, error) {
+	query := `
+		SELECT ` + nutritionGoalColumns + `
+		FROM nutrition_goals
+		WHERE user_id = ?
+		ORDER BY effective_from ASC, profile ASC
+	`
+
+	return r.queryGoals(query, userID)
+}
+
+// queryGoals runs a goal period query and scans the results
+func (r *NutritionGoalRepository) queryGoals(query string, args ...interface{}) ([]*model.GoalPeriod, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query nutrition goals: %w", err)
+	}
+	defer rows.Close()
+
+	goals := make([]*model.GoalPeriod, 0)
+	for rows.Next() {
+		goal, err := scanGoalPeriod(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan nutrition goal: %w", err)
+		}
+		goals = append(goals, goal)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating nutrition goals: %w", err)
+	}
+
+	return goals, nil
+}
+
+// scanGoalPeriod scans a row selecting nutritionGoalColumns
+func scanGoalPeriod(row rowScanner) (*model.GoalPeriod, error) {
+	goal := &model.GoalPeriod{}
+	var note sql.NullString
+
+	err := row.Scan(
+		&goal.ID,
+		&goal.UserID,
+		&goal.Profile,
+		&goal.EffectiveFrom,
+		&goal.Calories,
+		&goal.Protein,
+		&goal.Carbs,
+		&goal.Fat,
+		&goal.Fiber,
+		&note,
+		&goal.CreatedAt,
+		&goal.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	goal.Note = note.String
+	return goal, nil
+}
+
+// UpsertSchedule creates a weekday schedule, replacing an existing schedule with the same effective date
+func (r *NutritionGoalRepository) UpsertSchedule(schedule *model.GoalProfileSchedule) error {
+	query := `
+		INSERT INTO goal_profile_schedules (user_id, effective_from, weekday_profiles)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			id = LAST_INSERT_ID(id),
+			weekday_profiles = VALUES(weekday_profiles)
+	`
+
+	weekdayProfiles, err := json.Marshal(schedule.WeekdayProfiles)
+	if err != nil {
+		return fmt.Errorf("failed to marshal weekday profiles: %w", err)
+	}
+
+	result, err := r.db.Exec(query, schedule.UserID, dateArg(schedule.EffectiveFrom), string(weekdayProfiles))
+	if err != nil {
+		return fmt.Errorf("failed to save goal profile schedule: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	schedule.ID = id
+	return nil
+}
+
+// ListSchedules retrieves all weekday schedules of a user ordered by effective date
+func (r *NutritionGoalRepository) ListSchedules(userID int64) ([]*model.GoalProfileSchedule, error) {
+	query := `
+		SELECT ` + goalProfileScheduleColumns + `
+		FROM goal_profile_schedules
+		WHERE user_id = ?
+		ORDER BY effective_from ASC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query goal profile schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := make([]*model.GoalProfileSchedule, 0)
+	for rows.Next() {
+		schedule, err := scanGoalProfileSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan goal profile schedule: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating goal profile schedules: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// scanGoalProfileSchedule scans a row selecting goalProfileScheduleColumns
+func scanGoalProfileSchedule(row rowScanner) (*model.GoalProfileSchedule, error) {
+	schedule := &model.GoalProfileSchedule{}
+	var weekdayProfiles []byte
+
+	err := row.Scan(
+		&schedule.ID,
+		&schedule.UserID,
+		&schedule.EffectiveFrom,
+		&weekdayProfiles,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(weekdayProfiles, &schedule.WeekdayProfiles); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal weekday profiles: %w", err)
+	}
+	return schedule, nil
+}
+
+// UpsertOverride assigns a profile to a date, replacing an existing override for the date
+func (r *NutritionGoalRepository) UpsertOverride(override *model.GoalProfileOverride) error {
+	query := `
+		INSERT INTO goal_profile_overrides (user_id, override_date, profile, note)
+		VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			id = LAST_INSERT_ID(id),
+			profile = VALUES(profile),
+			note = VALUES(note)
+	`
+
+	result, err := r.db.Exec(query, override.UserID, dateArg(override.Date), override.Profile, nullableString(override.Note))
+	if err != nil {
+		return fmt.Errorf("failed to save goal profile override: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	override.ID = id
+	return nil
+}
+
+// DeleteOverride deletes a per-date profile override
+func (r *NutritionGoalRepository) DeleteOverride(userID, overrideID int64) error {
+	query := `DELETE FROM goal_profile_overrides WHERE id = ? AND user_id = ?`
+
+	result, err := r.db.Exec(query, overrideID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete goal profile override: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("goal profile override not found or access denied")
+	}
+
+	return nil
+}
+
+// GetOverridesByDateRange retrieves per-date profile overrides within a date range (inclusive)
+func (r *NutritionGoalRepository) GetOverridesByDateRange(userID int64, startDate, endDate time.Time) ([]*model.GoalProfileOverride, error) {
+	query := `
+		SELECT ` + goalProfileOverrideColumns + `
+		FROM goal_profile_overrides
+		WHERE user_id = ? AND override_date BETWEEN ? AND ?
+		ORDER BY override_date ASC
+	`
+
+	rows, err := r.db.Query(query, userID, dateArg(startDate), dateArg(endDate))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query goal profile overrides: %w", err)
+	}
+	defer rows.Close()
+
+	overrides := make([]*model.GoalProfileOverride, 0)
+	for rows.Next() {
+		override, err := scanGoalProfileOverride(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan goal profile override: %w", err)
+		}
+		overrides = append(overrides, override)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating goal profile overrides: %w", err)
+	}
+
+	return overrides, nil
+}
+
+// scanGoalProfileOverride scans a row selecting goalProfileOverrideColumns
+func scanGoalProfileOverride(row rowScanner) (*model.GoalProfileOverride, error) {
+	override := &model.GoalProfileOverride{}
+	var note sql.NullString
+
+	err := row.Scan(
+		&override.ID,
+		&override.UserID,
+		&override.Date,
+		&override.Profile,
+		&note,
+		&override.CreatedAt,
+		&override.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	override.Note = note.String
+	return override, nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+)
+
+var (
+	// ErrOIDCStateNotFound OIDC 授权流程不存在或已完成
+	ErrOIDCStateNotFound = errors.New("oidc login state not found")
+)
+
+// OIDCStateRepository OIDC 授权流程仓储接口
+type OIDCStateRepository interface {
+	CreateState(ctx context.Context, state *model.OIDCLoginState) error
+	ConsumeState(ctx context.Context, stateHash string) (*model.OIDCLoginState, error)
+}
+
+// oidcStateRepository OIDC 授权流程仓储实现
+type oidcStateRepository struct {
+	db *sql.DB
+}
+
+// NewOIDCStateRepository 创建 OIDC 授权流程仓储实例
+func NewOIDCStateRepository(db *sql.DB) OIDCStateRepository {
+	return &oidcStateRepository{
+		db: db,
+	}
+}
+
+// CreateState 保存新的授权流程，同时清理已过期的流程
+func (r *oidcStateRepository) CreateState(ctx context.Context, state *model.OIDCLoginState) error {
+	now := time.Now()
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM oidc_login_states WHERE expires_at < ?`, now); err != nil {
+		return fmt.Errorf("failed to delete expired oidc login states: %w", err)
+	}
+
+	query := `
+		INSERT INTO oidc_login_states (state_hash, purpose, user_id, nonce, code_verifier, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		state.StateHash,
+		state.Purpose,
+		state.UserID,
+		state.Nonce,
+		state.CodeVerifier,
+		state.ExpiresAt,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create oidc login state: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get oidc login state id: %w", err)
+	}
+
+	state.ID = id
+	state.CreatedAt = now
+
+	return nil
+}
+
+// ConsumeState 取出并删除授权流程，每个 state 只能使用一次（并发回调时只有一个请求成功）
+func (r *oidcStateRepository) ConsumeState(ctx context.Context, stateHash string) (*model.OIDCLoginState, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT id, state_hash, purpose, user_id, nonce, code_verifier, expires_at, created_at
+		FROM oidc_login_states
+		WHERE state_hash = ?
+		FOR UPDATE
+	`
+
+	state := &model.OIDCLoginState{}
+	var userID sql.NullInt64
+	err = tx.QueryRowContext(ctx, query, stateHash).Scan(
+		&state.ID,
+		&state.StateHash,
+		&state.Purpose,
+		&userID,
+		&state.Nonce,
+		&state.CodeVerifier,
+		&state.ExpiresAt,
+		&state.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrOIDCStateNotFound
+		}
+		return nil, fmt.Errorf("failed to get oidc login state: %w", err)
+	}
+
+	if userID.Valid {
+		state.UserID = &userID.Int64
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM oidc_login_states WHERE id = ?`, state.ID); err != nil {
+		return nil, fmt.Errorf("failed to delete oidc login state: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return state, nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
 )
@@ -40,7 +41,7 @@ func (r *PlanRepository) CreatePlan(plan *model.Plan) error {
 	result, err := r.db.Exec(
 		query,
 		plan.UserID,
-		plan.PlanDate,
+		dateArg(plan.PlanDate),
 		plan.MealType,
 		foodsJSON,
 		nutritionJSON,
@@ -81,7 +82,7 @@ func (r *PlanRepository) UpdatePlan(userID, planID int64, plan *model.Plan) erro
 
 	result, err := r.db.Exec(
 		query,
-		plan.PlanDate,
+		dateArg(plan.PlanDate),
 		plan.MealType,
 		foodsJSON,
 		nutritionJSON,
@@ -130,13 +131,14 @@ func (r *PlanRepository) DeletePlan(userID, planID int64) error {
 // GetPlanByID retrieves a plan record by ID (with ownership verification)
 func (r *PlanRepository) GetPlanByID(userID, planID int64) (*model.Plan, error) {
 	query := `
-		SELECT id, user_id, plan_date, meal_type, foods, nutrition, status, ai_reasoning, created_at, updated_at
+		SELECT id, user_id, plan_date, meal_type, foods, nutrition, status, meal_id, ai_reasoning, created_at, updated_at
 		FROM plans
 		WHERE id = ? AND user_id = ?
 	`
 
 	plan := &model.Plan{}
 	var foodsJSON, nutritionJSON []byte
+	var mealID sql.NullInt64
 
 	err := r.db.QueryRow(query, planID, userID).Scan(
 		&plan.ID,
@@ -146,6 +148,7 @@ func (r *PlanRepository) GetPlanByID(userID, planID int64) (*model.Plan, error)
 		&foodsJSON,
 		&nutritionJSON,
 		&plan.Status,
+		&mealID,
 		&plan.AIReasoning,
 		&plan.CreatedAt,
 		&plan.UpdatedAt,
@@ -166,6 +169,10 @@ func (r *PlanRepository) GetPlanByID(userID, planID int64) (*model.Plan, error)
 		return nil, fmt.Errorf("failed to unmarshal nutrition: %w", err)
 	}
 
+	if mealID.Valid {
+		plan.MealID = &mealID.Int64
+	}
+
 	return plan, nil
 }
 
@@ -177,12 +184,12 @@ func (r *PlanRepository) ListPlans(userID int64, filter *model.PlanFilter) ([]*m
 
 	if filter.StartDate != nil {
 		whereClauses = append(whereClauses, "plan_date >= ?")
-		args = append(args, *filter.StartDate)
+		args = append(args, dateArg(*filter.StartDate))
 	}
 
 	if filter.EndDate != nil {
 		whereClauses = append(whereClauses, "plan_date <= ?")
-		args = append(args, *filter.EndDate)
+		args = append(args, dateArg(*filter.EndDate))
 	}
 
 	if filter.Status != "" {
@@ -202,7 +209,7 @@ func (r *PlanRepository) ListPlans(userID int64, filter *model.PlanFilter) ([]*m
 
 	// Get paginated results
 	query := fmt.Sprintf(`
-		SELECT id, user_id, plan_date, meal_type, foods, nutrition, status, ai_reasoning, created_at, updated_at
+		SELECT id, user_id, plan_date, meal_type, foods, nutrition, status, meal_id, ai_reasoning, created_at, updated_at
 		FROM plans
 		WHERE %s
 		ORDER BY plan_date ASC, created_at ASC
@@ -222,6 +229,7 @@ func (r *PlanRepository) ListPlans(userID int64, filter *model.PlanFilter) ([]*m
 	for rows.Next() {
 		plan := &model.Plan{}
 		var foodsJSON, nutritionJSON []byte
+		var mealID sql.NullInt64
 
 		err := rows.Scan(
 			&plan.ID,
@@ -231,6 +239,7 @@ func (r *PlanRepository) ListPlans(userID int64, filter *model.PlanFilter) ([]*m
 			&foodsJSON,
 			&nutritionJSON,
 			&plan.Status,
+			&mealID,
 			&plan.AIReasoning,
 			&plan.CreatedAt,
 			&plan.UpdatedAt,
@@ -248,6 +257,10 @@ func (r *PlanRepository) ListPlans(userID int64, filter *model.PlanFilter) ([]*m
 			return nil, 0, fmt.Errorf("failed to unmarshal nutrition: %w", err)
 		}
 
+		if mealID.Valid {
+			plan.MealID = &mealID.Int64
+		}
+
 		plans = append(plans, plan)
 	}
 
@@ -282,3 +295,136 @@ func (r *PlanRepository) UpdatePlanStatus(userID, planID int64, status string) e
 
 	return nil
 }
+
+// LinkMeal marks a plan as completed and links it to the meal that fulfilled it (with ownership verification)
+func (r *PlanRepository) LinkMeal(userID, planID, mealID int64) error {
+	query := `
+		UPDATE plans 
+		SET status = 'completed', meal_id = ?
+		WHERE id = ? AND user_id = ?
+	`
+
+	result, err := r.db.Exec(query, mealID, planID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to link meal to plan: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("plan not found or access denied")
+	}
+
+	return nil
+}
+
+// GetUserIDsWithPendingPlans retrieves the IDs of users that have pending plans on or before the given date
+func (r *PlanRepository) GetUserIDsWithPendingPlans(until time.Time) ([]int64, error) {
+	query := `
+		SELECT DISTINCT user_id
+		FROM plans
+		WHERE status = 'pending' AND plan_date <= ?
+	`
+
+	rows, err := r.db.Query(query, dateArg(until))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users with pending plans: %w", err)
+	}
+	defer rows.Close()
+
+	userIDs := make([]int64, 0)
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan user id: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user ids: %w", err)
+	}
+
+	return userIDs, nil
+}
+
+// GetPendingPlansUntil retrieves all pending plans of a user on or before the given date
+func (r *PlanRepository) GetPendingPlansUntil(userID int64, until time.Time) ([]*model.Plan, error) {
+	query := `
+		SELECT id, user_id, plan_date, meal_type, foods, nutrition, status, meal_id, ai_reasoning, created_at, updated_at
+		FROM plans
+		WHERE user_id = ? AND status = 'pending' AND plan_date <= ?
+		ORDER BY plan_date ASC, created_at ASC
+	`
+
+	return r.queryPlans(query, userID, dateArg(until))
+}
+
+// GetPlansByDateRange retrieves all plans of a user within a date range (inclusive)
+func (r *PlanRepository) GetPlansByDateRange(userID int64, startDate, endDate time.Time) ([]*model.Plan, error) {
+	query := `
+		SELECT id, user_id, plan_date, meal_type, foods, nutrition, status, meal_id, ai_reasoning, created_at, updated_at
+		FROM plans
+		WHERE user_id = ? AND plan_date >= ? AND plan_date <= ?
+		ORDER BY plan_date ASC, created_at ASC
+	`
+
+	return r.queryPlans(query, userID, dateArg(startDate), dateArg(endDate))
+}
+
+// queryPlans runs a plan query without pagination and scans all rows
+func (r *PlanRepository) queryPlans(query string, args ...interface{}) ([]*model.Plan, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query plans: %w", err)
+	}
+	defer rows.Close()
+
+	plans := make([]*model.Plan, 0)
+	for rows.Next() {
+		plan := &model.Plan{}
+		var foodsJSON, nutritionJSON []byte
+		var mealID sql.NullInt64
+
+		err := rows.Scan(
+			&plan.ID,
+			&plan.UserID,
+			&plan.PlanDate,
+			&plan.MealType,
+			&foodsJSON,
+			&nutritionJSON,
+			&plan.Status,
+			&mealID,
+			&plan.AIReasoning,
+			&plan.CreatedAt,
+			&plan.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan plan: %w", err)
+		}
+
+		// Unmarshal JSON fields
+		if err := json.Unmarshal(foodsJSON, &plan.Foods); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal foods: %w", err)
+		}
+
+		if err := json.Unmarshal(nutritionJSON, &plan.Nutrition); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal nutrition: %w", err)
+		}
+
+		if mealID.Valid {
+			plan.MealID = &mealID.Int64
+		}
+
+		plans = append(plans, plan)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating plans: %w", err)
+	}
+
+	return plans, nil
+}
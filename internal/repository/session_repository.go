@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+)
+
+var (
+	// ErrSessionNotFound 会话不存在
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+// sessionColumns 会话查询的列，与 scanSession 的扫描顺序一致
+const sessionColumns = `id, user_id, refresh_token_id, user_agent, ip_address,
+	created_at, last_used_at, expires_at, revoked_at, revoked_reason`
+
+// SessionRepository 登录会话仓储接口
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session *model.Session) error
+	GetSession(ctx context.Context, sessionID int64) (*model.Session, error)
+	ListActiveSessions(ctx context.Context, userID int64) ([]*model.Session, error)
+	RotateRefreshToken(ctx context.Context, sessionID int64, oldTokenID, newTokenID, ipAddress, userAgent string, expiresAt time.Time) (bool, error)
+	RevokeSession(ctx context.Context, sessionID int64, reason string) (bool, error)
+	RevokeUserSessions(ctx context.Context, userID, exceptSessionID int64, reason string) ([]int64, error)
+}
+
+// sessionRepository 登录会话仓储实现
+type sessionRepository struct {
+	db *sql.DB
+}
+
+// NewSessionRepository 创建登录会话仓储实例
+func NewSessionRepository(db *sql.DB) SessionRepository {
+	return &sessionRepository{
+		db: db,
+	}
+}
+
+// CreateSession 创建会话
+func (r *sessionRepository) CreateSession(ctx context.Context, session *model.Session) error {
+	query := `
+		INSERT INTO user_sessions (user_id, refresh_token_id, user_agent, ip_address, created_at, last_used_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query,
+		session.UserID,
+		session.RefreshTokenID,
+		session.UserAgent,
+		session.IPAddress,
+		now,
+		now,
+		session.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get session id: %w", err)
+	}
+
+	session.ID = id
+	session.CreatedAt = now
+	session.LastUsedAt = now
+
+	return nil
+}
+
+// GetSession 根据 ID 获取会话
+func (r *sessionRepository) GetSession(ctx context.Context, sessionID int64) (*model.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM user_sessions WHERE id = ?`
+
+	session, err := scanSession(r.db.QueryRowContext(ctx, query, sessionID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return session, nil
+}
+
+// ListActiveSessions 获取用户未撤销且未过期的会话，最近使用的在前
+func (r *sessionRepository) ListActiveSessions(ctx context.Context, userID int64) ([]*model.Session, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM user_sessions
+		WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ?
+		ORDER BY last_used_at DESC, id DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]*model.Session, 0)
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// RotateRefreshToken 轮换会话的刷新令牌
+// 只有当前刷新令牌 ID 仍为 oldTokenID 且会话未撤销时才会更新，返回 false 表示令牌已被轮换过（并发刷新或重放）
+func (r *sessionRepository) RotateRefreshToken(ctx context.Context, sessionID int64, oldTokenID, newTokenID, ipAddress, userAgent string, expiresAt time.Time) (bool, error) {
+	query := `
+		UPDATE user_sessions
+		SET refresh_token_id = ?, ip_address = ?, user_agent = ?, last_used_at = ?, expires_at = ?
+		WHERE id = ? AND refresh_token_id = ? AND revoked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, newTokenID, ipAddress, userAgent, time.Now(), expiresAt, sessionID, oldTokenID)
+	if err != nil {
+		return false, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// RevokeSession 撤销会话，返回 false 表示会话不存在或已被撤销
+func (r *sessionRepository) RevokeSession(ctx context.Context, sessionID int64, reason string) (bool, error) {
+	query := `UPDATE user_sessions SET revoked_at = ?, revoked_reason = ? WHERE id = ? AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), reason, sessionID)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// RevokeUserSessions 撤销用户除 exceptSessionID 之外的所有未撤销会话（exceptSessionID 为 0 时撤销全部）
+// 返回被撤销的会话 ID，供调用方将会话加入令牌黑名单
+func (r *sessionRepository) RevokeUserSessions(ctx context.Context, userID, exceptSessionID int64, reason string) ([]int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `SELECT id FROM user_sessions WHERE user_id = ? AND id <> ? AND revoked_at IS NULL FOR UPDATE`
+	rows, err := tx.QueryContext(ctx, query, userID, exceptSessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan session id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return ids, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := []interface{}{time.Now(), reason}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	update := `UPDATE user_sessions SET revoked_at = ?, revoked_reason = ? WHERE id IN (` + placeholders + `)`
+	if _, err := tx.ExecContext(ctx, update, args...); err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return ids, nil
+}
+
+// scanSession 扫描一行会话数据
+func scanSession(row rowScanner) (*model.Session, error) {
+	session := &model.Session{}
+	var revokedAt sql.NullTime
+	var revokedReason sql.NullString
+
+	err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.RefreshTokenID,
+		&session.UserAgent,
+		&session.IPAddress,
+		&session.CreatedAt,
+		&session.LastUsedAt,
+		&session.ExpiresAt,
+		&revokedAt,
+		&revokedReason,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if revokedAt.Valid {
+		session.RevokedAt = &revokedAt.Time
+	}
+	session.RevokedReason = revokedReason.String
+
+	return session, nil
+}
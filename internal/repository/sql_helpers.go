@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// rowScanner 表示 *sql.Row 和 *sql.Rows 共有的 Scan 方法
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// nullableString 将空字符串转换为 NULL
+func nullableString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// dateArg 将时间转换为 DATE 列的查询参数（YYYY-MM-DD）
+// 日期按时间值自身所在时区的日历日期计算，避免驱动按服务器时区转换后跨天
+func dateArg(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// nullFloatPtr 将可空浮点数转换为指针，NULL 时返回 nil
+func nullFloatPtr(f sql.NullFloat64) *float64 {
+	if !f.Valid {
+		return nil
+	}
+	v := f.Float64
+	return &v
+}
+
+// marshalStringList 将字符串列表序列化为 JSON 列的参数，为空时写入 NULL
+// 字符串列表的序列化不会失败，因此忽略错误
+func marshalStringList(values []string) interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(values)
+	return data
+}
+
+// unmarshalStringList 解析 JSON 列中的字符串列表，NULL 或格式错误时返回空列表
+func unmarshalStringList(data []byte) []string {
+	values := []string{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &values); err != nil {
+			return []string{}
+		}
+	}
+	return values
+}
@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+)
+
+var (
+	// ErrTOTPNotFound 用户没有 TOTP 密钥
+	ErrTOTPNotFound = errors.New("totp not found")
+)
+
+// TwoFactorRepository 两步验证仓储接口
+type TwoFactorRepository interface {
+	GetTOTP(ctx context.Context, userID int64) (*model.UserTOTP, error)
+	SaveTOTPSecret(ctx context.Context, userID int64, secret string) error
+	EnableTOTP(ctx context.Context, userID int64, step int64, recoveryCodeHashes []string) error
+	UseTOTPStep(ctx context.Context, userID int64, step int64) (bool, error)
+	DeleteTwoFactor(ctx context.Context, userID int64) error
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error
+	GetUnusedRecoveryCodes(ctx context.Context, userID int64) ([]*model.RecoveryCode, error)
+	UseRecoveryCode(ctx context.Context, codeID int64) (bool, error)
+}
+
+// twoFactorRepository 两步验证仓储实现
+type twoFactorRepository struct {
+	db     *sql.DB
+	crypto *utils.CryptoService
+}
+
+// NewTwoFactorRepository 创建两步验证仓储实例
+func NewTwoFactorRepository(db *sql.DB, crypto *utils.CryptoService) TwoFactorRepository {
+	return &twoFactorRepository{
+		db:     db,
+		crypto: crypto,
+	}
+}
+
+// GetTOTP 获取用户的 TOTP 密钥（已解密）
+func (r *twoFactorRepository) GetTOTP(ctx context.Context, userID int64) (*model.UserTOTP, error) {
+	query := `
+		SELECT user_id, secret_encrypted, enabled_at, last_used_step, created_at, updated_at
+		FROM user_totp
+		WHERE user_id = ?
+	`
+
+	totp := &model.UserTOTP{}
+	var encryptedSecret string
+	var enabledAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&totp.UserID,
+		&encryptedSecret,
+		&enabledAt,
+		&totp.LastUsedStep,
+		&totp.CreatedAt,
+		&totp.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTOTPNotFound
+		}
+		return nil, fmt.Errorf("failed to get totp: %w", err)
+	}
+
+	totp.Secret, err = r.crypto.DecryptAES(encryptedSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt totp secret: %w", err)
+	}
+
+	if enabledAt.Valid {
+		totp.EnabledAt = &enabledAt.Time
+	}
+
+	return totp, nil
+}
+
+// SaveTOTPSecret 保存新的 TOTP 密钥（加密存储），并将其置为未启用状态
+func (r *twoFactorRepository) SaveTOTPSecret(ctx context.Context, userID int64, secret string) error {
+	encryptedSecret, err := r.crypto.EncryptAES(secret)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt totp secret: %w", err)
+	}
+
+	query := `
+		INSERT INTO user_totp (user_id, secret_encrypted, enabled_at, last_used_step, created_at, updated_at)
+		VALUES (?, ?, NULL, 0, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			secret_encrypted = VALUES(secret_encrypted),
+			enabled_at = NULL,
+			last_used_step = 0,
+			updated_at = VALUES(updated_at)
+	`
+
+	now := time.Now()
+	if _, err := r.db.ExecContext(ctx, query, userID, encryptedSecret, now, now); err != nil {
+		return fmt.Errorf("failed to save totp secret: %w", err)
+	}
+
+	return nil
+}
+
+// EnableTOTP 启用两步验证并保存恢复码（在同一事务中完成）
+func (r *twoFactorRepository) EnableTOTP(ctx context.Context, userID int64, step int64, recoveryCodeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE user_totp SET enabled_at = ?, last_used_step = ? WHERE user_id = ? AND enabled_at IS NULL`
+	result, err := tx.ExecContext(ctx, query, time.Now(), step, userID)
+	if err != nil {
+		return fmt.Errorf("failed to enable totp: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrTOTPNotFound
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UseTOTPStep 记录已使用的时间步
+// 只有时间步大于上次使用的时间步时才会更新，返回 false 表示验证码已被使用过（重放）
+func (r *twoFactorRepository) UseTOTPStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	query := `UPDATE user_totp SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?`
+
+	result, err := r.db.ExecContext(ctx, query, step, userID, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to update totp step: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// DeleteTwoFactor 删除用户的 TOTP 密钥和所有恢复码（关闭两步验证）
+func (r *twoFactorRepository) DeleteTwoFactor(ctx context.Context, userID int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to delete totp: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes 用新的恢复码替换用户所有旧的恢复码
+func (r *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetUnusedRecoveryCodes 获取用户未使用的恢复码
+func (r *twoFactorRepository) GetUnusedRecoveryCodes(ctx context.Context, userID int64) ([]*model.RecoveryCode, error) {
+	query := `
+		SELECT id, user_id, code_hash, created_at
+		FROM mfa_recovery_codes
+		WHERE user_id = ? AND used_at IS NULL
+		ORDER BY id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recovery codes: %w", err)
+	}
+	defer rows.Close()
+
+	codes := make([]*model.RecoveryCode, 0)
+	for rows.Next() {
+		code := &model.RecoveryCode{}
+		if err := rows.Scan(&code.ID, &code.UserID, &code.CodeHash, &code.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan recovery code: %w", err)
+		}
+		codes = append(codes, code)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating recovery codes: %w", err)
+	}
+
+	return codes, nil
+}
+
+// UseRecoveryCode 将恢复码标记为已使用，返回 false 表示恢复码已被使用过
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, codeID int64) (bool, error) {
+	query := `UPDATE mfa_recovery_codes SET used_at = ? WHERE id = ? AND used_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), codeID)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// replaceRecoveryCodes 在事务中删除用户的旧恢复码并插入新的恢复码
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID int64, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	now := time.Now()
+	for _, hash := range codeHashes {
+		query := `INSERT INTO mfa_recovery_codes (user_id, code_hash, created_at) VALUES (?, ?, ?)`
+		if _, err := tx.ExecContext(ctx, query, userID, hash, now); err != nil {
+			return fmt.Errorf("failed to insert recovery code: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// UserCacheRepository 用户相关缓存键仓库接口
+type UserCacheRepository interface {
+	// DeleteUserKeys 删除用户相关的所有缓存键（如按用户计数的限流窗口）
+	DeleteUserKeys(ctx context.Context, userID int64) error
+}
+
+// redisUserCacheRepository Redis 实现的用户缓存键仓库
+type redisUserCacheRepository struct {
+	client *redis.Client
+}
+
+// NewRedisUserCacheRepository 创建 Redis 用户缓存键仓库
+func NewRedisUserCacheRepository(client *redis.Client) UserCacheRepository {
+	return &redisUserCacheRepository{
+		client: client,
+	}
+}
+
+// DeleteUserKeys 删除用户相关的所有缓存键
+// 键的格式与 middleware.RedisRateLimiter 保持一致
+func (r *redisUserCacheRepository) DeleteUserKeys(ctx context.Context, userID int64) error {
+	keys := []string{
+		fmt.Sprintf("ratelimit:user:%d", userID),
+	}
+
+	if err := r.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to delete user cache keys: %w", err)
+	}
+	return nil
+}
+
+// noopUserCacheRepository 未启用 Redis 时的用户缓存键仓库
+// 内存中的限流计数会在窗口结束后自动清理，无需删除
+type noopUserCacheRepository struct{}
+
+// NewNoopUserCacheRepository 创建未启用 Redis 时的用户缓存键仓库
+func NewNoopUserCacheRepository() UserCacheRepository {
+	return noopUserCacheRepository{}
+}
+
+// DeleteUserKeys 未启用 Redis 时没有需要删除的键
+func (noopUserCacheRepository) DeleteUserKeys(ctx context.Context, userID int64) error {
+	return nil
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+)
+
+var (
+	// ErrUserIdentityNotFound 外部身份不存在
+	ErrUserIdentityNotFound = errors.New("user identity not found")
+	// ErrUserIdentityExists 外部身份已关联到某个用户，或该用户已关联同一身份提供方的其他身份
+	ErrUserIdentityExists = errors.New("user identity already exists")
+)
+
+// userIdentityColumns 外部身份查询的列，与 scanUserIdentity 的扫描顺序一致
+const userIdentityColumns = `id, user_id, issuer, subject, email, created_at, last_login_at`
+
+// UserIdentityRepository 外部身份仓储接口
+type UserIdentityRepository interface {
+	CreateIdentity(ctx context.Context, identity *model.UserIdentity) error
+	GetIdentity(ctx context.Context, issuer, subject string) (*model.UserIdentity, error)
+	ListIdentities(ctx context.Context, userID int64) ([]*model.UserIdentity, error)
+	UpdateLastLogin(ctx context.Context, identityID int64, email string) error
+	DeleteIdentity(ctx context.Context, userID, identityID int64) error
+}
+
+// userIdentityRepository 外部身份仓储实现
+type userIdentityRepository struct {
+	db *sql.DB
+}
+
+// NewUserIdentityRepository 创建外部身份仓储实例
+func NewUserIdentityRepository(db *sql.DB) UserIdentityRepository {
+	return &userIdentityRepository{
+		db: db,
+	}
+}
+
+// CreateIdentity 关联外部身份
+func (r *userIdentityRepository) CreateIdentity(ctx context.Context, identity *model.UserIdentity) error {
+	query := `
+		INSERT INTO user_identities (user_id, issuer, subject, email, created_at, last_login_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query,
+		identity.UserID,
+		identity.Issuer,
+		identity.Subject,
+		identity.Email,
+		now,
+		identity.LastLoginAt,
+	)
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return ErrUserIdentityExists
+		}
+		return fmt.Errorf("failed to create user identity: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get user identity id: %w", err)
+	}
+
+	identity.ID = id
+	identity.CreatedAt = now
+
+	return nil
+}
+
+// GetIdentity 根据签发方和外部用户标识获取外部身份
+func (r *userIdentityRepository) GetIdentity(ctx context.Context, issuer, subject string) (*model.UserIdentity, error) {
+	query := `SELECT ` + userIdentityColumns + ` FROM user_identities WHERE issuer = ? AND subject = ?`
+
+	identity, err := scanUserIdentity(r.db.QueryRowContext(ctx, query, issuer, subject))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserIdentityNotFound
+		}
+		return nil, fmt.Errorf("failed to get user identity: %w", err)
+	}
+
+	return identity, nil
+}
+
+// ListIdentities 获取用户关联的所有外部身份
+func (r *userIdentityRepository) ListIdentities(ctx context.Context, userID int64) ([]*model.UserIdentity, error) {
+	query := `SELECT ` + userIdentityColumns + ` FROM user_identities WHERE user_id = ? ORDER BY created_at ASC, id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user identities: %w", err)
+	}
+	defer rows.Close()
+
+	identities := make([]*model.UserIdentity, 0)
+	for rows.Next() {
+		identity, err := scanUserIdentity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user identity: %w", err)
+		}
+		identities = append(identities, identity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate user identities: %w", err)
+	}
+
+	return identities, nil
+}
+
+// UpdateLastLogin 更新最近登录时间和身份提供方返回的邮箱
+func (r *userIdentityRepository) UpdateLastLogin(ctx context.Context, identityID int64, email string) error {
+	query := `UPDATE user_identities SET last_login_at = ?, email = ? WHERE id = ?`
+
+	if _, err := r.db.ExecContext(ctx, query, time.Now(), email, identityID); err != nil {
+		return fmt.Errorf("failed to update user identity last login: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteIdentity 取消关联外部身份，只能删除属于该用户的身份
+func (r *userIdentityRepository) DeleteIdentity(ctx context.Context, userID, identityID int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM user_identities WHERE id = ? AND user_id = ?`, identityID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user identity: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserIdentityNotFound
+	}
+
+	return nil
+}
+
+// scanUserIdentity 扫描一行外部身份数据
+func scanUserIdentity(row rowScanner) (*model.UserIdentity, error) {
+	identity := &model.UserIdentity{}
+	var lastLoginAt sql.NullTime
+
+	err := row.Scan(
+		&identity.ID,
+		&identity.UserID,
+		&identity.Issuer,
+		&identity.Subject,
+		&identity.Email,
+		&identity.CreatedAt,
+		&lastLoginAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastLoginAt.Valid {
+		identity.LastLoginAt = &lastLoginAt.Time
+	}
+
+	return identity, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
@@ -29,10 +30,18 @@ func (r *userPreferencesRepository) CreatePreferences(prefs *model.UserPreferenc
 		INSERT INTO user_preferences (
 			user_id, taste_preferences, dietary_restrictions, 
 			daily_calories_goal, daily_protein_goal, daily_carbs_goal,
-			daily_fat_goal, daily_fiber_goal
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			daily_fat_goal, daily_fiber_goal, daily_water_goal_ml, plan_reconcile_time, timezone,
+			preferred_meal_times, weight_unit, length_unit, goal_weight_kg,
+			sex, birth_date, height_cm, activity_level, weight_goal, adaptive_tdee,
+			exercise_flex_pct, allergens, diets, restriction_mode
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
+	mealTimes, err := marshalMealTimes(prefs.PreferredMealTimes)
+	if err != nil {
+		return err
+	}
+
 	result, err := r.db.Exec(
 		query,
 		prefs.UserID,
@@ -43,6 +52,23 @@ func (r *userPreferencesRepository) CreatePreferences(prefs *model.UserPreferenc
 		prefs.DailyCarbsGoal,
 		prefs.DailyFatGoal,
 		prefs.DailyFiberGoal,
+		prefs.WaterGoalOrDefault(),
+		nullableString(prefs.PlanReconcileTime),
+		nullableString(prefs.Timezone),
+		mealTimes,
+		nullableString(prefs.WeightUnit),
+		nullableString(prefs.LengthUnit),
+		prefs.GoalWeightKg,
+		nullableString(prefs.Sex),
+		nullableString(prefs.BirthDate),
+		prefs.HeightCm,
+		nullableString(prefs.ActivityLevel),
+		nullableString(prefs.WeightGoal),
+		prefs.AdaptiveTDEE,
+		prefs.ExerciseFlexPct,
+		marshalStringList(prefs.Allergens),
+		marshalStringList(prefs.Diets),
+		nullableString(prefs.RestrictionMode),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create preferences: %w", err)
@@ -68,10 +94,32 @@ func (r *userPreferencesRepository) UpdatePreferences(prefs *model.UserPreferenc
 		    daily_carbs_goal = ?,
 		    daily_fat_goal = ?,
 		    daily_fiber_goal = ?,
+		    daily_water_goal_ml = ?,
+		    plan_reconcile_time = ?,
+		    timezone = ?,
+		    preferred_meal_times = ?,
+		    weight_unit = ?,
+		    length_unit = ?,
+		    goal_weight_kg = ?,
+		    sex = ?,
+		    birth_date = ?,
+		    height_cm = ?,
+		    activity_level = ?,
+		    weight_goal = ?,
+		    adaptive_tdee = ?,
+		    exercise_flex_pct = ?,
+		    allergens = ?,
+		    diets = ?,
+		    restriction_mode = ?,
 		    updated_at = CURRENT_TIMESTAMP
 		WHERE user_id = ?
 	`
 
+	mealTimes, err := marshalMealTimes(prefs.PreferredMealTimes)
+	if err != nil {
+		return err
+	}
+
 	result, err := r.db.Exec(
 		query,
 		prefs.TastePreferences,
@@ -81,6 +129,23 @@ func (r *userPreferencesRepository) UpdatePreferences(prefs *model.UserPreferenc
 		prefs.DailyCarbsGoal,
 		prefs.DailyFatGoal,
 		prefs.DailyFiberGoal,
+		prefs.WaterGoalOrDefault(),
+		nullableString(prefs.PlanReconcileTime),
+		nullableString(prefs.Timezone),
+		mealTimes,
+		nullableString(prefs.WeightUnit),
+		nullableString(prefs.LengthUnit),
+		prefs.GoalWeightKg,
+		nullableString(prefs.Sex),
+		nullableString(prefs.BirthDate),
+		prefs.HeightCm,
+		nullableString(prefs.ActivityLevel),
+		nullableString(prefs.WeightGoal),
+		prefs.AdaptiveTDEE,
+		prefs.ExerciseFlexPct,
+		marshalStringList(prefs.Allergens),
+		marshalStringList(prefs.Diets),
+		nullableString(prefs.RestrictionMode),
 		prefs.UserID,
 	)
 	if err != nil {
@@ -104,12 +169,23 @@ func (r *userPreferencesRepository) GetPreferences(userID int64) (*model.UserPre
 	query := `
 		SELECT id, user_id, taste_preferences, dietary_restrictions,
 		       daily_calories_goal, daily_protein_goal, daily_carbs_goal,
-		       daily_fat_goal, daily_fiber_goal, created_at, updated_at
+		       daily_fat_goal, daily_fiber_goal, daily_water_goal_ml, plan_reconcile_time, timezone, preferred_meal_times,
+		       weight_unit, length_unit, goal_weight_kg, sex, birth_date, height_cm,
+		       activity_level, weight_goal, adaptive_tdee, exercise_flex_pct,
+		       allergens, diets, restriction_mode, created_at, updated_at
 		FROM user_preferences
 		WHERE user_id = ?
 	`
 
 	var prefs model.UserPreferences
+	var planReconcileTime, timezone sql.NullString
+	var mealTimesJSON []byte
+	var weightUnit, lengthUnit sql.NullString
+	var goalWeight, heightCm sql.NullFloat64
+	var sex, activityLevel, weightGoal sql.NullString
+	var birthDate sql.NullTime
+	var allergens, diets []byte
+	var restrictionMode sql.NullString
 
 	err := r.db.QueryRow(query, userID).Scan(
 		&prefs.ID,
@@ -121,6 +197,23 @@ func (r *userPreferencesRepository) GetPreferences(userID int64) (*model.UserPre
 		&prefs.DailyCarbsGoal,
 		&prefs.DailyFatGoal,
 		&prefs.DailyFiberGoal,
+		&prefs.DailyWaterGoalML,
+		&planReconcileTime,
+		&timezone,
+		&mealTimesJSON,
+		&weightUnit,
+		&lengthUnit,
+		&goalWeight,
+		&sex,
+		&birthDate,
+		&heightCm,
+		&activityLevel,
+		&weightGoal,
+		&prefs.AdaptiveTDEE,
+		&prefs.ExerciseFlexPct,
+		&allergens,
+		&diets,
+		&restrictionMode,
 		&prefs.CreatedAt,
 		&prefs.UpdatedAt,
 	)
@@ -132,5 +225,46 @@ func (r *userPreferencesRepository) GetPreferences(userID int64) (*model.UserPre
 		return nil, fmt.Errorf("failed to get preferences: %w", err)
 	}
 
+	if planReconcileTime.Valid {
+		prefs.PlanReconcileTime = planReconcileTime.String
+	}
+	if timezone.Valid {
+		prefs.Timezone = timezone.String
+	}
+	prefs.WeightUnit = weightUnit.String
+	prefs.LengthUnit = lengthUnit.String
+	if goalWeight.Valid {
+		prefs.GoalWeightKg = &goalWeight.Float64
+	}
+	prefs.Sex = sex.String
+	if birthDate.Valid {
+		prefs.BirthDate = birthDate.Time.Format("2006-01-02")
+	}
+	prefs.HeightCm = nullFloatPtr(heightCm)
+	prefs.ActivityLevel = activityLevel.String
+	prefs.WeightGoal = weightGoal.String
+	prefs.Allergens = unmarshalStringList(allergens)
+	prefs.Diets = unmarshalStringList(diets)
+	prefs.RestrictionMode = restrictionMode.String
+	if len(mealTimesJSON) > 0 {
+		// 旧版本数据格式可能不同，解析失败时忽略
+		var mealTimes map[string]string
+		if err := json.Unmarshal(mealTimesJSON, &mealTimes); err == nil {
+			prefs.PreferredMealTimes = mealTimes
+		}
+	}
+
 	return &prefs, nil
 }
+
+// marshalMealTimes 将偏好用餐时间序列化为 JSON，为空时写入 NULL
+func marshalMealTimes(mealTimes map[string]string) (interface{}, error) {
+	if len(mealTimes) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(mealTimes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal preferred meal times: %w", err)
+	}
+	return data, nil
+}
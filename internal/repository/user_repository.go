@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
@@ -29,8 +30,25 @@ type UserRepository interface {
 	UpdatePasswordWithVersion(ctx context.Context, userID int64, newPasswordHash string, passwordVersion int64) error
 	CheckUsernameExists(ctx context.Context, username string) (bool, error)
 	GetUserCount(ctx context.Context) (int64, error)
+	ListUsers(ctx context.Context, filter *model.UserFilter) ([]*model.User, int, error)
+	GetUserUsage(ctx context.Context, userID int64) (*model.UserUsage, error)
+	UpdateRole(ctx context.Context, userID int64, role string) error
+	SetDisabledAt(ctx context.Context, userID int64, disabledAt *time.Time) error
+	DeleteUser(ctx context.Context, userID int64) error
+	ScheduleDeletion(ctx context.Context, userID int64, requestedAt, scheduledAt time.Time) error
+	CancelDeletion(ctx context.Context, userID int64) (bool, error)
+	ListPendingDeletions(ctx context.Context) ([]*model.User, error)
+	CountActiveAdmins(ctx context.Context) (int, error)
+	ListDueDeletions(ctx context.Context, now time.Time, limit int) ([]int64, error)
+	DeleteScheduledUser(ctx context.Context, userID int64, now time.Time) error
+	ListUsersByVerifiedEmail(ctx context.Context, email string) ([]*model.User, error)
+	MarkEmailVerified(ctx context.Context, userID int64, email string) (bool, error)
 }
 
+// userColumns 查询用户时选择的列，顺序与 scanUser 一致
+const userColumns = `id, username, password_hash, password_version, email, email_verified_at, role, disabled_at,
+	deletion_requested_at, deletion_scheduled_at, created_at, updated_at`
+
 // userRepository 用户仓储实现
 type userRepository struct {
 	db *sql.DB
@@ -100,25 +118,9 @@ func (r *userRepository) CreateUser(ctx context.Context, user *model.User, passw
 // GetUserByUsername 根据用户名获取用户
 func (r *userRepository) GetUserByUsername(ctx context.Context, username string) (*model.User, error) {
 	// 使用预编译语句防止 SQL 注入
-	query := `
-		SELECT id, username, password_hash, password_version, email, role, created_at, updated_at
-		FROM users
-		WHERE username = ?
-	`
-
-	user := &model.User{}
-	var role sql.NullString
-	err := r.db.QueryRowContext(ctx, query, username).Scan(
-		&user.ID,
-		&user.Username,
-		&user.PasswordHash,
-		&user.PasswordVersion,
-		&user.Email,
-		&role,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	query := `SELECT ` + userColumns + ` FROM users WHERE username = ?`
 
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, username))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrUserNotFound
@@ -126,53 +128,327 @@ func (r *userRepository) GetUserByUsername(ctx context.Context, username string)
 		return nil, fmt.Errorf("failed to get user by username: %w", err)
 	}
 
-	// 处理可能为 NULL 的 role 字段
-	if role.Valid {
-		user.Role = role.String
-	} else {
-		user.Role = model.RoleUser
-	}
-
 	return user, nil
 }
 
 // GetUserByID 根据用户 ID 获取用户
 func (r *userRepository) GetUserByID(ctx context.Context, userID int64) (*model.User, error) {
 	// 使用预编译语句防止 SQL 注入
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = ?`
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user by id: %w", err)
+	}
+
+	return user, nil
+}
+
+// ListUsers 获取用户列表（支持搜索、筛选和分页）
+func (r *userRepository) ListUsers(ctx context.Context, filter *model.UserFilter) ([]*model.User, int, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.Query != "" {
+		conditions = append(conditions, "(username LIKE ? OR email LIKE ?)")
+		pattern := "%" + escapeLike(filter.Query) + "%"
+		args = append(args, pattern, pattern)
+	}
+
+	if filter.Role != "" {
+		conditions = append(conditions, "role = ?")
+		args = append(args, filter.Role)
+	}
+
+	switch filter.Status {
+	case model.UserStatusActive:
+		conditions = append(conditions, "disabled_at IS NULL")
+	case model.UserStatusDisabled:
+		conditions = append(conditions, "disabled_at IS NOT NULL")
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// 查询总数
+	var total int
+	countQuery := "SELECT COUNT(*) FROM users " + whereClause
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	// 查询数据
+	query := `SELECT ` + userColumns + ` FROM users ` + whereClause + ` ORDER BY id ASC LIMIT ? OFFSET ?`
+	args = append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]*model.User, 0)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return users, total, nil
+}
+
+// GetUserUsage 统计用户拥有的数据量和最近活动时间
+func (r *userRepository) GetUserUsage(ctx context.Context, userID int64) (*model.UserUsage, error) {
 	query := `
-		SELECT id, username, password_hash, password_version, email, role, created_at, updated_at
-		FROM users
-		WHERE id = ?
+		SELECT
+			(SELECT COUNT(*) FROM foods WHERE user_id = u.id),
+			(SELECT COUNT(*) FROM meals WHERE user_id = u.id),
+			(SELECT COUNT(*) FROM plans WHERE user_id = u.id),
+			(SELECT COUNT(*) FROM conversation_flows WHERE user_id = u.id),
+			(SELECT COUNT(*) FROM messages m JOIN conversation_flows cf ON cf.id = m.conversation_id WHERE cf.user_id = u.id),
+			(SELECT COUNT(*) FROM activities WHERE user_id = u.id),
+			(SELECT COUNT(*) FROM water_logs WHERE user_id = u.id),
+			(SELECT COUNT(*) FROM body_metrics WHERE user_id = u.id),
+			(SELECT MAX(meal_date) FROM meals WHERE user_id = u.id),
+			(SELECT MAX(attempted_at) FROM login_attempts WHERE username = u.username AND success = TRUE)
+		FROM users u
+		WHERE u.id = ?
 	`
 
-	user := &model.User{}
-	var role sql.NullString
+	usage := &model.UserUsage{}
+	var lastMealDate, lastLoginAt sql.NullTime
 	err := r.db.QueryRowContext(ctx, query, userID).Scan(
-		&user.ID,
-		&user.Username,
-		&user.PasswordHash,
-		&user.PasswordVersion,
-		&user.Email,
-		&role,
-		&user.CreatedAt,
-		&user.UpdatedAt,
+		&usage.FoodCount,
+		&usage.MealCount,
+		&usage.PlanCount,
+		&usage.ConversationCount,
+		&usage.MessageCount,
+		&usage.ActivityCount,
+		&usage.WaterLogCount,
+		&usage.BodyMetricCount,
+		&lastMealDate,
+		&lastLoginAt,
 	)
-
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrUserNotFound
 		}
-		return nil, fmt.Errorf("failed to get user by id: %w", err)
+		return nil, fmt.Errorf("failed to get user usage: %w", err)
 	}
 
-	// 处理可能为 NULL 的 role 字段
-	if role.Valid {
-		user.Role = role.String
-	} else {
-		user.Role = model.RoleUser
+	if lastMealDate.Valid {
+		usage.LastMealDate = &lastMealDate.Time
+	}
+	if lastLoginAt.Valid {
+		usage.LastLoginAt = &lastLoginAt.Time
 	}
 
-	return user, nil
+	return usage, nil
+}
+
+// UpdateRole 更新用户角色
+func (r *userRepository) UpdateRole(ctx context.Context, userID int64, role string) error {
+	query := `UPDATE users SET role = ?, updated_at = ? WHERE id = ?`
+
+	if _, err := r.db.ExecContext(ctx, query, role, time.Now(), userID); err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+
+	return nil
+}
+
+// SetDisabledAt 停用（传入停用时间）或启用（传入 nil）用户
+func (r *userRepository) SetDisabledAt(ctx context.Context, userID int64, disabledAt *time.Time) error {
+	query := `UPDATE users SET disabled_at = ?, updated_at = ? WHERE id = ?`
+
+	if _, err := r.db.ExecContext(ctx, query, disabledAt, time.Now(), userID); err != nil {
+		return fmt.Errorf("failed to update user status: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteUser 删除用户及其所有数据
+func (r *userRepository) DeleteUser(ctx context.Context, userID int64) error {
+	return r.deleteUser(ctx, userID, nil)
+}
+
+// ScheduleDeletion 记录用户的账户删除申请
+func (r *userRepository) ScheduleDeletion(ctx context.Context, userID int64, requestedAt, scheduledAt time.Time) error {
+	query := `UPDATE users SET deletion_requested_at = ?, deletion_scheduled_at = ?, updated_at = ? WHERE id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, requestedAt, scheduledAt, time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to schedule account deletion: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// CancelDeletion 取消用户的账户删除申请，返回是否存在待删除的申请
+func (r *userRepository) CancelDeletion(ctx context.Context, userID int64) (bool, error) {
+	query := `
+		UPDATE users
+		SET deletion_requested_at = NULL, deletion_scheduled_at = NULL, updated_at = ?
+		WHERE id = ? AND deletion_scheduled_at IS NOT NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel account deletion: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// ListPendingDeletions 获取已申请删除账户的用户，最先到期的在前
+func (r *userRepository) ListPendingDeletions(ctx context.Context) ([]*model.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users
+		WHERE deletion_scheduled_at IS NOT NULL
+		ORDER BY deletion_scheduled_at ASC, id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending deletions: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]*model.User, 0)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return users, nil
+}
+
+// CountActiveAdmins 统计未停用且未申请删除账户的管理员数量
+func (r *userRepository) CountActiveAdmins(ctx context.Context) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM users
+		WHERE role = ? AND disabled_at IS NULL AND deletion_scheduled_at IS NULL
+	`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, model.RoleAdmin).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count admins: %w", err)
+	}
+
+	return count, nil
+}
+
+// ListDueDeletions 获取删除时间已到的用户 ID
+func (r *userRepository) ListDueDeletions(ctx context.Context, now time.Time, limit int) ([]int64, error) {
+	query := `
+		SELECT id FROM users
+		WHERE deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?
+		ORDER BY deletion_scheduled_at ASC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due deletions: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan user id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user ids: %w", err)
+	}
+
+	return ids, nil
+}
+
+// DeleteScheduledUser 永久删除删除时间已到的用户
+// 删除前锁定用户并重新检查删除时间，用户在此期间登录取消了删除时返回 ErrUserNotFound
+func (r *userRepository) DeleteScheduledUser(ctx context.Context, userID int64, now time.Time) error {
+	return r.deleteUser(ctx, userID, &now)
+}
+
+// deleteUser 在一个事务中删除用户及其所有数据
+// 用户拥有的数据通过外键级联删除；API 日志和登录记录没有外键，在同一事务中删除。
+// scheduledBefore 不为空时只删除在该时间之前到期的账户删除申请
+func (r *userRepository) deleteUser(ctx context.Context, userID int64, scheduledBefore *time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `SELECT username FROM users WHERE id = ?`
+	args := []interface{}{userID}
+	if scheduledBefore != nil {
+		query += ` AND deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?`
+		args = append(args, *scheduledBefore)
+	}
+	query += ` FOR UPDATE`
+
+	var username string
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&username); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to lock user: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM api_logs WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to delete api logs: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM login_attempts WHERE username = ?`, username); err != nil {
+		return fmt.Errorf("failed to delete login attempts: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
 }
 
 // UpdatePassword 更新用户密码
@@ -253,6 +529,102 @@ func (r *userRepository) GetUserCount(ctx context.Context) (int64, error) {
 	return count, nil
 }
 
+// ListUsersByVerifiedEmail 获取已验证指定邮箱的用户（邮箱不要求唯一，可能有多个）
+func (r *userRepository) ListUsersByVerifiedEmail(ctx context.Context, email string) ([]*model.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = ? AND email_verified_at IS NOT NULL ORDER BY id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users by email: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]*model.User, 0)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return users, nil
+}
+
+// MarkEmailVerified 标记用户邮箱已验证
+// 只有用户当前邮箱仍为 email 时才会更新，返回 false 表示邮箱已变更
+func (r *userRepository) MarkEmailVerified(ctx context.Context, userID int64, email string) (bool, error) {
+	query := `UPDATE users SET email_verified_at = ?, updated_at = ? WHERE id = ? AND email = ?`
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query, now, now, userID, email)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark email verified: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// scanUser 扫描一行用户数据
+func scanUser(row rowScanner) (*model.User, error) {
+	user := &model.User{}
+	var role sql.NullString
+	var emailVerifiedAt, disabledAt, deletionRequestedAt, deletionScheduledAt sql.NullTime
+	err := row.Scan(
+		&user.ID,
+		&user.Username,
+		&user.PasswordHash,
+		&user.PasswordVersion,
+		&user.Email,
+		&emailVerifiedAt,
+		&role,
+		&disabledAt,
+		&deletionRequestedAt,
+		&deletionScheduledAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// 处理可能为 NULL 的 role 字段
+	if role.Valid {
+		user.Role = role.String
+	} else {
+		user.Role = model.RoleUser
+	}
+
+	if emailVerifiedAt.Valid {
+		user.EmailVerifiedAt = &emailVerifiedAt.Time
+	}
+	if disabledAt.Valid {
+		user.DisabledAt = &disabledAt.Time
+	}
+	if deletionRequestedAt.Valid {
+		user.DeletionRequestedAt = &deletionRequestedAt.Time
+	}
+	if deletionScheduledAt.Valid {
+		user.DeletionScheduledAt = &deletionScheduledAt.Time
+	}
+
+	return user, nil
+}
+
+// escapeLike 转义 LIKE 模式中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
 // isDuplicateKeyError 检查是否是唯一键冲突错误
 func isDuplicateKeyError(err error) bool {
 	// MySQL 错误码 1062 表示唯一键冲突
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+)
+
+// WaterLogRepository handles water log data access operations
+type WaterLogRepository struct {
+	db *sql.DB
+}
+
+// NewWaterLogRepository creates a new WaterLogRepository instance
+func NewWaterLogRepository(db *sql.DB) *WaterLogRepository {
+	return &WaterLogRepository{db: db}
+}
+
+const waterLogColumns = `id, user_id, log_date, logged_at, amount_ml, beverage_type, notes, created_at, updated_at`
+
+// CreateWaterLog creates a new water log entry
+func (r *WaterLogRepository) CreateWaterLog(log *model.WaterLog) error {
+	query := `
+		INSERT INTO water_logs (user_id, log_date, logged_at, amount_ml, beverage_type, notes)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.Exec(
+		query,
+		log.UserID,
+		dateArg(log.LogDate),
+		log.LoggedAt,
+		log.AmountML,
+		log.BeverageType,
+		log.Notes,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create water log: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	log.ID = id
+	return nil
+}
+
+// UpdateWaterLog updates an existing water log entry
+func (r *WaterLogRepository) UpdateWaterLog(userID, logID int64, log *model.WaterLog) error {
+	query := `
+		UPDATE water_logs
+		SET log_date = ?, logged_at = ?, amount_ml = ?, beverage_type = ?, notes = ?
+		WHERE id = ? AND user_id = ?
+	`
+
+	result, err := r.db.Exec(
+		query,
+		dateArg(log.LogDate),
+		log.LoggedAt,
+		log.AmountML,
+		log.BeverageType,
+		log.Notes,
+		logID,
+		userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update water log: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("water log not found or access denied")
+	}
+
+	return nil
+}
+
+// DeleteWaterLog deletes a water log entry
+func (r *WaterLogRepository) DeleteWaterLog(userID, logID int64) error {
+	query := `DELETE FROM water_logs WHERE id = ? AND user_id = ?`
+
+	result, err := r.db.Exec(query, logID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete water log: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("water log not found or access denied")
+	}
+
+	return nil
+}
+
+// GetWaterLogByID retrieves a water log entry by ID
+func (r *WaterLogRepository) GetWaterLogByID(userID, logID int64) (*model.WaterLog, error) {
+	query := `
+		SELECT ` + waterLogColumns + `
+		FROM water_logs
+		WHERE id = ? AND user_id = ?
+	`
+
+	logs, err := r.queryWaterLogs(query, logID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(logs) == 0 {
+		return nil, fmt.Errorf("water log not found or access denied")
+	}
+
+	return logs[0], nil
+}
+
+// GetWaterLogsByDateRange retrieves water log entries within a date range (inclusive)
+func (r *WaterLogRepository) GetWaterLogsByDateRange(userID int64, startDate, endDate time.Time) ([]*model.WaterLog, error) {
+	query := `
+		SELECT ` + waterLogColumns + `
+		FROM water_logs
+		WHERE user_id = ? AND log_date BETWEEN ? AND ?
+		ORDER BY log_date ASC, logged_at ASC, id ASC
+	`
+
+	return r.queryWaterLogs(query, userID, dateArg(startDate), dateArg(endDate))
+}
+
+// scanWaterLog scans a row selecting waterLogColumns
+func scanWaterLog(row rowScanner) (*model.WaterLog, error) {
+	log := &model.WaterLog{}
+	var loggedAt sql.NullTime
+	var notes sql.NullString
+
+	err := row.Scan(
+		&log.ID,
+		&log.UserID,
+		&log.LogDate,
+		&loggedAt,
+		&log.AmountML,
+		&log.BeverageType,
+		&notes,
+		&log.CreatedAt,
+		&log.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if loggedAt.Valid {
+		log.LoggedAt = &loggedAt.Time
+	}
+	log.Notes = notes.String
+	return log, nil
+}
+
+// queryWaterLogs runs a water log query and scans the results
+func (r *WaterLogRepository) queryWaterLogs(query string, args ...interface{}) ([]*model.WaterLog, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query water logs: %w", err)
+	}
+	defer rows.Close()
+
+	logs := make([]*model.WaterLog, 0)
+	for rows.Next() {
+		log, err := scanWaterLog(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan water log: %w", err)
+		}
+		logs = append(logs, log)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating water logs: %w", err)
+	}
+
+	return logs, nil
+}
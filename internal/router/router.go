@@ -6,6 +6,7 @@ import (
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/config"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/handler"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/middleware"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
 	"github.com/gin-gonic/gin"
@@ -24,6 +25,22 @@ type Handlers struct {
 	Settings     *handler.SettingsHandler
 	Conversation *handler.ConversationHandler
 	Message      *handler.MessageHandler
+	Fasting      *handler.FastingHandler
+	BodyMetric   *handler.BodyMetricHandler
+	Energy       *handler.EnergyHandler
+	Goal         *handler.GoalHandler
+	Activity     *handler.ActivityHandler
+	Hydration    *handler.HydrationHandler
+	FoodCategory *handler.FoodCategoryHandler
+	Admin        *handler.AdminHandler
+	TwoFactor    *handler.TwoFactorHandler
+	Session      *handler.SessionHandler
+	APIToken     *handler.APITokenHandler
+	Email        *handler.EmailHandler
+	OIDC         *handler.OIDCHandler
+	AccountData  *handler.AccountDataHandler
+	Account      *handler.AccountHandler
+	Audit        *handler.AuditHandler
 }
 
 // SetupRouter 设置路由
@@ -39,7 +56,9 @@ type Handlers struct {
 //     router.POST("/upload", middleware.FileValidationMiddleware(uploadConfig, logger), handler)
 func SetupRouter(cfg *config.Config, logger *zap.Logger, jwtService *utils.JWTService, authService interface {
 	ValidateToken(ctx context.Context, token string) (*utils.Claims, error)
-}, handlers *Handlers, userRepo repository.UserRepository) *gin.Engine {
+}, apiTokenService interface {
+	Authenticate(ctx context.Context, token, ipAddress string) (*model.APIToken, error)
+}, handlers *Handlers, userRepo repository.UserRepository, userPrefsRepo repository.UserPreferencesRepository, apiLogRepo repository.APILogRepository) *gin.Engine {
 	// 设置 Gin 模式
 	gin.SetMode(cfg.Server.Mode)
 
@@ -48,6 +67,8 @@ func SetupRouter(cfg *config.Config, logger *zap.Logger, jwtService *utils.JWTSe
 
 	// 注册全局中间件
 	router.Use(middleware.RecoveryMiddleware(logger))
+	// 将客户端 IP 和用户代理写入请求 context，供审计日志使用
+	router.Use(middleware.RequestContextMiddleware())
 	router.Use(middleware.LoggerMiddleware(logger))
 	router.Use(middleware.RateLimitMiddleware(&cfg.RateLimit, &cfg.Redis, logger))
 	// 全局请求体大小限制（10MB）
@@ -65,10 +86,18 @@ func SetupRouter(cfg *config.Config, logger *zap.Logger, jwtService *utils.JWTSe
 
 	// API v1 路由组
 	v1 := router.Group("/api/v1")
+	// 记录 API 请求日志（异步写入 api_logs 表）
+	v1.Use(middleware.APILogMiddleware(apiLogRepo, logger))
 	{
 		// 认证路由（不需要认证中间件）
 		handlers.Auth.RegisterRoutes(v1)
 
+		// 邮箱验证和找回密码路由（不需要认证）
+		handlers.Email.RegisterPublicRoutes(v1)
+
+		// 单点登录路由（不需要认证）
+		handlers.OIDC.RegisterPublicRoutes(v1)
+
 		// 公开的系统信息路由（不需要认证）
 		system := v1.Group("/system")
 		{
@@ -77,34 +106,92 @@ func SetupRouter(cfg *config.Config, logger *zap.Logger, jwtService *utils.JWTSe
 
 		// 需要认证的路由
 		authenticated := v1.Group("")
-		authenticated.Use(middleware.AuthMiddleware(jwtService, authService))
+		authenticated.Use(middleware.AuthMiddleware(jwtService, authService, apiTokenService))
+		// 加载用户时区，供日期解析和统计使用
+		authenticated.Use(middleware.UserLocationMiddleware(userPrefsRepo))
 		{
+			// 个人访问令牌按路由组检查权限范围：GET 需要 read 或对应的写权限，修改操作需要对应的写权限
+			// JWT 认证的请求不受权限范围限制
+			scoped := func(writeScope string) *gin.RouterGroup {
+				return authenticated.Group("", middleware.RequireScope(writeScope))
+			}
+			// 账户相关的路由只允许 JWT 认证，个人访问令牌无法访问
+			jwtOnly := authenticated.Group("", middleware.RejectAPIToken())
+
 			// 食材管理路由
-			handlers.Food.RegisterRoutes(authenticated)
+			handlers.Food.RegisterRoutes(scoped(model.APITokenScopeFoodsWrite))
 
 			// 餐饮记录路由
-			handlers.Meal.RegisterRoutes(authenticated)
+			handlers.Meal.RegisterRoutes(scoped(model.APITokenScopeMealsWrite))
 
 			// 饮食计划路由
-			handlers.Plan.RegisterRoutes(authenticated)
+			handlers.Plan.RegisterRoutes(scoped(model.APITokenScopePlansWrite))
 
 			// AI 服务路由
-			handlers.AI.RegisterRoutes(authenticated)
+			handlers.AI.RegisterRoutes(scoped(model.APITokenScopeAIWrite))
 
-			// 营养分析路由
-			handlers.Nutrition.RegisterRoutes(authenticated)
+			// 营养分析路由（令牌只读）
+			handlers.Nutrition.RegisterRoutes(scoped(""))
 
-			// Dashboard 路由
-			handlers.Dashboard.RegisterRoutes(authenticated)
+			// Dashboard 路由（令牌只读）
+			handlers.Dashboard.RegisterRoutes(scoped(""))
 
 			// 设置管理路由
-			handlers.Settings.RegisterRoutes(authenticated, userRepo)
+			handlers.Settings.RegisterRoutes(jwtOnly, userRepo)
+
+			// 个人访问令牌管理路由
+			handlers.APIToken.RegisterRoutes(jwtOnly)
 
 			// 对话流管理路由
-			handlers.Conversation.RegisterRoutes(authenticated)
+			handlers.Conversation.RegisterRoutes(scoped(model.APITokenScopeAIWrite))
 
 			// 消息代理路由
-			handlers.Message.RegisterRoutes(authenticated)
+			handlers.Message.RegisterRoutes(scoped(model.APITokenScopeAIWrite))
+
+			// 间歇性断食路由
+			handlers.Fasting.RegisterRoutes(scoped(model.APITokenScopeFastingWrite))
+
+			// 身体指标路由
+			handlers.BodyMetric.RegisterRoutes(scoped(model.APITokenScopeBodyMetricsWrite))
+
+			// 能量消耗估算路由
+			handlers.Energy.RegisterRoutes(scoped(model.APITokenScopeGoalsWrite))
+
+			// 营养目标历史路由
+			handlers.Goal.RegisterRoutes(scoped(model.APITokenScopeGoalsWrite))
+
+			// 运动记录路由
+			handlers.Activity.RegisterRoutes(scoped(model.APITokenScopeActivitiesWrite))
+
+			// 饮水记录路由
+			handlers.Hydration.RegisterRoutes(scoped(model.APITokenScopeHydrationWrite))
+
+			// 食材分类路由
+			handlers.FoodCategory.RegisterRoutes(scoped(model.APITokenScopeFoodsWrite))
+
+			// 用户管理路由（需要管理员权限）
+			handlers.Admin.RegisterRoutes(jwtOnly, userRepo)
+
+			// 两步验证路由
+			handlers.TwoFactor.RegisterRoutes(jwtOnly)
+
+			// 登录会话管理路由
+			handlers.Session.RegisterRoutes(jwtOnly)
+
+			// 重新发送邮箱验证邮件路由
+			handlers.Email.RegisterRoutes(jwtOnly)
+
+			// 外部身份关联路由
+			handlers.OIDC.RegisterRoutes(jwtOnly)
+
+			// 账户数据导出和导入路由
+			handlers.AccountData.RegisterRoutes(jwtOnly)
+
+			// 账户删除路由
+			handlers.Account.RegisterRoutes(jwtOnly)
+
+			// 审计日志路由（需要管理员权限）
+			handlers.Audit.RegisterRoutes(jwtOnly, userRepo)
 		}
 	}
 
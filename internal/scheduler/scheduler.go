@@ -0,0 +1,142 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// JobFunc 后台任务函数
+type JobFunc func(ctx context.Context) error
+
+// job 已注册的后台任务
+type job struct {
+	name     string
+	interval time.Duration
+	run      JobFunc
+}
+
+// Scheduler 简单的周期性后台任务调度器
+// 每个任务在独立的 goroutine 中按固定间隔执行，随应用生命周期启动和停止
+type Scheduler struct {
+	logger *zap.Logger
+	jobs   []*job
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started bool
+}
+
+// New 创建调度器实例
+func New(logger *zap.Logger) *Scheduler {
+	return &Scheduler{
+		logger: logger,
+	}
+}
+
+// Register 注册周期性任务（必须在 Start 之前调用）
+func (s *Scheduler) Register(name string, interval time.Duration, run JobFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs = append(s.jobs, &job{
+		name:     name,
+		interval: interval,
+		run:      run,
+	})
+}
+
+// Start 启动所有已注册的任务
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.started = true
+
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+
+	s.logger.Info("Scheduler started", zap.Int("jobs", len(s.jobs)))
+}
+
+// Stop 停止所有任务并等待正在执行的任务结束
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.started {
+		s.mu.Unlock()
+		return nil
+	}
+	s.cancel()
+	s.started = false
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.logger.Info("Scheduler stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// loop 任务执行循环：启动时立即执行一次，之后按间隔执行
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	s.runOnce(ctx, j)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runOnce(ctx, j)
+		}
+	}
+}
+
+// runOnce 执行一次任务，捕获 panic 避免影响其他任务
+func (s *Scheduler) runOnce(ctx context.Context, j *job) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("Scheduled job panicked",
+				zap.String("job", j.name),
+				zap.Any("panic", r),
+			)
+		}
+	}()
+
+	startTime := time.Now()
+	if err := j.run(ctx); err != nil {
+		s.logger.Error("Scheduled job failed",
+			zap.String("job", j.name),
+			zap.Error(err),
+		)
+		return
+	}
+
+	s.logger.Debug("Scheduled job finished",
+		zap.String("job", j.name),
+		zap.Duration("duration", time.Since(startTime)),
+	)
+}
@@ -0,0 +1,756 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// accountExportTimeout 生成导出文件的最长时间，超过该时间仍未完成的任务视为失败
+	accountExportTimeout = 30 * time.Minute
+	// accountArchiveMaxSize ZIP 归档中账户数据文件解压后的最大大小
+	accountArchiveMaxSize = 256 << 20
+)
+
+var (
+	// ErrAccountExportInProgress 已有正在生成的导出任务
+	ErrAccountExportInProgress = errors.New("an account export is already in progress")
+	// ErrAccountExportNotFound 导出任务不存在或已过期
+	ErrAccountExportNotFound = errors.New("account export not found")
+	// ErrAccountExportNotReady 导出文件尚未生成或生成失败
+	ErrAccountExportNotReady = errors.New("account export is not ready")
+	// ErrInvalidAccountArchive 导入的归档格式错误或内容无效
+	ErrInvalidAccountArchive = errors.New("invalid account archive")
+	// ErrUnsupportedArchiveVersion 归档由更新版本的系统导出，当前版本无法导入
+	ErrUnsupportedArchiveVersion = errors.New("unsupported account archive version")
+	// ErrAccountNotEmpty 目标账户已有数据，只能导入到新账户或空账户
+	ErrAccountNotEmpty = errors.New("account already has data")
+)
+
+// archiveMealTypes 有效的餐次
+var archiveMealTypes = map[string]bool{"breakfast": true, "lunch": true, "dinner": true, "snack": true}
+
+// archivePlanStatuses 有效的计划状态
+var archivePlanStatuses = map[string]bool{"pending": true, "completed": true, "skipped": true}
+
+// archiveAIProviders 有效的 AI 提供商
+var archiveAIProviders = map[string]bool{"openai": true, "deepseek": true, "custom": true}
+
+// archiveFastStatuses 有效的断食状态
+var archiveFastStatuses = map[string]bool{
+	model.FastStatusActive:    true,
+	model.FastStatusCompleted: true,
+	model.FastStatusBroken:    true,
+}
+
+// archiveIntensities 有效的运动强度
+var archiveIntensities = map[string]bool{
+	model.IntensityLow:      true,
+	model.IntensityModerate: true,
+	model.IntensityHigh:     true,
+}
+
+// AccountDataService 账户数据导出和导入服务接口
+type AccountDataService interface {
+	CreateExport(ctx context.Context, userID int64, format string) (*model.AccountExport, error)
+	ListExports(ctx context.Context, userID int64) ([]*model.AccountExport, error)
+	GetExport(ctx context.Context, userID, exportID int64) (*model.AccountExport, error)
+	GetExportFile(ctx context.Context, userID, exportID int64) (*model.AccountExport, string, error)
+	ImportAccount(ctx context.Context, userID int64, data []byte) (*model.AccountImportResult, error)
+	CleanupExports(ctx context.Context) error
+}
+
+// accountDataService 账户数据导出和导入服务实现
+// 记录数不超过 asyncThreshold 时在请求中直接生成导出文件，否则在后台生成，完成后通过任务状态查询；
+// 导出文件保存在 exportPath 目录中，过期后由 CleanupExports 删除
+type accountDataService struct {
+	accountDataRepo repository.AccountDataRepository
+	exportRepo      repository.AccountExportRepository
+	userRepo        repository.UserRepository
+	settingsService SettingsService
+	auditService    AuditService
+	exportPath      string
+	asyncThreshold  int
+	exportTTL       time.Duration
+	logger          *zap.Logger
+}
+
+// NewAccountDataService 创建账户数据导出和导入服务实例
+func NewAccountDataService(
+	accountDataRepo repository.AccountDataRepository,
+	exportRepo repository.AccountExportRepository,
+	userRepo repository.UserRepository,
+	settingsService SettingsService,
+	auditService AuditService,
+	exportPath string,
+	asyncThreshold int,
+	exportTTL time.Duration,
+	logger *zap.Logger,
+) AccountDataService {
+	return &accountDataService{
+		accountDataRepo: accountDataRepo,
+		exportRepo:      exportRepo,
+		userRepo:        userRepo,
+		settingsService: settingsService,
+		auditService:    auditService,
+		exportPath:      exportPath,
+		asyncThreshold:  asyncThreshold,
+		exportTTL:       exportTTL,
+		logger:          logger,
+	}
+}
+
+// CreateExport 创建账户数据导出任务，每个用户同时只能有一个正在生成的任务
+func (s *accountDataService) CreateExport(ctx context.Context, userID int64, format string) (*model.AccountExport, error) {
+	if format == "" {
+		format = model.AccountExportFormatJSON
+	}
+
+	now := time.Now()
+	active, err := s.exportRepo.HasActiveExport(ctx, userID, now.Add(-accountExportTimeout))
+	if err != nil {
+		return nil, err
+	}
+	if active {
+		return nil, ErrAccountExportInProgress
+	}
+
+	recordCount, err := s.accountDataRepo.CountRecords(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &model.AccountExport{
+		UserID:      userID,
+		Format:      format,
+		Status:      model.AccountExportStatusPending,
+		RecordCount: recordCount,
+		ExpiresAt:   now.Add(s.exportTTL),
+	}
+	if err := s.exportRepo.CreateExport(ctx, export); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, &model.AuditLog{
+		Action:       model.AuditActionAccountExport,
+		ResourceType: model.AuditResourceAccountExport,
+		ResourceID:   strconv.FormatInt(export.ID, 10),
+		Details:      map[string]any{"format": format, "records": recordCount},
+	})
+
+	if recordCount <= s.asyncThreshold {
+		if err := s.generateExport(ctx, export); err != nil {
+			return nil, err
+		}
+		return export, nil
+	}
+
+	go func(export model.AccountExport) {
+		ctx, cancel := context.WithTimeout(context.Background(), accountExportTimeout)
+		defer cancel()
+
+		if err := s.generateExport(ctx, &export); err == nil {
+			s.logger.Info("Account export generated",
+				zap.Int64("user_id", export.UserID),
+				zap.Int64("export_id", export.ID),
+				zap.Int("records", export.RecordCount),
+			)
+		}
+	}(*export)
+
+	return export, nil
+}
+
+// ListExports 获取用户未过期的导出任务
+func (s *accountDataService) ListExports(ctx context.Context, userID int64) ([]*model.AccountExport, error) {
+	return s.exportRepo.ListExports(ctx, userID)
+}
+
+// GetExport 获取导出任务
+func (s *accountDataService) GetExport(ctx context.Context, userID, exportID int64) (*model.AccountExport, error) {
+	export, err := s.exportRepo.GetExport(ctx, userID, exportID)
+	if err != nil {
+		if errors.Is(err, repository.ErrAccountExportNotFound) {
+			return nil, ErrAccountExportNotFound
+		}
+		return nil, err
+	}
+
+	if !time.Now().Before(export.ExpiresAt) {
+		return nil, ErrAccountExportNotFound
+	}
+
+	return export, nil
+}
+
+// GetExportFile 获取已完成的导出任务及其文件路径
+func (s *accountDataService) GetExportFile(ctx context.Context, userID, exportID int64) (*model.AccountExport, string, error) {
+	export, err := s.GetExport(ctx, userID, exportID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if export.Status != model.AccountExportStatusCompleted {
+		return nil, "", ErrAccountExportNotReady
+	}
+
+	path := filepath.Join(s.exportPath, export.FileName)
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, "", ErrAccountExportNotFound
+		}
+		return nil, "", fmt.Errorf("failed to stat export file: %w", err)
+	}
+
+	s.auditService.Record(ctx, &model.AuditLog{
+		Action:       model.AuditActionAccountExportDownload,
+		ResourceType: model.AuditResourceAccountExport,
+		ResourceID:   strconv.FormatInt(export.ID, 10),
+		Details:      map[string]any{"format": export.Format, "file_size": export.FileSize},
+	})
+
+	return export, path, nil
+}
+
+// ImportAccount 将 JSON 或 ZIP 归档导入到用户账户，账户中不能已有数据
+// 用户资料不会导入；偏好在数据导入后恢复，恢复失败时只在结果中给出警告
+func (s *accountDataService) ImportAccount(ctx context.Context, userID int64, data []byte) (*model.AccountImportResult, error) {
+	archive, err := parseAccountArchive(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateAccountArchive(archive); err != nil {
+		return nil, err
+	}
+
+	result, err := s.accountDataRepo.ImportData(ctx, userID, archive)
+	if err != nil {
+		if errors.Is(err, repository.ErrAccountDataExists) {
+			return nil, ErrAccountNotEmpty
+		}
+		return nil, err
+	}
+
+	if archive.Preferences != nil {
+		prefs := *archive.Preferences
+		prefs.ID = 0
+		if err := s.settingsService.UpdateUserPreferences(ctx, userID, &prefs); err != nil {
+			s.logger.Warn("Failed to restore imported preferences", zap.Int64("user_id", userID), zap.Error(err))
+			result.Warnings = append(result.Warnings, "preferences were not restored: "+err.Error())
+		} else {
+			result.PreferencesRestored = true
+		}
+	}
+
+	if result.AISettings > 0 {
+		result.Warnings = append(result.Warnings, "ai settings were imported without api keys and are inactive, set an api key before using them")
+	}
+	if result.UnmappedFoodRefs > 0 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("%d meal or plan items reference foods that are not in the archive, their food_id was set to 0", result.UnmappedFoodRefs))
+	}
+	if result.UnmappedCategories > 0 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("%d foods reference categories that do not exist, they were moved to '%s'", result.UnmappedCategories, model.DefaultFoodCategory))
+	}
+
+	s.auditService.Record(ctx, &model.AuditLog{
+		Action:       model.AuditActionAccountImport,
+		ResourceType: model.AuditResourceUser,
+		ResourceID:   strconv.FormatInt(userID, 10),
+		Details: map[string]any{
+			"archive_version":      archive.Version,
+			"food_categories":      result.FoodCategories,
+			"foods":                result.Foods,
+			"meals":                result.Meals,
+			"plans":                result.Plans,
+			"fasts":                result.Fasts,
+			"body_metrics":         result.BodyMetrics,
+			"activities":           result.Activities,
+			"water_logs":           result.WaterLogs,
+			"goals":                result.Goals,
+			"goal_schedules":       result.GoalSchedules,
+			"goal_overrides":       result.GoalOverrides,
+			"ai_settings":          result.AISettings,
+			"conversations":        result.Conversations,
+			"messages":             result.Messages,
+			"preferences_restored": result.PreferencesRestored,
+		},
+	})
+
+	s.logger.Info("Account data imported",
+		zap.Int64("user_id", userID),
+		zap.Int("foods", result.Foods),
+		zap.Int("meals", result.Meals),
+		zap.Int("plans", result.Plans),
+		zap.Int("fasts", result.Fasts),
+		zap.Int("body_metrics", result.BodyMetrics),
+		zap.Int("activities", result.Activities),
+		zap.Int("water_logs", result.WaterLogs),
+		zap.Int("goals", result.Goals),
+		zap.Int("conversations", result.Conversations),
+	)
+
+	return result, nil
+}
+
+// CleanupExports 将超时未完成的导出任务标记为失败，删除过期的导出任务和导出文件
+func (s *accountDataService) CleanupExports(ctx context.Context) error {
+	now := time.Now()
+
+	failed, err := s.exportRepo.FailStaleExports(ctx, now.Add(-accountExportTimeout))
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.exportRepo.DeleteExpiredExports(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	removed, err := s.removeExpiredFiles(now)
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 || deleted > 0 || removed > 0 {
+		s.logger.Info("Account exports cleaned up",
+			zap.Int64("failed", failed),
+			zap.Int64("deleted", deleted),
+			zap.Int("files_removed", removed),
+		)
+	}
+
+	return nil
+}
+
+// generateExport 生成导出文件并更新任务状态，失败时将任务标记为失败
+func (s *accountDataService) generateExport(ctx context.Context, export *model.AccountExport) error {
+	if err := s.exportRepo.MarkProcessing(ctx, export.ID); err != nil {
+		return err
+	}
+	export.Status = model.AccountExportStatusProcessing
+
+	fileName, fileSize, recordCount, err := s.writeExport(ctx, export)
+	if err != nil {
+		s.logger.Error("Failed to generate account export",
+			zap.Int64("user_id", export.UserID),
+			zap.Int64("export_id", export.ID),
+			zap.Error(err),
+		)
+		// 使用独立的上下文记录失败，生成超时后仍能更新状态
+		failCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if failErr := s.exportRepo.FailExport(failCtx, export.ID, "failed to generate export"); failErr != nil {
+			s.logger.Error("Failed to mark account export failed", zap.Int64("export_id", export.ID), zap.Error(failErr))
+		}
+		return err
+	}
+
+	if err := s.exportRepo.CompleteExport(ctx, export.ID, fileName, fileSize, recordCount); err != nil {
+		os.Remove(filepath.Join(s.exportPath, fileName))
+		return err
+	}
+
+	completedAt := time.Now()
+	export.Status = model.AccountExportStatusCompleted
+	export.FileName = fileName
+	export.FileSize = fileSize
+	export.RecordCount = recordCount
+	export.CompletedAt = &completedAt
+
+	return nil
+}
+
+// writeExport 读取账户数据并写入导出目录，先写入临时文件再重命名，避免下载到不完整的文件
+func (s *accountDataService) writeExport(ctx context.Context, export *model.AccountExport) (string, int64, int, error) {
+	archive, err := s.buildArchive(ctx, export.UserID)
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", 0, 0, fmt.Errorf("failed to generate file name: %w", err)
+	}
+	fileName := fmt.Sprintf("account-%d-%s.%s", export.UserID, hex.EncodeToString(suffix), export.Format)
+
+	tmp, err := os.CreateTemp(s.exportPath, ".export-*")
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := writeAccountArchive(tmp, archive, export.Format); err != nil {
+		tmp.Close()
+		return "", 0, 0, err
+	}
+
+	info, err := tmp.Stat()
+	if err != nil {
+		tmp.Close()
+		return "", 0, 0, fmt.Errorf("failed to stat export file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", 0, 0, fmt.Errorf("failed to write export file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(s.exportPath, fileName)); err != nil {
+		return "", 0, 0, fmt.Errorf("failed to save export file: %w", err)
+	}
+
+	return fileName, info.Size(), archive.RecordCount(), nil
+}
+
+// buildArchive 读取用户资料、偏好和所有账户数据
+func (s *accountDataService) buildArchive(ctx context.Context, userID int64) (*model.AccountArchive, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	prefs, err := s.settingsService.GetUserPreferences(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preferences: %w", err)
+	}
+
+	archive, err := s.accountDataRepo.ExportData(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	archive.Version = model.AccountArchiveVersion
+	archive.ExportedAt = time.Now()
+	archive.Profile = &model.ArchiveProfile{
+		Username:  user.Username,
+		Email:     user.Email,
+		CreatedAt: user.CreatedAt,
+	}
+	archive.Preferences = prefs
+
+	return archive, nil
+}
+
+// removeExpiredFiles 删除导出目录中超过保留时间的文件（包括生成中断留下的临时文件）
+func (s *accountDataService) removeExpiredFiles(now time.Time) (int, error) {
+	entries, err := os.ReadDir(s.exportPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read export directory: %w", err)
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || now.Sub(info.ModTime()) < s.exportTTL {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.exportPath, entry.Name())); err != nil {
+			s.logger.Warn("Failed to remove expired export file", zap.String("file", entry.Name()), zap.Error(err))
+			continue
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// writeAccountArchive 按格式写入归档：JSON 直接写入，ZIP 中包含一个 account.json
+func writeAccountArchive(w io.Writer, archive *model.AccountArchive, format string) error {
+	if format == model.AccountExportFormatZIP {
+		zw := zip.NewWriter(w)
+		fw, err := zw.Create(model.AccountArchiveFileName)
+		if err != nil {
+			return fmt.Errorf("failed to create zip entry: %w", err)
+		}
+		if err := encodeAccountArchive(fw, archive); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("failed to write zip archive: %w", err)
+		}
+		return nil
+	}
+
+	return encodeAccountArchive(w, archive)
+}
+
+// encodeAccountArchive 将归档编码为格式化的 JSON
+func encodeAccountArchive(w io.Writer, archive *model.AccountArchive) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(archive); err != nil {
+		return fmt.Errorf("failed to encode account archive: %w", err)
+	}
+	return nil
+}
+
+// parseAccountArchive 解析 JSON 或 ZIP 归档（按文件头识别）并检查版本
+func parseAccountArchive(data []byte) (*model.AccountArchive, error) {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		content, err := readZipArchive(data)
+		if err != nil {
+			return nil, err
+		}
+		data = content
+	}
+
+	var archive model.AccountArchive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAccountArchive, err)
+	}
+
+	if archive.Version <= 0 {
+		return nil, fmt.Errorf("%w: missing version", ErrInvalidAccountArchive)
+	}
+	if archive.Version > model.AccountArchiveVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedArchiveVersion, archive.Version)
+	}
+
+	return &archive, nil
+}
+
+// readZipArchive 读取 ZIP 归档中的 account.json，限制解压后的大小
+func readZipArchive(data []byte) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAccountArchive, err)
+	}
+
+	for _, f := range zr.File {
+		if f.Name != model.AccountArchiveFileName {
+			continue
+		}
+		if f.UncompressedSize64 > accountArchiveMaxSize {
+			return nil, fmt.Errorf("%w: %s is too large", ErrInvalidAccountArchive, f.Name)
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidAccountArchive, err)
+		}
+		defer rc.Close()
+
+		content, err := io.ReadAll(io.LimitReader(rc, accountArchiveMaxSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidAccountArchive, err)
+		}
+		if len(content) > accountArchiveMaxSize {
+			return nil, fmt.Errorf("%w: %s is too large", ErrInvalidAccountArchive, f.Name)
+		}
+		return content, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s not found in zip archive", ErrInvalidAccountArchive, model.AccountArchiveFileName)
+}
+
+// isArchiveGoalProfile 检查归档中的目标配置名称是否为日常目标或有效的配置名称
+func isArchiveGoalProfile(profile string) bool {
+	return profile == model.BaseGoalProfile || model.IsValidGoalProfileName(profile)
+}
+
+// validateAccountArchive 检查归档中的必填字段和枚举值，避免导入到一半时因数据库约束失败
+func validateAccountArchive(archive *model.AccountArchive) error {
+	categorySlugs := make(map[string]bool, len(archive.FoodCategories))
+	for i, category := range archive.FoodCategories {
+		if category == nil || !model.IsValidFoodCategorySlug(category.Slug) || category.Name == "" || len([]rune(category.Name)) > 50 {
+			return fmt.Errorf("%w: food category #%d must have a valid slug and a name of at most 50 characters", ErrInvalidAccountArchive, i+1)
+		}
+		if categorySlugs[category.Slug] {
+			return fmt.Errorf("%w: duplicate food category '%s'", ErrInvalidAccountArchive, category.Slug)
+		}
+		if category.Parent != "" && !model.IsValidFoodCategorySlug(category.Parent) {
+			return fmt.Errorf("%w: food category #%d has an invalid parent", ErrInvalidAccountArchive, i+1)
+		}
+		if category.SortOrder < 0 || category.SortOrder > 10000 {
+			return fmt.Errorf("%w: food category #%d has an invalid sort order", ErrInvalidAccountArchive, i+1)
+		}
+		categorySlugs[category.Slug] = true
+	}
+
+	for i, food := range archive.Foods {
+		if food == nil || food.Name == "" || len([]rune(food.Name)) > 100 || food.Unit == "" || len([]rune(food.Unit)) > 20 {
+			return fmt.Errorf("%w: food #%d must have a name and a unit", ErrInvalidAccountArchive, i+1)
+		}
+		if food.Category == "" {
+			food.Category = model.DefaultFoodCategory
+		}
+		if !model.IsValidFoodCategorySlug(food.Category) {
+			return fmt.Errorf("%w: food #%d has an invalid category", ErrInvalidAccountArchive, i+1)
+		}
+	}
+
+	for i, meal := range archive.Meals {
+		if meal == nil || meal.MealDate.IsZero() || !archiveMealTypes[meal.MealType] {
+			return fmt.Errorf("%w: meal #%d must have a date and a valid meal type", ErrInvalidAccountArchive, i+1)
+		}
+	}
+
+	for i, plan := range archive.Plans {
+		if plan == nil || plan.PlanDate.IsZero() || !archiveMealTypes[plan.MealType] {
+			return fmt.Errorf("%w: plan #%d must have a date and a valid meal type", ErrInvalidAccountArchive, i+1)
+		}
+		if plan.Status == "" {
+			plan.Status = "pending"
+		}
+		if !archivePlanStatuses[plan.Status] {
+			return fmt.Errorf("%w: plan #%d has an invalid status", ErrInvalidAccountArchive, i+1)
+		}
+	}
+
+	activeFasts := 0
+	for i, fast := range archive.Fasts {
+		if fast == nil || fast.Protocol == "" || len([]rune(fast.Protocol)) > 20 || fast.TargetHours <= 0 || fast.StartedAt.IsZero() {
+			return fmt.Errorf("%w: fast #%d must have a protocol, a target duration and a start time", ErrInvalidAccountArchive, i+1)
+		}
+		if fast.Status == "" {
+			fast.Status = model.FastStatusCompleted
+		}
+		if !archiveFastStatuses[fast.Status] {
+			return fmt.Errorf("%w: fast #%d has an invalid status", ErrInvalidAccountArchive, i+1)
+		}
+		if fast.Status == model.FastStatusActive {
+			if fast.EndedAt != nil {
+				return fmt.Errorf("%w: active fast #%d must not have an end time", ErrInvalidAccountArchive, i+1)
+			}
+			activeFasts++
+		} else if fast.EndedAt == nil {
+			return fmt.Errorf("%w: fast #%d must have an end time", ErrInvalidAccountArchive, i+1)
+		}
+	}
+	if activeFasts > 1 {
+		return fmt.Errorf("%w: at most one fast can be active", ErrInvalidAccountArchive)
+	}
+
+	metricDates := make(map[string]bool, len(archive.BodyMetrics))
+	for i, metric := range archive.BodyMetrics {
+		if metric == nil || metric.MeasuredOn.IsZero() {
+			return fmt.Errorf("%w: body metric #%d must have a date", ErrInvalidAccountArchive, i+1)
+		}
+		date := metric.MeasuredOn.Format("2006-01-02")
+		if metricDates[date] {
+			return fmt.Errorf("%w: duplicate body metric on %s", ErrInvalidAccountArchive, date)
+		}
+		metricDates[date] = true
+	}
+
+	for i, activity := range archive.Activities {
+		if activity == nil || activity.ActivityType == "" || len([]rune(activity.ActivityType)) > 50 ||
+			activity.PerformedOn.IsZero() || activity.DurationMinutes <= 0 || activity.CaloriesBurned < 0 {
+			return fmt.Errorf("%w: activity #%d must have a type, a date, a duration and non-negative calories", ErrInvalidAccountArchive, i+1)
+		}
+		if activity.Intensity == "" {
+			activity.Intensity = model.IntensityModerate
+		}
+		if !archiveIntensities[activity.Intensity] {
+			return fmt.Errorf("%w: activity #%d has an invalid intensity", ErrInvalidAccountArchive, i+1)
+		}
+	}
+
+	for i, log := range archive.WaterLogs {
+		if log == nil || log.LogDate.IsZero() || log.AmountML <= 0 {
+			return fmt.Errorf("%w: water log #%d must have a date and a positive amount", ErrInvalidAccountArchive, i+1)
+		}
+		if log.BeverageType == "" {
+			log.BeverageType = model.BeverageWater
+		}
+		if len([]rune(log.BeverageType)) > 20 {
+			return fmt.Errorf("%w: water log #%d has an invalid beverage type", ErrInvalidAccountArchive, i+1)
+		}
+	}
+
+	goalKeys := make(map[string]bool, len(archive.Goals))
+	for i, goal := range archive.Goals {
+		if goal == nil || goal.EffectiveFrom.IsZero() || goal.Calories <= 0 {
+			return fmt.Errorf("%w: goal #%d must have an effective date and a calorie goal", ErrInvalidAccountArchive, i+1)
+		}
+		if !isArchiveGoalProfile(goal.Profile) {
+			return fmt.Errorf("%w: goal #%d has an invalid profile", ErrInvalidAccountArchive, i+1)
+		}
+		key := goal.Profile + "@" + goal.EffectiveFrom.Format("2006-01-02")
+		if goalKeys[key] {
+			return fmt.Errorf("%w: duplicate goal for profile '%s' from %s", ErrInvalidAccountArchive, goal.Profile, goal.EffectiveFrom.Format("2006-01-02"))
+		}
+		goalKeys[key] = true
+	}
+
+	scheduleDates := make(map[string]bool, len(archive.GoalSchedules))
+	for i, schedule := range archive.GoalSchedules {
+		if schedule == nil || schedule.EffectiveFrom.IsZero() {
+			return fmt.Errorf("%w: goal schedule #%d must have an effective date", ErrInvalidAccountArchive, i+1)
+		}
+		date := schedule.EffectiveFrom.Format("2006-01-02")
+		if scheduleDates[date] {
+			return fmt.Errorf("%w: duplicate goal schedule from %s", ErrInvalidAccountArchive, date)
+		}
+		scheduleDates[date] = true
+		if schedule.WeekdayProfiles == nil {
+			schedule.WeekdayProfiles = map[string]string{}
+		}
+		for weekday, profile := range schedule.WeekdayProfiles {
+			if !isWeekdayKey(weekday) || !model.IsValidGoalProfileName(profile) {
+				return fmt.Errorf("%w: goal schedule #%d has an invalid weekday or profile", ErrInvalidAccountArchive, i+1)
+			}
+		}
+	}
+
+	overrideDates := make(map[string]bool, len(archive.GoalOverrides))
+	for i, override := range archive.GoalOverrides {
+		if override == nil || override.Date.IsZero() || !isArchiveGoalProfile(override.Profile) {
+			return fmt.Errorf("%w: goal override #%d must have a date and a valid profile", ErrInvalidAccountArchive, i+1)
+		}
+		date := override.Date.Format("2006-01-02")
+		if overrideDates[date] {
+			return fmt.Errorf("%w: duplicate goal override on %s", ErrInvalidAccountArchive, date)
+		}
+		overrideDates[date] = true
+	}
+
+	for i, settings := range archive.AISettings {
+		if settings == nil || !archiveAIProviders[settings.Provider] {
+			return fmt.Errorf("%w: ai settings #%d has an invalid provider", ErrInvalidAccountArchive, i+1)
+		}
+		if settings.CreatedAt.IsZero() {
+			settings.CreatedAt = time.Now()
+		}
+	}
+
+	now := time.Now()
+	for i, conv := range archive.Conversations {
+		if conv == nil || conv.Title == "" || len([]rune(conv.Title)) > 200 {
+			return fmt.Errorf("%w: conversation #%d must have a title of at most 200 characters", ErrInvalidAccountArchive, i+1)
+		}
+		if conv.CreatedAt.IsZero() {
+			conv.CreatedAt = now
+		}
+		if conv.UpdatedAt.IsZero() {
+			conv.UpdatedAt = conv.CreatedAt
+		}
+		for j, msg := range conv.Messages {
+			if msg == nil || (msg.Role != model.MessageRoleUser && msg.Role != model.MessageRoleAssistant) {
+				return fmt.Errorf("%w: message #%d of conversation #%d has an invalid role", ErrInvalidAccountArchive, j+1, i+1)
+			}
+			if msg.CreatedAt.IsZero() {
+				msg.CreatedAt = conv.CreatedAt
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+	"go.uber.org/zap"
+)
+
+// accountPurgeBatchSize 每批永久删除的账户数量
+const accountPurgeBatchSize = 100
+
+var (
+	// ErrLastAdmin 唯一的管理员不能删除自己的账户，避免系统失去管理员
+	ErrLastAdmin = errors.New("the only admin account cannot be deleted, promote another admin first")
+)
+
+// AccountDeletionService 账户删除服务接口
+type AccountDeletionService interface {
+	RequestDeletion(ctx context.Context, userID int64, password string) (*model.AccountDeletion, error)
+	PurgeDueAccounts(ctx context.Context) error
+}
+
+// accountDeletionService 账户删除服务实现
+// 用户申请删除后账户在宽限期内保留，所有会话立即撤销，期间重新登录即取消删除（见 authService.createSession）；
+// 宽限期过后由 PurgeDueAccounts 永久删除账户及其所有数据
+type accountDeletionService struct {
+	userRepo       repository.UserRepository
+	userCacheRepo  repository.UserCacheRepository
+	sessionService SessionService
+	auditService   AuditService
+	gracePeriod    time.Duration
+	logger         *zap.Logger
+}
+
+// NewAccountDeletionService 创建账户删除服务实例
+func NewAccountDeletionService(
+	userRepo repository.UserRepository,
+	userCacheRepo repository.UserCacheRepository,
+	sessionService SessionService,
+	auditService AuditService,
+	gracePeriod time.Duration,
+	logger *zap.Logger,
+) AccountDeletionService {
+	return &accountDeletionService{
+		userRepo:       userRepo,
+		userCacheRepo:  userCacheRepo,
+		sessionService: sessionService,
+		auditService:   auditService,
+		gracePeriod:    gracePeriod,
+		logger:         logger,
+	}
+}
+
+// RequestDeletion 验证密码后申请删除账户，并撤销所有会话
+func (s *accountDeletionService) RequestDeletion(ctx context.Context, userID int64, password string) (*model.AccountDeletion, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := utils.VerifyPassword(user.PasswordHash, password); err != nil {
+		s.auditService.Record(ctx, &model.AuditLog{
+			Action:       model.AuditActionAccountDeletionRequest,
+			ResourceType: model.AuditResourceUser,
+			ResourceID:   strconv.FormatInt(userID, 10),
+			Outcome:      model.AuditOutcomeFailure,
+			Details:      map[string]any{"reason": "invalid_password"},
+		})
+		return nil, repository.ErrInvalidPassword
+	}
+
+	if user.IsAdmin() {
+		admins, err := s.userRepo.CountActiveAdmins(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if admins <= 1 {
+			return nil, ErrLastAdmin
+		}
+	}
+
+	// 重复申请时保留原来的删除时间
+	deletion := &model.AccountDeletion{}
+	if user.IsPendingDeletion() {
+		deletion.RequestedAt = *user.DeletionRequestedAt
+		deletion.ScheduledAt = *user.DeletionScheduledAt
+	} else {
+		now := time.Now()
+		deletion.RequestedAt = now
+		deletion.ScheduledAt = now.Add(s.gracePeriod)
+
+		if err := s.userRepo.ScheduleDeletion(ctx, userID, deletion.RequestedAt, deletion.ScheduledAt); err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				return nil, ErrUserNotFound
+			}
+			return nil, err
+		}
+	}
+
+	if err := s.sessionService.RevokeAllSessions(ctx, userID, model.SessionRevokedAccountDeletion); err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	s.auditService.Record(ctx, &model.AuditLog{
+		Action:       model.AuditActionAccountDeletionRequest,
+		ResourceType: model.AuditResourceUser,
+		ResourceID:   strconv.FormatInt(userID, 10),
+		Details: map[string]any{
+			"requested_at": deletion.RequestedAt,
+			"scheduled_at": deletion.ScheduledAt,
+		},
+	})
+
+	s.logger.Info("Account deletion requested",
+		zap.Int64("user_id", userID),
+		zap.Time("scheduled_at", deletion.ScheduledAt),
+	)
+
+	return deletion, nil
+}
+
+// PurgeDueAccounts 永久删除宽限期已过的账户
+// 账户数据通过外键级联删除，API 日志、登录记录和 Redis 中的用户键单独清理；
+// 单个账户删除失败不影响其他账户，下一次任务运行时重试
+func (s *accountDeletionService) PurgeDueAccounts(ctx context.Context) error {
+	now := time.Now()
+	var purged, failed int
+
+	for {
+		ids, err := s.userRepo.ListDueDeletions(ctx, now, accountPurgeBatchSize)
+		if err != nil {
+			return err
+		}
+
+		batchFailed := 0
+		for _, id := range ids {
+			if err := s.userRepo.DeleteScheduledUser(ctx, id, now); err != nil {
+				// 列出之后用户登录取消了删除
+				if errors.Is(err, repository.ErrUserNotFound) {
+					continue
+				}
+				s.logger.Error("Failed to purge account", zap.Int64("user_id", id), zap.Error(err))
+				batchFailed++
+				continue
+			}
+
+			// 后台任务没有操作者，actor_user_id 为空
+			s.auditService.Record(ctx, &model.AuditLog{
+				Action:       model.AuditActionAccountPurge,
+				ResourceType: model.AuditResourceUser,
+				ResourceID:   strconv.FormatInt(id, 10),
+			})
+
+			// 账户已经删除，缓存键删除失败只记录日志
+			if err := s.userCacheRepo.DeleteUserKeys(ctx, id); err != nil {
+				s.logger.Warn("Failed to delete user cache keys", zap.Int64("user_id", id), zap.Error(err))
+			}
+			purged++
+		}
+		failed += batchFailed
+
+		// 本批有失败的账户时停止，避免反复列出同一批账户
+		if len(ids) < accountPurgeBatchSize || batchFailed > 0 {
+			break
+		}
+	}
+
+	if purged > 0 {
+		s.logger.Info("Accounts purged", zap.Int("purged", purged))
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to purge %d accounts", failed)
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+)
+
+// ErrActivityWeightUnknown is returned when calories burned must be estimated but no
+// body weight has been recorded
+var ErrActivityWeightUnknown = errors.New("calories_burned is required until a body weight has been recorded")
+
+// ActivityService handles activity logging business logic
+type ActivityService struct {
+	activityRepo   *repository.ActivityRepository
+	bodyMetricRepo *repository.BodyMetricRepository
+}
+
+// NewActivityService creates a new ActivityService instance
+func NewActivityService(activityRepo *repository.ActivityRepository, bodyMetricRepo *repository.BodyMetricRepository) *ActivityService {
+	return &ActivityService{
+		activityRepo:   activityRepo,
+		bodyMetricRepo: bodyMetricRepo,
+	}
+}
+
+// CreateActivity logs an activity, estimating calories burned when not supplied
+func (s *ActivityService) CreateActivity(userID int64, req *model.ActivityRequest) (*model.Activity, error) {
+	activity, err := s.toActivity(userID, req)
+	if err != nil {
+		return nil, err
+	}
+	activity.UserID = userID
+
+	if err := s.activityRepo.CreateActivity(activity); err != nil {
+		return nil, err
+	}
+
+	// Reload to pick up timestamps
+	return s.activityRepo.GetActivityByID(userID, activity.ID)
+}
+
+// UpdateActivity replaces an activity, re-estimating calories burned when not supplied
+func (s *ActivityService) UpdateActivity(userID, activityID int64, req *model.ActivityRequest) error {
+	if _, err := s.activityRepo.GetActivityByID(userID, activityID); err != nil {
+		return err
+	}
+
+	activity, err := s.toActivity(userID, req)
+	if err != nil {
+		return err
+	}
+
+	return s.activityRepo.UpdateActivity(userID, activityID, activity)
+}
+
+// DeleteActivity deletes an activity
+func (s *ActivityService) DeleteActivity(userID, activityID int64) error {
+	return s.activityRepo.DeleteActivity(userID, activityID)
+}
+
+// GetActivity retrieves an activity
+func (s *ActivityService) GetActivity(userID, activityID int64) (*model.Activity, error) {
+	return s.activityRepo.GetActivityByID(userID, activityID)
+}
+
+// ListActivities lists activities between startDate and endDate (inclusive), oldest first
+func (s *ActivityService) ListActivities(userID int64, startDate, endDate time.Time) ([]*model.Activity, error) {
+	return s.activityRepo.GetActivitiesByDateRange(userID, startDate, endDate)
+}
+
+// GetDailySummaries returns activity totals for each day between startDate and
+// endDate (inclusive), including days without activities
+func (s *ActivityService) GetDailySummaries(userID int64, startDate, endDate time.Time) ([]*model.DailyActivitySummary, error) {
+	activities, err := s.activityRepo.GetActivitiesByDateRange(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	days := make(map[string]*model.DailyActivitySummary)
+	summaries := make([]*model.DailyActivitySummary, 0)
+	for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
+		summary := &model.DailyActivitySummary{Date: utils.FormatDate(d)}
+		days[summary.Date] = summary
+		summaries = append(summaries, summary)
+	}
+
+	for _, activity := range activities {
+		summary, ok := days[utils.FormatDate(activity.PerformedOn)]
+		if !ok {
+			continue
+		}
+		summary.ActivityCount++
+		summary.DurationMinutes += activity.DurationMinutes
+		summary.CaloriesBurned += activity.CaloriesBurned
+	}
+
+	return summaries, nil
+}
+
+// toActivity converts a request to an activity. When calories burned are omitted they
+// are estimated as MET x body weight (kg) x hours, using the weight recorded on or
+// before the activity date (or the latest weight if there is none).
+func (s *ActivityService) toActivity(userID int64, req *model.ActivityRequest) (*model.Activity, error) {
+	activity := &model.Activity{
+		ActivityType:    req.ActivityType,
+		PerformedOn:     req.PerformedOn,
+		StartedAt:       req.StartedAt,
+		DurationMinutes: req.DurationMinutes,
+		Intensity:       req.Intensity,
+		Notes:           req.Notes,
+	}
+	if activity.Intensity == "" {
+		activity.Intensity = model.IntensityModerate
+	}
+
+	if req.CaloriesBurned != nil {
+		activity.CaloriesBurned = *req.CaloriesBurned
+		return activity, nil
+	}
+
+	metric, err := s.bodyMetricRepo.GetWeightOnOrBefore(userID, req.PerformedOn)
+	if err != nil {
+		return nil, err
+	}
+	if metric == nil {
+		metric, err = s.bodyMetricRepo.GetLatestWeight(userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if metric == nil || metric.WeightKg == nil {
+		return nil, ErrActivityWeightUnknown
+	}
+
+	met := model.FindActivityMET(activity.ActivityType, activity.Intensity)
+	calories := met * *metric.WeightKg * float64(activity.DurationMinutes) / 60
+	activity.MET = &met
+	activity.CaloriesBurned = math.Round(calories*10) / 10
+	activity.CaloriesEstimated = true
+
+	return activity, nil
+}
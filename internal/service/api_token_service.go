@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+)
+
+const (
+	// maxAPITokensPerUser 每个用户最多可创建的个人访问令牌数量
+	maxAPITokensPerUser = 20
+	// maxAPITokenExpiryDays 个人访问令牌最长有效期（天）
+	maxAPITokenExpiryDays = 365
+	// apiTokenTouchInterval 最近使用时间的最小记录间隔
+	apiTokenTouchInterval = time.Minute
+)
+
+var (
+	// ErrAPITokenNotFound 个人访问令牌不存在或不属于当前用户
+	ErrAPITokenNotFound = errors.New("api token not found")
+	// ErrInvalidAPITokenScope 权限范围无效
+	ErrInvalidAPITokenScope = errors.New("invalid api token scope")
+	// ErrInvalidAPITokenExpiry 有效期无效
+	ErrInvalidAPITokenExpiry = errors.New("api token expiry must be between 1 and 365 days")
+	// ErrAPITokenLimitReached 个人访问令牌数量达到上限
+	ErrAPITokenLimitReached = errors.New("api token limit reached")
+	// ErrAPITokenExpired 个人访问令牌已过期
+	ErrAPITokenExpired = utils.ErrAPITokenExpired
+)
+
+// APITokenService 个人访问令牌服务接口
+type APITokenService interface {
+	ListTokens(ctx context.Context, userID int64) ([]*model.APIToken, error)
+	CreateToken(ctx context.Context, userID int64, name string, scopes []string, expiresInDays int) (*model.APITokenCreated, error)
+	RevokeToken(ctx context.Context, userID, tokenID int64) error
+	Authenticate(ctx context.Context, token, ipAddress string) (*model.APIToken, error)
+}
+
+// apiTokenService 个人访问令牌服务实现
+type apiTokenService struct {
+	apiTokenRepo repository.APITokenRepository
+	userRepo     repository.UserRepository
+	auditService AuditService
+}
+
+// NewAPITokenService 创建个人访问令牌服务实例
+func NewAPITokenService(apiTokenRepo repository.APITokenRepository, userRepo repository.UserRepository, auditService AuditService) APITokenService {
+	return &apiTokenService{
+		apiTokenRepo: apiTokenRepo,
+		userRepo:     userRepo,
+		auditService: auditService,
+	}
+}
+
+// ListTokens 获取用户的个人访问令牌列表（不包含明文令牌）
+func (s *apiTokenService) ListTokens(ctx context.Context, userID int64) ([]*model.APIToken, error) {
+	return s.apiTokenRepo.ListTokens(ctx, userID)
+}
+
+// CreateToken 创建个人访问令牌
+// expiresInDays 为 0 表示永不过期；明文令牌只在返回值中出现一次，数据库只保存哈希
+func (s *apiTokenService) CreateToken(ctx context.Context, userID int64, name string, scopes []string, expiresInDays int) (*model.APITokenCreated, error) {
+	normalized, err := normalizeAPITokenScopes(scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	if expiresInDays < 0 || expiresInDays > maxAPITokenExpiryDays {
+		return nil, ErrInvalidAPITokenExpiry
+	}
+
+	count, err := s.apiTokenRepo.CountTokens(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxAPITokensPerUser {
+		return nil, ErrAPITokenLimitReached
+	}
+
+	plaintext, err := utils.GenerateAPIToken()
+	if err != nil {
+		return nil, err
+	}
+
+	token := &model.APIToken{
+		UserID:      userID,
+		Name:        strings.TrimSpace(name),
+		TokenHash:   utils.HashAPIToken(plaintext),
+		TokenPrefix: utils.APITokenDisplayPrefix(plaintext),
+		Scopes:      normalized,
+	}
+	if expiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, expiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := s.apiTokenRepo.CreateToken(ctx, token); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, &model.AuditLog{
+		Action:       model.AuditActionAPITokenCreate,
+		ResourceType: model.AuditResourceAPIToken,
+		ResourceID:   strconv.FormatInt(token.ID, 10),
+		Details: map[string]any{
+			"name":       token.Name,
+			"scopes":     token.Scopes,
+			"expires_at": token.ExpiresAt,
+		},
+	})
+
+	return &model.APITokenCreated{APIToken: token, Token: plaintext}, nil
+}
+
+// RevokeToken 撤销（删除）个人访问令牌，立即生效
+func (s *apiTokenService) RevokeToken(ctx context.Context, userID, tokenID int64) error {
+	if err := s.apiTokenRepo.DeleteToken(ctx, userID, tokenID); err != nil {
+		if errors.Is(err, repository.ErrAPITokenNotFound) {
+			return ErrAPITokenNotFound
+		}
+		return err
+	}
+
+	s.auditService.Record(ctx, &model.AuditLog{
+		Action:       model.AuditActionAPITokenRevoke,
+		ResourceType: model.AuditResourceAPIToken,
+		ResourceID:   strconv.FormatInt(tokenID, 10),
+	})
+
+	return nil
+}
+
+// Authenticate 验证个人访问令牌并记录使用情况，返回令牌（含所属用户名）
+func (s *apiTokenService) Authenticate(ctx context.Context, token, ipAddress string) (*model.APIToken, error) {
+	apiToken, err := s.apiTokenRepo.GetTokenByHash(ctx, utils.HashAPIToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrAPITokenNotFound) {
+			return nil, utils.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if apiToken.IsExpired(now) {
+		return nil, ErrAPITokenExpired
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, apiToken.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, utils.ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// 停用或已申请删除的账户的令牌同样不能使用
+	if user.IsDisabled() {
+		return nil, ErrAccountDisabled
+	}
+	if user.IsPendingDeletion() {
+		return nil, ErrAccountPendingDeletion
+	}
+	apiToken.Username = user.Username
+
+	// 使用记录只用于展示，记录失败不影响本次请求
+	_ = s.apiTokenRepo.TouchToken(ctx, apiToken.ID, ipAddress, now, apiTokenTouchInterval)
+
+	return apiToken, nil
+}
+
+// normalizeAPITokenScopes 校验并去重权限范围，保持 model.APITokenScopes 中的顺序
+func normalizeAPITokenScopes(scopes []string) ([]string, error) {
+	requested := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !model.IsValidAPITokenScope(scope) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidAPITokenScope, scope)
+		}
+		requested[scope] = true
+	}
+
+	if len(requested) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidAPITokenScope)
+	}
+
+	normalized := make([]string, 0, len(requested))
+	for _, scope := range model.APITokenScopes {
+		if requested[scope] {
+			normalized = append(normalized, scope)
+		}
+	}
+
+	return normalized, nil
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	// auditWriteTimeout 写入一条审计日志的超时时间
+	auditWriteTimeout = 5 * time.Second
+	// auditExportMaxRows 一次导出的最大记录数
+	auditExportMaxRows = 10000
+	// maxAuditUsernameLength 用户名的最大长度，与 audit_logs.actor_username 列一致
+	// 登录失败时记录的是用户输入的用户名，可能超过用户名长度限制
+	maxAuditUsernameLength = 50
+)
+
+var (
+	// ErrInvalidAuditExportFormat 不支持的审计日志导出格式
+	ErrInvalidAuditExportFormat = errors.New("invalid export format, must be csv or json")
+)
+
+// auditCSVHeader CSV 导出的表头
+var auditCSVHeader = []string{
+	"id", "created_at", "actor_user_id", "actor_username", "api_token_id", "action",
+	"resource_type", "resource_id", "outcome", "ip_address", "user_agent", "changes", "details",
+}
+
+// AuditService 审计日志服务接口
+type AuditService interface {
+	Record(ctx context.Context, entry *model.AuditLog)
+	ListLogs(ctx context.Context, filter *model.AuditLogFilter) ([]*model.AuditLog, int, error)
+	ExportLogs(ctx context.Context, filter *model.AuditLogFilter, format string) ([]byte, int, error)
+	CleanupLogs(ctx context.Context) error
+}
+
+// auditService 审计日志服务实现
+type auditService struct {
+	auditLogRepo  repository.AuditLogRepository
+	retentionDays int
+	logger        *zap.Logger
+}
+
+// NewAuditService 创建审计日志服务实例
+// retentionDays 为 0 时永久保留审计日志
+func NewAuditService(auditLogRepo repository.AuditLogRepository, retentionDays int, logger *zap.Logger) AuditService {
+	return &auditService{
+		auditLogRepo:  auditLogRepo,
+		retentionDays: retentionDays,
+		logger:        logger,
+	}
+}
+
+// Record 记录一条审计日志
+// 未设置的操作者、IP 和用户代理从请求 context 中补充；changes 和 details 中的敏感字段在写入前脱敏。
+// 写入失败只记录日志，不影响业务操作；请求被取消时仍然写入
+func (s *auditService) Record(ctx context.Context, entry *model.AuditLog) {
+	if info := utils.RequestInfoFromContext(ctx); info != nil {
+		if entry.ActorUserID == nil && info.UserID > 0 {
+			userID := info.UserID
+			entry.ActorUserID = &userID
+			entry.ActorUsername = info.Username
+			if info.APITokenID > 0 {
+				tokenID := info.APITokenID
+				entry.APITokenID = &tokenID
+			}
+		}
+		if entry.IPAddress == "" {
+			entry.IPAddress = info.IPAddress
+		}
+		if entry.UserAgent == "" {
+			entry.UserAgent = info.UserAgent
+		}
+	}
+
+	if entry.Outcome == "" {
+		entry.Outcome = model.AuditOutcomeSuccess
+	}
+	if runes := []rune(entry.ActorUsername); len(runes) > maxAuditUsernameLength {
+		entry.ActorUsername = string(runes[:maxAuditUsernameLength])
+	}
+	entry.UserAgent = truncateUserAgent(entry.UserAgent)
+	entry.Changes = redactAuditChanges(entry.Changes)
+	if entry.Details != nil {
+		entry.Details = utils.RedactSensitive(entry.Details).(map[string]any)
+	}
+
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), auditWriteTimeout)
+	defer cancel()
+
+	if err := s.auditLogRepo.CreateAuditLog(writeCtx, entry); err != nil {
+		s.logger.Error("Failed to record audit log",
+			zap.String("action", entry.Action),
+			zap.String("resource_type", entry.ResourceType),
+			zap.String("resource_id", entry.ResourceID),
+			zap.Error(err),
+		)
+	}
+}
+
+// ListLogs 获取审计日志列表
+func (s *auditService) ListLogs(ctx context.Context, filter *model.AuditLogFilter) ([]*model.AuditLog, int, error) {
+	// 设置默认分页参数
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 20
+	}
+	if filter.PageSize > 100 {
+		filter.PageSize = 100
+	}
+
+	return s.auditLogRepo.ListAuditLogs(ctx, filter)
+}
+
+// ExportLogs 按筛选条件导出审计日志（CSV 或 JSON），最多导出最新的 auditExportMaxRows 条
+// 返回文件内容和导出的记录数；导出操作本身也会记录审计日志
+func (s *auditService) ExportLogs(ctx context.Context, filter *model.AuditLogFilter, format string) ([]byte, int, error) {
+	var buf bytes.Buffer
+	var count int
+	var err error
+
+	switch format {
+	case model.AuditExportFormatCSV:
+		count, err = s.exportCSV(ctx, filter, &buf)
+	case model.AuditExportFormatJSON:
+		count, err = s.exportJSON(ctx, filter, &buf)
+	default:
+		return nil, 0, ErrInvalidAuditExportFormat
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	s.Record(ctx, &model.AuditLog{
+		Action:       model.AuditActionAuditLogExport,
+		ResourceType: model.AuditResourceAuditLog,
+		Details: map[string]any{
+			"format":  format,
+			"records": count,
+			"filter":  auditFilterDetails(filter),
+		},
+	})
+
+	return buf.Bytes(), count, nil
+}
+
+// CleanupLogs 删除超过保留天数的审计日志
+func (s *auditService) CleanupLogs(ctx context.Context) error {
+	if s.retentionDays <= 0 {
+		return nil
+	}
+
+	deleted, err := s.auditLogRepo.DeleteAuditLogsBefore(ctx, time.Now().AddDate(0, 0, -s.retentionDays))
+	if err != nil {
+		return err
+	}
+
+	if deleted > 0 {
+		s.logger.Info("Old audit logs deleted", zap.Int64("deleted", deleted))
+	}
+	return nil
+}
+
+// exportCSV 以 CSV 格式写入审计日志，changes 和 details 列为 JSON
+func (s *auditService) exportCSV(ctx context.Context, filter *model.AuditLogFilter, buf *bytes.Buffer) (int, error) {
+	w := csv.NewWriter(buf)
+	if err := w.Write(auditCSVHeader); err != nil {
+		return 0, fmt.Errorf("failed to write csv header: %w", err)
+	}
+
+	count := 0
+	err := s.auditLogRepo.IterateAuditLogs(ctx, filter, auditExportMaxRows, func(log *model.AuditLog) error {
+		changes, err := marshalAuditField(log.Changes)
+		if err != nil {
+			return err
+		}
+		details, err := marshalAuditField(log.Details)
+		if err != nil {
+			return err
+		}
+
+		record := []string{
+			strconv.FormatInt(log.ID, 10),
+			log.CreatedAt.Format(time.RFC3339),
+			formatOptionalID(log.ActorUserID),
+			log.ActorUsername,
+			formatOptionalID(log.APITokenID),
+			log.Action,
+			log.ResourceType,
+			log.ResourceID,
+			log.Outcome,
+			log.IPAddress,
+			log.UserAgent,
+			changes,
+			details,
+		}
+		for i, value := range record {
+			record[i] = escapeCSVFormula(value)
+		}
+
+		count++
+		return w.Write(record)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return 0, fmt.Errorf("failed to write csv: %w", err)
+	}
+	return count, nil
+}
+
+// exportJSON 以 JSON 数组格式写入审计日志
+func (s *auditService) exportJSON(ctx context.Context, filter *model.AuditLogFilter, buf *bytes.Buffer) (int, error) {
+	buf.WriteByte('[')
+
+	count := 0
+	err := s.auditLogRepo.IterateAuditLogs(ctx, filter, auditExportMaxRows, func(log *model.AuditLog) error {
+		data, err := json.Marshal(log)
+		if err != nil {
+			return fmt.Errorf("failed to marshal audit log: %w", err)
+		}
+		if count > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('\n')
+		buf.Write(data)
+		count++
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	buf.WriteString("\n]\n")
+	return count, nil
+}
+
+// redactAuditChanges 脱敏字段修改记录
+// 敏感字段只保留是否修改的信息，前后值替换为 [REDACTED]（空值保持为空，便于区分设置和清除）
+func redactAuditChanges(changes model.AuditChanges) model.AuditChanges {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	redacted := make(model.AuditChanges, len(changes))
+	for field, change := range changes {
+		if utils.IsSensitiveKey(field) {
+			redacted[field] = model.AuditChange{
+				Before: redactAuditValue(change.Before),
+				After:  redactAuditValue(change.After),
+			}
+			continue
+		}
+		redacted[field] = model.AuditChange{
+			Before: utils.RedactSensitive(change.Before),
+			After:  utils.RedactSensitive(change.After),
+		}
+	}
+	return redacted
+}
+
+// redactAuditValue 将非空的敏感值替换为 [REDACTED]
+func redactAuditValue(value any) any {
+	if value == nil || value == "" {
+		return value
+	}
+	return utils.RedactedValue
+}
+
+// auditFilterDetails 返回导出时使用的筛选条件，记录在审计日志中
+func auditFilterDetails(filter *model.AuditLogFilter) map[string]any {
+	details := make(map[string]any)
+	if filter.ActorUserID != nil {
+		details["actor_user_id"] = *filter.ActorUserID
+	}
+	if filter.Action != "" {
+		details["action"] = filter.Action
+	}
+	if filter.ResourceType != "" {
+		details["resource_type"] = filter.ResourceType
+	}
+	if filter.ResourceID != "" {
+		details["resource_id"] = filter.ResourceID
+	}
+	if filter.Outcome != "" {
+		details["outcome"] = filter.Outcome
+	}
+	if filter.StartDate != nil {
+		details["start"] = filter.StartDate.Format(time.RFC3339)
+	}
+	if filter.EndDate != nil {
+		details["end"] = filter.EndDate.Format(time.RFC3339)
+	}
+	return details
+}
+
+// marshalAuditField 将 changes 或 details 序列化为 JSON 字符串，空值返回空字符串
+func marshalAuditField(value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal audit field: %w", err)
+	}
+	if string(data) == "null" || string(data) == "{}" {
+		return "", nil
+	}
+	return string(data), nil
+}
+
+// formatOptionalID 格式化可能为空的 ID
+func formatOptionalID(id *int64) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatInt(*id, 10)
+}
+
+// escapeCSVFormula 防止 CSV 注入：以公式字符开头的值前加单引号，避免在电子表格中被当作公式执行
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
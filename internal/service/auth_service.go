@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
@@ -20,12 +21,39 @@ var (
 	ErrUsernameExists = errors.New("username already exists")
 	// ErrRegistrationDisabled 注册已关闭
 	ErrRegistrationDisabled = errors.New("registration is currently disabled")
+	// ErrAccountDisabled 账户已被管理员停用
+	ErrAccountDisabled = utils.ErrAccountDisabled
+	// ErrAccountPendingDeletion 账户已申请删除，重新登录即取消删除
+	ErrAccountPendingDeletion = utils.ErrAccountPendingDeletion
+	// ErrInvalidMFAToken MFA 挑战令牌无效、已过期，或签发后用户已修改密码
+	ErrInvalidMFAToken = errors.New("invalid or expired mfa token")
+	// ErrPasswordPolicy 密码不符合密码策略，具体未通过的规则见 utils.PasswordPolicyError
+	ErrPasswordPolicy = utils.ErrPasswordPolicy
 )
 
+// 登录方式，记录在登录审计日志中
+const (
+	loginMethodPassword  = "password"
+	loginMethodExternal  = "external"
+	loginMethodTwoFactor = "two_factor"
+)
+
+// LoginResult 登录结果
+// 启用了两步验证的用户在密码验证通过后只会得到 MFA 挑战令牌，
+// 需要再调用 VerifyMFA 提交验证码换取正式的令牌对
+type LoginResult struct {
+	TokenPair   *utils.TokenPair
+	MFARequired bool
+	MFAToken    string
+	ExpiresIn   int64 // MFA 挑战令牌有效期（秒）
+}
+
 // AuthService 认证服务接口
 type AuthService interface {
-	Login(ctx context.Context, username, password, ipAddress string) (*utils.TokenPair, error)
-	RefreshToken(ctx context.Context, refreshToken string) (string, error)
+	Login(ctx context.Context, username, password, ipAddress, userAgent string) (*LoginResult, error)
+	VerifyMFA(ctx context.Context, mfaToken, code, ipAddress, userAgent string) (*utils.TokenPair, error)
+	CompleteExternalLogin(ctx context.Context, user *model.User, ipAddress, userAgent string) (*LoginResult, error)
+	RefreshToken(ctx context.Context, refreshToken, ipAddress, userAgent string) (*utils.TokenPair, error)
 	ValidateToken(ctx context.Context, token string) (*utils.Claims, error)
 	Logout(ctx context.Context, token string) error
 	ChangePassword(ctx context.Context, userID int64, oldPassword, newPassword string) error
@@ -38,7 +66,12 @@ type authService struct {
 	loginAttemptRepo   repository.LoginAttemptRepository
 	tokenBlacklistRepo repository.TokenBlacklistRepository
 	settingsService    SettingsService
+	twoFactorService   TwoFactorService
+	sessionService     SessionService
+	emailService       EmailService
+	auditService       AuditService
 	jwtService         *utils.JWTService
+	passwordPolicy     *utils.PasswordPolicy
 	maxLoginAttempts   int
 	lockoutDuration    time.Duration
 }
@@ -49,7 +82,12 @@ func NewAuthService(
 	loginAttemptRepo repository.LoginAttemptRepository,
 	tokenBlacklistRepo repository.TokenBlacklistRepository,
 	settingsService SettingsService,
+	twoFactorService TwoFactorService,
+	sessionService SessionService,
+	emailService EmailService,
+	auditService AuditService,
 	jwtService *utils.JWTService,
+	passwordPolicy *utils.PasswordPolicy,
 	maxLoginAttempts int,
 	lockoutDuration time.Duration,
 ) AuthService {
@@ -58,29 +96,25 @@ func NewAuthService(
 		loginAttemptRepo:   loginAttemptRepo,
 		tokenBlacklistRepo: tokenBlacklistRepo,
 		settingsService:    settingsService,
+		twoFactorService:   twoFactorService,
+		sessionService:     sessionService,
+		emailService:       emailService,
+		auditService:       auditService,
 		jwtService:         jwtService,
+		passwordPolicy:     passwordPolicy,
 		maxLoginAttempts:   maxLoginAttempts,
 		lockoutDuration:    lockoutDuration,
 	}
 }
 
 // Login 用户登录
-func (s *authService) Login(ctx context.Context, username, password, ipAddress string) (*utils.TokenPair, error) {
+func (s *authService) Login(ctx context.Context, username, password, ipAddress, userAgent string) (*LoginResult, error) {
 	// 检查登录限流
-	failedAttempts, err := s.loginAttemptRepo.GetRecentFailedAttempts(ctx, username, s.lockoutDuration)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check login attempts: %w", err)
-	}
-
-	if failedAttempts >= s.maxLoginAttempts {
-		// 记录失败的登录尝试
-		_ = s.loginAttemptRepo.RecordLoginAttempt(ctx, &model.LoginAttempt{
-			Username:    username,
-			IPAddress:   ipAddress,
-			Success:     false,
-			AttemptedAt: time.Now(),
-		})
-		return nil, ErrAccountLocked
+	if err := s.checkLoginLockout(ctx, username, ipAddress); err != nil {
+		if errors.Is(err, ErrAccountLocked) {
+			s.recordLoginAudit(ctx, nil, username, ipAddress, userAgent, loginMethodPassword, "account_locked")
+		}
+		return nil, err
 	}
 
 	// 获取用户
@@ -95,6 +129,7 @@ func (s *authService) Login(ctx context.Context, username, password, ipAddress s
 		})
 
 		if errors.Is(err, repository.ErrUserNotFound) {
+			s.recordLoginAudit(ctx, nil, username, ipAddress, userAgent, loginMethodPassword, "unknown_user")
 			return nil, ErrInvalidCredentials
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
@@ -109,55 +144,257 @@ func (s *authService) Login(ctx context.Context, username, password, ipAddress s
 			Success:     false,
 			AttemptedAt: time.Now(),
 		})
+		s.recordLoginAudit(ctx, user, username, ipAddress, userAgent, loginMethodPassword, "invalid_password")
 		return nil, ErrInvalidCredentials
 	}
 
+	// 检查账户是否被停用（在验证密码之后，避免向不知道密码的人暴露账户状态）
+	if user.IsDisabled() {
+		_ = s.loginAttemptRepo.RecordLoginAttempt(ctx, &model.LoginAttempt{
+			Username:    username,
+			IPAddress:   ipAddress,
+			Success:     false,
+			AttemptedAt: time.Now(),
+		})
+		s.recordLoginAudit(ctx, user, username, ipAddress, userAgent, loginMethodPassword, "account_disabled")
+		return nil, ErrAccountDisabled
+	}
+
+	return s.completeLogin(ctx, user, ipAddress, userAgent, loginMethodPassword)
+}
+
+// CompleteExternalLogin 外部身份（如 OpenID Connect）认证通过后完成登录
+// 与密码登录相同：停用的账户不能登录，启用了两步验证时返回 MFA 挑战令牌
+func (s *authService) CompleteExternalLogin(ctx context.Context, user *model.User, ipAddress, userAgent string) (*LoginResult, error) {
+	if user.IsDisabled() {
+		_ = s.loginAttemptRepo.RecordLoginAttempt(ctx, &model.LoginAttempt{
+			Username:    user.Username,
+			IPAddress:   ipAddress,
+			Success:     false,
+			AttemptedAt: time.Now(),
+		})
+		s.recordLoginAudit(ctx, user, user.Username, ipAddress, userAgent, loginMethodExternal, "account_disabled")
+		return nil, ErrAccountDisabled
+	}
+
+	return s.completeLogin(ctx, user, ipAddress, userAgent, loginMethodExternal)
+}
+
+// completeLogin 身份验证通过后签发 MFA 挑战令牌或创建会话
+func (s *authService) completeLogin(ctx context.Context, user *model.User, ipAddress, userAgent, method string) (*LoginResult, error) {
+	// 启用了两步验证时只签发 MFA 挑战令牌，登录成功的记录推迟到验证码校验通过之后
+	mfaEnabled, err := s.twoFactorService.IsEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check two-factor status: %w", err)
+	}
+
+	if mfaEnabled {
+		mfaToken, err := s.jwtService.GenerateMFAToken(user.ID, user.Username, user.PasswordVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate mfa token: %w", err)
+		}
+
+		return &LoginResult{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+			ExpiresIn:   int64(utils.MFATokenDuration.Seconds()),
+		}, nil
+	}
+
 	// 记录成功的登录尝试
 	_ = s.loginAttemptRepo.RecordLoginAttempt(ctx, &model.LoginAttempt{
-		Username:    username,
+		Username:    user.Username,
 		IPAddress:   ipAddress,
 		Success:     true,
 		AttemptedAt: time.Now(),
 	})
 
-	// 生成 token（包含密码版本）
-	tokenPair, err := s.jwtService.GenerateTokenPair(user.ID, user.Username, user.PasswordVersion)
+	// 创建会话并生成 token（包含密码版本和会话 ID）
+	tokenPair, err := s.createSession(ctx, user, ipAddress, userAgent)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+		return nil, err
+	}
+	s.recordLoginAudit(ctx, user, user.Username, ipAddress, userAgent, method, "")
+
+	return &LoginResult{TokenPair: tokenPair}, nil
+}
+
+// createSession 登录成功后创建会话，账户已申请删除时先取消删除
+func (s *authService) createSession(ctx context.Context, user *model.User, ipAddress, userAgent string) (*utils.TokenPair, error) {
+	if user.IsPendingDeletion() {
+		cancelled, err := s.userRepo.CancelDeletion(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if cancelled {
+			s.auditService.Record(ctx, &model.AuditLog{
+				ActorUserID:   &user.ID,
+				ActorUsername: user.Username,
+				Action:        model.AuditActionAccountDeletionCancel,
+				ResourceType:  model.AuditResourceUser,
+				ResourceID:    strconv.FormatInt(user.ID, 10),
+				IPAddress:     ipAddress,
+				UserAgent:     userAgent,
+				Changes: model.AuditChanges{
+					"deletion_scheduled_at": {Before: user.DeletionScheduledAt, After: nil},
+				},
+			})
+		}
+		user.DeletionRequestedAt = nil
+		user.DeletionScheduledAt = nil
+	}
+
+	tokenPair, err := s.sessionService.CreateSession(ctx, user, ipAddress, userAgent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
 
 	return tokenPair, nil
 }
 
-// RefreshToken 刷新访问令牌
-func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (string, error) {
-	// 验证 refresh token
-	claims, err := s.jwtService.ValidateToken(refreshToken)
+// VerifyMFA 两步登录的第二步：校验 MFA 挑战令牌和验证码（或恢复码），通过后签发令牌对
+func (s *authService) VerifyMFA(ctx context.Context, mfaToken, code, ipAddress, userAgent string) (*utils.TokenPair, error) {
+	claims, err := s.jwtService.ValidateMFAToken(mfaToken)
 	if err != nil {
-		return "", fmt.Errorf("invalid refresh token: %w", err)
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMFAToken, err)
+	}
+
+	// 验证码错误同样计入登录失败次数，防止暴力猜测
+	if err := s.checkLoginLockout(ctx, claims.Username, ipAddress); err != nil {
+		if errors.Is(err, ErrAccountLocked) {
+			s.recordLoginAudit(ctx, nil, claims.Username, ipAddress, userAgent, loginMethodTwoFactor, "account_locked")
+		}
+		return nil, err
 	}
 
-	// 验证用户是否存在并获取当前密码版本
 	user, err := s.userRepo.GetUserByID(ctx, claims.UserID)
 	if err != nil {
 		if errors.Is(err, repository.ErrUserNotFound) {
-			return "", ErrInvalidCredentials
+			return nil, ErrInvalidMFAToken
 		}
-		return "", fmt.Errorf("failed to get user: %w", err)
+		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	// 验证密码版本是否匹配
+	// 挑战令牌签发后账户被停用或修改了密码，都需要重新登录
+	if user.IsDisabled() {
+		return nil, ErrAccountDisabled
+	}
 	if err := s.jwtService.ValidatePasswordVersion(claims, user.PasswordVersion); err != nil {
-		return "", err
+		return nil, ErrInvalidMFAToken
+	}
+
+	if err := s.twoFactorService.Verify(ctx, user.ID, code); err != nil {
+		// 挑战令牌签发后两步验证被关闭或重置
+		if errors.Is(err, ErrTwoFactorNotEnabled) {
+			return nil, ErrInvalidMFAToken
+		}
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			_ = s.loginAttemptRepo.RecordLoginAttempt(ctx, &model.LoginAttempt{
+				Username:    user.Username,
+				IPAddress:   ipAddress,
+				Success:     false,
+				AttemptedAt: time.Now(),
+			})
+			s.recordLoginAudit(ctx, user, user.Username, ipAddress, userAgent, loginMethodTwoFactor, "invalid_two_factor_code")
+		}
+		return nil, err
 	}
 
-	// 生成新的 access token
-	accessToken, err := s.jwtService.RefreshAccessToken(refreshToken)
+	// 记录成功的登录尝试
+	_ = s.loginAttemptRepo.RecordLoginAttempt(ctx, &model.LoginAttempt{
+		Username:    user.Username,
+		IPAddress:   ipAddress,
+		Success:     true,
+		AttemptedAt: time.Now(),
+	})
+
+	tokenPair, err := s.createSession(ctx, user, ipAddress, userAgent)
 	if err != nil {
-		return "", fmt.Errorf("failed to refresh token: %w", err)
+		return nil, err
 	}
+	s.recordLoginAudit(ctx, user, user.Username, ipAddress, userAgent, loginMethodTwoFactor, "")
 
-	return accessToken, nil
+	return tokenPair, nil
+}
+
+// recordLoginAudit 记录登录审计日志，reason 为空表示登录成功
+// 用户不存在或被锁定时 user 为 nil，只记录尝试登录的用户名
+func (s *authService) recordLoginAudit(ctx context.Context, user *model.User, username, ipAddress, userAgent, method, reason string) {
+	entry := &model.AuditLog{
+		ActorUsername: username,
+		Action:        model.AuditActionLogin,
+		ResourceType:  model.AuditResourceUser,
+		IPAddress:     ipAddress,
+		UserAgent:     userAgent,
+		Details:       map[string]any{"method": method},
+	}
+	if user != nil {
+		entry.ActorUserID = &user.ID
+		entry.ActorUsername = user.Username
+		entry.ResourceID = strconv.FormatInt(user.ID, 10)
+	}
+	if reason != "" {
+		entry.Outcome = model.AuditOutcomeFailure
+		entry.Details["reason"] = reason
+	}
+
+	s.auditService.Record(ctx, entry)
+}
+
+// checkLoginLockout 检查登录限流，失败次数达到上限时返回 ErrAccountLocked
+func (s *authService) checkLoginLockout(ctx context.Context, username, ipAddress string) error {
+	failedAttempts, err := s.loginAttemptRepo.GetRecentFailedAttempts(ctx, username, s.lockoutDuration)
+	if err != nil {
+		return fmt.Errorf("failed to check login attempts: %w", err)
+	}
+
+	if failedAttempts >= s.maxLoginAttempts {
+		// 记录失败的登录尝试
+		_ = s.loginAttemptRepo.RecordLoginAttempt(ctx, &model.LoginAttempt{
+			Username:    username,
+			IPAddress:   ipAddress,
+			Success:     false,
+			AttemptedAt: time.Now(),
+		})
+		return ErrAccountLocked
+	}
+
+	return nil
+}
+
+// RefreshToken 使用刷新令牌换取新的令牌对
+// 刷新令牌每次使用后轮换，旧的刷新令牌被再次使用时撤销整个会话
+func (s *authService) RefreshToken(ctx context.Context, refreshToken, ipAddress, userAgent string) (*utils.TokenPair, error) {
+	// 验证 refresh token
+	claims, err := s.jwtService.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid refresh token: %w", err)
+	}
+
+	// 验证用户是否存在并获取当前密码版本
+	user, err := s.userRepo.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// 停用或已申请删除的账户不能刷新令牌
+	if user.IsDisabled() {
+		return nil, ErrAccountDisabled
+	}
+	if user.IsPendingDeletion() {
+		return nil, ErrAccountPendingDeletion
+	}
+
+	// 验证密码版本是否匹配
+	if err := s.jwtService.ValidatePasswordVersion(claims, user.PasswordVersion); err != nil {
+		return nil, err
+	}
+
+	// 轮换刷新令牌并生成新的令牌对
+	return s.sessionService.RotateSession(ctx, claims, user, ipAddress, userAgent)
 }
 
 // ValidateToken 验证令牌
@@ -167,6 +404,26 @@ func (s *authService) ValidateToken(ctx context.Context, token string) (*utils.C
 		return nil, err
 	}
 
+	// 检查令牌是否已登出
+	blacklisted, err := s.tokenBlacklistRepo.IsBlacklisted(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check token blacklist: %w", err)
+	}
+	if blacklisted {
+		return nil, utils.ErrInvalidToken
+	}
+
+	// 检查令牌所属的会话是否已被撤销
+	if claims.SessionID != 0 {
+		revoked, err := s.sessionService.IsSessionRevoked(ctx, claims.SessionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check session: %w", err)
+		}
+		if revoked {
+			return nil, ErrSessionRevoked
+		}
+	}
+
 	// 验证用户是否存在并获取当前密码版本
 	user, err := s.userRepo.GetUserByID(ctx, claims.UserID)
 	if err != nil {
@@ -176,6 +433,14 @@ func (s *authService) ValidateToken(ctx context.Context, token string) (*utils.C
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
+	// 停用或已申请删除的账户已签发的令牌立即失效
+	if user.IsDisabled() {
+		return nil, ErrAccountDisabled
+	}
+	if user.IsPendingDeletion() {
+		return nil, ErrAccountPendingDeletion
+	}
+
 	// 验证密码版本是否匹配
 	if err := s.jwtService.ValidatePasswordVersion(claims, user.PasswordVersion); err != nil {
 		return nil, err
@@ -205,6 +470,22 @@ func (s *authService) Logout(ctx context.Context, token string) error {
 		return fmt.Errorf("failed to add token to blacklist: %w", err)
 	}
 
+	// 撤销令牌所属的会话，使对应的刷新令牌也失效
+	if claims.SessionID != 0 {
+		err := s.sessionService.RevokeSession(ctx, claims.UserID, claims.SessionID, model.SessionRevokedLogout)
+		if err != nil && !errors.Is(err, ErrSessionNotFound) {
+			return fmt.Errorf("failed to revoke session: %w", err)
+		}
+	}
+
+	s.auditService.Record(ctx, &model.AuditLog{
+		ActorUserID:   &claims.UserID,
+		ActorUsername: claims.Username,
+		Action:        model.AuditActionLogout,
+		ResourceType:  model.AuditResourceSession,
+		ResourceID:    strconv.FormatInt(claims.SessionID, 10),
+	})
+
 	return nil
 }
 
@@ -221,9 +502,21 @@ func (s *authService) ChangePassword(ctx context.Context, userID int64, oldPassw
 
 	// 验证旧密码
 	if err := utils.VerifyPassword(user.PasswordHash, oldPassword); err != nil {
+		s.auditService.Record(ctx, &model.AuditLog{
+			Action:       model.AuditActionPasswordChange,
+			ResourceType: model.AuditResourceUser,
+			ResourceID:   strconv.FormatInt(userID, 10),
+			Outcome:      model.AuditOutcomeFailure,
+			Details:      map[string]any{"reason": "invalid_password"},
+		})
 		return repository.ErrInvalidPassword
 	}
 
+	// 校验新密码是否符合密码策略
+	if err := s.passwordPolicy.Validate(newPassword, user.Username); err != nil {
+		return err
+	}
+
 	// 哈希新密码
 	newPasswordHash, err := utils.HashPassword(newPassword)
 	if err != nil {
@@ -238,6 +531,17 @@ func (s *authService) ChangePassword(ctx context.Context, userID int64, oldPassw
 		return fmt.Errorf("failed to update password: %w", err)
 	}
 
+	// 旧令牌已因密码版本变化失效，同时撤销所有会话使会话列表保持准确
+	if err := s.sessionService.RevokeAllSessions(ctx, userID, model.SessionRevokedPasswordChanged); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	s.auditService.Record(ctx, &model.AuditLog{
+		Action:       model.AuditActionPasswordChange,
+		ResourceType: model.AuditResourceUser,
+		ResourceID:   strconv.FormatInt(userID, 10),
+	})
+
 	return nil
 }
 
@@ -253,7 +557,12 @@ func (s *authService) Register(ctx context.Context, username, password, email st
 		return nil, ErrRegistrationDisabled
 	}
 
-	// 2. 检查用户名唯一性（不区分大小写）
+	// 2. 校验密码是否符合密码策略
+	if err := s.passwordPolicy.Validate(password, username); err != nil {
+		return nil, err
+	}
+
+	// 3. 检查用户名唯一性（不区分大小写）
 	exists, err := s.userRepo.CheckUsernameExists(ctx, username)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check username exists: %w", err)
@@ -263,7 +572,7 @@ func (s *authService) Register(ctx context.Context, username, password, email st
 		return nil, ErrUsernameExists
 	}
 
-	// 3. 确定用户角色（第一个用户为管理员）
+	// 4. 确定用户角色（第一个用户为管理员）
 	userCount, err := s.userRepo.GetUserCount(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user count: %w", err)
@@ -274,7 +583,7 @@ func (s *authService) Register(ctx context.Context, username, password, email st
 		role = model.RoleAdmin // 第一个用户为管理员
 	}
 
-	// 4. 创建用户
+	// 5. 创建用户
 	user := &model.User{
 		Username: username,
 		Email:    email,
@@ -289,6 +598,21 @@ func (s *authService) Register(ctx context.Context, username, password, email st
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	// 5. 返回创建的用户信息
+	s.auditService.Record(ctx, &model.AuditLog{
+		ActorUserID:   &user.ID,
+		ActorUsername: user.Username,
+		Action:        model.AuditActionRegister,
+		ResourceType:  model.AuditResourceUser,
+		ResourceID:    strconv.FormatInt(user.ID, 10),
+		Details:       map[string]any{"role": user.Role, "method": loginMethodPassword},
+	})
+
+	// 6. 填写了邮箱时发送验证邮件
+	// 发送失败不影响注册（邮件服务会记录日志），用户可以登录后重新发送
+	if user.Email != "" {
+		_ = s.emailService.SendVerificationEmail(ctx, user.ID)
+	}
+
+	// 7. 返回创建的用户信息
 	return user, nil
 }
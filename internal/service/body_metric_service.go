@@ -0,0 +1,475 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+)
+
+const (
+	// DefaultWeightTrendWindow is the default moving average window in days
+	DefaultWeightTrendWindow = 7
+	// weightRateLookbackDays is how far back the weekly rate of change looks
+	weightRateLookbackDays = 28
+	// maxGoalProjectionDays caps goal date projections
+	maxGoalProjectionDays = 730
+)
+
+var (
+	// ErrBodyMetricExists is returned when an entry already exists for the date
+	ErrBodyMetricExists = errors.New("a body metric entry already exists for this date")
+	// ErrInvalidBodyMetric is returned when an entry has no measurements or an out-of-range value
+	ErrInvalidBodyMetric = errors.New("invalid body metric")
+)
+
+// BodyMetricService handles body weight and measurement business logic
+type BodyMetricService struct {
+	bodyMetricRepo  *repository.BodyMetricRepository
+	userPrefsRepo   repository.UserPreferencesRepository
+	settingsService SettingsService
+}
+
+// NewBodyMetricService creates a new BodyMetricService instance
+func NewBodyMetricService(
+	bodyMetricRepo *repository.BodyMetricRepository,
+	userPrefsRepo repository.UserPreferencesRepository,
+	settingsService SettingsService,
+) *BodyMetricService {
+	return &BodyMetricService{
+		bodyMetricRepo:  bodyMetricRepo,
+		userPrefsRepo:   userPrefsRepo,
+		settingsService: settingsService,
+	}
+}
+
+// CreateBodyMetric creates a body metric entry. Only one entry is allowed per date.
+func (s *BodyMetricService) CreateBodyMetric(userID int64, req *model.BodyMetricRequest) (*model.BodyMetricEntry, error) {
+	prefs, err := s.getPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	metric, err := toBodyMetric(req, prefs)
+	if err != nil {
+		return nil, err
+	}
+	metric.UserID = userID
+
+	existing, err := s.bodyMetricRepo.GetBodyMetricByDate(userID, metric.MeasuredOn)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrBodyMetricExists
+	}
+
+	if err := s.bodyMetricRepo.CreateBodyMetric(metric); err != nil {
+		return nil, err
+	}
+
+	// Reload to pick up timestamps
+	created, err := s.bodyMetricRepo.GetBodyMetricByID(userID, metric.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toBodyMetricEntry(created, prefs), nil
+}
+
+// UpdateBodyMetric replaces a body metric entry
+func (s *BodyMetricService) UpdateBodyMetric(userID, metricID int64, req *model.BodyMetricRequest) error {
+	prefs, err := s.getPreferences(userID)
+	if err != nil {
+		return err
+	}
+
+	metric, err := toBodyMetric(req, prefs)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.bodyMetricRepo.GetBodyMetricByID(userID, metricID); err != nil {
+		return err
+	}
+
+	other, err := s.bodyMetricRepo.GetBodyMetricByDate(userID, metric.MeasuredOn)
+	if err != nil {
+		return err
+	}
+	if other != nil && other.ID != metricID {
+		return ErrBodyMetricExists
+	}
+
+	return s.bodyMetricRepo.UpdateBodyMetric(userID, metricID, metric)
+}
+
+// DeleteBodyMetric deletes a body metric entry
+func (s *BodyMetricService) DeleteBodyMetric(userID, metricID int64) error {
+	return s.bodyMetricRepo.DeleteBodyMetric(userID, metricID)
+}
+
+// GetBodyMetric retrieves a body metric entry in the user's units
+func (s *BodyMetricService) GetBodyMetric(userID, metricID int64) (*model.BodyMetricEntry, error) {
+	prefs, err := s.getPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	metric, err := s.bodyMetricRepo.GetBodyMetricByID(userID, metricID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toBodyMetricEntry(metric, prefs), nil
+}
+
+// ListBodyMetrics retrieves entries between startDate and endDate (inclusive) in the user's units
+func (s *BodyMetricService) ListBodyMetrics(userID int64, startDate, endDate time.Time) ([]*model.BodyMetricEntry, error) {
+	prefs, err := s.getPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics, err := s.bodyMetricRepo.GetBodyMetricsByDateRange(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*model.BodyMetricEntry, 0, len(metrics))
+	for _, metric := range metrics {
+		entries = append(entries, toBodyMetricEntry(metric, prefs))
+	}
+
+	return entries, nil
+}
+
+// GetWeightTrend computes the trailing moving average of weigh-ins between startDate
+// and endDate, the weekly rate of change of that average over the last four weeks of
+// the range, and the projected date of reaching the goal weight at that rate.
+func (s *BodyMetricService) GetWeightTrend(userID int64, startDate, endDate time.Time, windowDays int) (*model.WeightTrend, error) {
+	if windowDays <= 0 {
+		windowDays = DefaultWeightTrendWindow
+	}
+
+	prefs, err := s.getPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	unit := prefs.WeightUnitOrDefault()
+
+	// Include the days before startDate that feed the first moving average
+	metrics, err := s.bodyMetricRepo.GetBodyMetricsByDateRange(userID, startDate.AddDate(0, 0, -(windowDays-1)), endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	points := weightMovingAverage(metrics, windowDays, utils.FormatDate(startDate))
+
+	trend := &model.WeightTrend{
+		StartDate:  utils.FormatDate(startDate),
+		EndDate:    utils.FormatDate(endDate),
+		Unit:       unit,
+		WindowDays: windowDays,
+		Points:     make([]*model.WeightTrendPoint, 0, len(points)),
+	}
+
+	for _, p := range points {
+		trend.Points = append(trend.Points, &model.WeightTrendPoint{
+			Date:          p.date,
+			Weight:        model.WeightFromKg(p.weightKg, unit),
+			MovingAverage: model.WeightFromKg(p.averageKg, unit),
+		})
+	}
+
+	if prefs.GoalWeightKg != nil {
+		goal := model.WeightFromKg(*prefs.GoalWeightKg, unit)
+		trend.GoalWeight = &goal
+	}
+
+	rateKg, ok := weeklyWeightRate(points)
+	if !ok {
+		return trend, nil
+	}
+	rate := roundWeightRate(rateKg, unit)
+	trend.WeeklyRate = &rate
+
+	if prefs.GoalWeightKg != nil {
+		last := points[len(points)-1]
+		trend.ProjectedGoalDate = projectGoalDate(last.date, last.averageKg, *prefs.GoalWeightKg, rateKg)
+	}
+
+	return trend, nil
+}
+
+// GetBodyMetricsSummary returns the latest entry and the four-week weight trend for the dashboard
+func (s *BodyMetricService) GetBodyMetricsSummary(userID int64, now time.Time, loc *time.Location) (*model.BodyMetricsSummary, error) {
+	prefs, err := s.getPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &model.BodyMetricsSummary{WeightUnit: prefs.WeightUnitOrDefault()}
+
+	latest, err := s.bodyMetricRepo.GetLatestBodyMetric(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest body metric: %w", err)
+	}
+	if latest != nil {
+		summary.Latest = toBodyMetricEntry(latest, prefs)
+	}
+
+	today := utils.StartOfDay(now, loc)
+	trend, err := s.GetWeightTrend(userID, today.AddDate(0, 0, -(weightRateLookbackDays-1)), today, DefaultWeightTrendWindow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weight trend: %w", err)
+	}
+
+	if len(trend.Points) > 0 {
+		trendWeight := trend.Points[len(trend.Points)-1].MovingAverage
+		summary.TrendWeight = &trendWeight
+	}
+	summary.WeeklyRate = trend.WeeklyRate
+	summary.GoalWeight = trend.GoalWeight
+	summary.ProjectedGoalDate = trend.ProjectedGoalDate
+
+	return summary, nil
+}
+
+// SetGoalWeight sets or clears the user's goal weight
+func (s *BodyMetricService) SetGoalWeight(ctx context.Context, userID int64, req *model.SetGoalWeightRequest) error {
+	prefs, err := s.settingsService.GetUserPreferences(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if req.GoalWeight == nil {
+		prefs.GoalWeightKg = nil
+	} else {
+		unit := req.Unit
+		if unit == "" {
+			unit = prefs.WeightUnitOrDefault()
+		}
+		goalKg := math.Round(model.WeightToKg(*req.GoalWeight, unit)*100) / 100
+		prefs.GoalWeightKg = &goalKg
+	}
+
+	return s.settingsService.UpdateUserPreferences(ctx, userID, prefs)
+}
+
+// getPreferences loads the user's preferences; missing preferences yield defaults
+func (s *BodyMetricService) getPreferences(userID int64) (*model.UserPreferences, error) {
+	prefs, err := s.userPrefsRepo.GetPreferences(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user preferences: %w", err)
+	}
+	if prefs == nil {
+		prefs = &model.UserPreferences{UserID: userID}
+	}
+	return prefs, nil
+}
+
+// toBodyMetric converts a request in the user's units to a metric entry
+func toBodyMetric(req *model.BodyMetricRequest, prefs *model.UserPreferences) (*model.BodyMetric, error) {
+	if req.Weight == nil && req.BodyFatPct == nil && req.Waist == nil &&
+		req.Hip == nil && req.Chest == nil && req.Neck == nil {
+		return nil, fmt.Errorf("%w: at least one measurement is required", ErrInvalidBodyMetric)
+	}
+
+	weightUnit := req.WeightUnit
+	if weightUnit == "" {
+		weightUnit = prefs.WeightUnitOrDefault()
+	}
+	lengthUnit := req.LengthUnit
+	if lengthUnit == "" {
+		lengthUnit = prefs.LengthUnitOrDefault()
+	}
+
+	metric := &model.BodyMetric{
+		MeasuredOn: req.MeasuredOn,
+		BodyFatPct: req.BodyFatPct,
+		Notes:      req.Notes,
+	}
+
+	if req.Weight != nil {
+		kg := math.Round(model.WeightToKg(*req.Weight, weightUnit)*100) / 100
+		if kg < model.MinWeightKg || kg > model.MaxWeightKg {
+			return nil, fmt.Errorf("%w: weight must be between %d and %d kg", ErrInvalidBodyMetric, model.MinWeightKg, model.MaxWeightKg)
+		}
+		metric.WeightKg = &kg
+	}
+
+	toCm := func(v *float64) *float64 {
+		if v == nil {
+			return nil
+		}
+		cm := math.Round(model.LengthToCm(*v, lengthUnit)*100) / 100
+		return &cm
+	}
+	metric.WaistCm = toCm(req.Waist)
+	metric.HipCm = toCm(req.Hip)
+	metric.ChestCm = toCm(req.Chest)
+	metric.NeckCm = toCm(req.Neck)
+
+	return metric, nil
+}
+
+// toBodyMetricEntry converts a metric entry to the user's units
+func toBodyMetricEntry(metric *model.BodyMetric, prefs *model.UserPreferences) *model.BodyMetricEntry {
+	weightUnit := prefs.WeightUnitOrDefault()
+	lengthUnit := prefs.LengthUnitOrDefault()
+
+	fromCm := func(v *float64) *float64 {
+		if v == nil {
+			return nil
+		}
+		converted := model.LengthFromCm(*v, lengthUnit)
+		return &converted
+	}
+
+	entry := &model.BodyMetricEntry{
+		ID:         metric.ID,
+		Date:       utils.FormatDate(metric.MeasuredOn),
+		BodyFatPct: metric.BodyFatPct,
+		Waist:      fromCm(metric.WaistCm),
+		Hip:        fromCm(metric.HipCm),
+		Chest:      fromCm(metric.ChestCm),
+		Neck:       fromCm(metric.NeckCm),
+		WeightUnit: weightUnit,
+		LengthUnit: lengthUnit,
+		Notes:      metric.Notes,
+		CreatedAt:  metric.CreatedAt,
+		UpdatedAt:  metric.UpdatedAt,
+	}
+
+	if metric.WeightKg != nil {
+		weight := model.WeightFromKg(*metric.WeightKg, weightUnit)
+		entry.Weight = &weight
+	}
+
+	return entry
+}
+
+// weightPoint is a weigh-in with its trailing moving average, in kilograms
+type weightPoint struct {
+	date      string
+	day       time.Time
+	weightKg  float64
+	averageKg float64
+}
+
+// weightMovingAverage computes the trailing windowDays moving average for each
+// weigh-in on or after fromDate. metrics must be ordered by date.
+func weightMovingAverage(metrics []*model.BodyMetric, windowDays int, fromDate string) []weightPoint {
+	weighIns := make([]weightPoint, 0, len(metrics))
+	for _, metric := range metrics {
+		if metric.WeightKg == nil {
+			continue
+		}
+		date := utils.FormatDate(metric.MeasuredOn)
+		day, _ := time.Parse("2006-01-02", date)
+		weighIns = append(weighIns, weightPoint{date: date, day: day, weightKg: *metric.WeightKg})
+	}
+
+	points := make([]weightPoint, 0, len(weighIns))
+	for i, p := range weighIns {
+		if p.date < fromDate {
+			continue
+		}
+
+		windowStart := p.day.AddDate(0, 0, -(windowDays - 1))
+		total, count := 0.0, 0
+		for j := i; j >= 0 && !weighIns[j].day.Before(windowStart); j-- {
+			total += weighIns[j].weightKg
+			count++
+		}
+
+		p.averageKg = total / float64(count)
+		points = append(points, p)
+	}
+
+	return points
+}
+
+// weeklyWeightRate returns the least-squares slope of the moving average over the
+// last four weeks of points, in kilograms per week. It needs at least two points
+// spanning a week.
+func weeklyWeightRate(points []weightPoint) (float64, bool) {
+	if len(points) < 2 {
+		return 0, false
+	}
+
+	last := points[len(points)-1].day
+	lookbackStart := last.AddDate(0, 0, -(weightRateLookbackDays - 1))
+
+	var xs, ys []float64
+	for _, p := range points {
+		if p.day.Before(lookbackStart) {
+			continue
+		}
+		xs = append(xs, p.day.Sub(lookbackStart).Hours()/24)
+		ys = append(ys, p.averageKg)
+	}
+
+	if len(xs) < 2 || xs[len(xs)-1]-xs[0] < 7 {
+		return 0, false
+	}
+
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(len(xs))
+	meanY /= float64(len(ys))
+
+	var num, den float64
+	for i := range xs {
+		num += (xs[i] - meanX) * (ys[i] - meanY)
+		den += (xs[i] - meanX) * (xs[i] - meanX)
+	}
+	if den == 0 {
+		return 0, false
+	}
+
+	return num / den * 7, true
+}
+
+// projectGoalDate projects when the goal weight is reached at the weekly rate,
+// starting from the last moving average. Returns nil when the trend is moving away
+// from the goal, is flat, or the projection is more than two years out.
+func projectGoalDate(lastDate string, currentKg, goalKg, weeklyRateKg float64) *string {
+	day, err := time.Parse("2006-01-02", lastDate)
+	if err != nil {
+		return nil
+	}
+
+	remaining := goalKg - currentKg
+	if math.Abs(remaining) < 0.05 {
+		return &lastDate
+	}
+	if weeklyRateKg == 0 || (remaining > 0) != (weeklyRateKg > 0) {
+		return nil
+	}
+
+	days := math.Ceil(remaining / (weeklyRateKg / 7))
+	if days > maxGoalProjectionDays {
+		return nil
+	}
+
+	projected := utils.FormatDate(day.AddDate(0, 0, int(days)))
+	return &projected
+}
+
+// roundWeightRate converts a weekly rate in kilograms to unit, rounded to 0.01
+func roundWeightRate(rateKg float64, unit string) float64 {
+	if unit == model.WeightUnitLb {
+		rateKg = rateKg / model.WeightToKg(1, model.WeightUnitLb)
+	}
+	return math.Round(rateKg*100) / 100
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+)
+
+func weighIn(date string, weightKg float64) *model.BodyMetric {
+	day, _ := time.Parse("2006-01-02", date)
+	return &model.BodyMetric{MeasuredOn: day, WeightKg: &weightKg}
+}
+
+func trendPoint(date string, averageKg float64) weightPoint {
+	day, _ := time.Parse("2006-01-02", date)
+	return weightPoint{date: date, day: day, weightKg: averageKg, averageKg: averageKg}
+}
+
+func TestWeightMovingAverage(t *testing.T) {
+	tests := []struct {
+		name       string
+		metrics    []*model.BodyMetric
+		windowDays int
+		fromDate   string
+		wantDates  []string
+		wantAvgs   []float64
+	}{
+		{
+			name: "Gaps and entries without weight",
+			metrics: []*model.BodyMetric{
+				weighIn("2024-03-01", 80),
+				{MeasuredOn: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)},
+				weighIn("2024-03-03", 79),
+				weighIn("2024-03-10", 78),
+			},
+			windowDays: 7,
+			fromDate:   "2024-03-01",
+			wantDates:  []string{"2024-03-01", "2024-03-03", "2024-03-10"},
+			wantAvgs:   []float64{80, 79.5, 78},
+		},
+		{
+			name: "Window edges",
+			metrics: []*model.BodyMetric{
+				weighIn("2024-03-01", 80),
+				weighIn("2024-03-07", 78),
+				weighIn("2024-03-08", 76),
+			},
+			windowDays: 7,
+			fromDate:   "2024-03-01",
+			wantDates:  []string{"2024-03-01", "2024-03-07", "2024-03-08"},
+			wantAvgs:   []float64{80, 79, 77},
+		},
+		{
+			name: "Earlier weigh-ins feed the first averages",
+			metrics: []*model.BodyMetric{
+				weighIn("2024-03-01", 80),
+				weighIn("2024-03-07", 78),
+				weighIn("2024-03-08", 76),
+			},
+			windowDays: 7,
+			fromDate:   "2024-03-07",
+			wantDates:  []string{"2024-03-07", "2024-03-08"},
+			wantAvgs:   []float64{79, 77},
+		},
+		{
+			name: "Single day window",
+			metrics: []*model.BodyMetric{
+				weighIn("2024-03-01", 80),
+				weighIn("2024-03-02", 79),
+			},
+			windowDays: 1,
+			fromDate:   "2024-03-01",
+			wantDates:  []string{"2024-03-01", "2024-03-02"},
+			wantAvgs:   []float64{80, 79},
+		},
+		{
+			name:       "No weigh-ins",
+			windowDays: 7,
+			fromDate:   "2024-03-01",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points := weightMovingAverage(tt.metrics, tt.windowDays, tt.fromDate)
+			if len(points) != len(tt.wantDates) {
+				t.Fatalf("got %d points, want %d", len(points), len(tt.wantDates))
+			}
+			for i, p := range points {
+				if p.date != tt.wantDates[i] || math.Abs(p.averageKg-tt.wantAvgs[i]) > 1e-9 {
+					t.Errorf("point %d = %s %.4f, want %s %.4f", i, p.date, p.averageKg, tt.wantDates[i], tt.wantAvgs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWeeklyWeightRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		points   []weightPoint
+		wantRate float64
+		wantOK   bool
+	}{
+		{
+			name:   "Single point",
+			points: []weightPoint{trendPoint("2024-03-01", 80)},
+		},
+		{
+			name:   "Less than a week of data",
+			points: []weightPoint{trendPoint("2024-03-01", 80), trendPoint("2024-03-07", 79)},
+		},
+		{
+			name:     "Exactly a week apart",
+			points:   []weightPoint{trendPoint("2024-03-01", 80), trendPoint("2024-03-08", 79.5)},
+			wantRate: -0.5,
+			wantOK:   true,
+		},
+		{
+			name: "Steady loss with gaps",
+			points: []weightPoint{
+				trendPoint("2024-03-01", 80),
+				trendPoint("2024-03-03", 79.8),
+				trendPoint("2024-03-08", 79.3),
+				trendPoint("2024-03-15", 78.6),
+			},
+			wantRate: -0.7,
+			wantOK:   true,
+		},
+		{
+			name: "Flat trend",
+			points: []weightPoint{
+				trendPoint("2024-03-01", 80),
+				trendPoint("2024-03-08", 80),
+				trendPoint("2024-03-15", 80),
+			},
+			wantRate: 0,
+			wantOK:   true,
+		},
+		{
+			name: "Points before the lookback are ignored",
+			points: []weightPoint{
+				trendPoint("2024-01-01", 90),
+				trendPoint("2024-03-01", 80),
+				trendPoint("2024-03-15", 81),
+			},
+			wantRate: 0.5,
+			wantOK:   true,
+		},
+		{
+			name: "Only one point inside the lookback",
+			points: []weightPoint{
+				trendPoint("2024-01-01", 90),
+				trendPoint("2024-03-15", 80),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, ok := weeklyWeightRate(tt.points)
+			if ok != tt.wantOK || math.Abs(rate-tt.wantRate) > 1e-9 {
+				t.Errorf("weeklyWeightRate() = (%.4f, %v), want (%.4f, %v)", rate, ok, tt.wantRate, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestProjectGoalDate(t *testing.T) {
+	tests := []struct {
+		name      string
+		lastDate  string
+		currentKg float64
+		goalKg    float64
+		rateKg    float64
+		want      string
+	}{
+		{
+			name:      "Losing toward goal",
+			lastDate:  "2024-03-01",
+			currentKg: 80,
+			goalKg:    75,
+			rateKg:    -0.5,
+			want:      "2024-05-10",
+		},
+		{
+			name:      "Gaining toward goal",
+			lastDate:  "2024-03-01",
+			currentKg: 60,
+			goalKg:    61,
+			rateKg:    0.35,
+			want:      "2024-03-21",
+		},
+		{
+			name:      "Already at goal",
+			lastDate:  "2024-03-01",
+			currentKg: 75.02,
+			goalKg:    75,
+			rateKg:    0.5,
+			want:      "2024-03-01",
+		},
+		{
+			name:      "Moving away from goal",
+			lastDate:  "2024-03-01",
+			currentKg: 80,
+			goalKg:    75,
+			rateKg:    0.5,
+		},
+		{
+			name:      "Flat trend",
+			lastDate:  "2024-03-01",
+			currentKg: 80,
+			goalKg:    75,
+			rateKg:    0,
+		},
+		{
+			name:      "Projection at the two-year cap",
+			lastDate:  "2024-03-01",
+			currentKg: 445,
+			goalKg:    80,
+			rateKg:    -3.5,
+			want:      "2026-03-01",
+		},
+		{
+			name:      "Projection beyond two years",
+			lastDate:  "2024-03-01",
+			currentKg: 445.5,
+			goalKg:    80,
+			rateKg:    -3.5,
+		},
+		{
+			name:      "Invalid date",
+			lastDate:  "not-a-date",
+			currentKg: 80,
+			goalKg:    75,
+			rateKg:    -0.5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := projectGoalDate(tt.lastDate, tt.currentKg, tt.goalKg, tt.rateKg)
+			switch {
+			case tt.want == "" && got != nil:
+				t.Errorf("projectGoalDate() = %s, want nil", *got)
+			case tt.want != "" && got == nil:
+				t.Errorf("projectGoalDate() = nil, want %s", tt.want)
+			case got != nil && *got != tt.want:
+				t.Errorf("projectGoalDate() = %s, want %s", *got, tt.want)
+			}
+		})
+	}
+}
@@ -5,13 +5,17 @@ import (
 	"time"
 
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
 )
 
 // DashboardService handles dashboard data aggregation
 type DashboardService struct {
-	mealService      *MealService
-	planService      *PlanService
-	nutritionService *NutritionService
+	mealService       *MealService
+	planService       *PlanService
+	nutritionService  *NutritionService
+	fastingService    *FastingService
+	bodyMetricService *BodyMetricService
+	hydrationService  *HydrationService
 }
 
 // NewDashboardService creates a new DashboardService instance
@@ -19,36 +23,36 @@ func NewDashboardService(
 	mealService *MealService,
 	planService *PlanService,
 	nutritionService *NutritionService,
+	fastingService *FastingService,
+	bodyMetricService *BodyMetricService,
+	hydrationService *HydrationService,
 ) *DashboardService {
 	return &DashboardService{
-		mealService:      mealService,
-		planService:      planService,
-		nutritionService: nutritionService,
+		mealService:       mealService,
+		planService:       planService,
+		nutritionService:  nutritionService,
+		fastingService:    fastingService,
+		bodyMetricService: bodyMetricService,
+		hydrationService:  hydrationService,
 	}
 }
 
-// GetDashboardData aggregates data for the dashboard view
-func (s *DashboardService) GetDashboardData(userID int64) (*model.DashboardData, error) {
-	now := time.Now()
+// GetDashboardData aggregates data for the dashboard view.
+// "Today" and the current month are determined in loc (the user's timezone).
+func (s *DashboardService) GetDashboardData(userID int64, loc *time.Location) (*model.DashboardData, error) {
+	now := time.Now().In(loc)
 	currentYear := now.Year()
 	currentMonth := int(now.Month())
 
 	// Get current month meal records
-	monthlyStats, err := s.mealService.GetMonthlyStats(userID, currentYear, currentMonth)
+	monthlyStats, err := s.mealService.GetMonthlyStats(userID, currentYear, currentMonth, loc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get monthly stats: %w", err)
 	}
 
 	// Get future plans (next 2 days)
-	tomorrow := now.AddDate(0, 0, 1)
-	dayAfterTomorrow := tomorrow.AddDate(0, 0, 1)
-	endOfDayAfterTomorrow := time.Date(
-		dayAfterTomorrow.Year(),
-		dayAfterTomorrow.Month(),
-		dayAfterTomorrow.Day(),
-		23, 59, 59, 0,
-		dayAfterTomorrow.Location(),
-	)
+	tomorrow := utils.StartOfDay(now, loc).AddDate(0, 0, 1)
+	endOfDayAfterTomorrow := utils.EndOfDay(tomorrow.AddDate(0, 0, 1), loc)
 
 	planFilter := &model.PlanFilter{
 		StartDate: &tomorrow,
@@ -69,6 +73,25 @@ func (s *DashboardService) GetDashboardData(userID int64) (*model.DashboardData,
 		return nil, fmt.Errorf("failed to get today's stats: %w", err)
 	}
 
+	// Get active fast and fasting streaks
+	fasting, err := s.fastingService.GetFastingSummary(userID, now, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get latest body metrics and weight trend
+	bodyMetrics, err := s.bodyMetricService.GetBodyMetricsSummary(userID, now, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get today's water intake against the water goal
+	today := utils.StartOfDay(now, loc)
+	hydration, err := s.hydrationService.GetDailyHydration(userID, today, today)
+	if err != nil {
+		return nil, err
+	}
+
 	// Assemble dashboard data
 	dashboardData := &model.DashboardData{
 		MonthlyStats: monthlyStats,
@@ -76,6 +99,9 @@ func (s *DashboardService) GetDashboardData(userID int64) (*model.DashboardData,
 		TodayStats:   todayStats,
 		CurrentMonth: currentMonth,
 		CurrentYear:  currentYear,
+		Fasting:      fasting,
+		BodyMetrics:  bodyMetrics,
+		Hydration:    hydration[0],
 		GeneratedAt:  now,
 	}
 
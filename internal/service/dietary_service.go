@@ -0,0 +1,98 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+)
+
+// ErrDietaryRestrictionViolated is returned when the user's restrictions are in
+// reject mode and a meal or plan contains a food that violates them
+var ErrDietaryRestrictionViolated = errors.New("foods violate dietary restrictions")
+
+// DietaryService checks foods against the user's allergen and diet restrictions
+type DietaryService struct {
+	foodRepo      *repository.FoodRepository
+	userPrefsRepo repository.UserPreferencesRepository
+}
+
+// NewDietaryService creates a new DietaryService instance
+func NewDietaryService(
+	foodRepo *repository.FoodRepository,
+	userPrefsRepo repository.UserPreferencesRepository,
+) *DietaryService {
+	return &DietaryService{
+		foodRepo:      foodRepo,
+		userPrefsRepo: userPrefsRepo,
+	}
+}
+
+// GetRestrictions returns the user's dietary restrictions
+func (s *DietaryService) GetRestrictions(userID int64) (model.DietaryRestrictions, error) {
+	prefs, err := s.userPrefsRepo.GetPreferences(userID)
+	if err != nil {
+		return model.DietaryRestrictions{}, fmt.Errorf("failed to get user preferences: %w", err)
+	}
+	return prefs.Restrictions(), nil
+}
+
+// CheckFoods checks meal or plan foods against the user's restrictions. In reject
+// mode any violation returns ErrDietaryRestrictionViolated; in warn mode each
+// violating food is returned as a warning.
+func (s *DietaryService) CheckFoods(userID int64, foods []model.MealFood) ([]*model.MealWarning, error) {
+	restrictions, err := s.GetRestrictions(userID)
+	if err != nil {
+		return nil, err
+	}
+	if restrictions.IsEmpty() || len(foods) == 0 {
+		return nil, nil
+	}
+
+	foodIDs := make([]int64, 0, len(foods))
+	for _, mf := range foods {
+		foodIDs = append(foodIDs, mf.FoodID)
+	}
+
+	foodsByID, err := s.foodRepo.GetFoodsByIDs(userID, foodIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get foods: %w", err)
+	}
+
+	var violations []*model.RestrictionViolation
+	checked := make(map[int64]bool, len(foods))
+	for _, mf := range foods {
+		food, ok := foodsByID[mf.FoodID]
+		if !ok || checked[mf.FoodID] {
+			continue
+		}
+		checked[mf.FoodID] = true
+
+		if violation := restrictions.Check(food); violation != nil {
+			violations = append(violations, violation)
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil, nil
+	}
+
+	if restrictions.Mode == model.RestrictionModeReject {
+		messages := make([]string, len(violations))
+		for i, v := range violations {
+			messages[i] = v.Message()
+		}
+		return nil, fmt.Errorf("%w: %s", ErrDietaryRestrictionViolated, strings.Join(messages, "; "))
+	}
+
+	warnings := make([]*model.MealWarning, len(violations))
+	for i, v := range violations {
+		warnings[i] = &model.MealWarning{
+			Type:    model.MealWarningRestrictionViolated,
+			Message: v.Message(),
+		}
+	}
+	return warnings, nil
+}
@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/mailer"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	// emailResendInterval 同一用户同一用途的邮件最小发送间隔
+	emailResendInterval = time.Minute
+	// emailSendTimeout 后台处理密码重置请求的超时时间
+	emailSendTimeout = 30 * time.Second
+)
+
+var (
+	// ErrInvalidEmailToken 邮件令牌无效、已使用或已过期
+	ErrInvalidEmailToken = errors.New("invalid or expired token")
+	// ErrEmailNotSet 账户未设置邮箱
+	ErrEmailNotSet = errors.New("no email address is set for this account")
+	// ErrEmailAlreadyVerified 邮箱已验证
+	ErrEmailAlreadyVerified = errors.New("email is already verified")
+	// ErrEmailRateLimited 邮件发送过于频繁
+	ErrEmailRateLimited = errors.New("please wait a minute before requesting another email")
+)
+
+// EmailService 邮箱验证和密码重置服务接口
+type EmailService interface {
+	SendVerificationEmail(ctx context.Context, userID int64) error
+	VerifyEmail(ctx context.Context, token string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+}
+
+// emailService 邮箱验证和密码重置服务实现
+// 邮件中的令牌只在邮件里出现，数据库只保存哈希；每个令牌只能使用一次，
+// 同一用户同一用途只有最新发出的令牌有效
+type emailService struct {
+	userRepo         repository.UserRepository
+	emailTokenRepo   repository.EmailTokenRepository
+	sessionService   SessionService
+	auditService     AuditService
+	mailer           mailer.Mailer
+	passwordPolicy   *utils.PasswordPolicy
+	appURL           string
+	verificationTTL  time.Duration
+	passwordResetTTL time.Duration
+	logger           *zap.Logger
+}
+
+// NewEmailService 创建邮箱验证和密码重置服务实例
+func NewEmailService(
+	userRepo repository.UserRepository,
+	emailTokenRepo repository.EmailTokenRepository,
+	sessionService SessionService,
+	auditService AuditService,
+	mailer mailer.Mailer,
+	passwordPolicy *utils.PasswordPolicy,
+	appURL string,
+	verificationTTL time.Duration,
+	passwordResetTTL time.Duration,
+	logger *zap.Logger,
+) EmailService {
+	return &emailService{
+		userRepo:         userRepo,
+		emailTokenRepo:   emailTokenRepo,
+		sessionService:   sessionService,
+		auditService:     auditService,
+		mailer:           mailer,
+		passwordPolicy:   passwordPolicy,
+		appURL:           strings.TrimRight(appURL, "/"),
+		verificationTTL:  verificationTTL,
+		passwordResetTTL: passwordResetTTL,
+		logger:           logger,
+	}
+}
+
+// SendVerificationEmail 向用户当前的邮箱发送验证邮件
+func (s *emailService) SendVerificationEmail(ctx context.Context, userID int64) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.Email == "" {
+		return ErrEmailNotSet
+	}
+	if user.IsEmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+
+	limited, err := s.isRateLimited(ctx, user.ID, model.EmailTokenPurposeVerifyEmail)
+	if err != nil {
+		return err
+	}
+	if limited {
+		return ErrEmailRateLimited
+	}
+
+	token, err := s.issueToken(ctx, user, model.EmailTokenPurposeVerifyEmail, s.verificationTTL)
+	if err != nil {
+		return err
+	}
+
+	msg := &mailer.Message{
+		To:      user.Email,
+		Subject: "验证你的邮箱 - AI Diet Assistant",
+		Body: fmt.Sprintf(
+			"%s，你好：\n\n请点击下面的链接验证你的邮箱：\n\n%s\n\n链接 %s 内有效。验证邮箱后，忘记密码时可以通过邮箱重置密码。\n\n如果这不是你的操作，请忽略这封邮件。\n",
+			user.Username, s.buildLink("/verify-email", token), formatTTL(s.verificationTTL),
+		),
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		s.logger.Error("Failed to send verification email", zap.Int64("user_id", user.ID), zap.Error(err))
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	return nil
+}
+
+// VerifyEmail 使用邮件中的令牌验证邮箱
+func (s *emailService) VerifyEmail(ctx context.Context, token string) error {
+	emailToken, err := s.consumeToken(ctx, token, model.EmailTokenPurposeVerifyEmail)
+	if err != nil {
+		return err
+	}
+
+	// 令牌发出后用户修改了邮箱，旧邮箱的验证链接不再有效
+	verified, err := s.userRepo.MarkEmailVerified(ctx, emailToken.UserID, emailToken.Email)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return ErrInvalidEmailToken
+	}
+
+	return nil
+}
+
+// RequestPasswordReset 向已验证该邮箱的账户发送密码重置邮件
+// 查询账户、签发令牌和发送邮件都在后台完成，无论邮箱是否存在都立即返回成功，
+// 避免通过响应内容或响应时间判断邮箱是否注册
+func (s *emailService) RequestPasswordReset(ctx context.Context, email string) error {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return nil
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), emailSendTimeout)
+		defer cancel()
+
+		if err := s.sendPasswordResetEmails(ctx, email); err != nil {
+			s.logger.Error("Failed to process password reset request", zap.Error(err))
+		}
+	}()
+
+	return nil
+}
+
+// sendPasswordResetEmails 为使用该邮箱的每个可用账户签发重置令牌并发送邮件
+func (s *emailService) sendPasswordResetEmails(ctx context.Context, email string) error {
+	users, err := s.userRepo.ListUsersByVerifiedEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		if user.IsDisabled() {
+			continue
+		}
+
+		// 频繁请求时静默跳过，不向调用方暴露账户存在
+		limited, err := s.isRateLimited(ctx, user.ID, model.EmailTokenPurposePasswordReset)
+		if err != nil {
+			return err
+		}
+		if limited {
+			continue
+		}
+
+		token, err := s.issueToken(ctx, user, model.EmailTokenPurposePasswordReset, s.passwordResetTTL)
+		if err != nil {
+			return err
+		}
+
+		msg := &mailer.Message{
+			To:      user.Email,
+			Subject: "重置密码 - AI Diet Assistant",
+			Body: fmt.Sprintf(
+				"%s，你好：\n\n我们收到了重置你账户密码的请求。请点击下面的链接设置新密码：\n\n%s\n\n链接 %s 内有效，只能使用一次。重置密码后所有设备都需要重新登录。\n\n如果这不是你的操作，请忽略这封邮件，你的密码不会改变。\n",
+				user.Username, s.buildLink("/reset-password", token), formatTTL(s.passwordResetTTL),
+			),
+		}
+		if err := s.mailer.Send(ctx, msg); err != nil {
+			s.logger.Error("Failed to send password reset email", zap.Int64("user_id", user.ID), zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// ResetPassword 使用邮件中的令牌重置密码
+// 更新密码版本使已签发的令牌全部失效，并撤销所有会话
+func (s *emailService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	emailToken, err := s.emailTokenRepo.GetTokenByHash(ctx, utils.HashEmailToken(token), model.EmailTokenPurposePasswordReset)
+	if err != nil {
+		if errors.Is(err, repository.ErrEmailTokenNotFound) {
+			return ErrInvalidEmailToken
+		}
+		return err
+	}
+	if !emailToken.IsUsable(time.Now()) {
+		return ErrInvalidEmailToken
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, emailToken.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrInvalidEmailToken
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// 令牌发出后邮箱被修改，不再信任发往旧邮箱的链接
+	if user.Email != emailToken.Email {
+		return ErrInvalidEmailToken
+	}
+	if user.IsDisabled() {
+		return ErrAccountDisabled
+	}
+
+	// 密码不符合策略时不使用令牌，用户可以修改密码后重新提交
+	if err := s.passwordPolicy.Validate(newPassword, user.Username); err != nil {
+		return err
+	}
+
+	passwordHash, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	used, err := s.emailTokenRepo.MarkTokenUsed(ctx, emailToken.ID)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidEmailToken
+	}
+
+	if err := s.userRepo.UpdatePasswordWithVersion(ctx, user.ID, passwordHash, time.Now().Unix()); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if err := s.sessionService.RevokeAllSessions(ctx, user.ID, model.SessionRevokedPasswordChanged); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	// 通过邮件链接重置密码时请求未认证，操作者为账户本人
+	s.auditService.Record(ctx, &model.AuditLog{
+		ActorUserID:   &user.ID,
+		ActorUsername: user.Username,
+		Action:        model.AuditActionPasswordReset,
+		ResourceType:  model.AuditResourceUser,
+		ResourceID:    strconv.FormatInt(user.ID, 10),
+	})
+
+	return nil
+}
+
+// consumeToken 校验并使用一次性令牌
+func (s *emailService) consumeToken(ctx context.Context, token, purpose string) (*model.EmailToken, error) {
+	emailToken, err := s.emailTokenRepo.GetTokenByHash(ctx, utils.HashEmailToken(token), purpose)
+	if err != nil {
+		if errors.Is(err, repository.ErrEmailTokenNotFound) {
+			return nil, ErrInvalidEmailToken
+		}
+		return nil, err
+	}
+
+	if !emailToken.IsUsable(time.Now()) {
+		return nil, ErrInvalidEmailToken
+	}
+
+	used, err := s.emailTokenRepo.MarkTokenUsed(ctx, emailToken.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, ErrInvalidEmailToken
+	}
+
+	return emailToken, nil
+}
+
+// issueToken 生成一次性令牌并保存哈希，返回令牌明文
+func (s *emailService) issueToken(ctx context.Context, user *model.User, purpose string, ttl time.Duration) (string, error) {
+	token, err := utils.GenerateEmailToken()
+	if err != nil {
+		return "", err
+	}
+
+	emailToken := &model.EmailToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: utils.HashEmailToken(token),
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.emailTokenRepo.CreateToken(ctx, emailToken); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// isRateLimited 检查距上次发送同一用途的邮件是否不足最小间隔
+func (s *emailService) isRateLimited(ctx context.Context, userID int64, purpose string) (bool, error) {
+	latest, err := s.emailTokenRepo.GetLatestTokenTime(ctx, userID, purpose)
+	if err != nil {
+		return false, err
+	}
+	return latest != nil && time.Since(*latest) < emailResendInterval, nil
+}
+
+// buildLink 生成前端页面链接
+func (s *emailService) buildLink(path, token string) string {
+	return s.appURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// formatTTL 将有效期格式化为中文描述
+func formatTTL(ttl time.Duration) string {
+	if ttl >= time.Hour && ttl%time.Hour == 0 {
+		return fmt.Sprintf("%d 小时", int(ttl/time.Hour))
+	}
+	return fmt.Sprintf("%d 分钟", int(ttl/time.Minute))
+}
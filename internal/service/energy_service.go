@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+)
+
+const (
+	// DefaultAdaptiveWeeks is the default lookback for adaptive TDEE
+	DefaultAdaptiveWeeks = 4
+	// kcalPerKg is the approximate energy content of a kilogram of body weight
+	kcalPerKg = 7700
+	// minAdaptiveLoggedDays is the fewest days with logged meals adaptive TDEE accepts
+	minAdaptiveLoggedDays = 10
+	// minAdaptiveWeightSpanDays is the shortest weigh-in span adaptive TDEE accepts
+	minAdaptiveWeightSpanDays = 14
+)
+
+var (
+	// ErrIncompleteEnergyProfile is returned when profile values needed for an estimate are missing
+	ErrIncompleteEnergyProfile = errors.New("energy profile is incomplete")
+	// ErrInvalidFormula is returned for unknown formulas or Katch-McArdle without body fat
+	ErrInvalidFormula = errors.New("invalid BMR formula")
+	// ErrInsufficientAdaptiveData is returned when there is not enough intake or weight data
+	ErrInsufficientAdaptiveData = errors.New("not enough logged intake and weight data for adaptive TDEE")
+)
+
+// EnergyService estimates BMR/TDEE and suggests calorie and macro goals
+type EnergyService struct {
+	userPrefsRepo   repository.UserPreferencesRepository
+	bodyMetricRepo  *repository.BodyMetricRepository
+	mealRepo        *repository.MealRepository
+	settingsService SettingsService
+}
+
+// NewEnergyService creates a new EnergyService instance
+func NewEnergyService(
+	userPrefsRepo repository.UserPreferencesRepository,
+	bodyMetricRepo *repository.BodyMetricRepository,
+	mealRepo *repository.MealRepository,
+	settingsService SettingsService,
+) *EnergyService {
+	return &EnergyService{
+		userPrefsRepo:   userPrefsRepo,
+		bodyMetricRepo:  bodyMetricRepo,
+		mealRepo:        mealRepo,
+		settingsService: settingsService,
+	}
+}
+
+// Estimate computes BMR with every applicable formula, TDEE with the requested
+// formula (Katch-McArdle when body fat is known, otherwise Mifflin-St Jeor, if
+// formula is empty) and suggested goals. When the user enabled adaptive TDEE and
+// enough data is available, the adaptive estimate drives the suggestions.
+func (s *EnergyService) Estimate(userID int64, formula string, now time.Time, loc *time.Location) (*model.EnergyEstimate, error) {
+	prefs, err := s.userPrefsRepo.GetPreferences(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user preferences: %w", err)
+	}
+
+	inputs, err := s.energyInputs(userID, prefs, now, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	bmr := calculateBMR(inputs)
+	if formula == "" {
+		formula = model.FormulaMifflinStJeor
+		if _, ok := bmr[model.FormulaKatchMcArdle]; ok {
+			formula = model.FormulaKatchMcArdle
+		}
+	}
+	selected, ok := bmr[formula]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFormula, formula)
+	}
+
+	estimate := &model.EnergyEstimate{
+		Inputs:      *inputs,
+		BMR:         bmr,
+		Formula:     formula,
+		FormulaTDEE: formulaTDEE(selected, inputs.ActivityFactor),
+		TDEESource:  "formula",
+	}
+	estimate.TDEE = estimate.FormulaTDEE
+
+	if prefs.AdaptiveTDEE {
+		adaptive, err := s.AdaptiveTDEE(userID, DefaultAdaptiveWeeks, now, loc)
+		if err != nil && !errors.Is(err, ErrInsufficientAdaptiveData) {
+			return nil, err
+		}
+		if adaptive != nil {
+			estimate.Adaptive = adaptive
+			estimate.TDEE = adaptive.TDEE
+			estimate.TDEESource = "adaptive"
+		}
+	}
+
+	estimate.Suggested = suggestGoals(estimate.TDEE, inputs)
+
+	return estimate, nil
+}
+
+// AdaptiveTDEE estimates TDEE from the past weeks of logged intake and the change in
+// the 7-day moving average weight: TDEE = average intake - weight change * 7700 / days.
+// The window ends yesterday, since today's log is usually incomplete.
+func (s *EnergyService) AdaptiveTDEE(userID int64, weeks int, now time.Time, loc *time.Location) (*model.AdaptiveTDEE, error) {
+	if weeks <= 0 {
+		weeks = DefaultAdaptiveWeeks
+	}
+
+	endDate := utils.StartOfDay(now, loc).AddDate(0, 0, -1)
+	startDate := endDate.AddDate(0, 0, -(weeks*7 - 1))
+
+	meals, err := s.mealRepo.GetMealsByDateRange(userID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meals: %w", err)
+	}
+
+	dailyCalories := make(map[string]float64)
+	for _, meal := range meals {
+		dailyCalories[utils.FormatDate(meal.MealDate)] += meal.Nutrition.Calories
+	}
+	if len(dailyCalories) < minAdaptiveLoggedDays {
+		return nil, fmt.Errorf("%w: %d days with logged meals, need %d", ErrInsufficientAdaptiveData, len(dailyCalories), minAdaptiveLoggedDays)
+	}
+
+	totalCalories := 0.0
+	for _, calories := range dailyCalories {
+		totalCalories += calories
+	}
+	avgIntake := totalCalories / float64(len(dailyCalories))
+
+	metrics, err := s.bodyMetricRepo.GetBodyMetricsByDateRange(userID, startDate.AddDate(0, 0, -(DefaultWeightTrendWindow-1)), endDate)
+	if err != nil {
+		return nil, err
+	}
+	points := weightMovingAverage(metrics, DefaultWeightTrendWindow, utils.FormatDate(startDate))
+	if len(points) < 2 {
+		return nil, fmt.Errorf("%w: need at least two weigh-ins", ErrInsufficientAdaptiveData)
+	}
+
+	first, last := points[0], points[len(points)-1]
+	spanDays := last.day.Sub(first.day).Hours() / 24
+	if spanDays < minAdaptiveWeightSpanDays {
+		return nil, fmt.Errorf("%w: weigh-ins span %.0f days, need %d", ErrInsufficientAdaptiveData, spanDays, minAdaptiveWeightSpanDays)
+	}
+
+	weightChange := last.averageKg - first.averageKg
+	tdee := avgIntake - weightChange*kcalPerKg/spanDays
+
+	return &model.AdaptiveTDEE{
+		StartDate:      utils.FormatDate(startDate),
+		EndDate:        utils.FormatDate(endDate),
+		LoggedDays:     len(dailyCalories),
+		AvgIntake:      math.Round(avgIntake),
+		WeightChangeKg: math.Round(weightChange*100) / 100,
+		TDEE:           math.Round(tdee),
+	}, nil
+}
+
+// ApplySuggestedGoals writes the suggested goals of an estimate into the user's preferences
+func (s *EnergyService) ApplySuggestedGoals(ctx context.Context, userID int64, formula string, now time.Time, loc *time.Location) (*model.EnergyEstimate, error) {
+	estimate, err := s.Estimate(userID, formula, now, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs, err := s.settingsService.GetUserPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs.DailyCaloriesGoal = estimate.Suggested.Calories
+	prefs.DailyProteinGoal = estimate.Suggested.Protein
+	prefs.DailyCarbsGoal = estimate.Suggested.Carbs
+	prefs.DailyFatGoal = estimate.Suggested.Fat
+	prefs.DailyFiberGoal = estimate.Suggested.Fiber
+
+	if err := s.settingsService.UpdateUserPreferences(ctx, userID, prefs); err != nil {
+		return nil, err
+	}
+
+	return estimate, nil
+}
+
+// energyInputs collects profile values and the latest weight and body fat
+func (s *EnergyService) energyInputs(userID int64, prefs *model.UserPreferences, now time.Time, loc *time.Location) (*model.EnergyInputs, error) {
+	if prefs == nil {
+		prefs = &model.UserPreferences{UserID: userID}
+	}
+
+	var missing []string
+	if prefs.Sex == "" {
+		missing = append(missing, "sex")
+	}
+	if prefs.BirthDate == "" {
+		missing = append(missing, "birth_date")
+	}
+	if prefs.HeightCm == nil {
+		missing = append(missing, "height_cm")
+	}
+	if prefs.ActivityLevel == "" {
+		missing = append(missing, "activity_level")
+	}
+
+	weight, err := s.currentWeightKg(userID)
+	if err != nil {
+		return nil, err
+	}
+	if weight == 0 {
+		missing = append(missing, "weight")
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: missing %s", ErrIncompleteEnergyProfile, strings.Join(missing, ", "))
+	}
+
+	birthDate, err := time.Parse("2006-01-02", prefs.BirthDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid birth date: %w", err)
+	}
+
+	weightGoal := prefs.WeightGoal
+	if weightGoal == "" {
+		weightGoal = model.WeightGoalMaintain
+	}
+
+	inputs := &model.EnergyInputs{
+		Sex:            prefs.Sex,
+		Age:            ageOn(birthDate, now.In(loc)),
+		HeightCm:       *prefs.HeightCm,
+		WeightKg:       weight,
+		ActivityLevel:  prefs.ActivityLevel,
+		ActivityFactor: model.ActivityFactors[prefs.ActivityLevel],
+		WeightGoal:     weightGoal,
+	}
+
+	bodyFat, err := s.bodyMetricRepo.GetLatestBodyFat(userID)
+	if err != nil {
+		return nil, err
+	}
+	if bodyFat != nil {
+		inputs.BodyFatPct = bodyFat.BodyFatPct
+	}
+
+	return inputs, nil
+}
+
+// currentWeightKg returns the 7-day moving average ending at the latest weigh-in,
+// or 0 if the user has never logged a weight
+func (s *EnergyService) currentWeightKg(userID int64) (float64, error) {
+	latest, err := s.bodyMetricRepo.GetLatestWeight(userID)
+	if err != nil {
+		return 0, err
+	}
+	if latest == nil {
+		return 0, nil
+	}
+
+	metrics, err := s.bodyMetricRepo.GetBodyMetricsByDateRange(userID, latest.MeasuredOn.AddDate(0, 0, -(DefaultWeightTrendWindow-1)), latest.MeasuredOn)
+	if err != nil {
+		return 0, err
+	}
+	points := weightMovingAverage(metrics, DefaultWeightTrendWindow, utils.FormatDate(latest.MeasuredOn))
+	if len(points) == 0 {
+		return *latest.WeightKg, nil
+	}
+
+	return math.Round(points[len(points)-1].averageKg*10) / 10, nil
+}
+
+// calculateBMR returns BMR (kcal/day) for each formula applicable to the inputs
+func calculateBMR(in *model.EnergyInputs) map[string]float64 {
+	w, h, a := in.WeightKg, in.HeightCm, float64(in.Age)
+	bmr := make(map[string]float64)
+
+	// Mifflin-St Jeor (1990)
+	mifflin := 10*w + 6.25*h - 5*a
+	if in.Sex == model.SexMale {
+		mifflin += 5
+	} else {
+		mifflin -= 161
+	}
+	bmr[model.FormulaMifflinStJeor] = math.Round(mifflin)
+
+	// Harris-Benedict, revised by Roza and Shizgal (1984)
+	var harris float64
+	if in.Sex == model.SexMale {
+		harris = 88.362 + 13.397*w + 4.799*h - 5.677*a
+	} else {
+		harris = 447.593 + 9.247*w + 3.098*h - 4.330*a
+	}
+	bmr[model.FormulaHarrisBenedict] = math.Round(harris)
+
+	// Katch-McArdle, from lean body mass
+	if in.BodyFatPct != nil {
+		leanMass := w * (1 - *in.BodyFatPct/100)
+		bmr[model.FormulaKatchMcArdle] = math.Round(370 + 21.6*leanMass)
+	}
+
+	return bmr
+}
+
+// formulaTDEE scales BMR by the activity factor, rounded to whole kcal
+func formulaTDEE(bmr, activityFactor float64) float64 {
+	return math.Round(bmr * activityFactor)
+}
+
+// suggestGoals derives calorie and macro goals from TDEE: a 20% deficit to lose,
+// a 10% surplus to gain; protein by body weight, 25% of calories from fat, the rest
+// from carbs, and 14 g fiber per 1000 kcal. Values are clamped to the ranges
+// accepted by the preferences validation.
+func suggestGoals(tdee float64, in *model.EnergyInputs) model.NutritionGoals {
+	calories := tdee
+	proteinPerKg := 1.6
+	switch in.WeightGoal {
+	case model.WeightGoalLose:
+		calories = tdee * 0.8
+		proteinPerKg = 2.0
+	case model.WeightGoalGain:
+		calories = tdee * 1.1
+		proteinPerKg = 1.8
+	}
+
+	minCalories := 1200.0
+	if in.Sex == model.SexMale {
+		minCalories = 1500
+	}
+	calories = clampFloat(math.Round(calories/10)*10, minCalories, 10000)
+
+	protein := clampFloat(math.Round(in.WeightKg*proteinPerKg), 0, 500)
+	fat := clampFloat(math.Round(calories*0.25/9), 0, 500)
+	carbs := clampFloat(math.Round((calories-protein*4-fat*9)/4), 0, 1000)
+	fiber := clampFloat(math.Round(calories/1000*14), 0, 200)
+
+	return model.NutritionGoals{
+		Calories: int(calories),
+		Protein:  int(protein),
+		Carbs:    int(carbs),
+		Fat:      int(fat),
+		Fiber:    int(fiber),
+	}
+}
+
+// ageOn returns the age in whole years on the given day
+func ageOn(birthDate, day time.Time) int {
+	age := day.Year() - birthDate.Year()
+	if day.Month() < birthDate.Month() || (day.Month() == birthDate.Month() && day.Day() < birthDate.Day()) {
+		age--
+	}
+	return age
+}
+
+// clampFloat limits v to [min, max]
+func clampFloat(v, min, max float64) float64 {
+	return math.Max(min, math.Min(max, v))
+}
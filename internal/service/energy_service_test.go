@@ -0,0 +1,181 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+)
+
+func TestCalculateBMR(t *testing.T) {
+	bodyFat := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name   string
+		inputs *model.EnergyInputs
+		want   map[string]float64
+	}{
+		{
+			name:   "Male without body fat",
+			inputs: &model.EnergyInputs{Sex: model.SexMale, Age: 30, HeightCm: 180, WeightKg: 80},
+			want: map[string]float64{
+				model.FormulaMifflinStJeor:  1780,
+				model.FormulaHarrisBenedict: 1854,
+			},
+		},
+		{
+			name:   "Female without body fat",
+			inputs: &model.EnergyInputs{Sex: model.SexFemale, Age: 25, HeightCm: 165, WeightKg: 60},
+			want: map[string]float64{
+				model.FormulaMifflinStJeor:  1345,
+				model.FormulaHarrisBenedict: 1405,
+			},
+		},
+		{
+			name:   "Male with body fat",
+			inputs: &model.EnergyInputs{Sex: model.SexMale, Age: 30, HeightCm: 180, WeightKg: 80, BodyFatPct: bodyFat(20)},
+			want: map[string]float64{
+				model.FormulaMifflinStJeor:  1780,
+				model.FormulaHarrisBenedict: 1854,
+				model.FormulaKatchMcArdle:   1752,
+			},
+		},
+		{
+			name:   "Female with body fat",
+			inputs: &model.EnergyInputs{Sex: model.SexFemale, Age: 25, HeightCm: 165, WeightKg: 60, BodyFatPct: bodyFat(30)},
+			want: map[string]float64{
+				model.FormulaMifflinStJeor:  1345,
+				model.FormulaHarrisBenedict: 1405,
+				model.FormulaKatchMcArdle:   1277,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calculateBMR(tt.inputs)
+			if len(got) != len(tt.want) {
+				t.Errorf("calculateBMR() returned %d formulas, want %d: %v", len(got), len(tt.want), got)
+			}
+			for formula, want := range tt.want {
+				if got[formula] != want {
+					t.Errorf("%s = %v, want %v", formula, got[formula], want)
+				}
+			}
+		})
+	}
+}
+
+func TestFormulaTDEE(t *testing.T) {
+	tests := []struct {
+		level string
+		want  float64
+	}{
+		{level: "sedentary", want: 1920},
+		{level: "light", want: 2200},
+		{level: "moderate", want: 2480},
+		{level: "active", want: 2760},
+		{level: "very_active", want: 3040},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			factor, ok := model.ActivityFactors[tt.level]
+			if !ok {
+				t.Fatalf("no activity factor for %s", tt.level)
+			}
+			if got := formulaTDEE(1600, factor); got != tt.want {
+				t.Errorf("formulaTDEE(1600, %v) = %v, want %v", factor, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestGoals(t *testing.T) {
+	tests := []struct {
+		name   string
+		tdee   float64
+		inputs *model.EnergyInputs
+		want   model.NutritionGoals
+	}{
+		{
+			name:   "Maintain",
+			tdee:   2500,
+			inputs: &model.EnergyInputs{Sex: model.SexMale, WeightKg: 80, WeightGoal: model.WeightGoalMaintain},
+			want:   model.NutritionGoals{Calories: 2500, Protein: 128, Carbs: 342, Fat: 69, Fiber: 35},
+		},
+		{
+			name:   "Empty weight goal maintains",
+			tdee:   2500,
+			inputs: &model.EnergyInputs{Sex: model.SexMale, WeightKg: 80},
+			want:   model.NutritionGoals{Calories: 2500, Protein: 128, Carbs: 342, Fat: 69, Fiber: 35},
+		},
+		{
+			name:   "Lose",
+			tdee:   2500,
+			inputs: &model.EnergyInputs{Sex: model.SexMale, WeightKg: 80, WeightGoal: model.WeightGoalLose},
+			want:   model.NutritionGoals{Calories: 2000, Protein: 160, Carbs: 214, Fat: 56, Fiber: 28},
+		},
+		{
+			name:   "Gain",
+			tdee:   2500,
+			inputs: &model.EnergyInputs{Sex: model.SexMale, WeightKg: 80, WeightGoal: model.WeightGoalGain},
+			want:   model.NutritionGoals{Calories: 2750, Protein: 144, Carbs: 373, Fat: 76, Fiber: 39},
+		},
+		{
+			name:   "Male calorie floor",
+			tdee:   1700,
+			inputs: &model.EnergyInputs{Sex: model.SexMale, WeightKg: 80, WeightGoal: model.WeightGoalLose},
+			want:   model.NutritionGoals{Calories: 1500, Protein: 160, Carbs: 121, Fat: 42, Fiber: 21},
+		},
+		{
+			name:   "Female calorie floor",
+			tdee:   1400,
+			inputs: &model.EnergyInputs{Sex: model.SexFemale, WeightKg: 55, WeightGoal: model.WeightGoalLose},
+			want:   model.NutritionGoals{Calories: 1200, Protein: 110, Carbs: 116, Fat: 33, Fiber: 17},
+		},
+		{
+			name:   "Female above the floor",
+			tdee:   1600,
+			inputs: &model.EnergyInputs{Sex: model.SexFemale, WeightKg: 55, WeightGoal: model.WeightGoalLose},
+			want:   model.NutritionGoals{Calories: 1280, Protein: 110, Carbs: 129, Fat: 36, Fiber: 18},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suggestGoals(tt.tdee, tt.inputs); got != tt.want {
+				t.Errorf("suggestGoals() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAgeOn(t *testing.T) {
+	birth := time.Date(1990, 6, 15, 0, 0, 0, 0, time.UTC)
+	leapBirth := time.Date(2000, 2, 29, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		birthDate time.Time
+		day       time.Time
+		want      int
+	}{
+		{name: "Day before birthday", birthDate: birth, day: time.Date(2024, 6, 14, 0, 0, 0, 0, time.UTC), want: 33},
+		{name: "On birthday", birthDate: birth, day: time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC), want: 34},
+		{name: "Day after birthday", birthDate: birth, day: time.Date(2024, 6, 16, 0, 0, 0, 0, time.UTC), want: 34},
+		{name: "Earlier month", birthDate: birth, day: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), want: 33},
+		{name: "Later month", birthDate: birth, day: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), want: 34},
+		{name: "Leap day birthday in a common year", birthDate: leapBirth, day: time.Date(2023, 2, 28, 0, 0, 0, 0, time.UTC), want: 22},
+		{name: "Day after leap day birthday in a common year", birthDate: leapBirth, day: time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC), want: 23},
+		{name: "Leap day birthday in a leap year", birthDate: leapBirth, day: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), want: 24},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ageOn(tt.birthDate, tt.day); got != tt.want {
+				t.Errorf("ageOn() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
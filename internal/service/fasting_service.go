@@ -0,0 +1,347 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+)
+
+const (
+	// CustomFastingProtocol is the protocol name for fasts with a user-defined target
+	CustomFastingProtocol = "custom"
+	// DefaultMinInferredFastHours is the shortest gap between meals reported as a fast
+	DefaultMinInferredFastHours = 12
+)
+
+var (
+	// ErrFastAlreadyActive is returned when starting a fast while another is active
+	ErrFastAlreadyActive = errors.New("a fast is already active")
+	// ErrNoActiveFast is returned when stopping a fast while none is active
+	ErrNoActiveFast = errors.New("no active fast")
+	// ErrInvalidFastingProtocol is returned for unknown protocols or custom fasts without a target
+	ErrInvalidFastingProtocol = errors.New("invalid fasting protocol")
+)
+
+// FastingService handles intermittent fasting business logic
+type FastingService struct {
+	fastingRepo *repository.FastingRepository
+	mealRepo    *repository.MealRepository
+}
+
+// NewFastingService creates a new FastingService instance
+func NewFastingService(fastingRepo *repository.FastingRepository, mealRepo *repository.MealRepository) *FastingService {
+	return &FastingService{
+		fastingRepo: fastingRepo,
+		mealRepo:    mealRepo,
+	}
+}
+
+// StartFast starts a new fast. Only one fast can be active at a time.
+func (s *FastingService) StartFast(userID int64, req *model.StartFastRequest, now time.Time) (*model.Fast, error) {
+	targetHours := req.TargetHours
+	if req.Protocol == CustomFastingProtocol {
+		if targetHours <= 0 {
+			return nil, fmt.Errorf("%w: custom fasts require target_hours", ErrInvalidFastingProtocol)
+		}
+	} else {
+		protocol, ok := model.FindFastingProtocol(req.Protocol)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFastingProtocol, req.Protocol)
+		}
+		if targetHours <= 0 {
+			targetHours = protocol.TargetHours
+		}
+	}
+
+	startedAt := now
+	if req.StartedAt != nil {
+		if req.StartedAt.After(now) {
+			return nil, fmt.Errorf("started_at cannot be in the future")
+		}
+		startedAt = *req.StartedAt
+	}
+
+	active, err := s.fastingRepo.GetActiveFast(userID)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil {
+		return nil, ErrFastAlreadyActive
+	}
+
+	fast := &model.Fast{
+		UserID:      userID,
+		Protocol:    req.Protocol,
+		TargetHours: targetHours,
+		StartedAt:   startedAt,
+		Status:      model.FastStatusActive,
+		Notes:       req.Notes,
+	}
+
+	if err := s.fastingRepo.CreateFast(fast); err != nil {
+		return nil, err
+	}
+
+	return fast, nil
+}
+
+// StopFast ends the user's active fast
+func (s *FastingService) StopFast(userID int64, now time.Time) (*model.Fast, error) {
+	fast, err := s.fastingRepo.GetActiveFast(userID)
+	if err != nil {
+		return nil, err
+	}
+	if fast == nil {
+		return nil, ErrNoActiveFast
+	}
+
+	if err := s.fastingRepo.EndFast(userID, fast.ID, now, model.FastStatusCompleted, nil); err != nil {
+		return nil, err
+	}
+
+	fast.EndedAt = &now
+	fast.Status = model.FastStatusCompleted
+	return fast, nil
+}
+
+// GetCurrentFast returns the progress of the user's active fast, or nil if none is active
+func (s *FastingService) GetCurrentFast(userID int64, now time.Time) (*model.FastStatus, error) {
+	fast, err := s.fastingRepo.GetActiveFast(userID)
+	if err != nil {
+		return nil, err
+	}
+	if fast == nil {
+		return nil, nil
+	}
+
+	target := time.Duration(fast.TargetHours * float64(time.Hour))
+	elapsed := fast.Duration(now)
+	remaining := target - elapsed
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	progress := 0.0
+	if target > 0 {
+		progress = math.Round(float64(elapsed)/float64(target)*10000) / 100
+	}
+
+	return &model.FastStatus{
+		Fast:             fast,
+		ElapsedMinutes:   int(elapsed.Minutes()),
+		RemainingMinutes: int(math.Ceil(remaining.Minutes())),
+		Progress:         progress,
+		TargetEndAt:      fast.StartedAt.Add(target),
+		GoalReached:      elapsed >= target,
+	}, nil
+}
+
+// ListFasts retrieves the user's fasting history with pagination
+func (s *FastingService) ListFasts(userID int64, page, pageSize int) ([]*model.Fast, int, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	return s.fastingRepo.ListFasts(userID, page, pageSize)
+}
+
+// DeleteFast deletes a fast record
+func (s *FastingService) DeleteFast(userID, fastID int64) error {
+	return s.fastingRepo.DeleteFast(userID, fastID)
+}
+
+// GetFastingStats computes totals and streaks over the user's ended fasts. Streak
+// days are the dates (in loc) on which a fast that reached its target ended.
+func (s *FastingService) GetFastingStats(userID int64, now time.Time, loc *time.Location) (*model.FastingStats, error) {
+	fasts, err := s.fastingRepo.GetEndedFasts(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildFastingStats(fasts, now, loc), nil
+}
+
+// buildFastingStats computes totals and streaks over ended fasts, with streak days in loc
+func buildFastingStats(fasts []*model.Fast, now time.Time, loc *time.Location) *model.FastingStats {
+	stats := &model.FastingStats{TotalFasts: len(fasts)}
+	completedDays := make(map[string]bool)
+	var totalHours float64
+	for _, fast := range fasts {
+		hours := fast.Duration(now).Hours()
+		totalHours += hours
+		if hours > stats.LongestFastHours {
+			stats.LongestFastHours = hours
+		}
+
+		if fast.GoalReached(now) && fast.EndedAt != nil {
+			stats.CompletedFasts++
+			completedDays[utils.FormatDate(fast.EndedAt.In(loc))] = true
+			if stats.LastCompletedFastAt == nil || fast.EndedAt.After(*stats.LastCompletedFastAt) {
+				stats.LastCompletedFastAt = fast.EndedAt
+			}
+		}
+	}
+
+	if stats.TotalFasts > 0 {
+		stats.AvgDurationHours = math.Round(totalHours/float64(stats.TotalFasts)*100) / 100
+		stats.CompletionRate = math.Round(float64(stats.CompletedFasts)/float64(stats.TotalFasts)*10000) / 100
+	}
+	stats.LongestFastHours = math.Round(stats.LongestFastHours*100) / 100
+	stats.CurrentStreak, stats.LongestStreak = fastingStreaks(completedDays, utils.StartOfDay(now, loc))
+
+	return stats
+}
+
+// GetFastingSummary returns the active fast and fasting stats for the dashboard
+func (s *FastingService) GetFastingSummary(userID int64, now time.Time, loc *time.Location) (*model.FastingSummary, error) {
+	current, err := s.GetCurrentFast(userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current fast: %w", err)
+	}
+
+	stats, err := s.GetFastingStats(userID, now, loc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fasting stats: %w", err)
+	}
+
+	return &model.FastingSummary{
+		Current: current,
+		Stats:   stats,
+	}, nil
+}
+
+// InferFastingWindows infers fasting windows from logged meal times between startDate
+// and endDate (inclusive): every gap of at least minHours between consecutive meals
+// with a recorded eaten_at is reported as a fast. Estimated meal times are skipped.
+// The day before startDate is included so a fast ending on startDate is found.
+func (s *FastingService) InferFastingWindows(userID int64, startDate, endDate time.Time, minHours float64) ([]*model.FastingWindow, error) {
+	if minHours <= 0 {
+		minHours = DefaultMinInferredFastHours
+	}
+
+	meals, err := s.mealRepo.GetMealsByDateRange(userID, startDate.AddDate(0, 0, -1), endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meals: %w", err)
+	}
+
+	return inferFastingWindows(meals, minHours), nil
+}
+
+// inferFastingWindows reports every gap of at least minHours between consecutive
+// recorded meal times as a fasting window
+func inferFastingWindows(meals []*model.Meal, minHours float64) []*model.FastingWindow {
+	times := make([]time.Time, 0, len(meals))
+	for _, meal := range meals {
+		if meal.HasRecordedTime() {
+			times = append(times, *meal.EatenAt)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool {
+		return times[i].Before(times[j])
+	})
+
+	windows := make([]*model.FastingWindow, 0)
+	for i := 1; i < len(times); i++ {
+		gap := times[i].Sub(times[i-1])
+		if gap.Hours() < minHours {
+			continue
+		}
+		windows = append(windows, &model.FastingWindow{
+			StartedAt:     times[i-1],
+			EndedAt:       times[i],
+			DurationHours: math.Round(gap.Hours()*100) / 100,
+		})
+	}
+
+	return windows
+}
+
+// CheckMealBreaksFast ends the user's active fast if the meal was eaten after the
+// fast started, and returns a warning describing the broken fast. Meals logged
+// for a time before the fast started do not break it.
+func (s *FastingService) CheckMealBreaksFast(userID int64, meal *model.Meal) (*model.MealWarning, error) {
+	if meal.EatenAt == nil {
+		return nil, nil
+	}
+
+	fast, err := s.fastingRepo.GetActiveFast(userID)
+	if err != nil {
+		return nil, err
+	}
+	if fast == nil || !meal.EatenAt.After(fast.StartedAt) {
+		return nil, nil
+	}
+
+	reached := fast.GoalReached(*meal.EatenAt)
+	if err := s.fastingRepo.EndFast(userID, fast.ID, *meal.EatenAt, model.FastStatusBroken, &meal.ID); err != nil {
+		return nil, err
+	}
+
+	hours := fast.Duration(*meal.EatenAt).Hours()
+	message := fmt.Sprintf("this meal ended your %s fast after %.1f of %.1f hours", fast.Protocol, hours, fast.TargetHours)
+	if reached {
+		message = fmt.Sprintf("this meal ended your %s fast after %.1f hours (target reached)", fast.Protocol, hours)
+	}
+
+	return &model.MealWarning{
+		Type:    model.MealWarningFastBroken,
+		Message: message,
+	}, nil
+}
+
+// fastingStreaks returns the current and longest runs of consecutive days in days.
+// The current streak counts back from today, or from yesterday if today has no
+// completed fast yet.
+func fastingStreaks(days map[string]bool, today time.Time) (int, int) {
+	if len(days) == 0 {
+		return 0, 0
+	}
+
+	dates := make([]string, 0, len(days))
+	for d := range days {
+		dates = append(dates, d)
+	}
+	sort.Strings(dates)
+
+	longest, run := 0, 0
+	var prev time.Time
+	for i, d := range dates {
+		date, err := time.Parse("2006-01-02", d)
+		if err != nil {
+			continue
+		}
+		if i > 0 && date.Equal(prev.AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+		prev = date
+	}
+
+	current := 0
+	day := today
+	if !days[utils.FormatDate(day)] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for days[utils.FormatDate(day)] {
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+
+	return current, longest
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+)
+
+func TestFastingStreaks(t *testing.T) {
+	today := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		days        []string
+		wantCurrent int
+		wantLongest int
+	}{
+		{
+			name: "No completed fasts",
+		},
+		{
+			name:        "Consecutive days ending today",
+			days:        []string{"2024-03-08", "2024-03-09", "2024-03-10"},
+			wantCurrent: 3,
+			wantLongest: 3,
+		},
+		{
+			name:        "Current streak ending yesterday",
+			days:        []string{"2024-03-08", "2024-03-09"},
+			wantCurrent: 2,
+			wantLongest: 2,
+		},
+		{
+			name:        "Last completed fast two days ago",
+			days:        []string{"2024-03-07", "2024-03-08"},
+			wantCurrent: 0,
+			wantLongest: 2,
+		},
+		{
+			name:        "Broken streak",
+			days:        []string{"2024-03-01", "2024-03-02", "2024-03-03", "2024-03-04", "2024-03-09", "2024-03-10"},
+			wantCurrent: 2,
+			wantLongest: 4,
+		},
+		{
+			name:        "Streak across month end",
+			days:        []string{"2024-02-28", "2024-02-29", "2024-03-01"},
+			wantCurrent: 0,
+			wantLongest: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days := make(map[string]bool)
+			for _, d := range tt.days {
+				days[d] = true
+			}
+			current, longest := fastingStreaks(days, today)
+			if current != tt.wantCurrent || longest != tt.wantLongest {
+				t.Errorf("fastingStreaks() = (%d, %d), want (%d, %d)", current, longest, tt.wantCurrent, tt.wantLongest)
+			}
+		})
+	}
+}
+
+func TestBuildFastingStatsStreaks(t *testing.T) {
+	shanghai := time.FixedZone("UTC+8", 8*3600)
+	fast := func(start time.Time, hours, target float64) *model.Fast {
+		end := start.Add(time.Duration(hours * float64(time.Hour)))
+		return &model.Fast{StartedAt: start, EndedAt: &end, TargetHours: target}
+	}
+
+	tests := []struct {
+		name          string
+		fasts         []*model.Fast
+		now           time.Time
+		loc           *time.Location
+		wantCompleted int
+		wantCurrent   int
+		wantLongest   int
+	}{
+		{
+			name: "Multiple fasts on one day count once",
+			fasts: []*model.Fast{
+				fast(time.Date(2024, 3, 9, 20, 0, 0, 0, time.UTC), 14, 12),
+				fast(time.Date(2024, 3, 10, 10, 0, 0, 0, time.UTC), 12, 12),
+			},
+			now:           time.Date(2024, 3, 10, 23, 0, 0, 0, time.UTC),
+			loc:           time.UTC,
+			wantCompleted: 2,
+			wantCurrent:   1,
+			wantLongest:   1,
+		},
+		{
+			name: "Fast ending after midnight in user timezone counts on the next day",
+			fasts: []*model.Fast{
+				// Ends 2024-03-09 17:00 UTC, which is 2024-03-10 01:00 in UTC+8
+				fast(time.Date(2024, 3, 9, 1, 0, 0, 0, time.UTC), 16, 16),
+				// Ends 2024-03-08 12:00 UTC, 2024-03-08 20:00 in UTC+8
+				fast(time.Date(2024, 3, 7, 20, 0, 0, 0, time.UTC), 16, 16),
+			},
+			now:           time.Date(2024, 3, 10, 2, 0, 0, 0, time.UTC),
+			loc:           shanghai,
+			wantCompleted: 2,
+			wantCurrent:   1,
+			wantLongest:   1,
+		},
+		{
+			name: "Same fasts in UTC are on consecutive days",
+			fasts: []*model.Fast{
+				fast(time.Date(2024, 3, 9, 1, 0, 0, 0, time.UTC), 16, 16),
+				fast(time.Date(2024, 3, 7, 20, 0, 0, 0, time.UTC), 16, 16),
+			},
+			now:           time.Date(2024, 3, 10, 2, 0, 0, 0, time.UTC),
+			loc:           time.UTC,
+			wantCompleted: 2,
+			wantCurrent:   2,
+			wantLongest:   2,
+		},
+		{
+			name: "Fast that missed its target does not extend the streak",
+			fasts: []*model.Fast{
+				fast(time.Date(2024, 3, 8, 20, 0, 0, 0, time.UTC), 16, 16),
+				fast(time.Date(2024, 3, 9, 20, 0, 0, 0, time.UTC), 10, 16),
+			},
+			now:           time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC),
+			loc:           time.UTC,
+			wantCompleted: 1,
+			wantCurrent:   1,
+			wantLongest:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := buildFastingStats(tt.fasts, tt.now, tt.loc)
+			if stats.CompletedFasts != tt.wantCompleted {
+				t.Errorf("CompletedFasts = %d, want %d", stats.CompletedFasts, tt.wantCompleted)
+			}
+			if stats.CurrentStreak != tt.wantCurrent || stats.LongestStreak != tt.wantLongest {
+				t.Errorf("streaks = (%d, %d), want (%d, %d)", stats.CurrentStreak, stats.LongestStreak, tt.wantCurrent, tt.wantLongest)
+			}
+		})
+	}
+}
+
+func TestInferFastingWindowsSkipsEstimatedTimes(t *testing.T) {
+	at := func(day, hour int) *time.Time {
+		v := time.Date(2024, 3, day, hour, 0, 0, 0, time.UTC)
+		return &v
+	}
+	meals := []*model.Meal{
+		{EatenAt: at(9, 19)},
+		// An estimated breakfast would otherwise split the overnight fast
+		{EatenAt: at(10, 8), EatenAtEstimated: true},
+		{EatenAt: at(10, 12)},
+		{MealType: "snack"},
+	}
+
+	windows := inferFastingWindows(meals, 12)
+
+	if len(windows) != 1 {
+		t.Fatalf("got %d windows, want 1", len(windows))
+	}
+	if !windows[0].StartedAt.Equal(*at(9, 19)) || !windows[0].EndedAt.Equal(*at(10, 12)) {
+		t.Errorf("window = %v - %v, want 19:00 - 12:00", windows[0].StartedAt, windows[0].EndedAt)
+	}
+	if windows[0].DurationHours != 17 {
+		t.Errorf("DurationHours = %v, want 17", windows[0].DurationHours)
+	}
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+)
+
+var (
+	// ErrFoodCategoryNotFound is returned when a category ID or slug does not exist
+	// for the user
+	ErrFoodCategoryNotFound = errors.New("food category not found")
+	// ErrInvalidFoodCategory is returned for malformed slugs and invalid parents
+	ErrInvalidFoodCategory = errors.New("invalid food category")
+	// ErrFoodCategoryExists is returned when creating a category with a slug the user
+	// can already see
+	ErrFoodCategoryExists = errors.New("a food category with this slug already exists")
+	// ErrFoodCategoryReadOnly is returned when changing a built-in category
+	ErrFoodCategoryReadOnly = errors.New("built-in food categories cannot be changed")
+	// ErrFoodCategoryHasChildren is returned when deleting a category with subcategories
+	ErrFoodCategoryHasChildren = errors.New("food category has subcategories")
+)
+
+// FoodCategoryService handles the food category tree: the built-in categories shared
+// by all users plus each user's custom categories
+type FoodCategoryService struct {
+	categoryRepo *repository.FoodCategoryRepository
+}
+
+// NewFoodCategoryService creates a new FoodCategoryService instance
+func NewFoodCategoryService(categoryRepo *repository.FoodCategoryRepository) *FoodCategoryService {
+	return &FoodCategoryService{
+		categoryRepo: categoryRepo,
+	}
+}
+
+// ListCategories returns the categories visible to the user as a flat list
+func (s *FoodCategoryService) ListCategories(userID int64) (model.FoodCategories, error) {
+	return s.categoryRepo.ListCategories(userID)
+}
+
+// GetCategoryTree returns the categories visible to the user as a tree
+func (s *FoodCategoryService) GetCategoryTree(userID int64) ([]*model.FoodCategory, error) {
+	categories, err := s.categoryRepo.ListCategories(userID)
+	if err != nil {
+		return nil, err
+	}
+	return categories.Tree(), nil
+}
+
+// CreateCategory creates a custom category for the user
+func (s *FoodCategoryService) CreateCategory(userID int64, category *model.FoodCategory) error {
+	if !model.IsValidFoodCategorySlug(category.Slug) {
+		return fmt.Errorf("%w: slug must be lowercase letters, digits and underscores, starting with a letter", ErrInvalidFoodCategory)
+	}
+
+	categories, err := s.categoryRepo.ListCategories(userID)
+	if err != nil {
+		return err
+	}
+
+	if categories.FindBySlug(category.Slug) != nil {
+		return ErrFoodCategoryExists
+	}
+
+	if category.ParentID != nil {
+		if _, ok := categories.ByID()[*category.ParentID]; !ok {
+			return fmt.Errorf("%w: parent category not found", ErrInvalidFoodCategory)
+		}
+	}
+
+	category.UserID = &userID
+	category.BuiltIn = false
+
+	return s.categoryRepo.CreateCategory(category)
+}
+
+// UpdateCategory renames or moves one of the user's own categories
+func (s *FoodCategoryService) UpdateCategory(userID, categoryID int64, category *model.FoodCategory) error {
+	categories, err := s.categoryRepo.ListCategories(userID)
+	if err != nil {
+		return err
+	}
+
+	existing, err := ownCategory(categories, categoryID)
+	if err != nil {
+		return err
+	}
+
+	if category.ParentID != nil {
+		if _, ok := categories.ByID()[*category.ParentID]; !ok {
+			return fmt.Errorf("%w: parent category not found", ErrInvalidFoodCategory)
+		}
+		// Moving a category under itself or one of its subcategories would create a cycle
+		if categories.IsDescendant(*category.ParentID, existing.ID) {
+			return fmt.Errorf("%w: a category cannot be moved under itself or its subcategories", ErrInvalidFoodCategory)
+		}
+	}
+
+	if err := s.categoryRepo.UpdateCategory(userID, categoryID, category); err != nil {
+		return err
+	}
+
+	category.ID = existing.ID
+	category.UserID = existing.UserID
+	category.Slug = existing.Slug
+	category.CreatedAt = existing.CreatedAt
+	return nil
+}
+
+// DeleteCategory deletes one of the user's own categories. Categories with
+// subcategories cannot be deleted; the user's foods in the category move to its
+// parent, or to the default category for top-level categories.
+func (s *FoodCategoryService) DeleteCategory(userID, categoryID int64) error {
+	categories, err := s.categoryRepo.ListCategories(userID)
+	if err != nil {
+		return err
+	}
+
+	existing, err := ownCategory(categories, categoryID)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range categories {
+		if c.ParentID != nil && *c.ParentID == existing.ID {
+			return ErrFoodCategoryHasChildren
+		}
+	}
+
+	replacement := model.DefaultFoodCategory
+	if existing.ParentID != nil {
+		if parent, ok := categories.ByID()[*existing.ParentID]; ok {
+			replacement = parent.Slug
+		}
+	}
+
+	return s.categoryRepo.DeleteCategory(userID, existing.ID, existing.Slug, replacement)
+}
+
+// ValidateSlug checks that a food category slug exists for the user
+func (s *FoodCategoryService) ValidateSlug(userID int64, slug string) error {
+	categories, err := s.categoryRepo.ListCategories(userID)
+	if err != nil {
+		return err
+	}
+	if categories.FindBySlug(slug) == nil {
+		return fmt.Errorf("%w: %s", ErrFoodCategoryNotFound, slug)
+	}
+	return nil
+}
+
+// Subtree returns the slugs of a category and all of its subcategories
+func (s *FoodCategoryService) Subtree(userID int64, slug string) ([]string, error) {
+	categories, err := s.categoryRepo.ListCategories(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	category := categories.FindBySlug(slug)
+	if category == nil {
+		return nil, fmt.Errorf("%w: %s", ErrFoodCategoryNotFound, slug)
+	}
+
+	return categories.Subtree(category.ID), nil
+}
+
+// ownCategory returns the user's own category with the given ID
+func ownCategory(categories model.FoodCategories, categoryID int64) (*model.FoodCategory, error) {
+	category, ok := categories.ByID()[categoryID]
+	if !ok {
+		return nil, ErrFoodCategoryNotFound
+	}
+	if category.BuiltIn {
+		return nil, ErrFoodCategoryReadOnly
+	}
+	return category, nil
+}
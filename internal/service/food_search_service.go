@@ -0,0 +1,432 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+)
+
+const (
+	// foodUsageWindowDays is how far back meals are counted when boosting
+	// frequently and recently used foods
+	foodUsageWindowDays = 90
+	// defaultCompletionLimit and maxCompletionLimit bound autocomplete results
+	defaultCompletionLimit = 10
+	maxCompletionLimit     = 20
+	// defaultCompletionAmount is suggested for foods that have not been eaten yet
+	defaultCompletionAmount = 100
+)
+
+// Match scores by match type; usage boosts add up to 25 on top, and favorites get
+// another 10
+const (
+	scoreExact           = 100
+	scorePrefix          = 90
+	scoreWordPrefix      = 80
+	scorePinyinExact     = 75
+	scoreSubstring       = 70
+	scorePinyinPrefix    = 65
+	scorePinyinInitials  = 60
+	scorePinyinSyllables = 55
+	scoreFuzzy           = 45 // Less 10 per edit
+	scoreFuzzyMin        = 15
+	scoreFavoriteBoost   = 10
+	// aliasScoreFactor slightly prefers matches on the name over matches on an alias
+	aliasScoreFactor = 0.95
+)
+
+// FoodSearchService searches the user's foods by name and alias. Catalogs are
+// per user and small enough to rank in memory, which allows typo-tolerant and
+// pinyin matching that SQL indexes cannot do.
+type FoodSearchService struct {
+	foodRepo        *repository.FoodRepository
+	mealRepo        *repository.MealRepository
+	categoryService *FoodCategoryService
+}
+
+// NewFoodSearchService creates a new FoodSearchService instance
+func NewFoodSearchService(
+	foodRepo *repository.FoodRepository,
+	mealRepo *repository.MealRepository,
+	categoryService *FoodCategoryService,
+) *FoodSearchService {
+	return &FoodSearchService{
+		foodRepo:        foodRepo,
+		mealRepo:        mealRepo,
+		categoryService: categoryService,
+	}
+}
+
+// Search returns the user's foods matching the query and the filter, best matches
+// first and paginated by the filter. Matches are ranked by how closely the name or
+// an alias matches, boosted by how often and how recently the food was eaten.
+func (s *FoodSearchService) Search(userID int64, query string, filter *model.FoodFilter) ([]*model.FoodSearchResult, int, error) {
+	// Set default pagination values
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 20
+	}
+	if filter.PageSize > 100 {
+		filter.PageSize = 100
+	}
+
+	if err := resolveFoodFilter(s.categoryService, userID, filter); err != nil {
+		return nil, 0, err
+	}
+
+	ranked, usage, err := s.rank(userID, query, filter, "")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := len(ranked)
+	start := (filter.Page - 1) * filter.PageSize
+	if start > total {
+		start = total
+	}
+	end := min(start+filter.PageSize, total)
+
+	results := make([]*model.FoodSearchResult, 0, end-start)
+	for _, match := range ranked[start:end] {
+		result := &model.FoodSearchResult{
+			Food:      match.food,
+			Score:     math.Round(match.score*10) / 10,
+			MatchType: match.matchType,
+			MatchedOn: match.matchedOn,
+		}
+		if u, ok := usage[match.food.ID]; ok {
+			lastUsed := u.LastUsed
+			result.UseCount = u.UseCount
+			result.LastUsed = &lastUsed
+		}
+		results = append(results, result)
+	}
+
+	return results, total, nil
+}
+
+// Autocomplete returns available foods matching a partial query for meal entry,
+// with the amount and unit the user last ate them in. When a meal type is given,
+// foods usually eaten at that meal rank higher.
+func (s *FoodSearchService) Autocomplete(userID int64, query, mealType string, limit int) ([]*model.FoodCompletion, error) {
+	if limit <= 0 {
+		limit = defaultCompletionLimit
+	}
+	if limit > maxCompletionLimit {
+		limit = maxCompletionLimit
+	}
+
+	available := true
+	ranked, usage, err := s.rank(userID, query, &model.FoodFilter{Available: &available}, mealType)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	completions := make([]*model.FoodCompletion, 0, len(ranked))
+	for _, match := range ranked {
+		completion := &model.FoodCompletion{
+			ID:        match.food.ID,
+			Name:      match.food.Name,
+			MatchedOn: match.matchedOn,
+			MatchType: match.matchType,
+			Category:  match.food.Category,
+			Calories:  match.food.Calories,
+			Amount:    defaultCompletionAmount,
+			Unit:      match.food.Unit,
+		}
+		if u, ok := usage[match.food.ID]; ok && u.LastAmount > 0 {
+			completion.Amount = u.LastAmount
+			if u.LastUnit != "" {
+				completion.Unit = u.LastUnit
+			}
+		}
+		completions = append(completions, completion)
+	}
+
+	return completions, nil
+}
+
+// foodMatch is a food that matched a search query
+type foodMatch struct {
+	food      *model.Food
+	score     float64
+	matchType string
+	matchedOn string
+}
+
+// rank returns the foods matching the query and the filter ordered by score, with
+// the usage used for boosting
+func (s *FoodSearchService) rank(userID int64, query string, filter *model.FoodFilter, mealType string) ([]*foodMatch, map[int64]*model.FoodUsage, error) {
+	q := newSearchQuery(query)
+	if q.text == "" {
+		return []*foodMatch{}, map[int64]*model.FoodUsage{}, nil
+	}
+
+	foods, err := s.foodRepo.ListAllFoods(userID, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	today := time.Now()
+	usage, err := s.mealRepo.GetFoodUsage(userID, today.AddDate(0, 0, -foodUsageWindowDays))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	matches := make([]*foodMatch, 0)
+	for _, food := range foods {
+		match := matchFood(q, food)
+		if match == nil {
+			continue
+		}
+		match.score += usageBoost(usage[food.ID], mealType, today)
+		if food.Favorite {
+			match.score += scoreFavoriteBoost
+		}
+		matches = append(matches, match)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		// Prefer shorter names among equal matches: "egg" before "egg noodles"
+		if len([]rune(matches[i].food.Name)) != len([]rune(matches[j].food.Name)) {
+			return len([]rune(matches[i].food.Name)) < len([]rune(matches[j].food.Name))
+		}
+		return matches[i].food.ID < matches[j].food.ID
+	})
+
+	return matches, usage, nil
+}
+
+// usageBoost scores how often and how recently a food was eaten: up to 15 for
+// frequency (diminishing with the number of uses) and up to 10 for recency
+// (fading over 30 days). With a meal type, the recency part is scaled by the share
+// of uses at that meal.
+func usageBoost(usage *model.FoodUsage, mealType string, today time.Time) float64 {
+	if usage == nil || usage.UseCount == 0 {
+		return 0
+	}
+
+	boost := math.Min(15, 5*math.Log2(1+float64(usage.UseCount)))
+
+	recency := 0.0
+	if days := today.Sub(usage.LastUsed).Hours() / 24; days < 30 {
+		recency = 10 * (1 - math.Max(days, 0)/30)
+	}
+	if mealType != "" {
+		recency *= float64(usage.MealTypes[mealType]) / float64(usage.UseCount)
+	}
+
+	return boost + recency
+}
+
+// searchQuery is a normalized search query
+type searchQuery struct {
+	text   string   // Normalized query
+	tokens []string // Words of the query
+	pinyin string   // Query as pinyin without spaces, for matching Chinese names
+	latin  bool     // Whether the query has only Latin letters and digits
+}
+
+// newSearchQuery normalizes a raw query
+func newSearchQuery(query string) *searchQuery {
+	q := &searchQuery{text: utils.NormalizeSearchText(query)}
+	if q.text == "" {
+		return q
+	}
+	q.tokens = strings.Fields(q.text)
+
+	// Latin queries may be pinyin; Chinese queries are converted so that a
+	// character typed with the wrong homophone still finds the food
+	syllables, _ := utils.Pinyin(q.text)
+	q.pinyin = strings.Join(syllables, "")
+	q.latin = isLatin(q.text)
+	return q
+}
+
+// searchKey is a name or alias prepared for matching
+type searchKey struct {
+	source    string   // Original name or alias
+	text      string   // Normalized text
+	tokens    []string // Words of the text
+	syllables []string // Pinyin syllables, if the text has Chinese characters
+	pinyin    string   // Pinyin without spaces
+	initials  string   // Pinyin initials
+}
+
+// newSearchKey prepares a name or alias for matching
+func newSearchKey(source string) *searchKey {
+	key := &searchKey{source: source, text: utils.NormalizeSearchText(source)}
+	key.tokens = strings.Fields(key.text)
+	if syllables, ok := utils.Pinyin(key.text); ok {
+		key.syllables = syllables
+		key.pinyin = strings.Join(syllables, "")
+		key.initials = utils.PinyinInitials(syllables)
+	}
+	return key
+}
+
+// matchFood matches the query against the food's name and aliases, returning the
+// best match or nil
+func matchFood(q *searchQuery, food *model.Food) *foodMatch {
+	var best *foodMatch
+
+	sources := append([]string{food.Name}, food.Aliases...)
+	for i, source := range sources {
+		score, matchType := matchKey(q, newSearchKey(source))
+		if score == 0 {
+			continue
+		}
+		if i > 0 {
+			score *= aliasScoreFactor
+		}
+		if best == nil || score > best.score {
+			best = &foodMatch{food: food, score: score, matchType: matchType, matchedOn: source}
+		}
+	}
+
+	return best
+}
+
+// matchKey scores how well the query matches a name or alias, 0 if it does not
+func matchKey(q *searchQuery, key *searchKey) (float64, string) {
+	if key.text == "" {
+		return 0, ""
+	}
+
+	switch {
+	case key.text == q.text:
+		return scoreExact, model.FoodMatchExact
+	case strings.HasPrefix(key.text, q.text):
+		return scorePrefix, model.FoodMatchPrefix
+	case tokensPrefixMatch(q.tokens, key.tokens):
+		return scoreWordPrefix, model.FoodMatchPrefix
+	}
+
+	if key.pinyin != "" && q.pinyin != "" {
+		switch {
+		case key.pinyin == q.pinyin:
+			return scorePinyinExact, model.FoodMatchPinyin
+		case strings.Contains(key.text, q.text):
+			return scoreSubstring, model.FoodMatchSubstring
+		case strings.HasPrefix(key.pinyin, q.pinyin):
+			return scorePinyinPrefix, model.FoodMatchPinyin
+		case q.latin && len(q.pinyin) >= 2 && strings.HasPrefix(key.initials, q.pinyin):
+			return scorePinyinInitials, model.FoodMatchPinyin
+		case syllablePrefixMatch(key.syllables, q.pinyin):
+			return scorePinyinSyllables, model.FoodMatchPinyin
+		}
+	} else if strings.Contains(key.text, q.text) {
+		return scoreSubstring, model.FoodMatchSubstring
+	}
+
+	if distance, ok := fuzzyMatch(q, key); ok {
+		return math.Max(scoreFuzzy-10*float64(distance), scoreFuzzyMin), model.FoodMatchFuzzy
+	}
+
+	return 0, ""
+}
+
+// tokensPrefixMatch reports whether every query word is a prefix of a different
+// word of the text, in any order: "breast chick" matches "chicken breast"
+func tokensPrefixMatch(queryTokens, tokens []string) bool {
+	if len(queryTokens) == 0 || len(queryTokens) > len(tokens) {
+		return false
+	}
+
+	used := make([]bool, len(tokens))
+	for _, qt := range queryTokens {
+		found := false
+		for i, t := range tokens {
+			if !used[i] && strings.HasPrefix(t, qt) {
+				used[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// syllablePrefixMatch reports whether the pinyin query matches the pinyin of the
+// text starting at a syllable other than the first: "xiongrou" matches 鸡胸肉
+func syllablePrefixMatch(syllables []string, pinyin string) bool {
+	for i := 1; i < len(syllables); i++ {
+		if strings.HasPrefix(strings.Join(syllables[i:], ""), pinyin) {
+			return true
+		}
+	}
+	return false
+}
+
+// fuzzyMatch matches Latin query words against the words of the text (and its
+// pinyin) allowing typos: one edit for words of 3-5 letters and two for longer
+// words. Each query word may match a whole word or the beginning of one, so
+// partially typed words are tolerated too. It returns the total number of edits.
+func fuzzyMatch(q *searchQuery, key *searchKey) (int, bool) {
+	if !q.latin {
+		return 0, false
+	}
+
+	targets := key.tokens
+	if key.pinyin != "" {
+		targets = append(append([]string{}, key.tokens...), key.pinyin)
+	}
+
+	total := 0
+	for _, qt := range q.tokens {
+		qlen := len([]rune(qt))
+		allowed := 0
+		switch {
+		case qlen >= 6:
+			allowed = 2
+		case qlen >= 3:
+			allowed = 1
+		}
+
+		best := -1
+		for _, t := range targets {
+			distance := utils.EditDistance(qt, t)
+			if runes := []rune(t); len(runes) > qlen {
+				distance = min(distance, utils.EditDistance(qt, string(runes[:qlen])))
+			}
+			if best < 0 || distance < best {
+				best = distance
+			}
+		}
+
+		if best < 0 || best > allowed {
+			return 0, false
+		}
+		total += best
+	}
+
+	return total, true
+}
+
+// isLatin reports whether the text has only Latin letters, digits and spaces
+func isLatin(text string) bool {
+	for _, r := range text {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
@@ -10,15 +11,23 @@ import (
 
 // FoodService handles food business logic
 type FoodService struct {
-	foodRepo *repository.FoodRepository
-	validate *validator.Validate
+	foodRepo        *repository.FoodRepository
+	categoryService *FoodCategoryService
+	dietaryService  *DietaryService
+	validate        *validator.Validate
 }
 
 // NewFoodService creates a new FoodService instance
-func NewFoodService(foodRepo *repository.FoodRepository) *FoodService {
+func NewFoodService(
+	foodRepo *repository.FoodRepository,
+	categoryService *FoodCategoryService,
+	dietaryService *DietaryService,
+) *FoodService {
 	return &FoodService{
-		foodRepo: foodRepo,
-		validate: validator.New(),
+		foodRepo:        foodRepo,
+		categoryService: categoryService,
+		dietaryService:  dietaryService,
+		validate:        validator.New(),
 	}
 }
 
@@ -32,6 +41,10 @@ func (s *FoodService) CreateFood(userID int64, food *model.Food) error {
 	// Force user_id to the authenticated user
 	food.UserID = userID
 
+	if err := s.prepareTaxonomy(userID, food); err != nil {
+		return err
+	}
+
 	// Set default values
 	if food.Unit == "" {
 		food.Unit = "g"
@@ -58,6 +71,13 @@ func (s *FoodService) UpdateFood(userID, foodID int64, food *model.Food) error {
 		return fmt.Errorf("food not found")
 	}
 
+	if err := s.prepareTaxonomy(userID, food); err != nil {
+		return err
+	}
+
+	// Favorites are changed through SetFavorite only
+	food.Favorite = existing.Favorite
+
 	return s.foodRepo.UpdateFood(userID, foodID, food)
 }
 
@@ -71,6 +91,21 @@ func (s *FoodService) GetFood(userID, foodID int64) (*model.Food, error) {
 	return s.foodRepo.GetFoodByID(userID, foodID)
 }
 
+// SetFavorite pins or unpins one of the user's foods
+func (s *FoodService) SetFavorite(userID, foodID int64, favorite bool) (*model.Food, error) {
+	food, err := s.foodRepo.GetFoodByID(userID, foodID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.foodRepo.SetFavorite(userID, foodID, favorite); err != nil {
+		return nil, err
+	}
+
+	food.Favorite = favorite
+	return food, nil
+}
+
 // ListFoods retrieves a list of foods with filtering and pagination
 func (s *FoodService) ListFoods(userID int64, filter *model.FoodFilter) ([]*model.Food, int, error) {
 	// Set default pagination values
@@ -84,9 +119,24 @@ func (s *FoodService) ListFoods(userID int64, filter *model.FoodFilter) ([]*mode
 		filter.PageSize = 100
 	}
 
+	if err := resolveFoodFilter(s.categoryService, userID, filter); err != nil {
+		return nil, 0, err
+	}
+
 	return s.foodRepo.ListFoods(userID, filter)
 }
 
+// ListTags returns the tags used on the user's foods, most used first
+func (s *FoodService) ListTags(userID int64) ([]*model.FoodTag, error) {
+	return s.foodRepo.ListTags(userID)
+}
+
+// GetDietaryRestrictions returns the user's dietary restrictions, used to filter
+// foods by compatibility
+func (s *FoodService) GetDietaryRestrictions(userID int64) (model.DietaryRestrictions, error) {
+	return s.dietaryService.GetRestrictions(userID)
+}
+
 // BatchImport imports multiple food items with validation
 func (s *FoodService) BatchImport(userID int64, foods []*model.Food) (*model.BatchResult, error) {
 	result := &model.BatchResult{
@@ -108,6 +158,12 @@ func (s *FoodService) BatchImport(userID int64, foods []*model.Food) (*model.Bat
 			continue
 		}
 
+		if err := s.prepareTaxonomy(userID, food); err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("row %d: %v", i+1, err))
+			continue
+		}
+
 		// Set default values
 		if food.Unit == "" {
 			food.Unit = "g"
@@ -128,3 +184,63 @@ func (s *FoodService) BatchImport(userID int64, foods []*model.Food) (*model.Bat
 
 	return result, nil
 }
+
+// prepareTaxonomy defaults and validates the food's category and normalizes its tags
+// and aliases
+func (s *FoodService) prepareTaxonomy(userID int64, food *model.Food) error {
+	if food.Category == "" {
+		food.Category = model.DefaultFoodCategory
+	}
+	if err := s.categoryService.ValidateSlug(userID, food.Category); err != nil {
+		return err
+	}
+
+	food.Tags = normalizeTags(food.Tags)
+	food.Aliases = normalizeAliases(food.Aliases)
+	return nil
+}
+
+// resolveFoodFilter expands the filter's category to its subtree, unless the
+// categories were already chosen, and normalizes its tags
+func resolveFoodFilter(categoryService *FoodCategoryService, userID int64, filter *model.FoodFilter) error {
+	if filter.Category != "" && len(filter.Categories) == 0 {
+		categories, err := categoryService.Subtree(userID, filter.Category)
+		if err != nil {
+			return err
+		}
+		filter.Categories = categories
+	}
+	filter.Tags = normalizeTags(filter.Tags)
+	return nil
+}
+
+// normalizeTags trims and lowercases tags, dropping empty and duplicate ones
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// normalizeAliases trims aliases, dropping empty ones and ones that differ only in case
+func normalizeAliases(aliases []string) []string {
+	normalized := make([]string, 0, len(aliases))
+	seen := make(map[string]bool, len(aliases))
+	for _, alias := range aliases {
+		alias = strings.TrimSpace(alias)
+		key := strings.ToLower(alias)
+		if alias == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, alias)
+	}
+	return normalized
+}
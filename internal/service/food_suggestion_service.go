@@ -0,0 +1,221 @@
+package service
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+)
+
+const (
+	// suggestionHistoryDays is how far back meals are considered for suggestions
+	suggestionHistoryDays = 90
+	// defaultSuggestionLimit and maxSuggestionLimit bound the suggested foods
+	defaultSuggestionLimit = 20
+	maxSuggestionLimit     = 50
+	// maxMealCombinations is the number of whole-meal combinations suggested
+	maxMealCombinations = 5
+	// weekdayWeight is how much more a use on the same weekday counts: people
+	// tend to eat the same things on the same days (weekday breakfasts, Friday dinners)
+	weekdayWeight = 2
+)
+
+// FoodSuggestionService suggests foods and whole meals when logging a meal, from
+// the user's favorites and what they have eaten at the same meal before
+type FoodSuggestionService struct {
+	foodRepo *repository.FoodRepository
+	mealRepo *repository.MealRepository
+}
+
+// NewFoodSuggestionService creates a new FoodSuggestionService instance
+func NewFoodSuggestionService(foodRepo *repository.FoodRepository, mealRepo *repository.MealRepository) *FoodSuggestionService {
+	return &FoodSuggestionService{
+		foodRepo: foodRepo,
+		mealRepo: mealRepo,
+	}
+}
+
+// GetMealSuggestions suggests foods and whole-meal combinations for a meal type on
+// a date, from the meals logged at that meal type in the 90 days before the date.
+// Uses on the same weekday count extra. Favorites are always suggested first;
+// unavailable and deleted foods are left out.
+func (s *FoodSuggestionService) GetMealSuggestions(userID int64, date time.Time, mealType string, limit int) (*model.MealSuggestions, error) {
+	if limit <= 0 {
+		limit = defaultSuggestionLimit
+	}
+	if limit > maxSuggestionLimit {
+		limit = maxSuggestionLimit
+	}
+
+	meals, err := s.mealRepo.GetMealsByDateRange(userID, date.AddDate(0, 0, -suggestionHistoryDays), date.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	weekday := date.Weekday()
+	stats := make(map[int64]*model.FoodSuggestion)
+	combinations := make(map[string]*model.MealCombination)
+	foodIDs := make([]int64, 0)
+
+	// Meals come in date order, so later meals overwrite the "last eaten" fields
+	for _, meal := range meals {
+		if meal.MealType != mealType {
+			continue
+		}
+		sameWeekday := meal.MealDate.Weekday() == weekday
+		mealDate := meal.MealDate
+
+		for _, mf := range meal.Foods {
+			stat, ok := stats[mf.FoodID]
+			if !ok {
+				stat = &model.FoodSuggestion{}
+				stats[mf.FoodID] = stat
+				foodIDs = append(foodIDs, mf.FoodID)
+			}
+			stat.UseCount++
+			if sameWeekday {
+				stat.WeekdayCount++
+			}
+			stat.LastEaten = &mealDate
+			stat.Amount = mf.Amount
+			stat.Unit = mf.Unit
+		}
+
+		// Single foods are covered by the food suggestions
+		key := combinationKey(meal.Foods)
+		if strings.Contains(key, ",") {
+			combination, ok := combinations[key]
+			if !ok {
+				combination = &model.MealCombination{}
+				combinations[key] = combination
+			}
+			combination.Count++
+			if sameWeekday {
+				combination.WeekdayCount++
+			}
+			combination.LastEaten = meal.MealDate
+			combination.Foods = append([]model.MealFood(nil), meal.Foods...)
+			combination.Nutrition = meal.Nutrition
+		}
+	}
+
+	available := true
+	favorites, err := s.foodRepo.ListAllFoods(userID, &model.FoodFilter{Available: &available, Favorite: &available})
+	if err != nil {
+		return nil, err
+	}
+
+	foods, err := s.foodRepo.GetFoodsByIDs(userID, foodIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, food := range favorites {
+		foods[food.ID] = food
+	}
+
+	suggestions := &model.MealSuggestions{
+		Date:     utils.FormatDate(date),
+		Weekday:  strings.ToLower(weekday.String()),
+		MealType: mealType,
+		Foods:    make([]*model.FoodSuggestion, 0),
+		Meals:    make([]*model.MealCombination, 0),
+	}
+
+	for _, food := range foods {
+		if !food.Available {
+			continue
+		}
+		suggestion, ok := stats[food.ID]
+		if !ok {
+			// A favorite not eaten at this meal yet
+			suggestion = &model.FoodSuggestion{Amount: defaultCompletionAmount, Unit: food.Unit}
+		}
+		suggestion.Food = food
+		suggestions.Foods = append(suggestions.Foods, suggestion)
+	}
+
+	sort.Slice(suggestions.Foods, func(i, j int) bool {
+		a, b := suggestions.Foods[i], suggestions.Foods[j]
+		if a.Food.Favorite != b.Food.Favorite {
+			return a.Food.Favorite
+		}
+		if scoreA, scoreB := a.UseCount+weekdayWeight*a.WeekdayCount, b.UseCount+weekdayWeight*b.WeekdayCount; scoreA != scoreB {
+			return scoreA > scoreB
+		}
+		if lastEatenAfter(a.LastEaten, b.LastEaten) != lastEatenAfter(b.LastEaten, a.LastEaten) {
+			return lastEatenAfter(a.LastEaten, b.LastEaten)
+		}
+		return a.Food.Name < b.Food.Name
+	})
+	if len(suggestions.Foods) > limit {
+		suggestions.Foods = suggestions.Foods[:limit]
+	}
+
+	for _, combination := range combinations {
+		if combination.Count < 2 || !allAvailable(combination.Foods, foods) {
+			continue
+		}
+		// Show the foods under their current names
+		for i := range combination.Foods {
+			combination.Foods[i].Name = foods[combination.Foods[i].FoodID].Name
+		}
+		suggestions.Meals = append(suggestions.Meals, combination)
+	}
+
+	sort.Slice(suggestions.Meals, func(i, j int) bool {
+		a, b := suggestions.Meals[i], suggestions.Meals[j]
+		if scoreA, scoreB := a.Count+weekdayWeight*a.WeekdayCount, b.Count+weekdayWeight*b.WeekdayCount; scoreA != scoreB {
+			return scoreA > scoreB
+		}
+		return a.LastEaten.After(b.LastEaten)
+	})
+	if len(suggestions.Meals) > maxMealCombinations {
+		suggestions.Meals = suggestions.Meals[:maxMealCombinations]
+	}
+
+	return suggestions, nil
+}
+
+// combinationKey identifies the set of foods in a meal regardless of order and
+// amounts
+func combinationKey(foods []model.MealFood) string {
+	ids := make([]int64, 0, len(foods))
+	seen := make(map[int64]bool, len(foods))
+	for _, mf := range foods {
+		if !seen[mf.FoodID] {
+			seen[mf.FoodID] = true
+			ids = append(ids, mf.FoodID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatInt(id, 10)
+	}
+	return strings.Join(parts, ",")
+}
+
+// allAvailable reports whether every food of a meal still exists and is available
+func allAvailable(mealFoods []model.MealFood, foods map[int64]*model.Food) bool {
+	for _, mf := range mealFoods {
+		food, ok := foods[mf.FoodID]
+		if !ok || !food.Available {
+			return false
+		}
+	}
+	return true
+}
+
+// lastEatenAfter reports whether a was eaten more recently than b; never eaten
+// counts as least recent
+func lastEatenAfter(a, b *time.Time) bool {
+	if a == nil {
+		return false
+	}
+	return b == nil || a.After(*b)
+}
@@ -0,0 +1,392 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+)
+
+var (
+	// ErrGoalInPast is returned when scheduling a goal period that starts before today
+	ErrGoalInPast = errors.New("goal changes cannot take effect before today")
+	// ErrGoalNotScheduled is returned when deleting a goal period that is already in effect
+	ErrGoalNotScheduled = errors.New("only scheduled goal changes can be deleted")
+	// ErrInvalidGoalProfile is returned for malformed profile names or weekday keys
+	ErrInvalidGoalProfile = errors.New("invalid goal profile")
+	// ErrUnknownGoalProfile is returned when assigning a profile that has no goals
+	ErrUnknownGoalProfile = errors.New("goal profile has no goals")
+)
+
+// GoalService handles nutrition goal history and goal profile business logic. Goals
+// and weekday schedules are versioned by effective date so past days are always judged
+// against the goals in effect at the time.
+type GoalService struct {
+	goalRepo      *repository.NutritionGoalRepository
+	userPrefsRepo repository.UserPreferencesRepository
+}
+
+// NewGoalService creates a new GoalService instance
+func NewGoalService(goalRepo *repository.NutritionGoalRepository, userPrefsRepo repository.UserPreferencesRepository) *GoalService {
+	return &GoalService{
+		goalRepo:      goalRepo,
+		userPrefsRepo: userPrefsRepo,
+	}
+}
+
+// GetTimeline returns the goal periods of all profiles. Users without any everyday
+// goal period get one with their preference goals (or the defaults).
+func (s *GoalService) GetTimeline(userID int64) (model.GoalTimeline, error) {
+	goals, err := s.goalRepo.ListGoals(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	timeline := model.GoalTimeline(goals)
+	if _, ok := timeline.ProfileOn(time.Time{}, model.BaseGoalProfile); ok {
+		return timeline, nil
+	}
+
+	prefs, err := s.userPrefsRepo.GetPreferences(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user preferences: %w", err)
+	}
+
+	base := &model.GoalPeriod{UserID: userID, NutritionGoals: preferenceGoals(prefs)}
+	return append(model.GoalTimeline{base}, timeline...), nil
+}
+
+// GetCalendar returns everything needed to resolve the goals of each day between
+// startDate and endDate (inclusive)
+func (s *GoalService) GetCalendar(userID int64, startDate, endDate time.Time) (*model.GoalCalendar, error) {
+	timeline, err := s.GetTimeline(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules, err := s.goalRepo.ListSchedules(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := s.goalRepo.GetOverridesByDateRange(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs, err := s.userPrefsRepo.GetPreferences(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user preferences: %w", err)
+	}
+
+	calendar := &model.GoalCalendar{
+		Timeline:  timeline,
+		Schedules: schedules,
+		Overrides: make(map[string]string, len(overrides)),
+	}
+	for _, override := range overrides {
+		calendar.Overrides[utils.FormatDate(override.Date)] = override.Profile
+	}
+	if prefs != nil {
+		calendar.ExerciseFlexPct = prefs.ExerciseFlexPct
+	}
+
+	return calendar, nil
+}
+
+// GetGoalsOn returns the goals for the calendar day of day, resolving its goal profile
+func (s *GoalService) GetGoalsOn(userID int64, day time.Time) (model.ResolvedGoals, error) {
+	calendar, err := s.GetCalendar(userID, day, day)
+	if err != nil {
+		return model.ResolvedGoals{}, err
+	}
+
+	return calendar.On(day), nil
+}
+
+// ListGoals returns all recorded goal periods with their status relative to today
+func (s *GoalService) ListGoals(userID int64, today time.Time) ([]*model.GoalPeriod, error) {
+	goals, err := s.goalRepo.ListGoals(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each profile has its own current period
+	todayStr := utils.FormatDate(today)
+	current := make(map[string]*model.GoalPeriod)
+	for _, goal := range goals {
+		if utils.FormatDate(goal.EffectiveFrom) <= todayStr {
+			current[goal.Profile] = goal
+		}
+	}
+
+	for _, goal := range goals {
+		switch {
+		case current[goal.Profile] == goal:
+			goal.Status = model.GoalStatusCurrent
+		case utils.FormatDate(goal.EffectiveFrom) < todayStr:
+			goal.Status = model.GoalStatusPast
+		default:
+			goal.Status = model.GoalStatusScheduled
+		}
+	}
+
+	return goals, nil
+}
+
+// ScheduleGoal adds a goal period for the everyday goals or a profile, starting today
+// or on a later date. A period of the same profile that already starts on the same
+// date is replaced.
+func (s *GoalService) ScheduleGoal(userID int64, req *model.ScheduleGoalRequest, today time.Time) (*model.GoalPeriod, error) {
+	if req.Profile != model.BaseGoalProfile && !model.IsValidGoalProfileName(req.Profile) {
+		return nil, fmt.Errorf("%w: name must be a lowercase slug of at most 30 characters", ErrInvalidGoalProfile)
+	}
+	if utils.FormatDate(req.EffectiveFrom) < utils.FormatDate(today) {
+		return nil, ErrGoalInPast
+	}
+
+	// The first everyday goal change must not be stretched back over earlier days
+	if req.Profile == model.BaseGoalProfile {
+		prefs, err := s.userPrefsRepo.GetPreferences(userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user preferences: %w", err)
+		}
+		if err := ensureBaseGoal(s.goalRepo, userID, preferenceGoals(prefs), today); err != nil {
+			return nil, err
+		}
+	}
+
+	goal := &model.GoalPeriod{
+		UserID:        userID,
+		Profile:       req.Profile,
+		EffectiveFrom: req.EffectiveFrom,
+		NutritionGoals: model.NutritionGoals{
+			Calories: req.Calories,
+			Protein:  req.Protein,
+			Carbs:    req.Carbs,
+			Fat:      req.Fat,
+			Fiber:    req.Fiber,
+		},
+		Note: req.Note,
+	}
+
+	if err := s.goalRepo.UpsertGoal(goal); err != nil {
+		return nil, err
+	}
+
+	return s.goalRepo.GetGoalByID(userID, goal.ID)
+}
+
+// DeleteGoal deletes a scheduled goal period. Periods already in effect are history
+// and cannot be deleted.
+func (s *GoalService) DeleteGoal(userID, goalID int64, today time.Time) error {
+	goal, err := s.goalRepo.GetGoalByID(userID, goalID)
+	if err != nil {
+		return err
+	}
+
+	if utils.FormatDate(goal.EffectiveFrom) <= utils.FormatDate(today) {
+		return ErrGoalNotScheduled
+	}
+
+	return s.goalRepo.DeleteGoal(userID, goalID)
+}
+
+// ListProfiles returns the user's goal profiles with their goals in effect today and
+// the weekdays assigned to them by the current schedule
+func (s *GoalService) ListProfiles(userID int64, today time.Time) ([]*model.GoalProfile, error) {
+	calendar, err := s.GetCalendar(userID, today, today)
+	if err != nil {
+		return nil, err
+	}
+
+	var currentSchedule *model.GoalProfileSchedule
+	for _, schedule := range calendar.Schedules {
+		if utils.FormatDate(schedule.EffectiveFrom) <= utils.FormatDate(today) {
+			currentSchedule = schedule
+		}
+	}
+
+	profiles := make([]*model.GoalProfile, 0)
+	seen := make(map[string]bool)
+	for _, period := range calendar.Timeline {
+		if period.Profile == model.BaseGoalProfile || seen[period.Profile] {
+			continue
+		}
+		seen[period.Profile] = true
+
+		goals, _ := calendar.Timeline.ProfileOn(today, period.Profile)
+		profile := &model.GoalProfile{Name: period.Profile, Goals: goals, Weekdays: []string{}}
+		if currentSchedule != nil {
+			for _, weekday := range model.Weekdays {
+				if currentSchedule.WeekdayProfiles[weekday] == period.Profile {
+					profile.Weekdays = append(profile.Weekdays, weekday)
+				}
+			}
+		}
+		profiles = append(profiles, profile)
+	}
+
+	return profiles, nil
+}
+
+// ListSchedules returns all weekday schedules with their status relative to today
+func (s *GoalService) ListSchedules(userID int64, today time.Time) ([]*model.GoalProfileSchedule, error) {
+	schedules, err := s.goalRepo.ListSchedules(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	todayStr := utils.FormatDate(today)
+	currentIndex := -1
+	for i, schedule := range schedules {
+		if utils.FormatDate(schedule.EffectiveFrom) <= todayStr {
+			currentIndex = i
+		}
+	}
+
+	for i, schedule := range schedules {
+		switch {
+		case i == currentIndex:
+			schedule.Status = model.GoalStatusCurrent
+		case i < currentIndex:
+			schedule.Status = model.GoalStatusPast
+		default:
+			schedule.Status = model.GoalStatusScheduled
+		}
+	}
+
+	return schedules, nil
+}
+
+// SetSchedule assigns profiles to weekdays from today (or a later date) on. Weekdays
+// left out use the everyday goals; an empty schedule turns weekday profiles off.
+func (s *GoalService) SetSchedule(userID int64, req *model.SetGoalProfileScheduleRequest, today time.Time) (*model.GoalProfileSchedule, error) {
+	effectiveFrom := today
+	if req.EffectiveFrom != nil {
+		effectiveFrom = *req.EffectiveFrom
+	}
+	if utils.FormatDate(effectiveFrom) < utils.FormatDate(today) {
+		return nil, ErrGoalInPast
+	}
+
+	timeline, err := s.GetTimeline(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	weekdayProfiles := make(map[string]string)
+	for weekday, profile := range req.WeekdayProfiles {
+		if !isWeekdayKey(weekday) {
+			return nil, fmt.Errorf("%w: unknown weekday %q", ErrInvalidGoalProfile, weekday)
+		}
+		if profile == model.BaseGoalProfile {
+			continue
+		}
+		if _, ok := timeline.ProfileOn(effectiveFrom, profile); !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownGoalProfile, profile)
+		}
+		weekdayProfiles[weekday] = profile
+	}
+
+	schedule := &model.GoalProfileSchedule{
+		UserID:          userID,
+		EffectiveFrom:   effectiveFrom,
+		WeekdayProfiles: weekdayProfiles,
+	}
+	if err := s.goalRepo.UpsertSchedule(schedule); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// ListOverrides returns the per-date profile overrides between startDate and endDate (inclusive)
+func (s *GoalService) ListOverrides(userID int64, startDate, endDate time.Time) ([]*model.GoalProfileOverride, error) {
+	return s.goalRepo.GetOverridesByDateRange(userID, startDate, endDate)
+}
+
+// SetOverride assigns a profile to a single date, taking precedence over the weekday
+// schedule. An empty profile forces the everyday goals on that date.
+func (s *GoalService) SetOverride(userID int64, req *model.SetGoalProfileOverrideRequest) (*model.GoalProfileOverride, error) {
+	if req.Profile != model.BaseGoalProfile {
+		timeline, err := s.GetTimeline(userID)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := timeline.ProfileOn(req.Date, req.Profile); !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownGoalProfile, req.Profile)
+		}
+	}
+
+	override := &model.GoalProfileOverride{
+		UserID:  userID,
+		Date:    req.Date,
+		Profile: req.Profile,
+		Note:    req.Note,
+	}
+	if err := s.goalRepo.UpsertOverride(override); err != nil {
+		return nil, err
+	}
+
+	return override, nil
+}
+
+// DeleteOverride deletes a per-date profile override
+func (s *GoalService) DeleteOverride(userID, overrideID int64) error {
+	return s.goalRepo.DeleteOverride(userID, overrideID)
+}
+
+// isWeekdayKey reports whether key is one of model.Weekdays
+func isWeekdayKey(key string) bool {
+	for _, weekday := range model.Weekdays {
+		if key == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureBaseGoal records goals as the everyday goals since account creation when the
+// user has no everyday goal period yet. Without it the first recorded change would be
+// applied to all earlier days as well.
+func ensureBaseGoal(goalRepo *repository.NutritionGoalRepository, userID int64, goals model.NutritionGoals, today time.Time) error {
+	periods, err := goalRepo.ListGoals(userID)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := model.GoalTimeline(periods).ProfileOn(time.Time{}, model.BaseGoalProfile); ok {
+		return nil
+	}
+	return goalRepo.CreateBaseGoal(userID, goals, today)
+}
+
+// preferenceGoals returns the goals stored in preferences, with defaults for unset values
+func preferenceGoals(prefs *model.UserPreferences) model.NutritionGoals {
+	goals := model.DefaultNutritionGoals()
+	if prefs == nil {
+		return goals
+	}
+
+	if prefs.DailyCaloriesGoal > 0 {
+		goals.Calories = prefs.DailyCaloriesGoal
+	}
+	if prefs.DailyProteinGoal > 0 {
+		goals.Protein = prefs.DailyProteinGoal
+	}
+	if prefs.DailyCarbsGoal > 0 {
+		goals.Carbs = prefs.DailyCarbsGoal
+	}
+	if prefs.DailyFatGoal > 0 {
+		goals.Fat = prefs.DailyFatGoal
+	}
+	if prefs.DailyFiberGoal > 0 {
+		goals.Fiber = prefs.DailyFiberGoal
+	}
+
+	return goals
+}
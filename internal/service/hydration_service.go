@@ -0,0 +1,185 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+)
+
+// HydrationService handles water intake business logic. Water intake is the sum of
+// water log entries and the water in beverage foods eaten as part of meals, so drinks
+// such as milk or juice are logged once and count toward both nutrition and hydration.
+type HydrationService struct {
+	waterLogRepo  *repository.WaterLogRepository
+	mealRepo      *repository.MealRepository
+	foodRepo      *repository.FoodRepository
+	userPrefsRepo repository.UserPreferencesRepository
+}
+
+// NewHydrationService creates a new HydrationService instance
+func NewHydrationService(
+	waterLogRepo *repository.WaterLogRepository,
+	mealRepo *repository.MealRepository,
+	foodRepo *repository.FoodRepository,
+	userPrefsRepo repository.UserPreferencesRepository,
+) *HydrationService {
+	return &HydrationService{
+		waterLogRepo:  waterLogRepo,
+		mealRepo:      mealRepo,
+		foodRepo:      foodRepo,
+		userPrefsRepo: userPrefsRepo,
+	}
+}
+
+// CreateWaterLog logs a drink
+func (s *HydrationService) CreateWaterLog(userID int64, req *model.WaterLogRequest) (*model.WaterLog, error) {
+	log := toWaterLog(req)
+	log.UserID = userID
+
+	if err := s.waterLogRepo.CreateWaterLog(log); err != nil {
+		return nil, err
+	}
+
+	// Reload to pick up timestamps
+	return s.waterLogRepo.GetWaterLogByID(userID, log.ID)
+}
+
+// UpdateWaterLog replaces a water log entry
+func (s *HydrationService) UpdateWaterLog(userID, logID int64, req *model.WaterLogRequest) error {
+	return s.waterLogRepo.UpdateWaterLog(userID, logID, toWaterLog(req))
+}
+
+// DeleteWaterLog deletes a water log entry
+func (s *HydrationService) DeleteWaterLog(userID, logID int64) error {
+	return s.waterLogRepo.DeleteWaterLog(userID, logID)
+}
+
+// GetWaterLog retrieves a water log entry
+func (s *HydrationService) GetWaterLog(userID, logID int64) (*model.WaterLog, error) {
+	return s.waterLogRepo.GetWaterLogByID(userID, logID)
+}
+
+// ListWaterLogs lists water log entries between startDate and endDate (inclusive), oldest first
+func (s *HydrationService) ListWaterLogs(userID int64, startDate, endDate time.Time) ([]*model.WaterLog, error) {
+	return s.waterLogRepo.GetWaterLogsByDateRange(userID, startDate, endDate)
+}
+
+// GetDailyHydration returns water intake for each day between startDate and endDate
+// (inclusive), including days without any intake
+func (s *HydrationService) GetDailyHydration(userID int64, startDate, endDate time.Time) ([]*model.DailyHydration, error) {
+	prefs, err := s.userPrefsRepo.GetPreferences(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user preferences: %w", err)
+	}
+	goal := prefs.WaterGoalOrDefault()
+
+	days := make(map[string]*model.DailyHydration)
+	result := make([]*model.DailyHydration, 0)
+	for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
+		day := &model.DailyHydration{Date: utils.FormatDate(d), GoalML: goal}
+		days[day.Date] = day
+		result = append(result, day)
+	}
+
+	logs, err := s.waterLogRepo.GetWaterLogsByDateRange(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	for _, log := range logs {
+		if day, ok := days[utils.FormatDate(log.LogDate)]; ok {
+			day.LoggedML += float64(log.AmountML)
+			day.LogCount++
+		}
+	}
+
+	fromFoods, err := s.waterFromMeals(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	for date, ml := range fromFoods {
+		if day, ok := days[date]; ok {
+			day.FromFoodsML = ml
+		}
+	}
+
+	for _, day := range result {
+		day.TotalML = day.LoggedML + day.FromFoodsML
+		day.GoalPct = math.Round(day.TotalML/float64(goal)*1000) / 10
+	}
+
+	return result, nil
+}
+
+// GetWaterByDay returns total water intake in ml between startDate and endDate
+// (inclusive), keyed by YYYY-MM-DD
+func (s *HydrationService) GetWaterByDay(userID int64, startDate, endDate time.Time) (map[string]float64, error) {
+	days, err := s.GetDailyHydration(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	water := make(map[string]float64, len(days))
+	for _, day := range days {
+		water[day.Date] = day.TotalML
+	}
+
+	return water, nil
+}
+
+// waterFromMeals sums the water in beverage foods eaten between startDate and endDate
+// (inclusive), keyed by YYYY-MM-DD. Amounts are in grams, treated as ml for drinks.
+func (s *HydrationService) waterFromMeals(userID int64, startDate, endDate time.Time) (map[string]float64, error) {
+	meals, err := s.mealRepo.GetMealsByDateRange(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int64]bool)
+	foodIDs := make([]int64, 0)
+	for _, meal := range meals {
+		for _, mealFood := range meal.Foods {
+			if !seen[mealFood.FoodID] {
+				seen[mealFood.FoodID] = true
+				foodIDs = append(foodIDs, mealFood.FoodID)
+			}
+		}
+	}
+
+	foods, err := s.foodRepo.GetFoodsByIDs(userID, foodIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	water := make(map[string]float64)
+	for _, meal := range meals {
+		for _, mealFood := range meal.Foods {
+			food, ok := foods[mealFood.FoodID]
+			if !ok || food.WaterPct <= 0 {
+				continue
+			}
+			water[utils.FormatDate(meal.MealDate)] += mealFood.Amount * food.WaterPct / 100
+		}
+	}
+
+	return water, nil
+}
+
+// toWaterLog converts a request to a water log entry
+func toWaterLog(req *model.WaterLogRequest) *model.WaterLog {
+	log := &model.WaterLog{
+		LogDate:      req.LogDate,
+		LoggedAt:     req.LoggedAt,
+		AmountML:     req.AmountML,
+		BeverageType: req.BeverageType,
+		Notes:        req.Notes,
+	}
+	if log.BeverageType == "" {
+		log.BeverageType = model.BeverageWater
+	}
+
+	return log
+}
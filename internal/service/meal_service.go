@@ -2,24 +2,40 @@ package service
 
 import (
 	"fmt"
+	"math"
+	"sort"
+	"time"
 
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
 	"github.com/go-playground/validator/v10"
 )
 
 // MealService handles meal business logic
 type MealService struct {
 	mealRepo         *repository.MealRepository
+	userPrefsRepo    repository.UserPreferencesRepository
 	nutritionService *NutritionService
+	fastingService   *FastingService
+	dietaryService   *DietaryService
 	validate         *validator.Validate
 }
 
 // NewMealService creates a new MealService instance
-func NewMealService(mealRepo *repository.MealRepository, nutritionService *NutritionService) *MealService {
+func NewMealService(
+	mealRepo *repository.MealRepository,
+	userPrefsRepo repository.UserPreferencesRepository,
+	nutritionService *NutritionService,
+	fastingService *FastingService,
+	dietaryService *DietaryService,
+) *MealService {
 	return &MealService{
 		mealRepo:         mealRepo,
+		userPrefsRepo:    userPrefsRepo,
 		nutritionService: nutritionService,
+		fastingService:   fastingService,
+		dietaryService:   dietaryService,
 		validate:         validator.New(),
 	}
 }
@@ -42,8 +58,36 @@ func (s *MealService) CreateMeal(userID int64, meal *model.Meal) error {
 
 	meal.Nutrition = *nutrition
 
+	// Check foods against the user's dietary restrictions
+	warnings, err := s.dietaryService.CheckFoods(userID, meal.Foods)
+	if err != nil {
+		return err
+	}
+	meal.Warnings = warnings
+
+	// Default eaten_at to the user's preferred time for this meal type
+	meal.EatenAtEstimated = false
+	if meal.EatenAt == nil {
+		eatenAt, err := s.defaultEatenAt(userID, meal.MealDate, meal.MealType)
+		if err != nil {
+			return err
+		}
+		meal.EatenAt = &eatenAt
+		meal.EatenAtEstimated = true
+	}
+
 	// Create meal record
-	return s.mealRepo.CreateMeal(meal)
+	if err := s.mealRepo.CreateMeal(meal); err != nil {
+		return err
+	}
+
+	// Warn if the meal breaks an active fast. The meal is already saved, so a
+	// failed check does not fail the request.
+	if warning, err := s.fastingService.CheckMealBreaksFast(userID, meal); err == nil && warning != nil {
+		meal.Warnings = append(meal.Warnings, warning)
+	}
+
+	return nil
 }
 
 // UpdateMeal updates an existing meal record
@@ -71,6 +115,30 @@ func (s *MealService) UpdateMeal(userID, mealID int64, meal *model.Meal) error {
 
 	meal.Nutrition = *nutrition
 
+	warnings, err := s.dietaryService.CheckFoods(userID, meal.Foods)
+	if err != nil {
+		return err
+	}
+	meal.Warnings = warnings
+
+	// Keep the recorded eaten_at unless the meal moved to another date or slot
+	meal.EatenAtEstimated = false
+	if meal.EatenAt == nil {
+		if existing.EatenAt != nil &&
+			utils.FormatDate(existing.MealDate) == utils.FormatDate(meal.MealDate) &&
+			existing.MealType == meal.MealType {
+			meal.EatenAt = existing.EatenAt
+			meal.EatenAtEstimated = existing.EatenAtEstimated
+		} else {
+			eatenAt, err := s.defaultEatenAt(userID, meal.MealDate, meal.MealType)
+			if err != nil {
+				return err
+			}
+			meal.EatenAt = &eatenAt
+			meal.EatenAtEstimated = true
+		}
+	}
+
 	return s.mealRepo.UpdateMeal(userID, mealID, meal)
 }
 
@@ -100,8 +168,8 @@ func (s *MealService) ListMeals(userID int64, filter *model.MealFilter) ([]*mode
 	return s.mealRepo.ListMeals(userID, filter)
 }
 
-// GetMonthlyStats retrieves monthly meal statistics
-func (s *MealService) GetMonthlyStats(userID int64, year, month int) (*model.MonthlyStats, error) {
+// GetMonthlyStats retrieves monthly meal statistics, with days in loc (the user's timezone)
+func (s *MealService) GetMonthlyStats(userID int64, year, month int, loc *time.Location) (*model.MonthlyStats, error) {
 	// Get all meals for the month
 	meals, err := s.mealRepo.GetMonthlyMeals(userID, year, month)
 	if err != nil {
@@ -109,7 +177,7 @@ func (s *MealService) GetMonthlyStats(userID int64, year, month int) (*model.Mon
 	}
 
 	// Get daily trend
-	dailyStats, err := s.nutritionService.GetMonthlyTrend(userID, year, month)
+	dailyStats, err := s.nutritionService.GetMonthlyTrend(userID, year, month, loc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get monthly trend: %w", err)
 	}
@@ -158,5 +226,206 @@ func (s *MealService) GetMonthlyStats(userID int64, year, month int) (*model.Mon
 		Total:      totalNutrition,
 	}
 
+	for _, day := range dailyStats {
+		stats.TotalCaloriesBurned += day.CaloriesBurned
+		stats.TotalWaterML += day.WaterML
+	}
+	stats.TotalNetCalories = totalNutrition.Calories - stats.TotalCaloriesBurned
+	if daysInMonth > 0 {
+		stats.AvgDailyNetCalories = stats.TotalNetCalories / float64(daysInMonth)
+		stats.AvgDailyWaterML = stats.TotalWaterML / float64(daysInMonth)
+	}
+
 	return stats, nil
 }
+
+// GetMealTimingStats analyzes when meals were eaten between startDate and endDate
+// (inclusive), with clock times in loc (the user's timezone). Meals without eaten_at
+// or with an estimated eaten_at are counted as untracked and excluded from the timing figures.
+func (s *MealService) GetMealTimingStats(userID int64, startDate, endDate time.Time, loc *time.Location) (*model.MealTimingStats, error) {
+	meals, err := s.mealRepo.GetMealsByDateRange(userID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meals: %w", err)
+	}
+
+	return buildMealTimingStats(meals, startDate, endDate, loc), nil
+}
+
+// buildMealTimingStats computes the timing analytics of meals, with clock times in loc
+func buildMealTimingStats(meals []*model.Meal, startDate, endDate time.Time, loc *time.Location) *model.MealTimingStats {
+	stats := &model.MealTimingStats{
+		StartDate:        utils.FormatDate(startDate),
+		EndDate:          utils.FormatDate(endDate),
+		MealTypeAvgTimes: make(map[string]string),
+		Days:             make([]*model.DailyMealTiming, 0),
+	}
+
+	days := make(map[string]*model.DailyMealTiming)
+	typeMinutes := make(map[string][]int)
+	for _, meal := range meals {
+		if !meal.HasRecordedTime() {
+			stats.UntrackedMeals++
+			continue
+		}
+		stats.TrackedMeals++
+
+		eatenAt := meal.EatenAt.In(loc)
+		date := utils.FormatDate(meal.MealDate)
+		day, ok := days[date]
+		if !ok {
+			day = &model.DailyMealTiming{Date: date, FirstMealAt: eatenAt, LastMealAt: eatenAt}
+			days[date] = day
+		}
+		day.MealCount++
+		if eatenAt.Before(day.FirstMealAt) {
+			day.FirstMealAt = eatenAt
+		}
+		if eatenAt.After(day.LastMealAt) {
+			day.LastMealAt = eatenAt
+		}
+		if isLateNight(eatenAt) {
+			day.LateNightMeals++
+			stats.LateNightMeals++
+		}
+
+		typeMinutes[meal.MealType] = append(typeMinutes[meal.MealType], minutesIntoDay(eatenAt))
+	}
+
+	var firstMinutes, lastMinutes []int
+	totalWindow := 0
+	for _, day := range days {
+		day.EatingWindowMinutes = int(day.LastMealAt.Sub(day.FirstMealAt).Minutes())
+		totalWindow += day.EatingWindowMinutes
+		firstMinutes = append(firstMinutes, minutesIntoDay(day.FirstMealAt))
+		lastMinutes = append(lastMinutes, minutesIntoDay(day.LastMealAt))
+		stats.Days = append(stats.Days, day)
+	}
+	sort.Slice(stats.Days, func(i, j int) bool {
+		return stats.Days[i].Date < stats.Days[j].Date
+	})
+
+	stats.DaysTracked = len(stats.Days)
+	if stats.DaysTracked > 0 {
+		stats.AvgFirstMealTime = formatClock(averageMinutes(firstMinutes))
+		stats.AvgLastMealTime = formatClock(averageMinutes(lastMinutes))
+		stats.AvgEatingWindowMinutes = math.Round(float64(totalWindow)/float64(stats.DaysTracked)*10) / 10
+	}
+	if stats.TrackedMeals > 0 {
+		stats.LateNightFrequency = math.Round(float64(stats.LateNightMeals)/float64(stats.TrackedMeals)*10000) / 10000
+	}
+	for mealType, minutes := range typeMinutes {
+		stats.MealTypeAvgTimes[mealType] = formatClock(averageMinutes(minutes))
+	}
+
+	return stats
+}
+
+// GetMealReminders returns today's reminders (in loc) for each meal type, based on the
+// user's preferred meal times. A reminder is due once its time has passed and no meal
+// of that type has been logged today.
+func (s *MealService) GetMealReminders(userID int64, now time.Time, loc *time.Location) ([]*model.MealReminder, error) {
+	prefs, err := s.userPrefsRepo.GetPreferences(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user preferences: %w", err)
+	}
+
+	today := utils.StartOfDay(now, loc)
+	meals, err := s.mealRepo.GetMealsByDateRange(userID, today, today)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get today's meals: %w", err)
+	}
+
+	logged := make(map[string]bool)
+	for _, meal := range meals {
+		logged[meal.MealType] = true
+	}
+
+	reminders := make([]*model.MealReminder, 0, 4)
+	for _, mealType := range []string{"breakfast", "lunch", "snack", "dinner"} {
+		preferredTime := prefs.MealTimeFor(mealType)
+		remindAt, err := atClockTime(today, preferredTime, loc)
+		if err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, &model.MealReminder{
+			MealType:      mealType,
+			PreferredTime: preferredTime,
+			RemindAt:      remindAt,
+			Logged:        logged[mealType],
+			Due:           !logged[mealType] && !now.Before(remindAt),
+		})
+	}
+
+	sort.SliceStable(reminders, func(i, j int) bool {
+		return reminders[i].RemindAt.Before(reminders[j].RemindAt)
+	})
+
+	return reminders, nil
+}
+
+// defaultEatenAt returns the meal's calendar date at the user's preferred time for
+// the meal type, in the user's timezone
+func (s *MealService) defaultEatenAt(userID int64, mealDate time.Time, mealType string) (time.Time, error) {
+	prefs, err := s.userPrefsRepo.GetPreferences(userID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get user preferences: %w", err)
+	}
+	return defaultEatenAt(prefs, mealDate, mealType)
+}
+
+// defaultEatenAt combines a meal date with the preferred time for its meal type
+func defaultEatenAt(prefs *model.UserPreferences, mealDate time.Time, mealType string) (time.Time, error) {
+	tzName := ""
+	if prefs != nil {
+		tzName = prefs.Timezone
+	}
+	loc, err := utils.LoadLocation(tzName)
+	if err != nil {
+		loc = time.Local
+	}
+
+	return atClockTime(utils.DateInLocation(mealDate, loc), prefs.MealTimeFor(mealType), loc)
+}
+
+// atClockTime returns the given calendar day at an "HH:MM" clock time in loc
+func atClockTime(day time.Time, clock string, loc *time.Location) (time.Time, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid meal time %q: %w", clock, err)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, loc), nil
+}
+
+// isLateNight reports whether a local time falls in the late-night window
+func isLateNight(t time.Time) bool {
+	return t.Hour() >= model.LateNightStartHour || t.Hour() < model.LateNightEndHour
+}
+
+// minutesIntoDay returns minutes since local midnight. Times before the end of the
+// late-night window are counted as the previous evening so that averages of
+// after-midnight meals stay sensible.
+func minutesIntoDay(t time.Time) int {
+	minutes := t.Hour()*60 + t.Minute()
+	if t.Hour() < model.LateNightEndHour {
+		minutes += 24 * 60
+	}
+	return minutes
+}
+
+// averageMinutes returns the rounded mean of the given minute values
+func averageMinutes(values []int) int {
+	if len(values) == 0 {
+		return 0
+	}
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return int(math.Round(float64(total) / float64(len(values))))
+}
+
+// formatClock formats minutes since midnight as "HH:MM"
+func formatClock(minutes int) string {
+	minutes %= 24 * 60
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
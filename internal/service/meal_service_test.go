@@ -0,0 +1,40 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+)
+
+func TestBuildMealTimingStatsSkipsEstimatedTimes(t *testing.T) {
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	at := func(hour, minute int) *time.Time {
+		v := day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+		return &v
+	}
+	meals := []*model.Meal{
+		{MealDate: day, MealType: "breakfast", EatenAt: at(8, 0)},
+		{MealDate: day, MealType: "lunch", EatenAt: at(12, 0), EatenAtEstimated: true},
+		{MealDate: day, MealType: "dinner", EatenAt: at(19, 30)},
+		{MealDate: day, MealType: "snack"},
+	}
+
+	stats := buildMealTimingStats(meals, day, day, time.UTC)
+
+	if stats.TrackedMeals != 2 || stats.UntrackedMeals != 2 {
+		t.Errorf("tracked/untracked = %d/%d, want 2/2", stats.TrackedMeals, stats.UntrackedMeals)
+	}
+	if stats.DaysTracked != 1 {
+		t.Errorf("DaysTracked = %d, want 1", stats.DaysTracked)
+	}
+	if stats.AvgFirstMealTime != "08:00" || stats.AvgLastMealTime != "19:30" {
+		t.Errorf("first/last = %s/%s, want 08:00/19:30", stats.AvgFirstMealTime, stats.AvgLastMealTime)
+	}
+	if stats.AvgEatingWindowMinutes != 690 {
+		t.Errorf("AvgEatingWindowMinutes = %v, want 690", stats.AvgEatingWindowMinutes)
+	}
+	if _, ok := stats.MealTypeAvgTimes["lunch"]; ok {
+		t.Errorf("estimated lunch time should not be averaged: %v", stats.MealTypeAvgTimes)
+	}
+}
@@ -49,6 +49,8 @@ type messageProxyService struct {
 	convRepo       repository.ConversationRepository
 	msgRepo        repository.MessageRepository
 	aiSettingsRepo *repository.AISettingsRepository
+	userPrefsRepo  repository.UserPreferencesRepository
+	goalService    *GoalService
 }
 
 // NewMessageProxyService creates a new message proxy service
@@ -56,11 +58,15 @@ func NewMessageProxyService(
 	convRepo repository.ConversationRepository,
 	msgRepo repository.MessageRepository,
 	aiSettingsRepo *repository.AISettingsRepository,
+	userPrefsRepo repository.UserPreferencesRepository,
+	goalService *GoalService,
 ) MessageProxyService {
 	return &messageProxyService{
 		convRepo:       convRepo,
 		msgRepo:        msgRepo,
 		aiSettingsRepo: aiSettingsRepo,
+		userPrefsRepo:  userPrefsRepo,
+		goalService:    goalService,
 	}
 }
 
@@ -101,8 +107,18 @@ func (s *messageProxyService) SendMessage(ctx context.Context, userID, convID in
 		return nil, fmt.Errorf("failed to get conversation history: %w", err)
 	}
 
-	// Build AI request with conversation history
-	aiMessages := make([]model.AIProxyMessage, 0, len(messages)+1)
+	// Describe the user's goals for today and tomorrow so answers use the right goal profile
+	goalContext, err := s.buildGoalContext(userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to build goal context: %w", err)
+	}
+
+	// Build AI request with goal context and conversation history
+	aiMessages := make([]model.AIProxyMessage, 0, len(messages)+2)
+	aiMessages = append(aiMessages, model.AIProxyMessage{
+		Role:    model.MessageRoleSystem,
+		Content: goalContext,
+	})
 	for _, msg := range messages {
 		aiMessages = append(aiMessages, model.AIProxyMessage{
 			Role:    msg.Role,
@@ -201,6 +217,47 @@ func (s *messageProxyService) SendMessage(ctx context.Context, userID, convID in
 	}, nil
 }
 
+// buildGoalContext describes the user's nutrition goals for today and tomorrow (in the
+// user's timezone), including the goal profile of each day
+func (s *messageProxyService) buildGoalContext(userID int64, now time.Time) (string, error) {
+	prefs, err := s.userPrefsRepo.GetPreferences(userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user preferences: %w", err)
+	}
+
+	loc := time.Local
+	if prefs != nil {
+		if userLoc, err := utils.LoadLocation(prefs.Timezone); err == nil {
+			loc = userLoc
+		}
+	}
+
+	today := utils.StartOfDay(now, loc)
+	tomorrow := today.AddDate(0, 0, 1)
+	calendar, err := s.goalService.GetCalendar(userID, today, tomorrow)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("The user's daily nutrition goals:")
+	for _, day := range []struct {
+		label string
+		date  time.Time
+	}{{"Today", today}, {"Tomorrow", tomorrow}} {
+		goals := calendar.On(day.date)
+		profile := "everyday goals"
+		if goals.Profile != model.BaseGoalProfile {
+			profile = fmt.Sprintf("%q goal profile", goals.Profile)
+		}
+		fmt.Fprintf(&b, "\n- %s (%s, %s, %s): %d kcal, protein %d g, carbs %d g, fat %d g, fiber %d g",
+			day.label, utils.FormatDate(day.date), day.date.Weekday(), profile,
+			goals.Calories, goals.Protein, goals.Carbs, goals.Fat, goals.Fiber)
+	}
+
+	return b.String(), nil
+}
+
 // loadAIConfig loads AI configuration for a user
 func (s *messageProxyService) loadAIConfig(ctx context.Context, userID int64) (*model.AIProxyConfig, error) {
 	// Get active AI settings for user
@@ -2,23 +2,39 @@ package service
 
 import (
 	"fmt"
+	"math"
+	"sort"
 	"time"
 
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
 )
 
 // NutritionService handles nutrition calculation and analysis
 type NutritionService struct {
-	foodRepo *repository.FoodRepository
-	mealRepo *repository.MealRepository
+	foodRepo     *repository.FoodRepository
+	mealRepo     *repository.MealRepository
+	activityRepo *repository.ActivityRepository
+
+	hydrationService *HydrationService
+	categoryService  *FoodCategoryService
 }
 
 // NewNutritionService creates a new NutritionService instance
-func NewNutritionService(foodRepo *repository.FoodRepository, mealRepo *repository.MealRepository) *NutritionService {
+func NewNutritionService(
+	foodRepo *repository.FoodRepository,
+	mealRepo *repository.MealRepository,
+	activityRepo *repository.ActivityRepository,
+	hydrationService *HydrationService,
+	categoryService *FoodCategoryService,
+) *NutritionService {
 	return &NutritionService{
-		foodRepo: foodRepo,
-		mealRepo: mealRepo,
+		foodRepo:         foodRepo,
+		mealRepo:         mealRepo,
+		activityRepo:     activityRepo,
+		hydrationService: hydrationService,
+		categoryService:  categoryService,
 	}
 }
 
@@ -53,11 +69,12 @@ func (s *NutritionService) CalculateNutrition(userID int64, foods []model.MealFo
 	return nutrition, nil
 }
 
-// GetDailyStats calculates nutrition statistics for a specific day
+// GetDailyStats calculates nutrition statistics for a specific day.
+// The day is the calendar day of date in date's location (the user's timezone).
 func (s *NutritionService) GetDailyStats(userID int64, date time.Time) (*model.DailyNutritionStats, error) {
-	// Set date to start of day
-	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-	endOfDay := startOfDay.Add(24*time.Hour - time.Second)
+	// Day bounds in the user's timezone (DST days are not 24 hours long)
+	startOfDay := utils.StartOfDay(date, date.Location())
+	endOfDay := utils.EndOfDay(date, date.Location())
 
 	// Get all meals for the day
 	filter := &model.MealFilter{
@@ -93,11 +110,25 @@ func (s *NutritionService) GetDailyStats(userID int64, date time.Time) (*model.D
 		stats.Nutrition.Calories += meal.Nutrition.Calories
 	}
 
+	burned, err := s.activityRepo.GetDailyCaloriesBurned(userID, startOfDay, startOfDay)
+	if err != nil {
+		return nil, err
+	}
+	stats.CaloriesBurned = burned[startOfDay.Format("2006-01-02")]
+	stats.NetCalories = stats.Nutrition.Calories - stats.CaloriesBurned
+
+	water, err := s.hydrationService.GetWaterByDay(userID, startOfDay, startOfDay)
+	if err != nil {
+		return nil, err
+	}
+	stats.WaterML = water[startOfDay.Format("2006-01-02")]
+
 	return stats, nil
 }
 
-// GetMonthlyTrend calculates daily nutrition statistics for an entire month
-func (s *NutritionService) GetMonthlyTrend(userID int64, year, month int) ([]*model.DailyNutritionStats, error) {
+// GetMonthlyTrend calculates daily nutrition statistics for an entire month,
+// with each day starting at midnight in loc (the user's timezone)
+func (s *NutritionService) GetMonthlyTrend(userID int64, year, month int, loc *time.Location) ([]*model.DailyNutritionStats, error) {
 	// Get all meals for the month
 	meals, err := s.mealRepo.GetMonthlyMeals(userID, year, month)
 	if err != nil {
@@ -112,9 +143,19 @@ func (s *NutritionService) GetMonthlyTrend(userID int64, year, month int) ([]*mo
 	}
 
 	// Calculate stats for each day
-	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, loc)
 	endDate := startDate.AddDate(0, 1, 0)
 
+	burned, err := s.activityRepo.GetDailyCaloriesBurned(userID, startDate, endDate.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	water, err := s.hydrationService.GetWaterByDay(userID, startDate, endDate.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+
 	var dailyStats []*model.DailyNutritionStats
 
 	for date := startDate; date.Before(endDate); date = date.AddDate(0, 0, 1) {
@@ -140,6 +181,9 @@ func (s *NutritionService) GetMonthlyTrend(userID int64, year, month int) ([]*mo
 			stats.Nutrition.Fiber += meal.Nutrition.Fiber
 			stats.Nutrition.Calories += meal.Nutrition.Calories
 		}
+		stats.CaloriesBurned = burned[dateKey]
+		stats.NetCalories = stats.Nutrition.Calories - stats.CaloriesBurned
+		stats.WaterML = water[dateKey]
 
 		dailyStats = append(dailyStats, stats)
 	}
@@ -147,6 +191,99 @@ func (s *NutritionService) GetMonthlyTrend(userID int64, year, month int) ([]*mo
 	return dailyStats, nil
 }
 
+// GetCategoryIntake breaks down the nutrition eaten between startDate and endDate
+// (calendar dates, inclusive) by food category. At the top level subcategories are
+// rolled up into their top-level category. Foods are attributed to their current
+// category; foods that have since been deleted are reported as "other".
+func (s *NutritionService) GetCategoryIntake(userID int64, startDate, endDate time.Time, level string) (*model.CategoryIntakeReport, error) {
+	meals, err := s.mealRepo.GetMealsByDateRange(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.categoryService.ListCategories(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	foodIDs := make([]int64, 0)
+	for _, meal := range meals {
+		for _, mf := range meal.Foods {
+			foodIDs = append(foodIDs, mf.FoodID)
+		}
+	}
+
+	foods, err := s.foodRepo.GetFoodsByIDs(userID, foodIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.CategoryIntakeReport{
+		StartDate:  utils.FormatDate(startDate),
+		EndDate:    utils.FormatDate(endDate),
+		Level:      level,
+		Categories: make([]*model.CategoryIntake, 0),
+	}
+
+	byCategory := make(map[string]*model.CategoryIntake)
+	foodsByCategory := make(map[string]map[int64]bool)
+	for _, meal := range meals {
+		for _, mf := range meal.Foods {
+			food, ok := foods[mf.FoodID]
+			if !ok {
+				food = &model.Food{ID: mf.FoodID, Category: model.DefaultFoodCategory}
+			}
+
+			category := categories.FindBySlug(food.Category)
+			if category == nil {
+				category = categories.FindBySlug(model.DefaultFoodCategory)
+			}
+			slug, name := food.Category, food.Category
+			if category != nil {
+				if level == model.CategoryLevelTop {
+					category = categories.Root(category)
+				}
+				slug, name = category.Slug, category.Name
+			}
+
+			intake, ok := byCategory[slug]
+			if !ok {
+				intake = &model.CategoryIntake{Category: slug, Name: name}
+				byCategory[slug] = intake
+				foodsByCategory[slug] = make(map[int64]bool)
+				report.Categories = append(report.Categories, intake)
+			}
+
+			// Food nutrition is per 100g and amounts are in grams, as in CalculateNutrition
+			ratio := mf.Amount / 100.0
+			nutrition := model.NutritionData{
+				Protein:  food.Protein * ratio,
+				Carbs:    food.Carbs * ratio,
+				Fat:      food.Fat * ratio,
+				Fiber:    food.Fiber * ratio,
+				Calories: food.Calories * ratio,
+			}
+			addNutrition(&intake.Nutrition, &nutrition)
+			addNutrition(&report.Total, &nutrition)
+			intake.Amount += mf.Amount
+			foodsByCategory[slug][mf.FoodID] = true
+		}
+	}
+
+	for _, intake := range report.Categories {
+		intake.FoodCount = len(foodsByCategory[intake.Category])
+		if report.Total.Calories > 0 {
+			intake.CaloriesPct = math.Round(intake.Nutrition.Calories/report.Total.Calories*1000) / 10
+		}
+	}
+
+	sort.SliceStable(report.Categories, func(i, j int) bool {
+		return report.Categories[i].Nutrition.Calories > report.Categories[j].Nutrition.Calories
+	})
+
+	return report, nil
+}
+
 // CompareWithTarget compares actual nutrition with target values
 func (s *NutritionService) CompareWithTarget(actual *model.NutritionData, target *model.NutritionData) (*model.NutritionComparison, error) {
 	if target == nil {
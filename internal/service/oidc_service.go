@@ -0,0 +1,455 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/oidc"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// oidcUsernameMinLength 自动创建账户时用户名的最小长度，与注册接口的校验规则一致
+	oidcUsernameMinLength = 3
+	// oidcUsernameBaseMaxLength 自动创建账户时用户名主体的最大长度，为冲突时追加的数字后缀留出空间
+	oidcUsernameBaseMaxLength = 44
+	// oidcUsernameAttempts 用户名冲突时尝试追加随机后缀的次数
+	oidcUsernameAttempts = 10
+	// oidcEmailMaxLength 用户邮箱的最大长度
+	oidcEmailMaxLength = 100
+)
+
+var (
+	// ErrOIDCDisabled 未启用 OpenID Connect 单点登录
+	ErrOIDCDisabled = errors.New("oidc login is not enabled")
+	// ErrInvalidOIDCState 授权流程不存在、已使用、已过期或不属于当前用户
+	ErrInvalidOIDCState = errors.New("invalid or expired oidc login state")
+	// ErrOIDCAuthenticationFailed 身份提供方拒绝了授权码，或 ID Token 校验失败
+	ErrOIDCAuthenticationFailed = errors.New("oidc authentication failed")
+	// ErrOIDCProviderUnavailable 无法访问身份提供方
+	ErrOIDCProviderUnavailable = oidc.ErrProviderUnavailable
+	// ErrOIDCAccountNotLinked 外部身份没有关联账户，且注册已关闭，无法自动创建账户
+	ErrOIDCAccountNotLinked = errors.New("no account is linked to this identity and registration is disabled")
+	// ErrIdentityAlreadyLinked 外部身份已关联到其他账户，或当前账户已关联该身份提供方的其他身份
+	ErrIdentityAlreadyLinked = errors.New("identity is already linked to an account")
+	// ErrIdentityNotFound 外部身份不存在
+	ErrIdentityNotFound = errors.New("identity not found")
+)
+
+// OIDCService OpenID Connect 单点登录服务接口
+type OIDCService interface {
+	GetProviderInfo() *model.OIDCProviderInfo
+	StartLogin(ctx context.Context) (*model.OIDCAuthorization, error)
+	CompleteLogin(ctx context.Context, code, state, ipAddress, userAgent string) (*LoginResult, error)
+	StartLink(ctx context.Context, userID int64) (*model.OIDCAuthorization, error)
+	CompleteLink(ctx context.Context, userID int64, code, state string) (*model.UserIdentity, error)
+	ListIdentities(ctx context.Context, userID int64) ([]*model.UserIdentity, error)
+	UnlinkIdentity(ctx context.Context, userID, identityID int64) error
+}
+
+// oidcService OpenID Connect 单点登录服务实现
+// 使用授权码流程和 PKCE，state、nonce 和 code_verifier 保存在服务端，每个授权流程只能完成一次；
+// 外部身份按 (issuer, sub) 关联本地用户，未关联的身份在开放注册时自动创建账户
+type oidcService struct {
+	provider        *oidc.Provider
+	providerName    string
+	stateTTL        time.Duration
+	userRepo        repository.UserRepository
+	identityRepo    repository.UserIdentityRepository
+	stateRepo       repository.OIDCStateRepository
+	settingsService SettingsService
+	authService     AuthService
+	auditService    AuditService
+	logger          *zap.Logger
+}
+
+// NewOIDCService 创建 OpenID Connect 单点登录服务实例，provider 为 nil 表示未启用
+func NewOIDCService(
+	provider *oidc.Provider,
+	providerName string,
+	stateTTL time.Duration,
+	userRepo repository.UserRepository,
+	identityRepo repository.UserIdentityRepository,
+	stateRepo repository.OIDCStateRepository,
+	settingsService SettingsService,
+	authService AuthService,
+	auditService AuditService,
+	logger *zap.Logger,
+) OIDCService {
+	return &oidcService{
+		provider:        provider,
+		providerName:    providerName,
+		stateTTL:        stateTTL,
+		userRepo:        userRepo,
+		identityRepo:    identityRepo,
+		stateRepo:       stateRepo,
+		settingsService: settingsService,
+		authService:     authService,
+		auditService:    auditService,
+		logger:          logger,
+	}
+}
+
+// GetProviderInfo 获取登录页展示的单点登录信息
+func (s *oidcService) GetProviderInfo() *model.OIDCProviderInfo {
+	if s.provider == nil {
+		return &model.OIDCProviderInfo{Enabled: false}
+	}
+	return &model.OIDCProviderInfo{Enabled: true, ProviderName: s.providerName}
+}
+
+// StartLogin 发起单点登录
+func (s *oidcService) StartLogin(ctx context.Context) (*model.OIDCAuthorization, error) {
+	return s.startAuthorization(ctx, model.OIDCStatePurposeLogin, nil)
+}
+
+// CompleteLogin 完成单点登录：校验授权流程、换取并校验 ID Token，然后按关联的账户登录
+func (s *oidcService) CompleteLogin(ctx context.Context, code, state, ipAddress, userAgent string) (*LoginResult, error) {
+	loginState, err := s.consumeState(ctx, state, model.OIDCStatePurposeLogin)
+	if err != nil {
+		return nil, err
+	}
+
+	idToken, err := s.authenticate(ctx, code, loginState)
+	if err != nil {
+		return nil, err
+	}
+
+	var user *model.User
+	identity, err := s.identityRepo.GetIdentity(ctx, idToken.Issuer, idToken.Subject)
+	switch {
+	case err == nil:
+		user, err = s.userRepo.GetUserByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+	case errors.Is(err, repository.ErrUserIdentityNotFound):
+		user, identity, err = s.provisionUser(ctx, idToken)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	result, err := s.authService.CompleteExternalLogin(ctx, user, ipAddress, userAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.identityRepo.UpdateLastLogin(ctx, identity.ID, truncateEmail(idToken.Email)); err != nil {
+		s.logger.Warn("Failed to update identity last login", zap.Int64("identity_id", identity.ID), zap.Error(err))
+	}
+
+	return result, nil
+}
+
+// StartLink 为已登录用户发起关联外部身份的授权流程
+func (s *oidcService) StartLink(ctx context.Context, userID int64) (*model.OIDCAuthorization, error) {
+	return s.startAuthorization(ctx, model.OIDCStatePurposeLink, &userID)
+}
+
+// CompleteLink 完成外部身份关联，授权流程必须由同一用户发起
+func (s *oidcService) CompleteLink(ctx context.Context, userID int64, code, state string) (*model.UserIdentity, error) {
+	linkState, err := s.consumeState(ctx, state, model.OIDCStatePurposeLink)
+	if err != nil {
+		return nil, err
+	}
+	if linkState.UserID == nil || *linkState.UserID != userID {
+		return nil, ErrInvalidOIDCState
+	}
+
+	idToken, err := s.authenticate(ctx, code, linkState)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.identityRepo.GetIdentity(ctx, idToken.Issuer, idToken.Subject)
+	if err == nil {
+		if existing.UserID == userID {
+			return existing, nil
+		}
+		return nil, ErrIdentityAlreadyLinked
+	}
+	if !errors.Is(err, repository.ErrUserIdentityNotFound) {
+		return nil, err
+	}
+
+	identity := &model.UserIdentity{
+		UserID:  userID,
+		Issuer:  idToken.Issuer,
+		Subject: idToken.Subject,
+		Email:   truncateEmail(idToken.Email),
+	}
+	if err := s.identityRepo.CreateIdentity(ctx, identity); err != nil {
+		if errors.Is(err, repository.ErrUserIdentityExists) {
+			return nil, ErrIdentityAlreadyLinked
+		}
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, &model.AuditLog{
+		Action:       model.AuditActionIdentityLink,
+		ResourceType: model.AuditResourceIdentity,
+		ResourceID:   strconv.FormatInt(identity.ID, 10),
+		Details:      map[string]any{"issuer": identity.Issuer, "subject": identity.Subject},
+	})
+
+	return identity, nil
+}
+
+// ListIdentities 获取用户关联的外部身份
+func (s *oidcService) ListIdentities(ctx context.Context, userID int64) ([]*model.UserIdentity, error) {
+	return s.identityRepo.ListIdentities(ctx, userID)
+}
+
+// UnlinkIdentity 取消关联外部身份
+func (s *oidcService) UnlinkIdentity(ctx context.Context, userID, identityID int64) error {
+	if err := s.identityRepo.DeleteIdentity(ctx, userID, identityID); err != nil {
+		if errors.Is(err, repository.ErrUserIdentityNotFound) {
+			return ErrIdentityNotFound
+		}
+		return err
+	}
+
+	s.auditService.Record(ctx, &model.AuditLog{
+		Action:       model.AuditActionIdentityUnlink,
+		ResourceType: model.AuditResourceIdentity,
+		ResourceID:   strconv.FormatInt(identityID, 10),
+	})
+
+	return nil
+}
+
+// startAuthorization 生成 state、nonce 和 PKCE code_verifier，保存授权流程并返回授权地址
+func (s *oidcService) startAuthorization(ctx context.Context, purpose string, userID *int64) (*model.OIDCAuthorization, error) {
+	if s.provider == nil {
+		return nil, ErrOIDCDisabled
+	}
+
+	state, err := oidc.GenerateRandomToken()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := oidc.GenerateRandomToken()
+	if err != nil {
+		return nil, err
+	}
+	codeVerifier, err := oidc.GenerateRandomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := s.provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallengeS256(codeVerifier))
+	if err != nil {
+		return nil, err
+	}
+
+	loginState := &model.OIDCLoginState{
+		StateHash:    oidc.HashState(state),
+		Purpose:      purpose,
+		UserID:       userID,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    time.Now().Add(s.stateTTL),
+	}
+	if err := s.stateRepo.CreateState(ctx, loginState); err != nil {
+		return nil, err
+	}
+
+	return &model.OIDCAuthorization{
+		AuthorizationURL: authURL,
+		State:            state,
+		ExpiresIn:        int64(s.stateTTL.Seconds()),
+	}, nil
+}
+
+// consumeState 取出授权流程并校验用途和有效期，每个 state 只能使用一次
+func (s *oidcService) consumeState(ctx context.Context, state, purpose string) (*model.OIDCLoginState, error) {
+	if s.provider == nil {
+		return nil, ErrOIDCDisabled
+	}
+
+	loginState, err := s.stateRepo.ConsumeState(ctx, oidc.HashState(state))
+	if err != nil {
+		if errors.Is(err, repository.ErrOIDCStateNotFound) {
+			return nil, ErrInvalidOIDCState
+		}
+		return nil, err
+	}
+
+	if loginState.Purpose != purpose || !time.Now().Before(loginState.ExpiresAt) {
+		return nil, ErrInvalidOIDCState
+	}
+
+	return loginState, nil
+}
+
+// authenticate 使用授权码换取 ID Token 并校验
+func (s *oidcService) authenticate(ctx context.Context, code string, loginState *model.OIDCLoginState) (*oidc.IDToken, error) {
+	rawIDToken, err := s.provider.Exchange(ctx, code, loginState.CodeVerifier)
+	if err != nil {
+		return nil, s.wrapProviderError(err)
+	}
+
+	idToken, err := s.provider.VerifyIDToken(ctx, rawIDToken, loginState.Nonce)
+	if err != nil {
+		return nil, s.wrapProviderError(err)
+	}
+
+	return idToken, nil
+}
+
+// wrapProviderError 身份提供方不可用时保留原错误，其他错误视为认证失败
+func (s *oidcService) wrapProviderError(err error) error {
+	if errors.Is(err, oidc.ErrProviderUnavailable) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrOIDCAuthenticationFailed, err)
+}
+
+// provisionUser 为未关联的外部身份自动创建账户（仅在开放注册时）
+// 账户使用随机密码，用户之后可以通过找回密码设置密码；第一个用户自动成为管理员，与注册接口一致
+func (s *oidcService) provisionUser(ctx context.Context, idToken *oidc.IDToken) (*model.User, *model.UserIdentity, error) {
+	registrationEnabled, err := s.settingsService.IsRegistrationEnabled(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check registration status: %w", err)
+	}
+	if !registrationEnabled {
+		return nil, nil, ErrOIDCAccountNotLinked
+	}
+
+	username, err := s.generateUsername(ctx, idToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	userCount, err := s.userRepo.GetUserCount(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get user count: %w", err)
+	}
+	role := model.RoleUser
+	if userCount == 0 {
+		role = model.RoleAdmin
+	}
+
+	password, err := oidc.GenerateRandomToken()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	email := truncateEmail(idToken.Email)
+	user := &model.User{
+		Username: username,
+		Email:    email,
+		Role:     role,
+	}
+	if err := s.userRepo.CreateUser(ctx, user, password); err != nil {
+		return nil, nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	identity := &model.UserIdentity{
+		UserID:  user.ID,
+		Issuer:  idToken.Issuer,
+		Subject: idToken.Subject,
+		Email:   email,
+	}
+	if err := s.identityRepo.CreateIdentity(ctx, identity); err != nil {
+		// 关联失败（如同一身份的并发登录）时删除刚创建的账户，避免留下无法登录的账户
+		if deleteErr := s.userRepo.DeleteUser(ctx, user.ID); deleteErr != nil {
+			s.logger.Error("Failed to delete user after identity creation failed", zap.Int64("user_id", user.ID), zap.Error(deleteErr))
+		}
+		return nil, nil, err
+	}
+
+	// 身份提供方已验证的邮箱直接标记为已验证
+	if email != "" && idToken.EmailVerified {
+		if _, err := s.userRepo.MarkEmailVerified(ctx, user.ID, email); err != nil {
+			s.logger.Warn("Failed to mark provisioned user email verified", zap.Int64("user_id", user.ID), zap.Error(err))
+		}
+	}
+
+	// 单点登录的请求未认证，操作者为新创建的账户
+	s.auditService.Record(ctx, &model.AuditLog{
+		ActorUserID:   &user.ID,
+		ActorUsername: user.Username,
+		Action:        model.AuditActionRegister,
+		ResourceType:  model.AuditResourceUser,
+		ResourceID:    strconv.FormatInt(user.ID, 10),
+		Details: map[string]any{
+			"role":   user.Role,
+			"method": loginMethodExternal,
+			"issuer": idToken.Issuer,
+		},
+	})
+
+	s.logger.Info("User provisioned from oidc identity",
+		zap.Int64("user_id", user.ID),
+		zap.String("username", user.Username),
+		zap.String("issuer", idToken.Issuer),
+	)
+
+	return user, identity, nil
+}
+
+// generateUsername 根据 preferred_username、邮箱前缀或姓名生成只含字母和数字的用户名，冲突时追加随机数字
+func (s *oidcService) generateUsername(ctx context.Context, idToken *oidc.IDToken) (string, error) {
+	emailLocal, _, _ := strings.Cut(idToken.Email, "@")
+
+	base := "user"
+	for _, candidate := range []string{idToken.PreferredUsername, emailLocal, idToken.Name} {
+		if sanitized := sanitizeUsername(candidate); len(sanitized) >= oidcUsernameMinLength {
+			base = sanitized
+			break
+		}
+	}
+	if len(base) > oidcUsernameBaseMaxLength {
+		base = base[:oidcUsernameBaseMaxLength]
+	}
+
+	candidate := base
+	for i := 0; i < oidcUsernameAttempts; i++ {
+		exists, err := s.userRepo.CheckUsernameExists(ctx, candidate)
+		if err != nil {
+			return "", fmt.Errorf("failed to check username exists: %w", err)
+		}
+		if !exists {
+			return candidate, nil
+		}
+
+		suffix, err := rand.Int(rand.Reader, big.NewInt(1000000))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate username suffix: %w", err)
+		}
+		candidate = base + suffix.String()
+	}
+
+	return "", fmt.Errorf("failed to generate a unique username for %q", base)
+}
+
+// sanitizeUsername 只保留 ASCII 字母和数字
+func sanitizeUsername(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// truncateEmail 丢弃超过长度限制的邮箱
+func truncateEmail(email string) string {
+	if len(email) > oidcEmailMaxLength {
+		return ""
+	}
+	return email
+}
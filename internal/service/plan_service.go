@@ -2,35 +2,60 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"sort"
+	"time"
 
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
 	"github.com/go-playground/validator/v10"
 )
 
+// DefaultPlanReconcileTime is the local time of day after which the current day's
+// pending plans are reconciled when neither the user nor the config specifies one
+const DefaultPlanReconcileTime = "23:30"
+
+// maxUTCOffset is the largest UTC offset in use (Pacific/Kiritimati, UTC+14)
+const maxUTCOffset = 14 * time.Hour
+
 // PlanService handles plan business logic
 type PlanService struct {
-	planRepo         *repository.PlanRepository
-	mealRepo         *repository.MealRepository
-	aiService        *AIService
-	nutritionService *NutritionService
-	validate         *validator.Validate
+	planRepo             *repository.PlanRepository
+	mealRepo             *repository.MealRepository
+	userPrefsRepo        repository.UserPreferencesRepository
+	aiService            *AIService
+	nutritionService     *NutritionService
+	dietaryService       *DietaryService
+	defaultReconcileTime string
+	validate             *validator.Validate
 }
 
 // NewPlanService creates a new PlanService instance
 func NewPlanService(
 	planRepo *repository.PlanRepository,
 	mealRepo *repository.MealRepository,
+	userPrefsRepo repository.UserPreferencesRepository,
 	aiService *AIService,
 	nutritionService *NutritionService,
+	dietaryService *DietaryService,
+	defaultReconcileTime string,
 ) *PlanService {
+	if _, err := time.Parse("15:04", defaultReconcileTime); err != nil {
+		defaultReconcileTime = DefaultPlanReconcileTime
+	}
+
 	return &PlanService{
-		planRepo:         planRepo,
-		mealRepo:         mealRepo,
-		aiService:        aiService,
-		nutritionService: nutritionService,
-		validate:         validator.New(),
+		planRepo:             planRepo,
+		mealRepo:             mealRepo,
+		userPrefsRepo:        userPrefsRepo,
+		aiService:            aiService,
+		nutritionService:     nutritionService,
+		dietaryService:       dietaryService,
+		defaultReconcileTime: defaultReconcileTime,
+		validate:             validator.New(),
 	}
 }
 
@@ -41,6 +66,36 @@ func (s *PlanService) GeneratePlan(ctx context.Context, userID int64, request *m
 	return nil, fmt.Errorf("AI-based meal plan generation is no longer supported - use the new message proxy service instead")
 }
 
+// CreatePlan creates a pending plan record with nutrition calculation
+func (s *PlanService) CreatePlan(userID int64, plan *model.Plan) error {
+	// Validate input
+	if err := s.validate.Struct(plan); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Force user_id to the authenticated user
+	plan.UserID = userID
+	plan.Status = "pending"
+	plan.MealID = nil
+
+	// Calculate nutrition from foods
+	nutrition, err := s.nutritionService.CalculateNutrition(userID, plan.Foods)
+	if err != nil {
+		return fmt.Errorf("failed to calculate nutrition: %w", err)
+	}
+
+	plan.Nutrition = *nutrition
+
+	// Check foods against the user's dietary restrictions
+	warnings, err := s.dietaryService.CheckFoods(userID, plan.Foods)
+	if err != nil {
+		return err
+	}
+	plan.Warnings = warnings
+
+	return s.planRepo.CreatePlan(plan)
+}
+
 // GetPlan retrieves a plan record by ID
 func (s *PlanService) GetPlan(userID, planID int64) (*model.Plan, error) {
 	return s.planRepo.GetPlanByID(userID, planID)
@@ -87,6 +142,12 @@ func (s *PlanService) UpdatePlan(userID, planID int64, plan *model.Plan) error {
 
 	plan.Nutrition = *nutrition
 
+	warnings, err := s.dietaryService.CheckFoods(userID, plan.Foods)
+	if err != nil {
+		return err
+	}
+	plan.Warnings = warnings
+
 	return s.planRepo.UpdatePlan(userID, planID, plan)
 }
 
@@ -117,15 +178,443 @@ func (s *PlanService) CompletePlan(userID, planID int64) (*model.Meal, error) {
 		Notes:     fmt.Sprintf("Completed from plan #%d", plan.ID),
 	}
 
+	// Record the meal at the user's preferred time for this meal type
+	prefs, err := s.userPrefsRepo.GetPreferences(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user preferences: %w", err)
+	}
+	eatenAt, err := defaultEatenAt(prefs, plan.PlanDate, plan.MealType)
+	if err != nil {
+		return nil, err
+	}
+	meal.EatenAt = &eatenAt
+	meal.EatenAtEstimated = true
+
 	// Create the meal record
 	if err := s.mealRepo.CreateMeal(meal); err != nil {
 		return nil, fmt.Errorf("failed to create meal from plan: %w", err)
 	}
 
-	// Update plan status to completed
-	if err := s.planRepo.UpdatePlanStatus(userID, planID, "completed"); err != nil {
+	// Update plan status to completed and link the new meal
+	if err := s.planRepo.LinkMeal(userID, planID, meal.ID); err != nil {
 		return nil, fmt.Errorf("failed to update plan status: %w", err)
 	}
 
 	return meal, nil
 }
+
+// ReconcilePlans resolves a user's past pending plans. A plan counts as past once its date
+// is before today, or is today and the user's reconcile time has passed. Plans with a
+// matching meal (same date and meal type, not yet linked to another plan) are completed
+// and linked to that meal; the rest are marked as skipped.
+func (s *PlanService) ReconcilePlans(userID int64, now time.Time) (*model.PlanReconcileResult, error) {
+	cutoff, err := s.reconcileCutoff(userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	plans, err := s.planRepo.GetPendingPlansUntil(userID, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending plans: %w", err)
+	}
+
+	result := &model.PlanReconcileResult{}
+	for _, plan := range plans {
+		result.Checked++
+
+		meal, err := s.mealRepo.FindUnlinkedMeal(userID, plan.PlanDate, plan.MealType)
+		if err != nil {
+			return result, fmt.Errorf("failed to find meal for plan %d: %w", plan.ID, err)
+		}
+
+		if meal != nil {
+			if err := s.planRepo.LinkMeal(userID, plan.ID, meal.ID); err != nil {
+				return result, fmt.Errorf("failed to complete plan %d: %w", plan.ID, err)
+			}
+			result.Completed++
+			continue
+		}
+
+		if err := s.planRepo.UpdatePlanStatus(userID, plan.ID, "skipped"); err != nil {
+			return result, fmt.Errorf("failed to skip plan %d: %w", plan.ID, err)
+		}
+		result.Skipped++
+	}
+
+	return result, nil
+}
+
+// ReconcileAllPlans runs ReconcilePlans for every user that has past pending plans.
+// Failures for individual users do not stop the run; they are returned joined together.
+func (s *PlanService) ReconcileAllPlans(ctx context.Context, now time.Time) (*model.PlanReconcileResult, error) {
+	userIDs, err := s.planRepo.GetUserIDsWithPendingPlans(reconcileCandidateDate(now))
+	if err != nil {
+		return nil, err
+	}
+
+	total := &model.PlanReconcileResult{}
+	var errs []error
+	for _, userID := range userIDs {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		result, err := s.ReconcilePlans(userID, now)
+		if result != nil {
+			total.Checked += result.Checked
+			total.Completed += result.Completed
+			total.Skipped += result.Skipped
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user %d: %w", userID, err))
+		}
+	}
+
+	return total, errors.Join(errs...)
+}
+
+// reconcileCandidateDate returns the latest local date anywhere (UTC+14) at now. Users with
+// pending plans up to that date are candidates for reconciliation, so users ahead of the
+// server's timezone are not missed; each user's own cutoff is applied in ReconcilePlans.
+func reconcileCandidateDate(now time.Time) time.Time {
+	return utils.StartOfDay(now.UTC().Add(maxUTCOffset), time.UTC)
+}
+
+// reconcileCutoff returns the latest plan date that is due for reconciliation,
+// evaluated in the user's timezone
+func (s *PlanService) reconcileCutoff(userID int64, now time.Time) (time.Time, error) {
+	reconcileTime := s.defaultReconcileTime
+
+	prefs, err := s.userPrefsRepo.GetPreferences(userID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get user preferences: %w", err)
+	}
+	if prefs != nil {
+		if prefs.PlanReconcileTime != "" {
+			reconcileTime = prefs.PlanReconcileTime
+		}
+		if loc, err := utils.LoadLocation(prefs.Timezone); err == nil {
+			now = now.In(loc)
+		}
+	}
+
+	return reconcileCutoffAt(now, reconcileTime), nil
+}
+
+// reconcileCutoffAt returns the latest plan date that is due for reconciliation at now,
+// given the HH:MM time of day after which the current day's plans are due. An invalid
+// time falls back to DefaultPlanReconcileTime.
+func reconcileCutoffAt(now time.Time, reconcileTime string) time.Time {
+	clock, err := time.Parse("15:04", reconcileTime)
+	if err != nil {
+		clock, _ = time.Parse("15:04", DefaultPlanReconcileTime)
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	reconcileAt := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+
+	if now.Before(reconcileAt) {
+		return today.AddDate(0, 0, -1)
+	}
+	return today
+}
+
+// GetAdherenceStats calculates planned vs. actually eaten meals per week (Monday to Sunday)
+// for plans dated within the given range
+func (s *PlanService) GetAdherenceStats(userID int64, startDate, endDate time.Time) (*model.PlanAdherenceStats, error) {
+	startDate = time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, startDate.Location())
+	endDate = time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, endDate.Location())
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("end date must be after or equal to start date")
+	}
+
+	plans, err := s.planRepo.GetPlansByDateRange(userID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plans: %w", err)
+	}
+
+	return buildAdherenceStats(plans, startDate, endDate), nil
+}
+
+// buildAdherenceStats counts plans per week (Monday to Sunday) and overall. The range
+// must start and end at midnight; plans outside the weeks covering it are ignored.
+func buildAdherenceStats(plans []*model.Plan, startDate, endDate time.Time) *model.PlanAdherenceStats {
+	// Build weekly buckets covering the whole range
+	var weeks []model.WeeklyAdherence
+	weekIndex := make(map[string]int)
+	for weekStart := startOfWeek(startDate); !weekStart.After(endDate); weekStart = weekStart.AddDate(0, 0, 7) {
+		key := weekStart.Format("2006-01-02")
+		weekIndex[key] = len(weeks)
+		weeks = append(weeks, model.WeeklyAdherence{
+			WeekStart: key,
+			WeekEnd:   weekStart.AddDate(0, 0, 6).Format("2006-01-02"),
+		})
+	}
+
+	stats := &model.PlanAdherenceStats{
+		StartDate: startDate.Format("2006-01-02"),
+		EndDate:   endDate.Format("2006-01-02"),
+	}
+
+	for _, plan := range plans {
+		idx, ok := weekIndex[startOfWeek(plan.PlanDate).Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		countPlanStatus(&weeks[idx].AdherenceCounts, plan.Status)
+		countPlanStatus(&stats.Overall, plan.Status)
+	}
+
+	for i := range weeks {
+		weeks[i].AdherenceRate = adherenceRate(&weeks[i].AdherenceCounts)
+	}
+	stats.Overall.AdherenceRate = adherenceRate(&stats.Overall)
+	stats.Weeks = weeks
+
+	return stats
+}
+
+// GetPlanReport builds a plan-vs-actual report for plans dated within the given range.
+// Each plan is matched with its linked meal, or otherwise with an unclaimed meal of the same
+// date and meal type. Daily comparisons use every meal eaten that day, planned or not.
+func (s *PlanService) GetPlanReport(userID int64, startDate, endDate time.Time) (*model.PlanReport, error) {
+	startDate = time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, startDate.Location())
+	endDate = time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, endDate.Location())
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("end date must be after or equal to start date")
+	}
+
+	plans, err := s.planRepo.GetPlansByDateRange(userID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plans: %w", err)
+	}
+
+	meals, err := s.mealRepo.GetMealsByDateRange(userID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meals: %w", err)
+	}
+
+	return s.buildPlanReport(plans, meals, startDate, endDate)
+}
+
+// buildPlanReport compares plans with the meals eaten in the same date range
+func (s *PlanService) buildPlanReport(plans []*model.Plan, meals []*model.Meal, startDate, endDate time.Time) (*model.PlanReport, error) {
+	// Index meals by ID and by date/meal type slot
+	mealsByID := make(map[int64]*model.Meal, len(meals))
+	mealsBySlot := make(map[string][]*model.Meal)
+	mealsByDate := make(map[string][]*model.Meal)
+	for _, meal := range meals {
+		date := meal.MealDate.Format("2006-01-02")
+		mealsByID[meal.ID] = meal
+		mealsBySlot[date+"|"+meal.MealType] = append(mealsBySlot[date+"|"+meal.MealType], meal)
+		mealsByDate[date] = append(mealsByDate[date], meal)
+	}
+
+	// Meals explicitly linked to a plan cannot be claimed by another plan
+	claimed := make(map[int64]bool)
+	for _, plan := range plans {
+		if plan.MealID != nil {
+			claimed[*plan.MealID] = true
+		}
+	}
+
+	report := &model.PlanReport{
+		StartDate:        startDate.Format("2006-01-02"),
+		EndDate:          endDate.Format("2006-01-02"),
+		Meals:            make([]model.PlanMealComparison, 0, len(plans)),
+		Days:             make([]model.PlanDayComparison, 0),
+		SkippedMealTypes: make([]model.MealTypeCount, 0),
+		SubstitutedFoods: make([]model.FoodSubstitution, 0),
+	}
+
+	plannedByDate := make(map[string]*model.NutritionData)
+	plannedCount := make(map[string]int)
+	var dates []string
+	skippedByType := make(map[string]int)
+	substitutions := make(map[[2]int64]*model.FoodSubstitution)
+
+	for _, plan := range plans {
+		date := plan.PlanDate.Format("2006-01-02")
+		countPlanStatus(&report.Adherence, plan.Status)
+		if plan.Status == "skipped" {
+			skippedByType[plan.MealType]++
+		}
+
+		// Accumulate planned nutrition per day
+		if _, ok := plannedByDate[date]; !ok {
+			plannedByDate[date] = &model.NutritionData{}
+			dates = append(dates, date)
+		}
+		addNutrition(plannedByDate[date], &plan.Nutrition)
+		plannedCount[date]++
+
+		// Match the plan with the meal that fulfilled it
+		var meal *model.Meal
+		if plan.MealID != nil {
+			meal = mealsByID[*plan.MealID]
+		} else {
+			for _, candidate := range mealsBySlot[date+"|"+plan.MealType] {
+				if !claimed[candidate.ID] {
+					meal = candidate
+					claimed[candidate.ID] = true
+					break
+				}
+			}
+		}
+
+		item := model.PlanMealComparison{
+			PlanID:   plan.ID,
+			Date:     date,
+			MealType: plan.MealType,
+			Status:   plan.Status,
+		}
+
+		if meal != nil {
+			comparison, err := s.nutritionService.CompareWithTarget(&meal.Nutrition, &plan.Nutrition)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compare plan %d: %w", plan.ID, err)
+			}
+			mealID := meal.ID
+			item.MealID = &mealID
+			item.Comparison = comparison
+
+			collectSubstitutions(substitutions, plan.Foods, meal.Foods)
+		}
+
+		report.Meals = append(report.Meals, item)
+	}
+
+	// Per-day comparison of planned nutrition vs. everything eaten that day
+	for _, date := range dates {
+		actual := &model.NutritionData{}
+		for _, meal := range mealsByDate[date] {
+			addNutrition(actual, &meal.Nutrition)
+		}
+
+		comparison, err := s.nutritionService.CompareWithTarget(actual, plannedByDate[date])
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare day %s: %w", date, err)
+		}
+
+		report.Days = append(report.Days, model.PlanDayComparison{
+			Date:         date,
+			PlannedMeals: plannedCount[date],
+			EatenMeals:   len(mealsByDate[date]),
+			Comparison:   comparison,
+		})
+	}
+	sort.Slice(report.Days, func(i, j int) bool {
+		return report.Days[i].Date < report.Days[j].Date
+	})
+
+	report.Adherence.AdherenceRate = adherenceRate(&report.Adherence)
+
+	// Most frequently skipped meal types first
+	for mealType, count := range skippedByType {
+		report.SkippedMealTypes = append(report.SkippedMealTypes, model.MealTypeCount{
+			MealType: mealType,
+			Count:    count,
+		})
+	}
+	sort.Slice(report.SkippedMealTypes, func(i, j int) bool {
+		if report.SkippedMealTypes[i].Count != report.SkippedMealTypes[j].Count {
+			return report.SkippedMealTypes[i].Count > report.SkippedMealTypes[j].Count
+		}
+		return report.SkippedMealTypes[i].MealType < report.SkippedMealTypes[j].MealType
+	})
+
+	// Most frequent substitutions first
+	for _, substitution := range substitutions {
+		report.SubstitutedFoods = append(report.SubstitutedFoods, *substitution)
+	}
+	sort.Slice(report.SubstitutedFoods, func(i, j int) bool {
+		a, b := report.SubstitutedFoods[i], report.SubstitutedFoods[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.PlannedFoodID != b.PlannedFoodID {
+			return a.PlannedFoodID < b.PlannedFoodID
+		}
+		return a.ActualFoodID < b.ActualFoodID
+	})
+
+	return report, nil
+}
+
+// collectSubstitutions pairs planned foods missing from the meal with foods eaten instead,
+// in the order they appear, and accumulates the pairs
+func collectSubstitutions(substitutions map[[2]int64]*model.FoodSubstitution, planned, actual []model.MealFood) {
+	plannedIDs := make(map[int64]bool, len(planned))
+	for _, food := range planned {
+		plannedIDs[food.FoodID] = true
+	}
+	actualIDs := make(map[int64]bool, len(actual))
+	for _, food := range actual {
+		actualIDs[food.FoodID] = true
+	}
+
+	var missing, added []model.MealFood
+	for _, food := range planned {
+		if !actualIDs[food.FoodID] {
+			missing = append(missing, food)
+		}
+	}
+	for _, food := range actual {
+		if !plannedIDs[food.FoodID] {
+			added = append(added, food)
+		}
+	}
+
+	for i := 0; i < len(missing) && i < len(added); i++ {
+		key := [2]int64{missing[i].FoodID, added[i].FoodID}
+		if substitution, ok := substitutions[key]; ok {
+			substitution.Count++
+			continue
+		}
+		substitutions[key] = &model.FoodSubstitution{
+			PlannedFoodID: missing[i].FoodID,
+			PlannedFood:   missing[i].Name,
+			ActualFoodID:  added[i].FoodID,
+			ActualFood:    added[i].Name,
+			Count:         1,
+		}
+	}
+}
+
+// addNutrition adds the nutrition values of src to dst
+func addNutrition(dst, src *model.NutritionData) {
+	dst.Protein += src.Protein
+	dst.Carbs += src.Carbs
+	dst.Fat += src.Fat
+	dst.Fiber += src.Fiber
+	dst.Calories += src.Calories
+}
+
+// startOfWeek returns the Monday of the week containing the given date
+func startOfWeek(date time.Time) time.Time {
+	offset := (int(date.Weekday()) + 6) % 7
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	return day.AddDate(0, 0, -offset)
+}
+
+// countPlanStatus adds a plan with the given status to the counts
+func countPlanStatus(counts *model.AdherenceCounts, status string) {
+	counts.Planned++
+	switch status {
+	case "completed":
+		counts.Completed++
+	case "skipped":
+		counts.Skipped++
+	default:
+		counts.Pending++
+	}
+}
+
+// adherenceRate returns the percentage (two decimals) of resolved plans that were actually eaten
+func adherenceRate(counts *model.AdherenceCounts) float64 {
+	resolved := counts.Completed + counts.Skipped
+	if resolved == 0 {
+		return 0
+	}
+	return math.Round(float64(counts.Completed)/float64(resolved)*10000) / 100
+}
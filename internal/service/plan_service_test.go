@@ -0,0 +1,418 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+)
+
+func TestReconcileCutoffAt(t *testing.T) {
+	tests := []struct {
+		name          string
+		now           time.Time
+		reconcileTime string
+		want          string
+	}{
+		{
+			name:          "Before reconcile time",
+			now:           time.Date(2024, 3, 10, 23, 29, 59, 0, time.UTC),
+			reconcileTime: "23:30",
+			want:          "2024-03-09",
+		},
+		{
+			name:          "Exactly at reconcile time",
+			now:           time.Date(2024, 3, 10, 23, 30, 0, 0, time.UTC),
+			reconcileTime: "23:30",
+			want:          "2024-03-10",
+		},
+		{
+			name:          "After reconcile time",
+			now:           time.Date(2024, 3, 10, 23, 45, 0, 0, time.UTC),
+			reconcileTime: "23:30",
+			want:          "2024-03-10",
+		},
+		{
+			name:          "Early reconcile time",
+			now:           time.Date(2024, 3, 10, 21, 0, 0, 0, time.UTC),
+			reconcileTime: "20:00",
+			want:          "2024-03-10",
+		},
+		{
+			name:          "Just after midnight",
+			now:           time.Date(2024, 3, 1, 0, 5, 0, 0, time.UTC),
+			reconcileTime: "23:30",
+			want:          "2024-02-29",
+		},
+		{
+			name:          "Invalid time falls back to default",
+			now:           time.Date(2024, 3, 10, 22, 0, 0, 0, time.UTC),
+			reconcileTime: "25:00",
+			want:          "2024-03-09",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reconcileCutoffAt(tt.now, tt.reconcileTime)
+			if got.Format("2006-01-02") != tt.want {
+				t.Errorf("reconcileCutoffAt() = %s, want %s", got.Format("2006-01-02"), tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileCutoffAtUserTimezone(t *testing.T) {
+	kiritimati := time.FixedZone("UTC+14", 14*60*60)
+	bakerIsland := time.FixedZone("UTC-12", -12*60*60)
+	// 2024-03-10 10:30 UTC is 2024-03-11 00:30 in UTC+14 and 2024-03-09 22:30 in UTC-12
+	now := time.Date(2024, 3, 10, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		loc           *time.Location
+		reconcileTime string
+		want          string
+	}{
+		{name: "UTC+14 after reconcile time", loc: kiritimati, reconcileTime: "00:15", want: "2024-03-11"},
+		{name: "UTC+14 before reconcile time", loc: kiritimati, reconcileTime: "23:30", want: "2024-03-10"},
+		{name: "UTC-12 after reconcile time", loc: bakerIsland, reconcileTime: "22:00", want: "2024-03-09"},
+		{name: "UTC-12 before reconcile time", loc: bakerIsland, reconcileTime: "23:30", want: "2024-03-08"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reconcileCutoffAt(now.In(tt.loc), tt.reconcileTime)
+			if got.Format("2006-01-02") != tt.want {
+				t.Errorf("reconcileCutoffAt() = %s, want %s", got.Format("2006-01-02"), tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileCandidateDate(t *testing.T) {
+	zones := []*time.Location{
+		time.FixedZone("UTC+14", 14*60*60),
+		time.FixedZone("UTC+8", 8*60*60),
+		time.UTC,
+		time.FixedZone("UTC-12", -12*60*60),
+	}
+
+	// Server clocks in several timezones around the day boundaries of the extremes
+	instants := []time.Time{
+		time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 10, 9, 59, 0, 0, time.UTC),
+		time.Date(2024, 3, 10, 10, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 10, 11, 59, 0, 0, time.UTC),
+		time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 10, 23, 59, 0, 0, time.UTC),
+	}
+
+	for _, instant := range instants {
+		for _, server := range zones {
+			now := instant.In(server)
+			candidate := reconcileCandidateDate(now).Format("2006-01-02")
+
+			// The candidate date must cover the latest cutoff of any user, which is the
+			// user's own today once a reconcile time of 00:00 has passed
+			for _, user := range zones {
+				cutoff := reconcileCutoffAt(now.In(user), "00:00").Format("2006-01-02")
+				if cutoff > candidate {
+					t.Errorf("at %s (server %s) user %s cutoff %s is after candidate date %s",
+						instant.Format(time.RFC3339), server, user, cutoff, candidate)
+				}
+			}
+		}
+	}
+
+	t.Run("Latest local date", func(t *testing.T) {
+		now := time.Date(2024, 3, 10, 10, 0, 0, 0, time.FixedZone("UTC-5", -5*60*60))
+		if got := reconcileCandidateDate(now).Format("2006-01-02"); got != "2024-03-11" {
+			t.Errorf("reconcileCandidateDate() = %s, want 2024-03-11", got)
+		}
+	})
+}
+
+func TestStartOfWeek(t *testing.T) {
+	tests := []struct {
+		name string
+		date time.Time
+		want string
+	}{
+		{
+			name: "Monday",
+			date: time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC),
+			want: "2024-03-11",
+		},
+		{
+			name: "Wednesday with time of day",
+			date: time.Date(2024, 3, 13, 18, 30, 0, 0, time.UTC),
+			want: "2024-03-11",
+		},
+		{
+			name: "Sunday belongs to the previous Monday",
+			date: time.Date(2024, 3, 17, 0, 0, 0, 0, time.UTC),
+			want: "2024-03-11",
+		},
+		{
+			name: "Week across the year boundary",
+			date: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			want: "2024-12-30",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := startOfWeek(tt.date)
+			if got.Format("2006-01-02") != tt.want {
+				t.Errorf("startOfWeek() = %s, want %s", got.Format("2006-01-02"), tt.want)
+			}
+			if got.Hour() != 0 || got.Minute() != 0 {
+				t.Errorf("startOfWeek() = %s, want midnight", got)
+			}
+		})
+	}
+}
+
+func TestAdherenceRate(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts model.AdherenceCounts
+		want   float64
+	}{
+		{
+			name:   "No plans",
+			counts: model.AdherenceCounts{},
+			want:   0,
+		},
+		{
+			name:   "Only pending plans",
+			counts: model.AdherenceCounts{Planned: 3, Pending: 3},
+			want:   0,
+		},
+		{
+			name:   "All completed",
+			counts: model.AdherenceCounts{Planned: 4, Completed: 4},
+			want:   100,
+		},
+		{
+			name:   "Pending plans are not counted",
+			counts: model.AdherenceCounts{Planned: 5, Completed: 2, Skipped: 1, Pending: 2},
+			want:   66.67,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := adherenceRate(&tt.counts); got != tt.want {
+				t.Errorf("adherenceRate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildAdherenceStats(t *testing.T) {
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+
+	t.Run("No plans", func(t *testing.T) {
+		stats := buildAdherenceStats(nil, date("2024-03-13"), date("2024-03-20"))
+
+		if len(stats.Weeks) != 2 {
+			t.Fatalf("len(Weeks) = %d, want 2", len(stats.Weeks))
+		}
+		if stats.Weeks[0].WeekStart != "2024-03-11" || stats.Weeks[1].WeekEnd != "2024-03-24" {
+			t.Errorf("weeks = %s..%s, want 2024-03-11..2024-03-24", stats.Weeks[0].WeekStart, stats.Weeks[1].WeekEnd)
+		}
+		if stats.Overall != (model.AdherenceCounts{}) {
+			t.Errorf("Overall = %+v, want zero counts", stats.Overall)
+		}
+	})
+
+	t.Run("Plans counted per week", func(t *testing.T) {
+		plans := []*model.Plan{
+			{PlanDate: date("2024-03-11"), Status: "completed"},
+			{PlanDate: date("2024-03-17"), Status: "skipped"},
+			{PlanDate: date("2024-03-18"), Status: "completed"},
+			{PlanDate: date("2024-03-19"), Status: "pending"},
+		}
+
+		stats := buildAdherenceStats(plans, date("2024-03-11"), date("2024-03-19"))
+
+		if len(stats.Weeks) != 2 {
+			t.Fatalf("len(Weeks) = %d, want 2", len(stats.Weeks))
+		}
+		first, second := stats.Weeks[0], stats.Weeks[1]
+		if first.Planned != 2 || first.Completed != 1 || first.Skipped != 1 || first.AdherenceRate != 50 {
+			t.Errorf("first week = %+v, want 2 planned, 1 completed, 1 skipped, rate 50", first.AdherenceCounts)
+		}
+		if second.Planned != 2 || second.Completed != 1 || second.Pending != 1 || second.AdherenceRate != 100 {
+			t.Errorf("second week = %+v, want 2 planned, 1 completed, 1 pending, rate 100", second.AdherenceCounts)
+		}
+		if stats.Overall.Planned != 4 || stats.Overall.AdherenceRate != 66.67 {
+			t.Errorf("Overall = %+v, want 4 planned, rate 66.67", stats.Overall)
+		}
+	})
+}
+
+func TestBuildPlanReport(t *testing.T) {
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	id := func(v int64) *int64 { return &v }
+
+	rice := model.MealFood{FoodID: 1, Name: "Rice", Amount: 150, Unit: "g"}
+	chicken := model.MealFood{FoodID: 2, Name: "Chicken", Amount: 120, Unit: "g"}
+	noodles := model.MealFood{FoodID: 3, Name: "Noodles", Amount: 200, Unit: "g"}
+	apple := model.MealFood{FoodID: 4, Name: "Apple", Amount: 1, Unit: "piece"}
+
+	plans := []*model.Plan{
+		// Matched exactly through the linked meal
+		{ID: 1, PlanDate: date("2024-03-11"), MealType: "lunch", Status: "completed", MealID: id(10),
+			Foods: []model.MealFood{rice, chicken}, Nutrition: model.NutritionData{Calories: 600, Protein: 40}},
+		// Skipped, no meal that day and slot
+		{ID: 2, PlanDate: date("2024-03-11"), MealType: "dinner", Status: "skipped",
+			Foods: []model.MealFood{rice}, Nutrition: model.NutritionData{Calories: 400}},
+		// Not linked yet, matched by date and meal type; rice was replaced by noodles
+		{ID: 3, PlanDate: date("2024-03-12"), MealType: "lunch", Status: "pending",
+			Foods: []model.MealFood{rice, chicken}, Nutrition: model.NutritionData{Calories: 600}},
+	}
+	meals := []*model.Meal{
+		{ID: 10, MealDate: date("2024-03-11"), MealType: "lunch",
+			Foods: []model.MealFood{rice, chicken}, Nutrition: model.NutritionData{Calories: 600, Protein: 40}},
+		// Unplanned snack, counted in the day but not matched with a plan
+		{ID: 11, MealDate: date("2024-03-11"), MealType: "snack",
+			Foods: []model.MealFood{apple}, Nutrition: model.NutritionData{Calories: 80}},
+		{ID: 12, MealDate: date("2024-03-12"), MealType: "lunch",
+			Foods: []model.MealFood{noodles, chicken}, Nutrition: model.NutritionData{Calories: 700}},
+	}
+
+	s := &PlanService{nutritionService: &NutritionService{}}
+	report, err := s.buildPlanReport(plans, meals, date("2024-03-11"), date("2024-03-12"))
+	if err != nil {
+		t.Fatalf("buildPlanReport() error = %v", err)
+	}
+
+	t.Run("Plan matched exactly", func(t *testing.T) {
+		item := report.Meals[0]
+		if item.MealID == nil || *item.MealID != 10 {
+			t.Fatalf("plan 1 meal_id = %v, want 10", item.MealID)
+		}
+		if item.Comparison == nil || item.Comparison.Difference.Calories != 0 {
+			t.Errorf("plan 1 comparison = %+v, want zero calorie difference", item.Comparison)
+		}
+	})
+
+	t.Run("Skipped plan", func(t *testing.T) {
+		item := report.Meals[1]
+		if item.MealID != nil || item.Comparison != nil {
+			t.Errorf("plan 2 = %+v, want no matched meal", item)
+		}
+		if len(report.SkippedMealTypes) != 1 || report.SkippedMealTypes[0] != (model.MealTypeCount{MealType: "dinner", Count: 1}) {
+			t.Errorf("SkippedMealTypes = %+v, want dinner once", report.SkippedMealTypes)
+		}
+	})
+
+	t.Run("Unplanned meal", func(t *testing.T) {
+		if len(report.Days) != 2 {
+			t.Fatalf("len(Days) = %d, want 2", len(report.Days))
+		}
+		day := report.Days[0]
+		if day.Date != "2024-03-11" || day.PlannedMeals != 2 || day.EatenMeals != 2 {
+			t.Errorf("first day = %+v, want 2 planned and 2 eaten meals", day)
+		}
+		if day.Comparison.Target.Calories != 1000 || day.Comparison.Actual.Calories != 680 {
+			t.Errorf("first day calories = %v of %v, want 680 of 1000", day.Comparison.Actual.Calories, day.Comparison.Target.Calories)
+		}
+		for _, item := range report.Meals {
+			if item.MealID != nil && *item.MealID == 11 {
+				t.Errorf("unplanned meal 11 was matched with plan %d", item.PlanID)
+			}
+		}
+	})
+
+	t.Run("Substituted food", func(t *testing.T) {
+		item := report.Meals[2]
+		if item.MealID == nil || *item.MealID != 12 {
+			t.Fatalf("plan 3 meal_id = %v, want 12", item.MealID)
+		}
+		want := model.FoodSubstitution{PlannedFoodID: 1, PlannedFood: "Rice", ActualFoodID: 3, ActualFood: "Noodles", Count: 1}
+		if len(report.SubstitutedFoods) != 1 || report.SubstitutedFoods[0] != want {
+			t.Errorf("SubstitutedFoods = %+v, want %+v", report.SubstitutedFoods, want)
+		}
+	})
+
+	t.Run("Adherence", func(t *testing.T) {
+		if report.Adherence.Planned != 3 || report.Adherence.Completed != 1 || report.Adherence.Skipped != 1 || report.Adherence.Pending != 1 {
+			t.Errorf("Adherence = %+v, want 3 planned, 1 completed, 1 skipped, 1 pending", report.Adherence)
+		}
+	})
+}
+
+func TestCollectSubstitutions(t *testing.T) {
+	food := func(id int64, name string) model.MealFood {
+		return model.MealFood{FoodID: id, Name: name, Amount: 100, Unit: "g"}
+	}
+
+	tests := []struct {
+		name    string
+		planned []model.MealFood
+		actual  []model.MealFood
+		want    []model.FoodSubstitution
+	}{
+		{
+			name:    "Same foods",
+			planned: []model.MealFood{food(1, "Rice"), food(2, "Chicken")},
+			actual:  []model.MealFood{food(2, "Chicken"), food(1, "Rice")},
+		},
+		{
+			name:    "Food left out",
+			planned: []model.MealFood{food(1, "Rice"), food(2, "Chicken")},
+			actual:  []model.MealFood{food(2, "Chicken")},
+		},
+		{
+			name:    "Extra food",
+			planned: []model.MealFood{food(1, "Rice")},
+			actual:  []model.MealFood{food(1, "Rice"), food(5, "Apple")},
+		},
+		{
+			name:    "Replaced foods paired in order",
+			planned: []model.MealFood{food(1, "Rice"), food(2, "Chicken")},
+			actual:  []model.MealFood{food(3, "Noodles"), food(4, "Beef")},
+			want: []model.FoodSubstitution{
+				{PlannedFoodID: 1, PlannedFood: "Rice", ActualFoodID: 3, ActualFood: "Noodles", Count: 1},
+				{PlannedFoodID: 2, PlannedFood: "Chicken", ActualFoodID: 4, ActualFood: "Beef", Count: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			substitutions := make(map[[2]int64]*model.FoodSubstitution)
+			collectSubstitutions(substitutions, tt.planned, tt.actual)
+
+			if len(substitutions) != len(tt.want) {
+				t.Fatalf("got %d substitutions, want %d", len(substitutions), len(tt.want))
+			}
+			for _, want := range tt.want {
+				got, ok := substitutions[[2]int64{want.PlannedFoodID, want.ActualFoodID}]
+				if !ok || *got != want {
+					t.Errorf("substitution %d -> %d = %+v, want %+v", want.PlannedFoodID, want.ActualFoodID, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("Repeated substitution is counted", func(t *testing.T) {
+		substitutions := make(map[[2]int64]*model.FoodSubstitution)
+		collectSubstitutions(substitutions, []model.MealFood{food(1, "Rice")}, []model.MealFood{food(3, "Noodles")})
+		collectSubstitutions(substitutions, []model.MealFood{food(1, "Rice")}, []model.MealFood{food(3, "Noodles")})
+
+		if got := substitutions[[2]int64{1, 3}]; got == nil || got.Count != 2 {
+			t.Errorf("substitution 1 -> 3 = %+v, want count 2", got)
+		}
+	})
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+)
+
+const (
+	// sessionBlacklistPrefix 会话在令牌黑名单中的键前缀
+	sessionBlacklistPrefix = "session:"
+	// maxUserAgentLength User-Agent 最大保存长度（与数据库列一致）
+	maxUserAgentLength = 255
+)
+
+var (
+	// ErrSessionNotFound 会话不存在或不属于当前用户
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrSessionRevoked 会话已被撤销或已过期
+	ErrSessionRevoked = utils.ErrSessionRevoked
+	// ErrRefreshTokenReused 已轮换的刷新令牌被再次使用，整个会话已被撤销
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected, session revoked")
+)
+
+// SessionService 登录会话服务接口
+type SessionService interface {
+	CreateSession(ctx context.Context, user *model.User, ipAddress, userAgent string) (*utils.TokenPair, error)
+	RotateSession(ctx context.Context, claims *utils.Claims, user *model.User, ipAddress, userAgent string) (*utils.TokenPair, error)
+	ListSessions(ctx context.Context, userID, currentSessionID int64) ([]*model.Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID int64, reason string) error
+	RevokeOtherSessions(ctx context.Context, userID, currentSessionID int64) (int, error)
+	RevokeAllSessions(ctx context.Context, userID int64, reason string) error
+	IsSessionRevoked(ctx context.Context, sessionID int64) (bool, error)
+}
+
+// sessionService 登录会话服务实现
+// 会话状态保存在数据库中，刷新令牌每次使用后轮换；
+// 撤销会话时将会话加入令牌黑名单，使该会话已签发的访问令牌立即失效
+type sessionService struct {
+	sessionRepo        repository.SessionRepository
+	tokenBlacklistRepo repository.TokenBlacklistRepository
+	auditService       AuditService
+	jwtService         *utils.JWTService
+}
+
+// NewSessionService 创建登录会话服务实例
+func NewSessionService(
+	sessionRepo repository.SessionRepository,
+	tokenBlacklistRepo repository.TokenBlacklistRepository,
+	auditService AuditService,
+	jwtService *utils.JWTService,
+) SessionService {
+	return &sessionService{
+		sessionRepo:        sessionRepo,
+		tokenBlacklistRepo: tokenBlacklistRepo,
+		auditService:       auditService,
+		jwtService:         jwtService,
+	}
+}
+
+// CreateSession 登录成功后创建会话并签发令牌对
+func (s *sessionService) CreateSession(ctx context.Context, user *model.User, ipAddress, userAgent string) (*utils.TokenPair, error) {
+	tokenID, err := utils.NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &model.Session{
+		UserID:         user.ID,
+		RefreshTokenID: tokenID,
+		UserAgent:      truncateUserAgent(userAgent),
+		IPAddress:      ipAddress,
+		ExpiresAt:      time.Now().Add(s.jwtService.RefreshTokenDuration()),
+	}
+	if err := s.sessionRepo.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	tokenPair, err := s.jwtService.GenerateTokenPair(user.ID, user.Username, user.PasswordVersion, session.ID, tokenID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return tokenPair, nil
+}
+
+// RotateSession 使用刷新令牌换取新的令牌对，旧的刷新令牌随即失效
+// 如果提交的刷新令牌已经被轮换过，说明令牌可能被盗用，撤销整个会话
+func (s *sessionService) RotateSession(ctx context.Context, claims *utils.Claims, user *model.User, ipAddress, userAgent string) (*utils.TokenPair, error) {
+	session, err := s.sessionRepo.GetSession(ctx, claims.SessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return nil, ErrSessionRevoked
+		}
+		return nil, err
+	}
+
+	if session.UserID != user.ID || !session.IsActive(time.Now()) {
+		return nil, ErrSessionRevoked
+	}
+
+	if session.RefreshTokenID != claims.ID {
+		if err := s.revoke(ctx, session.ID, model.SessionRevokedReuseDetected); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	newTokenID, err := utils.NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.jwtService.RefreshTokenDuration())
+	rotated, err := s.sessionRepo.RotateRefreshToken(ctx, session.ID, claims.ID, newTokenID, ipAddress, truncateUserAgent(userAgent), expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// 同一个刷新令牌被并发使用，同样视为重放
+		if err := s.revoke(ctx, session.ID, model.SessionRevokedReuseDetected); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	tokenPair, err := s.jwtService.GenerateTokenPair(user.ID, user.Username, user.PasswordVersion, session.ID, newTokenID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return tokenPair, nil
+}
+
+// ListSessions 获取用户的有效会话，并标记发起请求的会话
+func (s *sessionService) ListSessions(ctx context.Context, userID, currentSessionID int64) ([]*model.Session, error) {
+	sessions, err := s.sessionRepo.ListActiveSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, session := range sessions {
+		session.Current = session.ID == currentSessionID
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession 撤销用户的一个会话
+func (s *sessionService) RevokeSession(ctx context.Context, userID, sessionID int64, reason string) error {
+	session, err := s.sessionRepo.GetSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+
+	if session.UserID != userID || session.RevokedAt != nil {
+		return ErrSessionNotFound
+	}
+
+	if err := s.revoke(ctx, sessionID, reason); err != nil {
+		return err
+	}
+
+	// 登出由 authService.Logout 记录
+	if reason != model.SessionRevokedLogout {
+		s.auditService.Record(ctx, &model.AuditLog{
+			Action:       model.AuditActionSessionRevoke,
+			ResourceType: model.AuditResourceSession,
+			ResourceID:   strconv.FormatInt(sessionID, 10),
+			Details:      map[string]any{"reason": reason},
+		})
+	}
+
+	return nil
+}
+
+// RevokeOtherSessions 撤销用户除当前会话之外的所有会话，返回撤销的数量
+func (s *sessionService) RevokeOtherSessions(ctx context.Context, userID, currentSessionID int64) (int, error) {
+	ids, err := s.sessionRepo.RevokeUserSessions(ctx, userID, currentSessionID, model.SessionRevokedByUser)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.blacklistSessions(ctx, ids); err != nil {
+		return 0, err
+	}
+
+	s.auditService.Record(ctx, &model.AuditLog{
+		Action:       model.AuditActionSessionRevokeOthers,
+		ResourceType: model.AuditResourceUser,
+		ResourceID:   strconv.FormatInt(userID, 10),
+		Details:      map[string]any{"revoked": len(ids), "session_ids": ids},
+	})
+
+	return len(ids), nil
+}
+
+// RevokeAllSessions 撤销用户的所有会话（如修改或重置密码后）
+func (s *sessionService) RevokeAllSessions(ctx context.Context, userID int64, reason string) error {
+	ids, err := s.sessionRepo.RevokeUserSessions(ctx, userID, 0, reason)
+	if err != nil {
+		return err
+	}
+
+	return s.blacklistSessions(ctx, ids)
+}
+
+// IsSessionRevoked 检查会话是否已被撤销（只查询令牌黑名单，不访问数据库）
+func (s *sessionService) IsSessionRevoked(ctx context.Context, sessionID int64) (bool, error) {
+	return s.tokenBlacklistRepo.IsBlacklisted(ctx, sessionBlacklistKey(sessionID))
+}
+
+// revoke 撤销会话并将其加入令牌黑名单
+func (s *sessionService) revoke(ctx context.Context, sessionID int64, reason string) error {
+	if _, err := s.sessionRepo.RevokeSession(ctx, sessionID, reason); err != nil {
+		return err
+	}
+
+	return s.blacklistSessions(ctx, []int64{sessionID})
+}
+
+// blacklistSessions 将会话加入令牌黑名单
+// 黑名单有效期为访问令牌有效期，过期后该会话签发的访问令牌也都已过期
+func (s *sessionService) blacklistSessions(ctx context.Context, sessionIDs []int64) error {
+	for _, id := range sessionIDs {
+		if err := s.tokenBlacklistRepo.Add(ctx, sessionBlacklistKey(id), s.jwtService.AccessTokenDuration()); err != nil {
+			return fmt.Errorf("failed to add session to blacklist: %w", err)
+		}
+	}
+	return nil
+}
+
+// sessionBlacklistKey 返回会话在令牌黑名单中的键
+func sessionBlacklistKey(sessionID int64) string {
+	return sessionBlacklistPrefix + strconv.FormatInt(sessionID, 10)
+}
+
+// truncateUserAgent 截断过长的 User-Agent
+func truncateUserAgent(userAgent string) string {
+	runes := []rune(userAgent)
+	if len(runes) > maxUserAgentLength {
+		return string(runes[:maxUserAgentLength])
+	}
+	return userAgent
+}
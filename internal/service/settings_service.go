@@ -3,9 +3,12 @@ package service
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
 	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
 )
 
 // SettingsService 设置服务接口
@@ -27,6 +30,8 @@ type settingsService struct {
 	aiSettingsRepo     *repository.AISettingsRepository
 	userPrefsRepo      repository.UserPreferencesRepository
 	systemSettingsRepo repository.SystemSettingsRepository
+	goalRepo           *repository.NutritionGoalRepository
+	auditService       AuditService
 }
 
 // NewSettingsService 创建设置服务实例
@@ -34,11 +39,15 @@ func NewSettingsService(
 	aiSettingsRepo *repository.AISettingsRepository,
 	userPrefsRepo repository.UserPreferencesRepository,
 	systemSettingsRepo repository.SystemSettingsRepository,
+	goalRepo *repository.NutritionGoalRepository,
+	auditService AuditService,
 ) SettingsService {
 	return &settingsService{
 		aiSettingsRepo:     aiSettingsRepo,
 		userPrefsRepo:      userPrefsRepo,
 		systemSettingsRepo: systemSettingsRepo,
+		goalRepo:           goalRepo,
+		auditService:       auditService,
 	}
 }
 
@@ -81,6 +90,9 @@ func (s *settingsService) UpdateAISettings(ctx context.Context, userID int64, se
 		return fmt.Errorf("failed to check existing settings: %w", err)
 	}
 
+	// 在保存前计算修改，API 密钥只记录是否修改
+	changes := aiSettingsChanges(existing, settings)
+
 	// 如果存在则更新，否则创建
 	if existing != nil {
 		settings.ID = existing.ID
@@ -93,9 +105,34 @@ func (s *settingsService) UpdateAISettings(ctx context.Context, userID int64, se
 		}
 	}
 
+	if len(changes) > 0 {
+		s.auditService.Record(ctx, &model.AuditLog{
+			Action:       model.AuditActionAISettingsUpdate,
+			ResourceType: model.AuditResourceAISettings,
+			ResourceID:   strconv.FormatInt(settings.ID, 10),
+			Changes:      changes,
+		})
+	}
+
 	return nil
 }
 
+// aiSettingsChanges 比较 AI 设置修改前后的值，before 为 nil 表示新建
+func aiSettingsChanges(before, after *model.AISettings) model.AuditChanges {
+	if before == nil {
+		before = &model.AISettings{}
+	}
+
+	changes := model.AuditChanges{}
+	changes.Add("provider", before.Provider, after.Provider)
+	changes.Add("api_endpoint", before.APIEndpoint, after.APIEndpoint)
+	changes.Add("api_key", before.APIKey, after.APIKey)
+	changes.Add("model", before.Model, after.Model)
+	changes.Add("temperature", before.Temperature, after.Temperature)
+	changes.Add("max_tokens", before.MaxTokens, after.MaxTokens)
+	return changes
+}
+
 // TestAIConnection 测试 AI 连接（调用 AI Provider）
 // NOTE: This method is deprecated after removing AI provider implementations.
 // Use the message proxy service for AI interactions instead.
@@ -121,7 +158,25 @@ func (s *settingsService) GetUserPreferences(ctx context.Context, userID int64)
 			DailyCarbsGoal:      250,
 			DailyFatGoal:        70,
 			DailyFiberGoal:      30,
+			DailyWaterGoalML:    model.DefaultDailyWaterGoalML,
+			Allergens:           []string{},
+			Diets:               []string{},
+			RestrictionMode:     model.RestrictionModeWarn,
 		}
+		return prefs, nil
+	}
+
+	if prefs.RestrictionMode == "" {
+		prefs.RestrictionMode = model.RestrictionModeWarn
+	}
+
+	// 营养目标以目标历史中今天生效的日常目标为准（可能是提前安排、今天开始生效的目标）
+	goals, err := s.goalRepo.ListGoals(userID)
+	if err != nil {
+		return nil, err
+	}
+	if current, ok := model.GoalTimeline(goals).ProfileOn(preferencesToday(prefs), model.BaseGoalProfile); ok {
+		setPreferenceGoals(prefs, current)
 	}
 
 	return prefs, nil
@@ -154,9 +209,60 @@ func (s *settingsService) UpdateUserPreferences(ctx context.Context, userID int6
 		}
 	}
 
+	// 营养目标变化时记录一条今天生效的目标历史，过去的日期仍按当时的目标评估
+	if err := s.recordGoalChange(userID, existing, prefs); err != nil {
+		return fmt.Errorf("failed to record goal history: %w", err)
+	}
+
 	return nil
 }
 
+// recordGoalChange 在偏好中的营养目标与今天生效的目标不同时，保存一条今天生效的目标记录
+// 还没有目标历史时，先以修改前的目标（或默认值）记录一条从注册日期开始生效的记录
+func (s *settingsService) recordGoalChange(userID int64, previous, prefs *model.UserPreferences) error {
+	goals, err := s.goalRepo.ListGoals(userID)
+	if err != nil {
+		return err
+	}
+
+	today := preferencesToday(prefs)
+	updated := preferenceGoals(prefs)
+	current, ok := model.GoalTimeline(goals).ProfileOn(today, model.BaseGoalProfile)
+	if !ok {
+		current = preferenceGoals(previous)
+		if err := s.goalRepo.CreateBaseGoal(userID, current, today); err != nil {
+			return err
+		}
+	}
+	if current == updated {
+		return nil
+	}
+
+	return s.goalRepo.UpsertGoal(&model.GoalPeriod{
+		UserID:         userID,
+		EffectiveFrom:  today,
+		NutritionGoals: updated,
+	})
+}
+
+// preferencesToday 返回用户时区中的今天
+func preferencesToday(prefs *model.UserPreferences) time.Time {
+	loc, err := utils.LoadLocation(prefs.Timezone)
+	if err != nil {
+		loc = time.Local
+	}
+	return utils.StartOfDay(time.Now(), loc)
+}
+
+// setPreferenceGoals 将营养目标写入偏好
+func setPreferenceGoals(prefs *model.UserPreferences, goals model.NutritionGoals) {
+	prefs.DailyCaloriesGoal = goals.Calories
+	prefs.DailyProteinGoal = goals.Protein
+	prefs.DailyCarbsGoal = goals.Carbs
+	prefs.DailyFatGoal = goals.Fat
+	prefs.DailyFiberGoal = goals.Fiber
+}
+
 // validateAISettings 验证 AI 设置
 func (s *settingsService) validateAISettings(settings *model.AISettings) error {
 	if settings.Provider == "" {
@@ -228,6 +334,94 @@ func (s *settingsService) validateUserPreferences(prefs *model.UserPreferences)
 		return fmt.Errorf("daily fiber goal must be between 0 and 200g")
 	}
 
+	// 验证饮水目标
+	if prefs.DailyWaterGoalML != 0 && (prefs.DailyWaterGoalML < 500 || prefs.DailyWaterGoalML > 10000) {
+		return fmt.Errorf("daily water goal must be between 500 and 10000ml")
+	}
+
+	// 验证计划对账时间（HH:MM）
+	if prefs.PlanReconcileTime != "" {
+		if _, err := time.Parse("15:04", prefs.PlanReconcileTime); err != nil {
+			return fmt.Errorf("plan reconcile time must be in HH:MM format")
+		}
+	}
+
+	// 验证时区（IANA 时区名称）
+	if _, err := utils.LoadLocation(prefs.Timezone); err != nil {
+		return err
+	}
+
+	// 验证单位
+	if prefs.WeightUnit != "" && prefs.WeightUnit != model.WeightUnitKg && prefs.WeightUnit != model.WeightUnitLb {
+		return fmt.Errorf("weight unit must be kg or lb")
+	}
+	if prefs.LengthUnit != "" && prefs.LengthUnit != model.LengthUnitCm && prefs.LengthUnit != model.LengthUnitIn {
+		return fmt.Errorf("length unit must be cm or in")
+	}
+
+	// 验证目标体重
+	if prefs.GoalWeightKg != nil && (*prefs.GoalWeightKg < model.MinWeightKg || *prefs.GoalWeightKg > model.MaxWeightKg) {
+		return fmt.Errorf("goal weight must be between %d and %d kg", model.MinWeightKg, model.MaxWeightKg)
+	}
+
+	// 验证能量计算个人资料
+	if prefs.Sex != "" && prefs.Sex != model.SexMale && prefs.Sex != model.SexFemale {
+		return fmt.Errorf("sex must be male or female")
+	}
+	if prefs.BirthDate != "" {
+		birthDate, err := time.Parse("2006-01-02", prefs.BirthDate)
+		if err != nil {
+			return fmt.Errorf("birth date must be in YYYY-MM-DD format")
+		}
+		if birthDate.After(time.Now()) {
+			return fmt.Errorf("birth date cannot be in the future")
+		}
+	}
+	if prefs.HeightCm != nil && (*prefs.HeightCm < 50 || *prefs.HeightCm > 300) {
+		return fmt.Errorf("height must be between 50 and 300 cm")
+	}
+	if prefs.ActivityLevel != "" {
+		if _, ok := model.ActivityFactors[prefs.ActivityLevel]; !ok {
+			return fmt.Errorf("invalid activity level: %s", prefs.ActivityLevel)
+		}
+	}
+	if prefs.WeightGoal != "" && prefs.WeightGoal != model.WeightGoalLose &&
+		prefs.WeightGoal != model.WeightGoalMaintain && prefs.WeightGoal != model.WeightGoalGain {
+		return fmt.Errorf("weight goal must be lose, maintain or gain")
+	}
+
+	// 验证运动消耗上调比例
+	if prefs.ExerciseFlexPct < 0 || prefs.ExerciseFlexPct > 100 {
+		return fmt.Errorf("exercise flex percentage must be between 0 and 100")
+	}
+
+	// 验证饮食限制
+	for _, allergen := range prefs.Allergens {
+		if !model.IsValidAllergen(allergen) {
+			return fmt.Errorf("invalid allergen: %s", allergen)
+		}
+	}
+	for _, diet := range prefs.Diets {
+		if !model.IsValidDiet(diet) {
+			return fmt.Errorf("invalid diet: %s", diet)
+		}
+	}
+	if prefs.RestrictionMode != "" && prefs.RestrictionMode != model.RestrictionModeWarn &&
+		prefs.RestrictionMode != model.RestrictionModeReject {
+		return fmt.Errorf("restriction mode must be warn or reject")
+	}
+
+	// 验证偏好用餐时间（餐次 => HH:MM）
+	defaultMealTimes := model.DefaultPreferredMealTimes()
+	for mealType, mealTime := range prefs.PreferredMealTimes {
+		if _, ok := defaultMealTimes[mealType]; !ok {
+			return fmt.Errorf("invalid meal type in preferred meal times: %s", mealType)
+		}
+		if _, err := time.Parse("15:04", mealTime); err != nil {
+			return fmt.Errorf("preferred meal time for %s must be in HH:MM format", mealType)
+		}
+	}
+
 	return nil
 }
 
@@ -268,6 +462,12 @@ func (s *settingsService) GetSystemSettings(ctx context.Context) (map[string]int
 
 // UpdateSystemSettings 更新系统设置
 func (s *settingsService) UpdateSystemSettings(ctx context.Context, settings map[string]interface{}) error {
+	current, err := s.systemSettingsRepo.GetAllSettings(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get system settings: %w", err)
+	}
+
+	changes := model.AuditChanges{}
 	for key, value := range settings {
 		// 转换值为字符串存储
 		var strValue string
@@ -284,6 +484,21 @@ func (s *settingsService) UpdateSystemSettings(ctx context.Context, settings map
 		if err != nil {
 			return fmt.Errorf("failed to update setting %s: %w", key, err)
 		}
+
+		// 新增的设置没有修改前的值
+		if before, ok := current[key]; ok {
+			changes.Add(key, before, strValue)
+		} else {
+			changes.Add(key, nil, strValue)
+		}
+	}
+
+	if len(changes) > 0 {
+		s.auditService.Record(ctx, &model.AuditLog{
+			Action:       model.AuditActionSystemSettingsUpdate,
+			ResourceType: model.AuditResourceSystemSettings,
+			Changes:      changes,
+		})
 	}
 
 	return nil
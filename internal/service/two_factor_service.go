@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+)
+
+const (
+	// totpIssuer 身份验证器应用中显示的发行方名称
+	totpIssuer = "AI Diet Assistant"
+	// recoveryCodeCount 每次生成的恢复码数量
+	recoveryCodeCount = 10
+	// recoveryCodeHalfLength 恢复码每一段的长度（格式为 xxxxx-xxxxx）
+	recoveryCodeHalfLength = 5
+	// recoveryCodeAlphabet 恢复码字符集（去掉了容易混淆的 0/o、1/l/i）
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+var (
+	// ErrTwoFactorNotEnabled 两步验证未启用
+	ErrTwoFactorNotEnabled = errors.New("two-factor authentication is not enabled")
+	// ErrTwoFactorAlreadyEnabled 两步验证已启用
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrTwoFactorNotSetUp 尚未生成两步验证密钥
+	ErrTwoFactorNotSetUp = errors.New("two-factor authentication has not been set up")
+	// ErrInvalidTwoFactorCode 两步验证码或恢复码无效
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+)
+
+// TwoFactorService 两步验证服务接口
+type TwoFactorService interface {
+	GetStatus(ctx context.Context, userID int64) (*model.TwoFactorStatus, error)
+	Setup(ctx context.Context, userID int64, username string) (*model.TwoFactorSetup, error)
+	Enable(ctx context.Context, userID int64, code string) (*model.RecoveryCodes, error)
+	Disable(ctx context.Context, userID int64, password, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) (*model.RecoveryCodes, error)
+	IsEnabled(ctx context.Context, userID int64) (bool, error)
+	Verify(ctx context.Context, userID int64, code string) error
+	Reset(ctx context.Context, userID int64) error
+}
+
+// twoFactorService 两步验证服务实现
+type twoFactorService struct {
+	twoFactorRepo repository.TwoFactorRepository
+	userRepo      repository.UserRepository
+	auditService  AuditService
+}
+
+// NewTwoFactorService 创建两步验证服务实例
+func NewTwoFactorService(twoFactorRepo repository.TwoFactorRepository, userRepo repository.UserRepository, auditService AuditService) TwoFactorService {
+	return &twoFactorService{
+		twoFactorRepo: twoFactorRepo,
+		userRepo:      userRepo,
+		auditService:  auditService,
+	}
+}
+
+// GetStatus 获取用户的两步验证状态
+func (s *twoFactorService) GetStatus(ctx context.Context, userID int64) (*model.TwoFactorStatus, error) {
+	totp, err := s.twoFactorRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTOTPNotFound) {
+			return &model.TwoFactorStatus{Enabled: false}, nil
+		}
+		return nil, err
+	}
+
+	if !totp.IsEnabled() {
+		return &model.TwoFactorStatus{Enabled: false}, nil
+	}
+
+	codes, err := s.twoFactorRepo.GetUnusedRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TwoFactorStatus{
+		Enabled:                true,
+		EnabledAt:              totp.EnabledAt,
+		RecoveryCodesRemaining: len(codes),
+	}, nil
+}
+
+// Setup 生成新的 TOTP 密钥，需要再调用 Enable 验证一次验证码后才会生效
+func (s *twoFactorService) Setup(ctx context.Context, userID int64, username string) (*model.TwoFactorSetup, error) {
+	enabled, err := s.IsEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactorRepo.SaveTOTPSecret(ctx, userID, secret); err != nil {
+		return nil, err
+	}
+
+	return &model.TwoFactorSetup{
+		Secret:     secret,
+		OTPAuthURI: utils.TOTPURI(totpIssuer, username, secret),
+	}, nil
+}
+
+// Enable 验证验证码并启用两步验证，返回一次性展示的恢复码
+func (s *twoFactorService) Enable(ctx context.Context, userID int64, code string) (*model.RecoveryCodes, error) {
+	totp, err := s.twoFactorRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTOTPNotFound) {
+			return nil, ErrTwoFactorNotSetUp
+		}
+		return nil, err
+	}
+
+	if totp.IsEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	step, ok := utils.ValidateTOTP(totp.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactorRepo.EnableTOTP(ctx, userID, step, hashes); err != nil {
+		if errors.Is(err, repository.ErrTOTPNotFound) {
+			// 并发请求已经启用了两步验证
+			return nil, ErrTwoFactorAlreadyEnabled
+		}
+		return nil, err
+	}
+
+	s.recordAudit(ctx, model.AuditActionTwoFactorEnable, userID)
+
+	return &model.RecoveryCodes{Codes: codes}, nil
+}
+
+// Disable 关闭两步验证，需要同时提供密码和验证码（或恢复码）
+func (s *twoFactorService) Disable(ctx context.Context, userID int64, password, code string) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := utils.VerifyPassword(user.PasswordHash, password); err != nil {
+		return repository.ErrInvalidPassword
+	}
+
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return err
+	}
+
+	if err := s.twoFactorRepo.DeleteTwoFactor(ctx, userID); err != nil {
+		return err
+	}
+
+	s.recordAudit(ctx, model.AuditActionTwoFactorDisable, userID)
+
+	return nil
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，旧的恢复码全部作废
+func (s *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) (*model.RecoveryCodes, error) {
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	s.recordAudit(ctx, model.AuditActionRecoveryCodesRegenerate, userID)
+
+	return &model.RecoveryCodes{Codes: codes}, nil
+}
+
+// IsEnabled 检查用户是否已启用两步验证
+func (s *twoFactorService) IsEnabled(ctx context.Context, userID int64) (bool, error) {
+	totp, err := s.twoFactorRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTOTPNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return totp.IsEnabled(), nil
+}
+
+// Verify 验证 TOTP 验证码或恢复码
+// 每个 TOTP 时间步和每个恢复码都只能使用一次
+func (s *twoFactorService) Verify(ctx context.Context, userID int64, code string) error {
+	totp, err := s.twoFactorRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTOTPNotFound) {
+			return ErrTwoFactorNotEnabled
+		}
+		return err
+	}
+
+	if !totp.IsEnabled() {
+		return ErrTwoFactorNotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == utils.TOTPDigits {
+		step, ok := utils.ValidateTOTP(totp.Secret, code, time.Now())
+		if !ok || step <= totp.LastUsedStep {
+			return ErrInvalidTwoFactorCode
+		}
+
+		used, err := s.twoFactorRepo.UseTOTPStep(ctx, userID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	return s.useRecoveryCode(ctx, userID, code)
+}
+
+// Reset 重置用户的两步验证（管理员操作，用于用户丢失设备且没有恢复码的情况）
+func (s *twoFactorService) Reset(ctx context.Context, userID int64) error {
+	return s.twoFactorRepo.DeleteTwoFactor(ctx, userID)
+}
+
+// useRecoveryCode 验证并消耗一个恢复码
+func (s *twoFactorService) useRecoveryCode(ctx context.Context, userID int64, code string) error {
+	normalized := normalizeRecoveryCode(code)
+	if len(normalized) != recoveryCodeHalfLength*2 {
+		return ErrInvalidTwoFactorCode
+	}
+
+	codes, err := s.twoFactorRepo.GetUnusedRecoveryCodes(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, rc := range codes {
+		if utils.VerifyPassword(rc.CodeHash, normalized) != nil {
+			continue
+		}
+
+		used, err := s.twoFactorRepo.UseRecoveryCode(ctx, rc.ID)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	return ErrInvalidTwoFactorCode
+}
+
+// recordAudit 记录用户对自己两步验证设置的操作
+func (s *twoFactorService) recordAudit(ctx context.Context, action string, userID int64) {
+	s.auditService.Record(ctx, &model.AuditLog{
+		Action:       action,
+		ResourceType: model.AuditResourceUser,
+		ResourceID:   strconv.FormatInt(userID, 10),
+	})
+}
+
+// generateRecoveryCodes 生成恢复码，返回明文（用于展示）和哈希（用于存储）
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	max := big.NewInt(int64(len(recoveryCodeAlphabet)))
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, recoveryCodeHalfLength*2)
+		for j := range raw {
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+			}
+			raw[j] = recoveryCodeAlphabet[n.Int64()]
+		}
+
+		hash, err := utils.HashPassword(string(raw))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to hash recovery code: %w", err)
+		}
+
+		codes = append(codes, string(raw[:recoveryCodeHalfLength])+"-"+string(raw[recoveryCodeHalfLength:]))
+		hashes = append(hashes, hash)
+	}
+
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode 规范化用户输入的恢复码（忽略大小写、空格和连字符）
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/model"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/repository"
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/utils"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrUserNotFound 用户不存在
+	ErrUserNotFound = errors.New("user not found")
+	// ErrCannotModifySelf 管理员不能降级、停用或删除自己的账户，避免系统失去管理员
+	ErrCannotModifySelf = errors.New("admins cannot change the role or status of their own account")
+	// ErrInvalidRole 角色无效
+	ErrInvalidRole = errors.New("role must be admin or user")
+)
+
+// UserAdminService 用户管理服务接口（仅供管理员使用）
+type UserAdminService interface {
+	ListUsers(ctx context.Context, filter *model.UserFilter) ([]*model.UserSummary, int, error)
+	GetUser(ctx context.Context, userID int64) (*model.UserSummary, error)
+	CreateUser(ctx context.Context, username, password, email, role string) (*model.User, error)
+	ChangeRole(ctx context.Context, adminID, userID int64, role string) (*model.UserSummary, error)
+	ResetPassword(ctx context.Context, userID int64, newPassword string) error
+	SetDisabled(ctx context.Context, adminID, userID int64, disabled bool) (*model.UserSummary, error)
+	DeleteUser(ctx context.Context, adminID, userID int64) error
+	ListPendingDeletions(ctx context.Context) ([]*model.UserSummary, error)
+	ResetTwoFactor(ctx context.Context, userID int64) error
+}
+
+// userAdminService 用户管理服务实现
+type userAdminService struct {
+	userRepo         repository.UserRepository
+	userCacheRepo    repository.UserCacheRepository
+	twoFactorService TwoFactorService
+	sessionService   SessionService
+	auditService     AuditService
+	passwordPolicy   *utils.PasswordPolicy
+	logger           *zap.Logger
+}
+
+// NewUserAdminService 创建用户管理服务实例
+func NewUserAdminService(
+	userRepo repository.UserRepository,
+	userCacheRepo repository.UserCacheRepository,
+	twoFactorService TwoFactorService,
+	sessionService SessionService,
+	auditService AuditService,
+	passwordPolicy *utils.PasswordPolicy,
+	logger *zap.Logger,
+) UserAdminService {
+	return &userAdminService{
+		userRepo:         userRepo,
+		userCacheRepo:    userCacheRepo,
+		twoFactorService: twoFactorService,
+		sessionService:   sessionService,
+		auditService:     auditService,
+		passwordPolicy:   passwordPolicy,
+		logger:           logger,
+	}
+}
+
+// ListUsers 获取用户列表
+func (s *userAdminService) ListUsers(ctx context.Context, filter *model.UserFilter) ([]*model.UserSummary, int, error) {
+	// 设置默认分页参数
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = 20
+	}
+	if filter.PageSize > 100 {
+		filter.PageSize = 100
+	}
+
+	users, total, err := s.userRepo.ListUsers(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	summaries := make([]*model.UserSummary, 0, len(users))
+	for _, user := range users {
+		summaries = append(summaries, &model.UserSummary{User: user, Status: user.Status()})
+	}
+
+	return summaries, total, nil
+}
+
+// GetUser 获取用户详情及数据使用概况
+func (s *userAdminService) GetUser(ctx context.Context, userID int64) (*model.UserSummary, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := s.userRepo.GetUserUsage(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user usage: %w", err)
+	}
+
+	return &model.UserSummary{User: user, Status: user.Status(), Usage: usage}, nil
+}
+
+// CreateUser 创建用户，未指定角色时为普通用户
+func (s *userAdminService) CreateUser(ctx context.Context, username, password, email, role string) (*model.User, error) {
+	if role == "" {
+		role = model.RoleUser
+	}
+	if role != model.RoleAdmin && role != model.RoleUser {
+		return nil, ErrInvalidRole
+	}
+
+	if err := s.passwordPolicy.Validate(password, username); err != nil {
+		return nil, err
+	}
+
+	// 检查用户名唯一性（不区分大小写）
+	exists, err := s.userRepo.CheckUsernameExists(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check username exists: %w", err)
+	}
+
+	if exists {
+		return nil, ErrUsernameExists
+	}
+
+	user := &model.User{
+		Username: username,
+		Email:    email,
+		Role:     role,
+	}
+
+	if err := s.userRepo.CreateUser(ctx, user, password); err != nil {
+		if errors.Is(err, repository.ErrUserAlreadyExists) {
+			return nil, ErrUsernameExists
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	s.recordAudit(ctx, model.AuditActionAdminUserCreate, user.ID, nil, map[string]any{
+		"username": user.Username,
+		"role":     user.Role,
+	})
+
+	return user, nil
+}
+
+// ChangeRole 修改用户角色
+func (s *userAdminService) ChangeRole(ctx context.Context, adminID, userID int64, role string) (*model.UserSummary, error) {
+	if role != model.RoleAdmin && role != model.RoleUser {
+		return nil, ErrInvalidRole
+	}
+	if adminID == userID {
+		return nil, ErrCannotModifySelf
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdateRole(ctx, userID, role); err != nil {
+		return nil, err
+	}
+
+	changes := model.AuditChanges{}
+	changes.Add("role", user.Role, role)
+	s.recordAudit(ctx, model.AuditActionAdminUserRoleChange, userID, changes, nil)
+
+	user.Role = role
+	return &model.UserSummary{User: user, Status: user.Status()}, nil
+}
+
+// ResetPassword 重置用户密码
+// 同时更新密码版本，使该用户已签发的所有令牌失效
+func (s *userAdminService) ResetPassword(ctx context.Context, userID int64, newPassword string) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.passwordPolicy.Validate(newPassword, user.Username); err != nil {
+		return err
+	}
+
+	newPasswordHash, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err := s.userRepo.UpdatePasswordWithVersion(ctx, userID, newPasswordHash, time.Now().Unix()); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if err := s.sessionService.RevokeAllSessions(ctx, userID, model.SessionRevokedPasswordChanged); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	s.recordAudit(ctx, model.AuditActionAdminUserPasswordReset, userID, nil, nil)
+
+	return nil
+}
+
+// SetDisabled 停用或启用用户
+// 停用后用户无法登录，已签发的令牌在下一次请求时即被拒绝
+func (s *userAdminService) SetDisabled(ctx context.Context, adminID, userID int64, disabled bool) (*model.UserSummary, error) {
+	if adminID == userID {
+		return nil, ErrCannotModifySelf
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 已经是目标状态时保留原来的停用时间
+	if disabled != user.IsDisabled() {
+		var disabledAt *time.Time
+		if disabled {
+			now := time.Now()
+			disabledAt = &now
+		}
+
+		if err := s.userRepo.SetDisabledAt(ctx, userID, disabledAt); err != nil {
+			return nil, err
+		}
+
+		before := user.Status()
+		user.DisabledAt = disabledAt
+
+		changes := model.AuditChanges{}
+		changes.Add("status", before, user.Status())
+		s.recordAudit(ctx, model.AuditActionAdminUserStatusChange, userID, changes, nil)
+	}
+
+	return &model.UserSummary{User: user, Status: user.Status()}, nil
+}
+
+// DeleteUser 删除用户及其所有数据
+func (s *userAdminService) DeleteUser(ctx context.Context, adminID, userID int64) error {
+	if adminID == userID {
+		return ErrCannotModifySelf
+	}
+
+	// 删除前读取用户名，审计日志中保留被删除账户的标识
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.DeleteUser(ctx, userID); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	s.recordAudit(ctx, model.AuditActionAdminUserDelete, userID, nil, map[string]any{
+		"username": user.Username,
+		"role":     user.Role,
+	})
+
+	// 账户已经删除，缓存键删除失败只记录日志
+	if err := s.userCacheRepo.DeleteUserKeys(ctx, userID); err != nil {
+		s.logger.Warn("Failed to delete user cache keys", zap.Int64("user_id", userID), zap.Error(err))
+	}
+
+	return nil
+}
+
+// ListPendingDeletions 获取已申请删除账户的用户，最先到期的在前
+func (s *userAdminService) ListPendingDeletions(ctx context.Context) ([]*model.UserSummary, error) {
+	users, err := s.userRepo.ListPendingDeletions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]*model.UserSummary, 0, len(users))
+	for _, user := range users {
+		summaries = append(summaries, &model.UserSummary{User: user, Status: user.Status()})
+	}
+
+	return summaries, nil
+}
+
+// ResetTwoFactor 重置用户的两步验证，用户下次登录时只需要密码
+func (s *userAdminService) ResetTwoFactor(ctx context.Context, userID int64) error {
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.twoFactorService.Reset(ctx, userID); err != nil {
+		return err
+	}
+
+	s.recordAudit(ctx, model.AuditActionAdminUserTwoFactorReset, userID, nil, nil)
+
+	return nil
+}
+
+// recordAudit 记录管理员对用户的操作
+func (s *userAdminService) recordAudit(ctx context.Context, action string, userID int64, changes model.AuditChanges, details map[string]any) {
+	s.auditService.Record(ctx, &model.AuditLog{
+		Action:       action,
+		ResourceType: model.AuditResourceUser,
+		ResourceID:   strconv.FormatInt(userID, 10),
+		Changes:      changes,
+		Details:      details,
+	})
+}
+
+// getUser 获取用户，不存在时返回 ErrUserNotFound
+func (s *userAdminService) getUser(ctx context.Context, userID int64) (*model.User, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// APITokenPrefix 个人访问令牌前缀，用于与 JWT 区分
+	APITokenPrefix = "pat_"
+	// apiTokenSize 个人访问令牌随机部分长度（字节）
+	apiTokenSize = 32
+	// apiTokenDisplayLength 列表中显示的令牌前缀长度（含 pat_）
+	apiTokenDisplayLength = 12
+)
+
+var (
+	// ErrAPITokenExpired 个人访问令牌已过期
+	ErrAPITokenExpired = errors.New("api token has expired")
+)
+
+// GenerateAPIToken 生成个人访问令牌，返回明文令牌（只展示一次）
+func GenerateAPIToken() (string, error) {
+	b, err := randomTokenBytes(apiTokenSize)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate api token: %w", err)
+	}
+	return APITokenPrefix + hex.EncodeToString(b), nil
+}
+
+// IsAPIToken 检查令牌是否为个人访问令牌格式
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+// HashAPIToken 计算个人访问令牌的 SHA-256 哈希（十六进制）
+func HashAPIToken(token string) string {
+	return hashToken(token)
+}
+
+// APITokenDisplayPrefix 返回令牌开头的几位，用于在列表中识别令牌
+func APITokenDisplayPrefix(token string) string {
+	if len(token) <= apiTokenDisplayLength {
+		return token
+	}
+	return token[:apiTokenDisplayLength]
+}
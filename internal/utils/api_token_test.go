@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestGenerateAPIToken(t *testing.T) {
+	token, err := GenerateAPIToken()
+	if err != nil {
+		t.Fatalf("GenerateAPIToken() error: %v", err)
+	}
+	if !strings.HasPrefix(token, APITokenPrefix) {
+		t.Fatalf("GenerateAPIToken() = %s, missing prefix %s", token, APITokenPrefix)
+	}
+	if !IsAPIToken(token) {
+		t.Errorf("IsAPIToken(%s) = false for a generated token", token)
+	}
+
+	body := strings.TrimPrefix(token, APITokenPrefix)
+	decoded, err := hex.DecodeString(body)
+	if err != nil {
+		t.Fatalf("GenerateAPIToken() body %s is not hex: %v", body, err)
+	}
+	if len(decoded) != apiTokenSize {
+		t.Errorf("GenerateAPIToken() random part = %d bytes, want %d", len(decoded), apiTokenSize)
+	}
+}
+
+func TestIsAPIToken(t *testing.T) {
+	tests := []struct {
+		token string
+		want  bool
+	}{
+		{"pat_abc", true},
+		{"PAT_abc", false},
+		{"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.payload.sig", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsAPIToken(tt.token); got != tt.want {
+			t.Errorf("IsAPIToken(%q) = %v, want %v", tt.token, got, tt.want)
+		}
+	}
+}
+
+func TestAPITokenDisplayPrefix(t *testing.T) {
+	token, err := GenerateAPIToken()
+	if err != nil {
+		t.Fatalf("GenerateAPIToken() error: %v", err)
+	}
+
+	prefix := APITokenDisplayPrefix(token)
+	if len(prefix) != apiTokenDisplayLength {
+		t.Errorf("APITokenDisplayPrefix() length = %d, want %d", len(prefix), apiTokenDisplayLength)
+	}
+	if !strings.HasPrefix(token, prefix) || !strings.HasPrefix(prefix, APITokenPrefix) {
+		t.Errorf("APITokenDisplayPrefix(%s) = %s, want the start of the token", token, prefix)
+	}
+
+	// 比显示长度短的令牌原样返回
+	if got := APITokenDisplayPrefix("pat_01"); got != "pat_01" {
+		t.Errorf("APITokenDisplayPrefix() = %s, want pat_01", got)
+	}
+}
@@ -3,6 +3,8 @@ package utils
 import (
 	"fmt"
 	"time"
+	// Embed the timezone database so user timezones work on hosts without tzdata
+	_ "time/tzdata"
 )
 
 // Supported date formats
@@ -79,3 +81,85 @@ func FormatDate(t time.Time) string {
 func FormatDateISO8601(t time.Time) string {
 	return t.Format(time.RFC3339)
 }
+
+// LoadLocation loads an IANA timezone (e.g., "Asia/Shanghai", "America/New_York").
+// An empty name returns the server's local timezone.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone '%s': %w", name, err)
+	}
+
+	return loc, nil
+}
+
+// ParseDateInLocation parses a date string like ParseDate, but interprets values without
+// an explicit offset (YYYY-MM-DD, ISO 8601 without timezone) in loc, and converts values
+// with an explicit offset to loc
+func ParseDateInLocation(dateStr string, loc *time.Location) (time.Time, error) {
+	if dateStr == "" {
+		return time.Time{}, fmt.Errorf("date string is empty")
+	}
+
+	// Formats without timezone information are interpreted in loc
+	for _, format := range []string{DateFormatYYYYMMDD, DateFormatISO8601Short} {
+		if t, err := time.ParseInLocation(format, dateStr, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	t, err := ParseDate(dateStr)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return t.In(loc), nil
+}
+
+// ParseDateToStartOfDayInLocation parses a date string and returns the start of that day in loc
+func ParseDateToStartOfDayInLocation(dateStr string, loc *time.Location) (time.Time, error) {
+	t, err := ParseDateInLocation(dateStr, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return StartOfDay(t, loc), nil
+}
+
+// ParseDateToEndOfDayInLocation parses a date string and returns the end of that day in loc
+func ParseDateToEndOfDayInLocation(dateStr string, loc *time.Location) (time.Time, error) {
+	t, err := ParseDateInLocation(dateStr, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return EndOfDay(t, loc), nil
+}
+
+// StartOfDay returns the first instant of the calendar day containing t in loc
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// EndOfDay returns the last instant of the calendar day containing t in loc.
+// Days are not assumed to be 24 hours long, so DST transition days are handled correctly.
+func EndOfDay(t time.Time, loc *time.Location) time.Time {
+	start := StartOfDay(t, loc)
+	return time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, loc).Add(-time.Nanosecond)
+}
+
+// DateInLocation returns midnight in loc of the calendar date that t represents.
+// Values exactly at midnight in their own timezone (e.g., "2024-01-15T00:00:00Z" sent for a
+// date-only field, or DATE columns read from the database) keep their calendar date;
+// any other instant is converted to loc first.
+func DateInLocation(t time.Time, loc *time.Location) time.Time {
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+	return StartOfDay(t, loc)
+}
@@ -214,3 +214,186 @@ func TestParseDateConsistency(t *testing.T) {
 		}
 	}
 }
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%s) failed: %v", name, err)
+	}
+	return loc
+}
+
+func TestLoadLocation(t *testing.T) {
+	loc, err := LoadLocation("")
+	if err != nil || loc != time.Local {
+		t.Errorf("LoadLocation(\"\") = %v, %v, want time.Local", loc, err)
+	}
+
+	if _, err := LoadLocation("Asia/Shanghai"); err != nil {
+		t.Errorf("LoadLocation(Asia/Shanghai) unexpected error: %v", err)
+	}
+
+	if _, err := LoadLocation("Mars/Olympus_Mons"); err == nil {
+		t.Errorf("LoadLocation() expected error for invalid timezone")
+	}
+}
+
+func TestParseDateInLocationDayBoundaries(t *testing.T) {
+	shanghai := mustLoadLocation(t, "Asia/Shanghai")
+	losAngeles := mustLoadLocation(t, "America/Los_Angeles")
+
+	tests := []struct {
+		name     string
+		input    string
+		loc      *time.Location
+		wantDate string
+	}{
+		{
+			name:     "Date only is interpreted in location",
+			input:    "2024-01-15",
+			loc:      shanghai,
+			wantDate: "2024-01-15",
+		},
+		{
+			name:     "Late UTC evening is the next day in Shanghai",
+			input:    "2024-01-15T23:30:00Z",
+			loc:      shanghai,
+			wantDate: "2024-01-16",
+		},
+		{
+			name:     "Early UTC morning is the previous day in Los Angeles",
+			input:    "2024-01-15T05:00:00Z",
+			loc:      losAngeles,
+			wantDate: "2024-01-14",
+		},
+		{
+			name:     "Local time without offset stays on the same day",
+			input:    "2024-01-15T23:59:59",
+			loc:      losAngeles,
+			wantDate: "2024-01-15",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, err := ParseDateToStartOfDayInLocation(tt.input, tt.loc)
+			if err != nil {
+				t.Fatalf("ParseDateToStartOfDayInLocation() unexpected error: %v", err)
+			}
+			if start.Location() != tt.loc {
+				t.Errorf("ParseDateToStartOfDayInLocation() location = %v, want %v", start.Location(), tt.loc)
+			}
+			if got := FormatDate(start); got != tt.wantDate {
+				t.Errorf("ParseDateToStartOfDayInLocation() date = %s, want %s", got, tt.wantDate)
+			}
+			if start.Hour() != 0 || start.Minute() != 0 || start.Second() != 0 {
+				t.Errorf("ParseDateToStartOfDayInLocation() = %s, want midnight", start.Format(time.RFC3339))
+			}
+
+			end, err := ParseDateToEndOfDayInLocation(tt.input, tt.loc)
+			if err != nil {
+				t.Fatalf("ParseDateToEndOfDayInLocation() unexpected error: %v", err)
+			}
+			if got := FormatDate(end); got != tt.wantDate {
+				t.Errorf("ParseDateToEndOfDayInLocation() date = %s, want %s", got, tt.wantDate)
+			}
+		})
+	}
+}
+
+func TestDayLengthAcrossDST(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	tests := []struct {
+		name       string
+		date       time.Time
+		wantLength time.Duration
+	}{
+		{
+			name:       "Spring forward day has 23 hours",
+			date:       time.Date(2024, 3, 10, 12, 0, 0, 0, newYork),
+			wantLength: 23 * time.Hour,
+		},
+		{
+			name:       "Fall back day has 25 hours",
+			date:       time.Date(2024, 11, 3, 12, 0, 0, 0, newYork),
+			wantLength: 25 * time.Hour,
+		},
+		{
+			name:       "Regular day has 24 hours",
+			date:       time.Date(2024, 6, 1, 12, 0, 0, 0, newYork),
+			wantLength: 24 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := StartOfDay(tt.date, newYork)
+			end := EndOfDay(tt.date, newYork)
+
+			if got := end.Sub(start) + time.Nanosecond; got != tt.wantLength {
+				t.Errorf("day length = %v, want %v", got, tt.wantLength)
+			}
+			if FormatDate(start) != FormatDate(tt.date) || FormatDate(end) != FormatDate(tt.date) {
+				t.Errorf("day bounds %s - %s do not belong to %s",
+					start.Format(time.RFC3339), end.Format(time.RFC3339), FormatDate(tt.date))
+			}
+		})
+	}
+}
+
+func TestDailyIterationAcrossDST(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	// Iterating with AddDate must hit every calendar day exactly once around DST changes
+	start := time.Date(2024, 3, 9, 0, 0, 0, 0, newYork)
+	want := []string{"2024-03-09", "2024-03-10", "2024-03-11", "2024-03-12"}
+
+	var got []string
+	for d := start; len(got) < len(want); d = d.AddDate(0, 0, 1) {
+		if d.Hour() != 0 {
+			t.Errorf("day %s does not start at midnight", d.Format(time.RFC3339))
+		}
+		got = append(got, FormatDate(d))
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("day %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDateInLocation(t *testing.T) {
+	shanghai := mustLoadLocation(t, "Asia/Shanghai")
+
+	tests := []struct {
+		name     string
+		input    time.Time
+		wantDate string
+	}{
+		{
+			name:     "Midnight UTC keeps its calendar date",
+			input:    time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			wantDate: "2024-01-15",
+		},
+		{
+			name:     "Instant is converted to the location",
+			input:    time.Date(2024, 1, 15, 20, 0, 0, 0, time.UTC),
+			wantDate: "2024-01-16",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := DateInLocation(tt.input, shanghai)
+			if got := FormatDate(result); got != tt.wantDate {
+				t.Errorf("DateInLocation() = %s, want %s", got, tt.wantDate)
+			}
+			if result.Location() != shanghai || result.Hour() != 0 {
+				t.Errorf("DateInLocation() = %s, want midnight in Asia/Shanghai", result.Format(time.RFC3339))
+			}
+		})
+	}
+}
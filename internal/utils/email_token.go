@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// emailTokenSize 邮件令牌随机部分长度（字节）
+const emailTokenSize = 32
+
+// GenerateEmailToken 生成邮件链接中使用的一次性令牌（URL 安全的 base64，无填充）
+func GenerateEmailToken() (string, error) {
+	b, err := randomTokenBytes(emailTokenSize)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate email token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashEmailToken 计算邮件令牌的 SHA-256 哈希（十六进制），数据库中只保存哈希
+func HashEmailToken(token string) string {
+	return hashToken(token)
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestGenerateEmailToken(t *testing.T) {
+	token, err := GenerateEmailToken()
+	if err != nil {
+		t.Fatalf("GenerateEmailToken() error: %v", err)
+	}
+
+	// 令牌直接放在邮件链接的查询参数中，不能包含需要转义的字符
+	if strings.ContainsAny(token, "+/=") {
+		t.Errorf("GenerateEmailToken() = %s, contains characters that need URL escaping", token)
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		t.Fatalf("GenerateEmailToken() = %s, not URL-safe base64: %v", token, err)
+	}
+	if len(decoded) != emailTokenSize {
+		t.Errorf("GenerateEmailToken() decoded length = %d, want %d", len(decoded), emailTokenSize)
+	}
+}
@@ -1,7 +1,10 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -16,13 +19,31 @@ var (
 	ErrTokenGenerationFailed = errors.New("token generation failed")
 	// ErrPasswordChanged 密码已修改
 	ErrPasswordChanged = errors.New("password has been changed")
+	// ErrAccountDisabled 账户已停用，令牌不再有效
+	ErrAccountDisabled = errors.New("account has been disabled")
+	// ErrAccountPendingDeletion 账户已申请删除，重新登录前令牌不再有效
+	ErrAccountPendingDeletion = errors.New("account is scheduled for deletion")
+	// ErrSessionRevoked 令牌所属的会话已被撤销或已过期
+	ErrSessionRevoked = errors.New("session has been revoked")
+)
+
+// 令牌用途常量，访问令牌没有用途字段
+const (
+	// TokenPurposeRefresh 刷新令牌：只能用于换取新的令牌对，每次使用后轮换
+	TokenPurposeRefresh = "refresh"
+	// TokenPurposeMFA 两步验证挑战令牌：密码验证通过后签发，只能用于提交验证码
+	TokenPurposeMFA = "mfa"
+	// MFATokenDuration 两步验证挑战令牌有效期
+	MFATokenDuration = 5 * time.Minute
 )
 
 // Claims JWT 声明结构
 type Claims struct {
 	UserID          int64  `json:"user_id"`
 	Username        string `json:"username"`
-	PasswordVersion int64  `json:"pwd_ver"` // 密码版本（密码修改时间戳）
+	PasswordVersion int64  `json:"pwd_ver"`           // 密码版本（密码修改时间戳）
+	Purpose         string `json:"purpose,omitempty"` // 令牌用途，为空表示访问令牌
+	SessionID       int64  `json:"sid,omitempty"`     // 登录会话 ID，会话被撤销后令牌失效
 	jwt.RegisteredClaims
 }
 
@@ -50,15 +71,29 @@ func NewJWTService(secret string, accessTokenHours, refreshTokenHours int) *JWTS
 }
 
 // GenerateTokenPair 生成 token 对（Access Token 和 Refresh Token）
-func (j *JWTService) GenerateTokenPair(userID int64, username string, passwordVersion int64) (*TokenPair, error) {
+// 两个令牌都属于同一个登录会话，refreshTokenID 写入刷新令牌的 jti，用于轮换和重放检测
+func (j *JWTService) GenerateTokenPair(userID int64, username string, passwordVersion int64, sessionID int64, refreshTokenID string) (*TokenPair, error) {
 	// 生成 Access Token
-	accessToken, err := j.generateToken(userID, username, passwordVersion, j.accessTokenDuration)
+	accessToken, err := j.generateToken(Claims{
+		UserID:          userID,
+		Username:        username,
+		PasswordVersion: passwordVersion,
+		SessionID:       sessionID,
+	}, j.accessTokenDuration)
 	if err != nil {
 		return nil, err
 	}
 
 	// 生成 Refresh Token
-	refreshToken, err := j.generateToken(userID, username, passwordVersion, j.refreshTokenDuration)
+	refreshClaims := Claims{
+		UserID:          userID,
+		Username:        username,
+		PasswordVersion: passwordVersion,
+		Purpose:         TokenPurposeRefresh,
+		SessionID:       sessionID,
+	}
+	refreshClaims.ID = refreshTokenID
+	refreshToken, err := j.generateToken(refreshClaims, j.refreshTokenDuration)
 	if err != nil {
 		return nil, err
 	}
@@ -70,19 +105,32 @@ func (j *JWTService) GenerateTokenPair(userID int64, username string, passwordVe
 	}, nil
 }
 
-// generateToken 生成 JWT token
-func (j *JWTService) generateToken(userID int64, username string, passwordVersion int64, duration time.Duration) (string, error) {
-	now := time.Now()
-	claims := Claims{
+// GenerateMFAToken 生成两步验证挑战令牌
+func (j *JWTService) GenerateMFAToken(userID int64, username string, passwordVersion int64) (string, error) {
+	return j.generateToken(Claims{
 		UserID:          userID,
 		Username:        username,
 		PasswordVersion: passwordVersion,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(duration)),
-			IssuedAt:  jwt.NewNumericDate(now),
-			NotBefore: jwt.NewNumericDate(now),
-		},
-	}
+		Purpose:         TokenPurposeMFA,
+	}, MFATokenDuration)
+}
+
+// AccessTokenDuration 返回访问令牌有效期
+func (j *JWTService) AccessTokenDuration() time.Duration {
+	return j.accessTokenDuration
+}
+
+// RefreshTokenDuration 返回刷新令牌有效期
+func (j *JWTService) RefreshTokenDuration() time.Duration {
+	return j.refreshTokenDuration
+}
+
+// generateToken 填充时间字段并签名 JWT token
+func (j *JWTService) generateToken(claims Claims, duration time.Duration) (string, error) {
+	now := time.Now()
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(duration))
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.NotBefore = jwt.NewNumericDate(now)
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString(j.secret)
@@ -93,8 +141,45 @@ func (j *JWTService) generateToken(userID int64, username string, passwordVersio
 	return tokenString, nil
 }
 
-// ValidateToken 验证并解析 token
+// ValidateToken 验证并解析访问令牌
+// 有特定用途的令牌（如刷新令牌、两步验证挑战令牌）不能当作访问令牌使用
 func (j *JWTService) ValidateToken(tokenString string) (*Claims, error) {
+	claims, err := j.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// ValidateRefreshToken 验证并解析刷新令牌
+func (j *JWTService) ValidateRefreshToken(tokenString string) (*Claims, error) {
+	claims, err := j.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != TokenPurposeRefresh || claims.SessionID == 0 || claims.ID == "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// ValidateMFAToken 验证并解析两步验证挑战令牌
+func (j *JWTService) ValidateMFAToken(tokenString string) (*Claims, error) {
+	claims, err := j.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != TokenPurposeMFA {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// parseToken 验证签名和有效期并解析 token
+func (j *JWTService) parseToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		// 验证签名方法
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -117,22 +202,6 @@ func (j *JWTService) ValidateToken(tokenString string) (*Claims, error) {
 	return nil, ErrInvalidToken
 }
 
-// RefreshAccessToken 使用 Refresh Token 生成新的 Access Token
-func (j *JWTService) RefreshAccessToken(refreshToken string) (string, error) {
-	claims, err := j.ValidateToken(refreshToken)
-	if err != nil {
-		return "", err
-	}
-
-	// 生成新的 Access Token（保持相同的密码版本）
-	accessToken, err := j.generateToken(claims.UserID, claims.Username, claims.PasswordVersion, j.accessTokenDuration)
-	if err != nil {
-		return "", err
-	}
-
-	return accessToken, nil
-}
-
 // ValidatePasswordVersion 验证令牌中的密码版本是否匹配
 func (j *JWTService) ValidatePasswordVersion(claims *Claims, currentPasswordVersion int64) error {
 	if claims.PasswordVersion != currentPasswordVersion {
@@ -140,3 +209,12 @@ func (j *JWTService) ValidatePasswordVersion(claims *Claims, currentPasswordVers
 	}
 	return nil
 }
+
+// NewTokenID 生成随机的令牌 ID（32 位十六进制字符串），用作刷新令牌的 jti
+func NewTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,233 @@
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/config"
+)
+
+// 密码策略规则名称，用于在错误响应中标识未通过的规则
+const (
+	PasswordRuleMinLength         = "min_length"
+	PasswordRuleMaxLength         = "max_length"
+	PasswordRuleUppercase         = "uppercase"
+	PasswordRuleNumber            = "number"
+	PasswordRuleSpecialChar       = "special_char"
+	PasswordRuleCommonPassword    = "common_password"
+	PasswordRuleSimilarToUsername = "similar_to_username"
+)
+
+const (
+	// PasswordMaxBytes 密码最大长度（字节），bcrypt 只使用前 72 字节，超过时无法哈希
+	PasswordMaxBytes = 72
+	// defaultPasswordMinLength 未配置最小长度时使用的默认值
+	defaultPasswordMinLength = 8
+	// minDenylistBaseLength 去掉首尾数字和符号后参与常见密码比对的最小长度
+	minDenylistBaseLength = 4
+	// minSimilarUsernameLength 参与相似度检查的最小用户名长度，过短的用户名容易误判
+	minSimilarUsernameLength = 3
+	// maxUsernameEditDistance 密码与用户名的编辑距离不超过该值时视为过于相似
+	maxUsernameEditDistance = 2
+)
+
+// ErrPasswordPolicy 密码不符合密码策略，具体未通过的规则见 PasswordPolicyError
+var ErrPasswordPolicy = errors.New("password does not meet the password policy")
+
+// PasswordRuleViolation 未通过的密码规则
+type PasswordRuleViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError 密码策略校验错误，包含所有未通过的规则
+type PasswordPolicyError struct {
+	Violations []PasswordRuleViolation `json:"violations"`
+}
+
+// Error 实现 error 接口
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, v.Message)
+	}
+	return ErrPasswordPolicy.Error() + ": " + strings.Join(messages, "; ")
+}
+
+// Is 使 errors.Is(err, ErrPasswordPolicy) 能匹配策略校验错误
+func (e *PasswordPolicyError) Is(target error) bool {
+	return target == ErrPasswordPolicy
+}
+
+// PasswordPolicy 密码策略
+// 由 security 配置驱动，注册、修改密码、管理员重置密码、邮件重置密码和命令行创建用户共用同一策略
+type PasswordPolicy struct {
+	minLength          int
+	requireUppercase   bool
+	requireNumber      bool
+	requireSpecialChar bool
+	denylist           map[string]struct{}
+}
+
+// NewPasswordPolicy 根据安全配置创建密码策略
+// 配置了常见密码列表文件时加载该文件（每行一个密码，忽略空行和 # 开头的注释），文件不存在时返回错误
+func NewPasswordPolicy(cfg *config.SecurityConfig) (*PasswordPolicy, error) {
+	policy := &PasswordPolicy{
+		minLength:          cfg.PasswordMinLength,
+		requireUppercase:   cfg.RequireUppercase,
+		requireNumber:      cfg.RequireNumber,
+		requireSpecialChar: cfg.RequireSpecialChar,
+		denylist:           make(map[string]struct{}),
+	}
+	if policy.minLength <= 0 {
+		policy.minLength = defaultPasswordMinLength
+	}
+
+	if cfg.PasswordDenylistFile != "" {
+		if err := policy.loadDenylist(cfg.PasswordDenylistFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return policy, nil
+}
+
+// loadDenylist 从文件加载常见密码列表
+func (p *PasswordPolicy) loadDenylist(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open password denylist file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.denylist[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read password denylist file: %w", err)
+	}
+
+	return nil
+}
+
+// DenylistSize 返回已加载的常见密码数量
+func (p *PasswordPolicy) DenylistSize() int {
+	return len(p.denylist)
+}
+
+// Validate 校验密码是否符合策略
+// 返回 nil 表示通过；否则返回 *PasswordPolicyError，包含所有未通过的规则。
+// username 为空时跳过用户名相似度检查
+func (p *PasswordPolicy) Validate(password, username string) error {
+	var violations []PasswordRuleViolation
+	add := func(rule, message string) {
+		violations = append(violations, PasswordRuleViolation{Rule: rule, Message: message})
+	}
+
+	if utf8.RuneCountInString(password) < p.minLength {
+		add(PasswordRuleMinLength, fmt.Sprintf("password must be at least %d characters long", p.minLength))
+	}
+	if len(password) > PasswordMaxBytes {
+		add(PasswordRuleMaxLength, fmt.Sprintf("password must not exceed %d bytes", PasswordMaxBytes))
+	}
+
+	var hasUpper, hasNumber, hasSpecial bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsDigit(r):
+			hasNumber = true
+		case !unicode.IsLetter(r) && !unicode.IsSpace(r):
+			hasSpecial = true
+		}
+	}
+	if p.requireUppercase && !hasUpper {
+		add(PasswordRuleUppercase, "password must contain at least one uppercase letter")
+	}
+	if p.requireNumber && !hasNumber {
+		add(PasswordRuleNumber, "password must contain at least one number")
+	}
+	if p.requireSpecialChar && !hasSpecial {
+		add(PasswordRuleSpecialChar, "password must contain at least one special character")
+	}
+
+	if p.isCommonPassword(password) {
+		add(PasswordRuleCommonPassword, "password is too common")
+	}
+	if isSimilarToUsername(password, username) {
+		add(PasswordRuleSimilarToUsername, "password is too similar to the username")
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// isCommonPassword 检查密码是否在常见密码列表中
+// 同时比对去掉首尾数字和符号后的部分，避免 Password123! 这类简单变形绕过检查
+func (p *PasswordPolicy) isCommonPassword(password string) bool {
+	if len(p.denylist) == 0 {
+		return false
+	}
+
+	lower := strings.ToLower(password)
+	if _, ok := p.denylist[lower]; ok {
+		return true
+	}
+
+	base := strings.TrimFunc(lower, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if utf8.RuneCountInString(base) < minDenylistBaseLength {
+		return false
+	}
+	_, ok := p.denylist[base]
+	return ok
+}
+
+// isSimilarToUsername 检查密码是否包含用户名（或其倒序），或与用户名的编辑距离过小
+func isSimilarToUsername(password, username string) bool {
+	name := normalizeForSimilarity(username)
+	if utf8.RuneCountInString(name) < minSimilarUsernameLength {
+		return false
+	}
+
+	normalized := normalizeForSimilarity(password)
+	if strings.Contains(normalized, name) || strings.Contains(normalized, reverseString(name)) {
+		return true
+	}
+
+	return EditDistance(normalized, name) <= maxUsernameEditDistance
+}
+
+// normalizeForSimilarity 转为小写并只保留字母和数字
+func normalizeForSimilarity(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// reverseString 按字符倒序字符串
+func reverseString(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
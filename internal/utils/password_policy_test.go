@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Deepblue-Sky2333/Ai-Diet-Assistant/internal/config"
+)
+
+func newTestPasswordPolicy(t *testing.T, cfg config.SecurityConfig, denylist ...string) *PasswordPolicy {
+	t.Helper()
+
+	if len(denylist) > 0 {
+		path := filepath.Join(t.TempDir(), "common-passwords.txt")
+		content := "# comment\n\n" + strings.Join(denylist, "\n") + "\n"
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write denylist: %v", err)
+		}
+		cfg.PasswordDenylistFile = path
+	}
+
+	policy, err := NewPasswordPolicy(&cfg)
+	if err != nil {
+		t.Fatalf("NewPasswordPolicy() error: %v", err)
+	}
+	return policy
+}
+
+func violatedRules(err error) []string {
+	var policyErr *PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return nil
+	}
+	rules := make([]string, 0, len(policyErr.Violations))
+	for _, v := range policyErr.Violations {
+		rules = append(rules, v.Rule)
+	}
+	return rules
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	strict := config.SecurityConfig{
+		PasswordMinLength:  10,
+		RequireUppercase:   true,
+		RequireNumber:      true,
+		RequireSpecialChar: true,
+	}
+	policy := newTestPasswordPolicy(t, strict, "password", "Qwerty123")
+
+	tests := []struct {
+		name     string
+		password string
+		username string
+		want     []string
+	}{
+		{"valid", "Gr33n-Apple-Tea", "alice", nil},
+		{"too short", "Ab1!xyz", "alice", []string{PasswordRuleMinLength}},
+		{"too long for bcrypt", "Aa1!" + strings.Repeat("x", 70), "alice", []string{PasswordRuleMaxLength}},
+		{"missing uppercase", "gr33n-apple-tea", "alice", []string{PasswordRuleUppercase}},
+		{"missing number", "Green-Apple-Tea", "alice", []string{PasswordRuleNumber}},
+		{"missing special char", "Gr33nAppleTea", "alice", []string{PasswordRuleSpecialChar}},
+		{"multiple rules", "short", "alice", []string{PasswordRuleMinLength, PasswordRuleUppercase, PasswordRuleNumber, PasswordRuleSpecialChar}},
+		{"denylisted case-insensitive", "QWERTY123", "alice", []string{PasswordRuleMinLength, PasswordRuleSpecialChar, PasswordRuleCommonPassword}},
+		{"denylisted with suffix", "Password123!", "alice", []string{PasswordRuleCommonPassword}},
+		{"contains username", "Alice-2024-xyz!", "alice", []string{PasswordRuleSimilarToUsername}},
+		{"contains reversed username", "Ecila-2024-xyz!", "alice", []string{PasswordRuleSimilarToUsername}},
+		{"close to username", "Alicex!9", "alicexy", []string{PasswordRuleMinLength, PasswordRuleSimilarToUsername}},
+		{"short username ignored", "Gr33n-Apple-Tea", "ab", nil},
+		{"empty username ignored", "Gr33n-Apple-Tea", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password, tt.username)
+			got := violatedRules(err)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrPasswordPolicy) {
+				t.Fatalf("Validate() error = %v, want ErrPasswordPolicy", err)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Validate() rules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyDefaults(t *testing.T) {
+	policy := newTestPasswordPolicy(t, config.SecurityConfig{})
+
+	if err := policy.Validate("1234567", ""); !errors.Is(err, ErrPasswordPolicy) {
+		t.Errorf("Validate() with 7 characters error = %v, want ErrPasswordPolicy", err)
+	}
+	if err := policy.Validate("abcdefgh", ""); err != nil {
+		t.Errorf("Validate() with no character requirements error = %v, want nil", err)
+	}
+	if policy.DenylistSize() != 0 {
+		t.Errorf("DenylistSize() = %d, want 0", policy.DenylistSize())
+	}
+}
+
+func TestNewPasswordPolicyDenylist(t *testing.T) {
+	policy := newTestPasswordPolicy(t, config.SecurityConfig{}, "Password", "letmein")
+	if policy.DenylistSize() != 2 {
+		t.Errorf("DenylistSize() = %d, want 2", policy.DenylistSize())
+	}
+
+	_, err := NewPasswordPolicy(&config.SecurityConfig{
+		PasswordDenylistFile: filepath.Join(t.TempDir(), "missing.txt"),
+	})
+	if err == nil {
+		t.Error("NewPasswordPolicy() with missing denylist file should return error")
+	}
+}
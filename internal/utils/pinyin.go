@@ -0,0 +1,461 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// pinyinSyllables maps toneless pinyin syllables (ü written as v) to the Chinese
+// characters read that way. It covers the characters common in food names rather
+// than the whole CJK range; for polyphonic characters the reading used in food
+// names is chosen (卜 as in 萝卜, 参 as in 海参, 茄 as in 番茄). Foods with
+// characters missing here can be found through their aliases.
+var pinyinSyllables = map[string]string{
+	"a":      "阿",
+	"ai":     "艾爱",
+	"an":     "安鹌氨胺按岸暗案",
+	"ang":    "昂",
+	"ao":     "熬奥澳凹袄",
+	"ba":     "八巴芭粑疤拔把吧霸坝爸",
+	"bai":    "白百柏摆败拜",
+	"ban":    "半板拌斑瓣伴扳班般版办",
+	"bang":   "棒蚌榜邦帮绑磅",
+	"bao":    "包宝饱保抱爆鲍煲雹胞苞堡报豹暴",
+	"bei":    "贝北杯背焙碑被倍备悲",
+	"ben":    "本笨苯奔",
+	"beng":   "蹦绷泵崩",
+	"bi":     "比笔碧壁鼻必荜荸蓖毕闭避臂币",
+	"bian":   "扁边鞭便变遍蝙煸鳊编辨辩",
+	"biao":   "标表膘彪",
+	"bie":    "鳖别憋",
+	"bin":    "槟滨宾彬",
+	"bing":   "冰饼并病柄丙炳兵",
+	"bo":     "菠波薄钵拨脖驳剥伯泊勃卜玻博播搏箔",
+	"bu":     "布不部补步埠捕哺簿",
+	"ca":     "擦",
+	"cai":    "菜彩材才采蔡财猜裁踩",
+	"can":    "蚕餐残灿惨",
+	"cang":   "仓苍藏舱",
+	"cao":    "草槽糙曹操",
+	"ce":     "侧策测厕册",
+	"cen":    "岑",
+	"ceng":   "层蹭",
+	"cha":    "茶叉查插差察岔茬碴搽",
+	"chai":   "柴拆豺",
+	"chan":   "蝉缠产蟾铲馋禅颤",
+	"chang":  "长肠常场尝厂唱昌畅鲳偿",
+	"chao":   "炒潮朝超巢吵钞焯",
+	"che":    "车扯彻撤",
+	"chen":   "陈沉辰晨尘臣趁衬",
+	"cheng":  "橙成城程乘称撑澄诚承蛏秤",
+	"chi":    "吃赤池翅匙尺齿持迟驰豉痴",
+	"chong":  "虫冲充崇宠",
+	"chou":   "臭抽绸稠筹丑愁",
+	"chu":    "出初除厨橱楚础储触处锄雏",
+	"chuan":  "川穿串船传喘",
+	"chuang": "窗床闯创疮",
+	"chui":   "吹垂锤捶炊",
+	"chun":   "春纯唇淳醇蠢椿鹑",
+	"ci":     "茨慈磁瓷刺次词此糍雌",
+	"cong":   "葱从丛聪匆",
+	"cu":     "醋粗促簇",
+	"cuan":   "窜汆蹿",
+	"cui":    "脆翠催粹崔",
+	"cun":    "村存寸",
+	"cuo":    "错搓撮",
+	"da":     "大打达答搭",
+	"dai":    "带袋代待戴呆贷黛",
+	"dan":    "蛋单丹担胆淡但弹诞",
+	"dang":   "当党挡荡档",
+	"dao":    "刀岛稻道倒到导盗捣",
+	"de":     "得德的",
+	"deng":   "灯登等凳邓",
+	"di":     "地低底弟帝第滴笛迪抵蒂递",
+	"dian":   "点电店甸垫淀殿颠典",
+	"diao":   "雕掉吊钓鲷刁",
+	"die":    "碟蝶叠跌爹",
+	"ding":   "丁顶定钉鼎订",
+	"dong":   "冬东冻动洞懂董",
+	"dou":    "豆斗抖陡兜逗痘",
+	"du":     "肚毒独读度杜堵赌渡嘟",
+	"duan":   "段断短端锻缎",
+	"dui":    "对堆队兑",
+	"dun":    "炖顿墩盾吨蹲",
+	"duo":    "多朵剁夺躲舵",
+	"e":      "鹅额恶饿俄蛾鳄峨",
+	"en":     "恩",
+	"er":     "儿耳二而饵",
+	"fa":     "发法乏罚阀筏",
+	"fan":    "饭番翻反返范犯泛帆凡烦繁矾蕃",
+	"fang":   "方房芳防放访仿坊肪",
+	"fei":    "飞非肥啡菲费废肺沸翡鲱",
+	"fen":    "粉分份芬坟奋愤粪",
+	"feng":   "风蜂封丰峰锋凤枫疯奉",
+	"fo":     "佛",
+	"fu":     "腐夫肤扶服福浮芙附富副复父付符府腹覆辅伏蝠斧麸敷",
+	"ga":     "嘎尬",
+	"gai":    "盖改钙概该",
+	"gan":    "干甘柑肝敢感杆赶橄秆竿",
+	"gang":   "钢岗刚港缸纲",
+	"gao":    "糕高膏稿告搞",
+	"ge":     "鸽哥歌格葛割革阁个各蛤隔",
+	"gen":    "根跟",
+	"geng":   "羹耕更梗埂",
+	"gong":   "宫公功工供贡攻汞共拱",
+	"gou":    "狗钩沟构购够枸苟",
+	"gu":     "谷骨菇姑古鼓固故顾股雇孤箍菰",
+	"gua":    "瓜挂刮寡卦",
+	"guai":   "怪拐乖",
+	"guan":   "罐管关观官馆灌贯冠",
+	"guang":  "光广逛",
+	"gui":    "桂鬼贵龟规柜跪鳜归硅鲑",
+	"gun":    "滚棍",
+	"guo":    "果锅国过裹郭粿",
+	"ha":     "哈",
+	"hai":    "海孩害亥",
+	"han":    "汉寒含汗旱韩喊罕焊",
+	"hang":   "航杭",
+	"hao":    "好号蚝豪毫耗浩蒿",
+	"he":     "核合和河荷盒何喝禾贺鹤",
+	"hei":    "黑嘿",
+	"hen":    "很恨痕狠",
+	"heng":   "横恒衡哼",
+	"hong":   "红洪烘虹鸿宏轰",
+	"hou":    "猴厚后候喉吼",
+	"hu":     "胡葫湖糊蝴虎壶户护互狐忽呼乎沪",
+	"hua":    "花华滑化画话划桦",
+	"huai":   "怀坏槐淮",
+	"huan":   "环欢换还缓唤幻患",
+	"huang":  "黄皇煌簧荒慌晃谎蝗鳇",
+	"hui":    "灰回会惠汇茴烩辉徽挥毁慧绘鮰",
+	"hun":    "馄荤混昏婚魂浑",
+	"huo":    "火活或货获祸霍藿伙",
+	"ji":     "鸡鲫姬机积肌基及吉急集级极几己技季记既继寄纪济迹际剂荠脊籍激饥稷鲚",
+	"jia":    "家加佳嘉夹甲假价架驾嫁枷颊",
+	"jian":   "煎尖坚间肩艰兼监减剪简碱见件建健剑箭键腱茧笺",
+	"jiang":  "姜江将浆酱讲奖降僵疆豇",
+	"jiao":   "椒角饺脚交郊胶焦蕉叫教较轿窖酵搅缴茭藠娇骄浇",
+	"jie":    "结节街阶接揭杰洁姐解介界芥届借戒秸",
+	"jin":    "金斤今津紧锦仅尽劲近进晋浸筋禁堇",
+	"jing":   "京经精晶井景警净静境镜竞径茎粳鲸",
+	"jiong":  "炯窘",
+	"jiu":    "酒九久韭旧救就舅揪灸",
+	"ju":     "菊橘局居举巨句具剧聚锯据拒俱焗苣桔蒟咀",
+	"juan":   "卷捐娟倦绢",
+	"jue":    "蕨决觉绝爵掘嚼",
+	"jun":    "菌军君均俊骏",
+	"ka":     "咖卡喀",
+	"kai":    "开凯楷慨",
+	"kan":    "看砍刊堪坎",
+	"kang":   "康糠抗扛炕",
+	"kao":    "烤靠考拷",
+	"ke":     "可克科颗壳客刻课棵渴咳稞",
+	"ken":    "肯啃垦",
+	"kong":   "空孔控恐",
+	"kou":    "口扣蔻寇",
+	"ku":     "苦枯哭库裤酷窟",
+	"kua":    "夸跨垮挎",
+	"kuai":   "块快筷",
+	"kuan":   "宽款",
+	"kuang":  "矿筐狂框况旷",
+	"kui":    "葵魁亏盔溃馈",
+	"kun":    "昆困捆坤",
+	"kuo":    "扩阔括",
+	"la":     "辣腊拉啦喇蜡",
+	"lai":    "莱来赖籁",
+	"lan":    "蓝兰篮栏烂懒揽澜榄",
+	"lang":   "狼浪朗郎廊",
+	"lao":    "老捞劳牢烙酪涝姥",
+	"le":     "乐勒了",
+	"lei":    "雷类泪累蕾擂肋",
+	"leng":   "冷棱愣",
+	"li":     "梨李里理力历立利例丽励栗荔粒鲤厘离礼蛎藜莉狸璃篱黎蜊喱沥",
+	"lia":    "俩",
+	"lian":   "莲连联脸练恋炼链镰廉帘鲢",
+	"liang":  "凉粮梁量两亮良辆晾粱",
+	"liao":   "料辽疗聊寥",
+	"lie":    "列烈猎裂劣",
+	"lin":    "林临邻淋琳鳞磷凛",
+	"ling":   "菱零灵铃岭领另令凌陵羚龄鲮",
+	"liu":    "六流留柳榴刘溜瘤",
+	"long":   "龙笼聋隆垄拢",
+	"lou":    "楼漏搂篓",
+	"lu":     "卤芦鲈鹿炉路露陆录",
+	"lv":     "绿驴铝旅滤律",
+	"luan":   "卵乱",
+	"lue":    "略掠",
+	"lun":    "轮论伦",
+	"luo":    "萝罗螺落洛骆锣裸箩",
+	"ma":     "麻马妈码蚂骂吗",
+	"mai":    "麦卖买埋脉迈",
+	"man":    "馒鳗蔓满慢漫曼蛮",
+	"mang":   "芒忙盲茫莽",
+	"mao":    "毛茅猫帽冒贸锚卯",
+	"mei":    "梅莓煤眉美每没妹媒玫霉枚",
+	"men":    "门们焖闷",
+	"meng":   "蒙猛萌檬梦孟盟",
+	"mi":     "米蜜迷谜密秘眯弥觅猕",
+	"mian":   "面棉免绵眠勉",
+	"miao":   "苗秒庙妙描瞄",
+	"mie":    "灭蔑",
+	"min":    "民敏皿闽",
+	"ming":   "明名命鸣铭",
+	"mo":     "蘑魔磨摸模膜末莫墨默沫抹陌馍",
+	"mou":    "某谋牟",
+	"mu":     "木目母亩牡墓幕慕牧穆苜姆",
+	"na":     "拿那纳娜钠",
+	"nai":    "奶耐乃奈",
+	"nan":    "南男难楠腩",
+	"nang":   "囊馕",
+	"nao":    "脑闹恼",
+	"ne":     "呢",
+	"nei":    "内",
+	"nen":    "嫩",
+	"neng":   "能",
+	"ni":     "泥你尼拟逆腻倪",
+	"nian":   "年粘念黏碾捻鲶",
+	"niang":  "娘酿",
+	"niao":   "鸟尿",
+	"nie":    "捏聂",
+	"ning":   "宁凝柠拧",
+	"niu":    "牛扭纽钮妞",
+	"nong":   "农浓弄脓",
+	"nu":     "奴努怒",
+	"nv":     "女",
+	"nuan":   "暖",
+	"nuo":    "糯挪诺",
+	"ou":     "藕欧偶鸥殴呕",
+	"pa":     "怕爬帕扒琶杷",
+	"pai":    "排牌派拍",
+	"pan":    "盘盼判攀潘畔",
+	"pang":   "胖旁庞螃",
+	"pao":    "泡跑炮袍抛刨",
+	"pei":    "配陪培佩赔沛胚",
+	"pen":    "盆喷",
+	"peng":   "碰朋棚蓬鹏膨捧烹澎",
+	"pi":     "皮啤枇琵批披疲脾匹屁譬",
+	"pian":   "片偏篇骗",
+	"piao":   "飘票漂瓢",
+	"pin":    "品拼贫频聘",
+	"ping":   "苹瓶平评凭屏萍坪",
+	"po":     "破坡泼婆迫颇",
+	"pu":     "葡蒲普谱铺扑仆朴浦圃瀑",
+	"qi":     "七期其奇骑棋旗齐起气汽器企启漆妻戚欺杞芪脐琪",
+	"qia":    "恰洽掐",
+	"qian":   "千前钱浅欠牵铅签潜谦芡茜",
+	"qiang":  "枪强墙腔抢羌炝",
+	"qiao":   "桥巧乔荞敲瞧翘俏",
+	"qie":    "茄切且窃",
+	"qin":    "芹琴亲秦勤禽侵寝",
+	"qing":   "青清轻情晴请庆倾卿鲭",
+	"qiong":  "穷琼",
+	"qiu":    "秋球求丘囚邱鳅",
+	"qu":     "区曲取去趣渠驱屈娶蛆",
+	"quan":   "全泉拳权劝圈犬券",
+	"que":    "缺却确雀鹊",
+	"qun":    "群裙",
+	"ran":    "然燃染冉",
+	"rang":   "让壤嚷瓤",
+	"rao":    "饶绕扰",
+	"re":     "热惹",
+	"ren":    "人仁忍认任刃韧",
+	"reng":   "仍扔",
+	"ri":     "日",
+	"rong":   "荣容蓉绒融熔溶茸",
+	"rou":    "肉柔揉",
+	"ru":     "乳如入儒辱茹",
+	"ruan":   "软阮",
+	"rui":    "瑞锐蕊",
+	"run":    "润闰",
+	"ruo":    "弱若蒻",
+	"sa":     "撒洒萨",
+	"sai":    "塞赛腮鳃",
+	"san":    "三伞散叁馓",
+	"sang":   "桑丧嗓",
+	"sao":    "扫骚嫂臊",
+	"se":     "色涩瑟",
+	"sen":    "森",
+	"sha":    "沙砂杀纱鲨傻煞莎",
+	"shai":   "晒筛",
+	"shan":   "山杉衫闪扇善陕珊膳鳝汕",
+	"shang":  "上商伤赏尚裳",
+	"shao":   "烧少勺稍绍哨芍梢",
+	"she":    "蛇舌社射设摄舍涉奢",
+	"shen":   "参身深神审肾甚伸申绅渗慎椹糁",
+	"sheng":  "生声升胜省圣盛剩绳牲笙",
+	"shi":    "十石时食实识史使始示世市式事室视试是柿湿师诗狮施拾饰释氏莳士",
+	"shou":   "手首守寿受兽售瘦收",
+	"shu":    "熟书叔梳舒疏蔬薯鼠属数树术束述竖输黍署",
+	"shua":   "刷耍",
+	"shuai":  "摔衰帅甩",
+	"shuan":  "涮拴栓",
+	"shuang": "双霜爽",
+	"shui":   "水睡税谁",
+	"shun":   "顺瞬",
+	"shuo":   "说硕朔",
+	"si":     "丝四思私司斯死寺似饲撕",
+	"song":   "松送宋颂诵耸",
+	"sou":    "搜艘嗽",
+	"su":     "苏酥素速粟俗塑宿诉肃",
+	"suan":   "酸蒜算",
+	"sui":    "碎岁穗随髓遂隧虽荽",
+	"sun":    "笋孙损",
+	"suo":    "索锁所缩梭蓑",
+	"ta":     "他她它塔踏挞獭",
+	"tai":    "太台泰胎态抬苔",
+	"tan":    "炭碳谈坛潭贪摊滩瘫坦毯叹探檀",
+	"tang":   "汤糖唐堂塘膛躺烫趟棠",
+	"tao":    "桃淘陶逃涛套讨掏萄",
+	"te":     "特",
+	"teng":   "疼腾藤誊",
+	"ti":     "提题蹄体替梯踢剃惕鳀",
+	"tian":   "甜天田填添舔",
+	"tiao":   "条调跳挑眺",
+	"tie":    "铁贴帖",
+	"ting":   "听厅停亭庭挺艇",
+	"tong":   "通同铜童桶筒统痛彤桐茼",
+	"tou":    "头投透偷",
+	"tu":     "土兔突图涂途屠吐徒",
+	"tuan":   "团湍",
+	"tui":    "腿推退褪",
+	"tun":    "吞屯豚臀饨",
+	"tuo":    "托拖脱驼妥鸵陀",
+	"wa":     "瓦挖娃蛙袜哇",
+	"wai":    "外歪",
+	"wan":    "丸碗湾弯完玩晚万顽挽婉豌皖",
+	"wang":   "王网往望忘旺汪亡",
+	"wei":    "味微威维围伟为位尾胃喂卫未委慰薇魏煨鲔",
+	"wen":    "文温闻纹稳问吻蚊",
+	"weng":   "翁嗡瓮蕹",
+	"wo":     "我窝卧握沃蜗莴",
+	"wu":     "五无乌屋污吴午武舞务物误雾悟捂芜坞梧",
+	"xi":     "西吸希息稀溪锡熙膝席习洗喜戏系细夕析犀晰昔惜熄蜥",
+	"xia":    "虾下夏霞峡狭侠厦吓瞎",
+	"xian":   "鲜先仙纤咸贤闲显险县现线限宪陷馅献苋腺衔弦蚬籼",
+	"xiang":  "香乡相箱湘详祥想响享向象像项橡巷",
+	"xiao":   "小肖消宵销硝晓孝校笑效哮萧",
+	"xie":    "蟹些歇协斜鞋写泻谢卸屑械薤",
+	"xin":    "心新辛欣信芯锌馨鑫",
+	"xing":   "杏星腥兴刑形型醒姓幸性行荇",
+	"xiong":  "胸兄凶雄熊",
+	"xiu":    "修休秀袖绣锈嗅羞",
+	"xu":     "须虚需徐许序叙绪续蓄旭絮",
+	"xuan":   "宣悬旋选玄轩癣",
+	"xue":    "雪血学穴靴鳕薛",
+	"xun":    "熏寻巡询训迅讯循旬蕈",
+	"ya":     "鸭压牙芽崖哑雅亚呀",
+	"yan":    "烟盐岩延严言研颜炎沿眼演燕宴验艳雁焰腌咽芫",
+	"yang":   "羊阳杨洋扬养氧痒样央秧",
+	"yao":    "腰邀摇遥药要咬窑谣耀",
+	"ye":     "椰叶野夜业页液爷也冶",
+	"yi":     "一衣医依仪宜移遗疑以已椅蚁义亿忆艺议亦异译易益谊意毅薏翼",
+	"yin":    "因阴音银引饮印隐茵吟",
+	"ying":   "英樱鹰婴营迎盈蝇赢影硬应映莹",
+	"yong":   "用永泳勇涌拥庸蛹",
+	"you":    "油柚游由邮犹有友右又幼优忧悠鱿诱莜",
+	"yu":     "鱼玉芋于余俞娱渔愉榆语雨羽宇与育域欲遇御浴预豫裕禹愈郁",
+	"yuan":   "元园员原圆源远院愿怨猿缘袁苑",
+	"yue":    "月越约阅跃悦岳粤",
+	"yun":    "云运韵允匀晕孕芸耘",
+	"za":     "杂砸",
+	"zai":    "在再灾栽载宰仔崽",
+	"zan":    "赞暂咱簪",
+	"zang":   "脏葬",
+	"zao":    "枣早糟澡灶燥造皂凿藻",
+	"ze":     "则泽责择",
+	"zei":    "贼",
+	"zen":    "怎",
+	"zeng":   "增赠",
+	"zha":    "炸渣扎闸眨榨诈蚱栅楂",
+	"zhai":   "摘窄宅债寨斋",
+	"zhan":   "沾展占战站盏崭斩蘸栈",
+	"zhang":  "章张涨掌丈帐账障樟蟑",
+	"zhao":   "找招照兆罩召沼赵昭",
+	"zhe":    "遮折哲者这浙蔗蛰",
+	"zhen":   "真针珍侦枕诊阵振镇震榛臻胗",
+	"zheng":  "蒸正争整证政征睁挣症郑",
+	"zhi":    "汁芝枝知肢脂织直值植殖执职止只纸指至志制治质智置致栉炙",
+	"zhong":  "中钟终种肿重众仲盅",
+	"zhou":   "粥州洲舟周轴皱昼骤肘",
+	"zhu":    "猪竹珠朱株蛛主煮助住注柱祝著筑铸烛逐",
+	"zhua":   "爪抓",
+	"zhuan":  "砖专转赚撰篆",
+	"zhuang": "装庄桩壮状撞妆",
+	"zhui":   "追坠锥缀",
+	"zhun":   "准",
+	"zhuo":   "桌捉卓灼着浊酌啄",
+	"zi":     "子紫自字资姿滋籽孜梓",
+	"zong":   "粽宗总纵棕踪综",
+	"zou":    "走奏邹揍",
+	"zu":     "组足族祖租阻",
+	"zuan":   "钻纂",
+	"zui":    "嘴最醉罪",
+	"zun":    "尊遵鳟",
+	"zuo":    "做作坐左座昨佐",
+}
+
+// pinyinByRune indexes pinyinSyllables by character
+var pinyinByRune = buildPinyinIndex()
+
+// buildPinyinIndex inverts pinyinSyllables
+func buildPinyinIndex() map[rune]string {
+	index := make(map[rune]string, 3000)
+	for syllable, chars := range pinyinSyllables {
+		for _, r := range chars {
+			index[r] = syllable
+		}
+	}
+	return index
+}
+
+// Pinyin transliterates Chinese characters to toneless pinyin syllables, one per
+// character. Runs of other letters and digits are kept as single lowercase
+// syllables, so "ABC饼干" becomes ["abc", "bing", "gan"]; characters without a
+// known reading are kept as they are. The second result reports whether any
+// character was transliterated.
+func Pinyin(s string) ([]string, bool) {
+	syllables := make([]string, 0, len(s))
+	transliterated := false
+	var word strings.Builder
+
+	flush := func() {
+		if word.Len() > 0 {
+			syllables = append(syllables, word.String())
+			word.Reset()
+		}
+	}
+
+	for _, r := range strings.ToLower(s) {
+		if syllable, ok := pinyinByRune[r]; ok {
+			flush()
+			syllables = append(syllables, syllable)
+			transliterated = true
+			continue
+		}
+		if unicode.Is(unicode.Han, r) {
+			flush()
+			syllables = append(syllables, string(r))
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			word.WriteRune(r)
+			continue
+		}
+		flush()
+	}
+	flush()
+
+	return syllables, transliterated
+}
+
+// PinyinInitials returns the first letter of each syllable, e.g. "jxr" for 鸡胸肉
+func PinyinInitials(syllables []string) string {
+	var initials strings.Builder
+	for _, syllable := range syllables {
+		for _, r := range syllable {
+			initials.WriteRune(r)
+			break
+		}
+	}
+	return initials.String()
+}
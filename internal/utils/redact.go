@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// RedactedValue 脱敏后的占位值
+const RedactedValue = "[REDACTED]"
+
+// sensitiveKeyParts 字段名中出现这些部分（按 _ 和 - 分隔）时视为敏感字段
+var sensitiveKeyParts = map[string]bool{
+	"password":      true,
+	"passwd":        true,
+	"secret":        true,
+	"token":         true,
+	"key":           true,
+	"authorization": true,
+	"credential":    true,
+	"credentials":   true,
+	"hash":          true,
+	"code":          true,
+	"codes":         true,
+	"otp":           true,
+}
+
+// IsSensitiveKey 检查字段名是否为敏感字段（不区分大小写）
+// 例如 password、new_password、api_key、client_secret、refresh_token、recovery_codes
+func IsSensitiveKey(key string) bool {
+	parts := strings.FieldsFunc(strings.ToLower(key), func(r rune) bool {
+		return r == '_' || r == '-' || r == '.'
+	})
+	for _, part := range parts {
+		if sensitiveKeyParts[part] {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactSensitive 返回脱敏后的值
+// map 中敏感字段的值替换为 [REDACTED]，嵌套的 map 和切片递归处理；不修改原值
+// 其他类型的 map、切片和结构体先按 JSON 编码规则转换为 map[string]any 和 []any 再处理，
+// 字段名使用 JSON 字段名；无法编码的值整体替换为 [REDACTED]
+func RedactSensitive(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		redacted := make(map[string]any, len(v))
+		for key, item := range v {
+			if IsSensitiveKey(key) {
+				redacted[key] = RedactedValue
+				continue
+			}
+			redacted[key] = RedactSensitive(item)
+		}
+		return redacted
+	case []any:
+		redacted := make([]any, len(v))
+		for i, item := range v {
+			redacted[i] = RedactSensitive(item)
+		}
+		return redacted
+	case nil:
+		return nil
+	}
+
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Pointer, reflect.Interface:
+		normalized, err := normalizeJSON(value)
+		if err != nil {
+			return RedactedValue
+		}
+		return RedactSensitive(normalized)
+	default:
+		return value
+	}
+}
+
+// normalizeJSON 通过 JSON 编码再解码，将任意值转换为 map[string]any、[]any 和基本类型
+// 数字解码为 json.Number，避免大整数丢失精度
+func normalizeJSON(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var normalized any
+	if err := decoder.Decode(&normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestIsSensitiveKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"password", true},
+		{"new_password", true},
+		{"API_KEY", true},
+		{"client-secret", true},
+		{"refresh_token", true},
+		{"recovery_codes", true},
+		{"password_hash", true},
+		{"username", false},
+		{"api_endpoint", false},
+		{"registration_enabled", false},
+		{"keyboard", false},
+		{"scopes", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsSensitiveKey(tt.key); got != tt.want {
+			t.Errorf("IsSensitiveKey(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestRedactSensitive(t *testing.T) {
+	input := map[string]any{
+		"username": "alice",
+		"api_key":  "sk-123",
+		"nested": map[string]any{
+			"client_secret": "s3cret",
+			"issuer":        "https://id.example.com",
+		},
+		"items": []any{
+			map[string]any{"token": "abc", "name": "ci"},
+		},
+	}
+
+	want := map[string]any{
+		"username": "alice",
+		"api_key":  RedactedValue,
+		"nested": map[string]any{
+			"client_secret": RedactedValue,
+			"issuer":        "https://id.example.com",
+		},
+		"items": []any{
+			map[string]any{"token": RedactedValue, "name": "ci"},
+		},
+	}
+
+	got := RedactSensitive(input)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RedactSensitive() = %v, want %v", got, want)
+	}
+
+	// 原值不被修改
+	if input["api_key"] != "sk-123" {
+		t.Error("RedactSensitive() modified the input map")
+	}
+}
+
+func TestRedactSensitiveTypedValues(t *testing.T) {
+	type provider struct {
+		Name         string `json:"name"`
+		ClientSecret string `json:"client_secret"`
+	}
+
+	tests := []struct {
+		name  string
+		input any
+		want  any
+	}{
+		{
+			name:  "Typed string map",
+			input: map[string]string{"password": "hunter2", "username": "alice"},
+			want:  map[string]any{"password": RedactedValue, "username": "alice"},
+		},
+		{
+			name: "Nested typed maps",
+			input: map[string]map[string]string{
+				"smtp": {"password": "hunter2", "host": "mail.example.com"},
+			},
+			want: map[string]any{
+				"smtp": map[string]any{"password": RedactedValue, "host": "mail.example.com"},
+			},
+		},
+		{
+			name:  "Typed slice of maps",
+			input: []map[string]any{{"token": "abc", "name": "ci"}},
+			want:  []any{map[string]any{"token": RedactedValue, "name": "ci"}},
+		},
+		{
+			name: "Slice of structs uses JSON field names",
+			input: []provider{
+				{Name: "github", ClientSecret: "s3cret"},
+			},
+			want: []any{map[string]any{"name": "github", "client_secret": RedactedValue}},
+		},
+		{
+			name:  "Pointer to struct",
+			input: &provider{Name: "github", ClientSecret: "s3cret"},
+			want:  map[string]any{"name": "github", "client_secret": RedactedValue},
+		},
+		{
+			name: "Typed values inside map[string]any",
+			input: map[string]any{
+				"user_id":   int64(9007199254740993),
+				"providers": []provider{{Name: "github", ClientSecret: "s3cret"}},
+				"headers":   map[string][]string{"Authorization": {"Bearer abc"}, "Accept": {"*/*"}},
+			},
+			want: map[string]any{
+				"user_id":   int64(9007199254740993),
+				"providers": []any{map[string]any{"name": "github", "client_secret": RedactedValue}},
+				"headers":   map[string]any{"Authorization": RedactedValue, "Accept": []any{"*/*"}},
+			},
+		},
+		{
+			name:  "Numbers keep their precision",
+			input: []int64{9007199254740993},
+			want:  []any{json.Number("9007199254740993")},
+		},
+		{
+			name:  "Scalars are unchanged",
+			input: 42,
+			want:  42,
+		},
+		{
+			name:  "Nil",
+			input: nil,
+			want:  nil,
+		},
+		{
+			name:  "Values that cannot be encoded are redacted",
+			input: map[string]any{"callback": []func(){func() {}}},
+			want:  map[string]any{"callback": RedactedValue},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedactSensitive(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RedactSensitive() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package utils
+
+import "context"
+
+// requestInfoKey 请求信息在 context.Context 中的键
+type requestInfoKey struct{}
+
+// RequestInfo 发起请求的用户和客户端信息
+// 由中间件写入请求的 context，服务层记录审计日志时读取，无需逐层传递 IP 和用户代理
+type RequestInfo struct {
+	UserID     int64
+	Username   string
+	APITokenID int64 // 个人访问令牌认证时的令牌 ID，JWT 认证时为 0
+	IPAddress  string
+	UserAgent  string
+}
+
+// WithRequestInfo 返回携带请求信息的 context
+// 保存的是指针，认证中间件在认证成功后补充用户信息
+func WithRequestInfo(ctx context.Context, info *RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestInfoFromContext 从 context 获取请求信息，不存在时返回 nil
+func RequestInfoFromContext(ctx context.Context) *RequestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(*RequestInfo)
+	return info
+}
@@ -61,9 +61,10 @@ var errorMessages = map[int]string{
 
 // AppError 应用错误结构
 type AppError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Err     error  `json:"-"`
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"-"` // 随错误响应返回的结构化详情（如未通过的密码规则）
+	Err     error       `json:"-"`
 }
 
 // Error 实现 error 接口
@@ -86,6 +87,12 @@ func NewAppError(code int, message string, err error) *AppError {
 	}
 }
 
+// WithData 附加随错误响应返回的结构化详情
+func (e *AppError) WithData(data interface{}) *AppError {
+	e.Data = data
+	return e
+}
+
 // Response 统一响应结构
 type Response struct {
 	Code      int         `json:"code"`
@@ -163,6 +170,7 @@ func Error(c *gin.Context, appErr *AppError) {
 	response := Response{
 		Code:      appErr.Code,
 		Message:   sanitizeErrorMessage(appErr.Message),
+		Data:      appErr.Data,
 		Timestamp: time.Now().Unix(),
 	}
 
@@ -132,6 +132,24 @@ func TestErrorResponseWithError(t *testing.T) {
 	assert.NotEmpty(t, response.Error)
 }
 
+func TestErrorResponseWithData(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	details := map[string]string{"rule": "min_length"}
+	Error(c, NewAppError(CodeInvalidParams, "password does not meet the password policy", nil).WithData(details))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response Response
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.Equal(t, CodeInvalidParams, response.Code)
+	assert.Equal(t, map[string]interface{}{"rule": "min_length"}, response.Data)
+}
+
 func TestErrorWithMessageResponse(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	w := httptest.NewRecorder()
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeSearchText(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "Lowercase", input: "Chicken Breast", want: "chicken breast"},
+		{name: "Strip diacritics", input: "Crème Brûlée", want: "creme brulee"},
+		{name: "Full-width forms", input: "ＡＢＣ１２３", want: "abc123"},
+		{name: "Punctuation and whitespace", input: "  chicken,  breast (raw) ", want: "chicken breast raw"},
+		{name: "Chinese kept", input: "鸡胸肉（生）", want: "鸡胸肉 生"},
+		{name: "Empty", input: " - ", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeSearchText(tt.input); got != tt.want {
+				t.Errorf("NormalizeSearchText(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"chicken", "chicken", 0},
+		{"chiken", "chicken", 1},
+		{"chikcen", "chicken", 1},
+		{"brest", "breast", 1},
+		{"tofu", "tempeh", 5},
+		{"", "rice", 4},
+		{"鸡胸肉", "鸡腿肉", 1},
+	}
+
+	for _, tt := range tests {
+		if got := EditDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("EditDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestPinyin(t *testing.T) {
+	tests := []struct {
+		name               string
+		input              string
+		want               []string
+		wantTransliterated bool
+	}{
+		{name: "Food name", input: "鸡胸肉", want: []string{"ji", "xiong", "rou"}, wantTransliterated: true},
+		{name: "Polyphonic food reading", input: "胡萝卜", want: []string{"hu", "luo", "bo"}, wantTransliterated: true},
+		{name: "Mixed with Latin", input: "AD钙奶", want: []string{"ad", "gai", "nai"}, wantTransliterated: true},
+		{name: "Latin only", input: "Greek yogurt", want: []string{"greek", "yogurt"}, wantTransliterated: false},
+		{name: "Unknown character kept", input: "龘肉", want: []string{"龘", "rou"}, wantTransliterated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, transliterated := Pinyin(tt.input)
+			if !reflect.DeepEqual(got, tt.want) || transliterated != tt.wantTransliterated {
+				t.Errorf("Pinyin(%q) = %v, %v, want %v, %v", tt.input, got, transliterated, tt.want, tt.wantTransliterated)
+			}
+		})
+	}
+}
+
+func TestPinyinInitials(t *testing.T) {
+	syllables, _ := Pinyin("西兰花")
+	if got := PinyinInitials(syllables); got != "xlh" {
+		t.Errorf("PinyinInitials(%v) = %q, want %q", syllables, got, "xlh")
+	}
+}
@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// randomTokenBytes 生成令牌的随机部分
+func randomTokenBytes(size int) ([]byte, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// hashToken 计算令牌的 SHA-256 哈希（十六进制）
+// 令牌本身是高熵随机值，使用快速哈希即可安全存储，同时支持按哈希直接查找
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRandomTokenBytes(t *testing.T) {
+	b, err := randomTokenBytes(32)
+	if err != nil {
+		t.Fatalf("randomTokenBytes() error: %v", err)
+	}
+	if len(b) != 32 {
+		t.Errorf("randomTokenBytes() length = %d, want 32", len(b))
+	}
+
+	other, _ := randomTokenBytes(32)
+	if string(other) == string(b) {
+		t.Error("randomTokenBytes() returned the same bytes twice")
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	token := "pat_0123456789abcdef"
+
+	hash := hashToken(token)
+	if len(hash) != 64 {
+		t.Errorf("hashToken() length = %d, want 64", len(hash))
+	}
+	if hash != hashToken(token) {
+		t.Error("hashToken() is not deterministic")
+	}
+	if hash == hashToken(token+"0") {
+		t.Error("hashToken() returned the same hash for different tokens")
+	}
+	if strings.Contains(hash, token) {
+		t.Error("hashToken() leaked the token")
+	}
+}
@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret RFC 6238 附录 B 中 SHA1 测试向量使用的密钥 "12345678901234567890"
+var rfc6238Secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 附录 B 的 8 位验证码取后 6 位
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode(%d) error: %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := TOTPStep(now)
+
+	tests := []struct {
+		name     string
+		step     int64
+		wantOK   bool
+		wantStep int64
+	}{
+		{"Current step", current, true, current},
+		{"Previous step within skew", current - 1, true, current - 1},
+		{"Next step within skew", current + 1, true, current + 1},
+		{"Two steps ago", current - 2, false, 0},
+		{"Two steps ahead", current + 2, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := TOTPCode(rfc6238Secret, tt.step)
+			if err != nil {
+				t.Fatalf("TOTPCode error: %v", err)
+			}
+			step, ok := ValidateTOTP(rfc6238Secret, code, now)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("ValidateTOTP() = (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+
+	if _, ok := ValidateTOTP(rfc6238Secret, "12345", now); ok {
+		t.Error("ValidateTOTP() accepted a code with the wrong length")
+	}
+	if _, ok := ValidateTOTP("not base32!", "123456", now); ok {
+		t.Error("ValidateTOTP() accepted a code for an invalid secret")
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() error: %v", err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("GenerateTOTPSecret() length = %d, want 32", len(secret))
+	}
+	if _, err := TOTPCode(secret, 1); err != nil {
+		t.Errorf("generated secret is not usable: %v", err)
+	}
+
+	other, _ := GenerateTOTPSecret()
+	if other == secret {
+		t.Error("GenerateTOTPSecret() returned the same secret twice")
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("AI Diet Assistant", "alice", "JBSWY3DPEHPK3PXP")
+
+	if !strings.HasPrefix(uri, "otpauth://totp/AI%20Diet%20Assistant:alice?") {
+		t.Errorf("TOTPURI() label = %s", uri)
+	}
+	for _, param := range []string{"secret=JBSWY3DPEHPK3PXP", "issuer=AI+Diet+Assistant", "digits=6", "period=30", "algorithm=SHA1"} {
+		if !strings.Contains(uri, param) {
+			t.Errorf("TOTPURI() = %s, missing %s", uri, param)
+		}
+	}
+}
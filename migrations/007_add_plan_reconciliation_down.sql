@@ -0,0 +1,10 @@
+-- 回滚计划状态自动对账功能迁移
+
+USE ai_diet_assistant;
+
+-- 删除 plans 表的 meal_id 字段
+ALTER TABLE plans DROP FOREIGN KEY fk_plans_meal;
+ALTER TABLE plans DROP COLUMN meal_id;
+
+-- 删除 user_preferences 表的 plan_reconcile_time 字段
+ALTER TABLE user_preferences DROP COLUMN plan_reconcile_time;
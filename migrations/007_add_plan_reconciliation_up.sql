@@ -0,0 +1,23 @@
+-- 添加计划状态自动对账功能
+-- 为计划表添加关联的餐饮记录，为用户偏好添加每日对账时间
+
+USE ai_diet_assistant;
+
+-- 为 plans 表添加 meal_id 字段（计划完成后关联的餐饮记录）
+ALTER TABLE plans
+ADD COLUMN meal_id BIGINT NULL COMMENT '关联的餐饮记录ID' AFTER status,
+ADD CONSTRAINT fk_plans_meal FOREIGN KEY (meal_id) REFERENCES meals(id) ON DELETE SET NULL;
+
+-- 为 user_preferences 表添加每日对账时间（HH:MM，为空时使用系统默认值）
+ALTER TABLE user_preferences
+ADD COLUMN plan_reconcile_time VARCHAR(5) NULL COMMENT '每日计划对账时间(HH:MM)' AFTER daily_fiber_goal;
+
+-- 已完成的计划尝试关联由 CompletePlan 创建的餐饮记录
+UPDATE plans p
+INNER JOIN meals m
+    ON m.user_id = p.user_id
+    AND m.meal_date = p.plan_date
+    AND m.meal_type = p.meal_type
+    AND m.notes = CONCAT('Completed from plan #', p.id)
+SET p.meal_id = m.id
+WHERE p.status = 'completed' AND p.meal_id IS NULL;
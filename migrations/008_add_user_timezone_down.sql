@@ -0,0 +1,5 @@
+-- 回滚用户时区设置
+
+USE ai_diet_assistant;
+
+ALTER TABLE user_preferences DROP COLUMN timezone;
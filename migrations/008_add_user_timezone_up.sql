@@ -0,0 +1,7 @@
+-- 添加用户时区设置
+-- 用于按用户所在时区计算"今天"、每日/每月统计和计划日期
+
+USE ai_diet_assistant;
+
+ALTER TABLE user_preferences
+ADD COLUMN timezone VARCHAR(64) NULL COMMENT 'IANA 时区（如 Asia/Shanghai），为空时使用服务器时区' AFTER plan_reconcile_time;
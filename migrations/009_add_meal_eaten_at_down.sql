@@ -0,0 +1,7 @@
+-- 回滚餐食实际用餐时间
+
+USE ai_diet_assistant;
+
+DROP INDEX idx_meals_user_eaten_at ON meals;
+
+ALTER TABLE meals DROP COLUMN eaten_at_estimated, DROP COLUMN eaten_at;
@@ -0,0 +1,10 @@
+-- 添加餐食实际用餐时间
+-- 用于进食时间分析（进食窗口、深夜进食等），并重新启用 user_preferences.preferred_meal_times
+
+USE ai_diet_assistant;
+
+ALTER TABLE meals
+ADD COLUMN eaten_at DATETIME NULL COMMENT '实际用餐时间，为空时表示未记录' AFTER notes,
+ADD COLUMN eaten_at_estimated BOOLEAN NOT NULL DEFAULT FALSE COMMENT '用餐时间是否为按偏好时间估算的值' AFTER eaten_at;
+
+CREATE INDEX idx_meals_user_eaten_at ON meals(user_id, eaten_at);
@@ -0,0 +1,5 @@
+-- 回滚间歇性断食记录
+
+USE ai_diet_assistant;
+
+DROP TABLE IF EXISTS fasts;
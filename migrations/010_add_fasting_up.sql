@@ -0,0 +1,22 @@
+-- 添加间歇性断食记录
+-- 记录用户的断食周期（开始/结束时间、方案和目标时长）
+
+USE ai_diet_assistant;
+
+CREATE TABLE IF NOT EXISTS fasts (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    user_id BIGINT NOT NULL,
+    protocol VARCHAR(20) NOT NULL COMMENT '断食方案，如 16:8、omad、custom',
+    target_hours DECIMAL(6,2) NOT NULL COMMENT '目标断食时长（小时）',
+    started_at DATETIME NOT NULL COMMENT '开始时间',
+    ended_at DATETIME NULL COMMENT '结束时间，进行中时为空',
+    status ENUM('active', 'completed', 'broken') DEFAULT 'active' COMMENT 'active: 进行中, completed: 主动结束, broken: 因记录餐饮而中断',
+    broken_by_meal_id BIGINT NULL COMMENT '中断断食的餐饮记录',
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_fasts_meal FOREIGN KEY (broken_by_meal_id) REFERENCES meals(id) ON DELETE SET NULL,
+    INDEX idx_fasts_user_started (user_id, started_at),
+    INDEX idx_fasts_user_status (user_id, status)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
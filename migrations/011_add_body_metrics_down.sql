@@ -0,0 +1,10 @@
+-- 回滚身体指标记录
+
+USE ai_diet_assistant;
+
+ALTER TABLE user_preferences
+DROP COLUMN goal_weight_kg,
+DROP COLUMN length_unit,
+DROP COLUMN weight_unit;
+
+DROP TABLE IF EXISTS body_metrics;
@@ -0,0 +1,26 @@
+-- 添加身体指标记录
+-- 记录体重、体脂率和围度（统一以千克/厘米存储），并在用户偏好中添加单位和目标体重
+
+USE ai_diet_assistant;
+
+CREATE TABLE IF NOT EXISTS body_metrics (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    user_id BIGINT NOT NULL,
+    measured_on DATE NOT NULL COMMENT '测量日期',
+    weight_kg DECIMAL(6,2) NULL COMMENT '体重（千克）',
+    body_fat_pct DECIMAL(5,2) NULL COMMENT '体脂率（%）',
+    waist_cm DECIMAL(6,2) NULL COMMENT '腰围（厘米）',
+    hip_cm DECIMAL(6,2) NULL COMMENT '臀围（厘米）',
+    chest_cm DECIMAL(6,2) NULL COMMENT '胸围（厘米）',
+    neck_cm DECIMAL(6,2) NULL COMMENT '颈围（厘米）',
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY uk_body_metrics_user_date (user_id, measured_on)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE user_preferences
+ADD COLUMN weight_unit VARCHAR(2) NULL COMMENT '体重单位（kg/lb），为空时使用 kg' AFTER preferred_meal_times,
+ADD COLUMN length_unit VARCHAR(2) NULL COMMENT '围度单位（cm/in），为空时使用 cm' AFTER weight_unit,
+ADD COLUMN goal_weight_kg DECIMAL(6,2) NULL COMMENT '目标体重（千克）' AFTER length_unit;
@@ -0,0 +1,11 @@
+-- 回滚能量计算个人资料
+
+USE ai_diet_assistant;
+
+ALTER TABLE user_preferences
+DROP COLUMN adaptive_tdee,
+DROP COLUMN weight_goal,
+DROP COLUMN activity_level,
+DROP COLUMN height_cm,
+DROP COLUMN birth_date,
+DROP COLUMN sex;
@@ -0,0 +1,12 @@
+-- 添加能量计算所需的个人资料
+-- 用于计算 BMR/TDEE 并推荐热量和宏量营养素目标
+
+USE ai_diet_assistant;
+
+ALTER TABLE user_preferences
+ADD COLUMN sex VARCHAR(10) NULL COMMENT '性别（male/female）' AFTER goal_weight_kg,
+ADD COLUMN birth_date DATE NULL COMMENT '出生日期' AFTER sex,
+ADD COLUMN height_cm DECIMAL(5,1) NULL COMMENT '身高（厘米）' AFTER birth_date,
+ADD COLUMN activity_level VARCHAR(20) NULL COMMENT '活动水平（sedentary/light/moderate/active/very_active）' AFTER height_cm,
+ADD COLUMN weight_goal VARCHAR(10) NULL COMMENT '体重目标（lose/maintain/gain）' AFTER activity_level,
+ADD COLUMN adaptive_tdee BOOLEAN NOT NULL DEFAULT FALSE COMMENT '是否根据摄入和体重变化自适应计算 TDEE' AFTER weight_goal;
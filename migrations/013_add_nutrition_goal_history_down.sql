@@ -0,0 +1,5 @@
+-- 回滚营养目标历史
+
+USE ai_diet_assistant;
+
+DROP TABLE IF EXISTS nutrition_goals;
@@ -0,0 +1,32 @@
+-- 添加营养目标历史
+-- 每条记录从生效日期开始生效，直到下一条记录的生效日期；支持提前安排未来的目标变更
+-- 历史对比和趋势按每天当时生效的目标计算
+
+USE ai_diet_assistant;
+
+CREATE TABLE IF NOT EXISTS nutrition_goals (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    user_id BIGINT NOT NULL,
+    effective_from DATE NOT NULL COMMENT '生效日期',
+    calories INT NOT NULL COMMENT '每日热量目标（千卡）',
+    protein INT NOT NULL COMMENT '每日蛋白质目标（克）',
+    carbs INT NOT NULL COMMENT '每日碳水化合物目标（克）',
+    fat INT NOT NULL COMMENT '每日脂肪目标（克）',
+    fiber INT NOT NULL COMMENT '每日纤维目标（克）',
+    note VARCHAR(200) NULL COMMENT '备注，如减脂阶段',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY uk_nutrition_goals_user_date (user_id, effective_from)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 以现有偏好中的目标作为每个用户的第一条记录（未设置的目标使用默认值）
+INSERT IGNORE INTO nutrition_goals (user_id, effective_from, calories, protein, carbs, fat, fiber)
+SELECT user_id,
+       DATE(created_at),
+       COALESCE(NULLIF(daily_calories_goal, 0), 2000),
+       COALESCE(NULLIF(daily_protein_goal, 0), 150),
+       COALESCE(NULLIF(daily_carbs_goal, 0), 250),
+       COALESCE(NULLIF(daily_fat_goal, 0), 70),
+       COALESCE(NULLIF(daily_fiber_goal, 0), 30)
+FROM user_preferences;
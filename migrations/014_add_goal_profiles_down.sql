@@ -0,0 +1,16 @@
+-- 回滚目标配置
+
+USE ai_diet_assistant;
+
+DROP TABLE IF EXISTS goal_profile_overrides;
+DROP TABLE IF EXISTS goal_profile_schedules;
+
+-- 只保留日常目标
+DELETE FROM nutrition_goals WHERE profile <> '';
+
+ALTER TABLE nutrition_goals
+ADD UNIQUE KEY uk_nutrition_goals_user_date (user_id, effective_from);
+
+ALTER TABLE nutrition_goals
+DROP INDEX uk_nutrition_goals_user_profile_date,
+DROP COLUMN profile;
@@ -0,0 +1,39 @@
+-- 添加目标配置（如训练日、休息日、高碳日）
+-- 每个配置有自己的营养目标（与日常目标一样按生效日期记录），按星期安排或按日期单独指定
+
+USE ai_diet_assistant;
+
+-- 目标历史增加配置名称，空字符串表示日常目标
+ALTER TABLE nutrition_goals
+ADD COLUMN profile VARCHAR(30) NOT NULL DEFAULT '' COMMENT '目标配置名称，空字符串表示日常目标' AFTER user_id;
+
+ALTER TABLE nutrition_goals
+ADD UNIQUE KEY uk_nutrition_goals_user_profile_date (user_id, profile, effective_from);
+
+ALTER TABLE nutrition_goals
+DROP INDEX uk_nutrition_goals_user_date;
+
+-- 按星期安排目标配置，同样按生效日期记录
+CREATE TABLE IF NOT EXISTS goal_profile_schedules (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    user_id BIGINT NOT NULL,
+    effective_from DATE NOT NULL COMMENT '生效日期',
+    weekday_profiles JSON NOT NULL COMMENT '星期 => 目标配置名称',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY uk_goal_profile_schedules_user_date (user_id, effective_from)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 按日期单独指定目标配置，优先于星期安排
+CREATE TABLE IF NOT EXISTS goal_profile_overrides (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    user_id BIGINT NOT NULL,
+    override_date DATE NOT NULL COMMENT '日期',
+    profile VARCHAR(30) NOT NULL DEFAULT '' COMMENT '目标配置名称，空字符串表示使用日常目标',
+    note VARCHAR(200) NULL COMMENT '备注',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY uk_goal_profile_overrides_user_date (user_id, override_date)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,8 @@
+-- 回滚运动与活动记录
+
+USE ai_diet_assistant;
+
+ALTER TABLE user_preferences
+DROP COLUMN exercise_flex_pct;
+
+DROP TABLE IF EXISTS activities;
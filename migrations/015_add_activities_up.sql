@@ -0,0 +1,25 @@
+-- 添加运动与活动记录
+-- 未提供消耗热量时按 MET 值和体重估算；用户偏好中可设置按运动消耗上调热量目标的比例
+
+USE ai_diet_assistant;
+
+CREATE TABLE IF NOT EXISTS activities (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    user_id BIGINT NOT NULL,
+    activity_type VARCHAR(50) NOT NULL COMMENT '活动类型',
+    performed_on DATE NOT NULL COMMENT '活动日期',
+    started_at DATETIME NULL COMMENT '开始时间',
+    duration_minutes INT NOT NULL COMMENT '时长（分钟）',
+    intensity VARCHAR(10) NOT NULL DEFAULT 'moderate' COMMENT '强度（low/moderate/high）',
+    met DECIMAL(4,1) NULL COMMENT '估算使用的 MET 值',
+    calories_burned DECIMAL(8,2) NOT NULL COMMENT '消耗热量（千卡）',
+    calories_estimated BOOLEAN NOT NULL DEFAULT FALSE COMMENT '消耗热量是否为估算值',
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_activities_user_date (user_id, performed_on)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE user_preferences
+ADD COLUMN exercise_flex_pct INT NOT NULL DEFAULT 0 COMMENT '按运动消耗上调热量目标的比例（0-100%）' AFTER adaptive_tdee;
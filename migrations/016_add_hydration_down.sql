@@ -0,0 +1,11 @@
+-- 回滚饮水记录
+
+USE ai_diet_assistant;
+
+ALTER TABLE user_preferences
+DROP COLUMN daily_water_goal_ml;
+
+ALTER TABLE foods
+DROP COLUMN water_pct;
+
+DROP TABLE IF EXISTS water_logs;
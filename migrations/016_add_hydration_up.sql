@@ -0,0 +1,24 @@
+-- 添加饮水记录
+-- 牛奶、果汁等同时也是食材的饮品在餐饮记录中记录一次，按食材的含水比例计入饮水量
+
+USE ai_diet_assistant;
+
+CREATE TABLE IF NOT EXISTS water_logs (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    user_id BIGINT NOT NULL,
+    log_date DATE NOT NULL COMMENT '饮水日期',
+    logged_at DATETIME NULL COMMENT '饮水时间',
+    amount_ml INT NOT NULL COMMENT '饮水量（毫升）',
+    beverage_type VARCHAR(20) NOT NULL DEFAULT 'water' COMMENT '饮品类型',
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_water_logs_user_date (user_id, log_date)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE foods
+ADD COLUMN water_pct DECIMAL(5,2) NOT NULL DEFAULT 0 COMMENT '计入饮水量的含水比例（%），0 表示不是饮品' AFTER calories;
+
+ALTER TABLE user_preferences
+ADD COLUMN daily_water_goal_ml INT NOT NULL DEFAULT 2000 COMMENT '每日饮水目标（毫升）' AFTER daily_fiber_goal;
@@ -0,0 +1,12 @@
+-- 回滚结构化饮食限制
+
+USE ai_diet_assistant;
+
+ALTER TABLE user_preferences
+DROP COLUMN restriction_mode,
+DROP COLUMN diets,
+DROP COLUMN allergens;
+
+ALTER TABLE foods
+DROP COLUMN diet_tags,
+DROP COLUMN allergens;
@@ -0,0 +1,13 @@
+-- 添加结构化饮食限制
+-- 食材标记过敏原和适用的饮食方式，用户偏好记录需要避免的过敏原、遵循的饮食方式和违反限制时的处理方式
+
+USE ai_diet_assistant;
+
+ALTER TABLE foods
+ADD COLUMN allergens JSON NULL COMMENT '食材包含的过敏原' AFTER water_pct,
+ADD COLUMN diet_tags JSON NULL COMMENT '食材适用的饮食方式' AFTER allergens;
+
+ALTER TABLE user_preferences
+ADD COLUMN allergens JSON NULL COMMENT '需要避免的过敏原' AFTER exercise_flex_pct,
+ADD COLUMN diets JSON NULL COMMENT '遵循的饮食方式' AFTER allergens,
+ADD COLUMN restriction_mode VARCHAR(10) NULL COMMENT '违反饮食限制时的处理方式（warn/reject）' AFTER diets;
@@ -0,0 +1,15 @@
+-- 回滚可扩展的食材分类树和标签
+-- 内置子分类归入原有的枚举值，其余新分类和用户自定义分类归入 other
+
+USE ai_diet_assistant;
+
+UPDATE foods SET category = 'meat' WHERE category IN ('poultry', 'red_meat');
+UPDATE foods SET category = 'vegetable' WHERE category IN ('leafy_green', 'root_vegetable');
+UPDATE foods SET category = 'grain' WHERE category IN ('whole_grain', 'refined_grain');
+UPDATE foods SET category = 'other' WHERE category NOT IN ('meat', 'vegetable', 'fruit', 'grain', 'other');
+
+ALTER TABLE foods
+DROP COLUMN tags,
+MODIFY COLUMN category ENUM('meat', 'vegetable', 'fruit', 'grain', 'other') NOT NULL COMMENT '食材分类';
+
+DROP TABLE IF EXISTS food_categories;
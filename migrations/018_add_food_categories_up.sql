@@ -0,0 +1,53 @@
+-- 添加可扩展的食材分类树和标签
+-- 内置分类对所有用户可见（user_id 为 NULL），用户可以在任意分类下添加自己的子分类
+-- foods.category 由固定枚举改为分类标识（slug），原有的 5 个枚举值对应同名的内置分类，无需转换
+
+USE ai_diet_assistant;
+
+CREATE TABLE IF NOT EXISTS food_categories (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    user_id BIGINT NULL COMMENT '所属用户，NULL 表示内置分类',
+    parent_id BIGINT NULL COMMENT '上级分类，NULL 表示顶级分类',
+    slug VARCHAR(50) NOT NULL COMMENT '分类标识，创建后不可修改',
+    name VARCHAR(50) NOT NULL COMMENT '显示名称',
+    sort_order INT NOT NULL DEFAULT 0 COMMENT '同级排序',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES food_categories(id) ON DELETE CASCADE,
+    UNIQUE KEY uk_food_categories_user_slug (user_id, slug),
+    INDEX idx_food_categories_parent (parent_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 内置顶级分类
+INSERT INTO food_categories (user_id, parent_id, slug, name, sort_order) VALUES
+    (NULL, NULL, 'meat', '肉类', 10),
+    (NULL, NULL, 'seafood', '水产', 20),
+    (NULL, NULL, 'eggs', '蛋类', 30),
+    (NULL, NULL, 'dairy', '奶制品', 40),
+    (NULL, NULL, 'vegetable', '蔬菜', 50),
+    (NULL, NULL, 'fruit', '水果', 60),
+    (NULL, NULL, 'grain', '谷物', 70),
+    (NULL, NULL, 'legume', '豆类', 80),
+    (NULL, NULL, 'nuts_seeds', '坚果种子', 90),
+    (NULL, NULL, 'oil', '油脂', 100),
+    (NULL, NULL, 'beverage', '饮品', 110),
+    (NULL, NULL, 'condiment', '调味品', 120),
+    (NULL, NULL, 'other', '其他', 1000);
+
+-- 内置子分类
+INSERT INTO food_categories (user_id, parent_id, slug, name, sort_order)
+SELECT NULL, p.id, c.slug, c.name, c.sort_order
+FROM (
+    SELECT 'meat' AS parent, 'poultry' AS slug, '禽肉' AS name, 10 AS sort_order
+    UNION ALL SELECT 'meat', 'red_meat', '红肉', 20
+    UNION ALL SELECT 'vegetable', 'leafy_green', '叶菜', 10
+    UNION ALL SELECT 'vegetable', 'root_vegetable', '根茎类', 20
+    UNION ALL SELECT 'grain', 'whole_grain', '全谷物', 10
+    UNION ALL SELECT 'grain', 'refined_grain', '精制谷物', 20
+) c
+JOIN (SELECT id, slug FROM food_categories WHERE user_id IS NULL AND parent_id IS NULL) p ON p.slug = c.parent;
+
+ALTER TABLE foods
+MODIFY COLUMN category VARCHAR(50) NOT NULL DEFAULT 'other' COMMENT '食材分类（food_categories.slug）',
+ADD COLUMN tags JSON NULL COMMENT '自定义标签' AFTER category;
@@ -0,0 +1,6 @@
+-- 回滚食材别名
+
+USE ai_diet_assistant;
+
+ALTER TABLE foods
+DROP COLUMN aliases;
@@ -0,0 +1,7 @@
+-- 添加食材别名
+-- 别名用于食材搜索，如为"鸡胸肉"添加 "chicken breast"，或为内置拼音表未覆盖的汉字添加拼音
+
+USE ai_diet_assistant;
+
+ALTER TABLE foods
+ADD COLUMN aliases JSON NULL COMMENT '搜索别名' AFTER name;
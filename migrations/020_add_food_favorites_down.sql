@@ -0,0 +1,7 @@
+-- 回滚收藏食材
+
+USE ai_diet_assistant;
+
+ALTER TABLE foods
+DROP INDEX idx_user_favorite,
+DROP COLUMN favorite;
@@ -0,0 +1,8 @@
+-- 添加收藏食材
+-- 收藏的食材在餐饮录入建议中置顶，并在搜索中排名靠前
+
+USE ai_diet_assistant;
+
+ALTER TABLE foods
+ADD COLUMN favorite BOOLEAN NOT NULL DEFAULT FALSE COMMENT '是否收藏' AFTER available,
+ADD INDEX idx_user_favorite (user_id, favorite);
@@ -0,0 +1,7 @@
+-- 回滚用户停用状态
+
+USE ai_diet_assistant;
+
+ALTER TABLE users
+DROP INDEX idx_role,
+DROP COLUMN disabled_at;
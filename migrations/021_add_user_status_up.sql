@@ -0,0 +1,8 @@
+-- 添加用户停用状态
+-- 管理员可以停用账户，停用的账户无法登录，已签发的令牌立即失效
+
+USE ai_diet_assistant;
+
+ALTER TABLE users
+ADD COLUMN disabled_at TIMESTAMP NULL DEFAULT NULL COMMENT '停用时间，NULL 表示正常' AFTER role,
+ADD INDEX idx_role (role);
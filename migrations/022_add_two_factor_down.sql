@@ -0,0 +1,6 @@
+-- 回滚两步验证
+
+USE ai_diet_assistant;
+
+DROP TABLE IF EXISTS mfa_recovery_codes;
+DROP TABLE IF EXISTS user_totp;
@@ -0,0 +1,26 @@
+-- 添加两步验证（TOTP）
+-- 密钥使用 AES-GCM 加密存储，恢复码使用 bcrypt 哈希存储
+
+USE ai_diet_assistant;
+
+-- TOTP 密钥表：每个用户最多一个，enabled_at 为空表示已生成密钥但尚未验证启用
+CREATE TABLE IF NOT EXISTS user_totp (
+    user_id BIGINT PRIMARY KEY,
+    secret_encrypted TEXT NOT NULL COMMENT '加密的 TOTP 密钥',
+    enabled_at TIMESTAMP NULL DEFAULT NULL COMMENT '启用时间',
+    last_used_step BIGINT NOT NULL DEFAULT 0 COMMENT '最近一次使用的时间步，防止验证码重放',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 恢复码表：每个恢复码只能使用一次
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    user_id BIGINT NOT NULL,
+    code_hash VARCHAR(255) NOT NULL COMMENT '恢复码哈希',
+    used_at TIMESTAMP NULL DEFAULT NULL COMMENT '使用时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_user_used (user_id, used_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
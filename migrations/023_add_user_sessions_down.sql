@@ -0,0 +1,5 @@
+-- 回滚登录会话
+
+USE ai_diet_assistant;
+
+DROP TABLE IF EXISTS user_sessions;
@@ -0,0 +1,20 @@
+-- 添加登录会话
+-- 每次登录创建一个会话，会话即一个刷新令牌家族：刷新时轮换刷新令牌，
+-- 旧的刷新令牌被再次使用（重放）时撤销整个会话
+
+USE ai_diet_assistant;
+
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    user_id BIGINT NOT NULL,
+    refresh_token_id CHAR(32) NOT NULL COMMENT '当前有效的刷新令牌 ID（jti），每次刷新时轮换',
+    user_agent VARCHAR(255) NOT NULL DEFAULT '' COMMENT '登录设备的 User-Agent',
+    ip_address VARCHAR(45) NOT NULL DEFAULT '' COMMENT '最近一次使用的 IP 地址',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '最近一次登录或刷新的时间',
+    expires_at TIMESTAMP NOT NULL COMMENT '当前刷新令牌的过期时间',
+    revoked_at TIMESTAMP NULL DEFAULT NULL COMMENT '撤销时间',
+    revoked_reason VARCHAR(32) NULL DEFAULT NULL COMMENT '撤销原因：logout, revoked, reuse_detected, password_changed',
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_user_revoked (user_id, revoked_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,5 @@
+-- 回滚个人访问令牌
+
+USE ai_diet_assistant;
+
+DROP TABLE IF EXISTS api_tokens;
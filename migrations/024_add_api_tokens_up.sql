@@ -0,0 +1,20 @@
+-- 添加个人访问令牌
+-- 令牌只在创建时返回一次，数据库中只保存 SHA-256 哈希
+
+USE ai_diet_assistant;
+
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    user_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL COMMENT '令牌名称',
+    token_hash CHAR(64) NOT NULL COMMENT '令牌的 SHA-256 哈希',
+    token_prefix VARCHAR(16) NOT NULL COMMENT '令牌开头几位，用于在列表中识别',
+    scopes JSON NOT NULL COMMENT '权限范围列表',
+    expires_at TIMESTAMP NULL DEFAULT NULL COMMENT '过期时间，为空表示永不过期',
+    last_used_at TIMESTAMP NULL DEFAULT NULL COMMENT '最近一次使用时间',
+    last_used_ip VARCHAR(45) NULL DEFAULT NULL COMMENT '最近一次使用的 IP 地址',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY uk_token_hash (token_hash),
+    INDEX idx_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
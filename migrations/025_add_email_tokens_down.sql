@@ -0,0 +1,9 @@
+-- 回滚邮箱验证和密码重置
+
+USE ai_diet_assistant;
+
+DROP TABLE IF EXISTS email_tokens;
+
+ALTER TABLE users
+DROP INDEX idx_email,
+DROP COLUMN email_verified_at;
@@ -0,0 +1,22 @@
+-- 添加邮箱验证和密码重置
+-- 邮件中的令牌只使用一次且会过期，数据库中只保存 SHA-256 哈希
+
+USE ai_diet_assistant;
+
+ALTER TABLE users
+ADD COLUMN email_verified_at TIMESTAMP NULL DEFAULT NULL COMMENT '邮箱验证时间，NULL 表示未验证' AFTER email,
+ADD INDEX idx_email (email);
+
+CREATE TABLE IF NOT EXISTS email_tokens (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    user_id BIGINT NOT NULL,
+    purpose VARCHAR(20) NOT NULL COMMENT '用途：verify_email/password_reset',
+    token_hash CHAR(64) NOT NULL COMMENT '令牌的 SHA-256 哈希',
+    email VARCHAR(100) NOT NULL COMMENT '令牌发送到的邮箱',
+    expires_at TIMESTAMP NOT NULL COMMENT '过期时间',
+    used_at TIMESTAMP NULL DEFAULT NULL COMMENT '使用时间，NULL 表示未使用',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY uk_token_hash (token_hash),
+    INDEX idx_user_purpose (user_id, purpose)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
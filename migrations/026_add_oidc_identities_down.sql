@@ -0,0 +1,6 @@
+-- 回滚 OpenID Connect 单点登录
+
+USE ai_diet_assistant;
+
+DROP TABLE IF EXISTS oidc_login_states;
+DROP TABLE IF EXISTS user_identities;
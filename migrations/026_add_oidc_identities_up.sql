@@ -0,0 +1,31 @@
+-- 添加 OpenID Connect 单点登录
+-- user_identities 记录外部身份与本地用户的关联，oidc_login_states 保存登录流程中的 state、nonce 和 PKCE code_verifier
+
+USE ai_diet_assistant;
+
+CREATE TABLE IF NOT EXISTS user_identities (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    user_id BIGINT NOT NULL,
+    issuer VARCHAR(255) NOT NULL COMMENT '身份提供方签发方地址',
+    subject VARCHAR(255) NOT NULL COMMENT '身份提供方中的用户标识（sub 声明）',
+    email VARCHAR(100) NOT NULL DEFAULT '' COMMENT '最近一次登录时身份提供方返回的邮箱',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_login_at TIMESTAMP NULL DEFAULT NULL COMMENT '最近一次通过该身份登录的时间',
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY uk_issuer_subject (issuer, subject),
+    UNIQUE KEY uk_user_issuer (user_id, issuer)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS oidc_login_states (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    state_hash CHAR(64) NOT NULL COMMENT 'state 的 SHA-256 哈希',
+    purpose VARCHAR(10) NOT NULL COMMENT '用途：login/link',
+    user_id BIGINT NULL DEFAULT NULL COMMENT '关联身份时发起流程的用户',
+    nonce VARCHAR(64) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL COMMENT 'PKCE code_verifier',
+    expires_at TIMESTAMP NOT NULL COMMENT '过期时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY uk_state_hash (state_hash),
+    INDEX idx_expires_at (expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,5 @@
+-- 回滚账户数据导出
+
+USE ai_diet_assistant;
+
+DROP TABLE IF EXISTS account_exports;
@@ -0,0 +1,21 @@
+-- 添加账户数据导出
+-- 导出文件保存在服务器的导出目录中，过期后由后台任务删除
+
+USE ai_diet_assistant;
+
+CREATE TABLE IF NOT EXISTS account_exports (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    user_id BIGINT NOT NULL,
+    format ENUM('json', 'zip') NOT NULL COMMENT '导出格式',
+    status ENUM('pending', 'processing', 'completed', 'failed') NOT NULL DEFAULT 'pending' COMMENT '状态',
+    file_name VARCHAR(100) NULL DEFAULT NULL COMMENT '导出目录中的文件名，生成完成后设置',
+    file_size BIGINT NOT NULL DEFAULT 0 COMMENT '文件大小（字节）',
+    record_count INT NOT NULL DEFAULT 0 COMMENT '导出的记录数',
+    error_message VARCHAR(255) NULL DEFAULT NULL COMMENT '生成失败的原因',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP NULL DEFAULT NULL COMMENT '生成完成时间',
+    expires_at TIMESTAMP NOT NULL COMMENT '过期时间，过期后不能下载',
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_user_created (user_id, created_at),
+    INDEX idx_expires (expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,8 @@
+-- 回滚账户删除申请
+
+USE ai_diet_assistant;
+
+ALTER TABLE users
+DROP INDEX idx_deletion_scheduled,
+DROP COLUMN deletion_scheduled_at,
+DROP COLUMN deletion_requested_at;
@@ -0,0 +1,9 @@
+-- 添加账户删除申请
+-- 用户申请删除账户后在宽限期内保留数据，期间登录即取消删除；宽限期过后由后台任务永久删除
+
+USE ai_diet_assistant;
+
+ALTER TABLE users
+ADD COLUMN deletion_requested_at TIMESTAMP NULL DEFAULT NULL COMMENT '申请删除账户的时间，NULL 表示未申请' AFTER disabled_at,
+ADD COLUMN deletion_scheduled_at TIMESTAMP NULL DEFAULT NULL COMMENT '计划永久删除的时间' AFTER deletion_requested_at,
+ADD INDEX idx_deletion_scheduled (deletion_scheduled_at);
@@ -0,0 +1,5 @@
+-- 回滚审计日志
+
+USE ai_diet_assistant;
+
+DROP TABLE IF EXISTS audit_logs;
@@ -0,0 +1,25 @@
+-- 添加审计日志
+-- 记录登录、密码修改、角色变更、设置修改、数据导出和删除等安全相关操作和管理操作
+-- 不设置外键，账户永久删除后审计记录仍然保留
+
+USE ai_diet_assistant;
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id BIGINT PRIMARY KEY AUTO_INCREMENT,
+    actor_user_id BIGINT NULL DEFAULT NULL COMMENT '执行操作的用户ID，系统任务或未认证时为 NULL',
+    actor_username VARCHAR(50) NOT NULL DEFAULT '' COMMENT '执行操作的用户名（记录时的值）',
+    api_token_id BIGINT NULL DEFAULT NULL COMMENT '使用个人访问令牌认证时的令牌ID',
+    action VARCHAR(64) NOT NULL COMMENT '操作，如 auth.login、admin.user.role_update',
+    resource_type VARCHAR(32) NOT NULL DEFAULT '' COMMENT '资源类型',
+    resource_id VARCHAR(64) NOT NULL DEFAULT '' COMMENT '资源ID',
+    outcome ENUM('success', 'failure') NOT NULL DEFAULT 'success' COMMENT '结果',
+    ip_address VARCHAR(45) NOT NULL DEFAULT '' COMMENT 'IP地址',
+    user_agent VARCHAR(255) NOT NULL DEFAULT '' COMMENT '用户代理',
+    changes JSON NULL COMMENT '字段修改前后的值，敏感字段已脱敏',
+    details JSON NULL COMMENT '其他上下文信息，敏感字段已脱敏',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_created (created_at),
+    INDEX idx_actor_created (actor_user_id, created_at),
+    INDEX idx_action_created (action, created_at),
+    INDEX idx_resource (resource_type, resource_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;